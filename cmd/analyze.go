@@ -0,0 +1,797 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	sampleSize int
+	positions  int
+	confidence float64
+	maxSize    int64
+	limit      int64
+	offset     int64
+	weightCol  string
+	mergeParts bool
+	columns    []string
+	excludes   []string
+	sampleRowN int
+	sampleCols []string
+	noSample   bool
+	delimiter  string
+	encoding   string
+	quoteChar  string
+	lazyQuotes bool
+	escapeMode string
+	noHeader   bool
+	onParseErr string
+	maxParseEr int64
+	comment    string
+	progress   bool
+	outFormat  string
+	jobs       int
+	fileTime   time.Duration
+	baseline   string
+	storedBase bool
+	driftSigma float64
+	driftKS    float64
+	driftPSI   float64
+	maskCols   []string
+	maskPII    bool
+	maskMode   string
+	maskKey    string
+	uniqueKey  []string
+	keyCands   []string
+	groupBy    string
+	anomalies  bool
+	recommend  bool
+	checkpoint string
+	ckptEvery  time.Duration
+	patterns   []string
+	plugins    []string
+	plugTime   time.Duration
+	nanPolicy  string
+	exactSums  bool
+	typeTol    float64
+	noSparks   bool
+	noColor    bool
+	wide       bool
+	summary    bool
+	locale     string
+	siNumbers  bool
+)
+
+// analyzeCmd profiles a single file and prints the full statistics report
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze <file|glob>...",
+	Short: "Profile CSV/TSV files and print their statistics",
+	Example: `  gotablestats analyze data.csv
+  gotablestats analyze large.tsv --sample-size 5000 --positions 10
+  gotablestats analyze data.csv --confidence 0.99
+  gotablestats analyze 'exports/*.csv' --summary
+  gotablestats analyze events.csv --offset -1000000
+  gotablestats analyze 'data/part-*.csv' --merge
+  gotablestats analyze 'logs/*.csv' --jobs 8 --file-timeout 30s
+  gotablestats analyze today.csv --baseline profile.json
+  gotablestats analyze feed.csv --stored-baseline
+  gotablestats analyze users.csv --mask-columns email,ssn --mask-pii
+  gotablestats analyze order_lines.csv --unique-key order_id,line_no
+  gotablestats analyze sales.csv --key-candidate date,store_id --key-candidate date,store_id,sku
+  gotablestats analyze sales.csv --group-by category
+  gotablestats analyze readings.csv --anomalies
+  gotablestats analyze events.csv --recommendations
+  gotablestats analyze products.csv --pattern 'sku=^SKU-\d{6}$'
+  gotablestats analyze payments.csv --plugin 'iban=./iban-analyzer --strict'
+  gotablestats analyze big.csv --cache-ttl 1h
+  gotablestats analyze huge.csv --max-size 1000000000000 --checkpoint huge.ckpt`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		runAnalyze(cmd.Context(), args)
+	},
+}
+
+func init() {
+	addSamplingFlags(analyzeCmd.Flags())
+	addMergeFlag(analyzeCmd.Flags())
+	analyzeCmd.Flags().StringVarP(&outFormat, "format", "f", "text", "Output format (text, json, markdown or ydata)")
+	analyzeCmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "Max number of files processed concurrently")
+	analyzeCmd.Flags().DurationVar(&fileTime, "file-timeout", 0, "Abort a file that takes longer than this, e.g. 30s (0 = no timeout)")
+	analyzeCmd.Flags().StringVar(&baseline, "baseline", "", "Report drift against a profile saved with --format json")
+	analyzeCmd.Flags().BoolVar(&storedBase, "stored-baseline", false, "Report drift of each file against its baseline in the --store")
+	addStoreFlag(analyzeCmd.Flags())
+	analyzeCmd.Flags().Float64Var(&driftSigma, "drift-sigma", tablestats.DefaultDriftStdErrors, "Standard errors a null share or mean must move by to count as drift")
+	analyzeCmd.Flags().Float64Var(&driftKS, "drift-ks-alpha", tablestats.DefaultKSAlpha, "KS p-value a numeric column's distribution must fall below to count as drift (0 = unchecked)")
+	analyzeCmd.Flags().Float64Var(&driftPSI, "drift-psi", tablestats.DefaultPSI, "Population stability index a numeric column must exceed to count as drift (0 = unchecked)")
+	analyzeCmd.Flags().StringSliceVar(&uniqueKey, "unique-key", nil, "Fail when rows share a value of these columns combined, e.g. order_id,line_no")
+	analyzeCmd.Flags().StringArrayVar(&keyCands, "key-candidate", nil, "Count the distinct combinations of these columns to judge them as a composite key, e.g. date,store_id (repeatable)")
+	analyzeCmd.Flags().StringVar(&groupBy, "group-by", "", "Also aggregate the numeric columns per value of this column, e.g. category")
+	analyzeCmd.Flags().BoolVar(&anomalies, "anomalies", false, "Report numeric columns with sentinel spikes, impossible values or two clusters")
+	analyzeCmd.Flags().BoolVar(&recommend, "recommendations", false, "Suggest how to store the columns: narrower types, dictionary encoding and a sort key")
+	analyzeCmd.Flags().StringVar(&checkpoint, "checkpoint", "", "Save the state of a full read (see --max-size) to this file periodically and on interrupt, and resume from it when it exists")
+	analyzeCmd.Flags().DurationVar(&ckptEvery, "checkpoint-interval", tablestats.DefaultCheckpointInterval, "Time between checkpoints, e.g. 5m")
+	analyzeCmd.Flags().StringArrayVar(&patterns, "pattern", nil, "Report how many values of a column match a regular expression, as column=regex (repeatable)")
+	analyzeCmd.Flags().StringArrayVar(&plugins, "plugin", nil, "Run an external analyzer over every column, as name=command (repeatable; see the README for the protocol)")
+	analyzeCmd.Flags().DurationVar(&plugTime, "plugin-timeout", tablestats.DefaultPluginTimeout, "Kill a plugin still running after this long")
+	analyzeCmd.Flags().BoolVar(&noSparks, "no-sparklines", false, "Leave out the sparklines of numeric columns in the text report, for plain-ASCII terminals")
+	analyzeCmd.Flags().BoolVar(&wide, "wide", false, "Print every detail of each column instead of a table with a row per column")
+	analyzeCmd.Flags().BoolVar(&summary, "summary", false, "Print a one-screen overview of each file for triage; add --wide for the column details")
+	analyzeCmd.Flags().StringVar(&locale, "locale", "", "Locale for thousands and decimal separators in text and markdown, e.g. de-DE (default from LC_ALL, LC_NUMERIC or LANG)")
+	analyzeCmd.Flags().BoolVar(&siNumbers, "si", false, "Abbreviate large numbers in text and markdown with SI prefixes, e.g. 1.2M")
+	analyzeCmd.Flags().BoolVar(&noColor, "no-color", false, "Do not color the text report, even on a terminal")
+	addMaskFlags(analyzeCmd.Flags())
+	addCacheFlags(analyzeCmd.Flags())
+	addHistoryFlags(analyzeCmd.Flags())
+	addNotifyFlags(analyzeCmd.Flags())
+	addPushFlags(analyzeCmd.Flags())
+	rootCmd.AddCommand(analyzeCmd)
+}
+
+// addMaskFlags registers the flags that hide sensitive values in the output
+func addMaskFlags(flags *pflag.FlagSet) {
+	flags.StringSliceVar(&maskCols, "mask-columns", nil, "Hide the values of these columns in the output (comma-separated)")
+	flags.BoolVar(&maskPII, "mask-pii", false, "Hide the values of columns flagged as personal data")
+	flags.StringVar(&maskMode, "mask-mode", "redact", "How hidden values are shown (redact or hash)")
+	flags.StringVar(&maskKey, "mask-key", "", "Key of the hashes shown by --mask-mode hash, so they match between runs (default $GOTABLESTATS_MASK_KEY, or a random key per run)")
+}
+
+// addMergeFlag registers the flag that profiles several inputs as one table
+func addMergeFlag(flags *pflag.FlagSet) {
+	flags.BoolVar(&mergeParts, "merge", false, "Profile all inputs as one logical table (same header required)")
+}
+
+// addReaderFlags registers the flags that control how a file is parsed
+func addReaderFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&delimiter, "delimiter", "d", "", "Field delimiter, e.g. ';' or '\\t' (detected when empty)")
+	flags.StringVar(&encoding, "encoding", "utf-8", "File encoding (utf-8, utf-16le, latin1 or windows-1252)")
+	flags.StringVar(&quoteChar, "quote", "", "Quote character, e.g. \"'\" (detected with the delimiter when empty, else '\"')")
+	flags.BoolVar(&lazyQuotes, "lazy-quotes", false, "Tolerate stray quotes inside fields (switched on when detected)")
+	flags.StringVar(&escapeMode, "escape", "doubled", "How quotes inside quoted fields are escaped (doubled or backslash; backslash is switched on when detected)")
+	flags.BoolVar(&noHeader, "no-header", false, "The first row holds values; name the columns column_1, column_2 and so on (detected when no delimiter is given)")
+	flags.StringVar(&onParseErr, "on-parse-error", "fail", "What to do with malformed records (fail, skip or report)")
+	flags.Int64Var(&maxParseEr, "max-parse-errors", 0, "Fail once more records are malformed, with skip or report (0 = no limit)")
+	flags.StringVar(&comment, "comment", "", "Skip lines starting with this character, e.g. '#'")
+}
+
+// addSamplingFlags registers the flags that control how a file is read and sampled
+func addSamplingFlags(flags *pflag.FlagSet) {
+	addReaderFlags(flags)
+	flags.IntVarP(&sampleSize, "sample-size", "s", 1000, "Number of rows to sample")
+	flags.IntVarP(&positions, "positions", "p", 5, "Number of random positions")
+	flags.Float64VarP(&confidence, "confidence", "c", 0.95, "Confidence level (0-1)")
+	flags.Int64VarP(&maxSize, "max-size", "m", 100*1024*1024, "Max file size for full processing (bytes)")
+	flags.Int64Var(&limit, "limit", 0, "Max number of rows to profile (0 = no limit)")
+	flags.Int64Var(&offset, "offset", 0, "Rows to skip before profiling (negative = count back from the end)")
+	flags.StringVar(&weightCol, "weight-column", "", "Numeric column to weight the sample by (e.g. amount)")
+	flags.StringSliceVar(&columns, "columns", nil, "Only profile these columns (comma-separated)")
+	flags.StringSliceVar(&excludes, "exclude-columns", nil, "Skip these columns (comma-separated)")
+	flags.IntVar(&sampleRowN, "sample-rows", tablestats.DefaultSampleRows, "Number of example rows to show")
+	flags.StringSliceVar(&sampleCols, "sample-columns", nil, "Only show these columns in the example rows, e.g. id,amount")
+	flags.BoolVar(&noSample, "no-sample-data", false, "Do not show example rows")
+	flags.BoolVar(&progress, "progress", false, "Report read progress on stderr")
+	flags.StringVar(&nanPolicy, "nan-policy", "ignore", "How NaN and infinite numbers are profiled (ignore, propagate or count-separately)")
+	flags.BoolVar(&exactSums, "exact-sums", false, "Sum numeric columns exactly, which is slower but never rounds intermediate sums")
+	flags.Float64Var(&typeTol, "type-tolerance", 0, "Share of values (0-1) that may fail to parse before a numeric column is profiled as text")
+}
+
+// withProgress attaches a progress printer for filePath when --progress is set.
+// Updates are written to stderr in steps of 10%.
+func withProgress(config tablestats.SamplingConfig, filePath string) tablestats.SamplingConfig {
+	if !progress {
+		return config
+	}
+	lastPct := int64(-10)
+	config.Progress = func(bytesRead, totalBytes, rowsProcessed int64) {
+		if totalBytes <= 0 {
+			return
+		}
+		pct := bytesRead * 100 / totalBytes
+		if pct-lastPct >= 10 || (pct == 100 && lastPct != 100) {
+			fmt.Fprintf(os.Stderr, "Progress: %s %d%% (%d rows)\n", filePath, pct, rowsProcessed)
+			lastPct = pct
+		}
+	}
+	return config
+}
+
+// samplingConfig builds a validated SamplingConfig from the sampling flags
+func samplingConfig() tablestats.SamplingConfig {
+	config := tablestats.SamplingConfig{
+		SampleSize:      sampleSize,
+		RandomPositions: positions,
+		Confidence:      confidence,
+		MaxFileSize:     maxSize,
+		Offset:          offset,
+		Limit:           limit,
+		WeightColumn:    weightCol,
+		Columns:         columns,
+		ExcludeColumns:  excludes,
+		SampleRows:      sampleRowN,
+		SampleColumns:   sampleCols,
+		UniqueKey:       uniqueKey,
+		KeyCandidates:   keyCandidates(keyCands),
+		GroupBy:         groupBy,
+		DetectAnomalies: anomalies,
+		Recommend:       recommend,
+		Patterns:        columnPatterns(patterns),
+		ExactSums:       exactSums,
+		TypeTolerance:   typeTol,
+	}
+	if noSample || sampleRowN == 0 {
+		config.SampleRows = -1
+	}
+	policy, err := tablestats.ParseNonFinitePolicy(nanPolicy)
+	if err != nil {
+		log.Fatal(err)
+	}
+	config.NonFinite = policy
+	for _, spec := range plugins {
+		plugin, err := tablestats.ParsePlugin(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		plugin.Timeout = plugTime
+		config.Plugins = append(config.Plugins, plugin)
+	}
+
+	if err := validateConfig(config); err != nil {
+		log.Fatal(err)
+	}
+	return config
+}
+
+func runAnalyze(ctx context.Context, inputs []string) {
+	config := samplingConfig()
+	if _, err := tablestats.NewRenderer(outFormat, ""); err != nil {
+		log.Fatal(err)
+	}
+	if jobs < 1 {
+		log.Fatal("jobs must be positive")
+	}
+	if driftSigma <= 0 {
+		log.Fatal("drift sigma must be positive")
+	}
+	if driftKS < 0 || driftKS >= 1 || driftPSI < 0 {
+		log.Fatal("drift KS alpha must be between 0 and 1, and drift PSI must not be negative")
+	}
+	if _, err := tablestats.ParseMaskMode(maskMode); err != nil {
+		log.Fatal(err)
+	}
+	if storedBase && (baseline != "" || mergeParts) {
+		log.Fatal("--stored-baseline cannot be combined with --baseline or --merge")
+	}
+	var base *tablestats.TableStats
+	if baseline != "" {
+		var err error
+		if base, err = tablestats.LoadBaseline(baseline); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	files, err := expandInputs(inputs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if checkpoint != "" {
+		if len(files) != 1 || mergeParts {
+			log.Fatal("--checkpoint needs a single input file")
+		}
+		config.Checkpoint, config.CheckpointInterval = checkpoint, ckptEvery
+	}
+	// Load every stored baseline up front so a missing one fails before profiling
+	bases := make([]*tablestats.TableStats, len(files))
+	for i, filePath := range files {
+		bases[i] = base
+		if storedBase {
+			if bases[i], err = tablestats.NewBaselineStore(storeDir).Load(filePath); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	// Process files
+	start := time.Now()
+	if mergeParts {
+		samples := make([]*tablestats.Sample, len(files))
+		err = forEachFile(ctx, files, func(ctx context.Context, i int, filePath string) error {
+			var err error
+			samples[i], err = readSample(ctx, filePath, config)
+			return skipEmpty(filePath, err, len(files))
+		})
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+		merged, err := tablestats.MergeSamples(nonNil(samples))
+		if err != nil {
+			log.Fatalf("Error merging files: %v", err)
+		}
+		log.Printf("Process time: %v", time.Since(start).String())
+
+		stats := tablestats.AnalyzeSample(merged, config)
+		failures := outputStats(stats, "", base)
+		pushRun(ctx, newRun("analyze", strings.Join(files, ", "), start, stats, failures))
+		if len(failures) > 0 {
+			notifyFailure(ctx, "analyze", strings.Join(files, ", "), failures)
+			os.Exit(1)
+		}
+		return
+	}
+
+	results := make([]*tablestats.TableStats, len(files))
+	durations := make([]time.Duration, len(files))
+	err = forEachFile(ctx, files, func(ctx context.Context, i int, filePath string) error {
+		fileStart := time.Now()
+		var err error
+		results[i], err = processFile(ctx, filePath, config)
+		durations[i] = time.Since(fileStart)
+		return skipEmpty(filePath, err, len(files))
+	})
+	if err != nil {
+		log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+	}
+	processTime := time.Since(start).String()
+	log.Printf("Process time: %v", processTime)
+
+	failed := false
+	for i, stats_ := range results {
+		if stats_ == nil {
+			continue
+		}
+		name := ""
+		if len(files) > 1 {
+			name = files[i]
+		}
+		failures := outputStats(stats_, name, bases[i])
+		run := newRun("analyze", files[i], start, stats_, failures)
+		run.Duration = durations[i]
+		pushRun(ctx, run)
+		if len(failures) > 0 {
+			notifyFailure(ctx, "analyze", files[i], failures)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// outputStats masks and renders a profile, followed by its drift against base
+// when one is given. It returns the drifts and duplicate keys found.
+func outputStats(stats *tablestats.TableStats, title string, base *tablestats.TableStats) []string {
+	// Drift is detected on the unmasked values, which the baseline holds too
+	var report *tablestats.DriftReport
+	if base != nil {
+		report = tablestats.DetectDriftWith(base, stats, tablestats.DriftThresholds{StdErrors: driftSigma, KSAlpha: driftKS, PSI: driftPSI})
+	}
+	warnNonFinite(stats, title)
+	warnRenamedColumns(stats, title)
+	masked := maskStats(stats)
+	renderStats(stats, title)
+	var failures []string
+	if stats.UniqueKey != nil {
+		failures = stats.UniqueKey.Failures()
+	}
+	if report == nil {
+		return failures
+	}
+	report.Mask(masked, masking())
+	printDrift(report)
+	return append(failures, report.Failures()...)
+}
+
+// warnNonFinite points out the NaN and infinite values profiled as nulls by
+// the default --nan-policy
+func warnNonFinite(stats *tablestats.TableStats, title string) {
+	if stats.SamplingConfig.NonFinite != tablestats.NonFiniteIgnore {
+		return
+	}
+	prefix := ""
+	if title != "" {
+		prefix = title + ": "
+	}
+	for _, name := range stats.ColumnNames {
+		if counts, ok := stats.NonFinite[name]; ok {
+			fmt.Fprintf(os.Stderr, "Warning: %scolumn %q has %d NaN or infinite values, profiled as nulls (see --nan-policy)\n",
+				prefix, name, counts.Total())
+		}
+	}
+}
+
+// warnRenamedColumns points out blank and repeated header names, which are
+// profiled under new names
+func warnRenamedColumns(stats *tablestats.TableStats, title string) {
+	prefix := ""
+	if title != "" {
+		prefix = title + ": "
+	}
+	for _, r := range stats.RenamedColumns {
+		fmt.Fprintf(os.Stderr, "Warning: %scolumn %d is named %q, profiled as %q\n", prefix, r.Position, r.Original, r.Name)
+	}
+}
+
+// maskStats applies --mask-columns and --mask-pii to a profile, and returns
+// the columns masked
+func maskStats(stats *tablestats.TableStats) []string {
+	columns := maskCols
+	if maskPII {
+		columns = append(columns[:len(columns):len(columns)], stats.PIIColumns()...)
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	if err := stats.Mask(columns, masking()); err != nil {
+		log.Fatal(err)
+	}
+	return columns
+}
+
+// runMaskKey is the key of the hashes of a run without --mask-key
+var runMaskKey = sync.OnceValue(func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("failed to generate a mask key: %v", err)
+	}
+	return key
+})
+
+// masking returns the --mask-mode and --mask-key settings
+func masking() tablestats.Masking {
+	mode, err := tablestats.ParseMaskMode(maskMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+	key := maskKey
+	if key == "" {
+		key = os.Getenv("GOTABLESTATS_MASK_KEY")
+	}
+	if key == "" {
+		return tablestats.Masking{Mode: mode, Key: runMaskKey()}
+	}
+	return tablestats.Masking{Mode: mode, Key: []byte(key)}
+}
+
+// printDrift prints a drift report, on stderr when the output is machine readable
+func printDrift(report *tablestats.DriftReport) {
+	if outFormat == "json" || outFormat == "ydata" {
+		for _, d := range report.Drifts {
+			fmt.Fprintf(os.Stderr, "Drift: %s: %s: %s\n", d.Column, d.Kind, d.Detail)
+		}
+	} else if err := report.WriteText(os.Stdout); err != nil {
+		log.Fatalf("Error writing output: %v", err)
+	}
+}
+
+// renderStats writes a profile to stdout in the --format output format
+func renderStats(stats *tablestats.TableStats, title string) {
+	renderer, err := tablestats.NewRenderer(outFormat, title)
+	if err != nil {
+		log.Fatal(err)
+	}
+	numbers := numberFormat()
+	switch r := renderer.(type) {
+	case *tablestats.TextRenderer:
+		r.NoSparklines = noSparks
+		r.Wide = wide
+		r.Summary = summary
+		r.Color = !noColor && colorTerminal()
+		r.Numbers = numbers
+	case *tablestats.MarkdownRenderer:
+		r.Numbers = numbers
+	}
+	if err := renderer.Render(os.Stdout, stats); err != nil {
+		log.Fatalf("Error writing output: %v", err)
+	}
+}
+
+// numberFormat builds the NumberFormat of human-readable reports from
+// --locale, or the locale of the environment, and --si
+func numberFormat() tablestats.NumberFormat {
+	format := tablestats.NumberFormat{SI: siNumbers}
+	if locale != "" {
+		tag, err := tablestats.ParseLocale(locale)
+		if err != nil {
+			log.Fatalf("invalid locale %q: %v", locale, err)
+		}
+		format.Locale = tag
+		return format
+	}
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			// A locale the environment misspells falls back to English separators
+			format.Locale, _ = tablestats.ParseLocale(value)
+			break
+		}
+	}
+	return format
+}
+
+// colorTerminal reports whether stdout is a terminal that shows colors, as
+// opposed to a pipe or file, and NO_COLOR is not set
+func colorTerminal() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// skipEmpty turns an empty-file error into a warning when several files are
+// processed, so one empty part does not fail the whole run
+func skipEmpty(filePath string, err error, files int) error {
+	if files > 1 && errors.Is(err, tablestats.ErrEmptyFile) {
+		log.Printf("Skipping empty file %s", filePath)
+		return nil
+	}
+	return err
+}
+
+// nonNil drops the entries of skipped files
+func nonNil[T any](items []*T) []*T {
+	var kept []*T
+	for _, item := range items {
+		if item != nil {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// errorHint suggests flags that may fix a failed read
+func errorHint(err error) string {
+	var parseErr *tablestats.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		return " (try --lazy-quotes, --escape, --delimiter and --quote to match the file's dialect, or --on-parse-error skip)"
+	case errors.Is(err, tablestats.ErrNoHeader):
+		return " (the first row must hold the column names, or use --no-header)"
+	case errors.Is(err, tablestats.ErrUnknownColumn):
+		return " (column names are case-sensitive)"
+	}
+	return ""
+}
+
+// expandInputs resolves glob patterns into the list of files to process
+func expandInputs(inputs []string) ([]string, error) {
+	var files []string
+	for _, input := range inputs {
+		if !strings.ContainsAny(input, "*?[") {
+			files = append(files, input)
+			continue
+		}
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %v", input, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", input)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// forEachFile calls fn for every file on up to --jobs workers. Each call gets
+// its own context, limited by --file-timeout. All files are processed even when
+// some fail, and every failure is reported in the returned error.
+func forEachFile(ctx context.Context, files []string, fn func(ctx context.Context, i int, filePath string) error) error {
+	errs := make([]error, len(files))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(jobs, len(files)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := processOne(ctx, i, files[i], fn); err != nil {
+					errs[i] = fmt.Errorf("%s: %w", files[i], err)
+				}
+			}
+		}()
+	}
+	for i := range files {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// processOne calls fn for a single file under the --file-timeout limit
+func processOne(ctx context.Context, i int, filePath string, fn func(ctx context.Context, i int, filePath string) error) error {
+	if fileTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, fileTime)
+		defer cancel()
+	}
+	err := fn(ctx, i, filePath)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %v", fileTime)
+	}
+	return err
+}
+
+// keyCandidates parses comma-separated column tuples
+func keyCandidates(args []string) [][]string {
+	var result [][]string
+	for _, arg := range args {
+		columns := strings.Split(arg, ",")
+		for i, column := range columns {
+			columns[i] = strings.TrimSpace(column)
+			if columns[i] == "" {
+				log.Fatalf("invalid key candidate %q (use column,column,...)", arg)
+			}
+		}
+		result = append(result, columns)
+	}
+	return result
+}
+
+// columnPatterns parses column=regex arguments; the regex may contain '='
+func columnPatterns(args []string) map[string]string {
+	if len(args) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(args))
+	for _, arg := range args {
+		column, expr, ok := strings.Cut(arg, "=")
+		if !ok || column == "" {
+			log.Fatalf("invalid pattern %q (use column=regex)", arg)
+		}
+		if _, err := regexp.Compile(expr); err != nil {
+			log.Fatalf("invalid pattern for column %q: %v", column, err)
+		}
+		result[column] = expr
+	}
+	return result
+}
+
+func validateConfig(config tablestats.SamplingConfig) error {
+	if config.SampleSize <= 0 {
+		return fmt.Errorf("sample size must be positive")
+	}
+	if config.RandomPositions <= 0 {
+		return fmt.Errorf("random positions must be positive")
+	}
+	if config.Confidence <= 0 || config.Confidence >= 1 {
+		return fmt.Errorf("confidence must be between 0 and 1")
+	}
+	if config.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	if config.SampleRows < -1 {
+		return fmt.Errorf("sample rows must not be negative")
+	}
+	if config.TypeTolerance < 0 || config.TypeTolerance >= 1 {
+		return fmt.Errorf("type tolerance must be at least 0 and below 1")
+	}
+	return nil
+}
+
+// processFile profiles a file, or returns its cached profile when the file
+// and options are unchanged since it was cached. Fresh profiles are recorded
+// in the history.
+func processFile(ctx context.Context, filePath string, config tablestats.SamplingConfig) (*tablestats.TableStats, error) {
+	opts, err := readerOptions()
+	if err != nil {
+		return nil, err
+	}
+	cache := resultCache()
+	key := ""
+	if cache != nil {
+		// Files that cannot be keyed are profiled, and fail there if unreadable
+		if key, err = cache.Key(filePath, config, opts); err == nil {
+			if stats, ok := cache.Get(key); ok {
+				log.Printf("Using cached profile of %s", filePath)
+				return stats, nil
+			}
+		}
+	}
+
+	reader, err := tablestats.NewReaderFor(filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := reader.ReadTable(ctx, filePath, withProgress(config, filePath))
+	if err != nil {
+		return nil, err
+	}
+	if key != "" {
+		if err := cache.Put(key, stats); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+	recordRun(filePath, stats)
+	return stats, nil
+}
+
+// newReader picks a TableReader for the file from the reader registry.
+// An explicit --delimiter wins over content sniffing and the file extension.
+func newReader(filePath string) (tablestats.TableReader, error) {
+	opts, err := readerOptions()
+	if err != nil {
+		return nil, err
+	}
+	return tablestats.NewReaderFor(filePath, opts)
+}
+
+// readerOptions builds the reader options from the reader flags
+func readerOptions() (tablestats.ReaderOptions, error) {
+	delim, err := parseDelimiter(delimiter)
+	if err != nil {
+		return tablestats.ReaderOptions{}, err
+	}
+	quote, err := parseDialectChar("quote", quoteChar)
+	if err != nil {
+		return tablestats.ReaderOptions{}, err
+	}
+	commentChar, err := parseDialectChar("comment", comment)
+	if err != nil {
+		return tablestats.ReaderOptions{}, err
+	}
+	policy, err := tablestats.ParseErrorPolicy(onParseErr)
+	if err != nil {
+		return tablestats.ReaderOptions{}, err
+	}
+	escape, err := tablestats.ParseEscapeStyle(escapeMode)
+	if err != nil {
+		return tablestats.ReaderOptions{}, err
+	}
+	if maxParseEr < 0 {
+		return tablestats.ReaderOptions{}, fmt.Errorf("max parse errors must not be negative")
+	}
+
+	return tablestats.ReaderOptions{
+		Delimiter:      delim,
+		Encoding:       encoding,
+		Quote:          quote,
+		LazyQuotes:     lazyQuotes,
+		Escape:         escape,
+		Comment:        commentChar,
+		NoHeader:       noHeader,
+		OnParseError:   policy,
+		MaxParseErrors: maxParseEr,
+	}, nil
+}
+
+// parseDelimiter converts a --delimiter value into a rune, 0 meaning auto-detect
+func parseDelimiter(value string) (rune, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case "\\t", "tab":
+		return '\t', nil
+	}
+
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf("invalid delimiter %q", value)
+	}
+	return runes[0], nil
+}
+
+// parseDialectChar converts a --quote or --comment value into a rune, 0 meaning unset
+func parseDialectChar(flag, value string) (rune, error) {
+	if value == "" {
+		return 0, nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf("invalid %s character %q", flag, value)
+	}
+	return runes[0], nil
+}
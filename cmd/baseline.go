@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var storeDir string
+
+// baselineCmd groups the commands that manage stored baseline profiles
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage the stored baseline profiles used for drift checks",
+	Example: `  gotablestats baseline save feed.csv
+  gotablestats analyze feed.csv --stored-baseline
+  gotablestats baseline update feed.csv`,
+}
+
+var baselineSaveCmd = &cobra.Command{
+	Use:   "save <file>",
+	Short: "Profile a file and store it as its baseline",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := tablestats.NewBaselineStore(storeDir)
+		exists, err := store.Has(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if exists {
+			log.Fatalf("A baseline for %s already exists, use 'gotablestats baseline update' to replace it", args[0])
+		}
+		saveBaseline(cmd, store, args[0])
+	},
+}
+
+var baselineUpdateCmd = &cobra.Command{
+	Use:   "update <file>",
+	Short: "Profile a file and replace its stored baseline",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		store := tablestats.NewBaselineStore(storeDir)
+		exists, err := store.Has(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !exists {
+			log.Fatalf("No baseline for %s, use 'gotablestats baseline save' to create one", args[0])
+		}
+		saveBaseline(cmd, store, args[0])
+	},
+}
+
+var baselineShowCmd = &cobra.Command{
+	Use:   "show <file>",
+	Short: "Print the stored baseline of a file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		stats, err := tablestats.NewBaselineStore(storeDir).Load(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		renderStats(stats, args[0])
+	},
+}
+
+func init() {
+	addStoreFlag(baselineCmd.PersistentFlags())
+	addSamplingFlags(baselineSaveCmd.Flags())
+	addSamplingFlags(baselineUpdateCmd.Flags())
+	baselineShowCmd.Flags().StringVarP(&outFormat, "format", "f", "text", "Output format (text, json, markdown or ydata)")
+	baselineCmd.AddCommand(baselineSaveCmd, baselineUpdateCmd, baselineShowCmd)
+	rootCmd.AddCommand(baselineCmd)
+}
+
+// addStoreFlag registers the flag that locates the baseline store
+func addStoreFlag(flags *pflag.FlagSet) {
+	flags.StringVar(&storeDir, "store", defaultStoreDir(), "Directory holding the baseline profiles")
+}
+
+// defaultStoreDir keeps baselines next to the user config file, or in the
+// working directory when there is no user config directory
+func defaultStoreDir() string {
+	if dir, err := os.UserConfigDir(); err == nil {
+		return filepath.Join(dir, "gotablestats", "baselines")
+	}
+	return filepath.Join(".gotablestats", "baselines")
+}
+
+// saveBaseline profiles filePath and stores the result
+func saveBaseline(cmd *cobra.Command, store *tablestats.BaselineStore, filePath string) {
+	stats, err := processFile(cmd.Context(), filePath, samplingConfig())
+	if err != nil {
+		log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+	}
+	if err := store.Save(filePath, stats); err != nil {
+		log.Fatal(err)
+	}
+	path, _ := store.Path(filePath)
+	fmt.Printf("Saved baseline for %s (%d sampled rows) to %s\n", filePath, stats.RowCount, path)
+}
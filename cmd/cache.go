@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/pflag"
+)
+
+var (
+	cacheDir  string
+	cacheTTL  time.Duration
+	cacheHash bool
+	noCache   bool
+)
+
+// addCacheFlags registers the flags that control the result cache
+func addCacheFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&cacheDir, "cache-dir", defaultCacheDir(), "Directory holding cached profiles")
+	flags.DurationVar(&cacheTTL, "cache-ttl", 24*time.Hour, "How long a cached profile is used for (0 = until the file changes)")
+	flags.BoolVar(&cacheHash, "cache-hash", false, "Key cached profiles by a hash of the file content instead of its size and modification time")
+	flags.BoolVar(&noCache, "no-cache", false, "Always profile files, neither reading nor writing the cache")
+}
+
+// defaultCacheDir keeps cached profiles in the user cache directory
+func defaultCacheDir() string {
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "gotablestats", "profiles")
+	}
+	return filepath.Join(".gotablestats", "cache")
+}
+
+// resultCache returns the cache set up by the cache flags, or nil when caching
+// is off or the command has no cache flags
+func resultCache() *tablestats.ResultCache {
+	if noCache || cacheDir == "" {
+		return nil
+	}
+	cache := tablestats.NewResultCache(cacheDir, cacheTTL)
+	cache.HashContent = cacheHash
+	return cache
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rulesFile  string
+	expectFile string
+)
+
+// checkCmd evaluates YAML quality rules and expectations against a file and
+// fails on any violation
+var checkCmd = &cobra.Command{
+	Use:   "check <file>",
+	Short: "Evaluate quality rules and expectations against a file and report violations",
+	Example: `  gotablestats check data.csv --rules rules.yaml
+  gotablestats check large.csv --rules rules.yaml --sample-size 100000
+  gotablestats check orders.csv --expect expectations.yaml
+  gotablestats check orders.csv --rules rules.yaml --format gh-annotations`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+		config := samplingConfig()
+		checkReportFormat()
+		if rulesFile == "" && expectFile == "" {
+			log.Fatal("at least one of --rules or --expect is required")
+		}
+
+		var rules *tablestats.Rules
+		var expectations *tablestats.Expectations
+		var err error
+		if rulesFile != "" {
+			if rules, err = tablestats.LoadRules(rulesFile); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if expectFile != "" {
+			if expectations, err = tablestats.LoadExpectations(expectFile); err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		sample, err := readSample(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+
+		failed := false
+		var failures []string
+		var annotations []tablestats.Annotation
+		var violations int64
+		if rules != nil {
+			report := tablestats.Check(sample, rules)
+			violations = report.TotalViolations()
+			if reportFormat == "gh-annotations" {
+				annotations = report.Annotations(args[0], tablestats.SchemaThresholds{})
+			} else if err := report.WriteText(os.Stdout, tablestats.SchemaThresholds{}); err != nil {
+				log.Fatalf("Error writing output: %v", err)
+			}
+			failed = report.Failed(tablestats.SchemaThresholds{})
+			failures = report.Failures()
+		}
+		// Expectations are assertions over the profile of the same rows
+		var stats *tablestats.TableStats
+		if expectations != nil || pushgateway() != nil {
+			stats = tablestats.AnalyzeSample(sample, config)
+		}
+		if expectations != nil {
+			report, err := tablestats.Evaluate(stats, expectations)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if reportFormat == "gh-annotations" {
+				annotations = append(annotations, report.Annotations(args[0])...)
+			} else if err := report.WriteText(os.Stdout); err != nil {
+				log.Fatalf("Error writing output: %v", err)
+			}
+			failed = failed || report.Failed()
+			failures = append(failures, report.Failures()...)
+		}
+		if reportFormat == "gh-annotations" {
+			writeAnnotations(annotations)
+		}
+
+		run := newRun("check", args[0], start, stats, failures)
+		run.Violations = violations
+		pushRun(cmd.Context(), run)
+
+		if failed {
+			notifyFailure(cmd.Context(), "check", args[0], failures)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(checkCmd.Flags())
+	checkCmd.Flags().StringVar(&rulesFile, "rules", "", "Rules file (YAML)")
+	checkCmd.Flags().StringVar(&expectFile, "expect", "", "Expectations file (YAML) with assertions over the profile")
+	addReportFormatFlag(checkCmd.Flags())
+	addNotifyFlags(checkCmd.Flags())
+	addPushFlags(checkCmd.Flags())
+	rootCmd.AddCommand(checkCmd)
+}
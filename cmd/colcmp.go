@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	colcmpA      string
+	colcmpB      string
+	colcmpFormat string
+)
+
+// colcmpCmd compares two numeric columns of one file
+var colcmpCmd = &cobra.Command{
+	Use:   "colcmp <file>",
+	Short: "Compare the distributions of two numeric columns of a file",
+	Long: `Compare two numeric columns of a file, such as a forecast and the actual
+values: their summary statistics, the difference of --b minus --a row by row,
+Pearson's correlation, the Kolmogorov-Smirnov statistic of their distributions
+and a scatter grid of the pairs.
+
+Like column, only the two columns are kept, so the default sample is 100,000
+rows.`,
+	Example: `  gotablestats colcmp data.csv --a forecast --b actual
+  gotablestats colcmp prices.csv --a list_price --b paid --format json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if colcmpFormat != "text" && colcmpFormat != "json" {
+			log.Fatalf("unsupported colcmp format %q (use text or json)", colcmpFormat)
+		}
+		if colcmpA == colcmpB {
+			log.Fatal("--a and --b must name different columns")
+		}
+		if !cmd.Flags().Changed("sample-size") {
+			sampleSize = columnSampleSize
+		}
+		columns = []string{colcmpA, colcmpB}
+		excludes = nil
+		config := samplingConfig()
+
+		sample, err := readSample(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+		comparison, err := tablestats.CompareColumns(sample, colcmpA, colcmpB)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if colcmpFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(comparison)
+		} else {
+			err = comparison.WriteText(os.Stdout, numberFormat())
+		}
+		if err != nil {
+			log.Fatalf("Error writing output: %v", err)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(colcmpCmd.Flags())
+	colcmpCmd.Flags().MarkHidden("columns")
+	colcmpCmd.Flags().MarkHidden("exclude-columns")
+	colcmpCmd.Flags().MarkHidden("weight-column")
+	colcmpCmd.Flags().StringVar(&colcmpA, "a", "", "First numeric column, e.g. forecast")
+	colcmpCmd.Flags().StringVar(&colcmpB, "b", "", "Second numeric column, compared against the first, e.g. actual")
+	colcmpCmd.MarkFlagRequired("a")
+	colcmpCmd.MarkFlagRequired("b")
+	colcmpCmd.Flags().StringVarP(&colcmpFormat, "format", "f", "text", "Output format (text or json)")
+	colcmpCmd.Flags().StringVar(&locale, "locale", "", "Locale for thousands and decimal separators, e.g. de-DE (default from LC_ALL, LC_NUMERIC or LANG)")
+	colcmpCmd.Flags().BoolVar(&siNumbers, "si", false, "Abbreviate large numbers with SI prefixes, e.g. 1.2M")
+	rootCmd.AddCommand(colcmpCmd)
+}
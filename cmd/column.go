@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+// columnSampleSize is the default sample size of the column command, larger
+// than analyze's as a single column is cheap to keep
+const columnSampleSize = 100_000
+
+var columnFormat string
+
+// columnCmd profiles one column of a file in depth
+var columnCmd = &cobra.Command{
+	Use:   "column <file> <column>",
+	Short: "Profile a single column in depth",
+	Long: `Profile a single column in depth: a 50-bin histogram, the 50 most frequent
+values, outliers beyond 1.5 interquartile ranges and the masks its values
+follow, such as AA-9999 for FR-2024.
+
+Only the column is kept in memory, so the default sample is 100,000 rows
+rather than analyze's 1,000.`,
+	Example: `  gotablestats column data.csv amount
+  gotablestats column data.csv country --sample-size 1000000 --format json`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if columnFormat != "text" && columnFormat != "json" {
+			log.Fatalf("unsupported column format %q (use text or json)", columnFormat)
+		}
+		if !cmd.Flags().Changed("sample-size") {
+			sampleSize = columnSampleSize
+		}
+		columns = []string{args[1]}
+		excludes = nil
+		config := samplingConfig()
+
+		sample, err := readSample(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+		profile, err := tablestats.ProfileColumn(sample, args[1], config)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if columnFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(profile)
+		} else {
+			err = profile.WriteText(os.Stdout, numberFormat())
+		}
+		if err != nil {
+			log.Fatalf("Error writing output: %v", err)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(columnCmd.Flags())
+	columnCmd.Flags().MarkHidden("columns")
+	columnCmd.Flags().MarkHidden("exclude-columns")
+	columnCmd.Flags().StringVarP(&columnFormat, "format", "f", "text", "Output format (text or json)")
+	columnCmd.Flags().StringVar(&locale, "locale", "", "Locale for thousands and decimal separators, e.g. de-DE (default from LC_ALL, LC_NUMERIC or LANG)")
+	columnCmd.Flags().BoolVar(&siNumbers, "si", false, "Abbreviate large numbers with SI prefixes, e.g. 1.2M")
+	rootCmd.AddCommand(columnCmd)
+}
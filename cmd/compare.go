@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+var compareThresholds tablestats.CompareThresholds
+
+// compareCmd profiles two files and reports how the second differs from the first
+var compareCmd = &cobra.Command{
+	Use:   "compare <old-file> <new-file>",
+	Short: "Report schema changes and metric deltas between two files",
+	Example: `  gotablestats compare old.csv new.csv
+  gotablestats compare old.csv new.csv --max-null-change 5 --max-mean-change 10
+  gotablestats compare old.csv new.csv --ks-alpha 0.01 --max-psi 0.25`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		config := samplingConfig()
+
+		oldStats, err := processFile(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file %s: %v%s", args[0], err, errorHint(err))
+		}
+		newStats, err := processFile(cmd.Context(), args[1], config)
+		if err != nil {
+			log.Fatalf("Error processing file %s: %v%s", args[1], err, errorHint(err))
+		}
+
+		comparison := tablestats.Compare(oldStats, newStats)
+		if err := comparison.WriteText(os.Stdout, compareThresholds); err != nil {
+			log.Fatalf("Error writing output: %v", err)
+		}
+
+		if comparison.Failed(compareThresholds) {
+			notifyFailure(cmd.Context(), "compare", args[0]+" -> "+args[1], comparison.Failures(compareThresholds))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(compareCmd.Flags())
+	addCacheFlags(compareCmd.Flags())
+	addHistoryFlags(compareCmd.Flags())
+	addNotifyFlags(compareCmd.Flags())
+	compareCmd.Flags().Float64Var(&compareThresholds.RowCountPct, "max-row-change", 0, "Max percent change in row count (0 = unchecked)")
+	compareCmd.Flags().Float64Var(&compareThresholds.NullPctPoints, "max-null-change", 0, "Max change in null percentage points per column (0 = unchecked)")
+	compareCmd.Flags().Float64Var(&compareThresholds.MeanPct, "max-mean-change", 0, "Max percent change in column means (0 = unchecked)")
+	compareCmd.Flags().Float64Var(&compareThresholds.DistinctPct, "max-distinct-change", 0, "Max percent change in distinct counts (0 = unchecked)")
+	compareCmd.Flags().Float64Var(&compareThresholds.KSAlpha, "ks-alpha", 0, "Fail when a numeric column's KS p-value falls below this, e.g. 0.01 (0 = unchecked)")
+	compareCmd.Flags().Float64Var(&compareThresholds.PSI, "max-psi", 0, "Max population stability index per numeric column, e.g. 0.25 (0 = unchecked)")
+	compareCmd.Flags().BoolVar(&compareThresholds.AllowSchemaChange, "allow-schema-change", false, "Do not fail on added, removed or retyped columns")
+	rootCmd.AddCommand(compareCmd)
+}
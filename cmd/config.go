@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigName is looked up in the working directory before the user config directory
+const defaultConfigName = ".gotablestats.yaml"
+
+var (
+	configFile  string
+	profileName string
+)
+
+// fileConfig is the layout of the configuration file. Each profile maps flag
+// names to values, so a profile can bundle sampling, output and threshold
+// settings for any subcommand:
+//
+//	default_profile: quick
+//	profiles:
+//	  quick:
+//	    sample-size: 200
+//	  ci:
+//	    sample-size: 5000
+//	    max-null-change: 5
+type fileConfig struct {
+	DefaultProfile string                            `yaml:"default_profile"`
+	Profiles       map[string]map[string]interface{} `yaml:"profiles"`
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default ./"+defaultConfigName+" or <user config dir>/gotablestats/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Named profile from the config file to apply")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return applyProfile(cmd)
+	}
+}
+
+// applyProfile sets the flags of the selected profile that were not given on
+// the command line. Settings for flags of other commands are ignored; settings
+// no command has a flag for are rejected, as they are most likely typos.
+func applyProfile(cmd *cobra.Command) error {
+	config, path, err := loadFileConfig()
+	if err != nil {
+		return err
+	}
+
+	name := profileName
+	if name == "" && config != nil {
+		name = config.DefaultProfile
+	}
+	if name == "" {
+		return nil
+	}
+	if config == nil {
+		return fmt.Errorf("profile %q requested but no config file was found", name)
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in %s", name, path)
+	}
+
+	keys := make([]string, 0, len(profile))
+	for key := range profile {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		flag := cmd.Flags().Lookup(key)
+		if flag == nil && !definesFlag(cmd.Root(), key) {
+			return fmt.Errorf("profile %q in %s: unknown setting %q, no command has a --%s flag", name, path, key, key)
+		}
+		if flag == nil || flag.Changed {
+			continue
+		}
+		// Repeatable flags take one value per list item or map entry
+		values := []string{profileValue(profile[key])}
+		if flag.Value.Type() == "stringArray" {
+			values = profileValues(profile[key])
+		}
+		for _, value := range values {
+			if err := cmd.Flags().Set(key, value); err != nil {
+				return fmt.Errorf("profile %q: invalid value for %s: %v", name, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// definesFlag reports whether cmd or any of its subcommands has the flag name
+func definesFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if definesFlag(sub, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadFileConfig reads the config file, returning nil when none exists
+func loadFileConfig() (*fileConfig, string, error) {
+	paths := []string{configFile}
+	if configFile == "" {
+		paths = []string{defaultConfigName}
+		if dir, err := os.UserConfigDir(); err == nil {
+			paths = append(paths, filepath.Join(dir, "gotablestats", "config.yaml"))
+		}
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) && configFile == "" {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read config: %w", err)
+		}
+
+		config := &fileConfig{}
+		if err := yaml.Unmarshal(data, config); err != nil {
+			return nil, "", fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+		return config, path, nil
+	}
+	return nil, "", nil
+}
+
+// profileValue converts a YAML value into its flag string form
+func profileValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		parts := make([]string, len(list))
+		for i, item := range list {
+			parts[i] = scalarValue(item)
+		}
+		return strings.Join(parts, ",")
+	}
+	return scalarValue(value)
+}
+
+// profileValues converts a YAML list or map into one flag value per item,
+// writing map entries as key=value
+func profileValues(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = scalarValue(item)
+		}
+		return values
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		values := make([]string, len(keys))
+		for i, key := range keys {
+			values[i] = key + "=" + scalarValue(v[key])
+		}
+		return values
+	default:
+		return []string{scalarValue(value)}
+	}
+}
+
+// scalarValue writes a YAML scalar as a flag value. Floats are written in
+// full, as fmt would write 1e6 as 1e+06, which integer flags reject.
+func scalarValue(value interface{}) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// profileCommand returns a command with flags of each kind profiles set,
+// next to a command with a flag of its own
+func profileCommand() *cobra.Command {
+	root := &cobra.Command{Use: "root"}
+	other := &cobra.Command{Use: "other", Run: func(*cobra.Command, []string) {}}
+	other.Flags().Float64("max-null-change", 0, "")
+	cmd := &cobra.Command{Use: "test", Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(cmd, other)
+	cmd.Flags().Int("sample-size", 1000, "")
+	cmd.Flags().Float64("confidence", 0.95, "")
+	cmd.Flags().String("delimiter", "", "")
+	cmd.Flags().StringSlice("columns", nil, "")
+	cmd.Flags().StringArray("pattern", nil, "")
+	return cmd
+}
+
+// useConfig points the config lookup at a file holding content and selects
+// profile, restoring both afterwards
+func useConfig(t *testing.T, content, profile string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	oldFile, oldProfile := configFile, profileName
+	t.Cleanup(func() { configFile, profileName = oldFile, oldProfile })
+	configFile, profileName = path, profile
+}
+
+func TestApplyProfile(t *testing.T) {
+	const config = `default_profile: quick
+profiles:
+  quick:
+    sample-size: 200
+    delimiter: ";"
+    max-null-change: 5
+  ci:
+    sample-size: 1e6
+    confidence: 0.0000001
+    columns: [id, amount]
+    pattern:
+      email: "@"
+      code: "^[A-Z]+$"
+`
+	tests := []struct {
+		name     string
+		profile  string
+		args     []string
+		expected map[string]string
+	}{
+		{
+			name:     "default profile, skipping flags of other commands",
+			expected: map[string]string{"sample-size": "200", "delimiter": ";", "confidence": "0.95"},
+		},
+		{
+			name:     "selected profile",
+			profile:  "ci",
+			expected: map[string]string{"sample-size": "1000000", "confidence": "1e-07", "delimiter": "", "columns": "[id,amount]"},
+		},
+		{
+			name:     "command line wins",
+			profile:  "ci",
+			args:     []string{"--sample-size", "50", "--columns", "name"},
+			expected: map[string]string{"sample-size": "50", "columns": "[name]", "confidence": "1e-07"},
+		},
+		{
+			name:     "command line wins over default profile",
+			args:     []string{"--delimiter", "|"},
+			expected: map[string]string{"sample-size": "200", "delimiter": "|"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, config, tt.profile)
+			cmd := profileCommand()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags failed: %v", err)
+			}
+			if err := applyProfile(cmd); err != nil {
+				t.Fatalf("applyProfile failed: %v", err)
+			}
+			for name, want := range tt.expected {
+				if got := cmd.Flags().Lookup(name).Value.String(); got != want {
+					t.Errorf("Expected %s %q, got %q", name, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyProfile_RepeatableFlag(t *testing.T) {
+	useConfig(t, "profiles:\n  ci:\n    pattern:\n      email: '@'\n      code: '^[A-Z]+$'\n", "ci")
+	cmd := profileCommand()
+	if err := applyProfile(cmd); err != nil {
+		t.Fatalf("applyProfile failed: %v", err)
+	}
+	patterns, _ := cmd.Flags().GetStringArray("pattern")
+	// Map entries are set in key order, one flag value each
+	if expected := []string{"code=^[A-Z]+$", "email=@"}; !reflect.DeepEqual(patterns, expected) {
+		t.Errorf("Expected patterns %q, got %q", expected, patterns)
+	}
+}
+
+func TestApplyProfile_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		profile  string
+		expected string
+	}{
+		{"unknown profile", "profiles:\n  quick:\n    sample-size: 200\n", "ci", `profile "ci" not found`},
+		{"unknown default profile", "default_profile: ci\nprofiles: {}\n", "", `profile "ci" not found`},
+		{"invalid value", "profiles:\n  quick:\n    sample-size: many\n", "quick", "invalid value for sample-size"},
+		{"fractional integer", "profiles:\n  quick:\n    sample-size: 2.5\n", "quick", "invalid value for sample-size"},
+		{"invalid YAML", "profiles: [\n", "quick", "failed to parse config"},
+		{"unknown setting", "profiles:\n  quick:\n    sampel-size: 200\n", "quick", `unknown setting "sampel-size"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useConfig(t, tt.config, tt.profile)
+			if err := applyProfile(profileCommand()); err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected an error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadFileConfig_Lookup(t *testing.T) {
+	oldFile, oldProfile := configFile, profileName
+	t.Cleanup(func() { configFile, profileName = oldFile, oldProfile })
+	configFile, profileName = "", ""
+
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Chdir(t.TempDir())
+
+	// Without any config file, profiles are optional but cannot be requested
+	if config, _, err := loadFileConfig(); config != nil || err != nil {
+		t.Fatalf("Expected no config, got %+v, %v", config, err)
+	}
+	profileName = "quick"
+	if err := applyProfile(profileCommand()); err == nil || !strings.Contains(err.Error(), "no config file was found") {
+		t.Errorf("Expected a missing config error, got %v", err)
+	}
+
+	userPath := filepath.Join(home, "gotablestats", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(userPath), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(userPath, []byte("default_profile: user\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if config, path, err := loadFileConfig(); err != nil || path != userPath || config.DefaultProfile != "user" {
+		t.Errorf("Expected the user config, got %+v from %q, %v", config, path, err)
+	}
+
+	// The working directory's file is preferred over the user's
+	if err := os.WriteFile(defaultConfigName, []byte("default_profile: local\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if config, path, err := loadFileConfig(); err != nil || path != defaultConfigName || config.DefaultProfile != "local" {
+		t.Errorf("Expected the working directory config, got %+v from %q, %v", config, path, err)
+	}
+
+	// An explicit --config must exist
+	configFile = filepath.Join(home, "missing.yaml")
+	if _, _, err := loadFileConfig(); err == nil {
+		t.Error("Expected error for a missing --config file")
+	}
+}
+
+func TestScalarValue(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{1e6, "1000000"},
+		{0.0000001, "0.0000001"},
+		{2.5, "2.5"},
+		{200, "200"},
+		{true, "true"},
+		{"a,b", "a,b"},
+	}
+	for _, tt := range tests {
+		if got := scalarValue(tt.value); got != tt.expected {
+			t.Errorf("Expected %v to be written as %q, got %q", tt.value, tt.expected, got)
+		}
+	}
+	if got := profileValue([]interface{}{1e6, "x"}); got != "1000000,x" {
+		t.Errorf("Expected list 1000000,x, got %q", got)
+	}
+}
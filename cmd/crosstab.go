@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	crosstabRows    string
+	crosstabCols    string
+	crosstabPercent string
+	crosstabFormat  string
+)
+
+// crosstabCmd counts the rows of a file by the values of two columns
+var crosstabCmd = &cobra.Command{
+	Use:   "crosstab <file>",
+	Short: "Count the rows of a file by the values of two categorical columns",
+	Long: `Count the rows of a file for each combination of the values of two
+categorical columns, as a contingency table with the totals of each row and
+column. Every cell also shows its share of all rows, or with --percent of its
+row or column.
+
+The 20 most frequent values of each column are listed apart; the rest are
+counted as (other), and null values as (null). Like column, only the two
+columns are kept, so the default sample is 100,000 rows.`,
+	Example: `  gotablestats crosstab data.csv --rows region --cols status
+  gotablestats crosstab data.csv --rows region --cols status --percent row`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if crosstabFormat != "text" && crosstabFormat != "json" {
+			log.Fatalf("unsupported crosstab format %q (use text or json)", crosstabFormat)
+		}
+		percent, err := tablestats.ParseCrossTabPercent(crosstabPercent)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if crosstabRows == crosstabCols {
+			log.Fatal("--rows and --cols must name different columns")
+		}
+		if !cmd.Flags().Changed("sample-size") {
+			sampleSize = columnSampleSize
+		}
+		columns = []string{crosstabRows, crosstabCols}
+		excludes = nil
+		config := samplingConfig()
+
+		sample, err := readSample(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+		table, err := tablestats.CrossTabulate(sample, crosstabRows, crosstabCols)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if crosstabFormat == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(table)
+		} else {
+			err = table.WriteText(os.Stdout, numberFormat(), percent)
+		}
+		if err != nil {
+			log.Fatalf("Error writing output: %v", err)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(crosstabCmd.Flags())
+	crosstabCmd.Flags().MarkHidden("columns")
+	crosstabCmd.Flags().MarkHidden("exclude-columns")
+	crosstabCmd.Flags().MarkHidden("weight-column")
+	crosstabCmd.Flags().StringVar(&crosstabRows, "rows", "", "Column whose values label the rows, e.g. region")
+	crosstabCmd.Flags().StringVar(&crosstabCols, "cols", "", "Column whose values label the columns, e.g. status")
+	crosstabCmd.MarkFlagRequired("rows")
+	crosstabCmd.MarkFlagRequired("cols")
+	crosstabCmd.Flags().StringVar(&crosstabPercent, "percent", "total", "What the percentages are shares of (total, row or col)")
+	crosstabCmd.Flags().StringVarP(&crosstabFormat, "format", "f", "text", "Output format (text or json)")
+	crosstabCmd.Flags().StringVar(&locale, "locale", "", "Locale for thousands and decimal separators, e.g. de-DE (default from LC_ALL, LC_NUMERIC or LANG)")
+	crosstabCmd.Flags().BoolVar(&siNumbers, "si", false, "Abbreviate large numbers with SI prefixes, e.g. 1.2M")
+	rootCmd.AddCommand(crosstabCmd)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"log"
+	"os"
+
+	"github.com/WindowGenerator/gotablestats/internal/daemon"
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	daemonOnce        bool
+	daemonMaxDownload int64
+)
+
+// daemonCmd profiles the datasets of a config file on their schedules
+var daemonCmd = &cobra.Command{
+	Use:   "daemon <datasets.yaml>",
+	Short: "Profile datasets on cron-style schedules, check them and notify on failures",
+	Long: `Profile the datasets listed in a YAML file on cron-style schedules:
+
+  datasets:
+    - name: orders
+      path: /data/orders.csv          # or glob: /data/part-*.csv, url: https://...,
+                                      # or table: public.orders with driver and dsn
+      schedule: "0 * * * *"           # minute hour day-of-month month day-of-week,
+                                      # @hourly, @daily, @weekly, @monthly or @every 15m
+      rules: orders-rules.yaml        # as for check --rules
+      expect: orders-expect.yaml      # as for check --expect
+      sample_size: 5000
+      on_failure: ./page-oncall.sh    # run through sh when a check fails
+
+Every profile is recorded in the history store (see 'gotablestats history').
+The on_failure command gets GOTABLESTATS_DATASET, GOTABLESTATS_SOURCE and
+GOTABLESTATS_FAILURES (one failure per line) in its environment. The sampling
+and parsing flags set the defaults for every dataset. Tables are read through
+database/sql drivers, which must be compiled into the binary.`,
+	Example: `  gotablestats daemon datasets.yaml
+  gotablestats daemon datasets.yaml --once`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := daemon.LoadConfig(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts, err := readerOptions()
+		if err != nil {
+			log.Fatal(err)
+		}
+		d := &daemon.Daemon{
+			Config:      samplingConfig(),
+			Options:     opts,
+			Pushgateway: pushgateway(),
+			MaxDownload: daemonMaxDownload,
+		}
+		if !noHistory && historyDir != "" {
+			d.History = tablestats.NewHistoryStore(historyDir)
+		}
+
+		if daemonOnce {
+			failed := false
+			for _, ds := range config.Datasets {
+				for _, r := range d.RunDataset(cmd.Context(), ds) {
+					failed = failed || r.Failed()
+				}
+			}
+			if failed {
+				os.Exit(1)
+			}
+			return
+		}
+
+		log.Printf("Scheduling %d datasets", len(config.Datasets))
+		if err := d.Run(cmd.Context(), config.Datasets); err != nil && !errors.Is(err, cmd.Context().Err()) {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(daemonCmd.Flags())
+	addHistoryFlags(daemonCmd.Flags())
+	addPushFlags(daemonCmd.Flags())
+	daemonCmd.Flags().BoolVar(&daemonOnce, "once", false, "Run every dataset once and exit, with a nonzero code when any failed")
+	daemonCmd.Flags().Int64Var(&daemonMaxDownload, "max-download", daemon.DefaultMaxDownload, "Max size of downloaded url datasets (bytes)")
+	rootCmd.AddCommand(daemonCmd)
+}
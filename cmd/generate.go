@@ -0,0 +1,325 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/internal/generator"
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+// rowCount is an int flag that also accepts scientific notation such as 1e6
+type rowCount int
+
+func (c *rowCount) String() string { return strconv.Itoa(int(*c)) }
+func (c *rowCount) Type() string   { return "rows" }
+
+func (c *rowCount) Set(s string) error {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v < 0 || v != float64(int(v)) {
+		return fmt.Errorf("invalid row count %q", s)
+	}
+	*c = rowCount(v)
+	return nil
+}
+
+var (
+	genRows     = rowCount(1000000)
+	genOutput   string
+	genWorkers  int
+	genSchema   string
+	genSeed     int64
+	genNulls    map[string]string
+	genDirty    map[string]string
+	genDupRate  float64
+	genBadRate  float64
+	genFormat   string
+	genCompress string
+	genDists    []string
+
+	genTimeSeries bool
+	genTS         = generator.TimeSeriesOptions{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	genStart      string
+)
+
+// generateCmd writes a synthetic dataset for testing the profiler
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a synthetic CSV, TSV, JSON Lines or Parquet dataset",
+	Example: `  gotablestats generate --rows 1e6 --output big_data.csv
+  gotablestats generate --rows 10000 --schema schema.yaml --output fixture.csv
+  gotablestats generate --rows 10000 --seed 42 --output fixture.csv
+  gotablestats generate --rows 10000 --null-rate age=0.05 --dirty-rate email=0.01
+  gotablestats generate --rows 10000 --duplicate-rate 0.02
+  gotablestats generate --rows 10000 --malformed-rate 0.001
+  gotablestats generate --rows 10000 --dist 'salary=lognormal(10.5,0.4)' --dist department='zipf(1.5)'
+  gotablestats generate --rows 1e6 --output big_data.parquet
+  gotablestats generate --rows 1e6 --timeseries --interval 1m --series 4 --gap-rate 0.001
+  gotablestats generate --rows 1e8 --compress zstd --output big_data.csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// A random seed is still printed so that the run can be repeated
+		seed := genSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		rows := int(genRows)
+
+		var gen generator.RowGenerator = generator.Employees{}
+		var series *generator.TimeSeries
+		if genTimeSeries {
+			if genSchema != "" {
+				log.Fatal("--timeseries and --schema cannot be combined")
+			}
+			if genStart != "" {
+				start, err := parseStart(genStart)
+				if err != nil {
+					log.Fatal(err)
+				}
+				genTS.Start = start
+			}
+			var err error
+			if series, err = generator.NewTimeSeries(genTS, rows, seed); err != nil {
+				log.Fatal(err)
+			}
+			gen = series
+		}
+		if genSchema != "" {
+			schema, err := tablestats.LoadSchema(genSchema)
+			if err != nil {
+				log.Fatal(err)
+			}
+			gen, err = generator.NewSchemaGenerator(schema)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if len(genDists) > 0 {
+			dist, err := generator.NewDistributor(gen, columnDistributions(genDists))
+			if err != nil {
+				log.Fatal(err)
+			}
+			gen = dist
+		}
+		if len(genNulls) > 0 || len(genDirty) > 0 {
+			inj, err := generator.NewInjector(gen, columnRates(genNulls), columnRates(genDirty))
+			if err != nil {
+				log.Fatal(err)
+			}
+			gen = inj
+		}
+
+		config := generator.Config{
+			Rows:          rows,
+			Workers:       genWorkers,
+			Seed:          seed,
+			DuplicateRate: genDupRate,
+			MalformedRate: genBadRate,
+		}
+		writeDataset(cmd.Context(), gen, config, genOutput, genFormat, genCompress, func() {
+			if genDupRate > 0 {
+				fmt.Printf("🔁 Duplicate rows: %d\n", config.DuplicateRows())
+			}
+			if genBadRate > 0 {
+				fmt.Printf("🧨 Malformed rows: %d\n", config.MalformedRows())
+			}
+			if series != nil {
+				gaps, missing := series.Gaps()
+				fmt.Printf("⏸️  Gaps: %d (%d missing intervals)\n", gaps, missing)
+			}
+		})
+	},
+}
+
+func init() {
+	generateCmd.Flags().VarP(&genRows, "rows", "n", "Number of rows to generate (e.g. 1e6)")
+	generateCmd.Flags().StringVarP(&genOutput, "output", "o", "big_data.csv", "Output filename")
+	generateCmd.Flags().IntVarP(&genWorkers, "workers", "w", 4, "Number of worker goroutines")
+	generateCmd.Flags().StringVar(&genSchema, "schema", "", "Schema file (YAML) describing the columns to generate")
+	generateCmd.Flags().Float64Var(&genBadRate, "malformed-rate", 0, "Share of rows to write with a wrong field count, an unbalanced quote or an embedded newline, e.g. 0.001")
+	generateCmd.Flags().StringArrayVar(&genDists, "dist", nil, "Draw a column from a distribution: lognormal(mu,sigma), exponential(mean) or zipf(s[,n]), e.g. salary=lognormal(10.5,0.4) (repeatable)")
+	generateCmd.Flags().StringToStringVar(&genNulls, "null-rate", nil, "Share of values to leave empty per column, e.g. age=0.05")
+	generateCmd.Flags().StringToStringVar(&genDirty, "dirty-rate", nil, "Share of values to corrupt per column, e.g. email=0.01")
+	generateCmd.Flags().Float64Var(&genDupRate, "duplicate-rate", 0, "Share of rows to replace by copies of earlier rows, e.g. 0.02")
+	generateCmd.Flags().BoolVar(&genTimeSeries, "timeseries", false, "Generate timestamped readings (timestamp, series, value) instead of employees")
+	generateCmd.Flags().StringVar(&genStart, "start", "", "First timestamp of --timeseries, RFC 3339 or YYYY-MM-DD (default 2024-01-01)")
+	generateCmd.Flags().DurationVar(&genTS.Interval, "interval", time.Minute, "Time between readings of a series")
+	generateCmd.Flags().IntVar(&genTS.Series, "series", 1, "Number of interleaved series")
+	generateCmd.Flags().DurationVar(&genTS.Period, "period", 24*time.Hour, "Seasonality period of the values (0 disables)")
+	generateCmd.Flags().Float64Var(&genTS.Amplitude, "amplitude", 20, "Seasonal swing of the values around their level of 100")
+	generateCmd.Flags().Float64Var(&genTS.GapRate, "gap-rate", 0, "Share of timestamps preceded by a gap in the readings, e.g. 0.001")
+	generateCmd.Flags().IntVar(&genTS.GapLength, "gap-length", 10, "Maximum number of missing intervals per gap")
+	generateCmd.Flags().StringVar(&genFormat, "format", "", "Output format: csv, tsv, jsonl or parquet (default: from the output extension)")
+	generateCmd.Flags().StringVar(&genCompress, "compress", "", "Compress the output: gzip or zstd (default: from the output extension); the extension is added if missing")
+	generateCmd.Flags().Int64Var(&genSeed, "seed", 0, "Seed for reproducible output, independent of --workers (0 picks a random seed)")
+	rootCmd.AddCommand(generateCmd)
+}
+
+// writeDataset generates config.Rows rows from gen into output, picking the
+// format and compression from the output name unless they are given, and
+// prints a summary. report, if not nil, adds generator-specific lines to it.
+func writeDataset(ctx context.Context, gen generator.RowGenerator, config generator.Config, output, format, compression string, report func()) {
+	if compression == "" {
+		compression = generator.CompressionFromPath(output)
+	} else if !slices.Contains(generator.Compressions, compression) {
+		log.Fatalf("unsupported compression %q (use %s)", compression, strings.Join(generator.Compressions, ", "))
+	}
+	output = generator.CompressedPath(output, compression)
+
+	if format == "" {
+		format = generator.FormatFromPath(output)
+	}
+	if !slices.Contains(generator.Formats, format) {
+		log.Fatalf("unsupported format %q (use %s)", format, strings.Join(generator.Formats, ", "))
+	}
+
+	rows := config.Rows
+	fmt.Printf("Generating %s with %d rows...\n", strings.ToUpper(format), rows)
+	fmt.Printf("Output file: %s\n", output)
+	if compression != "" {
+		fmt.Printf("Compression: %s\n", compression)
+	}
+	fmt.Printf("Workers: %d\n", config.Workers)
+	fmt.Printf("Seed: %d\n", config.Seed)
+
+	startTime := time.Now()
+
+	file, err := os.Create(output)
+	if err != nil {
+		log.Fatalf("Error creating file: %v", err)
+	}
+	defer file.Close()
+	out, err := generator.NewCompressor(file, compression)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	progressInterval := rows / 10
+	lastReported := 0
+	config.Format = format
+	config.Progress = func(written, total int) {
+		if written-lastReported >= progressInterval || written == total {
+			fmt.Printf("Progress: %d%% (%d/%d rows)\n", written*100/total, written, total)
+			lastReported = written
+		}
+	}
+	if err := generator.Generate(ctx, out, gen, config); err != nil {
+		log.Fatalf("Error generating data: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatalf("Error compressing data: %v", err)
+	}
+
+	duration := time.Since(startTime)
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		log.Fatalf("Error getting file stats: %v", err)
+	}
+
+	fmt.Printf("\n✅ %s generation complete!\n", strings.ToUpper(format))
+	fmt.Printf("📄 File: %s\n", output)
+	if format == "csv" || format == "tsv" {
+		fmt.Printf("📊 Rows: %d (plus header)\n", rows)
+	} else {
+		fmt.Printf("📊 Rows: %d\n", rows)
+	}
+	if report != nil {
+		report()
+	}
+	fmt.Printf("💾 Size: %.2f MB\n", float64(fileInfo.Size())/1024/1024)
+	fmt.Printf("⏱️  Time: %v\n", duration)
+	fmt.Printf("🚀 Speed: %.0f rows/second\n", float64(rows)/duration.Seconds())
+
+	if format != "parquet" {
+		fmt.Println("\nSample data:")
+		showSample(output, format, compression)
+	}
+}
+
+// columnRates parses the values of a column=rate flag
+func columnRates(flags map[string]string) map[string]float64 {
+	rates := make(map[string]float64, len(flags))
+	for column, value := range flags {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Fatalf("invalid rate %q for column %q", value, column)
+		}
+		rates[column] = rate
+	}
+	return rates
+}
+
+// parseStart reads a timestamp given as RFC 3339 or as a date
+func parseStart(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return t, fmt.Errorf("invalid start %q (use RFC 3339 or YYYY-MM-DD)", s)
+	}
+	return t, nil
+}
+
+// columnDistributions parses the values of the --dist flag
+func columnDistributions(args []string) map[string]generator.Distribution {
+	dists := make(map[string]generator.Distribution, len(args))
+	for _, arg := range args {
+		column, spec, ok := strings.Cut(arg, "=")
+		if !ok || column == "" {
+			log.Fatalf("invalid distribution %q (use column=kind(params))", arg)
+		}
+		dist, err := generator.ParseDistribution(spec)
+		if err != nil {
+			log.Fatalf("invalid distribution for column %q: %v", column, err)
+		}
+		dists[column] = dist
+	}
+	return dists
+}
+
+// showSample prints the first lines of a text output file
+func showSample(filename, format, compression string) {
+	file, err := os.Open(filename)
+	if err != nil {
+		fmt.Printf("Error opening file for sample: %v\n", err)
+		return
+	}
+	defer file.Close()
+	r, err := generator.NewDecompressor(file, compression)
+	if err != nil {
+		fmt.Printf("Error opening file for sample: %v\n", err)
+		return
+	}
+	defer r.Close()
+
+	if format == "jsonl" {
+		scanner := bufio.NewScanner(r)
+		for i := 0; i < 5 && scanner.Scan(); i++ {
+			fmt.Println(scanner.Text())
+		}
+		return
+	}
+
+	reader := csv.NewReader(r)
+	if format == "tsv" {
+		reader.Comma = '\t'
+	}
+	for i := 0; i < 5; i++ {
+		record, err := reader.Read()
+		if err != nil {
+			break
+		}
+		fmt.Printf("%s\n", record)
+	}
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	historyDir    string
+	noHistory     bool
+	historyFormat string
+	historyLast   int
+)
+
+// historyCmd reports how the profile of a file evolved across recorded runs
+var historyCmd = &cobra.Command{
+	Use:   "history <file>",
+	Short: "Show how a file's row count, null rates and aggregates evolved across runs",
+	Long: `Show how a file's row count, null rates and aggregates evolved across runs.
+
+Every profile analyze and compare compute is recorded in the history store
+(--history-dir); profiles returned from the cache are not recorded again. The
+text format draws a sparkline per metric with its first and latest value; the
+csv format writes a row per run, for spreadsheets and plotting.`,
+	Example: `  gotablestats history feed.csv
+  gotablestats history feed.csv --last 30 --format csv > trend.csv`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if historyFormat != "text" && historyFormat != "csv" {
+			log.Fatalf("unsupported history format %q (use text or csv)", historyFormat)
+		}
+		if historyLast < 0 {
+			log.Fatal("last must not be negative")
+		}
+		runs, err := tablestats.NewHistoryStore(historyDir).Runs(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if historyLast > 0 && len(runs) > historyLast {
+			runs = runs[len(runs)-historyLast:]
+		}
+
+		trend := tablestats.BuildTrend(runs)
+		if historyFormat == "csv" {
+			err = trend.WriteCSV(os.Stdout)
+		} else {
+			err = trend.WriteText(os.Stdout, args[0])
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	addHistoryDirFlag(historyCmd.Flags())
+	historyCmd.Flags().StringVarP(&historyFormat, "format", "f", "text", "Output format (text or csv)")
+	historyCmd.Flags().IntVar(&historyLast, "last", 0, "Only show the latest runs (0 = all)")
+	rootCmd.AddCommand(historyCmd)
+}
+
+// addHistoryDirFlag registers the flag that locates the history store
+func addHistoryDirFlag(flags *pflag.FlagSet) {
+	flags.StringVar(&historyDir, "history-dir", defaultHistoryDir(), "Directory holding the recorded runs")
+}
+
+// addHistoryFlags registers the flags that control recording runs
+func addHistoryFlags(flags *pflag.FlagSet) {
+	addHistoryDirFlag(flags)
+	flags.BoolVar(&noHistory, "no-history", false, "Do not record this run in the history store")
+}
+
+// defaultHistoryDir keeps the history next to the baselines
+func defaultHistoryDir() string {
+	return filepath.Join(filepath.Dir(defaultStoreDir()), "history")
+}
+
+// recordRun adds a freshly computed profile to the history store, when the
+// command records runs. Failing to record only warns.
+func recordRun(filePath string, stats *tablestats.TableStats) {
+	if noHistory || historyDir == "" {
+		return
+	}
+	if err := tablestats.NewHistoryStore(historyDir).Append(filePath, stats, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/internal/notify"
+	"github.com/spf13/pflag"
+)
+
+var (
+	notifyWebhook string
+	notifySlack   string
+	reportURL     string
+)
+
+// addNotifyFlags registers the flags that post failed checks to webhooks
+func addNotifyFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&notifyWebhook, "notify-webhook", "", "POST a JSON summary of failed checks to this URL")
+	flags.StringVar(&notifySlack, "notify-slack", "", "Post a summary of failed checks to this Slack incoming webhook URL")
+	flags.StringVar(&reportURL, "report-url", "", "Link to the full report to include in notifications, e.g. the CI job URL")
+}
+
+// notifyFailure posts the failures of a check of source to the configured
+// webhooks. Failing to notify only warns, so the check's own exit code stands.
+func notifyFailure(ctx context.Context, check, source string, failures []string) {
+	senders := notify.New(notifyWebhook, notifySlack)
+	if len(senders) == 0 || len(failures) == 0 {
+		return
+	}
+	msg := &notify.Message{Check: check, Source: source, Failures: failures, ReportURL: reportURL, Time: time.Now().UTC()}
+	if err := notify.SendAll(ctx, senders, msg); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
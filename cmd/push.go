@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/internal/push"
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/pflag"
+)
+
+var (
+	pushgatewayURL string
+	pushgatewayJob string
+)
+
+// addPushFlags registers the flags that push run metrics to a Pushgateway
+func addPushFlags(flags *pflag.FlagSet) {
+	flags.StringVar(&pushgatewayURL, "pushgateway", "", "Push run metrics (duration, rows, null ratios, violations) to this Prometheus Pushgateway URL")
+	flags.StringVar(&pushgatewayJob, "pushgateway-job", push.DefaultJob, "Job label of the pushed metrics")
+}
+
+// pushgateway returns the Pushgateway of the flags, or nil when none is set
+func pushgateway() *push.Pushgateway {
+	if pushgatewayURL == "" {
+		return nil
+	}
+	return &push.Pushgateway{URL: pushgatewayURL, Job: pushgatewayJob}
+}
+
+// pushRun pushes the metrics of a run. Failing to push only warns, so the
+// command's own exit code stands.
+func pushRun(ctx context.Context, run *push.Run) {
+	p := pushgateway()
+	if p == nil {
+		return
+	}
+	if err := p.Push(ctx, run); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
+
+// newRun describes a run of command on source that started at start and
+// ended now. The run failed when there are failures.
+func newRun(command, source string, start time.Time, stats *tablestats.TableStats, failures []string) *push.Run {
+	return &push.Run{
+		Command:      command,
+		Source:       source,
+		Time:         start,
+		Duration:     time.Since(start),
+		Stats:        stats,
+		FailedChecks: len(failures),
+		Failed:       len(failures) > 0,
+	}
+}
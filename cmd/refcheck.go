@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+var maxMissingPct float64
+
+// refcheckCmd checks that every foreign key value of one file exists in another
+var refcheckCmd = &cobra.Command{
+	Use:   "refcheck <file:column> <file:column>",
+	Short: "Report foreign key values missing from a referenced file",
+	Example: `  gotablestats refcheck orders.csv:customer_id customers.csv:id
+  gotablestats refcheck orders.csv:customer_id customers.csv:id --max-missing-pct 0.1`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		child, err := columnRef(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		parent, err := columnRef(args[1])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		report, err := tablestats.RefCheck(cmd.Context(), child, parent)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+		if err := report.WriteText(os.Stdout, child, parent); err != nil {
+			log.Fatalf("Error writing output: %v", err)
+		}
+
+		if report.Missing > 0 && report.MissingPct() > maxMissingPct {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addReaderFlags(refcheckCmd.Flags())
+	refcheckCmd.Flags().Float64Var(&maxMissingPct, "max-missing-pct", 0, "Max percentage of missing foreign key values before failing")
+	rootCmd.AddCommand(refcheckCmd)
+}
+
+// columnRef parses a file:column argument. The last colon separates the
+// column, so Windows drive letters keep working.
+func columnRef(arg string) (tablestats.ColumnRef, error) {
+	i := strings.LastIndexByte(arg, ':')
+	if i <= 0 || i == len(arg)-1 {
+		return tablestats.ColumnRef{}, fmt.Errorf("invalid reference %q, expected file:column", arg)
+	}
+	path, column := arg[:i], arg[i+1:]
+
+	reader, err := newReader(path)
+	if err != nil {
+		return tablestats.ColumnRef{}, err
+	}
+	scanner, ok := reader.(tablestats.ColumnScanner)
+	if !ok {
+		return tablestats.ColumnRef{}, fmt.Errorf("%s reader does not support column scans", reader.GetFormatName())
+	}
+	return tablestats.ColumnRef{Reader: scanner, Path: path, Column: column}, nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sampleRows   int
+	sampleOutput string
+)
+
+// sampleCmd writes a representative sample of a file to a new file
+var sampleCmd = &cobra.Command{
+	Use:   "sample <file>",
+	Short: "Write a representative sample of a file with its header",
+	Example: `  gotablestats sample big.csv --rows 10000 --output sample.csv
+  gotablestats sample big.csv --rows 500 --weight-column amount > sample.csv`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sampleSize = sampleRows
+		config := samplingConfig()
+
+		sample, err := readSample(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+		// Small files are read entirely, so trim them down to the requested size
+		if weightCol != "" {
+			if err := sample.ShrinkWeighted(sampleRows, weightCol); err != nil {
+				log.Fatalf("Error sampling file: %v", err)
+			}
+		}
+		sample.Shrink(sampleRows)
+
+		var out io.Writer = os.Stdout
+		delimiter := ','
+		if sampleOutput != "" && sampleOutput != "-" {
+			file, err := os.Create(sampleOutput)
+			if err != nil {
+				log.Fatalf("Error creating output file: %v", err)
+			}
+			defer file.Close()
+			out = file
+			if strings.ToLower(filepath.Ext(sampleOutput)) == ".tsv" {
+				delimiter = '\t'
+			}
+		}
+
+		if err := sample.WriteCSV(out, delimiter); err != nil {
+			log.Fatalf("Error writing sample: %v", err)
+		}
+		log.Printf("Wrote %d sampled rows", sample.Len())
+	},
+}
+
+func init() {
+	addSamplingFlags(sampleCmd.Flags())
+	// --rows replaces --sample-size for this command
+	sampleCmd.Flags().MarkHidden("sample-size")
+	sampleCmd.Flags().IntVarP(&sampleRows, "rows", "n", 1000, "Number of rows to write")
+	sampleCmd.Flags().StringVarP(&sampleOutput, "output", "o", "", "Output file (.csv or .tsv); stdout when empty")
+	rootCmd.AddCommand(sampleCmd)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	schemaRows   int64
+	schemaFormat string
+)
+
+// schemaCmd infers only column names, types and nullability from the head of a file
+var schemaCmd = &cobra.Command{
+	Use:   "schema <file>",
+	Short: "Infer column names, types and nullability",
+	Example: `  gotablestats schema data.csv
+  gotablestats schema data.csv --format yaml > schema.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		config := tablestats.DefaultSamplingConfig()
+		config.Limit = schemaRows
+
+		sample, err := readSample(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+		schema := tablestats.InferSchema(sample)
+
+		switch schemaFormat {
+		case "text":
+			for _, col := range schema.Columns {
+				typ := col.Type
+				if typ == "" {
+					typ = "unknown"
+				}
+				nullable := "not null"
+				if col.IsNullable() {
+					nullable = "nullable"
+				}
+				fmt.Printf("%s\t%s\t%s\n", col.Name, typ, nullable)
+			}
+		case "yaml":
+			// The YAML form can be edited and passed to validate --schema
+			encoder := yaml.NewEncoder(os.Stdout)
+			encoder.SetIndent(2)
+			if err := encoder.Encode(schema); err != nil {
+				log.Fatalf("Error writing schema: %v", err)
+			}
+		default:
+			log.Fatalf("unsupported format %q (use text or yaml)", schemaFormat)
+		}
+	},
+}
+
+func init() {
+	addReaderFlags(schemaCmd.Flags())
+	schemaCmd.Flags().Int64VarP(&schemaRows, "rows", "n", 1000, "Number of leading rows to inspect")
+	schemaCmd.Flags().StringVarP(&schemaFormat, "format", "f", "text", "Output format (text or yaml)")
+	rootCmd.AddCommand(schemaCmd)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/internal/server"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/spf13/cobra"
+)
+
+var (
+	servePort      int
+	serveHost      string
+	serveDataDir   string
+	serveAllowURLs bool
+	serveMaxUpload int64
+	serveResults   string
+	serveFlight    int
+	serveMaxKept   int
+)
+
+// serveCmd runs the profiler as an HTTP API
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP API that profiles uploaded or referenced files",
+	Long: `Serve an HTTP API that profiles files and returns their JSON profiles:
+
+  POST /profile        Profile a multipart upload (field "file"), or a file
+                       given as JSON {"path": "..."} (inside --data-dir) or
+                       {"url": "..."} (with --allow-urls). The sampling
+                       options sample_size, positions, limit and columns can
+                       be given as query parameters.
+  GET  /profiles       List the stored profiles
+  GET  /profiles/{id}  Return a stored profile
+  GET  /               Web dashboard listing the profiled datasets, with
+                       per-column charts and the history of each dataset
+
+The server listens on 127.0.0.1 only; pass --host 0.0.0.0 to expose it to
+other machines. Profiles are kept in --results-dir, so they survive restarts. The sampling
+and parsing flags set the defaults for every request.
+
+With --flight-port, profiles are also served over Arrow Flight: the ticket
+profile/<id> returns a table with one row per column, and sample/<id> the
+sampled rows of profiles made with ?sample=true.`,
+	Example: `  gotablestats serve --port 8080
+  gotablestats serve --data-dir /data --allow-urls
+  gotablestats serve --host 0.0.0.0 --port 8080
+  curl -F file=@data.csv 'localhost:8080/profile?sample_size=5000'
+  curl -d '{"path": "sales/2024.csv"}' localhost:8080/profile
+  gotablestats serve --flight-port 8815`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts, err := readerOptions()
+		if err != nil {
+			log.Fatal(err)
+		}
+		srv := &server.Server{
+			Config:      samplingConfig(),
+			Options:     opts,
+			DataDir:     serveDataDir,
+			AllowURLs:   serveAllowURLs,
+			MaxUpload:   serveMaxUpload,
+			ResultsDir:  serveResults,
+			MaxProfiles: serveMaxKept,
+		}
+		if err := srv.Load(); err != nil {
+			log.Fatal(err)
+		}
+
+		httpServer := &http.Server{
+			Addr:              net.JoinHostPort(serveHost, strconv.Itoa(servePort)),
+			Handler:           srv.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		if serveFlight > 0 {
+			flightAddr := net.JoinHostPort(serveHost, strconv.Itoa(serveFlight))
+			flightServer := flight.NewServerWithMiddleware(nil)
+			if err := flightServer.Init(flightAddr); err != nil {
+				log.Fatalf("failed to listen for Arrow Flight: %v", err)
+			}
+			flightServer.RegisterFlightService(srv.FlightService())
+			go func() {
+				if err := flightServer.Serve(); err != nil {
+					log.Fatalf("Arrow Flight server failed: %v", err)
+				}
+			}()
+			defer flightServer.Shutdown()
+			fmt.Printf("Arrow Flight on grpc://%s\n", dashboardHost(flightAddr))
+		}
+
+		go func() {
+			<-cmd.Context().Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			httpServer.Shutdown(ctx)
+		}()
+
+		fmt.Printf("Listening on %s, dashboard at http://%s/\n", httpServer.Addr, dashboardHost(httpServer.Addr))
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(serveCmd.Flags())
+	serveCmd.Flags().IntVar(&servePort, "port", 8080, "Port to listen on")
+	serveCmd.Flags().StringVar(&serveHost, "host", "127.0.0.1", "Address to listen on; 0.0.0.0 or '' exposes the server on all interfaces")
+	serveCmd.Flags().StringVar(&serveDataDir, "data-dir", "", "Directory that path references are resolved in (path references are disabled when empty)")
+	serveCmd.Flags().BoolVar(&serveAllowURLs, "allow-urls", false, "Allow profiling files fetched from http(s) URLs")
+	serveCmd.Flags().Int64Var(&serveMaxUpload, "max-upload", server.DefaultMaxUpload, "Max size of uploaded or fetched files (bytes)")
+	serveCmd.Flags().IntVar(&serveFlight, "flight-port", 0, "Also serve profiles over Arrow Flight on this port (0 = disabled)")
+	serveCmd.Flags().StringVar(&serveResults, "results-dir", defaultResultsDir(), "Directory holding the profiles of the server (in memory only when empty)")
+	serveCmd.Flags().IntVar(&serveMaxKept, "max-profiles", server.DefaultMaxProfiles, "Number of profiles kept in memory and listed, newest first")
+	rootCmd.AddCommand(serveCmd)
+}
+
+// defaultResultsDir keeps server profiles next to the baselines
+func defaultResultsDir() string {
+	return filepath.Join(filepath.Dir(defaultStoreDir()), "results")
+}
+
+// dashboardHost returns the address to open the dashboard at, localhost when
+// listening on all interfaces
+func dashboardHost(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || (host != "" && host != "0.0.0.0" && host != "::") {
+		return addr
+	}
+	return net.JoinHostPort("localhost", port)
+}
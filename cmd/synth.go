@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"log"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/internal/generator"
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+)
+
+var (
+	synthProfile  string
+	synthRows     rowCount
+	synthOutput   string
+	synthWorkers  int
+	synthSeed     int64
+	synthFormat   string
+	synthCompress string
+)
+
+// synthCmd generates a stand-in dataset from a saved profile
+var synthCmd = &cobra.Command{
+	Use:   "synth",
+	Short: "Generate synthetic data matching a saved profile",
+	Long: `Generate synthetic data whose columns match the types, null rates,
+cardinalities and percentiles of a profile saved with --format json. Apart
+from the categories listed in the profile, no values of the original data are
+used, so the output can stand in for a sensitive dataset.`,
+	Example: `  gotablestats analyze customers.csv --format json > stats.json
+  gotablestats synth --from-profile stats.json --rows 1e6 --output customers_synth.csv`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		stats, err := tablestats.LoadBaseline(synthProfile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		seed := synthSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		rows := int(synthRows)
+		if rows == 0 {
+			rows = int(max(stats.EstimatedRows, stats.RowCount))
+		}
+
+		gen, err := generator.NewProfileGenerator(stats)
+		if err != nil {
+			log.Fatal(err)
+		}
+		config := generator.Config{Rows: rows, Workers: synthWorkers, Seed: seed}
+		writeDataset(cmd.Context(), gen, config, synthOutput, synthFormat, synthCompress, nil)
+	},
+}
+
+func init() {
+	synthCmd.Flags().StringVar(&synthProfile, "from-profile", "", "Profile saved with --format json to match")
+	synthCmd.MarkFlagRequired("from-profile")
+	synthCmd.Flags().VarP(&synthRows, "rows", "n", "Number of rows to generate, e.g. 1e6 (default: the profile's estimated rows)")
+	synthCmd.Flags().StringVarP(&synthOutput, "output", "o", "synthetic.csv", "Output filename")
+	synthCmd.Flags().IntVarP(&synthWorkers, "workers", "w", 4, "Number of worker goroutines")
+	synthCmd.Flags().StringVar(&synthFormat, "format", "", "Output format: csv, tsv, jsonl or parquet (default: from the output extension)")
+	synthCmd.Flags().StringVar(&synthCompress, "compress", "", "Compress the output: gzip or zstd (default: from the output extension)")
+	synthCmd.Flags().Int64Var(&synthSeed, "seed", 0, "Seed for reproducible output (0 picks a random seed)")
+	rootCmd.AddCommand(synthCmd)
+}
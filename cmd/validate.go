@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	schemaFile      string
+	maxViolations   int64
+	maxViolationPct float64
+	reportFormat    string
+)
+
+// validateCmd checks a file against a YAML schema and fails on too many violations
+var validateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Check a file against a schema and report violations",
+	Example: `  gotablestats validate data.csv --schema schema.yaml
+  gotablestats validate data.csv --schema schema.yaml --max-violation-pct 0.5
+  gotablestats validate data.csv --schema schema.yaml --format gh-annotations`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		start := time.Now()
+		config := samplingConfig()
+		checkReportFormat()
+
+		schema, err := tablestats.LoadSchema(schemaFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if cmd.Flags().Changed("max-violations") {
+			schema.Thresholds.MaxViolations = maxViolations
+		}
+		if cmd.Flags().Changed("max-violation-pct") {
+			schema.Thresholds.MaxViolationPct = maxViolationPct
+		}
+
+		sample, err := readSample(cmd.Context(), args[0], config)
+		if err != nil {
+			log.Fatalf("Error processing file: %v%s", err, errorHint(err))
+		}
+
+		report := tablestats.Validate(sample, schema)
+		if reportFormat == "gh-annotations" {
+			writeAnnotations(report.Annotations(args[0], schema.Thresholds))
+		} else if err := report.WriteText(os.Stdout, schema.Thresholds); err != nil {
+			log.Fatalf("Error writing output: %v", err)
+		}
+
+		// Violations within the thresholds do not fail the run
+		var failures []string
+		if report.Failed(schema.Thresholds) {
+			failures = report.Failures()
+		}
+		if pushgateway() != nil {
+			run := newRun("validate", args[0], start, tablestats.AnalyzeSample(sample, config), failures)
+			run.Violations = report.TotalViolations()
+			pushRun(cmd.Context(), run)
+		}
+
+		if len(failures) > 0 {
+			notifyFailure(cmd.Context(), "validate", args[0], failures)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	addSamplingFlags(validateCmd.Flags())
+	validateCmd.Flags().StringVar(&schemaFile, "schema", "", "Schema file (YAML) (required)")
+	validateCmd.Flags().Int64Var(&maxViolations, "max-violations", 0, "Max number of violating values before failing")
+	validateCmd.Flags().Float64Var(&maxViolationPct, "max-violation-pct", 0, "Max percentage of violating values before failing")
+	addReportFormatFlag(validateCmd.Flags())
+	addNotifyFlags(validateCmd.Flags())
+	addPushFlags(validateCmd.Flags())
+	validateCmd.MarkFlagRequired("schema")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// addReportFormatFlag registers the output format of check reports
+func addReportFormatFlag(flags *pflag.FlagSet) {
+	flags.StringVarP(&reportFormat, "format", "f", "text", "Output format (text, or gh-annotations for GitHub Actions workflow commands)")
+}
+
+func checkReportFormat() {
+	if reportFormat != "text" && reportFormat != "gh-annotations" {
+		log.Fatalf("unsupported format %q (use text or gh-annotations)", reportFormat)
+	}
+}
+
+// writeAnnotations writes failed checks as GitHub Actions workflow commands
+func writeAnnotations(annotations []tablestats.Annotation) {
+	if err := tablestats.WriteGitHubAnnotations(os.Stdout, annotations); err != nil {
+		log.Fatalf("Error writing output: %v", err)
+	}
+}
+
+// readSample reads the raw rows of a file selected by the sampling config
+func readSample(ctx context.Context, filePath string, config tablestats.SamplingConfig) (*tablestats.Sample, error) {
+	reader, err := newReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleReader, ok := reader.(tablestats.SampleReader)
+	if !ok {
+		return nil, fmt.Errorf("%s reader does not support row access", reader.GetFormatName())
+	}
+	return sampleReader.ReadSample(ctx, filePath, withProgress(config, filePath))
+}
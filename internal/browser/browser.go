@@ -0,0 +1,80 @@
+// Package browser profiles files held in memory, for the WebAssembly build
+// that runs the profiler in a web page (see wasm/). It does not touch the file
+// system, so dropped files never leave the browser.
+package browser
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+// Options are the profiling options accepted from JavaScript, as JSON
+type Options struct {
+	Name       string   `json:"name"`       // File name; its extension picks the format when it cannot be sniffed
+	Format     string   `json:"format"`     // json (default), text, markdown or ydata
+	Delimiter  string   `json:"delimiter"`  // Detected when empty; "\t" or "tab" for tabs
+	Encoding   string   `json:"encoding"`   // utf-8 (default), utf-16le, latin1 or windows-1252
+	NoHeader   bool     `json:"noHeader"`   // The first row holds values
+	SampleSize int      `json:"sampleSize"` // Rows to sample from files over MaxSize
+	MaxSize    int64    `json:"maxSize"`    // Files up to this many bytes are profiled in full
+	Columns    []string `json:"columns"`    // Only profile these columns
+}
+
+// Profile profiles data and renders the result in opts.Format
+func Profile(ctx context.Context, data []byte, opts Options) ([]byte, error) {
+	format := opts.Format
+	if format == "" {
+		format = "json"
+	}
+	renderer, err := tablestats.NewRenderer(format, opts.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	readerOpts := tablestats.ReaderOptions{Encoding: opts.Encoding, NoHeader: opts.NoHeader}
+	switch opts.Delimiter {
+	case "":
+	case "\t", "\\t", "tab":
+		readerOpts.Delimiter = '\t'
+	default:
+		runes := []rune(opts.Delimiter)
+		if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+			return nil, fmt.Errorf("invalid delimiter %q", opts.Delimiter)
+		}
+		readerOpts.Delimiter = runes[0]
+	}
+
+	config := tablestats.DefaultSamplingConfig()
+	if opts.SampleSize < 0 || opts.MaxSize < 0 {
+		return nil, fmt.Errorf("sample size and max size must not be negative")
+	}
+	if opts.SampleSize > 0 {
+		config.SampleSize = opts.SampleSize
+	}
+	if opts.MaxSize > 0 {
+		config.MaxFileSize = opts.MaxSize
+	}
+	config.Columns = opts.Columns
+
+	reader, err := tablestats.NewReaderForContent(opts.Name, data, readerOpts)
+	if err != nil {
+		return nil, err
+	}
+	streamReader, ok := reader.(tablestats.StreamReader)
+	if !ok {
+		return nil, fmt.Errorf("%s files cannot be profiled in the browser: %w", reader.GetFormatName(), tablestats.ErrUnsupportedFormat)
+	}
+	stats, err := streamReader.ReadTableFrom(ctx, bytes.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, stats); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+const testCSV = "id;name;amount\n1;a;2.5\n2;b;\n3;c;7\n"
+
+func TestProfile(t *testing.T) {
+	out, err := Profile(context.Background(), []byte(testCSV), Options{Name: "orders.csv"})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	stats, err := tablestats.Unmarshal(out)
+	if err != nil {
+		t.Fatalf("Expected a JSON profile, got %v:\n%s", err, out)
+	}
+	if stats.RowCount != 3 {
+		t.Errorf("Expected 3 rows, got %d", stats.RowCount)
+	}
+	if stats.ColumnTypes["amount"] != "float64" {
+		t.Errorf("Expected amount to be float64, got %s", stats.ColumnTypes["amount"])
+	}
+
+	// Other formats, options and a delimiter that is not sniffed
+	tsv := strings.ReplaceAll(testCSV, ";", "\t")
+	out, err = Profile(context.Background(), []byte(tsv), Options{Format: "markdown", Delimiter: "tab", Columns: []string{"id"}})
+	if err != nil {
+		t.Fatalf("Profile failed: %v", err)
+	}
+	if !strings.Contains(string(out), "| id |") || strings.Contains(string(out), "amount") {
+		t.Errorf("Expected a markdown report of id only, got:\n%s", out)
+	}
+}
+
+func TestProfile_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		opts Options
+	}{
+		{"empty", "", Options{}},
+		{"format", testCSV, Options{Format: "xml"}},
+		{"delimiter", testCSV, Options{Delimiter: ";;"}},
+		{"sample size", testCSV, Options{SampleSize: -1}},
+		{"column", testCSV, Options{Columns: []string{"missing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Profile(context.Background(), []byte(tt.data), tt.opts); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	_, err := Profile(context.Background(), []byte("PAR1\x00\x01"), Options{Name: "data.parquet"})
+	if !errors.Is(err, tablestats.ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for Parquet, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package daemon
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"gopkg.in/yaml.v3"
+)
+
+// Config lists the datasets the daemon profiles:
+//
+//	slack: https://hooks.slack.com/services/...
+//	datasets:
+//	  - name: orders
+//	    path: /data/orders.csv
+//	    schedule: "0 * * * *"
+//	    rules: orders-rules.yaml
+//	    on_failure: ./page-oncall.sh
+//	  - name: events
+//	    glob: /data/events/*.csv
+//	    schedule: "@every 15m"
+//	    expect: events-expect.yaml
+//	    sample_size: 10000
+//	  - name: partner-feed
+//	    url: https://example.com/export/feed.csv
+//	    schedule: "@daily"
+//	  - name: customers
+//	    driver: postgres
+//	    dsn: postgres://reader@db/shop
+//	    table: public.customers
+//	    schedule: "@daily"
+type Config struct {
+	// Webhook, Slack and ReportURL apply to the datasets that do not set their own
+	Webhook   string `yaml:"webhook,omitempty"`
+	Slack     string `yaml:"slack,omitempty"`
+	ReportURL string `yaml:"report_url,omitempty"`
+
+	Datasets []*Dataset `yaml:"datasets"`
+}
+
+// Dataset is one scheduled profiling job. Exactly one of Path, Glob, URL and
+// Table locates the data; relative paths, including those of Rules and Expect,
+// are resolved against the directory of the config file.
+type Dataset struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path,omitempty"`
+	Glob string `yaml:"glob,omitempty"` // Every matching file is profiled on its own
+	URL  string `yaml:"url,omitempty"`  // http(s) URL, downloaded for each run
+	// Table is a database table, optionally schema qualified, read through
+	// the database/sql driver named Driver from the database at DSN. The
+	// driver must be registered in the binary.
+	Table    string `yaml:"table,omitempty"`
+	Driver   string `yaml:"driver,omitempty"`
+	DSN      string `yaml:"dsn,omitempty"`
+	Schedule string `yaml:"schedule"`
+
+	Rules      string `yaml:"rules,omitempty"`       // Quality rules file, as for check --rules
+	Expect     string `yaml:"expect,omitempty"`      // Expectations file, as for check --expect
+	SampleSize int    `yaml:"sample_size,omitempty"` // Overrides the daemon's sample size
+	// OnFailure is a shell command run when a check fails or the dataset
+	// cannot be profiled
+	OnFailure string `yaml:"on_failure,omitempty"`
+	// Webhook receives failed results as JSON, Slack as a Slack message
+	Webhook string `yaml:"webhook,omitempty"`
+	Slack   string `yaml:"slack,omitempty"`
+	// ReportURL is linked from notifications; {dataset} and {source} are
+	// replaced by the escaped dataset name and source
+	ReportURL string `yaml:"report_url,omitempty"`
+
+	schedule     Schedule
+	rules        *tablestats.Rules
+	expectations *tablestats.Expectations
+	db           *sql.DB // Opened for Table datasets
+}
+
+// tableName matches table names, optionally qualified by a schema, that can
+// be used in a query as they are
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// LoadConfig reads and validates a daemon config file, including the rules
+// and expectations it refers to
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daemon config: %w", err)
+	}
+	config := &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse daemon config: %w", err)
+	}
+	if len(config.Datasets) == 0 {
+		return nil, fmt.Errorf("daemon config %s lists no datasets", path)
+	}
+
+	dir := filepath.Dir(path)
+	names := make(map[string]bool)
+	for i, ds := range config.Datasets {
+		if ds.Name == "" {
+			return nil, fmt.Errorf("dataset %d has no name", i+1)
+		}
+		if names[ds.Name] {
+			return nil, fmt.Errorf("dataset name %q is used twice", ds.Name)
+		}
+		names[ds.Name] = true
+		if ds.Webhook == "" {
+			ds.Webhook = config.Webhook
+		}
+		if ds.Slack == "" {
+			ds.Slack = config.Slack
+		}
+		if ds.ReportURL == "" {
+			ds.ReportURL = config.ReportURL
+		}
+		if err := ds.prepare(dir); err != nil {
+			return nil, fmt.Errorf("dataset %q: %w", ds.Name, err)
+		}
+	}
+	return config, nil
+}
+
+// prepare validates a dataset and loads its schedule, rules and expectations
+func (ds *Dataset) prepare(dir string) error {
+	sources := 0
+	for _, s := range []string{ds.Path, ds.Glob, ds.URL, ds.Table} {
+		if s != "" {
+			sources++
+		}
+	}
+	switch {
+	case sources != 1:
+		return fmt.Errorf("exactly one of path, glob, url and table is required")
+	case (ds.Driver != "" || ds.DSN != "") && ds.Table == "":
+		return fmt.Errorf("driver and dsn are only used with table")
+	case ds.SampleSize < 0:
+		return fmt.Errorf("sample_size must not be negative")
+	}
+
+	var err error
+	if ds.schedule, err = ParseSchedule(ds.Schedule); err != nil {
+		return err
+	}
+	ds.Path = resolve(dir, ds.Path)
+	ds.Glob = resolve(dir, ds.Glob)
+	if ds.URL != "" {
+		if u, err := url.Parse(ds.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid url %q", ds.URL)
+		}
+	}
+	for _, hook := range []string{ds.Webhook, ds.Slack} {
+		if u, err := url.Parse(hook); hook != "" && (err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+			return fmt.Errorf("invalid webhook url")
+		}
+	}
+	if ds.Table != "" {
+		if err := ds.openTable(); err != nil {
+			return err
+		}
+	}
+	if ds.Glob != "" {
+		if _, err := filepath.Match(ds.Glob, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", ds.Glob, err)
+		}
+	}
+	if ds.Rules != "" {
+		if ds.rules, err = tablestats.LoadRules(resolve(dir, ds.Rules)); err != nil {
+			return err
+		}
+	}
+	if ds.Expect != "" {
+		if ds.expectations, err = tablestats.LoadExpectations(resolve(dir, ds.Expect)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openTable checks the table source of a dataset and opens its database. No
+// connection is made until the table is first read.
+func (ds *Dataset) openTable() error {
+	switch {
+	case !tableName.MatchString(ds.Table):
+		return fmt.Errorf("invalid table name %q", ds.Table)
+	case ds.Driver == "" || ds.DSN == "":
+		return fmt.Errorf("table requires driver and dsn")
+	case !slices.Contains(sql.Drivers(), ds.Driver):
+		return fmt.Errorf("database driver %q is not available in this build", ds.Driver)
+	}
+	var err error
+	if ds.db, err = sql.Open(ds.Driver, ds.DSN); err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	return nil
+}
+
+func resolve(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,52 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	start := time.Date(2024, 1, 31, 10, 17, 30, 0, time.UTC) // A Wednesday
+	tests := []struct {
+		spec     string
+		expected []string // Successive run times from start
+	}{
+		{"* * * * *", []string{"2024-01-31 10:18", "2024-01-31 10:19"}},
+		{"*/15 * * * *", []string{"2024-01-31 10:30", "2024-01-31 10:45", "2024-01-31 11:00"}},
+		{"0 9-17/4 * * *", []string{"2024-01-31 13:00", "2024-01-31 17:00", "2024-02-01 09:00"}},
+		{"30 2 1,15 * *", []string{"2024-02-01 02:30", "2024-02-15 02:30"}},
+		{"0 0 29 2 *", []string{"2024-02-29 00:00", "2028-02-29 00:00"}},
+		{"0 8 * * 1-5", []string{"2024-02-01 08:00", "2024-02-02 08:00", "2024-02-05 08:00"}},
+		{"0 0 * * 7", []string{"2024-02-04 00:00"}},
+		// Both day fields restricted: either matches
+		{"0 0 13 * 5", []string{"2024-02-02 00:00", "2024-02-09 00:00", "2024-02-13 00:00"}},
+		{"@daily", []string{"2024-02-01 00:00"}},
+		{"@monthly", []string{"2024-02-01 00:00", "2024-03-01 00:00"}},
+		{"@every 90m", []string{"2024-01-31 11:47", "2024-01-31 13:17"}},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.spec)
+		if err != nil {
+			t.Errorf("ParseSchedule(%q) failed: %v", tt.spec, err)
+			continue
+		}
+		next := start
+		for _, want := range tt.expected {
+			next = s.Next(next)
+			if got := next.Format("2006-01-02 15:04"); got != want {
+				t.Errorf("Expected %q to run at %s, got %s", tt.spec, want, got)
+				break
+			}
+		}
+	}
+
+	if s, _ := ParseSchedule("0 0 31 2 *"); !s.Next(start).IsZero() {
+		t.Errorf("Expected a schedule that never matches to have no next run")
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@every 0s", "@yearly"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
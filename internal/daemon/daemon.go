@@ -0,0 +1,415 @@
+// Package daemon profiles configured datasets on cron-style schedules, records
+// the profiles in the history store, evaluates quality rules and
+// expectations, and notifies on failures.
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/internal/notify"
+	"github.com/WindowGenerator/gotablestats/internal/push"
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+// DefaultMaxDownload is the default size limit of downloaded files
+const DefaultMaxDownload = 1 << 30
+
+// Daemon runs the datasets of a Config
+type Daemon struct {
+	Config  tablestats.SamplingConfig // Defaults for every dataset
+	Options tablestats.ReaderOptions
+
+	// History records every profile; nil keeps no results
+	History *tablestats.HistoryStore
+	// Notifiers are told about every failed result, after the dataset's
+	// on_failure command and webhooks
+	Notifiers []Notifier
+	// Pushgateway receives the metrics of every result; nil pushes none
+	Pushgateway *push.Pushgateway
+	// Client downloads URL datasets; nil uses a client with a ten minute timeout
+	Client *http.Client
+	// MaxDownload limits the size of downloaded files in bytes; 0 means
+	// DefaultMaxDownload
+	MaxDownload int64
+	// Logger reports runs and failures; nil uses the standard logger
+	Logger *log.Logger
+}
+
+// Result is the outcome of profiling one file of a dataset
+type Result struct {
+	Dataset      string
+	Source       string // File path, URL, or driver://table
+	Time         time.Time
+	Duration     time.Duration
+	Stats        *tablestats.TableStats
+	Rules        *tablestats.ValidationReport
+	Expectations *tablestats.ExpectationReport
+	Err          error // Why the source could not be profiled
+}
+
+// Failed reports whether the source could not be profiled or a check failed
+func (r *Result) Failed() bool {
+	return r.Err != nil ||
+		(r.Rules != nil && r.Rules.Failed(tablestats.SchemaThresholds{})) ||
+		(r.Expectations != nil && r.Expectations.Failed())
+}
+
+// Failures returns a line per violated rule, failed expectation or error
+func (r *Result) Failures() []string {
+	var lines []string
+	if r.Err != nil {
+		lines = append(lines, "error: "+r.Err.Error())
+	}
+	if r.Rules != nil {
+		lines = append(lines, r.Rules.Failures()...)
+	}
+	if r.Expectations != nil {
+		lines = append(lines, r.Expectations.Failures()...)
+	}
+	return lines
+}
+
+// Message returns the notification of a result, linking to reportURL. The
+// placeholders {dataset} and {source} in reportURL are replaced by the
+// escaped dataset name and source.
+func (r *Result) Message(reportURL string) *notify.Message {
+	return &notify.Message{
+		Check:    "daemon: " + r.Dataset,
+		Source:   r.Source,
+		Failures: r.Failures(),
+		ReportURL: strings.NewReplacer(
+			"{dataset}", url.QueryEscape(r.Dataset),
+			"{source}", url.QueryEscape(r.Source)).Replace(reportURL),
+		Time: r.Time,
+	}
+}
+
+// Run returns the metrics of a result
+func (r *Result) Run() *push.Run {
+	run := &push.Run{
+		Command:      "daemon",
+		Dataset:      r.Dataset,
+		Source:       r.Source,
+		Time:         r.Time,
+		Duration:     r.Duration,
+		Stats:        r.Stats,
+		FailedChecks: len(r.Failures()),
+		Failed:       r.Failed(),
+	}
+	if r.Err != nil {
+		run.FailedChecks--
+	}
+	if r.Rules != nil {
+		run.Violations = r.Rules.TotalViolations()
+	}
+	return run
+}
+
+// Summary describes a result in one line
+func (r *Result) Summary() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("%s: %s failed: %v", r.Dataset, r.Source, r.Err)
+	case r.Failed():
+		return fmt.Sprintf("%s: %s FAIL, %d checks failed", r.Dataset, r.Source, len(r.Failures()))
+	default:
+		return fmt.Sprintf("%s: %s PASS, %d rows profiled", r.Dataset, r.Source, r.Stats.RowCount)
+	}
+}
+
+// Run profiles every dataset on its schedule until ctx is cancelled
+func (d *Daemon) Run(ctx context.Context, datasets []*Dataset) error {
+	var wg sync.WaitGroup
+	for _, ds := range datasets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.schedule(ctx, ds)
+		}()
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// schedule runs a dataset at each of its scheduled times. Times that pass
+// while the dataset is being profiled are skipped.
+func (d *Daemon) schedule(ctx context.Context, ds *Dataset) {
+	next := ds.schedule.Next(time.Now())
+	if next.IsZero() {
+		d.logger().Printf("%s: schedule %q never runs", ds.Name, ds.Schedule)
+		return
+	}
+	d.logger().Printf("%s: next run at %s", ds.Name, next.Format(time.DateTime))
+	for !next.IsZero() {
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		d.RunDataset(ctx, ds)
+		next = ds.schedule.Next(time.Now())
+	}
+}
+
+// RunDataset profiles every file of a dataset once, records the profiles,
+// checks them, and runs the notifications of failed ones
+func (d *Daemon) RunDataset(ctx context.Context, ds *Dataset) []*Result {
+	var results []*Result
+	switch {
+	case ds.URL != "":
+		results = []*Result{d.profileURL(ctx, ds)}
+	case ds.Table != "":
+		results = []*Result{d.profileTable(ctx, ds)}
+	case ds.Glob != "":
+		files, err := filepath.Glob(ds.Glob)
+		if err == nil && len(files) == 0 {
+			err = fmt.Errorf("no files match")
+		}
+		if err != nil {
+			results = []*Result{{Dataset: ds.Name, Source: ds.Glob, Time: time.Now(), Err: err}}
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			results = append(results, d.profile(ctx, ds, file, file))
+		}
+	default:
+		results = []*Result{d.profile(ctx, ds, ds.Path, ds.Path)}
+	}
+
+	for _, r := range results {
+		d.logger().Print(r.Summary())
+		if ctx.Err() != nil {
+			continue
+		}
+		if r.Failed() {
+			d.notify(ctx, ds, r)
+		}
+		if d.Pushgateway != nil {
+			if err := d.Pushgateway.Push(ctx, r.Run()); err != nil {
+				d.logger().Printf("%s: %v", ds.Name, err)
+			}
+		}
+	}
+	return results
+}
+
+// profile profiles and checks filePath, recording its profile under source
+func (d *Daemon) profile(ctx context.Context, ds *Dataset, filePath, source string) *Result {
+	r := &Result{Dataset: ds.Name, Source: source, Time: time.Now()}
+	defer func() { r.Duration = time.Since(r.Time) }()
+	config := d.config(ds)
+
+	reader, err := tablestats.NewReaderFor(filePath, d.Options)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if ds.rules == nil && ds.expectations == nil {
+		r.Stats, r.Err = reader.ReadTable(ctx, filePath, config)
+	} else {
+		// Rules need the rows; expectations are evaluated over their profile
+		sampleReader, ok := reader.(tablestats.SampleReader)
+		if !ok {
+			r.Err = fmt.Errorf("%s reader does not support row access, needed by rules and expectations", reader.GetFormatName())
+			return r
+		}
+		sample, err := sampleReader.ReadSample(ctx, filePath, config)
+		if err != nil {
+			r.Err = err
+			return r
+		}
+		d.check(ds, r, sample, config)
+	}
+	d.record(ds, r)
+	return r
+}
+
+// profileTable reads, profiles and checks a table dataset, recording its
+// profile under driver://table
+func (d *Daemon) profileTable(ctx context.Context, ds *Dataset) *Result {
+	r := &Result{Dataset: ds.Name, Source: ds.Driver + "://" + ds.Table, Time: time.Now()}
+	defer func() { r.Duration = time.Since(r.Time) }()
+	config := d.config(ds)
+
+	sample, err := readTable(ctx, ds.db, ds.Table, config.SampleSize)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	d.check(ds, r, sample, config)
+	d.record(ds, r)
+	return r
+}
+
+// config returns the sampling config of a dataset
+func (d *Daemon) config(ds *Dataset) tablestats.SamplingConfig {
+	config := d.Config
+	if ds.SampleSize > 0 {
+		config.SampleSize = ds.SampleSize
+	}
+	return config
+}
+
+// check profiles a sample into r and evaluates the dataset's rules and
+// expectations against it
+func (d *Daemon) check(ds *Dataset, r *Result, sample *tablestats.Sample, config tablestats.SamplingConfig) {
+	r.Stats = tablestats.AnalyzeSample(sample, config)
+	if ds.rules != nil {
+		r.Rules = tablestats.Check(sample, ds.rules)
+	}
+	if ds.expectations != nil {
+		var err error
+		if r.Expectations, err = tablestats.Evaluate(r.Stats, ds.expectations); err != nil {
+			r.Err = err
+		}
+	}
+}
+
+// record appends the profile of a successful result to the history
+func (d *Daemon) record(ds *Dataset, r *Result) {
+	if r.Err != nil || d.History == nil {
+		return
+	}
+	if err := d.History.Append(r.Source, r.Stats, r.Time); err != nil {
+		d.logger().Printf("%s: %v", ds.Name, err)
+	}
+}
+
+// profileURL downloads a URL dataset to a temporary file and profiles it. The
+// download counts towards the duration of the result.
+func (d *Daemon) profileURL(ctx context.Context, ds *Dataset) *Result {
+	start := time.Now()
+	r := &Result{Dataset: ds.Name, Source: ds.URL, Time: start}
+	dir, err := os.MkdirTemp("", "gotablestats-daemon-")
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	defer os.RemoveAll(dir)
+
+	if filePath, err := d.download(ctx, ds.URL, dir); err != nil {
+		r.Err = err
+	} else {
+		r = d.profile(ctx, ds, filePath, ds.URL)
+	}
+	r.Duration = time.Since(start)
+	return r
+}
+
+// download fetches rawURL into dir, keeping its file name so that the reader
+// can go by the extension
+func (d *Daemon) download(ctx context.Context, rawURL, dir string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch: %s", resp.Status)
+	}
+
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = "download"
+	}
+	filePath := filepath.Join(dir, name)
+	file, err := os.Create(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch: %w", err)
+	}
+	// One byte past the limit tells a file of exactly the limit from a larger one
+	n, err := io.Copy(file, io.LimitReader(resp.Body, d.maxDownload()+1))
+	if err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to fetch: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to fetch: %w", err)
+	}
+	if n > d.maxDownload() {
+		return "", fmt.Errorf("failed to fetch: file is larger than %d bytes", d.maxDownload())
+	}
+	return filePath, nil
+}
+
+func (d *Daemon) maxDownload() int64 {
+	if d.MaxDownload > 0 {
+		return d.MaxDownload
+	}
+	return DefaultMaxDownload
+}
+
+// notify runs the dataset's on_failure command, posts to its webhooks and
+// tells the notifiers
+func (d *Daemon) notify(ctx context.Context, ds *Dataset, r *Result) {
+	if ds.OnFailure != "" {
+		if err := runCommand(ctx, ds.OnFailure, r); err != nil {
+			d.logger().Printf("%s: on_failure command failed: %v", ds.Name, err)
+		}
+	}
+	if senders := notify.New(ds.Webhook, ds.Slack); len(senders) > 0 {
+		if err := notify.SendAll(ctx, senders, r.Message(ds.ReportURL)); err != nil {
+			d.logger().Printf("%s: %v", ds.Name, err)
+		}
+	}
+	for _, n := range d.Notifiers {
+		if err := n.Notify(ctx, r); err != nil {
+			d.logger().Printf("%s: notification failed: %v", ds.Name, err)
+		}
+	}
+}
+
+func (d *Daemon) logger() *log.Logger {
+	if d.Logger != nil {
+		return d.Logger
+	}
+	return log.Default()
+}
+
+// Notifier is told about failed results
+type Notifier interface {
+	Notify(ctx context.Context, r *Result) error
+}
+
+// runCommand runs an on_failure command through the shell. The result is
+// passed in the environment: GOTABLESTATS_DATASET, GOTABLESTATS_SOURCE and
+// GOTABLESTATS_FAILURES, one failure per line.
+func runCommand(ctx context.Context, command string, r *Result) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(),
+		"GOTABLESTATS_DATASET="+r.Dataset,
+		"GOTABLESTATS_SOURCE="+r.Source,
+		"GOTABLESTATS_FAILURES="+strings.Join(r.Failures(), "\n"))
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
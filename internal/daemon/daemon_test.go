@@ -0,0 +1,373 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+const testCSV = "id,age\n1,34\n2,51\n3,230\n"
+
+// recordingNotifier keeps the results it is told about
+type recordingNotifier struct {
+	results []*Result
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, r *Result) error {
+	n.results = append(n.results, r)
+	return nil
+}
+
+// testTables are the tables of the test database driver, by name
+var testTables = map[string][][]driver.Value{
+	"shop.customers": {
+		{"id", "name", "age"},
+		{int64(1), []byte("Alice"), int64(34)},
+		{int64(2), "Bob", nil},
+		{int64(3), "Carol", 230.0},
+	},
+}
+
+func init() {
+	sql.Register("gotablestats-test", testDriver{})
+}
+
+// testDriver serves testTables to SELECT * FROM queries; its DSN is unused
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) {
+	table, ok := testTables[strings.TrimPrefix(query, "SELECT * FROM ")]
+	if !ok {
+		return nil, fmt.Errorf("no such table in %q", query)
+	}
+	return testStmt{table}, nil
+}
+func (testConn) Close() error              { return nil }
+func (testConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type testStmt struct{ table [][]driver.Value }
+
+func (s testStmt) Close() error  { return nil }
+func (s testStmt) NumInput() int { return 0 }
+func (s testStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, driver.ErrSkip
+}
+func (s testStmt) Query([]driver.Value) (driver.Rows, error) {
+	return &testRows{table: s.table, next: 1}, nil
+}
+
+type testRows struct {
+	table [][]driver.Value
+	next  int
+}
+
+func (r *testRows) Columns() []string {
+	columns := make([]string, len(r.table[0]))
+	for i, v := range r.table[0] {
+		columns[i] = v.(string)
+	}
+	return columns
+}
+func (r *testRows) Close() error { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.next == len(r.table) {
+		return io.EOF
+	}
+	copy(dest, r.table[r.next])
+	r.next++
+	return nil
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+}
+
+func newTestDaemon(t *testing.T) (*Daemon, *recordingNotifier) {
+	notifier := &recordingNotifier{}
+	return &Daemon{
+		Config:    tablestats.DefaultSamplingConfig(),
+		History:   tablestats.NewHistoryStore(filepath.Join(t.TempDir(), "history")),
+		Notifiers: []Notifier{notifier},
+		Logger:    log.New(io.Discard, "", 0),
+	}, notifier
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rules.yaml"), "columns:\n  - column: age\n    between: [0, 120]\n")
+	writeTestFile(t, filepath.Join(dir, "daemon.yaml"), `
+datasets:
+  - name: parts
+    glob: data/*.csv
+    schedule: "*/5 * * * *"
+    rules: rules.yaml
+  - name: feed
+    url: https://example.com/feed.csv
+    schedule: "@daily"
+`)
+	config, err := LoadConfig(filepath.Join(dir, "daemon.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if len(config.Datasets) != 2 || config.Datasets[0].Glob != filepath.Join(dir, "data/*.csv") || config.Datasets[0].rules == nil {
+		t.Errorf("Expected the glob resolved against the config and the rules loaded, got %+v", config.Datasets[0])
+	}
+
+	tests := []struct {
+		config   string
+		expected string
+	}{
+		{"datasets: []", "lists no datasets"},
+		{"datasets:\n  - path: a.csv\n    schedule: '@daily'", "has no name"},
+		{"datasets:\n  - name: a\n    path: a.csv\n    schedule: '@daily'\n  - name: a\n    path: b.csv\n    schedule: '@daily'", "used twice"},
+		{"datasets:\n  - name: a\n    schedule: '@daily'", "exactly one of"},
+		{"datasets:\n  - name: a\n    path: a.csv\n    glob: '*.csv'\n    schedule: '@daily'", "exactly one of"},
+		{"datasets:\n  - name: a\n    url: ftp://host/a.csv\n    schedule: '@daily'", "invalid url"},
+		{"datasets:\n  - name: a\n    table: orders\n    schedule: '@daily'", "requires driver and dsn"},
+		{"datasets:\n  - name: a\n    table: orders\n    driver: nosuchdb\n    dsn: x\n    schedule: '@daily'", `driver "nosuchdb" is not available`},
+		{"datasets:\n  - name: a\n    table: orders; DROP TABLE orders\n    driver: gotablestats-test\n    dsn: x\n    schedule: '@daily'", "invalid table name"},
+		{"datasets:\n  - name: a\n    path: a.csv\n    dsn: x\n    schedule: '@daily'", "only used with table"},
+		{"datasets:\n  - name: a\n    path: a.csv\n    schedule: 'often'", "invalid schedule"},
+		{"datasets:\n  - name: a\n    path: a.csv\n    schedule: '@daily'\n    rules: missing.yaml", "failed to read rules"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, "bad.yaml")
+		writeTestFile(t, path, tt.config)
+		if _, err := LoadConfig(path); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("Expected an error containing %q for %q, got %v", tt.expected, tt.config, err)
+		}
+	}
+}
+
+func TestRunDataset(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "data", "a.csv"), testCSV)
+	writeTestFile(t, filepath.Join(dir, "data", "b.csv"), "id,age\n1,40\n")
+	writeTestFile(t, filepath.Join(dir, "rules.yaml"), "columns:\n  - column: age\n    between: [0, 120]\n")
+	writeTestFile(t, filepath.Join(dir, "daemon.yaml"), `
+datasets:
+  - name: parts
+    glob: data/*.csv
+    schedule: "@hourly"
+    rules: rules.yaml
+    on_failure: echo "$GOTABLESTATS_DATASET $GOTABLESTATS_SOURCE $GOTABLESTATS_FAILURES" > alert.txt
+  - name: empty
+    glob: none/*.csv
+    schedule: "@hourly"
+`)
+	config, err := LoadConfig(filepath.Join(dir, "daemon.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	d, notifier := newTestDaemon(t)
+
+	// The on_failure command runs in the working directory
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+
+	results := d.RunDataset(context.Background(), config.Datasets[0])
+	if len(results) != 2 || !results[0].Failed() || results[1].Failed() {
+		t.Fatalf("Expected a.csv to fail and b.csv to pass, got %+v", results)
+	}
+	if failures := results[0].Failures(); len(failures) != 1 || failures[0] != "age: between violated by 1 values" {
+		t.Errorf("Unexpected failures %v", failures)
+	}
+	if len(notifier.results) != 1 || notifier.results[0] != results[0] {
+		t.Errorf("Expected the notifier to get the failed result, got %v", notifier.results)
+	}
+	alert, err := os.ReadFile(filepath.Join(dir, "alert.txt"))
+	if err != nil || !strings.Contains(string(alert), "parts "+results[0].Source+" age: between violated by 1 values") {
+		t.Errorf("Expected the on_failure command to run with the result, got %q, %v", alert, err)
+	}
+
+	// Every profiled file is recorded
+	runs, err := d.History.Runs(results[0].Source)
+	if err != nil || len(runs) != 1 || runs[0].Stats.RowCount != 3 {
+		t.Errorf("Expected one recorded run of 3 rows, got %v, %v", runs, err)
+	}
+
+	results = d.RunDataset(context.Background(), config.Datasets[1])
+	if len(results) != 1 || results[0].Err == nil || !strings.Contains(results[0].Summary(), "no files match") {
+		t.Errorf("Expected a glob without matches to fail, got %+v", results)
+	}
+}
+
+func TestRunDataset_URL(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/export/feed.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testCSV))
+	}))
+	defer remote.Close()
+	d, notifier := newTestDaemon(t)
+
+	ds := &Dataset{Name: "feed", URL: remote.URL + "/export/feed.csv", Schedule: "@daily"}
+	if err := ds.prepare(t.TempDir()); err != nil {
+		t.Fatalf("prepare failed: %v", err)
+	}
+	results := d.RunDataset(context.Background(), ds)
+	if len(results) != 1 || results[0].Failed() || results[0].Stats.RowCount != 3 || results[0].Source != ds.URL {
+		t.Fatalf("Expected the downloaded file to be profiled, got %+v", results[0])
+	}
+	if runs, _ := d.History.Runs(ds.URL); len(runs) != 1 {
+		t.Errorf("Expected the run to be recorded under the URL, got %d runs", len(runs))
+	}
+
+	ds.URL = remote.URL + "/missing.csv"
+	results = d.RunDataset(context.Background(), ds)
+	if results[0].Err == nil || len(notifier.results) != 1 {
+		t.Errorf("Expected a failed download to be notified, got %+v", results[0])
+	}
+}
+
+func TestRunDataset_URLTooLarge(t *testing.T) {
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testCSV))
+	}))
+	defer remote.Close()
+	d, _ := newTestDaemon(t)
+
+	ds := &Dataset{Name: "feed", URL: remote.URL + "/feed.csv", Schedule: "@daily"}
+	if err := ds.prepare(t.TempDir()); err != nil {
+		t.Fatalf("prepare failed: %v", err)
+	}
+	d.MaxDownload = int64(len(testCSV))
+	if results := d.RunDataset(context.Background(), ds); results[0].Err != nil {
+		t.Errorf("Expected a file of exactly the limit to be profiled, got %v", results[0].Err)
+	}
+	d.MaxDownload = int64(len(testCSV)) - 1
+	results := d.RunDataset(context.Background(), ds)
+	if results[0].Err == nil || !strings.Contains(results[0].Err.Error(), "larger than") {
+		t.Errorf("Expected a file over the limit to fail, got %v", results[0].Err)
+	}
+}
+
+func TestRunDataset_Table(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "rules.yaml"), "columns:\n  - column: age\n    between: [0, 120]\n")
+	writeTestFile(t, filepath.Join(dir, "daemon.yaml"), `
+datasets:
+  - name: customers
+    driver: gotablestats-test
+    dsn: shop
+    table: shop.customers
+    schedule: "@daily"
+    rules: rules.yaml
+  - name: sampled
+    driver: gotablestats-test
+    dsn: shop
+    table: shop.customers
+    schedule: "@daily"
+    sample_size: 2
+  - name: missing
+    driver: gotablestats-test
+    dsn: shop
+    table: shop.orders
+    schedule: "@daily"
+`)
+	config, err := LoadConfig(filepath.Join(dir, "daemon.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	d, _ := newTestDaemon(t)
+
+	results := d.RunDataset(context.Background(), config.Datasets[0])
+	r := results[0]
+	if r.Err != nil || r.Source != "gotablestats-test://shop.customers" || r.Stats.RowCount != 3 {
+		t.Fatalf("Expected the table to be profiled, got %+v", r)
+	}
+	if r.Stats.NullCounts["age"] != 1 || r.Stats.MinValues["name"] != "Alice" {
+		t.Errorf("Expected NULL to be counted as null and bytes read as text, got %v, %v", r.Stats.NullCounts, r.Stats.MinValues)
+	}
+	if failures := r.Failures(); len(failures) != 1 || failures[0] != "age: between violated by 1 values" {
+		t.Errorf("Unexpected failures %v", failures)
+	}
+	if runs, _ := d.History.Runs(r.Source); len(runs) != 1 {
+		t.Errorf("Expected the run to be recorded under the table, got %d runs", len(runs))
+	}
+
+	r = d.RunDataset(context.Background(), config.Datasets[1])[0]
+	if r.Err != nil || r.Stats.RowCount != 2 || r.Stats.EstimatedRows != 3 {
+		t.Errorf("Expected 2 of 3 rows to be sampled, got %+v", r.Stats)
+	}
+
+	r = d.RunDataset(context.Background(), config.Datasets[2])[0]
+	if r.Err == nil || !strings.Contains(r.Err.Error(), "failed to query shop.orders") {
+		t.Errorf("Expected a missing table to fail, got %v", r.Err)
+	}
+}
+
+func TestRunDataset_Webhooks(t *testing.T) {
+	posted := make(map[string]string)
+	hooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted[r.URL.Path] = string(body)
+	}))
+	defer hooks.Close()
+
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "daemon.yaml"), `
+webhook: `+hooks.URL+`/hook
+report_url: https://reports.example.com/{dataset}?file={source}
+datasets:
+  - name: orders
+    path: missing.csv
+    schedule: "@daily"
+  - name: quiet
+    path: missing.csv
+    schedule: "@daily"
+    webhook: `+hooks.URL+`/quiet
+    slack: `+hooks.URL+`/slack
+`)
+	config, err := LoadConfig(filepath.Join(dir, "daemon.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	d, _ := newTestDaemon(t)
+	for _, ds := range config.Datasets {
+		d.RunDataset(context.Background(), ds)
+	}
+
+	var msg struct {
+		Check     string   `json:"check"`
+		Failures  []string `json:"failures"`
+		ReportURL string   `json:"report_url"`
+	}
+	if err := json.Unmarshal([]byte(posted["/hook"]), &msg); err != nil {
+		t.Fatalf("Failed to decode webhook body %q: %v", posted["/hook"], err)
+	}
+	wantURL := "https://reports.example.com/orders?file=" + url.QueryEscape(filepath.Join(dir, "missing.csv"))
+	if msg.Check != "daemon: orders" || len(msg.Failures) != 1 || msg.ReportURL != wantURL {
+		t.Errorf("Unexpected webhook message %+v", msg)
+	}
+	// A dataset's own webhook replaces the config's
+	if posted["/quiet"] == "" || posted["/slack"] == "" || strings.Contains(posted["/hook"], "quiet") {
+		t.Errorf("Expected the quiet dataset to post to its own hooks only, got %v", posted)
+	}
+}
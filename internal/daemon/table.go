@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+// readTable reads the rows of a database table into a sample. When size is
+// positive and the table has more rows, size of them are kept by reservoir
+// sampling.
+func readTable(ctx context.Context, db *sql.DB, table string, size int) (*tablestats.Sample, error) {
+	rows, err := db.QueryContext(ctx, "SELECT * FROM "+table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	defer rows.Close()
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+
+	values := make([]any, len(header))
+	dest := make([]any, len(header))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	var records [][]string
+	var seen int64
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		seen++
+		if size > 0 && len(records) >= size {
+			j := rand.Int63n(seen)
+			if j >= int64(size) {
+				continue
+			}
+			formatRow(records[j], values)
+			continue
+		}
+		record := make([]string, len(values))
+		formatRow(record, values)
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+
+	sample := tablestats.NewSample(header, records)
+	sample.EstimatedRows = seen
+	sample.Exact = int64(len(records)) == seen
+	return sample, nil
+}
+
+// formatRow writes the text of each scanned value into record. NULL becomes
+// the empty field, as in CSV files.
+func formatRow(record []string, values []any) {
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+			record[i] = ""
+		case []byte:
+			record[i] = string(v)
+		case string:
+			record[i] = v
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case time.Time:
+			record[i] = v.Format(time.RFC3339Nano)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compressions lists the supported output compressions
+var Compressions = []string{"gzip", "zstd"}
+
+// compressionExts maps compressions to their file extensions
+var compressionExts = map[string]string{"gzip": ".gz", "zstd": ".zst"}
+
+// CompressionFromPath returns the compression a file extension implies, or ""
+func CompressionFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".gzip":
+		return "gzip"
+	case ".zst", ".zstd":
+		return "zstd"
+	default:
+		return ""
+	}
+}
+
+// CompressedPath adds the compression's extension to path unless it already has it
+func CompressedPath(path, compression string) string {
+	if compression == "" || CompressionFromPath(path) == compression {
+		return path
+	}
+	return path + compressionExts[compression]
+}
+
+// NewCompressor wraps w so that everything written is compressed. Closing the
+// compressor flushes it but leaves w open. An empty compression writes through.
+func NewCompressor(w io.Writer, compression string) (io.WriteCloser, error) {
+	switch compression {
+	case "":
+		return nopCloser{w}, nil
+	case "gzip":
+		return gzip.NewWriter(w), nil
+	case "zstd":
+		enc, err := zstd.NewWriter(w)
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd writer: %w", err)
+		}
+		return enc, nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q (use %s)", compression, strings.Join(Compressions, ", "))
+	}
+}
+
+// NewDecompressor reads what a compressor of the given kind wrote
+func NewDecompressor(r io.Reader, compression string) (io.ReadCloser, error) {
+	switch compression {
+	case "":
+		return io.NopCloser(r), nil
+	case "gzip":
+		return gzip.NewReader(r)
+	case "zstd":
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("creating zstd reader: %w", err)
+		}
+		return dec.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q (use %s)", compression, strings.Join(Compressions, ", "))
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,226 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Distribution describes how the values of a column are drawn:
+//
+//	lognormal(mu,sigma)  exp(mu + sigma*N(0,1)), e.g. lognormal(10.5,0.4) for salaries
+//	exponential(mean)    exponential with the given mean
+//	zipf(s[,n])          rank k is drawn with probability proportional to 1/k^s (s > 1)
+//	                     over the column's categories, or over n integers
+type Distribution struct {
+	Kind   string
+	Params []float64
+}
+
+// distributionParams gives the minimum and maximum number of parameters of each kind
+var distributionParams = map[string][2]int{
+	"lognormal":   {2, 2},
+	"exponential": {1, 1},
+	"zipf":        {1, 2},
+}
+
+// defaultZipfRanks is the number of integers a zipf column without bounds spans
+const defaultZipfRanks = 1000
+
+// ParseDistribution parses a distribution such as "lognormal(10.5,0.4)"
+func ParseDistribution(s string) (Distribution, error) {
+	s = strings.TrimSpace(s)
+	kind, args, ok := strings.Cut(s, "(")
+	if !ok || !strings.HasSuffix(args, ")") {
+		return Distribution{}, fmt.Errorf("invalid distribution %q, expected kind(params)", s)
+	}
+	d := Distribution{Kind: strings.ToLower(strings.TrimSpace(kind))}
+	counts, ok := distributionParams[d.Kind]
+	if !ok {
+		return d, fmt.Errorf("unknown distribution %q (use lognormal, exponential or zipf)", d.Kind)
+	}
+
+	if args = strings.TrimSuffix(args, ")"); strings.TrimSpace(args) != "" {
+		for _, arg := range strings.Split(args, ",") {
+			v, err := strconv.ParseFloat(strings.TrimSpace(arg), 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return d, fmt.Errorf("invalid parameter %q in %q", arg, s)
+			}
+			d.Params = append(d.Params, v)
+		}
+	}
+	if len(d.Params) < counts[0] || len(d.Params) > counts[1] {
+		return d, fmt.Errorf("%s takes %d to %d parameters, got %d", d.Kind, counts[0], counts[1], len(d.Params))
+	}
+
+	switch {
+	case d.Kind == "lognormal" && d.Params[1] <= 0:
+		return d, fmt.Errorf("lognormal sigma must be positive")
+	case d.Kind == "exponential" && d.Params[0] <= 0:
+		return d, fmt.Errorf("exponential mean must be positive")
+	case d.Kind == "zipf" && d.Params[0] <= 1:
+		return d, fmt.Errorf("zipf exponent must be greater than 1")
+	case d.Kind == "zipf" && len(d.Params) == 2 && (d.Params[1] < 1 || d.Params[1] != math.Trunc(d.Params[1])):
+		return d, fmt.Errorf("zipf needs a whole number of ranks")
+	}
+	return d, nil
+}
+
+func (d Distribution) String() string {
+	params := make([]string, len(d.Params))
+	for i, p := range d.Params {
+		params[i] = strconv.FormatFloat(p, 'g', -1, 64)
+	}
+	return d.Kind + "(" + strings.Join(params, ",") + ")"
+}
+
+// ColumnDomain is implemented by generators that know which values a column
+// may take: a list of categories, or numeric bounds (nil when open)
+type ColumnDomain interface {
+	Domain(column int) (categories []string, lo, hi *float64)
+}
+
+// Distributor wraps a RowGenerator and draws the values of chosen columns from
+// skewed or long-tailed distributions instead of the generator's own, usually
+// uniform, ones
+type Distributor struct {
+	Gen RowGenerator
+
+	columns []distributedColumn
+}
+
+type distributedColumn struct {
+	index      int
+	dist       Distribution
+	typ        string   // int64 or float64 for numeric draws
+	categories []string // Ranked categories for zipf
+	lo, hi     float64  // Bounds of numeric draws
+	ranks      uint64   // Number of zipf ranks
+}
+
+// NewDistributor creates a Distributor for gen. Numeric distributions need an
+// int64 or float64 column; zipf also works on a column with categories, which
+// it ranks in the order the generator lists them. Numeric draws outside the
+// column's bounds are redrawn.
+func NewDistributor(gen RowGenerator, dists map[string]Distribution) (*Distributor, error) {
+	header := gen.Header()
+	types := make([]string, len(header))
+	if typer, ok := gen.(ColumnTyper); ok {
+		copy(types, typer.ColumnTypes())
+	}
+	domain, _ := gen.(ColumnDomain)
+
+	names := make([]string, 0, len(dists))
+	for name := range dists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	d := &Distributor{Gen: gen}
+	for _, name := range names {
+		idx := -1
+		for i, h := range header {
+			if h == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("distribution for unknown column %q", name)
+		}
+
+		col := distributedColumn{index: idx, dist: dists[name], typ: types[idx], lo: math.Inf(-1), hi: math.Inf(1)}
+		if domain != nil {
+			var lo, hi *float64
+			col.categories, lo, hi = domain.Domain(idx)
+			if lo != nil {
+				col.lo = *lo
+			}
+			if hi != nil {
+				col.hi = *hi
+			}
+		}
+		numeric := col.typ == "int64" || col.typ == "float64"
+
+		switch {
+		case col.dist.Kind == "zipf" && len(col.categories) > 0:
+			col.ranks = uint64(len(col.categories))
+		case !numeric:
+			return nil, fmt.Errorf("column %q is not numeric; only zipf applies to columns with categories", name)
+		case col.dist.Kind == "zipf":
+			// Integers from the lower bound, or from 1
+			if math.IsInf(col.lo, -1) {
+				col.lo = 1
+			}
+			col.ranks = defaultZipfRanks
+			if len(col.dist.Params) == 2 {
+				col.ranks = uint64(col.dist.Params[1])
+			} else if !math.IsInf(col.hi, 1) {
+				col.ranks = uint64(math.Floor(col.hi-col.lo)) + 1
+			}
+		}
+		if col.lo > col.hi {
+			return nil, fmt.Errorf("column %q has an empty range", name)
+		}
+		d.columns = append(d.columns, col)
+	}
+	return d, nil
+}
+
+func (d *Distributor) Header() []string {
+	return d.Gen.Header()
+}
+
+func (d *Distributor) ColumnTypes() []string {
+	if typer, ok := d.Gen.(ColumnTyper); ok {
+		return typer.ColumnTypes()
+	}
+	return make([]string, len(d.Gen.Header()))
+}
+
+func (d *Distributor) Row(rng *rand.Rand, id int) []string {
+	row := d.Gen.Row(rng, id)
+	for i := range d.columns {
+		row[d.columns[i].index] = d.columns[i].draw(rng)
+	}
+	return row
+}
+
+// maxRedraws bounds the attempts to draw a value within a column's bounds
+// before the value is clamped
+const maxRedraws = 100
+
+func (c *distributedColumn) draw(rng *rand.Rand) string {
+	if c.dist.Kind == "zipf" {
+		// Draws are 0-based ranks, 0 being the most frequent
+		k := rand.NewZipf(rng, c.dist.Params[0], 1, c.ranks-1).Uint64()
+		if len(c.categories) > 0 {
+			return c.categories[k]
+		}
+		return c.format(c.lo + float64(k))
+	}
+
+	var v float64
+	for i := 0; i < maxRedraws; i++ {
+		switch c.dist.Kind {
+		case "lognormal":
+			v = math.Exp(c.dist.Params[0] + c.dist.Params[1]*rng.NormFloat64())
+		case "exponential":
+			v = rng.ExpFloat64() * c.dist.Params[0]
+		}
+		if v >= c.lo && v <= c.hi {
+			break
+		}
+	}
+	return c.format(math.Min(math.Max(v, c.lo), c.hi))
+}
+
+func (c *distributedColumn) format(v float64) string {
+	if c.typ == "int64" {
+		return strconv.FormatInt(int64(math.Round(v)), 10)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
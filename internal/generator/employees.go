@@ -0,0 +1,69 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Data arrays for random generation
+var (
+	departments = []string{"Engineering", "Marketing", "Sales", "HR", "Finance", "Operations", "Legal", "IT"}
+	categories  = []string{"A", "B", "C", "D", "E"}
+	domains     = []string{"gmail.com", "yahoo.com", "company.com", "outlook.com", "hotmail.com"}
+	firstNames  = []string{"John", "Jane", "Michael", "Sarah", "David", "Lisa", "Robert", "Emily", "James", "Ashley", "Chris", "Jessica", "Daniel", "Amanda", "Matthew", "Nicole", "William", "Jennifer", "Richard", "Michelle", "Joseph", "Kimberly", "Thomas", "Amy", "Charles", "Angela", "Christopher", "Brenda", "Mark", "Emma"}
+	lastNames   = []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin", "Lee", "Perez", "Thompson", "White", "Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson"}
+)
+
+// Employees generates the built-in employee dataset used when no schema is given
+type Employees struct{}
+
+func (Employees) Header() []string {
+	return []string{"id", "name", "email", "age", "salary", "department", "join_date", "active", "score", "category"}
+}
+
+func (Employees) ColumnTypes() []string {
+	return []string{"int64", "string", "string", "int64", "int64", "string", "string", "string", "float64", "string"}
+}
+
+// Domain lists the categories of the department and category columns
+func (Employees) Domain(column int) ([]string, *float64, *float64) {
+	switch column {
+	case 5:
+		return departments, nil, nil
+	case 9:
+		return categories, nil, nil
+	default:
+		return nil, nil, nil
+	}
+}
+
+func (Employees) Row(rng *rand.Rand, id int) []string {
+	firstName := firstNames[rng.Intn(len(firstNames))]
+	lastName := lastNames[rng.Intn(len(lastNames))]
+
+	return []string{
+		strconv.Itoa(id),
+		fmt.Sprintf("%s %s", firstName, lastName),
+		fmt.Sprintf("%s.%s%d@%s",
+			strings.ToLower(firstName),
+			strings.ToLower(lastName),
+			rng.Intn(9999),
+			domains[rng.Intn(len(domains))]),
+		strconv.Itoa(22 + rng.Intn(44)),        // 22-65
+		strconv.Itoa(30000 + rng.Intn(120000)), // 30k-150k
+		departments[rng.Intn(len(departments))],
+		generateRandomDate(rng),
+		strconv.FormatBool(rng.Intn(2) == 0),
+		fmt.Sprintf("%.2f", rng.Float64()*100), // 0-100
+		categories[rng.Intn(len(categories))],
+	}
+}
+
+func generateRandomDate(rng *rand.Rand) string {
+	year := 2015 + rng.Intn(9) // 2015-2023
+	month := 1 + rng.Intn(12)
+	day := 1 + rng.Intn(28)
+	return fmt.Sprintf("%d-%02d-%02d", year, month, day)
+}
@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// batchSize is the number of rows a worker generates per job
+const batchSize = 10000
+
+// RowGenerator produces the header and the rows of a synthetic dataset
+type RowGenerator interface {
+	Header() []string
+	// Row returns the fields of the row with the given 1-based id
+	Row(rng *rand.Rand, id int) []string
+}
+
+// Config controls a generation run
+type Config struct {
+	Rows     int
+	Workers  int
+	Seed     int64                    // Makes the output reproducible; 0 picks a random seed
+	Format   string                   // One of Formats; empty means CSV
+	Progress func(written, total int) // Called after every written batch, may be nil
+
+	// DuplicateRate is the share of rows replaced by a copy of an earlier row
+	// of the same batch
+	DuplicateRate float64
+	// MalformedRate is the share of rows written with a wrong field count, an
+	// unbalanced quote or an embedded newline. Not supported for Parquet.
+	MalformedRate float64
+}
+
+// DuplicateRows returns how many rows a run replaces by copies of earlier
+// rows. Each one repeats a row written before it, so unless gen itself
+// repeats rows, the output holds exactly this many duplicates.
+func (c Config) DuplicateRows() int {
+	total := 0
+	for start := 0; start < c.Rows; start += batchSize {
+		total += batchDuplicates(min(batchSize, c.Rows-start), c.DuplicateRate)
+	}
+	return total
+}
+
+// batchDuplicates returns how many rows of a batch are duplicated; the first
+// row of a batch never is, as it has no earlier row to copy
+func batchDuplicates(size int, rate float64) int {
+	return min(int(math.Round(float64(size)*rate)), size-1)
+}
+
+// duplicateRows overwrites rows of the batch with copies of earlier rows.
+// Rows are overwritten in order, so every copy repeats a row that is kept.
+func duplicateRows(rng *rand.Rand, batch [][]string, rate float64) {
+	n := batchDuplicates(len(batch), rate)
+	if n <= 0 {
+		return
+	}
+	positions := rng.Perm(len(batch) - 1)[:n]
+	sort.Ints(positions)
+	for _, pos := range positions {
+		pos++
+		batch[pos] = batch[rng.Intn(pos)]
+	}
+}
+
+// generatedBatch is a batch of rows and the rows to write malformed
+type generatedBatch struct {
+	rows      [][]string
+	malformed []malformation // By ascending row
+}
+
+func (b generatedBatch) writeTo(w rowWriter) error {
+	start := 0
+	for _, m := range b.malformed {
+		if err := w.write(b.rows[start:m.row]); err != nil {
+			return err
+		}
+		if err := w.writeMalformed(b.rows[m.row], m); err != nil {
+			return err
+		}
+		start = m.row + 1
+	}
+	return w.write(b.rows[start:])
+}
+
+// batchSeed derives the seed of a batch from the run seed (SplitMix64), so a
+// batch's rows do not depend on which worker generates it or on how many
+// workers there are
+func batchSeed(seed int64, batch int) int64 {
+	z := uint64(seed) + uint64(batch+1)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return int64(z ^ z>>31)
+}
+
+// Generate writes config.Rows rows from gen to w in config.Format. Rows are produced by
+// config.Workers goroutines in batches and written in order. The same seed
+// gives the same output for any number of workers. Generation stops with the
+// context's error once ctx is cancelled.
+func Generate(ctx context.Context, w io.Writer, gen RowGenerator, config Config) error {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	if config.DuplicateRate < 0 || config.DuplicateRate > 1 {
+		return fmt.Errorf("duplicate rate must be between 0 and 1")
+	}
+	if config.MalformedRate < 0 || config.MalformedRate > 1 {
+		return fmt.Errorf("malformed rate must be between 0 and 1")
+	}
+	if config.MalformedRate > 0 && config.Format == "parquet" {
+		return fmt.Errorf("malformed rows cannot be written as parquet")
+	}
+
+	writer, err := newRowWriter(config.Format, w, gen)
+	if err != nil {
+		return err
+	}
+
+	type job struct {
+		batch int
+		start int
+		size  int
+		out   chan generatedBatch
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	jobs := make(chan job)
+	// pending preserves batch order and bounds the number of batches in flight
+	pending := make(chan chan generatedBatch, config.Workers*2)
+
+	go func() {
+		defer close(jobs)
+		defer close(pending)
+		for start := 0; start < config.Rows; start += batchSize {
+			size := batchSize
+			if config.Rows-start < size {
+				size = config.Rows - start
+			}
+			j := job{batch: start / batchSize, start: start, size: size, out: make(chan generatedBatch, 1)}
+			select {
+			case pending <- j.out:
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < config.Workers; i++ {
+		go func() {
+			rng := rand.New(rand.NewSource(0))
+			fields := len(gen.Header())
+			for j := range jobs {
+				rng.Seed(batchSeed(config.Seed, j.batch))
+				batch := make([][]string, j.size)
+				for k := range batch {
+					batch[k] = gen.Row(rng, j.start+k+1)
+				}
+				duplicateRows(rng, batch, config.DuplicateRate)
+				j.out <- generatedBatch{
+					rows:      batch,
+					malformed: malformRows(rng, j.size, fields, config.MalformedRate),
+				}
+			}
+		}()
+	}
+
+	written := 0
+	for out := range pending {
+		var batch generatedBatch
+		select {
+		case batch = <-out:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if err := batch.writeTo(writer); err != nil {
+			return err
+		}
+		written += len(batch.rows)
+		if config.Progress != nil {
+			config.Progress(written, config.Rows)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return writer.close()
+}
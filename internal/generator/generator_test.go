@@ -0,0 +1,740 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+func TestGenerate(t *testing.T) {
+	var buf bytes.Buffer
+	var progress []int
+
+	config := Config{
+		Rows:    25000,
+		Workers: 4,
+		Progress: func(written, total int) {
+			progress = append(progress, written)
+		},
+	}
+	if err := Generate(context.Background(), &buf, Employees{}, config); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse generated CSV: %v", err)
+	}
+	if len(records) != config.Rows+1 {
+		t.Fatalf("Expected %d records including header, got %d", config.Rows+1, len(records))
+	}
+	if records[0][0] != "id" {
+		t.Errorf("Expected header to start with id, got %v", records[0])
+	}
+
+	// Batches from concurrent workers must be written in order
+	for i, record := range records[1:] {
+		if record[0] != strconv.Itoa(i+1) {
+			t.Fatalf("Expected id %d at row %d, got %s", i+1, i+1, record[0])
+		}
+	}
+
+	expectedProgress := []int{10000, 20000, 25000}
+	if len(progress) != len(expectedProgress) {
+		t.Fatalf("Expected progress %v, got %v", expectedProgress, progress)
+	}
+	for i := range progress {
+		if progress[i] != expectedProgress[i] {
+			t.Errorf("Expected progress %v, got %v", expectedProgress, progress)
+		}
+	}
+}
+
+func TestGenerate_Seed(t *testing.T) {
+	generate := func(seed int64, workers int) string {
+		var buf bytes.Buffer
+		config := Config{Rows: 25000, Workers: workers, Seed: seed}
+		if err := Generate(context.Background(), &buf, Employees{}, config); err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		return buf.String()
+	}
+
+	// The output depends only on the seed, not on how batches are spread over workers
+	first := generate(42, 4)
+	if generate(42, 4) != first || generate(42, 1) != first {
+		t.Error("Expected the same seed to give the same output")
+	}
+	if generate(43, 4) == first {
+		t.Error("Expected different seeds to give different output")
+	}
+}
+
+func TestGenerate_Duplicates(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{Rows: 25000, Workers: 4, DuplicateRate: 0.02}
+	if err := Generate(context.Background(), &buf, Employees{}, config); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse generated CSV: %v", err)
+	}
+	seen := make(map[string]bool)
+	duplicates := 0
+	for _, record := range records[1:] {
+		key := strings.Join(record, ",")
+		if seen[key] {
+			duplicates++
+		}
+		seen[key] = true
+	}
+
+	// Employee rows are unique by id, so every repeat is an injected one
+	if config.DuplicateRows() != 500 {
+		t.Errorf("Expected 500 duplicate rows for 2%% of 25000, got %d", config.DuplicateRows())
+	}
+	if duplicates != config.DuplicateRows() {
+		t.Errorf("Expected %d duplicate rows, got %d", config.DuplicateRows(), duplicates)
+	}
+
+	config.DuplicateRate = 1.5
+	if err := Generate(context.Background(), &buf, Employees{}, config); err == nil {
+		t.Error("Expected error for a duplicate rate above 1")
+	}
+}
+
+func TestGenerate_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	err := Generate(ctx, &buf, Employees{}, Config{Rows: 100000, Workers: 2})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSchemaGenerator(t *testing.T) {
+	lo, hi := 18.0, 65.0
+	schema := &tablestats.Schema{Columns: []tablestats.ColumnSchema{
+		{Name: "age", Type: "int64", Min: &lo, Max: &hi},
+		{Name: "score", Type: "float64", Max: &hi},
+		{Name: "status", Allowed: []string{"active", "inactive"}},
+		{Name: "note"},
+	}}
+
+	gen, err := NewSchemaGenerator(schema)
+	if err != nil {
+		t.Fatalf("NewSchemaGenerator failed: %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 1; i <= 1000; i++ {
+		row := gen.Row(rng, i)
+
+		age, err := strconv.Atoi(row[0])
+		if err != nil || age < 18 || age > 65 {
+			t.Fatalf("Expected age in [18, 65], got %s", row[0])
+		}
+		score, err := strconv.ParseFloat(row[1], 64)
+		if err != nil || score > 65 {
+			t.Fatalf("Expected score <= 65, got %s", row[1])
+		}
+		if row[2] != "active" && row[2] != "inactive" {
+			t.Fatalf("Expected allowed status, got %s", row[2])
+		}
+		if row[3] == "" {
+			t.Fatal("Expected non-empty note")
+		}
+	}
+
+	schema.Columns[0].Min, schema.Columns[0].Max = &hi, &lo
+	if _, err := NewSchemaGenerator(schema); err == nil {
+		t.Error("Expected error for min greater than max")
+	}
+}
+
+func TestInjector(t *testing.T) {
+	inj, err := NewInjector(Employees{}, map[string]float64{"age": 0.1}, map[string]float64{"salary": 0.2})
+	if err != nil {
+		t.Fatalf("NewInjector failed: %v", err)
+	}
+
+	const rows = 20000
+	rng := rand.New(rand.NewSource(1))
+	var nulls, dirty, other int
+	for i := 1; i <= rows; i++ {
+		row := inj.Row(rng, i)
+		if row[3] == "" {
+			nulls++
+		}
+		if _, err := strconv.Atoi(row[4]); err != nil {
+			dirty++
+		}
+		if row[0] == "" || row[1] == "" {
+			other++
+		}
+	}
+
+	if share := float64(nulls) / rows; share < 0.09 || share > 0.11 {
+		t.Errorf("Expected about 10%% empty ages, got %.3f", share)
+	}
+	if share := float64(dirty) / rows; share < 0.19 || share > 0.21 {
+		t.Errorf("Expected about 20%% malformed salaries, got %.3f", share)
+	}
+	if other != 0 {
+		t.Errorf("Expected other columns to be left alone, got %d empty values", other)
+	}
+
+	invalid := []struct {
+		nulls, dirty map[string]float64
+	}{
+		{map[string]float64{"height": 0.1}, nil},
+		{nil, map[string]float64{"age": 1.5}},
+		{map[string]float64{"age": 0.6}, map[string]float64{"age": 0.6}},
+	}
+	for _, tt := range invalid {
+		if _, err := NewInjector(Employees{}, tt.nulls, tt.dirty); err == nil {
+			t.Errorf("Expected error for null rates %v and dirty rates %v", tt.nulls, tt.dirty)
+		}
+	}
+}
+
+func TestGenerate_Formats(t *testing.T) {
+	gen, err := NewInjector(Employees{}, map[string]float64{"age": 0.1}, map[string]float64{"salary": 0.1})
+	if err != nil {
+		t.Fatalf("NewInjector failed: %v", err)
+	}
+	generate := func(format string) []byte {
+		var buf bytes.Buffer
+		config := Config{Rows: 15000, Workers: 2, Seed: 7, Format: format}
+		if err := Generate(context.Background(), &buf, gen, config); err != nil {
+			t.Fatalf("Generate failed for %s: %v", format, err)
+		}
+		return buf.Bytes()
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(generate("csv"))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse generated CSV: %v", err)
+	}
+	header, rows := records[0], records[1:]
+
+	t.Run("tsv", func(t *testing.T) {
+		reader := csv.NewReader(bytes.NewReader(generate("tsv")))
+		reader.Comma = '\t'
+		got, err := reader.ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse generated TSV: %v", err)
+		}
+		if !reflect.DeepEqual(got, records) {
+			t.Error("Expected TSV output to hold the same records as CSV")
+		}
+	})
+
+	t.Run("jsonl", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(string(generate("jsonl")), "\n"), "\n")
+		if len(lines) != len(rows) {
+			t.Fatalf("Expected %d lines, got %d", len(rows), len(lines))
+		}
+		for i, line := range lines {
+			var obj map[string]any
+			if err := json.Unmarshal([]byte(line), &obj); err != nil {
+				t.Fatalf("Failed to parse line %d: %v", i+1, err)
+			}
+			for j, name := range header {
+				value, want := obj[name], rows[i][j]
+				switch {
+				case want == "":
+					if value != nil {
+						t.Fatalf("Expected null %s at line %d, got %v", name, i+1, value)
+					}
+				case name == "age" || name == "score":
+					number, ok := value.(float64)
+					if expected, _ := strconv.ParseFloat(want, 64); !ok || number != expected {
+						t.Fatalf("Expected number %s for %s at line %d, got %v", want, name, i+1, value)
+					}
+				case name == "id":
+					if value != float64(i+1) {
+						t.Fatalf("Expected id %d at line %d, got %v", i+1, i+1, value)
+					}
+				default:
+					// Corrupted salaries make the column text
+					if value != want {
+						t.Fatalf("Expected %q for %s at line %d, got %v", want, name, i+1, value)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		data := generate("parquet")
+		table, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(data), nil, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+		if err != nil {
+			t.Fatalf("Failed to read generated Parquet: %v", err)
+		}
+		defer table.Release()
+
+		if table.NumRows() != int64(len(rows)) {
+			t.Fatalf("Expected %d rows, got %d", len(rows), table.NumRows())
+		}
+		expected := map[string]arrow.Type{"id": arrow.INT64, "age": arrow.INT64, "salary": arrow.STRING, "score": arrow.FLOAT64, "name": arrow.STRING}
+		for name, typ := range expected {
+			indexes := table.Schema().FieldIndices(name)
+			if len(indexes) != 1 || table.Schema().Field(indexes[0]).Type.ID() != typ {
+				t.Errorf("Expected %s to be %s, got %v", name, typ, table.Schema().FieldIndices(name))
+			}
+		}
+
+		nulls := 0
+		for _, row := range rows {
+			if row[3] == "" {
+				nulls++
+			}
+		}
+		if got := table.Column(3).NullN(); got != nulls {
+			t.Errorf("Expected %d null ages, got %d", nulls, got)
+		}
+	})
+
+	if _, err := newRowWriter("xml", io.Discard, gen); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"data.csv":      "csv",
+		"data.TSV":      "tsv",
+		"data.jsonl":    "jsonl",
+		"data.ndjson":   "jsonl",
+		"data.parquet":  "parquet",
+		"data":          "csv",
+		"data.json.txt": "csv",
+		"data.tsv.gz":   "tsv",
+		"data.zst":      "csv",
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("Expected %s for %s, got %s", want, path, got)
+		}
+	}
+}
+
+func TestCompressor(t *testing.T) {
+	for _, compression := range append([]string{""}, Compressions...) {
+		var buf bytes.Buffer
+		out, err := NewCompressor(&buf, compression)
+		if err != nil {
+			t.Fatalf("NewCompressor(%q) failed: %v", compression, err)
+		}
+		config := Config{Rows: 12000, Workers: 2, Seed: 5}
+		if err := Generate(context.Background(), out, Employees{}, config); err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		if err := out.Close(); err != nil {
+			t.Fatalf("Closing the %q compressor failed: %v", compression, err)
+		}
+
+		r, err := NewDecompressor(&buf, compression)
+		if err != nil {
+			t.Fatalf("NewDecompressor(%q) failed: %v", compression, err)
+		}
+		records, err := csv.NewReader(r).ReadAll()
+		r.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %q output: %v", compression, err)
+		}
+		if len(records) != config.Rows+1 {
+			t.Errorf("Expected %d records from %q output, got %d", config.Rows+1, compression, len(records))
+		}
+	}
+
+	if _, err := NewCompressor(io.Discard, "lz4"); err == nil {
+		t.Error("Expected error for an unsupported compression")
+	}
+
+	paths := []struct{ path, compression, want string }{
+		{"data.csv", "gzip", "data.csv.gz"},
+		{"data.csv.gz", "gzip", "data.csv.gz"},
+		{"data.csv", "zstd", "data.csv.zst"},
+		{"data.csv", "", "data.csv"},
+	}
+	for _, tt := range paths {
+		if got := CompressedPath(tt.path, tt.compression); got != tt.want {
+			t.Errorf("Expected %s for %s with %q, got %s", tt.want, tt.path, tt.compression, got)
+		}
+	}
+}
+
+func TestParseDistribution(t *testing.T) {
+	tests := []struct {
+		spec string
+		want Distribution
+	}{
+		{"lognormal(10.5,0.4)", Distribution{"lognormal", []float64{10.5, 0.4}}},
+		{" Exponential( 50 ) ", Distribution{"exponential", []float64{50}}},
+		{"zipf(1.2, 100)", Distribution{"zipf", []float64{1.2, 100}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseDistribution(tt.spec)
+		if err != nil {
+			t.Errorf("Parsing %q failed: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %v for %q, got %v", tt.want, tt.spec, got)
+		}
+	}
+
+	invalid := []string{"lognormal", "lognormal(1)", "normal(0,1)", "lognormal(1,0)", "exponential(-1)", "zipf(1)", "zipf(2,1.5)", "zipf(a)"}
+	for _, spec := range invalid {
+		if _, err := ParseDistribution(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestDistributor(t *testing.T) {
+	dist := func(spec string) Distribution {
+		d, err := ParseDistribution(spec)
+		if err != nil {
+			t.Fatalf("ParseDistribution failed: %v", err)
+		}
+		return d
+	}
+
+	gen, err := NewDistributor(Employees{}, map[string]Distribution{
+		"salary":     dist("lognormal(10.5,0.4)"),
+		"department": dist("zipf(1.5)"),
+		"score":      dist("exponential(10)"),
+	})
+	if err != nil {
+		t.Fatalf("NewDistributor failed: %v", err)
+	}
+
+	const rows = 20000
+	rng := rand.New(rand.NewSource(1))
+	var salaries, scores []float64
+	departmentCounts := make(map[string]int)
+	for i := 1; i <= rows; i++ {
+		row := gen.Row(rng, i)
+		salary, err := strconv.Atoi(row[4])
+		if err != nil {
+			t.Fatalf("Expected an integer salary, got %s", row[4])
+		}
+		score, err := strconv.ParseFloat(row[8], 64)
+		if err != nil {
+			t.Fatalf("Expected a numeric score, got %s", row[8])
+		}
+		salaries = append(salaries, float64(salary))
+		scores = append(scores, score)
+		departmentCounts[row[5]]++
+	}
+
+	// The median of a lognormal is exp(mu), and the mean of an exponential its parameter
+	sort.Float64s(salaries)
+	if median := salaries[rows/2]; math.Abs(median-math.Exp(10.5))/math.Exp(10.5) > 0.03 {
+		t.Errorf("Expected a salary median near %.0f, got %.0f", math.Exp(10.5), median)
+	}
+	sum := 0.0
+	for _, s := range scores {
+		sum += s
+	}
+	if mean := sum / rows; math.Abs(mean-10) > 0.5 {
+		t.Errorf("Expected a score mean near 10, got %.2f", mean)
+	}
+	// With s=1.5 over 8 departments the first one gets about 52% of rows
+	if share := float64(departmentCounts[departments[0]]) / rows; share < 0.49 || share > 0.55 {
+		t.Errorf("Expected about 52%% %s, got %.3f", departments[0], share)
+	}
+
+	// Schema bounds are respected
+	lo, hi := 10.0, 20.0
+	schema := &tablestats.Schema{Columns: []tablestats.ColumnSchema{
+		{Name: "level", Type: "int64", Min: &lo, Max: &hi},
+		{Name: "wait", Type: "float64", Max: &hi},
+		{Name: "tier", Allowed: []string{"gold", "silver", "bronze"}},
+	}}
+	sg, err := NewSchemaGenerator(schema)
+	if err != nil {
+		t.Fatalf("NewSchemaGenerator failed: %v", err)
+	}
+	bounded, err := NewDistributor(sg, map[string]Distribution{
+		"level": dist("zipf(2)"),
+		"wait":  dist("exponential(5)"),
+		"tier":  dist("zipf(3)"),
+	})
+	if err != nil {
+		t.Fatalf("NewDistributor failed: %v", err)
+	}
+	levels := make(map[string]int)
+	for i := 1; i <= 1000; i++ {
+		row := bounded.Row(rng, i)
+		level, _ := strconv.Atoi(row[0])
+		wait, _ := strconv.ParseFloat(row[1], 64)
+		if level < 10 || level > 20 || wait < 0 || wait > 20 {
+			t.Fatalf("Expected values within the schema bounds, got %v", row)
+		}
+		levels[row[0]]++
+	}
+	if levels["10"] < levels["11"] || levels["11"] < levels["12"] {
+		t.Errorf("Expected the lowest levels to be the most frequent, got %v", levels)
+	}
+
+	invalid := []map[string]Distribution{
+		{"height": dist("zipf(2)")},
+		{"name": dist("lognormal(1,1)")},
+		{"tier": dist("exponential(1)")},
+	}
+	for _, dists := range invalid {
+		var g RowGenerator = Employees{}
+		if _, ok := dists["tier"]; ok {
+			g = sg
+		}
+		if _, err := NewDistributor(g, dists); err == nil {
+			t.Errorf("Expected error for %v", dists)
+		}
+	}
+}
+
+func TestTimeSeries(t *testing.T) {
+	opts := TimeSeriesOptions{
+		Start:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Interval:  time.Minute,
+		Series:    3,
+		Period:    time.Hour,
+		Amplitude: 50,
+		GapRate:   0.01,
+		GapLength: 5,
+	}
+	const rows = 30000
+	ts, err := NewTimeSeries(opts, rows, 9)
+	if err != nil {
+		t.Fatalf("NewTimeSeries failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(context.Background(), &buf, ts, Config{Rows: rows, Workers: 4, Seed: 9}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse generated CSV: %v", err)
+	}
+
+	// Every timestamp has one row per series, and gaps are whole missing intervals
+	var prev time.Time
+	gaps, missing := 0, 0
+	var peak, trough float64
+	for i, record := range records[1:] {
+		stamp, err := time.Parse(time.RFC3339, record[0])
+		if err != nil {
+			t.Fatalf("Expected an RFC 3339 timestamp, got %s", record[0])
+		}
+		if want := "series_" + strconv.Itoa(i%3+1); record[1] != want {
+			t.Fatalf("Expected %s at row %d, got %s", want, i+1, record[1])
+		}
+		if i%3 != 0 {
+			if !stamp.Equal(prev) {
+				t.Fatalf("Expected the series of row %d to share a timestamp", i+1)
+			}
+			continue
+		}
+		if i > 0 {
+			step := stamp.Sub(prev)
+			if step < time.Minute || step%time.Minute != 0 {
+				t.Fatalf("Expected whole intervals between timestamps, got %v at row %d", step, i+1)
+			}
+			if step > time.Minute {
+				gaps++
+				missing += int(step/time.Minute) - 1
+			}
+		}
+		prev = stamp
+
+		// Readings at the peak and trough of the hourly cycle
+		value, _ := strconv.ParseFloat(record[2], 64)
+		switch stamp.Minute() {
+		case 15:
+			peak += value
+		case 45:
+			trough += value
+		}
+	}
+
+	wantGaps, wantMissing := ts.Gaps()
+	if gaps != wantGaps || missing != wantMissing || gaps == 0 {
+		t.Errorf("Expected %d gaps with %d missing intervals, got %d with %d", wantGaps, wantMissing, gaps, missing)
+	}
+	if peak <= trough {
+		t.Errorf("Expected seasonal peaks above troughs, got %.0f and %.0f", peak, trough)
+	}
+
+	invalid := []TimeSeriesOptions{
+		{Interval: 0, Series: 1},
+		{Interval: time.Second, Series: 0},
+		{Interval: time.Second, Series: 1, GapRate: 1},
+		{Interval: time.Second, Series: 1, GapRate: 0.1},
+	}
+	for _, opts := range invalid {
+		if _, err := NewTimeSeries(opts, 10, 1); err == nil {
+			t.Errorf("Expected error for %+v", opts)
+		}
+	}
+}
+
+func TestGenerate_Malformed(t *testing.T) {
+	config := Config{Rows: 20000, Workers: 4, Seed: 3, MalformedRate: 0.005}
+	if got := config.MalformedRows(); got != 100 {
+		t.Fatalf("Expected 100 malformed rows, got %d", got)
+	}
+
+	var buf bytes.Buffer
+	if err := Generate(context.Background(), &buf, Employees{}, config); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	// Every malformed row breaks the CSV in one of the three ways; count
+	// the rows a lenient reader sees as broken
+	data := buf.Bytes()
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse generated CSV: %v", err)
+	}
+	wrongCount, quoted := 0, 0
+	for _, record := range records[1:] {
+		if len(record) != 10 {
+			wrongCount++
+		}
+		if strings.Contains(strings.Join(record, ","), `"`) {
+			quoted++
+		}
+	}
+	// A line break splits a row into two ragged ones
+	if wrongCount == 0 || quoted == 0 || wrongCount+quoted > 2*config.MalformedRows() || wrongCount+quoted < config.MalformedRows() {
+		t.Errorf("Expected about %d malformed rows, got %d ragged and %d with stray quotes", config.MalformedRows(), wrongCount, quoted)
+	}
+
+	strict := csv.NewReader(bytes.NewReader(data))
+	if _, err := strict.ReadAll(); err == nil {
+		t.Error("Expected the strict reader to reject the output")
+	}
+
+	var jsonl bytes.Buffer
+	config.Format = "jsonl"
+	if err := Generate(context.Background(), &jsonl, Employees{}, config); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	invalid := 0
+	for _, line := range strings.Split(strings.TrimSuffix(jsonl.String(), "\n"), "\n") {
+		if !json.Valid([]byte(line)) {
+			invalid++
+		}
+	}
+	if invalid == 0 {
+		t.Error("Expected invalid JSON lines")
+	}
+
+	config.Format = "parquet"
+	if err := Generate(context.Background(), io.Discard, Employees{}, config); err == nil {
+		t.Error("Expected error for malformed Parquet rows")
+	}
+}
+
+func TestProfileGenerator(t *testing.T) {
+	// Profile a generated dataset, then check that data synthesized from the
+	// profile has the same shape
+	var src bytes.Buffer
+	base, err := NewInjector(Employees{}, map[string]float64{"age": 0.2}, nil)
+	if err != nil {
+		t.Fatalf("NewInjector failed: %v", err)
+	}
+	if err := Generate(context.Background(), &src, base, Config{Rows: 5000, Seed: 1}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	profile := func(data []byte) *tablestats.TableStats {
+		stats, err := tablestats.NewCSVReader(',').ReadTableFrom(context.Background(), bytes.NewReader(data), int64(len(data)), tablestats.DefaultSamplingConfig())
+		if err != nil {
+			t.Fatalf("ReadTableFrom failed: %v", err)
+		}
+		return stats
+	}
+	original := profile(src.Bytes())
+
+	// The generator must work from a saved profile
+	data, err := tablestats.Marshal(original)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	saved, err := tablestats.Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	gen, err := NewProfileGenerator(saved)
+	if err != nil {
+		t.Fatalf("NewProfileGenerator failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := Generate(context.Background(), &out, gen, Config{Rows: 5000, Workers: 2, Seed: 2}); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	synth := profile(out.Bytes())
+
+	if !reflect.DeepEqual(synth.ColumnNames, original.ColumnNames) || !reflect.DeepEqual(synth.ColumnTypes, original.ColumnTypes) {
+		t.Fatalf("Expected columns %v, got %v", original.ColumnTypes, synth.ColumnTypes)
+	}
+	if got := synth.NullPercentage["age"]; math.Abs(got-original.NullPercentage["age"]) > 3 {
+		t.Errorf("Expected about %.1f%% null ages, got %.1f%%", original.NullPercentage["age"], got)
+	}
+	if !reflect.DeepEqual(synth.Categories["department"], original.Categories["department"]) {
+		t.Errorf("Expected departments %v, got %v", original.Categories["department"], synth.Categories["department"])
+	}
+	if synth.DistinctCounts["id"] != 5000 {
+		t.Errorf("Expected unique ids, got %d distinct", synth.DistinctCounts["id"])
+	}
+	for _, name := range []string{"salary", "score"} {
+		want, got := original.Aggregates[name], synth.Aggregates[name]
+		for _, p := range []int{25, 50, 75, 90} {
+			if diff := math.Abs(got.Percentiles[p]-want.Percentiles[p]) / want.Percentiles[p]; diff > 0.05 {
+				t.Errorf("Expected %s p%d near %.2f, got %.2f", name, p, want.Percentiles[p], got.Percentiles[p])
+			}
+		}
+	}
+	for _, name := range []string{"age", "join_date"} {
+		want, got := float64(original.DistinctCounts[name]), float64(synth.DistinctCounts[name])
+		if math.Abs(got-want)/want > 0.1 {
+			t.Errorf("Expected about %.0f distinct %s values, got %.0f", want, name, got)
+		}
+	}
+	if min, max := synth.MinValues["join_date"].(string), synth.MaxValues["join_date"].(string); min < original.MinValues["join_date"].(string) || max > original.MaxValues["join_date"].(string) {
+		t.Errorf("Expected join dates within the original range, got %s to %s", min, max)
+	}
+
+	if _, err := NewProfileGenerator(&tablestats.TableStats{}); err == nil {
+		t.Error("Expected error for a profile without columns")
+	}
+}
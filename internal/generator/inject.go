@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"unicode/utf8"
+)
+
+// Injector wraps a RowGenerator and blanks or corrupts a share of the values
+// of chosen columns, to test how the profiler copes with missing and
+// malformed data. Rates are per value, between 0 and 1.
+type Injector struct {
+	Gen RowGenerator
+
+	nullRates  []float64 // By column position, 0 for untouched columns
+	dirtyRates []float64
+}
+
+// NewInjector creates an Injector for gen. nullRates and dirtyRates map
+// column names to the share of their values to leave empty or corrupt.
+func NewInjector(gen RowGenerator, nullRates, dirtyRates map[string]float64) (*Injector, error) {
+	header := gen.Header()
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[name] = i
+	}
+
+	inj := &Injector{
+		Gen:        gen,
+		nullRates:  make([]float64, len(header)),
+		dirtyRates: make([]float64, len(header)),
+	}
+	for _, rates := range []struct {
+		kind   string
+		byName map[string]float64
+		out    []float64
+	}{
+		{"null", nullRates, inj.nullRates},
+		{"dirty", dirtyRates, inj.dirtyRates},
+	} {
+		names := make([]string, 0, len(rates.byName))
+		for name := range rates.byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rate := rates.byName[name]
+			idx, ok := positions[name]
+			if !ok {
+				return nil, fmt.Errorf("%s rate for unknown column %q", rates.kind, name)
+			}
+			if rate < 0 || rate > 1 {
+				return nil, fmt.Errorf("%s rate for column %q must be between 0 and 1", rates.kind, name)
+			}
+			rates.out[idx] = rate
+		}
+	}
+	for i := range header {
+		if inj.nullRates[i]+inj.dirtyRates[i] > 1 {
+			return nil, fmt.Errorf("null and dirty rates for column %q add up to more than 1", header[i])
+		}
+	}
+	return inj, nil
+}
+
+func (inj *Injector) Header() []string {
+	return inj.Gen.Header()
+}
+
+// ColumnTypes passes on the wrapped generator's types, except that corrupted
+// columns become text
+func (inj *Injector) ColumnTypes() []string {
+	types := make([]string, len(inj.nullRates))
+	if typer, ok := inj.Gen.(ColumnTyper); ok {
+		copy(types, typer.ColumnTypes())
+	}
+	for i, rate := range inj.dirtyRates {
+		if rate > 0 {
+			types[i] = "string"
+		}
+	}
+	return types
+}
+
+func (inj *Injector) Row(rng *rand.Rand, id int) []string {
+	row := inj.Gen.Row(rng, id)
+	for i := range row {
+		if inj.nullRates[i] == 0 && inj.dirtyRates[i] == 0 {
+			continue
+		}
+		switch p := rng.Float64(); {
+		case p < inj.nullRates[i]:
+			row[i] = ""
+		case p < inj.nullRates[i]+inj.dirtyRates[i]:
+			row[i] = dirtyValue(rng, row[i])
+		}
+	}
+	return row
+}
+
+// dirtyValue mangles a value the way hand-edited or badly exported data
+// often is, so that it no longer parses as its column's type
+func dirtyValue(rng *rand.Rand, value string) string {
+	switch rng.Intn(3) {
+	case 0:
+		return value + "?"
+	case 1:
+		return "#" + value
+	default:
+		mid := len(value) / 2
+		for mid > 0 && mid < len(value) && !utf8.RuneStart(value[mid]) {
+			mid--
+		}
+		return value[:mid] + "~" + value[mid:]
+	}
+}
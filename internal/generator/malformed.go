@@ -0,0 +1,85 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"unicode/utf8"
+)
+
+// Ways a row can be malformed
+const (
+	malformedFieldCount = iota // One field too few or too many
+	malformedQuote             // A stray quote inside a field
+	malformedNewline           // A line break inside an unquoted field
+	malformedKinds
+)
+
+// malformation marks a row of a batch to write malformed
+type malformation struct {
+	row   int
+	kind  int
+	field int // Field to corrupt; for malformedFieldCount, an even field drops the last field
+}
+
+// MalformedRows returns how many rows a run writes malformed
+func (c Config) MalformedRows() int {
+	total := 0
+	for start := 0; start < c.Rows; start += batchSize {
+		total += batchMalformed(min(batchSize, c.Rows-start), c.MalformedRate)
+	}
+	return total
+}
+
+func batchMalformed(size int, rate float64) int {
+	return int(math.Round(float64(size) * rate))
+}
+
+// malformRows picks the rows of a batch to write malformed and how
+func malformRows(rng *rand.Rand, size, fields int, rate float64) []malformation {
+	n := batchMalformed(size, rate)
+	if n <= 0 || fields == 0 {
+		return nil
+	}
+	rows := rng.Perm(size)[:n]
+	sort.Ints(rows)
+	malformed := make([]malformation, n)
+	for i, row := range rows {
+		malformed[i] = malformation{row: row, kind: rng.Intn(malformedKinds), field: rng.Intn(fields)}
+	}
+	return malformed
+}
+
+// malformFields applies a malformation to the fields of a delimited row and
+// returns the fields to write unquoted
+func malformFields(row []string, m malformation) []string {
+	fields := append([]string(nil), row...)
+	switch m.kind {
+	case malformedFieldCount:
+		if m.field%2 == 0 && len(fields) > 1 {
+			return fields[:len(fields)-1]
+		}
+		return append(fields, fields[m.field])
+	case malformedQuote:
+		fields[m.field] = splitInsert(fields[m.field], `"`)
+	default:
+		fields[m.field] = splitInsert(fields[m.field], "\n")
+	}
+	return fields
+}
+
+// splitInsert inserts s in the middle of value
+func splitInsert(value, s string) string {
+	mid := splitPoint(value)
+	return value[:mid] + s + value[mid:]
+}
+
+// splitPoint returns a rune boundary near the middle of value, after its
+// first byte so that an inserted quote does not open a quoted field
+func splitPoint(value string) int {
+	mid := min(max(len(value)/2, 1), len(value))
+	for mid < len(value) && !utf8.RuneStart(value[mid]) {
+		mid++
+	}
+	return mid
+}
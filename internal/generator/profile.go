@@ -0,0 +1,280 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+// maxPoolSize caps the number of distinct values kept per column
+const maxPoolSize = 100000
+
+// uniqueShare is the distinct share of non-null values from which a column is
+// treated as unique
+const uniqueShare = 0.95
+
+// profileQuantiles are the quantiles a profile records for numeric columns,
+// from the minimum to the maximum
+var profileQuantiles = []float64{0, 0.25, 0.5, 0.75, 0.90, 0.95, 0.99, 1}
+
+// dateLayouts are the layouts tried to recognize date columns from their
+// minimum and maximum
+var dateLayouts = []string{time.DateOnly, time.RFC3339, time.DateTime}
+
+// ProfileGenerator generates rows that resemble the data a profile describes,
+// without copying any of its values other than the listed categories:
+//   - the column order and types are kept;
+//   - values are left empty at each column's null rate;
+//   - numeric values follow the profile's percentiles, interpolated between
+//     the minimum, the recorded percentiles and the maximum;
+//   - categorical columns draw uniformly from their categories, other string
+//     columns from "<column>_<n>" values, or dates when the minimum and
+//     maximum are dates;
+//   - columns with repeated values draw from a pool sized so that as many
+//     rows as the profile counted would show its distinct count.
+//
+// Columns whose values were (nearly) all distinct stay unique; unique integer
+// columns without gaps, typically keys, become sequences from their minimum.
+type ProfileGenerator struct {
+	header  []string
+	columns []profileColumn
+}
+
+type profileColumn struct {
+	name      string
+	typ       string
+	nullRate  float64
+	quantiles []float64 // Values at profileQuantiles, for numeric columns
+	decimals  int       // Decimals of float64 values
+	pool      []string  // Values to pick from; nil when values are drawn fresh
+	unique    bool
+	sequence  bool        // Unique integers written as a sequence from the minimum
+	dates     []time.Time // Range of date columns
+	layout    string
+}
+
+// NewProfileGenerator creates a generator from a profile
+func NewProfileGenerator(stats *tablestats.TableStats) (*ProfileGenerator, error) {
+	if len(stats.ColumnNames) == 0 {
+		return nil, fmt.Errorf("profile has no columns")
+	}
+
+	g := &ProfileGenerator{header: stats.ColumnNames}
+	for _, name := range stats.ColumnNames {
+		col := profileColumn{name: name, typ: stats.ColumnTypes[name], nullRate: stats.NullPercentage[name] / 100}
+		if col.typ == "" {
+			col.typ = "string"
+		}
+
+		nonNull := stats.RowCount - stats.NullCounts[name]
+		distinct := stats.DistinctCounts[name]
+		col.unique = nonNull > 1 && float64(distinct) >= uniqueShare*float64(nonNull)
+
+		switch col.typ {
+		case "int64", "float64":
+			q, err := profileQuantileValues(stats, name)
+			if err != nil {
+				return nil, err
+			}
+			col.quantiles = q
+			col.decimals = floatDecimals(stats.MinValues[name], stats.MaxValues[name])
+			// Unique integers spanning about as many values as rows are keys
+			col.sequence = col.unique && col.typ == "int64" && q[len(q)-1]-q[0] < 2*float64(nonNull)
+		default:
+			if categories := stats.Categories[name]; len(categories) > 0 {
+				col.pool = categories
+				col.unique = false
+				break
+			}
+			col.dates, col.layout = dateRange(stats.MinValues[name], stats.MaxValues[name])
+		}
+
+		if !col.unique && col.pool == nil {
+			// Evenly spread over the distribution, so that picking uniformly
+			// from the pool follows it and values rarely coincide
+			size := int(min(populationSize(distinct, nonNull), maxPoolSize))
+			col.pool = make([]string, size)
+			for i := range col.pool {
+				col.pool[i] = col.value((float64(i)+0.5)/float64(size), i+1)
+			}
+		}
+		g.columns = append(g.columns, col)
+	}
+	return g, nil
+}
+
+// populationSize estimates how many distinct values a column draws from, given
+// that rows uniform draws found distinct of them: the size k for which
+// k·(1 - e^(-rows/k)) = distinct
+func populationSize(distinct, rows int64) int64 {
+	if distinct < 1 || rows <= distinct {
+		return max(distinct, 1)
+	}
+	expected := func(k float64) float64 {
+		return k * (1 - math.Exp(-float64(rows)/k))
+	}
+	lo, hi := float64(distinct), float64(distinct)
+	for expected(hi) < float64(distinct) && hi < maxPoolSize {
+		hi *= 2
+	}
+	for i := 0; i < 50; i++ {
+		mid := (lo + hi) / 2
+		if expected(mid) < float64(distinct) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return int64(math.Round(hi))
+}
+
+// profileQuantileValues returns the values of a numeric column at profileQuantiles
+func profileQuantileValues(stats *tablestats.TableStats, name string) ([]float64, error) {
+	lo, okLo := toFloat(stats.MinValues[name])
+	hi, okHi := toFloat(stats.MaxValues[name])
+	agg := stats.Aggregates[name]
+	if !okLo || !okHi || agg == nil {
+		// An all-null column has no range
+		if stats.NullCounts[name] == stats.RowCount {
+			return []float64{0, 0}, nil
+		}
+		return nil, fmt.Errorf("numeric column %q has no minimum, maximum or aggregates", name)
+	}
+
+	values := []float64{lo}
+	for _, p := range []int{25, 50, 75, 90, 95, 99} {
+		v, ok := agg.Percentiles[p]
+		if p == 50 && !ok {
+			v, ok = agg.Median, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("numeric column %q has no %dth percentile", name, p)
+		}
+		values = append(values, v)
+	}
+	values = append(values, hi)
+
+	// Estimated percentiles can be slightly out of order
+	for i := 1; i < len(values); i++ {
+		values[i] = math.Max(values[i], values[i-1])
+	}
+	return values, nil
+}
+
+// floatDecimals returns the number of decimals to write float values with: as
+// many as the minimum or maximum have, at least 2 and at most 6
+func floatDecimals(values ...any) int {
+	decimals := 2
+	for _, v := range values {
+		f, ok := toFloat(v)
+		if !ok {
+			continue
+		}
+		text := strconv.FormatFloat(f, 'f', -1, 64)
+		if dot := strings.IndexByte(text, '.'); dot >= 0 {
+			decimals = max(decimals, len(text)-dot-1)
+		}
+	}
+	return min(decimals, 6)
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// dateRange returns the range and layout of a column whose minimum and
+// maximum are dates, or nothing
+func dateRange(minVal, maxVal any) ([]time.Time, string) {
+	lo, okLo := minVal.(string)
+	hi, okHi := maxVal.(string)
+	if !okLo || !okHi {
+		return nil, ""
+	}
+	for _, layout := range dateLayouts {
+		start, errLo := time.Parse(layout, lo)
+		end, errHi := time.Parse(layout, hi)
+		if errLo == nil && errHi == nil && !end.Before(start) {
+			return []time.Time{start, end}, layout
+		}
+	}
+	return nil, ""
+}
+
+func (g *ProfileGenerator) Header() []string {
+	return g.header
+}
+
+func (g *ProfileGenerator) ColumnTypes() []string {
+	types := make([]string, len(g.columns))
+	for i := range g.columns {
+		types[i] = g.columns[i].typ
+	}
+	return types
+}
+
+func (g *ProfileGenerator) Row(rng *rand.Rand, id int) []string {
+	row := make([]string, len(g.columns))
+	for i := range g.columns {
+		col := &g.columns[i]
+		switch {
+		case col.nullRate > 0 && rng.Float64() < col.nullRate:
+		case col.pool != nil:
+			row[i] = col.pool[rng.Intn(len(col.pool))]
+		default:
+			row[i] = col.value(rng.Float64(), id)
+		}
+	}
+	return row
+}
+
+// value returns the value at quantile u of the column. n, a row id or pool
+// position, numbers string values and unique integers instead.
+func (c *profileColumn) value(u float64, n int) string {
+	switch {
+	case c.sequence:
+		return strconv.FormatInt(int64(c.quantiles[0])+int64(n)-1, 10)
+	case c.quantiles != nil:
+		v := interpolateQuantile(c.quantiles, u)
+		if c.typ == "int64" {
+			return strconv.FormatInt(int64(math.Round(v)), 10)
+		}
+		return strconv.FormatFloat(v, 'f', c.decimals, 64)
+	case c.dates != nil:
+		offset := time.Duration(u * float64(c.dates[1].Sub(c.dates[0])))
+		if c.layout == time.DateOnly {
+			offset = offset.Truncate(24 * time.Hour)
+		}
+		return c.dates[0].Add(offset).Format(c.layout)
+	default:
+		return c.name + "_" + strconv.Itoa(n)
+	}
+}
+
+// interpolateQuantile maps u in [0, 1) onto the piecewise-linear distribution
+// through the values at profileQuantiles
+func interpolateQuantile(values []float64, u float64) float64 {
+	if len(values) != len(profileQuantiles) {
+		return values[0]
+	}
+	i := sort.SearchFloat64s(profileQuantiles, u)
+	if i == 0 {
+		return values[0]
+	}
+	q0, q1 := profileQuantiles[i-1], profileQuantiles[i]
+	return values[i-1] + (values[i]-values[i-1])*(u-q0)/(q1-q0)
+}
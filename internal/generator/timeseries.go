@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Level and noise of time series values around the seasonal curve
+const (
+	seriesLevel = 100
+	seriesNoise = 5
+)
+
+// TimeSeriesOptions describes the timestamped readings TimeSeries generates
+type TimeSeriesOptions struct {
+	Start    time.Time
+	Interval time.Duration // Time between consecutive readings of a series
+	Series   int           // Number of interleaved series; every timestamp has a row per series
+
+	// Values follow Amplitude*sin(2πt/Period) around a constant level, plus
+	// noise. A zero Period disables seasonality.
+	Period    time.Duration
+	Amplitude float64
+
+	// GapRate is the share of timestamps preceded by a gap of 1 to GapLength
+	// missing intervals, during which no series has readings
+	GapRate   float64
+	GapLength int
+}
+
+// TimeSeries generates event data: a timestamp, a series name and a value per
+// row, in timestamp order
+type TimeSeries struct {
+	opts TimeSeriesOptions
+
+	gapSlots   []int // Slots preceded by a gap, ascending
+	gapOffsets []int // Missing intervals up to and including each gap
+}
+
+// NewTimeSeries creates a generator for rows rows. Gaps are placed up front
+// from seed, so that any batch of rows can be generated independently.
+func NewTimeSeries(opts TimeSeriesOptions, rows int, seed int64) (*TimeSeries, error) {
+	switch {
+	case opts.Interval <= 0:
+		return nil, fmt.Errorf("interval must be positive")
+	case opts.Series < 1:
+		return nil, fmt.Errorf("number of series must be at least 1")
+	case opts.Period < 0:
+		return nil, fmt.Errorf("period must not be negative")
+	case opts.GapRate < 0 || opts.GapRate >= 1:
+		return nil, fmt.Errorf("gap rate must be at least 0 and below 1")
+	case opts.GapRate > 0 && opts.GapLength < 1:
+		return nil, fmt.Errorf("gap length must be at least 1")
+	}
+
+	ts := &TimeSeries{opts: opts}
+	if opts.GapRate > 0 {
+		rng := rand.New(rand.NewSource(seed))
+		slots := (rows + opts.Series - 1) / opts.Series
+		missing := 0
+		// Distances between gaps are geometric, so only the gaps are drawn
+		for slot := 0; ; {
+			slot += 1 + int(math.Log(1-rng.Float64())/math.Log(1-opts.GapRate))
+			if slot >= slots {
+				break
+			}
+			missing += 1 + rng.Intn(opts.GapLength)
+			ts.gapSlots = append(ts.gapSlots, slot)
+			ts.gapOffsets = append(ts.gapOffsets, missing)
+		}
+	}
+	return ts, nil
+}
+
+// Gaps returns the number of gaps and of missing intervals in the generated
+// rows, to compare gap detection against
+func (ts *TimeSeries) Gaps() (gaps, missing int) {
+	if len(ts.gapSlots) == 0 {
+		return 0, 0
+	}
+	return len(ts.gapSlots), ts.gapOffsets[len(ts.gapOffsets)-1]
+}
+
+func (ts *TimeSeries) Header() []string {
+	return []string{"timestamp", "series", "value"}
+}
+
+func (ts *TimeSeries) ColumnTypes() []string {
+	return []string{"string", "string", "float64"}
+}
+
+func (ts *TimeSeries) Row(rng *rand.Rand, id int) []string {
+	slot := (id - 1) / ts.opts.Series
+	series := (id - 1) % ts.opts.Series
+
+	// Shift the slot by the intervals missing before it
+	if i := sort.SearchInts(ts.gapSlots, slot+1) - 1; i >= 0 {
+		slot += ts.gapOffsets[i]
+	}
+	elapsed := time.Duration(slot) * ts.opts.Interval
+
+	value := seriesLevel + seriesNoise*rng.NormFloat64()
+	if ts.opts.Period > 0 {
+		value += ts.opts.Amplitude * math.Sin(2*math.Pi*float64(elapsed)/float64(ts.opts.Period))
+	}
+
+	return []string{
+		ts.opts.Start.Add(elapsed).UTC().Format(time.RFC3339),
+		"series_" + strconv.Itoa(series+1),
+		strconv.FormatFloat(value, 'f', 2, 64),
+	}
+}
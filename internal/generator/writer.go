@@ -0,0 +1,313 @@
+package generator
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/compress"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+)
+
+// Formats lists the output formats Generate supports
+var Formats = []string{"csv", "tsv", "jsonl", "parquet"}
+
+// ColumnTyper is implemented by generators that know the type of each column
+// (int64, float64 or string). Typed formats such as Parquet and JSON Lines use
+// it; without it every column is written as text.
+type ColumnTyper interface {
+	ColumnTypes() []string
+}
+
+// FormatFromPath picks the output format from a file extension, defaulting to
+// CSV. A compression extension is skipped, so data.tsv.gz is TSV.
+func FormatFromPath(path string) string {
+	if CompressionFromPath(path) != "" {
+		path = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".tsv", ".tab":
+		return "tsv"
+	case ".jsonl", ".ndjson":
+		return "jsonl"
+	case ".parquet":
+		return "parquet"
+	default:
+		return "csv"
+	}
+}
+
+// rowWriter writes generated rows in one output format
+type rowWriter interface {
+	write(rows [][]string) error
+	writeMalformed(row []string, m malformation) error
+	// close flushes buffered output; it does not close the underlying writer
+	close() error
+}
+
+// newRowWriter creates a writer for the format, writing the header where the
+// format has one
+func newRowWriter(format string, w io.Writer, gen RowGenerator) (rowWriter, error) {
+	header := gen.Header()
+	types := make([]string, len(header))
+	if typer, ok := gen.(ColumnTyper); ok {
+		copy(types, typer.ColumnTypes())
+	}
+
+	switch format {
+	case "", "csv", "tsv":
+		writer := csv.NewWriter(w)
+		if format == "tsv" {
+			writer.Comma = '\t'
+		}
+		if err := writer.Write(header); err != nil {
+			return nil, fmt.Errorf("writing header: %w", err)
+		}
+		return &csvRowWriter{w: w, writer: writer}, nil
+	case "jsonl":
+		return newJSONLRowWriter(w, header, types), nil
+	case "parquet":
+		return newParquetRowWriter(w, header, types)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (use %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+type csvRowWriter struct {
+	w      io.Writer
+	writer *csv.Writer
+}
+
+func (c *csvRowWriter) write(rows [][]string) error {
+	if err := c.writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	return nil
+}
+
+func (c *csvRowWriter) writeMalformed(row []string, m malformation) error {
+	fields := malformFields(row, m)
+	if m.kind == malformedFieldCount {
+		return c.write([][]string{fields})
+	}
+
+	// Quoting would make the row valid again, so it bypasses the CSV writer
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	if _, err := io.WriteString(c.w, strings.Join(fields, string(c.writer.Comma))+"\n"); err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	return nil
+}
+
+func (c *csvRowWriter) close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// jsonlRowWriter writes one JSON object per row, keyed by the header. Empty
+// values become null and values of numeric columns become JSON numbers.
+type jsonlRowWriter struct {
+	out   *bufio.Writer
+	keys  [][]byte // Encoded `"name":` prefixes
+	types []string
+	line  []byte
+}
+
+func newJSONLRowWriter(w io.Writer, header, types []string) *jsonlRowWriter {
+	keys := make([][]byte, len(header))
+	for i, name := range header {
+		keys[i] = append(appendJSONString(nil, name), ':')
+	}
+	return &jsonlRowWriter{out: bufio.NewWriter(w), keys: keys, types: types}
+}
+
+func (j *jsonlRowWriter) write(rows [][]string) error {
+	for _, row := range rows {
+		if err := j.writeLine(j.appendObject(j.line[:0], row, nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeMalformed writes an object with a missing or an unknown key, or one
+// whose string value lacks its closing quote or contains a raw line break
+func (j *jsonlRowWriter) writeMalformed(row []string, m malformation) error {
+	switch {
+	case m.kind == malformedFieldCount && m.field%2 == 0 && len(row) > 1:
+		return j.writeLine(j.appendObject(j.line[:0], row[:len(row)-1], nil))
+	case m.kind == malformedFieldCount:
+		line := j.appendObject(j.line[:0], row, nil)
+		return j.writeLine(append(line[:len(line)-1], `,"extra":null}`...))
+	default:
+		return j.writeLine(j.appendObject(j.line[:0], row, &m))
+	}
+}
+
+// appendObject appends a row as a JSON object, corrupting the field m names
+func (j *jsonlRowWriter) appendObject(dst []byte, row []string, m *malformation) []byte {
+	dst = append(dst, '{')
+	for i, value := range row {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, j.keys[i]...)
+		switch {
+		case m == nil || m.field != i:
+			dst = appendJSONValue(dst, value, j.types[i])
+		case m.kind == malformedQuote:
+			dst = appendJSONString(dst, value)
+			dst = dst[:len(dst)-1]
+		default:
+			mid := splitPoint(value)
+			dst = appendJSONString(dst, value[:mid])
+			dst = append(dst[:len(dst)-1], '\n')
+			dst = append(dst, appendJSONString(nil, value[mid:])[1:]...)
+		}
+	}
+	return append(dst, '}')
+}
+
+func (j *jsonlRowWriter) writeLine(line []byte) error {
+	j.line = append(line, '\n')
+	if _, err := j.out.Write(j.line); err != nil {
+		return fmt.Errorf("writing record: %w", err)
+	}
+	return nil
+}
+
+func (j *jsonlRowWriter) close() error {
+	return j.out.Flush()
+}
+
+func appendJSONValue(dst []byte, value, typ string) []byte {
+	if value == "" {
+		return append(dst, "null"...)
+	}
+	if typ == "int64" || typ == "float64" {
+		// Only finite decimals are valid JSON numbers
+		if v, err := strconv.ParseFloat(value, 64); err == nil && !strings.ContainsAny(value, "xXpPiInN_") {
+			return strconv.AppendFloat(dst, v, 'f', -1, 64)
+		}
+	}
+	return appendJSONString(dst, value)
+}
+
+// appendJSONString appends s as a quoted JSON string
+func appendJSONString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '"' || c == '\\':
+			dst = append(dst, '\\', c)
+		case c < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		case c < utf8.RuneSelf:
+			dst = append(dst, c)
+		default:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			dst = utf8.AppendRune(dst, r) // Invalid bytes become U+FFFD
+			i += size
+			continue
+		}
+		i++
+	}
+	return append(dst, '"')
+}
+
+// parquetRowWriter writes every batch as a Parquet row group. Columns get the
+// generator's types; values that do not parse as their type are stored as nulls.
+type parquetRowWriter struct {
+	file    *pqarrow.FileWriter
+	builder *array.RecordBuilder
+	types   []string
+}
+
+func newParquetRowWriter(w io.Writer, header, types []string) (*parquetRowWriter, error) {
+	fields := make([]arrow.Field, len(header))
+	for i, name := range header {
+		var typ arrow.DataType = arrow.BinaryTypes.String
+		switch types[i] {
+		case "int64":
+			typ = arrow.PrimitiveTypes.Int64
+		case "float64":
+			typ = arrow.PrimitiveTypes.Float64
+		}
+		fields[i] = arrow.Field{Name: name, Type: typ, Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	// The Parquet writer closes its sink, which belongs to the caller
+	sink := struct{ io.Writer }{w}
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	file, err := pqarrow.NewFileWriter(schema, sink, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, fmt.Errorf("creating parquet writer: %w", err)
+	}
+	return &parquetRowWriter{
+		file:    file,
+		builder: array.NewRecordBuilder(memory.DefaultAllocator, schema),
+		types:   types,
+	}, nil
+}
+
+func (p *parquetRowWriter) write(rows [][]string) error {
+	p.builder.Reserve(len(rows))
+	for i, field := range p.builder.Fields() {
+		for _, row := range rows {
+			value := row[i]
+			switch b := field.(type) {
+			case *array.Int64Builder:
+				if v, err := strconv.ParseInt(value, 10, 64); err == nil {
+					b.Append(v)
+				} else {
+					b.AppendNull()
+				}
+			case *array.Float64Builder:
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					b.Append(v)
+				} else {
+					b.AppendNull()
+				}
+			case *array.StringBuilder:
+				if value == "" {
+					b.AppendNull()
+				} else {
+					b.Append(value)
+				}
+			}
+		}
+	}
+	rec := p.builder.NewRecordBatch()
+	defer rec.Release()
+	if err := p.file.Write(rec); err != nil {
+		return fmt.Errorf("writing row group: %w", err)
+	}
+	return nil
+}
+
+func (p *parquetRowWriter) writeMalformed([]string, malformation) error {
+	return fmt.Errorf("malformed rows cannot be written as parquet")
+}
+
+func (p *parquetRowWriter) close() error {
+	p.builder.Release()
+	if err := p.file.Close(); err != nil {
+		return fmt.Errorf("closing parquet file: %w", err)
+	}
+	return nil
+}
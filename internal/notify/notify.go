@@ -0,0 +1,135 @@
+// Package notify posts summaries of failed quality checks to webhooks and
+// Slack.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxSlackFailures caps the failures listed in a Slack message; the full
+// list is in the report
+const maxSlackFailures = 20
+
+// Message summarizes a failed check
+type Message struct {
+	Check     string    `json:"check"`                // Command or dataset that failed, e.g. "check" or "daemon: orders"
+	Source    string    `json:"source"`               // File or URL that was checked
+	Failures  []string  `json:"failures"`             // Violated rules, one per line
+	ReportURL string    `json:"report_url,omitempty"` // Link to the full report
+	Time      time.Time `json:"time"`
+}
+
+// Title describes the message in one line
+func (m *Message) Title() string {
+	checks := "checks"
+	if len(m.Failures) == 1 {
+		checks = "check"
+	}
+	return fmt.Sprintf("%s failed for %s: %d failed %s", m.Check, m.Source, len(m.Failures), checks)
+}
+
+// Sender delivers messages
+type Sender interface {
+	Send(ctx context.Context, m *Message) error
+}
+
+// Webhook POSTs the message as JSON to a URL
+type Webhook struct {
+	URL    string
+	Client *http.Client // nil uses a client with a 30 second timeout
+}
+
+func (w *Webhook) Send(ctx context.Context, m *Message) error {
+	return post(ctx, w.Client, w.URL, m)
+}
+
+// Slack posts the message to a Slack incoming webhook URL
+type Slack struct {
+	URL    string
+	Client *http.Client // nil uses a client with a 30 second timeout
+}
+
+func (s *Slack) Send(ctx context.Context, m *Message) error {
+	var text strings.Builder
+	fmt.Fprintf(&text, ":rotating_light: *%s*\n", slackEscape(m.Title()))
+	for i, failure := range m.Failures {
+		if i == maxSlackFailures {
+			fmt.Fprintf(&text, "• … and %d more\n", len(m.Failures)-maxSlackFailures)
+			break
+		}
+		fmt.Fprintf(&text, "• %s\n", slackEscape(failure))
+	}
+	if m.ReportURL != "" {
+		fmt.Fprintf(&text, "<%s|Full report>\n", m.ReportURL)
+	}
+	return post(ctx, s.Client, s.URL, map[string]string{"text": text.String()})
+}
+
+// slackEscape escapes the characters Slack reads as markup
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// New returns the senders for a webhook and a Slack URL, either of which may
+// be empty
+func New(webhookURL, slackURL string) []Sender {
+	var senders []Sender
+	if webhookURL != "" {
+		senders = append(senders, &Webhook{URL: webhookURL})
+	}
+	if slackURL != "" {
+		senders = append(senders, &Slack{URL: slackURL})
+	}
+	return senders
+}
+
+// SendAll delivers a message through every sender, returning their errors
+// joined
+func SendAll(ctx context.Context, senders []Sender, m *Message) error {
+	var errs []error
+	for _, s := range senders {
+		if err := s.Send(ctx, m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func post(ctx context.Context, client *http.Client, target string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send notification: invalid URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// Webhook URLs hold secrets, so errors name the host only
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send notification to %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to send notification: %s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// capture records the bodies posted to each path; /fail answers with an error
+func capture(t *testing.T) (*httptest.Server, map[string][]byte) {
+	t.Helper()
+	bodies := make(map[string][]byte)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies[r.URL.Path] = body
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON request, got %q", r.Header.Get("Content-Type"))
+		}
+		if r.URL.Path == "/fail" {
+			http.Error(w, "no", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts, bodies
+}
+
+func TestSenders(t *testing.T) {
+	ts, bodies := capture(t)
+	msg := &Message{
+		Check:     "check",
+		Source:    "orders.csv",
+		Failures:  []string{"age: between violated by 3 values", "id: <unique> violated by 1 values"},
+		ReportURL: "https://ci.example.com/job/1",
+		Time:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	senders := New(ts.URL+"/hook", ts.URL+"/slack")
+	if err := SendAll(context.Background(), senders, msg); err != nil {
+		t.Fatalf("SendAll failed: %v", err)
+	}
+
+	var posted Message
+	if err := json.Unmarshal(bodies["/hook"], &posted); err != nil {
+		t.Fatalf("Failed to decode webhook body: %v", err)
+	}
+	if posted.Source != "orders.csv" || len(posted.Failures) != 2 || posted.ReportURL != msg.ReportURL || !posted.Time.Equal(msg.Time) {
+		t.Errorf("Unexpected webhook payload %+v", posted)
+	}
+
+	var slack struct{ Text string }
+	if err := json.Unmarshal(bodies["/slack"], &slack); err != nil {
+		t.Fatalf("Failed to decode Slack body: %v", err)
+	}
+	for _, want := range []string{
+		"*check failed for orders.csv: 2 failed checks*",
+		"• id: &lt;unique&gt; violated by 1 values",
+		"<https://ci.example.com/job/1|Full report>",
+	} {
+		if !strings.Contains(slack.Text, want) {
+			t.Errorf("Expected the Slack text to contain %q, got %q", want, slack.Text)
+		}
+	}
+
+	if senders := New("", ""); len(senders) != 0 {
+		t.Errorf("Expected no senders without URLs, got %d", len(senders))
+	}
+}
+
+func TestSlack_Truncates(t *testing.T) {
+	ts, bodies := capture(t)
+	msg := &Message{Check: "validate", Source: "a.csv"}
+	for i := 0; i < maxSlackFailures+5; i++ {
+		msg.Failures = append(msg.Failures, fmt.Sprintf("col%d: null violated by 1 values", i))
+	}
+	if err := (&Slack{URL: ts.URL + "/slack"}).Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	var slack struct{ Text string }
+	json.Unmarshal(bodies["/slack"], &slack)
+	if strings.Count(slack.Text, "• col") != maxSlackFailures || !strings.Contains(slack.Text, "… and 5 more") {
+		t.Errorf("Expected %d failures and a remainder line, got %q", maxSlackFailures, slack.Text)
+	}
+}
+
+func TestSendAll_Errors(t *testing.T) {
+	ts, _ := capture(t)
+	secret := ts.URL + "/fail?token=secret"
+	err := SendAll(context.Background(), []Sender{&Webhook{URL: secret}, &Webhook{URL: "http://127.0.0.1:1/hook?token=secret"}}, &Message{})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "127.0.0.1:1") {
+		t.Errorf("Expected both failures to be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected webhook URLs to be left out of errors, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Package push sends the metrics of profiling runs to a Prometheus
+// Pushgateway, so that batch jobs can be alerted on like other services.
+package push
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+// DefaultJob is the job label runs are pushed under
+const DefaultJob = "gotablestats"
+
+// Run is one profiling or checking run of a source
+type Run struct {
+	Command  string // e.g. "check" or "daemon"
+	Dataset  string // Daemon dataset name, empty otherwise
+	Source   string // File path or URL
+	Time     time.Time
+	Duration time.Duration
+	Stats    *tablestats.TableStats // nil when the source could not be profiled
+	// Violations counts offending values found by rules or a schema
+	Violations int64
+	// FailedChecks counts violated rules, failed expectations, drifts and
+	// duplicate keys
+	FailedChecks int
+	Failed       bool
+}
+
+// WriteMetrics writes the metrics of a run in the Prometheus text format
+func (r *Run) WriteMetrics(w io.Writer) error {
+	var b bytes.Buffer
+	gauge := func(name, help string, value float64) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", name, help, name, name, formatValue(value))
+	}
+	success := 1.0
+	if r.Failed {
+		success = 0
+	}
+	gauge("gotablestats_last_run_timestamp_seconds", "Time the run started, in seconds since the epoch.", float64(r.Time.UnixMilli())/1000)
+	gauge("gotablestats_run_duration_seconds", "Duration of the run.", r.Duration.Seconds())
+	gauge("gotablestats_run_success", "Whether the source was profiled and every check passed.", success)
+	gauge("gotablestats_violations", "Values violating quality rules or the schema.", float64(r.Violations))
+	gauge("gotablestats_failed_checks", "Violated rules, failed expectations, drifts and duplicate keys.", float64(r.FailedChecks))
+	if r.Stats == nil {
+		_, err := w.Write(b.Bytes())
+		return err
+	}
+
+	gauge("gotablestats_rows", "Estimated number of rows of the source.", float64(r.Stats.EstimatedRows))
+	gauge("gotablestats_rows_profiled", "Rows the profile was computed from.", float64(r.Stats.RowCount))
+	gauge("gotablestats_columns", "Number of columns.", float64(r.Stats.ColumnCount))
+	if len(r.Stats.ColumnNames) > 0 {
+		b.WriteString("# HELP gotablestats_null_ratio Share of null values of a column, from 0 to 1.\n# TYPE gotablestats_null_ratio gauge\n")
+		for _, name := range r.Stats.ColumnNames {
+			fmt.Fprintf(&b, "gotablestats_null_ratio{column=\"%s\"} %s\n", escapeLabel(name), formatValue(r.Stats.NullPercentage[name]/100))
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value of the text format
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// Pushgateway pushes runs to a Pushgateway. Each run replaces the metrics of
+// its group, which is keyed by job, command, dataset and source.
+type Pushgateway struct {
+	URL    string
+	Job    string       // Empty uses DefaultJob
+	Client *http.Client // nil uses a client with a 30 second timeout
+}
+
+// Push replaces the metrics of the run's group with those of r
+func (p *Pushgateway) Push(ctx context.Context, r *Run) error {
+	var body bytes.Buffer
+	if err := r.WriteMetrics(&body); err != nil {
+		return fmt.Errorf("failed to encode metrics: %w", err)
+	}
+	target, err := p.groupURL(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, &body)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: invalid URL")
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to push metrics to %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to push metrics: %s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// groupURL returns the URL of the run's group. Label values are base64
+// encoded, as sources usually contain slashes.
+func (p *Pushgateway) groupURL(r *Run) (string, error) {
+	u, err := url.Parse(p.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid pushgateway URL")
+	}
+	job := p.Job
+	if job == "" {
+		job = DefaultJob
+	}
+	path := strings.TrimSuffix(u.Path, "/") + "/metrics" + groupSegment("job", job) + groupSegment("command", r.Command)
+	if r.Dataset != "" {
+		path += groupSegment("dataset", r.Dataset)
+	}
+	u.Path, u.RawPath = path+groupSegment("source", r.Source), ""
+	return u.String(), nil
+}
+
+func groupSegment(label, value string) string {
+	if value == "" {
+		// The Pushgateway reads a lone "=" as the empty value
+		return "/" + label + "@base64/="
+	}
+	return "/" + label + "@base64/" + base64.URLEncoding.EncodeToString([]byte(value))
+}
@@ -0,0 +1,92 @@
+package push
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+func TestPush(t *testing.T) {
+	var method, path, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.EscapedPath(), string(data)
+	}))
+	defer ts.Close()
+
+	run := &Run{
+		Command:  "check",
+		Source:   "/data/orders.csv",
+		Time:     time.Unix(1700000000, 500000000),
+		Duration: 1500 * time.Millisecond,
+		Stats: &tablestats.TableStats{
+			RowCount:       100,
+			EstimatedRows:  1000,
+			ColumnCount:    2,
+			ColumnNames:    []string{"id", `say "hi"`},
+			NullPercentage: map[string]float64{"id": 0, `say "hi"`: 12.5},
+		},
+		Violations:   3,
+		FailedChecks: 1,
+		Failed:       true,
+	}
+	p := &Pushgateway{URL: ts.URL + "/gateway/"}
+	if err := p.Push(context.Background(), run); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Errorf("Expected PUT, got %s", method)
+	}
+	source := base64.URLEncoding.EncodeToString([]byte("/data/orders.csv"))
+	expectedPath := "/gateway/metrics/job@base64/Z290YWJsZXN0YXRz/command@base64/Y2hlY2s=/source@base64/" + source
+	if path != expectedPath {
+		t.Errorf("Expected path %s, got %s", expectedPath, path)
+	}
+	for _, want := range []string{
+		"# TYPE gotablestats_run_duration_seconds gauge\ngotablestats_run_duration_seconds 1.5\n",
+		"gotablestats_last_run_timestamp_seconds 1.7000000005e+09\n",
+		"gotablestats_run_success 0\n",
+		"gotablestats_violations 3\n",
+		"gotablestats_failed_checks 1\n",
+		"gotablestats_rows 1000\n",
+		"gotablestats_rows_profiled 100\n",
+		`gotablestats_null_ratio{column="id"} 0` + "\n",
+		`gotablestats_null_ratio{column="say \"hi\""} 0.125` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in metrics:\n%s", want, body)
+		}
+	}
+
+	// Without a profile only the run metrics are pushed
+	run.Stats, run.Dataset = nil, "orders"
+	if err := p.Push(context.Background(), run); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if strings.Contains(body, "gotablestats_rows") || !strings.Contains(path, "/dataset@base64/") {
+		t.Errorf("Unexpected push of a failed run to %s:\n%s", path, body)
+	}
+}
+
+func TestPush_Errors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad metrics", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	run := &Run{Command: "analyze", Source: "a.csv", Time: time.Now()}
+	if err := (&Pushgateway{URL: ts.URL}).Push(context.Background(), run); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Errorf("Expected the status in the error, got %v", err)
+	}
+	if err := (&Pushgateway{URL: "localhost:9091"}).Push(context.Background(), run); err == nil {
+		t.Error("Expected an error for a URL without scheme")
+	}
+}
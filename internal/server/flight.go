@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// FlightService serves the stored profiles over Arrow Flight, so that Python
+// and R clients can read them as dataframes without parsing JSON:
+//
+//	profile/<id>  One row per column with its statistics
+//	sample/<id>   The sampled rows, for profiles made with sample=true
+//
+// Tickets are these names; path descriptors are their segments, e.g.
+// ["profile", "<id>"]. ListFlights lists every profile and kept sample.
+type FlightService struct {
+	flight.BaseFlightServer
+	server *Server
+}
+
+// FlightService returns the Arrow Flight service of the server's profiles
+func (s *Server) FlightService() *FlightService {
+	return &FlightService{server: s}
+}
+
+func (f *FlightService) ListFlights(_ *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	f.server.mu.RLock()
+	profiles := make([]*Profile, 0, len(f.server.profiles))
+	for _, p := range f.server.profiles {
+		profiles = append(profiles, p)
+	}
+	f.server.mu.RUnlock()
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Created.After(profiles[j].Created) })
+
+	for _, p := range profiles {
+		names := []string{"profile/" + p.ID}
+		if p.Sample {
+			names = append(names, "sample/"+p.ID)
+		}
+		for _, name := range names {
+			info, err := f.flightInfo(name)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(info); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *FlightService) GetFlightInfo(_ context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	if desc.GetType() != flight.DescriptorPATH {
+		return nil, status.Error(codes.InvalidArgument, "expected a path descriptor such as [\"profile\", \"<id>\"]")
+	}
+	return f.flightInfo(strings.Join(desc.GetPath(), "/"))
+}
+
+func (f *FlightService) GetSchema(_ context.Context, desc *flight.FlightDescriptor) (*flight.SchemaResult, error) {
+	if desc.GetType() != flight.DescriptorPATH {
+		return nil, status.Error(codes.InvalidArgument, "expected a path descriptor such as [\"profile\", \"<id>\"]")
+	}
+	batch, err := f.batch(strings.Join(desc.GetPath(), "/"))
+	if err != nil {
+		return nil, err
+	}
+	defer batch.Release()
+	return &flight.SchemaResult{Schema: flight.SerializeSchema(batch.Schema(), memory.DefaultAllocator)}, nil
+}
+
+func (f *FlightService) DoGet(ticket *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	batch, err := f.batch(string(ticket.GetTicket()))
+	if err != nil {
+		return err
+	}
+	defer batch.Release()
+
+	writer := flight.NewRecordWriter(stream, ipc.WithSchema(batch.Schema()))
+	if err := writer.Write(batch); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+// flightInfo describes a profile or sample, to be fetched from this server
+// with its name as the ticket
+func (f *FlightService) flightInfo(name string) (*flight.FlightInfo, error) {
+	batch, err := f.batch(name)
+	if err != nil {
+		return nil, err
+	}
+	defer batch.Release()
+	return &flight.FlightInfo{
+		Schema:           flight.SerializeSchema(batch.Schema(), memory.DefaultAllocator),
+		FlightDescriptor: &flight.FlightDescriptor{Type: flight.DescriptorPATH, Path: strings.Split(name, "/")},
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: []byte(name)}}},
+		TotalRecords:     batch.NumRows(),
+		TotalBytes:       -1,
+	}, nil
+}
+
+// batch returns the record batch of a profile or sample name. The caller
+// must Release it.
+func (f *FlightService) batch(name string) (arrow.RecordBatch, error) {
+	kind, id, ok := strings.Cut(name, "/")
+	if !ok || (kind != "profile" && kind != "sample") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid ticket %q (expected profile/<id> or sample/<id>)", name)
+	}
+	p, ok := f.server.lookup(id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "profile %q not found", id)
+	}
+
+	if kind == "sample" {
+		if !p.Sample {
+			return nil, status.Errorf(codes.NotFound, "profile %q was made without sample=true", id)
+		}
+		batch, err := f.server.loadSample(id)
+		if err != nil {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return batch, nil
+	}
+	stats, err := tablestats.Unmarshal(p.Profile)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to read profile %q: %v", id, err)
+	}
+	return stats.ArrowColumnStats(memory.DefaultAllocator), nil
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/flight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// newFlightClient serves the Flight service of s on a local port
+func newFlightClient(t *testing.T, s *Server) flight.Client {
+	t.Helper()
+	fs := flight.NewServerWithMiddleware(nil)
+	if err := fs.Init("localhost:0"); err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	fs.RegisterFlightService(s.FlightService())
+	go fs.Serve()
+	t.Cleanup(fs.Shutdown)
+
+	client, err := flight.NewClientWithMiddleware(fs.Addr().String(), nil, nil, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+// fetch reads the rows of a ticket
+func fetch(t *testing.T, client flight.Client, ticket string) (*flight.Reader, error) {
+	t.Helper()
+	stream, err := client.DoGet(context.Background(), &flight.Ticket{Ticket: []byte(ticket)})
+	if err != nil {
+		return nil, err
+	}
+	reader, err := flight.NewRecordReader(stream)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(reader.Release)
+	return reader, nil
+}
+
+func TestFlightService(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	s := &Server{ResultsDir: dir}
+	ts := newTestServer(t, s)
+	var ids []string
+	for _, query := range []string{"", "?sample=true"} {
+		resp, err := http.DefaultClient.Do(uploadRequest(t, ts.URL+"/profile"+query, "data.csv", testCSV))
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		ids = append(ids, decodeProfile(t, resp, http.StatusCreated).ID)
+	}
+	client := newFlightClient(t, s)
+
+	list, err := client.ListFlights(context.Background(), &flight.Criteria{})
+	if err != nil {
+		t.Fatalf("ListFlights failed: %v", err)
+	}
+	flights := 0
+	for {
+		_, err := list.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("ListFlights failed: %v", err)
+		}
+		flights++
+	}
+	if flights != 3 {
+		t.Errorf("Expected 2 profiles and 1 sample, got %d flights", flights)
+	}
+
+	reader, err := fetch(t, client, "profile/"+ids[0])
+	if err != nil {
+		t.Fatalf("DoGet failed: %v", err)
+	}
+	if !reader.Next() || reader.RecordBatch().NumRows() != 3 {
+		t.Fatalf("Expected a row per column, got %v", reader.Err())
+	}
+	if got := reader.RecordBatch().Column(0).(*array.String).Value(2); got != "amount" {
+		t.Errorf("Expected the third row to describe amount, got %q", got)
+	}
+
+	info, err := client.GetFlightInfo(context.Background(), &flight.FlightDescriptor{Type: flight.DescriptorPATH, Path: []string{"sample", ids[1]}})
+	if err != nil || info.TotalRecords != 3 {
+		t.Fatalf("Expected the sample to hold 3 rows, got %v (%v)", info, err)
+	}
+
+	// Samples are read back from the results directory after a restart
+	restarted := &Server{ResultsDir: dir}
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	reader, err = fetch(t, newFlightClient(t, restarted), "sample/"+ids[1])
+	if err != nil {
+		t.Fatalf("DoGet failed: %v", err)
+	}
+	if !reader.Next() || reader.RecordBatch().NumRows() != 3 || reader.RecordBatch().NumCols() != 3 {
+		t.Fatalf("Expected the 3x3 sample, got %v", reader.Err())
+	}
+
+	for ticket, code := range map[string]codes.Code{
+		"sample/" + ids[0]: codes.NotFound,
+		"profile/missing":  codes.NotFound,
+		"profiles":         codes.InvalidArgument,
+	} {
+		if _, err := fetch(t, client, ticket); status.Code(err) != code {
+			t.Errorf("Expected %s for %s, got %v", code, ticket, err)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+// Package server exposes the profiler over HTTP.
+//
+// Endpoints:
+//
+//	POST /profile        Profile an uploaded file (multipart field "file") or a
+//	                     reference given as JSON: {"path": "..."} or {"url": "..."}
+//	GET  /profiles       List the profiles kept in memory, newest first
+//	GET  /profiles/{id}  Return a stored profile
+//	GET  /               Web dashboard browsing the stored profiles
+//
+// POST /profile accepts the sampling options sample_size, positions, limit and
+// columns (comma-separated) as query parameters; sample=true keeps the sampled
+// rows, to be fetched over Arrow Flight (see FlightService).
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+// DefaultMaxUpload is the default size limit of uploaded and downloaded files
+const DefaultMaxUpload = 1 << 30
+
+// DefaultMaxProfiles is the default number of profiles kept in memory
+const DefaultMaxProfiles = 1000
+
+// Server profiles files on request and keeps the newest results in memory
+type Server struct {
+	Config  tablestats.SamplingConfig // Defaults for every request
+	Options tablestats.ReaderOptions
+
+	// DataDir is the directory path references are resolved in; when empty,
+	// path references are rejected
+	DataDir string
+	// AllowURLs enables profiling files fetched from http(s) URLs
+	AllowURLs bool
+	// MaxUpload limits the size of uploaded and downloaded files in bytes;
+	// 0 means DefaultMaxUpload
+	MaxUpload int64
+	// Client fetches URL references; nil uses a client with a one minute timeout
+	Client *http.Client
+	// ResultsDir is the directory profiles are kept in, one JSON file each;
+	// when empty, profiles are only kept in memory
+	ResultsDir string
+	// MaxProfiles limits the profiles kept in memory and listed, dropping the
+	// oldest first; 0 means DefaultMaxProfiles. Dropped profiles stay in
+	// ResultsDir and can still be fetched by id.
+	MaxProfiles int
+
+	mu       sync.RWMutex
+	profiles map[string]*Profile
+	samples  map[string]*tablestats.Sample // Kept samples not yet read back from ResultsDir
+}
+
+// Profile is a stored profiling result
+type Profile struct {
+	ID      string          `json:"id"`
+	Source  string          `json:"source"` // File name, path or URL that was profiled
+	Created time.Time       `json:"created"`
+	Profile json.RawMessage `json:"profile"`          // As written by tablestats.Marshal
+	Sample  bool            `json:"sample,omitempty"` // The sampled rows were kept
+}
+
+// ProfileSummary describes a stored profile in listings
+type ProfileSummary struct {
+	ID          string    `json:"id"`
+	Source      string    `json:"source"`
+	Created     time.Time `json:"created"`
+	RowCount    int64     `json:"row_count"`
+	ColumnCount int       `json:"column_count"`
+}
+
+// profileRequest references a file to profile
+type profileRequest struct {
+	Path string `json:"path"`
+	URL  string `json:"url"`
+}
+
+// statusError carries the HTTP status a failed request should get
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string { return e.err.Error() }
+func (e *statusError) Unwrap() error { return e.err }
+
+func httpError(status int, format string, args ...any) error {
+	return &statusError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// Handler returns the HTTP handler serving the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /profile", s.handleProfile)
+	mux.HandleFunc("GET /profiles", s.handleList)
+	mux.HandleFunc("GET /profiles/{id}", s.handleGet)
+	mux.Handle("GET /", uiHandler())
+	return mux
+}
+
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	config, err := s.requestConfig(r.URL.Query())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	keepSample, err := boolParam(r.URL.Query(), "sample")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "gotablestats-serve-")
+	if err != nil {
+		writeError(w, fmt.Errorf("failed to create temporary directory: %w", err))
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	filePath, source, err := s.resolveInput(w, r, dir)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var stats *tablestats.TableStats
+	var sample *tablestats.Sample
+	if keepSample {
+		if sample, err = s.readSample(r.Context(), filePath, config); err == nil {
+			stats = tablestats.AnalyzeSample(sample, config)
+		}
+	} else {
+		stats, err = s.analyze(r.Context(), filePath, config)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	data, err := tablestats.Marshal(stats)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	p := &Profile{ID: newID(), Source: source, Created: time.Now().UTC(), Profile: data, Sample: keepSample}
+	if err := s.saveSample(p.ID, sample); err != nil {
+		writeError(w, err)
+		return
+	}
+	if err := s.save(p); err != nil {
+		writeError(w, err)
+		return
+	}
+	s.mu.Lock()
+	if s.profiles == nil {
+		s.profiles = make(map[string]*Profile)
+	}
+	s.profiles[p.ID] = p
+	if sample != nil && s.ResultsDir == "" {
+		if s.samples == nil {
+			s.samples = make(map[string]*tablestats.Sample)
+		}
+		s.samples[p.ID] = sample
+	}
+	s.trim()
+	s.mu.Unlock()
+
+	w.Header().Set("Location", "/profiles/"+p.ID)
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, httpError(http.StatusNotFound, "profile %q not found", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	summaries := make([]ProfileSummary, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		var counts struct {
+			RowCount    int64 `json:"row_count"`
+			ColumnCount int   `json:"column_count"`
+		}
+		json.Unmarshal(p.Profile, &counts)
+		summaries = append(summaries, ProfileSummary{
+			ID: p.ID, Source: p.Source, Created: p.Created,
+			RowCount: counts.RowCount, ColumnCount: counts.ColumnCount,
+		})
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Created.After(summaries[j].Created)
+	})
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// requestConfig applies the query parameters to the default sampling config
+func (s *Server) requestConfig(query url.Values) (tablestats.SamplingConfig, error) {
+	config := s.Config
+	for _, param := range []struct {
+		name string
+		set  func(int64)
+	}{
+		{"sample_size", func(v int64) { config.SampleSize = int(v) }},
+		{"positions", func(v int64) { config.RandomPositions = int(v) }},
+		{"limit", func(v int64) { config.Limit = v }},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || v < 0 || (v == 0 && param.name != "limit") {
+			return config, httpError(http.StatusBadRequest, "invalid %s %q", param.name, value)
+		}
+		param.set(v)
+	}
+	if columns := query.Get("columns"); columns != "" {
+		config.Columns = strings.Split(columns, ",")
+	}
+	return config, nil
+}
+
+// resolveInput returns the local file to profile and a description of its
+// source. Uploads and downloads are written to dir.
+func (s *Server) resolveInput(w http.ResponseWriter, r *http.Request, dir string) (string, string, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		return s.saveUpload(w, r, dir)
+	}
+
+	var req profileRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&req); err != nil {
+		return "", "", httpError(http.StatusBadRequest, "expected a multipart upload or a JSON body with path or url: %v", err)
+	}
+	switch {
+	case req.Path != "" && req.URL != "":
+		return "", "", httpError(http.StatusBadRequest, "give either path or url, not both")
+	case req.Path != "":
+		filePath, err := s.resolvePath(req.Path)
+		return filePath, req.Path, err
+	case req.URL != "":
+		filePath, err := s.download(r.Context(), req.URL, dir)
+		return filePath, req.URL, err
+	default:
+		return "", "", httpError(http.StatusBadRequest, "missing path or url")
+	}
+}
+
+func (s *Server) maxUpload() int64 {
+	if s.MaxUpload > 0 {
+		return s.MaxUpload
+	}
+	return DefaultMaxUpload
+}
+
+// saveUpload stores the multipart field "file" in dir under its base name, so
+// that the reader can still go by the extension
+func (s *Server) saveUpload(w http.ResponseWriter, r *http.Request, dir string) (string, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, s.maxUpload())
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return "", "", httpError(http.StatusBadRequest, "invalid multipart upload: %v", err)
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return "", "", httpError(http.StatusBadRequest, `missing multipart field "file"`)
+		}
+		if err != nil {
+			return "", "", uploadError(err)
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		name := filepath.Base(filepath.Clean("/" + part.FileName()))
+		if name == "/" || name == "." {
+			name = "upload"
+		}
+		filePath := filepath.Join(dir, name)
+		if err := writeFile(filePath, part); err != nil {
+			return "", "", uploadError(err)
+		}
+		return filePath, name, nil
+	}
+}
+
+func uploadError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return httpError(http.StatusRequestEntityTooLarge, "file is larger than %d bytes", tooLarge.Limit)
+	}
+	return httpError(http.StatusBadRequest, "failed to read upload: %v", err)
+}
+
+// resolvePath maps a path reference into DataDir, refusing to leave it
+func (s *Server) resolvePath(ref string) (string, error) {
+	if s.DataDir == "" {
+		return "", httpError(http.StatusForbidden, "path references are disabled")
+	}
+	filePath := filepath.Join(s.DataDir, filepath.Clean("/"+ref))
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return "", httpError(http.StatusNotFound, "file %q not found", ref)
+	}
+	if info.IsDir() {
+		return "", httpError(http.StatusBadRequest, "%q is a directory", ref)
+	}
+	return filePath, nil
+}
+
+// download fetches an http(s) URL into dir
+func (s *Server) download(ctx context.Context, rawURL, dir string) (string, error) {
+	if !s.AllowURLs {
+		return "", httpError(http.StatusForbidden, "url references are disabled")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", httpError(http.StatusBadRequest, "invalid url %q", rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", httpError(http.StatusBadRequest, "invalid url %q", rawURL)
+	}
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", httpError(http.StatusBadGateway, "failed to fetch %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", httpError(http.StatusBadGateway, "failed to fetch %s: %s", rawURL, resp.Status)
+	}
+
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		name = "download"
+	}
+	filePath := filepath.Join(dir, name)
+	body := io.LimitReader(resp.Body, s.maxUpload()+1)
+	if err := writeFile(filePath, body); err != nil {
+		return "", httpError(http.StatusBadGateway, "failed to fetch %s: %v", rawURL, err)
+	}
+	if info, err := os.Stat(filePath); err == nil && info.Size() > s.maxUpload() {
+		return "", httpError(http.StatusRequestEntityTooLarge, "file is larger than %d bytes", s.maxUpload())
+	}
+	return filePath, nil
+}
+
+func writeFile(filePath string, r io.Reader) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (s *Server) analyze(ctx context.Context, filePath string, config tablestats.SamplingConfig) (*tablestats.TableStats, error) {
+	reader, err := tablestats.NewReaderFor(filePath, s.Options)
+	if err != nil {
+		return nil, &statusError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	stats, err := reader.ReadTable(ctx, filePath, config)
+	if err != nil {
+		return nil, &statusError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	return stats, nil
+}
+
+// readSample reads the rows of a file selected by config, for profiles that
+// keep their sample
+func (s *Server) readSample(ctx context.Context, filePath string, config tablestats.SamplingConfig) (*tablestats.Sample, error) {
+	reader, err := tablestats.NewReaderFor(filePath, s.Options)
+	if err != nil {
+		return nil, &statusError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	sampleReader, ok := reader.(tablestats.SampleReader)
+	if !ok {
+		return nil, httpError(http.StatusUnprocessableEntity, "%s reader does not support row access, needed to keep the sample", reader.GetFormatName())
+	}
+	sample, err := sampleReader.ReadSample(ctx, filePath, config)
+	if err != nil {
+		return nil, &statusError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	return sample, nil
+}
+
+func boolParam(query url.Values, name string) (bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, httpError(http.StatusBadRequest, "invalid %s %q", name, value)
+	}
+	return v, nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var se *statusError
+	if errors.As(err, &se) {
+		status = se.status
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
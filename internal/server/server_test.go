@@ -0,0 +1,278 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+)
+
+const testCSV = "id,name,amount\n1,a,10\n2,b,20\n3,c,30\n"
+
+func newTestServer(t *testing.T, s *Server) *httptest.Server {
+	t.Helper()
+	s.Config = tablestats.DefaultSamplingConfig()
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func decodeProfile(t *testing.T, resp *http.Response, status int) *Profile {
+	t.Helper()
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		var body bytes.Buffer
+		body.ReadFrom(resp.Body)
+		t.Fatalf("Expected status %d, got %d: %s", status, resp.StatusCode, body.String())
+	}
+	p := &Profile{}
+	if err := json.NewDecoder(resp.Body).Decode(p); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return p
+}
+
+func uploadRequest(t *testing.T, url, name, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("CreateFormFile failed: %v", err)
+	}
+	fw.Write([]byte(content))
+	mw.Close()
+
+	req, err := http.NewRequest(http.MethodPost, url, &body)
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestServer_Upload(t *testing.T) {
+	ts := newTestServer(t, &Server{})
+
+	resp, err := http.DefaultClient.Do(uploadRequest(t, ts.URL+"/profile?columns=amount", "data.csv", testCSV))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	if loc := resp.Header.Get("Location"); !strings.HasPrefix(loc, "/profiles/") {
+		t.Errorf("Expected a Location header, got %q", loc)
+	}
+	created := decodeProfile(t, resp, http.StatusCreated)
+	if created.Source != "data.csv" {
+		t.Errorf("Expected source data.csv, got %s", created.Source)
+	}
+	stats, err := tablestats.Unmarshal(created.Profile)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if stats.RowCount != 3 || stats.ColumnCount != 1 || stats.Aggregates["amount"].Sum != 60 {
+		t.Errorf("Expected 3 rows and only the amount column, got %d rows and %v", stats.RowCount, stats.ColumnNames)
+	}
+
+	resp, err = http.Get(ts.URL + "/profiles/" + created.ID)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	if got := decodeProfile(t, resp, http.StatusOK); got.ID != created.ID || !bytes.Equal(got.Profile, created.Profile) {
+		t.Errorf("Expected the stored profile %s, got %s", created.ID, got.ID)
+	}
+
+	resp, err = http.Get(ts.URL + "/profiles")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	var list []ProfileSummary
+	json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if len(list) != 1 || list[0].ID != created.ID || list[0].RowCount != 3 {
+		t.Errorf("Expected one listed profile, got %+v", list)
+	}
+}
+
+func TestServer_References(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data.csv"), []byte(testCSV), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/export/data.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testCSV))
+	}))
+	defer remote.Close()
+
+	post := func(ts *httptest.Server, body string) *http.Response {
+		resp, err := http.Post(ts.URL+"/profile", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		return resp
+	}
+
+	ts := newTestServer(t, &Server{DataDir: dir, AllowURLs: true})
+	if p := decodeProfile(t, post(ts, `{"path": "data.csv"}`), http.StatusCreated); p.Source != "data.csv" {
+		t.Errorf("Expected source data.csv, got %s", p.Source)
+	}
+	decodeProfile(t, post(ts, `{"url": "`+remote.URL+`/export/data.csv"}`), http.StatusCreated)
+
+	tests := []struct {
+		server *httptest.Server
+		body   string
+		status int
+	}{
+		{ts, `{"path": "../../etc/passwd"}`, http.StatusNotFound}, // Resolved inside DataDir
+		{ts, `{"path": "missing.csv"}`, http.StatusNotFound},
+		{ts, `{"url": "` + remote.URL + `/missing.csv"}`, http.StatusBadGateway},
+		{ts, `{"url": "file:///etc/passwd"}`, http.StatusBadRequest},
+		{ts, `{}`, http.StatusBadRequest},
+		{ts, `not json`, http.StatusBadRequest},
+		{newTestServer(t, &Server{}), `{"path": "data.csv"}`, http.StatusForbidden},
+		{newTestServer(t, &Server{}), `{"url": "` + remote.URL + `/export/data.csv"}`, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		resp := post(tt.server, tt.body)
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("Expected status %d for %s, got %d", tt.status, tt.body, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	ts := newTestServer(t, &Server{})
+	small := newTestServer(t, &Server{MaxUpload: 10})
+
+	tests := []struct {
+		req    *http.Request
+		status int
+	}{
+		{uploadRequest(t, small.URL+"/profile", "data.csv", testCSV), http.StatusRequestEntityTooLarge},
+		{uploadRequest(t, ts.URL+"/profile?sample_size=0", "data.csv", testCSV), http.StatusBadRequest},
+		{uploadRequest(t, ts.URL+"/profile", "empty.csv", ""), http.StatusUnprocessableEntity},
+	}
+	for i, tt := range tests {
+		resp, err := http.DefaultClient.Do(tt.req)
+		if err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("Expected status %d for request %d, got %d", tt.status, i, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(ts.URL + "/profiles/unknown")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestServer_ResultsDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	ts := newTestServer(t, &Server{ResultsDir: dir})
+
+	resp, err := http.DefaultClient.Do(uploadRequest(t, ts.URL+"/profile", "data.csv", testCSV))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	created := decodeProfile(t, resp, http.StatusCreated)
+	if _, err := os.Stat(filepath.Join(dir, created.ID+".json")); err != nil {
+		t.Fatalf("Expected the profile to be saved: %v", err)
+	}
+
+	// A new server sees the profiles of the previous one
+	restarted := &Server{ResultsDir: dir}
+	if err := restarted.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	ts = newTestServer(t, restarted)
+	resp, err = http.Get(ts.URL + "/profiles/" + created.ID)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	if got := decodeProfile(t, resp, http.StatusOK); got.Source != "data.csv" || !got.Created.Equal(created.Created) {
+		t.Errorf("Expected the saved profile, got %+v", got)
+	}
+
+	if err := (&Server{ResultsDir: filepath.Join(dir, "missing")}).Load(); err != nil {
+		t.Errorf("Expected a missing results directory to hold no profiles, got %v", err)
+	}
+}
+
+func TestServer_MaxProfiles(t *testing.T) {
+	for _, resultsDir := range []string{"", filepath.Join(t.TempDir(), "results")} {
+		ts := newTestServer(t, &Server{MaxProfiles: 2, ResultsDir: resultsDir})
+		var ids []string
+		for i := 0; i < 3; i++ {
+			resp, err := http.DefaultClient.Do(uploadRequest(t, ts.URL+"/profile", "data.csv", testCSV))
+			if err != nil {
+				t.Fatalf("POST failed: %v", err)
+			}
+			ids = append(ids, decodeProfile(t, resp, http.StatusCreated).ID)
+		}
+
+		resp, err := http.Get(ts.URL + "/profiles")
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		var list []ProfileSummary
+		json.NewDecoder(resp.Body).Decode(&list)
+		resp.Body.Close()
+		if len(list) != 2 || list[0].ID != ids[2] || list[1].ID != ids[1] {
+			t.Errorf("Expected the two newest profiles to be listed, got %+v", list)
+		}
+
+		// The oldest profile is only left in the results directory
+		resp, err = http.Get(ts.URL + "/profiles/" + ids[0])
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		resp.Body.Close()
+		expected := http.StatusNotFound
+		if resultsDir != "" {
+			expected = http.StatusOK
+		}
+		if resp.StatusCode != expected {
+			t.Errorf("Expected status %d for the dropped profile with results dir %q, got %d", expected, resultsDir, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_Dashboard(t *testing.T) {
+	ts := newTestServer(t, &Server{})
+
+	for _, tt := range []struct {
+		path        string
+		contentType string
+	}{
+		{"/", "text/html"},
+		{"/app.js", "text/javascript"},
+		{"/style.css", "text/css"},
+	} {
+		resp, err := http.Get(ts.URL + tt.path)
+		if err != nil {
+			t.Fatalf("GET %s failed: %v", tt.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), tt.contentType) {
+			t.Errorf("Expected %s to serve %s, got %d %s", tt.path, tt.contentType, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WindowGenerator/gotablestats/pkg/tablestats"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// Load reads the profiles kept in ResultsDir, so that results of earlier runs
+// of the server stay listed. A missing directory holds no profiles.
+func (s *Server) Load() error {
+	if s.ResultsDir == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(s.ResultsDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read results directory: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.profiles == nil {
+		s.profiles = make(map[string]*Profile)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.ResultsDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read stored profile: %w", err)
+		}
+		p := &Profile{}
+		if err := json.Unmarshal(data, p); err != nil || p.ID == "" {
+			return fmt.Errorf("failed to load stored profile %s: invalid profile", entry.Name())
+		}
+		s.profiles[p.ID] = p
+	}
+	s.trim()
+	return nil
+}
+
+// trim drops the oldest profiles, and their samples, from memory until at
+// most MaxProfiles are left. The caller must hold s.mu.
+func (s *Server) trim() {
+	limit := s.MaxProfiles
+	if limit <= 0 {
+		limit = DefaultMaxProfiles
+	}
+	if len(s.profiles) <= limit {
+		return
+	}
+	profiles := make([]*Profile, 0, len(s.profiles))
+	for _, p := range s.profiles {
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Created.After(profiles[j].Created) })
+	for _, p := range profiles[limit:] {
+		delete(s.profiles, p.ID)
+		delete(s.samples, p.ID)
+	}
+}
+
+// lookup returns a profile kept in memory or, once dropped from memory, read
+// back from ResultsDir
+func (s *Server) lookup(id string) (*Profile, bool) {
+	s.mu.RLock()
+	p, ok := s.profiles[id]
+	s.mu.RUnlock()
+	if ok || s.ResultsDir == "" {
+		return p, ok
+	}
+	// Ids are hex, which also keeps them from leaving ResultsDir
+	if _, err := hex.DecodeString(id); err != nil || id == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(s.ResultsDir, id+".json"))
+	if err != nil {
+		return nil, false
+	}
+	p = &Profile{}
+	if err := json.Unmarshal(data, p); err != nil || p.ID != id {
+		return nil, false
+	}
+	return p, true
+}
+
+// save writes a profile to ResultsDir. The profile is written to a temporary
+// file first, so that Load never sees a partial one.
+func (s *Server) save(p *Profile) error {
+	if s.ResultsDir == "" {
+		return nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.ResultsDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create results directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(s.ResultsDir, ".profile-*")
+	if err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.ResultsDir, p.ID+".json")); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	return nil
+}
+
+// saveSample writes a kept sample to ResultsDir as an Arrow IPC file, next to
+// its profile. Without ResultsDir, samples are only kept in memory.
+func (s *Server) saveSample(id string, sample *tablestats.Sample) error {
+	if s.ResultsDir == "" || sample == nil {
+		return nil
+	}
+	if err := os.MkdirAll(s.ResultsDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create results directory: %w", err)
+	}
+	batch := sample.ArrowRecordBatch(memory.DefaultAllocator)
+	defer batch.Release()
+
+	tmp, err := os.CreateTemp(s.ResultsDir, ".sample-*")
+	if err != nil {
+		return fmt.Errorf("failed to save sample: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	writer, err := ipc.NewFileWriter(tmp, ipc.WithSchema(batch.Schema()))
+	if err == nil {
+		err = writer.Write(batch)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save sample: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.ResultsDir, id+".arrow")); err != nil {
+		return fmt.Errorf("failed to save sample: %w", err)
+	}
+	return nil
+}
+
+// loadSample returns the kept sample of a profile. The caller must Release
+// the batch.
+func (s *Server) loadSample(id string) (arrow.RecordBatch, error) {
+	s.mu.RLock()
+	sample := s.samples[id]
+	s.mu.RUnlock()
+	if sample != nil {
+		return sample.ArrowRecordBatch(memory.DefaultAllocator), nil
+	}
+	if s.ResultsDir == "" {
+		return nil, fmt.Errorf("sample of profile %q not found", id)
+	}
+
+	file, err := os.Open(filepath.Join(s.ResultsDir, id+".arrow"))
+	if err != nil {
+		return nil, fmt.Errorf("sample of profile %q not found", id)
+	}
+	defer file.Close()
+	reader, err := ipc.NewFileReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample: %w", err)
+	}
+	defer reader.Close()
+	if reader.NumRecords() != 1 {
+		return nil, fmt.Errorf("failed to read sample: expected 1 record batch, got %d", reader.NumRecords())
+	}
+	batch, err := reader.RecordBatchAt(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample: %w", err)
+	}
+	return batch, nil
+}
@@ -0,0 +1,177 @@
+// Dashboard over the JSON API: datasets are the sources profiled so far, each
+// with its runs newest first
+"use strict";
+
+const maxHistory = 20; // Runs fetched to draw a dataset's history
+const percentiles = [25, 50, 75, 90, 95, 99];
+
+let datasets = new Map(); // Source -> summaries, newest first
+let selected = null;
+
+function escapeHTML(s) {
+  return String(s).replace(/[&<>"']/g, c => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;"})[c]);
+}
+
+function formatNumber(v) {
+  if (typeof v !== "number") return v === undefined || v === null ? "–" : escapeHTML(v);
+  return Number.isInteger(v) ? v.toLocaleString() : v.toLocaleString(undefined, {maximumFractionDigits: 4});
+}
+
+async function getJSON(url, options) {
+  const resp = await fetch(url, options);
+  const body = await resp.json();
+  if (!resp.ok) throw new Error(body.error || resp.statusText);
+  return body;
+}
+
+async function loadDatasets() {
+  const summaries = await getJSON("profiles");
+  datasets = new Map();
+  for (const s of summaries) {
+    if (!datasets.has(s.source)) datasets.set(s.source, []);
+    datasets.get(s.source).push(s);
+  }
+  renderDatasets();
+}
+
+function renderDatasets() {
+  const list = document.getElementById("datasets");
+  if (datasets.size === 0) {
+    list.innerHTML = '<li class="muted">No profiles yet</li>';
+    return;
+  }
+  list.innerHTML = "";
+  for (const [source, runs] of datasets) {
+    const item = document.createElement("li");
+    item.className = source === selected ? "selected" : "";
+    item.innerHTML = `${escapeHTML(source)}<small>${runs.length} run${runs.length === 1 ? "" : "s"}, last ${new Date(runs[0].created).toLocaleString()}</small>`;
+    item.onclick = () => selectDataset(source);
+    list.appendChild(item);
+  }
+}
+
+async function selectDataset(source) {
+  selected = source;
+  renderDatasets();
+  const section = document.getElementById("dataset");
+  section.innerHTML = '<p class="muted">Loading…</p>';
+  try {
+    const runs = datasets.get(source).slice(0, maxHistory);
+    const profiles = await Promise.all(runs.map(r => getJSON("profiles/" + encodeURIComponent(r.id))));
+    if (selected === source) renderDataset(section, profiles);
+  } catch (err) {
+    section.innerHTML = `<p class="error">${escapeHTML(err.message)}</p>`;
+  }
+}
+
+function renderDataset(section, runs) {
+  const latest = runs[0].profile;
+  const history = runs.slice().reverse(); // Oldest first
+  section.innerHTML = `
+    <div class="card">
+      <h2>${escapeHTML(runs[0].source)}</h2>
+      <div class="summary">
+        <div><span class="muted">Rows profiled</span><b>${formatNumber(latest.row_count)}</b></div>
+        <div><span class="muted">Estimated rows</span><b>${formatNumber(latest.estimated_rows)}</b></div>
+        <div><span class="muted">Columns</span><b>${formatNumber(latest.column_count)}</b></div>
+        <div><span class="muted">Profiled</span><b>${new Date(runs[0].created).toLocaleString()}</b></div>
+      </div>
+    </div>
+    ${runs.length > 1 ? historyCard(history) : ""}
+    <div class="columns">${latest.column_names.map(name => columnCard(latest, name, history)).join("")}</div>`;
+}
+
+function historyCard(history) {
+  const rows = history.map(r => r.profile.estimated_rows || r.profile.row_count);
+  return `
+    <div class="card">
+      <h2>History</h2>
+      <table>
+        <tr><th>Estimated rows</th><td>${sparkline(rows, 300, 40)}</td><td>${formatNumber(rows[rows.length - 1])}</td></tr>
+        <tr><th>Columns</th><td>${sparkline(history.map(r => r.profile.column_count), 300, 40)}</td><td>${formatNumber(history[history.length - 1].profile.column_count)}</td></tr>
+      </table>
+      <p class="muted">${history.length} runs from ${new Date(history[0].created).toLocaleString()}</p>
+    </div>`;
+}
+
+function columnCard(stats, name, history) {
+  const nulls = (stats.null_percentage || {})[name] || 0;
+  const agg = (stats.aggregates || {})[name];
+  const categories = (stats.categories || {})[name];
+  const nullHistory = history.map(r => ((r.profile.null_percentage || {})[name]) || 0);
+
+  let body = `
+    <div class="muted">Nulls ${nulls.toFixed(1)}%${history.length > 1 ? " " + sparkline(nullHistory, 80, 14, true) : ""}</div>
+    <div class="bar"><span style="width:${Math.min(nulls, 100)}%"></span></div>
+    <table>
+      <tr><th>Distinct</th><td>${formatNumber((stats.distinct_counts || {})[name])}</td></tr>
+      <tr><th>Min</th><td>${formatNumber((stats.min_values || {})[name])}</td></tr>
+      <tr><th>Max</th><td>${formatNumber((stats.max_values || {})[name])}</td></tr>`;
+  if (agg) {
+    body += `
+      <tr><th>Mean</th><td>${formatNumber(agg.mean)}</td></tr>
+      <tr><th>Std dev</th><td>${formatNumber(agg.std_dev)}</td></tr>`;
+  }
+  body += "</table>";
+  if (agg && agg.percentiles) {
+    body += percentileChart(stats.min_values[name], stats.max_values[name], agg.percentiles);
+  }
+  if (categories && categories.length) {
+    body += `<div class="chips">${categories.map(c => `<span>${escapeHTML(c)}</span>`).join("")}</div>`;
+  }
+  return `<div class="card"><h3>${escapeHTML(name)}</h3><div class="type">${escapeHTML(stats.column_types[name] || "")}</div>${body}</div>`;
+}
+
+// percentileChart draws the minimum, recorded percentiles and maximum on one
+// axis, with a box from the 25th to the 75th percentile
+function percentileChart(min, max, values) {
+  if (typeof min !== "number" || typeof max !== "number") return "";
+  const width = 260, height = 44, pad = 6;
+  const x = v => max === min ? width / 2 : pad + (v - min) / (max - min) * (width - 2 * pad);
+  const p = percentiles.filter(k => values[k] !== undefined).map(k => [k, values[k]]);
+  const p25 = values[25], p75 = values[75];
+
+  let svg = `<svg width="${width}" height="${height}" role="img" aria-label="percentiles">`;
+  svg += `<line x1="${x(min)}" x2="${x(max)}" y1="16" y2="16" stroke="#999"/>`;
+  if (p25 !== undefined && p75 !== undefined) {
+    svg += `<rect x="${x(p25)}" y="8" width="${Math.max(x(p75) - x(p25), 1)}" height="16" fill="#c8d7f0" stroke="#4a6fa5"/>`;
+  }
+  for (const [k, v] of p) {
+    svg += `<line x1="${x(v)}" x2="${x(v)}" y1="6" y2="26" stroke="#4a6fa5"><title>p${k}: ${formatNumber(v)}</title></line>`;
+  }
+  svg += `<text x="${pad}" y="40" font-size="10">${formatNumber(min)}</text>`;
+  svg += `<text x="${width - pad}" y="40" font-size="10" text-anchor="end">${formatNumber(max)}</text>`;
+  return svg + "</svg>";
+}
+
+function sparkline(values, width, height, inline) {
+  const lo = Math.min(...values), hi = Math.max(...values);
+  const points = values.map((v, i) => {
+    const px = values.length === 1 ? width / 2 : i / (values.length - 1) * (width - 2) + 1;
+    const py = hi === lo ? height / 2 : height - 1 - (v - lo) / (hi - lo) * (height - 2);
+    return px.toFixed(1) + "," + py.toFixed(1);
+  });
+  const style = inline ? ' style="display:inline;vertical-align:middle"' : "";
+  return `<svg width="${width}" height="${height}"${style}><polyline points="${points.join(" ")}" fill="none" stroke="#4a6fa5" stroke-width="1.5"/></svg>`;
+}
+
+document.getElementById("upload").onsubmit = async event => {
+  event.preventDefault();
+  const form = event.target;
+  const button = form.querySelector("button");
+  button.disabled = true;
+  try {
+    const created = await getJSON("profile", {method: "POST", body: new FormData(form)});
+    form.reset();
+    await loadDatasets();
+    selectDataset(created.source);
+  } catch (err) {
+    document.getElementById("dataset").innerHTML = `<p class="error">${escapeHTML(err.message)}</p>`;
+  } finally {
+    button.disabled = false;
+  }
+};
+
+loadDatasets().catch(err => {
+  document.getElementById("datasets").innerHTML = `<li class="error">${escapeHTML(err.message)}</li>`;
+});
@@ -0,0 +1,734 @@
+package tablestats
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// columnAccumulator builds the statistics of a single column one value at a time
+type columnAccumulator struct {
+	name              string
+	rows              int64
+	valueBytes        int64  // Bytes of the raw values, nulls included
+	textBytes         int64  // Bytes of the trimmed non-null values
+	compressionSample []byte // First non-null values, one per line, for Compressibility
+	nullCount         int64
+	emptyCount        int64       // Nulls that are empty or blank fields
+	nullTokens        int64       // Nulls spelled NULL or null
+	minVal            interface{} // Set once the column is known to hold strings
+	maxVal            interface{}
+	minNum            float64 // Numeric range, kept unboxed while the column is numeric
+	maxNum            float64
+	minInt            int64 // Exact range of integer columns
+	maxInt            int64
+	inexactInt        bool // An integer did not fit in an int64
+	hasRange          bool
+	isNumeric         bool
+	isFloat           bool
+	weighted          bool
+	nanPolicy         NonFinitePolicy
+	nonFinite         NonFiniteCounts // NaN and infinite values seen while the column is numeric
+	decimal           DecimalShape    // Digits of the finite numbers, while the column is numeric
+	unicode           UnicodeIssues   // Invisible characters and normalization forms of the raw values
+	padding           PaddingCounts   // Values with whitespace around them
+	fit               TypeFit         // Values that do not parse as each numeric type
+	typeTolerance     float64         // Share of values that may not be numbers in a numeric column
+	numeric           *numericSummary // Aggregates computed in one pass, weighted ones only when sketched
+	numericValues     []float64       // Weighted values, kept for weighted percentiles
+	valueWeights      []float64
+	distinct          map[string]struct{}
+	distinctHLL       *hyperLogLog      // Replaces distinct when sketched
+	valueCounts       map[string]*int64 // Rows per value, dropped past MaxCategories values
+	pii               *piiScanner
+	anomalies         *anomalyScanner // Set when anomalies are detected
+	pattern           *patternCheck   // Set when the column has a pattern
+	custom            []columnAnalyzer
+}
+
+// newColumnAccumulator creates an empty accumulator for the named column.
+// A sketched accumulator keeps bounded memory for any number of values.
+func newColumnAccumulator(name string, sketch bool) *columnAccumulator {
+	c := &columnAccumulator{
+		name:      name,
+		isNumeric: true,
+		numeric:   &numericSummary{},
+		pii:       newPIIScanner(name),
+		custom:    newColumnAnalyzers(name),
+	}
+	if sketch {
+		c.numeric.digest = newTDigest()
+		c.distinctHLL = newHyperLogLog()
+	} else {
+		c.distinct = make(map[string]struct{})
+		c.valueCounts = make(map[string]*int64)
+	}
+	return c
+}
+
+// fieldValue is a field as returned by encoding/csv or by fieldSplitter
+type fieldValue interface {
+	string | []byte
+}
+
+// add records a value along with its inverse-probability weight, which is only
+// used when the accumulator is weighted
+func (c *columnAccumulator) add(value string, weight float64) {
+	for _, ca := range c.custom {
+		ca.analyzer.Observe(value)
+	}
+	c.valueBytes += int64(len(value))
+	observeUnicode(&c.unicode, value)
+	trimmed := strings.TrimSpace(value)
+	observePadding(&c.padding, value, trimmed)
+	accumulate(c, trimmed, weight)
+}
+
+// addBytes records a value that is only valid for the duration of the call.
+// Strings are only allocated for the parts of it the accumulator keeps.
+func (c *columnAccumulator) addBytes(value []byte, weight float64) {
+	if len(c.custom) > 0 {
+		raw := string(value)
+		for _, ca := range c.custom {
+			ca.analyzer.Observe(raw)
+		}
+	}
+	c.valueBytes += int64(len(value))
+	observeUnicode(&c.unicode, value)
+	trimmed := bytes.TrimSpace(value)
+	observePadding(&c.padding, value, trimmed)
+	accumulate(c, trimmed, weight)
+}
+
+// accumulate records a trimmed value
+func accumulate[T fieldValue](c *columnAccumulator, value T, weight float64) {
+	c.rows++
+	if isNullValue(value) {
+		c.nullCount++
+		if len(value) == 0 {
+			c.emptyCount++
+		} else {
+			c.nullTokens++
+		}
+		return
+	}
+	c.textBytes += int64(len(value))
+	observeCompression(c, value)
+
+	// Numbers are classified first, as ignored NaN and infinities count as nulls
+	kind, floatVal := classifyNumber(value)
+	finite := kind == notNumber || isFinite(floatVal)
+	if c.isNumeric && !finite {
+		c.nonFinite.add(floatVal)
+		if c.nanPolicy == NonFiniteIgnore {
+			c.nullCount++
+			return
+		}
+	}
+	c.fit.observe(kind, finite)
+
+	if c.pii.wants() {
+		c.pii.observe(string(value))
+	}
+	if c.pattern != nil {
+		observePattern(c.pattern, value)
+	}
+	if c.distinctHLL != nil {
+		addDistinct(c.distinctHLL, value)
+	} else if v, ok := any(value).(string); ok {
+		c.distinct[v] = struct{}{}
+	} else if _, seen := c.distinct[string(value)]; !seen {
+		// Only allocate the key for byte values not seen before
+		c.distinct[string(value)] = struct{}{}
+	}
+	if c.valueCounts != nil {
+		if n, ok := c.valueCounts[string(value)]; ok {
+			*n++
+		} else if len(c.valueCounts) < MaxCategories {
+			n := int64(1)
+			c.valueCounts[string(value)] = &n
+		} else {
+			// Too many values for a categorical column
+			c.valueCounts = nil
+		}
+	}
+
+	// Try to determine type and collect numeric values
+	if c.isNumeric {
+		if kind != notNumber {
+			if kind == floatNumber || !finite {
+				c.isFloat = true
+			}
+			if !finite && c.nanPolicy == NonFiniteCount {
+				return
+			}
+			switch {
+			case !c.weighted:
+				c.numeric.add(floatVal)
+			case c.numeric.digest != nil:
+				c.numeric.addWeighted(floatVal, weight)
+			default:
+				c.numericValues = append(c.numericValues, floatVal)
+				c.valueWeights = append(c.valueWeights, weight)
+			}
+			if c.anomalies != nil {
+				c.anomalies.observe(floatVal)
+			}
+			if finite {
+				observeDecimal(&c.decimal, value)
+			}
+			if !c.hasRange || floatVal < c.minNum {
+				c.minNum = floatVal
+			}
+			if !c.hasRange || floatVal > c.maxNum {
+				c.maxNum = floatVal
+			}
+			if !c.isFloat && !c.inexactInt {
+				if n, ok := exactInt(value, floatVal); !ok {
+					c.inexactInt = true
+				} else {
+					if !c.hasRange || n < c.minInt {
+						c.minInt = n
+					}
+					if !c.hasRange || n > c.maxInt {
+						c.maxInt = n
+					}
+				}
+			}
+			c.hasRange = true
+			if c.typeTolerance == 0 {
+				return
+			}
+			// With a tolerance, every value also keeps a text range in
+			// case finalize finds the column is not numeric after all
+		} else if c.typeTolerance == 0 {
+			c.becomeText()
+		}
+	}
+
+	// String comparison
+	if c.minVal == nil || string(value) < c.minVal.(string) {
+		c.minVal = string(value)
+	}
+	if c.maxVal == nil || string(value) > c.maxVal.(string) {
+		c.maxVal = string(value)
+	}
+}
+
+// becomeText turns a numeric column into a string column
+func (c *columnAccumulator) becomeText() {
+	// The numbers seen so far now compare as text, unless the text range
+	// already holds them
+	if c.hasRange && c.typeTolerance == 0 {
+		minText, maxText := c.rangeText()
+		if c.minVal == nil || minText < c.minVal.(string) {
+			c.minVal = minText
+		}
+		if c.maxVal == nil || maxText > c.maxVal.(string) {
+			c.maxVal = maxText
+		}
+	}
+	c.isNumeric = false
+	c.isFloat = false
+	// NaN and infinities are plain text in a string column; those
+	// ignored so far remain counted as nulls
+	c.nonFinite = NonFiniteCounts{}
+	c.decimal = DecimalShape{}
+	// Switch to string comparison and clear numeric values
+	c.numeric = nil
+	c.numericValues = nil
+	c.valueWeights = nil
+}
+
+// numericRange returns the range of a numeric column: int64 values for
+// integer columns, so that large IDs stay exact, and float64 otherwise
+func (c *columnAccumulator) numericRange() (any, any) {
+	if c.isFloat || c.inexactInt {
+		return c.minNum, c.maxNum
+	}
+	return c.minInt, c.maxInt
+}
+
+// rangeText returns the numeric range formatted as text
+func (c *columnAccumulator) rangeText() (string, string) {
+	if c.isFloat || c.inexactInt {
+		return strconv.FormatFloat(c.minNum, 'f', -1, 64), strconv.FormatFloat(c.maxNum, 'f', -1, 64)
+	}
+	return strconv.FormatInt(c.minInt, 10), strconv.FormatInt(c.maxInt, 10)
+}
+
+// exactInt returns the int64 value of an integer field parsed as v
+func exactInt[T fieldValue](value T, v float64) (int64, bool) {
+	if math.Abs(v) < 1<<53 {
+		// Exactly representable, as are the integers below it
+		return int64(v), true
+	}
+	n, err := strconv.ParseInt(string(value), 10, 64)
+	return n, err == nil
+}
+
+// finalize writes the column statistics into stats. estimatedRows is used to
+// extrapolate the column total.
+func (c *columnAccumulator) finalize(stats *TableStats, estimatedRows int64) {
+	colName := c.name
+	if c.isNumeric && !c.fit.fits(c.typeTolerance) {
+		c.becomeText()
+	}
+
+	// Set column type
+	if c.isNumeric {
+		if c.isFloat {
+			stats.ColumnTypes[colName] = "float64"
+		} else {
+			stats.ColumnTypes[colName] = "int64"
+		}
+
+		// Calculate aggregates for numeric columns
+		var agg *AggregateStats
+		if len(c.numericValues) > 0 {
+			agg = calculateWeightedAggregates(c.numericValues, c.valueWeights)
+		} else if c.numeric.count > 0 {
+			agg = c.numeric.aggregates()
+		}
+		if agg != nil {
+			// Extrapolate the total over the non-null share of the estimated rows
+			nonNullShare := float64(agg.Count) / float64(c.rows)
+			agg.EstimatedTotal = agg.Mean * float64(estimatedRows) * nonNullShare
+			stats.Aggregates[colName] = agg
+		}
+	} else {
+		stats.ColumnTypes[colName] = "string"
+	}
+
+	stats.NullCounts[colName] = c.nullCount
+	stats.EmptyCounts[colName] = c.emptyCount
+	stats.NullTokens[colName] = c.nullTokens
+	if c.distinctHLL != nil {
+		stats.DistinctCounts[colName] = c.distinctHLL.estimate()
+	} else {
+		stats.DistinctCounts[colName] = int64(len(c.distinct))
+	}
+	stats.NullPercentage[colName] = float64(c.nullCount) / float64(c.rows) * 100
+	if !c.isNumeric && c.distinct != nil && len(c.distinct) <= MaxCategories {
+		if stats.Categories == nil {
+			stats.Categories = make(map[string][]string)
+		}
+		values := make([]string, 0, len(c.distinct))
+		for v := range c.distinct {
+			values = append(values, v)
+		}
+		sort.Strings(values)
+		stats.Categories[colName] = values
+
+		if groups := caseGroups(c.valueCounts); len(groups) > 0 {
+			if stats.CaseVariants == nil {
+				stats.CaseVariants = make(map[string][]CaseGroup)
+			}
+			stats.CaseVariants[colName] = groups
+		}
+	}
+	if c.isNumeric && c.hasRange {
+		stats.MinValues[colName], stats.MaxValues[colName] = c.numericRange()
+	} else {
+		stats.MinValues[colName] = c.minVal
+		stats.MaxValues[colName] = c.maxVal
+	}
+
+	if c.isNumeric && c.decimal.Precision > 0 {
+		if stats.Decimals == nil {
+			stats.Decimals = make(map[string]*DecimalShape)
+		}
+		shape := c.decimal
+		stats.Decimals[colName] = &shape
+	}
+
+	if c.fit.mixed() {
+		if stats.TypeFit == nil {
+			stats.TypeFit = make(map[string]*TypeFit)
+		}
+		fit := c.fit
+		stats.TypeFit[colName] = &fit
+	}
+
+	if c.padding != (PaddingCounts{}) {
+		if stats.Padding == nil {
+			stats.Padding = make(map[string]*PaddingCounts)
+		}
+		counts := c.padding
+		stats.Padding[colName] = &counts
+	}
+
+	if c.unicode.found() {
+		if stats.UnicodeIssues == nil {
+			stats.UnicodeIssues = make(map[string]*UnicodeIssues)
+		}
+		issues := c.unicode
+		stats.UnicodeIssues[colName] = &issues
+	}
+
+	if c.isNumeric && c.nonFinite.Total() > 0 {
+		if stats.NonFinite == nil {
+			stats.NonFinite = make(map[string]*NonFiniteCounts)
+		}
+		counts := c.nonFinite
+		stats.NonFinite[colName] = &counts
+	}
+
+	if c.anomalies != nil && c.isNumeric {
+		values, weights := c.distribution()
+		stats.Anomalies = append(stats.Anomalies,
+			c.anomalies.report(colName, stats.DistinctCounts[colName], values, weights, !c.isFloat)...)
+	}
+
+	if c.pattern != nil {
+		if stats.Conformance == nil {
+			stats.Conformance = make(map[string]*Conformance)
+		}
+		stats.Conformance[colName] = &c.pattern.result
+	}
+
+	if flag := c.pii.flag(); flag != nil {
+		if stats.PII == nil {
+			stats.PII = make(map[string]*PIIFlag)
+		}
+		stats.PII[colName] = flag
+	}
+
+	for _, ca := range c.custom {
+		if stats.CustomMetrics == nil {
+			stats.CustomMetrics = make(map[string]map[string]any)
+		}
+		if stats.CustomMetrics[ca.name] == nil {
+			stats.CustomMetrics[ca.name] = make(map[string]any)
+		}
+		if result := ca.analyzer.Result(); result != nil {
+			stats.CustomMetrics[ca.name][colName] = result
+		}
+		if d, ok := ca.analyzer.(TypeDetector); ok && stats.DetectedTypes[colName] == "" {
+			if typ := d.DetectedType(); typ != "" {
+				if stats.DetectedTypes == nil {
+					stats.DetectedTypes = make(map[string]string)
+				}
+				stats.DetectedTypes[colName] = typ
+			}
+		}
+	}
+}
+
+// TableAccumulator builds table statistics from records added one at a time,
+// for callers that stream rows from their own sources
+type TableAccumulator struct {
+	config     SamplingConfig
+	indexes    []int
+	sampleIdx  []int // Positions of the columns of the example rows
+	columns    []*columnAccumulator
+	sampleRows int
+	sampleData [][]string
+	rows       int64
+	keys       *keyTracker         // Set when config.UniqueKey is
+	candidates []*compositeCounter // One per config.KeyCandidates tuple
+	groups     *groupTracker       // Set when config.GroupBy is
+	plugins    []*pluginSession    // One per config.Plugins entry
+	scratch    []byte              // Row bytes being converted by addFields
+	record     []string            // Row being passed from addFields to add
+}
+
+// NewTableAccumulator creates an accumulator for records with the given header.
+// config.Columns, config.ExcludeColumns, config.SampleRows, config.SampleColumns,
+// config.UniqueKey, config.KeyCandidates, config.GroupBy, config.DetectAnomalies,
+// config.Recommend, config.Patterns, config.NonFinite, config.ExactSums,
+// config.TypeTolerance and config.Plugins are honored. An accumulator that is
+// not finalized must be discarded to stop its plugins.
+func NewTableAccumulator(header []string, config SamplingConfig) (*TableAccumulator, error) {
+	return newTableAccumulator(context.Background(), header, config)
+}
+
+// newTableAccumulator creates an accumulator whose plugins are killed when ctx
+// is cancelled
+func newTableAccumulator(ctx context.Context, header []string, config SamplingConfig) (*TableAccumulator, error) {
+	indexes, err := config.columnIndexes(header)
+	if err != nil {
+		return nil, err
+	}
+
+	sampleRows := config.SampleRows
+	if sampleRows == 0 {
+		sampleRows = DefaultSampleRows
+	} else if sampleRows < 0 {
+		sampleRows = 0
+	}
+
+	sampleIdx, err := config.sampleIndexes(header, indexes)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TableAccumulator{
+		config:     config,
+		indexes:    indexes,
+		sampleIdx:  sampleIdx,
+		columns:    make([]*columnAccumulator, len(indexes)),
+		sampleRows: sampleRows,
+		sampleData: make([][]string, 0),
+	}
+	if len(config.UniqueKey) > 0 {
+		keyIdx, err := config.keyIndexes(header)
+		if err != nil {
+			return nil, err
+		}
+		t.keys = newKeyTracker(config.UniqueKey, keyIdx)
+	}
+	candidateIdx, err := config.candidateIndexes(header)
+	if err != nil {
+		return nil, err
+	}
+	for i, columns := range config.KeyCandidates {
+		t.candidates = append(t.candidates, newCompositeCounter(columns, candidateIdx[i]))
+	}
+	if config.GroupBy != "" {
+		groupIdx, err := config.groupIndex(header)
+		if err != nil {
+			return nil, err
+		}
+		t.groups = newGroupTracker(config.GroupBy, groupIdx, indexes)
+	}
+	patterns, err := config.compilePatterns(header, indexes)
+	if err != nil {
+		return nil, err
+	}
+	for i, idx := range indexes {
+		t.columns[i] = newColumnAccumulator(header[idx], config.SampleSize >= SketchSampleSize)
+		t.columns[i].nanPolicy = config.NonFinite
+		t.columns[i].typeTolerance = config.TypeTolerance
+		if config.ExactSums {
+			t.columns[i].numeric.sum = newExactSum()
+		}
+		if config.DetectAnomalies {
+			t.columns[i].anomalies = newAnomalyScanner(header[idx])
+		}
+		if re, ok := patterns[i]; ok {
+			t.columns[i].pattern = newPatternCheck(re)
+		}
+	}
+	// Each plugin runs once for the table and sees every profiled column
+	names := make([]string, len(indexes))
+	for i, idx := range indexes {
+		names[i] = header[idx]
+	}
+	for _, plugin := range config.Plugins {
+		session := newPluginSession(ctx, plugin, names)
+		t.plugins = append(t.plugins, session)
+		for i, c := range t.columns {
+			c.custom = append(c.custom, columnAnalyzer{name: plugin.Name, analyzer: &pluginColumn{session: session, index: i}})
+		}
+	}
+	return t, nil
+}
+
+// Add records a single row. Missing trailing fields are treated as nulls.
+// The record slice is not retained, so callers may reuse it between calls.
+func (t *TableAccumulator) Add(record []string) {
+	t.add(record, 0)
+}
+
+func (t *TableAccumulator) add(record []string, weight float64) {
+	t.rows++
+	if t.keys != nil {
+		t.keys.add(record)
+	}
+	for _, k := range t.candidates {
+		k.add(record)
+	}
+	if t.groups != nil {
+		t.groups.add(record, weight)
+	}
+	if len(t.sampleData) < t.sampleRows {
+		t.sampleData = append(t.sampleData, projectRecords([][]string{record}, t.sampleIdx)[0])
+	}
+	for i, idx := range t.indexes {
+		value := ""
+		if idx < len(record) {
+			value = record[idx]
+		}
+		t.columns[i].add(value, weight)
+	}
+}
+
+// addSample records the rows of a sample, one column at a time. Rows are only
+// put together for the trackers that relate the fields of a row.
+func (t *TableAccumulator) addSample(s *Sample) {
+	rows := s.Len()
+	weight := func(i int) float64 {
+		if s.Weights == nil {
+			return 0
+		}
+		return s.Weights[i]
+	}
+
+	byRow := rows
+	if t.keys == nil && len(t.candidates) == 0 && t.groups == nil {
+		byRow = min(rows, t.sampleRows)
+	}
+	record := make([]string, len(s.Columns))
+	for i := 0; i < byRow; i++ {
+		for j, column := range s.Columns {
+			record[j] = column.Value(i)
+		}
+		if t.keys != nil {
+			t.keys.add(record)
+		}
+		for _, k := range t.candidates {
+			k.add(record)
+		}
+		if t.groups != nil {
+			t.groups.add(record, weight(i))
+		}
+		if len(t.sampleData) < t.sampleRows {
+			t.sampleData = append(t.sampleData, projectRecords([][]string{record}, t.sampleIdx)[0])
+		}
+	}
+
+	t.rows += int64(rows)
+	for i, idx := range t.indexes {
+		c, column := t.columns[i], s.Columns[idx]
+		for row := 0; row < rows; row++ {
+			c.add(column.Value(row), weight(row))
+		}
+	}
+}
+
+// addFields records a row of fields that are only valid for the duration of
+// the call. Rows whose values were all seen before are accumulated without
+// allocating; other rows are converted to one string shared by their fields,
+// as encoding/csv does.
+func (t *TableAccumulator) addFields(fields [][]byte) {
+	if len(t.sampleData) < t.sampleRows || t.keys != nil || len(t.candidates) > 0 || t.groups != nil || t.keepsValue(fields) {
+		t.scratch = t.scratch[:0]
+		for _, field := range fields {
+			t.scratch = append(t.scratch, field...)
+		}
+		line := string(t.scratch)
+		t.record = t.record[:0]
+		start := 0
+		for _, field := range fields {
+			t.record = append(t.record, line[start:start+len(field)])
+			start += len(field)
+		}
+		t.add(t.record, 0)
+		return
+	}
+
+	t.rows++
+	for i, idx := range t.indexes {
+		var value []byte
+		if idx < len(fields) {
+			value = fields[idx]
+		}
+		t.columns[i].addBytes(value, 0)
+	}
+}
+
+// keepsValue reports whether adding the row would retain one of its values,
+// either as a new distinct value or by passing it to a custom analyzer
+func (t *TableAccumulator) keepsValue(fields [][]byte) bool {
+	for i, idx := range t.indexes {
+		c := t.columns[i]
+		if len(c.custom) > 0 {
+			return true
+		}
+		if idx >= len(fields) {
+			continue
+		}
+		value := bytes.TrimSpace(fields[idx])
+		if c.distinct == nil || isNullValue(value) {
+			continue
+		}
+		if _, ok := c.distinct[string(value)]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// setWeighted makes the numeric aggregates weighted estimates. It must be
+// called before any record is added.
+func (t *TableAccumulator) setWeighted() {
+	for _, col := range t.columns {
+		col.weighted = true
+	}
+}
+
+// Finalize returns the statistics of every record added so far
+func (t *TableAccumulator) Finalize() *TableStats {
+	return t.finalize(t.rows)
+}
+
+// Discard stops the plugins of an accumulator that will not be finalized,
+// e.g. because reading its rows failed
+func (t *TableAccumulator) Discard() {
+	for _, session := range t.plugins {
+		session.discard()
+	}
+}
+
+func (t *TableAccumulator) finalize(estimatedRows int64) *TableStats {
+	names := make([]string, len(t.columns))
+	for i, col := range t.columns {
+		names[i] = col.name
+	}
+
+	stats := &TableStats{
+		SchemaVersion:  CurrentSchemaVersion,
+		RowCount:       t.rows,
+		EstimatedRows:  estimatedRows,
+		ColumnCount:    len(names),
+		ColumnNames:    names,
+		ColumnTypes:    make(map[string]string),
+		NullCounts:     make(map[string]int64),
+		EmptyCounts:    make(map[string]int64),
+		NullTokens:     make(map[string]int64),
+		NullPercentage: make(map[string]float64),
+		DistinctCounts: make(map[string]int64),
+		MinValues:      make(map[string]interface{}),
+		MaxValues:      make(map[string]interface{}),
+		SampleData:     t.sampleData,
+		SampleColumns:  t.config.SampleColumns,
+		Aggregates:     make(map[string]*AggregateStats),
+		SamplingConfig: t.config,
+		Provenance:     newProvenance(t.rows, estimatedRows),
+	}
+	if t.keys != nil {
+		stats.UniqueKey = t.keys.result(t.rows, t.rows == estimatedRows)
+	}
+	for _, k := range t.candidates {
+		stats.KeyCandidates = append(stats.KeyCandidates, k.result(t.rows == estimatedRows))
+	}
+	if t.rows == 0 {
+		return stats
+	}
+
+	var valueBytes int64
+	stats.Memory = &MemoryFootprint{Columns: make(map[string]int64, len(t.columns))}
+	stats.Compression = make(map[string]*Compressibility, len(t.columns))
+	compressor := newCompressor()
+	for _, col := range t.columns {
+		col.finalize(stats, estimatedRows)
+		stats.Provenance.addColumn(col, stats)
+		valueBytes += col.valueBytes
+		stats.Memory.add(col.name, col.footprint(stats.ColumnTypes[col.name], estimatedRows))
+		if comp := col.compressibility(compressor, estimatedRows); comp != nil {
+			stats.Compression[col.name] = comp
+		}
+	}
+	if t.groups != nil {
+		stats.Groups = t.groups.result(stats)
+	}
+	stats.EstimatedSize = int64(float64(valueBytes) / float64(t.rows) * float64(estimatedRows))
+	if t.config.Recommend {
+		stats.Recommendations = recommend(stats)
+	}
+	return stats
+}
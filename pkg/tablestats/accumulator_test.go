@@ -0,0 +1,282 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableAccumulator(t *testing.T) {
+	header := []string{"id", "name", "score"}
+	records := [][]string{
+		{"1", "Alice", "85.5"},
+		{"2", "Bob", ""},
+		{"3", "Charlie", "78.5"},
+		{"4"},
+	}
+	config := SamplingConfig{SampleRows: 2, ExcludeColumns: []string{"name"}}
+
+	acc, err := NewTableAccumulator(header, config)
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range records {
+		acc.Add(record)
+	}
+	stats := acc.Finalize()
+
+	if stats.RowCount != 4 || stats.EstimatedRows != 4 {
+		t.Errorf("Expected 4 rows, got %d (estimated %d)", stats.RowCount, stats.EstimatedRows)
+	}
+	if stats.ColumnCount != 2 || !reflect.DeepEqual(stats.ColumnNames, []string{"id", "score"}) {
+		t.Errorf("Expected columns [id score], got %d %v", stats.ColumnCount, stats.ColumnNames)
+	}
+	if !reflect.DeepEqual(stats.ColumnTypes, map[string]string{"id": "int64", "score": "float64"}) {
+		t.Errorf("Expected int64 id and float64 score, got %v", stats.ColumnTypes)
+	}
+	// The blank score and the one missing from the short row are both nulls
+	if stats.NullCounts["score"] != 2 || stats.EmptyCounts["score"] != 2 || stats.NullCounts["id"] != 0 {
+		t.Errorf("Expected 2 empty null scores and no null ids, got %v (empty %v)", stats.NullCounts, stats.EmptyCounts)
+	}
+	if !floatEqual(stats.NullPercentage["score"], 50) {
+		t.Errorf("Expected 50%% null scores, got %f", stats.NullPercentage["score"])
+	}
+	if stats.DistinctCounts["id"] != 4 || stats.DistinctCounts["score"] != 2 {
+		t.Errorf("Expected 4 distinct ids and 2 scores, got %v", stats.DistinctCounts)
+	}
+	if !reflect.DeepEqual(stats.MinValues, map[string]interface{}{"id": int64(1), "score": 78.5}) ||
+		!reflect.DeepEqual(stats.MaxValues, map[string]interface{}{"id": int64(4), "score": 85.5}) {
+		t.Errorf("Expected id 1-4 and score 78.5-85.5, got %v-%v", stats.MinValues, stats.MaxValues)
+	}
+	if id := stats.Aggregates["id"]; id == nil || id.Count != 4 || !floatEqual(id.Sum, 10) {
+		t.Errorf("Expected 4 ids summing to 10, got %+v", id)
+	}
+	if !floatEqual(stats.Aggregates["score"].Mean, 82.0) {
+		t.Errorf("Expected mean score 82, got %f", stats.Aggregates["score"].Mean)
+	}
+	if !reflect.DeepEqual(stats.SampleData, [][]string{{"1", "85.5"}, {"2", ""}}) {
+		t.Errorf("Expected two projected sample rows, got %v", stats.SampleData)
+	}
+
+	if _, err := NewTableAccumulator(header, SamplingConfig{Columns: []string{"missing"}}); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+}
+
+func TestTableAccumulator_SampleColumns(t *testing.T) {
+	header := []string{"id", "name", "score"}
+	config := SamplingConfig{Columns: []string{"score"}, SampleColumns: []string{"name", "id"}}
+	acc, err := NewTableAccumulator(header, config)
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	acc.Add([]string{"1", "Alice", "85.5"})
+	acc.Add([]string{"2"})
+	stats := acc.Finalize()
+
+	expected := [][]string{{"Alice", "1"}, {"", "2"}}
+	if !reflect.DeepEqual(stats.SampleData, expected) {
+		t.Errorf("Expected sample data %v, got %v", expected, stats.SampleData)
+	}
+	if !reflect.DeepEqual(stats.SampleColumns, []string{"name", "id"}) {
+		t.Errorf("Expected sample columns name and id, got %v", stats.SampleColumns)
+	}
+
+	var b strings.Builder
+	if err := (&MarkdownRenderer{}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "| name | id |\n| --- | --- |\n| Alice | 1 |\n") {
+		t.Errorf("Expected the sample columns in the markdown table, got:\n%s", b.String())
+	}
+
+	if err := stats.Mask([]string{"name"}, Masking{}); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+	if stats.SampleData[0][0] != redacted {
+		t.Errorf("Expected the name to be masked, got %v", stats.SampleData[0])
+	}
+
+	config.SampleColumns = []string{"email"}
+	if _, err := NewTableAccumulator(header, config); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestReadTable_SampleColumns(t *testing.T) {
+	data := "id,name,score\n1,Alice,85.5\n2,Bob,70\n"
+	config := DefaultSamplingConfig()
+	config.Columns = []string{"score"}
+	config.SampleColumns = []string{"id", "name"}
+	stats, err := NewCSVReader(',').ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadTableFrom failed: %v", err)
+	}
+	expected := [][]string{{"1", "Alice"}, {"2", "Bob"}}
+	if !reflect.DeepEqual(stats.SampleData, expected) {
+		t.Errorf("Expected sample data %v, got %v", expected, stats.SampleData)
+	}
+}
+
+func TestTableAccumulator_Empty(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"a", "b"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+
+	stats := acc.Finalize()
+	if stats.RowCount != 0 || stats.ColumnCount != 2 {
+		t.Errorf("Expected 0 rows and 2 columns, got %d rows and %d columns", stats.RowCount, stats.ColumnCount)
+	}
+	if len(stats.ColumnTypes) != 0 {
+		t.Errorf("Expected no column types, got %v", stats.ColumnTypes)
+	}
+}
+
+func TestTableAccumulator_NumbersThenText(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"salary"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	// A malformed value turns the column into text; later values compare as text
+	for _, value := range []string{"500", "20.5", "#300", "7000"} {
+		acc.Add([]string{value})
+	}
+
+	stats := acc.Finalize()
+	if stats.ColumnTypes["salary"] != "string" {
+		t.Errorf("Expected string type, got %s", stats.ColumnTypes["salary"])
+	}
+	if stats.MinValues["salary"] != "#300" || stats.MaxValues["salary"] != "7000" {
+		t.Errorf("Expected min #300 and max 7000, got %v and %v", stats.MinValues["salary"], stats.MaxValues["salary"])
+	}
+}
+
+func TestTableAccumulator_ExactIntRange(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"id", "amount"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range [][]string{
+		{"9223372036854775807", "1"},
+		{"-9223372036854775808", "2.5"},
+		{"9007199254740993", "3"},
+	} {
+		acc.Add(record)
+	}
+
+	stats := acc.Finalize()
+	if stats.MinValues["id"] != int64(math.MinInt64) || stats.MaxValues["id"] != int64(math.MaxInt64) {
+		t.Errorf("Expected the exact int64 range, got %v to %v", stats.MinValues["id"], stats.MaxValues["id"])
+	}
+	if stats.MinValues["amount"] != float64(1) || stats.MaxValues["amount"] != float64(3) {
+		t.Errorf("Expected a float range of 1 to 3, got %v to %v", stats.MinValues["amount"], stats.MaxValues["amount"])
+	}
+
+	data, err := Marshal(stats)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"id":9223372036854775807`) {
+		t.Errorf("Expected the exact maximum in JSON, got %s", data)
+	}
+	loaded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if loaded.MaxValues["id"] != int64(math.MaxInt64) || loaded.MaxValues["amount"] != float64(3) {
+		t.Errorf("Expected the range to survive a round trip, got %v and %v", loaded.MaxValues["id"], loaded.MaxValues["amount"])
+	}
+
+	// Integers beyond int64 fall back to floats
+	acc, _ = NewTableAccumulator([]string{"id"}, SamplingConfig{})
+	acc.Add([]string{"1"})
+	acc.Add([]string{"99999999999999999999"})
+	stats = acc.Finalize()
+	if stats.ColumnTypes["id"] != "int64" || stats.MaxValues["id"] != 1e20 {
+		t.Errorf("Expected a float maximum of 1e20, got %v (%T)", stats.MaxValues["id"], stats.MaxValues["id"])
+	}
+}
+
+func TestTableAccumulator_NonFinitePolicy(t *testing.T) {
+	header := []string{"value"}
+	records := [][]string{{"1"}, {"NaN"}, {"3"}, {"+Inf"}, {"-infinity"}}
+	counts := &NonFiniteCounts{NaN: 1, PosInf: 1, NegInf: 1}
+
+	profile := func(policy NonFinitePolicy) *TableStats {
+		sample := withRecords(&Sample{Header: header, EstimatedRows: 5, Exact: true}, records)
+		return AnalyzeSample(sample, SamplingConfig{NonFinite: policy})
+	}
+
+	stats := profile(NonFiniteIgnore)
+	if !reflect.DeepEqual(stats.NonFinite["value"], counts) {
+		t.Errorf("Expected counts %+v, got %+v", counts, stats.NonFinite["value"])
+	}
+	if stats.NullCounts["value"] != 3 || stats.ColumnTypes["value"] != "int64" {
+		t.Errorf("Expected 3 nulls in an int64 column, got %d in %s", stats.NullCounts["value"], stats.ColumnTypes["value"])
+	}
+	if agg := stats.Aggregates["value"]; agg.Count != 2 || !floatEqual(agg.Mean, 2) {
+		t.Errorf("Expected a mean of 2 over 2 values, got %f over %d", agg.Mean, agg.Count)
+	}
+
+	stats = profile(NonFiniteCount)
+	if !reflect.DeepEqual(stats.NonFinite["value"], counts) {
+		t.Errorf("Expected counts %+v, got %+v", counts, stats.NonFinite["value"])
+	}
+	if stats.NullCounts["value"] != 0 || stats.DistinctCounts["value"] != 5 {
+		t.Errorf("Expected no nulls and 5 distinct values, got %d and %d", stats.NullCounts["value"], stats.DistinctCounts["value"])
+	}
+	if agg := stats.Aggregates["value"]; agg.Count != 2 || !floatEqual(agg.Mean, 2) {
+		t.Errorf("Expected a mean of 2 over 2 values, got %f over %d", agg.Mean, agg.Count)
+	}
+	if stats.MinValues["value"] != float64(1) || stats.MaxValues["value"] != float64(3) {
+		t.Errorf("Expected range 1 to 3, got %v to %v", stats.MinValues["value"], stats.MaxValues["value"])
+	}
+
+	stats = profile(NonFinitePropagate)
+	if agg := stats.Aggregates["value"]; agg.Count != 5 || !math.IsNaN(agg.Mean) {
+		t.Errorf("Expected a NaN mean over 5 values, got %f over %d", agg.Mean, agg.Count)
+	}
+
+	// In text columns they are plain strings
+	stats = AnalyzeSample(withRecords(&Sample{Header: header}, append(records, []string{"n/a"})), SamplingConfig{})
+	if stats.NonFinite != nil {
+		t.Errorf("Expected no counts for a string column, got %+v", stats.NonFinite)
+	}
+
+	for name, want := range map[string]NonFinitePolicy{"": NonFiniteIgnore, "propagate": NonFinitePropagate, "count-separately": NonFiniteCount} {
+		if policy, err := ParseNonFinitePolicy(name); err != nil || policy != want {
+			t.Errorf("Expected %v for %q, got %v (%v)", want, name, policy, err)
+		}
+	}
+	if _, err := ParseNonFinitePolicy("drop"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestTableAccumulator_EmptyAndNullTokens(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"id", "note"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range [][]string{{"1", ""}, {"2", "  "}, {"3", "NULL"}, {"4", "null"}, {"5", "ok"}, {"6"}} {
+		acc.Add(record)
+	}
+	stats := acc.Finalize()
+
+	if stats.NullCounts["note"] != 5 {
+		t.Errorf("Expected 5 nulls, got %d", stats.NullCounts["note"])
+	}
+	if stats.EmptyCounts["note"] != 3 {
+		t.Errorf("Expected 3 empty fields (blank and missing ones included), got %d", stats.EmptyCounts["note"])
+	}
+	if stats.NullTokens["note"] != 2 {
+		t.Errorf("Expected 2 NULL tokens, got %d", stats.NullTokens["note"])
+	}
+	if stats.EmptyCounts["id"] != 0 || stats.NullTokens["id"] != 0 {
+		t.Errorf("Expected no nulls in id, got %d empty and %d NULL", stats.EmptyCounts["id"], stats.NullTokens["id"])
+	}
+}
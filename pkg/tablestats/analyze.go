@@ -0,0 +1,192 @@
+package tablestats
+
+import (
+	"context"
+	"fmt"
+)
+
+// AnalyzeSample computes the table statistics for rows selected by a reader.
+// Only the columns selected by config.Columns and config.ExcludeColumns are
+// analyzed; unknown column names are ignored.
+func AnalyzeSample(sample *Sample, config SamplingConfig) *TableStats {
+	return analyzeSample(context.Background(), sample, config)
+}
+
+// analyzeSample is AnalyzeSample with the plugins bound to ctx
+func analyzeSample(ctx context.Context, sample *Sample, config SamplingConfig) *TableStats {
+	acc, err := newTableAccumulator(ctx, sample.Header, config)
+	if err != nil {
+		// Readers reject unknown columns up front; here they are dropped
+		config.Columns = knownColumns(sample.Header, config.Columns)
+		config.ExcludeColumns = knownColumns(sample.Header, config.ExcludeColumns)
+		if len(knownColumns(sample.Header, config.UniqueKey)) != len(config.UniqueKey) {
+			config.UniqueKey = nil
+		}
+		var candidates [][]string
+		for _, columns := range config.KeyCandidates {
+			if len(columns) > 0 && len(knownColumns(sample.Header, columns)) == len(columns) {
+				candidates = append(candidates, columns)
+			}
+		}
+		config.KeyCandidates = candidates
+		config.SampleColumns = knownColumns(sample.Header, config.SampleColumns)
+		if len(knownColumns(sample.Header, []string{config.GroupBy})) == 0 {
+			config.GroupBy = ""
+		}
+		patterns := make(map[string]string, len(config.Patterns))
+		for _, name := range knownColumns(sample.Header, sortedKeys(config.Patterns)) {
+			patterns[name] = config.Patterns[name]
+		}
+		config.Patterns = patterns
+		if acc, err = newTableAccumulator(ctx, sample.Header, config); err != nil {
+			// Invalid patterns are dropped as well
+			config.Patterns = nil
+			acc, _ = newTableAccumulator(ctx, sample.Header, config)
+		}
+	}
+	if sample.Weights != nil {
+		acc.setWeighted()
+	}
+
+	acc.addSample(sample)
+
+	stats := acc.finalize(sample.EstimatedRows)
+	stats.RaggedRows = sample.Ragged.found()
+	stats.ParseErrors = sample.ParseErrors
+	stats.RenamedColumns = sample.Renamed
+	stats.FileInfo = sample.FileInfo
+	return stats
+}
+
+// columnIndexes returns the header positions of the columns to profile
+func (c SamplingConfig) columnIndexes(header []string) ([]int, error) {
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[name] = i
+	}
+
+	for _, names := range [][]string{c.Columns, c.ExcludeColumns} {
+		for _, name := range names {
+			if _, ok := positions[name]; !ok {
+				return nil, fmt.Errorf("column %q: %w", name, ErrUnknownColumn)
+			}
+		}
+	}
+	for name := range c.Patterns {
+		if _, ok := positions[name]; !ok {
+			return nil, fmt.Errorf("pattern column %q: %w", name, ErrUnknownColumn)
+		}
+	}
+
+	excluded := make(map[string]bool, len(c.ExcludeColumns))
+	for _, name := range c.ExcludeColumns {
+		excluded[name] = true
+	}
+
+	var indexes []int
+	if len(c.Columns) > 0 {
+		for _, name := range c.Columns {
+			if idx, ok := positions[name]; ok && !excluded[name] {
+				indexes = append(indexes, idx)
+			}
+		}
+		return indexes, nil
+	}
+	for i, name := range header {
+		if !excluded[name] {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+// sampleIndexes returns the header positions of the columns of the example
+// rows: the SampleColumns, or indexes when none are set
+func (c SamplingConfig) sampleIndexes(header []string, indexes []int) ([]int, error) {
+	if len(c.SampleColumns) == 0 {
+		return indexes, nil
+	}
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		positions[name] = i
+	}
+	var sampleIdx []int
+	for _, name := range c.SampleColumns {
+		idx, ok := positions[name]
+		if !ok {
+			return nil, fmt.Errorf("sample column %q: %w", name, ErrUnknownColumn)
+		}
+		sampleIdx = append(sampleIdx, idx)
+	}
+	return sampleIdx, nil
+}
+
+// sampleColumnNames returns the columns of the example rows
+func (s *TableStats) sampleColumnNames() []string {
+	if len(s.SampleColumns) > 0 {
+		return s.SampleColumns
+	}
+	return s.ColumnNames
+}
+
+// knownColumns returns the names that appear in the header
+func knownColumns(header, names []string) []string {
+	var known []string
+	for _, name := range names {
+		for _, h := range header {
+			if h == name {
+				known = append(known, name)
+				break
+			}
+		}
+	}
+	return known
+}
+
+// projectRecords returns copies of the records holding only the given fields
+func projectRecords(records [][]string, indexes []int) [][]string {
+	projected := make([][]string, len(records))
+	for i, record := range records {
+		row := make([]string, 0, len(indexes))
+		for _, idx := range indexes {
+			if idx < len(record) {
+				row = append(row, record[idx])
+			} else {
+				row = append(row, "")
+			}
+		}
+		projected[i] = row
+	}
+	return projected
+}
+
+// storedFields returns the header positions, in header order, that a reader
+// must keep to profile indexes and to use the extra fields, such as the weight
+// column (negative positions are ignored), or nil when every field is needed
+func storedFields(width int, indexes []int, extra ...int) []int {
+	keep := make([]bool, width)
+	for _, idx := range indexes {
+		keep[idx] = true
+	}
+	for _, idx := range extra {
+		if idx >= 0 {
+			keep[idx] = true
+		}
+	}
+
+	var fields []int
+	for i, k := range keep {
+		if k {
+			fields = append(fields, i)
+		}
+	}
+	if len(fields) == width {
+		return nil
+	}
+	return fields
+}
+
+// isNullValue reports whether a trimmed field value represents a missing value
+func isNullValue[T fieldValue](value T) bool {
+	return len(value) == 0 || string(value) == "NULL" || string(value) == "null"
+}
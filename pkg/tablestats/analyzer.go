@@ -0,0 +1,66 @@
+package tablestats
+
+import (
+	"sort"
+	"sync"
+)
+
+// ColumnAnalyzer computes a custom metric over the values of one column.
+// Observe receives every raw field value, including nulls, in row order.
+type ColumnAnalyzer interface {
+	Observe(value string)
+	Result() any
+}
+
+// AnalyzerFactory creates an analyzer for the named column, or returns nil to
+// skip the column
+type AnalyzerFactory func(column string) ColumnAnalyzer
+
+var analyzers = struct {
+	sync.RWMutex
+	byName map[string]AnalyzerFactory
+}{byName: make(map[string]AnalyzerFactory)}
+
+// RegisterAnalyzer adds a custom analyzer that runs alongside the built-in
+// statistics. Its results are stored in TableStats.CustomMetrics under name.
+// A later registration with the same name replaces the earlier one.
+func RegisterAnalyzer(name string, factory AnalyzerFactory) {
+	analyzers.Lock()
+	defer analyzers.Unlock()
+	analyzers.byName[name] = factory
+}
+
+// UnregisterAnalyzer removes the analyzer registered under name, e.g. when a
+// test that registered it ends. Unknown names are ignored.
+func UnregisterAnalyzer(name string) {
+	analyzers.Lock()
+	defer analyzers.Unlock()
+	delete(analyzers.byName, name)
+}
+
+// columnAnalyzer is a registered analyzer bound to one column
+type columnAnalyzer struct {
+	name     string
+	analyzer ColumnAnalyzer
+}
+
+// newColumnAnalyzers instantiates every registered analyzer for a column,
+// ordered by analyzer name
+func newColumnAnalyzers(column string) []columnAnalyzer {
+	analyzers.RLock()
+	defer analyzers.RUnlock()
+
+	names := make([]string, 0, len(analyzers.byName))
+	for name := range analyzers.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var bound []columnAnalyzer
+	for _, name := range names {
+		if a := analyzers.byName[name](column); a != nil {
+			bound = append(bound, columnAnalyzer{name: name, analyzer: a})
+		}
+	}
+	return bound
+}
@@ -0,0 +1,63 @@
+package tablestats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// maxLengthAnalyzer tracks the longest value of a column
+type maxLengthAnalyzer struct{ max int }
+
+func (a *maxLengthAnalyzer) Observe(value string) {
+	if len(value) > a.max {
+		a.max = len(value)
+	}
+}
+
+func (a *maxLengthAnalyzer) Result() any {
+	return a.max
+}
+
+func TestRegisterAnalyzer(t *testing.T) {
+	RegisterAnalyzer("max_length", func(column string) ColumnAnalyzer {
+		// Only attach to the column used by this test
+		if column != "analyzer_code" {
+			return nil
+		}
+		return &maxLengthAnalyzer{}
+	})
+	t.Cleanup(func() { UnregisterAnalyzer("max_length") })
+
+	sample := withRecords(&Sample{
+		Header:        []string{"id", "analyzer_code"},
+		EstimatedRows: 3,
+	}, [][]string{{"1", "AB"}, {"2", "ABCDE"}, {"3", ""}})
+	stats := AnalyzeSample(sample, SamplingConfig{})
+
+	result, ok := stats.CustomMetrics["max_length"]["analyzer_code"]
+	if !ok {
+		t.Fatalf("Expected max_length result for analyzer_code, got %v", stats.CustomMetrics)
+	}
+	if result != 5 {
+		t.Errorf("Expected max length 5, got %v", result)
+	}
+	if _, ok := stats.CustomMetrics["max_length"]["id"]; ok {
+		t.Error("Expected no result for a column the factory skipped")
+	}
+
+	// Custom metrics are printed with the column details
+	var buf bytes.Buffer
+	if err := (&TextRenderer{Wide: true}).Render(&buf, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "max_length: 5") {
+		t.Errorf("Expected output to contain max_length: 5, got:\n%s", buf.String())
+	}
+
+	UnregisterAnalyzer("max_length")
+	stats = AnalyzeSample(sample, SamplingConfig{})
+	if _, ok := stats.CustomMetrics["max_length"]; ok {
+		t.Error("Expected no max_length results once unregistered")
+	}
+}
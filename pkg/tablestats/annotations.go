@@ -0,0 +1,82 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Annotation is a failed check as reported to a CI system
+type Annotation struct {
+	Level   string // error or warning
+	File    string
+	Column  string // Empty for checks of the whole file
+	Message string
+}
+
+// Annotations returns an annotation per violation. Violations are errors when
+// the report fails the thresholds and warnings when it stays within them.
+func (r *ValidationReport) Annotations(file string, th SchemaThresholds) []Annotation {
+	level := "warning"
+	if r.Failed(th) {
+		level = "error"
+	}
+	annotations := make([]Annotation, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		a := Annotation{Level: level, File: file, Column: v.Column}
+		if v.Rule == "missing" {
+			a.Level, a.Message = "error", "required column is missing"
+		} else {
+			a.Message = fmt.Sprintf("%s violated by %d values", v.Rule, v.Count)
+			if len(v.Examples) > 0 {
+				a.Message += fmt.Sprintf(", e.g. %q in row %d", v.Examples[0].Value, v.Examples[0].Row)
+			}
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
+
+// Annotations returns an error annotation per failed expectation
+func (r *ExpectationReport) Annotations(file string) []Annotation {
+	var annotations []Annotation
+	for _, res := range r.Results {
+		a := Annotation{Level: "error", File: file, Column: res.Column}
+		switch {
+		case res.Error != "":
+			a.Message = fmt.Sprintf("expectation %s could not be evaluated: %s", res.Expectation, res.Error)
+		case !res.Passed:
+			a.Message = fmt.Sprintf("expectation %s failed (actual %s)", res.Expectation, res.Actual)
+		default:
+			continue
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
+
+// WriteGitHubAnnotations writes annotations as GitHub Actions workflow
+// commands (::error file=...::message), which show up inline in pull request
+// checks. The file and column are repeated in the message, as files that are
+// not part of the repository get no inline annotation.
+func WriteGitHubAnnotations(w io.Writer, annotations []Annotation) error {
+	ew := &errWriter{w: w}
+	for _, a := range annotations {
+		message := a.File + ": " + a.Message
+		if a.Column != "" {
+			message = fmt.Sprintf("%s: column %s: %s", a.File, a.Column, a.Message)
+		}
+		ew.printf("::%s file=%s,title=gotablestats::%s\n", a.Level, escapeGitHubProperty(a.File), escapeGitHubData(message))
+	}
+	return ew.err
+}
+
+// escapeGitHubData escapes the message of a workflow command
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
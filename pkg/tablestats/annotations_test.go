@@ -0,0 +1,46 @@
+package tablestats
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	report := &ValidationReport{
+		CheckedRows:   10,
+		CheckedValues: 20,
+		Violations: []*Violation{
+			{Column: "zip", Rule: "missing"},
+			{Column: "age", Rule: "between", Count: 2, Examples: []ViolationExample{{Row: 4, Value: "-1"}}},
+		},
+	}
+	expectations := &ExpectationReport{Results: []ExpectationResult{
+		{Expectation: "row_count > 0", Passed: true, Actual: "10"},
+		{Expectation: "columns.email.null_pct <= 1", Column: "email", Actual: "12.5"},
+		{Expectation: "columns.sku.type == string", Column: "sku", Error: "no value"},
+	}}
+
+	annotations := append(report.Annotations("data/a,b.csv", SchemaThresholds{}), expectations.Annotations("data/a,b.csv")...)
+	var buf bytes.Buffer
+	if err := WriteGitHubAnnotations(&buf, annotations); err != nil {
+		t.Fatalf("WriteGitHubAnnotations failed: %v", err)
+	}
+	expected := `::error file=data/a%2Cb.csv,title=gotablestats::data/a,b.csv: column zip: required column is missing
+::error file=data/a%2Cb.csv,title=gotablestats::data/a,b.csv: column age: between violated by 2 values, e.g. "-1" in row 4
+::error file=data/a%2Cb.csv,title=gotablestats::data/a,b.csv: column email: expectation columns.email.null_pct <= 1 failed (actual 12.5)
+::error file=data/a%2Cb.csv,title=gotablestats::data/a,b.csv: column sku: expectation columns.sku.type == string could not be evaluated: no value
+`
+	if buf.String() != expected {
+		t.Errorf("Expected\n%s\ngot\n%s", expected, buf.String())
+	}
+
+	// Violations within the thresholds are warnings, missing columns are not
+	report.Violations = report.Violations[1:]
+	annotations = report.Annotations("a.csv", SchemaThresholds{MaxViolations: 5})
+	if len(annotations) != 1 || annotations[0].Level != "warning" {
+		t.Errorf("Expected one warning, got %+v", annotations)
+	}
+	if got := escapeGitHubData("50% done\nnext"); got != "50%25 done%0Anext" {
+		t.Errorf("Expected escaped message, got %q", got)
+	}
+}
@@ -0,0 +1,268 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Anomaly is a suspicious artifact in the values of a numeric column
+type Anomaly struct {
+	Column string `json:"column"`
+	Kind   string `json:"kind"` // sentinel, out_of_range or bimodal
+	Detail string `json:"detail"`
+}
+
+// anomalySentinels are placeholder values often written instead of a null
+var anomalySentinels = []float64{-9999, -999, -99, -1, 0, 999, 9999, 99999, 999999}
+
+// sentinelMinShare is the share of values a sentinel must hold to be reported
+const sentinelMinShare = 0.05
+
+// sentinelSpikeRatio is how many times more often than the typical value a
+// sentinel must occur to be reported, so that e.g. the zeros of a column
+// holding only 0, 1 and 2 are not
+const sentinelSpikeRatio = 10
+
+// bimodalMinValues is the number of values needed to judge the shape of a
+// distribution; bimodalMinDistinct keeps code columns out of the check
+const (
+	bimodalMinValues   = 200
+	bimodalMinDistinct = 10
+	bimodalBins        = 20
+)
+
+// valueBounds is the range of values a column named after one of words can hold
+type valueBounds struct {
+	words    []string
+	min, max float64
+}
+
+var anomalyBounds = []valueBounds{
+	{words: []string{"age"}, min: 0, max: 150},
+	{words: []string{"percent", "percentage", "pct"}, min: 0, max: 100},
+	{words: []string{"price", "qty", "quantity", "count", "duration", "height", "weight", "distance"}, min: 0, max: math.Inf(1)},
+}
+
+// anomalyScanner watches the numeric values of a column for artifacts
+type anomalyScanner struct {
+	bounds    *valueBounds
+	word      string // Header word that selected bounds
+	outside   int64
+	sentinels []int64 // Counts of anomalySentinels
+	values    int64
+}
+
+func newAnomalyScanner(column string) *anomalyScanner {
+	s := &anomalyScanner{sentinels: make([]int64, len(anomalySentinels))}
+	for _, word := range headerWords(column) {
+		for i, b := range anomalyBounds {
+			for _, w := range b.words {
+				if w == word && s.bounds == nil {
+					s.bounds, s.word = &anomalyBounds[i], word
+				}
+			}
+		}
+	}
+	return s
+}
+
+// headerWords splits a column name into lower-case words at punctuation and
+// camelCase boundaries, e.g. "customerAge" and "customer_age" both give
+// customer and age
+func headerWords(name string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	var prev rune
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r):
+			flush()
+		case unicode.IsUpper(r) && unicode.IsLower(prev):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+		prev = r
+	}
+	flush()
+	return words
+}
+
+// observe records a numeric value
+func (s *anomalyScanner) observe(v float64) {
+	s.values++
+	if s.bounds != nil && (v < s.bounds.min || v > s.bounds.max) {
+		s.outside++
+	}
+	for i, sentinel := range anomalySentinels {
+		if v == sentinel {
+			s.sentinels[i]++
+			break
+		}
+	}
+}
+
+// report returns the anomalies of a numeric column with the given number of
+// distinct values and value distribution (weights may be nil)
+func (s *anomalyScanner) report(column string, distinct int64, values, weights []float64, integer bool) []Anomaly {
+	if s.values == 0 {
+		return nil
+	}
+	var anomalies []Anomaly
+	add := func(kind, format string, args ...any) {
+		anomalies = append(anomalies, Anomaly{Column: column, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	typical := 1 / float64(max(distinct, 1))
+	for i, count := range s.sentinels {
+		share := float64(count) / float64(s.values)
+		if share >= sentinelMinShare && share >= sentinelSpikeRatio*typical {
+			add("sentinel", "value %g holds %.2f%% of values (typical value %.2f%%)",
+				anomalySentinels[i], share*100, typical*100)
+		}
+	}
+
+	if s.outside > 0 {
+		expected := fmt.Sprintf("%g to %g", s.bounds.min, s.bounds.max)
+		if math.IsInf(s.bounds.max, 1) {
+			expected = fmt.Sprintf("at least %g", s.bounds.min)
+		}
+		add("out_of_range", "%d values (%.2f%%) outside the %s expected of %s",
+			s.outside, float64(s.outside)/float64(s.values)*100, expected, s.word)
+	}
+
+	if distinct >= bimodalMinDistinct && s.values >= bimodalMinValues {
+		if low, high, ok := bimodalPeaks(values, weights, integer); ok {
+			add("bimodal", "values cluster around %.4g and %.4g with few in between", low, high)
+		}
+	}
+	return anomalies
+}
+
+// bimodalPeaks looks for two separate peaks in a histogram of values between
+// their 1st and 99th percentile. It returns the centers of the two highest
+// peaks that are separated by a valley at most half as high as either.
+func bimodalPeaks(values, weights []float64, integer bool) (low, high float64, ok bool) {
+	weight := func(i int) float64 {
+		if weights == nil {
+			return 1
+		}
+		return weights[i]
+	}
+
+	// Trim the tails so that a few outliers do not squeeze the histogram
+	order := make([]int, len(values))
+	total := 0.0
+	for i := range order {
+		order[i] = i
+		total += weight(i)
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+	lo, hi := math.NaN(), math.NaN()
+	cum := 0.0
+	for _, i := range order {
+		cum += weight(i)
+		if math.IsNaN(lo) && cum >= 0.01*total {
+			lo = values[i]
+		}
+		if math.IsNaN(hi) && cum >= 0.99*total {
+			hi = values[i]
+		}
+	}
+	if math.IsNaN(lo) || math.IsNaN(hi) || !(hi > lo) || math.IsInf(hi-lo, 0) {
+		return 0, 0, false
+	}
+
+	// Integers get whole-numbered bins so that every bin covers as many values
+	bins := bimodalBins
+	width := (hi - lo) / float64(bins)
+	if integer {
+		width = math.Ceil((hi - lo + 1) / float64(bins))
+		bins = int(math.Ceil((hi - lo + 1) / width))
+	}
+	center := func(bin int) float64 {
+		if integer {
+			return lo + float64(bin)*width + (width-1)/2
+		}
+		return lo + (float64(bin)+0.5)*width
+	}
+	hist := make([]float64, bins)
+	for i, v := range values {
+		if v < lo || v > hi {
+			continue
+		}
+		hist[min(bins-1, int((v-lo)/width))] += weight(i)
+	}
+
+	// scale converts weights into numbers of values
+	scale := float64(len(values)) / total
+
+	// Smooth out sampling noise before looking for peaks
+	smooth := make([]float64, bins)
+	tallest := 0.0
+	for i := range hist {
+		left, right := hist[max(i-1, 0)], hist[min(i+1, bins-1)]
+		smooth[i] = (left + 2*hist[i] + right) / 4
+		tallest = math.Max(tallest, smooth[i])
+	}
+	var peaks []int
+	for i, h := range smooth {
+		if (i == 0 || h > smooth[i-1]) && (i == bins-1 || h >= smooth[i+1]) {
+			peaks = append(peaks, i)
+		}
+	}
+
+	best := 0.0
+	for a := 0; a < len(peaks); a++ {
+		for b := a + 1; b < len(peaks); b++ {
+			i, j := peaks[a], peaks[b]
+			lower := math.Min(smooth[i], smooth[j])
+			if j-i < 3 || lower < 0.2*tallest || lower <= best {
+				continue
+			}
+			valley := lower
+			for k := i + 1; k < j; k++ {
+				valley = math.Min(valley, smooth[k])
+			}
+			// The dip must also be too deep to be sampling noise: a smoothed
+			// bin of n values varies by about sqrt(0.375 n)
+			noise := math.Sqrt(0.375 * (lower + valley) * scale)
+			if valley <= lower/2 && (lower-valley)*scale >= 4*noise {
+				best = lower
+				low, high, ok = center(i), center(j), true
+			}
+		}
+	}
+	return low, high, ok
+}
+
+// distribution returns the numeric values the accumulator kept for
+// percentiles, with their weights (nil when uniform). Sketched columns return
+// their t-digest clusters.
+func (c *columnAccumulator) distribution() ([]float64, []float64) {
+	switch {
+	case c.numericValues != nil:
+		return c.numericValues, c.valueWeights
+	case c.numeric.digest != nil:
+		d := c.numeric.digest
+		d.merge()
+		values := make([]float64, len(d.centroids))
+		weights := make([]float64, len(d.centroids))
+		for i, centroid := range d.centroids {
+			values[i], weights[i] = centroid.mean, centroid.weight
+		}
+		return values, weights
+	default:
+		return c.numeric.quantiles.values, nil
+	}
+}
@@ -0,0 +1,117 @@
+package tablestats
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestHeaderWords(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"customer_age", []string{"customer", "age"}},
+		{"customerAge", []string{"customer", "age"}},
+		{"Discount Pct", []string{"discount", "pct"}},
+		{"page_views", []string{"page", "views"}},
+		{"ID", []string{"id"}},
+	}
+
+	for _, tt := range tests {
+		if got := headerWords(tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %v for %q, got %v", tt.want, tt.name, got)
+		}
+	}
+}
+
+func TestBimodalPeaks(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tests := []struct {
+		name string
+		draw func() float64
+		want bool
+	}{
+		{"normal", func() float64 { return rng.NormFloat64()*10 + 50 }, false},
+		{"uniform", func() float64 { return rng.Float64() * 100 }, false},
+		{"exponential", rng.ExpFloat64, false},
+		{"two clusters", func() float64 {
+			if rng.Intn(3) == 0 {
+				return rng.NormFloat64()*5 + 200
+			}
+			return rng.NormFloat64()*5 + 20
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Repeat with fresh samples so that noise alone does not decide the outcome
+			for run := 0; run < 20; run++ {
+				values := make([]float64, 1000)
+				for i := range values {
+					values[i] = tt.draw()
+				}
+				low, high, ok := bimodalPeaks(values, nil, false)
+				if ok != tt.want {
+					t.Fatalf("Expected bimodal %v, got %v (peaks %.1f and %.1f)", tt.want, ok, low, high)
+				}
+				if ok && (low < 10 || low > 30 || high < 190 || high > 210) {
+					t.Errorf("Expected peaks near 20 and 200, got %.1f and %.1f", low, high)
+				}
+			}
+		})
+	}
+}
+
+func TestTableAccumulator_Anomalies(t *testing.T) {
+	header := []string{"customer_age", "score", "latency_ms", "visits"}
+	rng := rand.New(rand.NewSource(1))
+	rows := make([][]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		age := 20 + rng.Intn(50)
+		if i%100 == 0 {
+			age = -age
+		}
+		score := fmt.Sprint(rng.Intn(1000))
+		if i%10 == 0 {
+			score = "9999"
+		}
+		latency := rng.NormFloat64()*3 + 10
+		if i%2 == 0 {
+			latency += 100
+		}
+		rows = append(rows, []string{
+			fmt.Sprint(age), score, fmt.Sprintf("%.2f", latency), fmt.Sprint(rng.Intn(3)),
+		})
+	}
+
+	analyze := func(detect bool) *TableStats {
+		acc, err := NewTableAccumulator(header, SamplingConfig{DetectAnomalies: detect})
+		if err != nil {
+			t.Fatalf("NewTableAccumulator failed: %v", err)
+		}
+		for _, row := range rows {
+			acc.Add(row)
+		}
+		return acc.Finalize()
+	}
+
+	stats := analyze(true)
+	var got []string
+	for _, a := range stats.Anomalies {
+		got = append(got, a.Column+":"+a.Kind)
+	}
+	// visits holds mostly zeros, but no more than its other values
+	expected := []string{"customer_age:out_of_range", "score:sentinel", "latency_ms:bimodal"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected anomalies %v, got %v", expected, stats.Anomalies)
+	}
+	if len(stats.Anomalies) > 0 && stats.Anomalies[0].Detail != "10 values (1.00%) outside the 0 to 150 expected of age" {
+		t.Errorf("Unexpected detail %q", stats.Anomalies[0].Detail)
+	}
+
+	if stats := analyze(false); stats.Anomalies != nil {
+		t.Errorf("Expected no anomalies unless requested, got %v", stats.Anomalies)
+	}
+}
@@ -0,0 +1,163 @@
+package tablestats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// RecordBatch returns the sampled rows as an Arrow record batch of large_utf8
+// columns holding the raw values, with nulls for the fields missing from short
+// rows. The batch shares the sample's arrays rather than copying them. The
+// caller must Release the batch.
+func (s *Sample) RecordBatch() arrow.RecordBatch {
+	fields := make([]arrow.Field, len(s.Header))
+	columns := make([]arrow.Array, len(s.Columns))
+	for i, name := range s.Header {
+		fields[i] = arrow.Field{Name: name, Type: arrow.BinaryTypes.LargeString, Nullable: true}
+		columns[i] = s.Columns[i]
+	}
+	return array.NewRecordBatch(arrow.NewSchema(fields, nil), columns, int64(s.Len()))
+}
+
+// ArrowRecordBatch converts the sample into a columnar Arrow record batch with
+// one typed column per header field, so sampled rows can be handed to Arrow and
+// Parquet writers without another pass through CSV. Columns whose non-null
+// values are all integers become int64, other numeric columns float64 and the
+// rest utf8; null values such as "" or "NULL" become Arrow nulls. The caller
+// must Release the batch.
+func (s *Sample) ArrowRecordBatch(mem memory.Allocator) arrow.RecordBatch {
+	fields := make([]arrow.Field, len(s.Header))
+	for i, name := range s.Header {
+		fields[i] = arrow.Field{Name: name, Type: arrowType(s.Columns[i]), Nullable: true}
+	}
+
+	builder := array.NewRecordBuilder(mem, arrow.NewSchema(fields, nil))
+	defer builder.Release()
+	builder.Reserve(s.Len())
+
+	for i, field := range builder.Fields() {
+		column := s.Columns[i]
+		for row := 0; row < column.Len(); row++ {
+			value := strings.TrimSpace(column.Value(row))
+			if isNullValue(value) {
+				field.AppendNull()
+				continue
+			}
+			switch b := field.(type) {
+			case *array.Int64Builder:
+				v, _ := strconv.ParseInt(value, 10, 64)
+				b.Append(v)
+			case *array.Float64Builder:
+				_, v := classifyNumber(value)
+				b.Append(v)
+			case *array.StringBuilder:
+				b.Append(value)
+			}
+		}
+	}
+	return builder.NewRecordBatch()
+}
+
+// arrowType picks the narrowest Arrow type that holds every value of a column.
+// Columns without any value are utf8.
+func arrowType(column *array.LargeString) arrow.DataType {
+	seen, integer := false, true
+	for row := 0; row < column.Len(); row++ {
+		value := strings.TrimSpace(column.Value(row))
+		if isNullValue(value) {
+			continue
+		}
+		seen = true
+		switch kind, _ := classifyNumber(value); kind {
+		case notNumber:
+			return arrow.BinaryTypes.String
+		case floatNumber:
+			integer = false
+		case intNumber:
+			// Exponents, Inf and NaN parse as numbers but not as int64
+			if integer {
+				if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+					integer = false
+				}
+			}
+		}
+	}
+	switch {
+	case !seen:
+		return arrow.BinaryTypes.String
+	case integer:
+		return arrow.PrimitiveTypes.Int64
+	default:
+		return arrow.PrimitiveTypes.Float64
+	}
+}
+
+// ArrowColumnStats converts the profile into an Arrow record batch with one row
+// per column: its name, type, null and distinct counts, min and max rendered
+// as text, and the aggregates and percentiles of numeric columns, null for
+// other columns. The row counts are kept in the schema metadata. The caller
+// must Release the batch.
+func (s *TableStats) ArrowColumnStats(mem memory.Allocator) arrow.RecordBatch {
+	fields := []arrow.Field{
+		{Name: "column", Type: arrow.BinaryTypes.String},
+		{Name: "type", Type: arrow.BinaryTypes.String},
+		{Name: "null_count", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "null_pct", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "distinct", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "min", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "max", Type: arrow.BinaryTypes.String, Nullable: true},
+	}
+	for _, name := range []string{"count", "sum", "mean", "median", "std_dev", "variance"} {
+		fields = append(fields, arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64, Nullable: true})
+	}
+	for _, p := range percentilePoints {
+		fields = append(fields, arrow.Field{Name: "p" + strconv.Itoa(p), Type: arrow.PrimitiveTypes.Float64, Nullable: true})
+	}
+	metadata := arrow.NewMetadata(
+		[]string{"row_count", "estimated_rows"},
+		[]string{strconv.FormatInt(s.RowCount, 10), strconv.FormatInt(s.EstimatedRows, 10)})
+
+	builder := array.NewRecordBuilder(mem, arrow.NewSchema(fields, &metadata))
+	defer builder.Release()
+	builder.Reserve(len(s.ColumnNames))
+
+	text := func(b array.Builder, v any) {
+		if v == nil {
+			b.AppendNull()
+		} else {
+			b.(*array.StringBuilder).Append(fmt.Sprint(v))
+		}
+	}
+	for _, name := range s.ColumnNames {
+		builder.Field(0).(*array.StringBuilder).Append(name)
+		builder.Field(1).(*array.StringBuilder).Append(s.ColumnTypes[name])
+		builder.Field(2).(*array.Int64Builder).Append(s.NullCounts[name])
+		builder.Field(3).(*array.Float64Builder).Append(s.NullPercentage[name])
+		builder.Field(4).(*array.Int64Builder).Append(s.DistinctCounts[name])
+		text(builder.Field(5), s.MinValues[name])
+		text(builder.Field(6), s.MaxValues[name])
+
+		agg := s.Aggregates[name]
+		values := make([]float64, 0, 6+len(percentilePoints))
+		if agg != nil {
+			values = append(values, float64(agg.Count), agg.Sum, agg.Mean, agg.Median, agg.StdDev, agg.Variance)
+			for _, p := range percentilePoints {
+				values = append(values, agg.Percentiles[p])
+			}
+		}
+		for i := 7; i < len(fields); i++ {
+			b := builder.Field(i).(*array.Float64Builder)
+			if agg == nil {
+				b.AppendNull()
+			} else {
+				b.Append(values[i-7])
+			}
+		}
+	}
+	return builder.NewRecordBatch()
+}
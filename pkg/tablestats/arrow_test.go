@@ -0,0 +1,128 @@
+package tablestats
+
+import (
+	"math"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+func TestSample_ArrowRecordBatch(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header: []string{"id", "score", "name", "empty", "big"},
+	}, [][]string{
+		{"1", "9.5", "Alice", "", "1e3"},
+		{"2", "", "Bob", "NULL", "Inf"},
+		{" 3 ", "7", "NULL", ""}, // Missing trailing field
+	})
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	batch := sample.ArrowRecordBatch(mem)
+	defer batch.Release()
+
+	if batch.NumRows() != 3 || batch.NumCols() != 5 {
+		t.Fatalf("Expected 3x5 batch, got %dx%d", batch.NumRows(), batch.NumCols())
+	}
+
+	expectedTypes := []arrow.DataType{
+		arrow.PrimitiveTypes.Int64,
+		arrow.PrimitiveTypes.Float64,
+		arrow.BinaryTypes.String,
+		arrow.BinaryTypes.String,
+		arrow.PrimitiveTypes.Float64,
+	}
+	for i, want := range expectedTypes {
+		if got := batch.Schema().Field(i).Type; !arrow.TypeEqual(got, want) {
+			t.Errorf("Expected column %s to be %s, got %s", sample.Header[i], want, got)
+		}
+	}
+
+	ids := batch.Column(0).(*array.Int64)
+	if ids.Value(0) != 1 || ids.Value(2) != 3 {
+		t.Errorf("Expected ids 1 and 3, got %d and %d", ids.Value(0), ids.Value(2))
+	}
+	scores := batch.Column(1).(*array.Float64)
+	if scores.Value(0) != 9.5 || !scores.IsNull(1) {
+		t.Errorf("Expected score 9.5 then null, got %v", scores)
+	}
+	names := batch.Column(2).(*array.String)
+	if names.Value(1) != "Bob" || !names.IsNull(2) {
+		t.Errorf("Expected name Bob then null, got %v", names)
+	}
+	if batch.Column(3).NullN() != 3 {
+		t.Errorf("Expected an all-null column, got %d nulls", batch.Column(3).NullN())
+	}
+	big := batch.Column(4).(*array.Float64)
+	if big.Value(0) != 1000 || !math.IsInf(big.Value(1), 1) || !big.IsNull(2) {
+		t.Errorf("Expected 1000, +Inf and null, got %v", big)
+	}
+}
+
+func TestSample_RecordBatch(t *testing.T) {
+	sample := NewSample([]string{"id", "name"}, [][]string{{"1", "Alice"}, {" 2 ", "NULL"}, {"3"}})
+
+	batch := sample.RecordBatch()
+	defer batch.Release()
+
+	if batch.NumRows() != 3 || batch.NumCols() != 2 {
+		t.Fatalf("Expected 3x2 batch, got %dx%d", batch.NumRows(), batch.NumCols())
+	}
+	for i, name := range sample.Header {
+		if got := batch.Schema().Field(i); got.Name != name || !arrow.TypeEqual(got.Type, arrow.BinaryTypes.LargeString) {
+			t.Errorf("Expected large_utf8 column %s, got %s %s", name, got.Name, got.Type)
+		}
+		// The batch hands over the sample's arrays without copying
+		if batch.Column(i) != arrow.Array(sample.Columns[i]) {
+			t.Errorf("Expected column %s to share the sample's array", name)
+		}
+	}
+
+	// Values are kept raw; only fields missing from short rows are null
+	names := batch.Column(1).(*array.LargeString)
+	if names.Value(1) != "NULL" || !names.IsNull(2) {
+		t.Errorf("Expected NULL then null, got %v", names)
+	}
+	if ids := batch.Column(0).(*array.LargeString); ids.Value(1) != " 2 " {
+		t.Errorf("Expected raw value \" 2 \", got %q", ids.Value(1))
+	}
+}
+
+func TestTableStats_ArrowColumnStats(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header:        []string{"id", "name"},
+		EstimatedRows: 40,
+	}, [][]string{{"1", "Alice"}, {"2", ""}, {"3", "Carol"}, {"4", "Dan"}}), SamplingConfig{})
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	batch := stats.ArrowColumnStats(mem)
+	defer batch.Release()
+
+	if batch.NumRows() != 2 || batch.NumCols() != 19 {
+		t.Fatalf("Expected 2x19 batch, got %dx%d", batch.NumRows(), batch.NumCols())
+	}
+	if rows, _ := batch.Schema().Metadata().GetValue("estimated_rows"); rows != "40" {
+		t.Errorf("Expected estimated_rows 40 in the metadata, got %q", rows)
+	}
+
+	names := batch.Column(0).(*array.String)
+	if names.Value(0) != "id" || names.Value(1) != "name" {
+		t.Errorf("Expected columns id and name, got %s and %s", names.Value(0), names.Value(1))
+	}
+	if got := batch.Column(2).(*array.Int64).Value(1); got != 1 {
+		t.Errorf("Expected 1 null name, got %d", got)
+	}
+	if got := batch.Column(5).(*array.String).Value(0); got != "1" {
+		t.Errorf("Expected min id 1, got %q", got)
+	}
+
+	mean := batch.Column(batch.Schema().FieldIndices("mean")[0]).(*array.Float64)
+	if mean.Value(0) != 2.5 || !mean.IsNull(1) {
+		t.Errorf("Expected mean 2.5 for id and null for name, got %v and %v", mean.Value(0), mean.Value(1))
+	}
+}
@@ -0,0 +1,117 @@
+package tablestats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoBaseline is returned when the store has no baseline for a file
+var ErrNoBaseline = errors.New("no baseline stored")
+
+// LoadBaseline reads a profile saved with the JSON output format
+func LoadBaseline(path string) (*TableStats, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	stats, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline %s: %w", path, err)
+	}
+	return stats, nil
+}
+
+// BaselineStore keeps one baseline profile per data file in a directory. The
+// profiles are the JSON written by Marshal, so they can also be passed to
+// LoadBaseline directly.
+type BaselineStore struct {
+	Dir string
+}
+
+// NewBaselineStore returns a store that keeps its profiles in dir
+func NewBaselineStore(dir string) *BaselineStore {
+	return &BaselineStore{Dir: dir}
+}
+
+// Path returns where the baseline of filePath is stored. Files are keyed by
+// their absolute path, so the same name in different directories does not clash.
+func (s *BaselineStore) Path(filePath string) (string, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", filePath, err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := strings.TrimSuffix(filepath.Base(abs), filepath.Ext(abs))
+	return filepath.Join(s.Dir, name+"-"+hex.EncodeToString(sum[:6])+".json"), nil
+}
+
+// Has reports whether a baseline is stored for filePath
+func (s *BaselineStore) Has(filePath string) (bool, error) {
+	path, err := s.Path(filePath)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Save stores stats as the baseline of filePath, replacing any earlier one.
+// The profile is written to a temporary file first, so a failed save leaves
+// the previous baseline intact.
+func (s *BaselineStore) Save(filePath string, stats *TableStats) error {
+	path, err := s.Path(filePath)
+	if err != nil {
+		return err
+	}
+	data, err := Marshal(stats)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(s.Dir, path, data); err != nil {
+		return fmt.Errorf("failed to save baseline: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to path in dir through a temporary file, so
+// that a failed write leaves any previous file intact
+func writeFileAtomic(dir, path string, data []byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load returns the baseline stored for filePath, or ErrNoBaseline
+func (s *BaselineStore) Load(filePath string) (*TableStats, error) {
+	path, err := s.Path(filePath)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := LoadBaseline(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", filePath, ErrNoBaseline)
+	}
+	return stats, err
+}
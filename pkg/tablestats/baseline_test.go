@@ -0,0 +1,80 @@
+package tablestats
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadBaseline(t *testing.T) {
+	stats := &TableStats{RowCount: 3, ColumnNames: []string{"a"}, Categories: map[string][]string{"a": {"x"}}}
+	data, err := Marshal(stats)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+
+	loaded, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatalf("LoadBaseline failed: %v", err)
+	}
+	if loaded.RowCount != 3 || loaded.Categories["a"][0] != "x" {
+		t.Errorf("Unexpected baseline %+v", loaded)
+	}
+
+	os.WriteFile(path, []byte(`{"row_count": 3}`), 0o644)
+	if _, err := LoadBaseline(path); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("Expected ErrUnsupportedVersion, got %v", err)
+	}
+}
+
+func TestBaselineStore(t *testing.T) {
+	store := NewBaselineStore(filepath.Join(t.TempDir(), "baselines"))
+
+	if _, err := store.Load("data/feed.csv"); !errors.Is(err, ErrNoBaseline) {
+		t.Errorf("Expected ErrNoBaseline, got %v", err)
+	}
+	if ok, err := store.Has("data/feed.csv"); ok || err != nil {
+		t.Errorf("Expected no baseline, got %v, %v", ok, err)
+	}
+
+	if err := store.Save("data/feed.csv", &TableStats{RowCount: 10}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err := store.Save("other/feed.csv", &TableStats{RowCount: 20}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if ok, err := store.Has("data/feed.csv"); !ok || err != nil {
+		t.Errorf("Expected a stored baseline, got %v, %v", ok, err)
+	}
+
+	// Files with the same name in different directories keep separate baselines
+	for path, rows := range map[string]int64{"data/feed.csv": 10, "other/feed.csv": 20} {
+		stats, err := store.Load(path)
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if stats.RowCount != rows {
+			t.Errorf("Expected %d rows for %s, got %d", rows, path, stats.RowCount)
+		}
+	}
+
+	// Saving again replaces the baseline without leaving temporary files
+	if err := store.Save("data/feed.csv", &TableStats{RowCount: 30}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if stats, _ := store.Load("data/feed.csv"); stats == nil || stats.RowCount != 30 {
+		t.Errorf("Expected the updated baseline, got %+v", stats)
+	}
+	entries, err := os.ReadDir(store.Dir)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 stored files, got %d", len(entries))
+	}
+}
@@ -0,0 +1,112 @@
+package tablestats
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// bloomInitialCapacity is the number of keys the first filter of a
+// scalableBloom is sized for
+const bloomInitialCapacity = 1 << 16
+
+// bloomFilter is a fixed-size set that may report keys it never saw, but never
+// misses a key it did
+type bloomFilter struct {
+	bits     []uint64
+	hashes   int
+	capacity int64 // Keys the filter holds at its target false positive rate
+	count    int64
+}
+
+func newBloomFilter(capacity int64, falsePositiveRate float64) *bloomFilter {
+	// Optimal size and hash count for the capacity and rate
+	m := math.Ceil(-float64(capacity) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	k := max(1, int(math.Round(m/float64(capacity)*math.Ln2)))
+	return &bloomFilter{
+		bits:     make([]uint64, (int64(m)+63)/64),
+		hashes:   k,
+		capacity: capacity,
+	}
+}
+
+// positions derives the filter's bit positions from one 64-bit hash by double
+// hashing, calling fn for each
+func (f *bloomFilter) positions(hash uint64, fn func(word int, mask uint64) bool) bool {
+	size := uint64(len(f.bits)) * 64
+	h1, h2 := hash, hash>>32|hash<<32|1
+	for i := 0; i < f.hashes; i++ {
+		bit := (h1 + uint64(i)*h2) % size
+		if !fn(int(bit/64), 1<<(bit%64)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *bloomFilter) add(hash uint64) {
+	f.positions(hash, func(word int, mask uint64) bool {
+		f.bits[word] |= mask
+		return true
+	})
+	f.count++
+}
+
+func (f *bloomFilter) contains(hash uint64) bool {
+	return f.positions(hash, func(word int, mask uint64) bool {
+		return f.bits[word]&mask != 0
+	})
+}
+
+// scalableBloom is a Bloom filter that grows with the number of keys instead
+// of being sized up front. Each full filter is followed by one twice as large
+// with half the false positive rate, so the combined rate stays below the
+// target and memory stays proportional to the number of keys.
+type scalableBloom struct {
+	seed    maphash.Seed
+	filters []*bloomFilter
+	rate    float64 // False positive rate of the newest filter, halved for each new one
+}
+
+func newScalableBloom(falsePositiveRate float64) *scalableBloom {
+	return &scalableBloom{seed: maphash.MakeSeed(), rate: falsePositiveRate}
+}
+
+func (b *scalableBloom) add(value string) {
+	hash := maphash.String(b.seed, value)
+	if b.contains(hash) {
+		return
+	}
+	last := len(b.filters) - 1
+	if last < 0 || b.filters[last].count >= b.filters[last].capacity {
+		capacity := int64(bloomInitialCapacity)
+		if last >= 0 {
+			capacity = b.filters[last].capacity * 2
+		}
+		b.rate /= 2
+		b.filters = append(b.filters, newBloomFilter(capacity, b.rate))
+		last++
+	}
+	b.filters[last].add(hash)
+}
+
+func (b *scalableBloom) has(value string) bool {
+	return b.contains(maphash.String(b.seed, value))
+}
+
+func (b *scalableBloom) contains(hash uint64) bool {
+	for _, f := range b.filters {
+		if f.contains(hash) {
+			return true
+		}
+	}
+	return false
+}
+
+// bytes returns the memory held by the filters' bit arrays
+func (b *scalableBloom) bytes() int64 {
+	var total int64
+	for _, f := range b.filters {
+		total += int64(len(f.bits)) * 8
+	}
+	return total
+}
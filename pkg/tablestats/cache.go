@@ -0,0 +1,117 @@
+package tablestats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ResultCache keeps completed profiles in a directory, keyed by the file they
+// describe and the options they were made with, so that profiling an
+// unchanged file again can return the earlier result.
+type ResultCache struct {
+	Dir string
+	// TTL is how long a cached profile is used for; 0 means until the file changes
+	TTL time.Duration
+	// HashContent keys files by a SHA-256 of their content instead of their
+	// size and modification time. It reads the whole file, but survives
+	// copies and touches that keep the content.
+	HashContent bool
+}
+
+// NewResultCache returns a cache that keeps its profiles in dir for ttl
+func NewResultCache(dir string, ttl time.Duration) *ResultCache {
+	return &ResultCache{Dir: dir, TTL: ttl}
+}
+
+// cacheKey holds everything a cached profile depends on
+type cacheKey struct {
+	Path          string         `json:"path"`
+	Size          int64          `json:"size"`
+	ModTime       int64          `json:"mod_time,omitempty"`
+	ContentHash   string         `json:"content_hash,omitempty"`
+	Config        SamplingConfig `json:"config"`
+	Options       ReaderOptions  `json:"options"`
+	SchemaVersion int            `json:"schema_version"`
+}
+
+// Key returns the cache key of profiling filePath with config and opts
+func (c *ResultCache) Key(filePath string, config SamplingConfig, opts ReaderOptions) (string, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", filePath, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", filePath, err)
+	}
+
+	key := cacheKey{Path: abs, Size: info.Size(), Config: config, Options: opts, SchemaVersion: CurrentSchemaVersion}
+	if c.HashContent {
+		if key.ContentHash, err = hashFile(abs); err != nil {
+			return "", err
+		}
+	} else {
+		key.ModTime = info.ModTime().UnixNano()
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16]), nil
+}
+
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *ResultCache) path(key string) string {
+	return filepath.Join(c.Dir, key+".json")
+}
+
+// Get returns the profile cached under key. Expired and unreadable entries
+// are misses; expired ones are removed.
+func (c *ResultCache) Get(key string) (*TableStats, bool) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.TTL > 0 && time.Since(info.ModTime()) > c.TTL {
+		os.Remove(path)
+		return nil, false
+	}
+	stats, err := LoadBaseline(path)
+	if err != nil {
+		return nil, false
+	}
+	return stats, true
+}
+
+// Put caches stats under key
+func (c *ResultCache) Put(key string, stats *TableStats) error {
+	data, err := Marshal(stats)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(c.Dir, c.path(key), data); err != nil {
+		return fmt.Errorf("failed to cache profile: %w", err)
+	}
+	return nil
+}
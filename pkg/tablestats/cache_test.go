@@ -0,0 +1,95 @@
+package tablestats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestResultCache(t *testing.T) {
+	dir := t.TempDir()
+	dataPath := filepath.Join(dir, "data.csv")
+	if err := os.WriteFile(dataPath, []byte("a,b\n1,2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write data: %v", err)
+	}
+	config := DefaultSamplingConfig()
+
+	for _, hashContent := range []bool{false, true} {
+		cache := NewResultCache(filepath.Join(dir, "cache"), 0)
+		cache.HashContent = hashContent
+
+		key, err := cache.Key(dataPath, config, ReaderOptions{})
+		if err != nil {
+			t.Fatalf("Key failed: %v", err)
+		}
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("Expected a miss before caching (hash %v)", hashContent)
+		}
+		if err := cache.Put(key, &TableStats{RowCount: 1}); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+		if stats, ok := cache.Get(key); !ok || stats.RowCount != 1 {
+			t.Errorf("Expected the cached profile (hash %v), got %v, %v", hashContent, stats, ok)
+		}
+
+		// Other options and other files get other keys
+		other := config
+		other.SampleSize = 10
+		otherKey, _ := cache.Key(dataPath, other, ReaderOptions{})
+		delimKey, _ := cache.Key(dataPath, config, ReaderOptions{Delimiter: ';'})
+		if otherKey == key || delimKey == key {
+			t.Errorf("Expected different options to change the key (hash %v)", hashContent)
+		}
+	}
+
+	// Touching the file invalidates keys by modification time, not by content
+	byTime := NewResultCache(filepath.Join(dir, "cache"), 0)
+	byContent := &ResultCache{Dir: byTime.Dir, HashContent: true}
+	timeKey, _ := byTime.Key(dataPath, config, ReaderOptions{})
+	contentKey, _ := byContent.Key(dataPath, config, ReaderOptions{})
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(dataPath, later, later); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	if key, _ := byTime.Key(dataPath, config, ReaderOptions{}); key == timeKey {
+		t.Errorf("Expected a touched file to get a new key")
+	}
+	if key, _ := byContent.Key(dataPath, config, ReaderOptions{}); key != contentKey {
+		t.Errorf("Expected a touched file to keep its content key")
+	}
+
+	// Changed content always gets a new key
+	os.WriteFile(dataPath, []byte("a,b\n1,3\n"), 0o644)
+	if key, _ := byContent.Key(dataPath, config, ReaderOptions{}); key == contentKey {
+		t.Errorf("Expected changed content to get a new key")
+	}
+
+	if _, err := byTime.Key(filepath.Join(dir, "missing.csv"), config, ReaderOptions{}); err == nil {
+		t.Errorf("Expected an error for a missing file")
+	}
+}
+
+func TestResultCache_TTL(t *testing.T) {
+	cache := NewResultCache(t.TempDir(), time.Minute)
+	if err := cache.Put("k", &TableStats{RowCount: 1}); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if _, ok := cache.Get("k"); !ok {
+		t.Errorf("Expected a fresh entry to be used")
+	}
+
+	old := time.Now().Add(-2 * time.Minute)
+	os.Chtimes(cache.path("k"), old, old)
+	if _, ok := cache.Get("k"); ok {
+		t.Errorf("Expected an expired entry to be a miss")
+	}
+	if _, err := os.Stat(cache.path("k")); !os.IsNotExist(err) {
+		t.Errorf("Expected the expired entry to be removed, got %v", err)
+	}
+
+	os.WriteFile(cache.path("bad"), []byte("not json"), 0o644)
+	if _, ok := cache.Get("bad"); ok {
+		t.Errorf("Expected an unreadable entry to be a miss")
+	}
+}
@@ -0,0 +1,57 @@
+package tablestats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/cases"
+)
+
+// ValueCount is a value with the number of rows holding it
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// CaseGroup lists the spellings of a value that differ only in case, the most
+// frequent first, e.g. "USA" (120 rows), "usa" (4) and "Usa" (1)
+type CaseGroup []ValueCount
+
+// caseGroups returns the groups of values that are equal when case is folded,
+// ordered by their most frequent spelling
+func caseGroups(counts map[string]*int64) []CaseGroup {
+	fold := cases.Fold()
+	byKey := make(map[string]CaseGroup)
+	for v, n := range counts {
+		key := fold.String(v)
+		byKey[key] = append(byKey[key], ValueCount{Value: v, Count: *n})
+	}
+
+	var groups []CaseGroup
+	for _, g := range byKey {
+		if len(g) < 2 {
+			continue
+		}
+		sort.Slice(g, func(i, j int) bool {
+			if g[i].Count != g[j].Count {
+				return g[i].Count > g[j].Count
+			}
+			return g[i].Value < g[j].Value
+		})
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i][0].Value < groups[j][0].Value
+	})
+	return groups
+}
+
+// String describes the group, e.g. `"USA" (120), "usa" (4)`
+func (g CaseGroup) String() string {
+	parts := make([]string, len(g))
+	for i, vc := range g {
+		parts[i] = fmt.Sprintf("%q (%d)", vc.Value, vc.Count)
+	}
+	return strings.Join(parts, ", ")
+}
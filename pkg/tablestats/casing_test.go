@@ -0,0 +1,56 @@
+package tablestats
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTableAccumulator_CaseVariants(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"country", "code"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range [][]string{
+		{"USA", "1"}, {"usa", "2"}, {"USA", "3"}, {"Usa", "4"},
+		{"Straße", "5"}, {"STRASSE", "6"}, {"France", "7"},
+	} {
+		acc.Add(record)
+	}
+
+	stats := acc.Finalize()
+	expected := []CaseGroup{
+		{{"STRASSE", 1}, {"Straße", 1}},
+		{{"USA", 2}, {"Usa", 1}, {"usa", 1}},
+	}
+	if !reflect.DeepEqual(stats.CaseVariants["country"], expected) {
+		t.Errorf("Expected case variants %v, got %v", expected, stats.CaseVariants["country"])
+	}
+	if _, ok := stats.CaseVariants["code"]; ok {
+		t.Errorf("Expected no case variants for a numeric column")
+	}
+	if s := expected[1].String(); s != `"USA" (2), "Usa" (1), "usa" (1)` {
+		t.Errorf("Unexpected group description %s", s)
+	}
+
+	if err := stats.Mask([]string{"country"}, Masking{}); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+	if g := stats.CaseVariants["country"][1]; g[0].Value != redacted || g[0].Count != 2 {
+		t.Errorf("Expected masked values with their counts, got %v", g)
+	}
+}
+
+func TestTableAccumulator_CaseVariantsNotCategorical(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"name"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	acc.Add([]string{"ann"})
+	acc.Add([]string{"ANN"})
+	for i := 0; i < MaxCategories; i++ {
+		acc.Add([]string{"name" + string(rune('a'+i%26)) + string(rune('a'+i/26))})
+	}
+	if stats := acc.Finalize(); stats.CaseVariants != nil {
+		t.Errorf("Expected no case variants past MaxCategories values, got %v", stats.CaseVariants)
+	}
+}
@@ -0,0 +1,631 @@
+package tablestats
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// checkpointVersion is bumped whenever the saved state changes shape
+const checkpointVersion = 1
+
+// DefaultCheckpointInterval is the time between checkpoints when
+// SamplingConfig.CheckpointInterval is not set
+const DefaultCheckpointInterval = time.Minute
+
+// checkpoint is the state of a full read saved to disk: where reading got to
+// and the accumulator built from the rows before it
+type checkpoint struct {
+	Version int
+	Size    int64  // Size of the file
+	ModTime int64  // Modification time of the file, 0 when not known
+	Options string // Hash of the sampling config and dialect
+	Header  string // FileInfo.HeaderChecksum
+	Offset  int64  // File bytes consumed by the rows accumulated
+	Line    int    // Lines consumed by them
+	Rows    int    // Reader row counter, the header included
+	Ragged  RaggedRows
+	Errors  ParseErrorReport
+	Table   tableSnapshot
+}
+
+// tableSnapshot is the state of a TableAccumulator. Parts derived from the
+// header and config, such as compiled patterns, are not saved but rebuilt by
+// NewTableAccumulator before the snapshot is restored.
+type tableSnapshot struct {
+	Rows       int64
+	SampleData [][]string
+	Columns    []columnSnapshot
+	Keys       *keySnapshot
+	Candidates []candidateSnapshot
+	Groups     *groupSnapshot
+}
+
+type columnSnapshot struct {
+	Rows              int64
+	ValueBytes        int64
+	TextBytes         int64
+	CompressionSample []byte
+	NullCount         int64
+	EmptyCount        int64
+	NullTokens        int64
+	HasText           bool // MinText and MaxText hold the text range
+	MinText, MaxText  string
+	MinNum, MaxNum    float64
+	MinInt, MaxInt    int64
+	InexactInt        bool
+	HasRange          bool
+	IsNumeric         bool
+	IsFloat           bool
+	Weighted          bool
+	NonFinite         NonFiniteCounts
+	Decimal           DecimalShape
+	Unicode           UnicodeIssues
+	Padding           PaddingCounts
+	Fit               TypeFit
+	Numeric           *numericSnapshot
+	NumericValues     []float64
+	ValueWeights      []float64
+	Distinct          []string
+	Registers         []uint8 // HyperLogLog registers of a sketched column
+	HasValueCounts    bool    // Counts were not dropped
+	ValueCounts       map[string]int64
+	PIIMatches        []int
+	PIIChecked        int
+	Anomalies         *anomalySnapshot
+	Pattern           *Conformance
+}
+
+type numericSnapshot struct {
+	Count     int64
+	Sum       float64
+	SumC      float64
+	ExactSum  *big.Float
+	Weight    float64
+	Mean      float64
+	M2        float64
+	Quantiles []float64
+	Seen      int64
+	Digest    *digestSnapshot
+}
+
+type digestSnapshot struct {
+	Means, Weights             []float64 // Merged centroids
+	BufferMeans, BufferWeights []float64
+	Total, Min, Max            float64
+}
+
+type anomalySnapshot struct {
+	Outside   int64
+	Sentinels []int64
+	Values    int64
+}
+
+type keySnapshot struct {
+	Counts map[string]int64
+	Nulls  int64
+}
+
+type candidateSnapshot struct {
+	Combinations []string // Counted exactly so far
+	Registers    []uint8  // HyperLogLog registers once the combinations are sketched
+	Rows         int64
+	Nulls        int64
+}
+
+type groupSnapshot struct {
+	Groups      map[string]groupAccSnapshot
+	Null, Other *groupAccSnapshot
+}
+
+type groupAccSnapshot struct {
+	Rows    int64
+	Columns []*numericSnapshot
+}
+
+// snapshot returns the state of the accumulator
+func (t *TableAccumulator) snapshot() tableSnapshot {
+	s := tableSnapshot{Rows: t.rows, SampleData: t.sampleData}
+	for _, c := range t.columns {
+		s.Columns = append(s.Columns, c.snapshot())
+	}
+	if t.keys != nil {
+		s.Keys = &keySnapshot{Counts: t.keys.counts, Nulls: t.keys.nulls}
+	}
+	for _, k := range t.candidates {
+		c := candidateSnapshot{Rows: k.rows, Nulls: k.nulls}
+		for key := range k.exact {
+			c.Combinations = append(c.Combinations, key)
+		}
+		if k.sketch != nil {
+			c.Registers = k.sketch.registers[:]
+		}
+		s.Candidates = append(s.Candidates, c)
+	}
+	if t.groups != nil {
+		s.Groups = &groupSnapshot{Groups: make(map[string]groupAccSnapshot, len(t.groups.groups))}
+		for value, group := range t.groups.groups {
+			s.Groups.Groups[value] = group.snapshot()
+		}
+		if t.groups.null != nil {
+			null := t.groups.null.snapshot()
+			s.Groups.Null = &null
+		}
+		if t.groups.other != nil {
+			other := t.groups.other.snapshot()
+			s.Groups.Other = &other
+		}
+	}
+	return s
+}
+
+// restore sets the state of an accumulator created for the same header and
+// config as the one the snapshot was taken of
+func (t *TableAccumulator) restore(s tableSnapshot) error {
+	if len(s.Columns) != len(t.columns) {
+		return fmt.Errorf("checkpoint has %d columns, expected %d: %w", len(s.Columns), len(t.columns), ErrCheckpointMismatch)
+	}
+	t.rows = s.Rows
+	t.sampleData = s.SampleData
+	if t.sampleData == nil {
+		t.sampleData = make([][]string, 0)
+	}
+	for i, c := range t.columns {
+		c.restore(s.Columns[i])
+	}
+	if t.keys != nil && s.Keys != nil {
+		if s.Keys.Counts != nil {
+			t.keys.counts = s.Keys.Counts
+		}
+		t.keys.nulls = s.Keys.Nulls
+	}
+	for i, c := range s.Candidates {
+		if i >= len(t.candidates) {
+			break
+		}
+		k := t.candidates[i]
+		k.rows, k.nulls = c.Rows, c.Nulls
+		if c.Registers != nil {
+			k.sketch = newHyperLogLog()
+			copy(k.sketch.registers[:], c.Registers)
+			k.exact = nil
+		}
+		for _, key := range c.Combinations {
+			k.exact[key] = struct{}{}
+		}
+	}
+	if t.groups != nil && s.Groups != nil {
+		for value, group := range s.Groups.Groups {
+			t.groups.groups[value] = group.restore()
+		}
+		if s.Groups.Null != nil {
+			t.groups.null = s.Groups.Null.restore()
+		}
+		if s.Groups.Other != nil {
+			t.groups.other = s.Groups.Other.restore()
+		}
+	}
+	return nil
+}
+
+func (c *columnAccumulator) snapshot() columnSnapshot {
+	s := columnSnapshot{
+		Rows:              c.rows,
+		ValueBytes:        c.valueBytes,
+		TextBytes:         c.textBytes,
+		CompressionSample: c.compressionSample,
+		NullCount:         c.nullCount,
+		EmptyCount:        c.emptyCount,
+		NullTokens:        c.nullTokens,
+		MinNum:            c.minNum,
+		MaxNum:            c.maxNum,
+		MinInt:            c.minInt,
+		MaxInt:            c.maxInt,
+		InexactInt:        c.inexactInt,
+		HasRange:          c.hasRange,
+		IsNumeric:         c.isNumeric,
+		IsFloat:           c.isFloat,
+		Weighted:          c.weighted,
+		NonFinite:         c.nonFinite,
+		Decimal:           c.decimal,
+		Unicode:           c.unicode,
+		Padding:           c.padding,
+		Fit:               c.fit,
+		Numeric:           c.numeric.snapshot(),
+		NumericValues:     c.numericValues,
+		ValueWeights:      c.valueWeights,
+		HasValueCounts:    c.valueCounts != nil,
+	}
+	if c.minVal != nil {
+		s.HasText = true
+		s.MinText, s.MaxText = c.minVal.(string), c.maxVal.(string)
+	}
+	for value := range c.distinct {
+		s.Distinct = append(s.Distinct, value)
+	}
+	if c.distinctHLL != nil {
+		s.Registers = c.distinctHLL.registers[:]
+	}
+	if c.valueCounts != nil {
+		s.ValueCounts = make(map[string]int64, len(c.valueCounts))
+		for value, n := range c.valueCounts {
+			s.ValueCounts[value] = *n
+		}
+	}
+	if c.pii != nil {
+		s.PIIMatches, s.PIIChecked = c.pii.matches, c.pii.checked
+	}
+	if c.anomalies != nil {
+		s.Anomalies = &anomalySnapshot{Outside: c.anomalies.outside, Sentinels: c.anomalies.sentinels, Values: c.anomalies.values}
+	}
+	if c.pattern != nil {
+		s.Pattern = &c.pattern.result
+	}
+	return s
+}
+
+func (c *columnAccumulator) restore(s columnSnapshot) {
+	c.rows = s.Rows
+	c.valueBytes = s.ValueBytes
+	c.textBytes = s.TextBytes
+	c.compressionSample = s.CompressionSample
+	c.nullCount = s.NullCount
+	c.emptyCount = s.EmptyCount
+	c.nullTokens = s.NullTokens
+	if s.HasText {
+		c.minVal, c.maxVal = s.MinText, s.MaxText
+	}
+	c.minNum, c.maxNum = s.MinNum, s.MaxNum
+	c.minInt, c.maxInt = s.MinInt, s.MaxInt
+	c.inexactInt = s.InexactInt
+	c.hasRange = s.HasRange
+	c.isNumeric = s.IsNumeric
+	c.isFloat = s.IsFloat
+	c.weighted = s.Weighted
+	c.nonFinite = s.NonFinite
+	c.decimal = s.Decimal
+	c.unicode = s.Unicode
+	c.padding = s.Padding
+	c.fit = s.Fit
+	c.numeric = s.Numeric.restore()
+	c.numericValues = s.NumericValues
+	c.valueWeights = s.ValueWeights
+	if c.distinct != nil {
+		for _, value := range s.Distinct {
+			c.distinct[value] = struct{}{}
+		}
+	}
+	if c.distinctHLL != nil {
+		copy(c.distinctHLL.registers[:], s.Registers)
+	}
+	if !s.HasValueCounts {
+		c.valueCounts = nil
+	} else if c.valueCounts != nil {
+		for value, n := range s.ValueCounts {
+			c.valueCounts[value] = &n
+		}
+	}
+	if c.pii != nil {
+		copy(c.pii.matches, s.PIIMatches)
+		c.pii.checked = s.PIIChecked
+	}
+	if c.anomalies != nil && s.Anomalies != nil {
+		c.anomalies.outside = s.Anomalies.Outside
+		copy(c.anomalies.sentinels, s.Anomalies.Sentinels)
+		c.anomalies.values = s.Anomalies.Values
+	}
+	if c.pattern != nil && s.Pattern != nil {
+		c.pattern.result = *s.Pattern
+	}
+}
+
+func (n *numericSummary) snapshot() *numericSnapshot {
+	if n == nil {
+		return nil
+	}
+	s := &numericSnapshot{
+		Count:     n.count,
+		Sum:       n.sum.sum,
+		SumC:      n.sum.c,
+		ExactSum:  n.sum.exact,
+		Weight:    n.weight,
+		Mean:      n.mean,
+		M2:        n.m2,
+		Quantiles: n.quantiles.values,
+		Seen:      n.quantiles.seen,
+	}
+	if d := n.digest; d != nil {
+		s.Digest = &digestSnapshot{Total: d.total, Min: d.min, Max: d.max}
+		for _, c := range d.centroids {
+			s.Digest.Means = append(s.Digest.Means, c.mean)
+			s.Digest.Weights = append(s.Digest.Weights, c.weight)
+		}
+		for _, c := range d.buffer {
+			s.Digest.BufferMeans = append(s.Digest.BufferMeans, c.mean)
+			s.Digest.BufferWeights = append(s.Digest.BufferWeights, c.weight)
+		}
+	}
+	return s
+}
+
+func (s *numericSnapshot) restore() *numericSummary {
+	if s == nil {
+		return nil
+	}
+	n := &numericSummary{
+		count:     s.Count,
+		sum:       compensatedSum{sum: s.Sum, c: s.SumC, exact: s.ExactSum},
+		weight:    s.Weight,
+		mean:      s.Mean,
+		m2:        s.M2,
+		quantiles: quantileSketch{values: s.Quantiles, seen: s.Seen},
+	}
+	if s.Digest != nil {
+		d := newTDigest()
+		d.total, d.min, d.max = s.Digest.Total, s.Digest.Min, s.Digest.Max
+		for i, mean := range s.Digest.Means {
+			d.centroids = append(d.centroids, centroid{mean: mean, weight: s.Digest.Weights[i]})
+		}
+		for i, mean := range s.Digest.BufferMeans {
+			d.buffer = append(d.buffer, centroid{mean: mean, weight: s.Digest.BufferWeights[i]})
+		}
+		n.digest = d
+	}
+	return n
+}
+
+func (g *groupAccumulator) snapshot() groupAccSnapshot {
+	s := groupAccSnapshot{Rows: g.rows}
+	for _, n := range g.columns {
+		s.Columns = append(s.Columns, n.snapshot())
+	}
+	return s
+}
+
+func (s *groupAccSnapshot) restore() *groupAccumulator {
+	g := &groupAccumulator{rows: s.Rows}
+	for _, n := range s.Columns {
+		g.columns = append(g.columns, n.restore())
+	}
+	return g
+}
+
+// checkpointer saves the state of a full read of a file to config.Checkpoint
+// every config.CheckpointInterval, and when the read is cancelled
+type checkpointer struct {
+	path     string
+	interval time.Duration
+	file     io.ReaderAt
+	last     time.Time
+	state    checkpoint // Identity of the file and options, and the last position saved
+}
+
+// newCheckpointer prepares checkpoints of a full read of file. It returns
+// the saved checkpoint to resume from, or nil when there is none.
+func (r *CSVReader) newCheckpointer(file randomAccessReader, size int64, info *FileInfo, config SamplingConfig) (*checkpointer, *checkpoint, error) {
+	options, err := json.Marshal(struct {
+		Config SamplingConfig
+		Reader *CSVReader
+	}{config, r})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode checkpoint options: %w", err)
+	}
+	sum := sha256.Sum256(options)
+	cp := &checkpointer{
+		path:     config.Checkpoint,
+		interval: config.CheckpointInterval,
+		file:     file,
+		last:     time.Now(),
+		state: checkpoint{
+			Version: checkpointVersion,
+			Size:    size,
+			Options: hex.EncodeToString(sum[:16]),
+			Header:  info.HeaderChecksum,
+		},
+	}
+	if cp.interval <= 0 {
+		cp.interval = DefaultCheckpointInterval
+	}
+	if f, ok := file.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if fi, err := f.Stat(); err == nil {
+			cp.state.ModTime = fi.ModTime().UnixNano()
+		}
+	}
+
+	saved, err := loadCheckpoint(cp.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if saved.Version != cp.state.Version || saved.Size != cp.state.Size || saved.ModTime != cp.state.ModTime ||
+		saved.Options != cp.state.Options || saved.Header != cp.state.Header {
+		return nil, nil, fmt.Errorf("%s was saved for another file or other options, delete it to start over: %w", cp.path, ErrCheckpointMismatch)
+	}
+	cp.state.Offset, cp.state.Line = saved.Offset, saved.Line
+	return cp, saved, nil
+}
+
+// due reports whether a checkpoint should be saved
+func (cp *checkpointer) due() bool {
+	return time.Since(cp.last) >= cp.interval
+}
+
+// save writes the state of the read after offset bytes of the file, replacing
+// the previous checkpoint atomically
+func (cp *checkpointer) save(acc *TableAccumulator, offset int64, rows int, ragged *raggedCounter, errs *parseErrorCounter) error {
+	lines, err := countLineFeeds(cp.file, cp.state.Offset, offset)
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	state := cp.state
+	state.Offset, state.Line, state.Rows = offset, state.Line+lines, rows
+	state.Ragged, state.Errors = ragged.RaggedRows, errs.ParseErrorReport
+	state.Table = acc.snapshot()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&state); err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(cp.path), filepath.Base(cp.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cp.path); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	cp.state.Offset, cp.state.Line = state.Offset, state.Line
+	cp.last = time.Now()
+	return nil
+}
+
+// loadCheckpoint reads a checkpoint saved by checkpointer.save
+func loadCheckpoint(path string) (*checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state checkpoint
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// countLineFeeds counts the line feeds in the bytes of file from start to end
+func countLineFeeds(file io.ReaderAt, start, end int64) (int, error) {
+	buf := make([]byte, min(end-start, 1<<20))
+	lines := 0
+	for start < end {
+		n, err := file.ReadAt(buf[:min(int64(len(buf)), end-start)], start)
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		start += int64(n)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return lines, nil
+}
+
+// checkpointedRecords feeds every remaining record of the splitter or, when it
+// is nil, of csvReader into a new accumulator like splitRecords and
+// streamRecords, resuming from the checkpoint in config.Checkpoint when there
+// is one. file is the input the readers read from, which is positioned at the
+// checkpoint. The checkpoint is removed once every record is read.
+func (r *CSVReader) checkpointedRecords(ctx context.Context, file randomAccessReader, size int64, info *FileInfo, splitter *fieldSplitter, csvReader *recordReader, header []string, config SamplingConfig) (*TableAccumulator, error) {
+	acc, err := newTableAccumulator(ctx, header, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(acc.columns) > 0 && len(acc.columns[0].custom) > 0 {
+		return nil, fmt.Errorf("checkpoints cannot save the state of custom analyzers")
+	}
+	cp, saved, err := r.newCheckpointer(file, size, info, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var ragged *raggedCounter
+	var errs *parseErrorCounter
+	var progress *progressReporter
+	if splitter != nil {
+		ragged, errs, progress = splitter.ragged, splitter.errs, splitter.progress
+	} else {
+		ragged, errs, progress = csvReader.ragged, csvReader.errs, csvReader.progress
+	}
+	var base int64 // Offset the readers started from
+	if saved != nil {
+		if err := acc.restore(saved.Table); err != nil {
+			return nil, err
+		}
+		ragged.RaggedRows, errs.ParseErrorReport = saved.Ragged, saved.Errors
+		if _, err := file.Seek(saved.Offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		base = saved.Offset
+		if progress != nil {
+			progress.bytes, progress.rows = saved.Offset, saved.Table.Rows
+		}
+		input := &countingReader{r: file, p: progress}
+		if splitter != nil {
+			splitter.r.Reset(input)
+			splitter.offset, splitter.numLine, splitter.rows = 0, saved.Line, saved.Rows
+			if splitter.fieldsPerRecord == 0 {
+				splitter.fieldsPerRecord = len(header)
+			}
+		} else {
+			prev := csvReader
+			csvReader = r.newCSVReader(prev.ctx, input)
+			csvReader.progress, csvReader.ragged, csvReader.errs = prev.progress, prev.ragged, prev.errs
+			csvReader.tracked, csvReader.lineBase, csvReader.rows = true, saved.Line, saved.Rows
+		}
+	}
+	if csvReader != nil {
+		// The accumulator copies what it keeps, so the record slice can be reused
+		csvReader.ReuseRecord = true
+	}
+
+	position := func() (int64, int) {
+		if splitter != nil {
+			return base + splitter.offset, splitter.rows
+		}
+		return base + csvReader.InputOffset(), csvReader.rows
+	}
+	for n := 1; ; n++ {
+		if splitter != nil {
+			var fields [][]byte
+			if fields, err = splitter.Read(); err == nil {
+				acc.addFields(fields)
+			}
+		} else {
+			var record []string
+			if record, err = csvReader.Read(); err == nil {
+				acc.Add(record)
+			}
+		}
+		if err == io.EOF {
+			if err := os.Remove(cp.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to remove checkpoint: %w", err)
+			}
+			return acc, nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				// Keep what was read for the next run
+				offset, rows := position()
+				if err := cp.save(acc, offset, rows-1, ragged, errs); err != nil {
+					return nil, err
+				}
+			}
+			return nil, err
+		}
+		if n%cancelCheckInterval == 0 && cp.due() {
+			offset, rows := position()
+			if err := cp.save(acc, offset, rows, ragged, errs); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
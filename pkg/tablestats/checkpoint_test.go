@@ -0,0 +1,153 @@
+package tablestats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeCheckpointCSV writes rows of numbers, categories, nulls, text and a
+// few ragged and malformed rows
+func writeCheckpointCSV(t *testing.T, rows int) string {
+	var b strings.Builder
+	b.WriteString("id,region,amount,email,note\n")
+	for i := 1; i <= rows; i++ {
+		amount := fmt.Sprintf("%d.%02d", i%97, i%100)
+		if i%13 == 0 {
+			amount = ""
+		}
+		fmt.Fprintf(&b, "%d,r%d,%s,user%d@example.com,\"note %d\nsecond line\"\n", i, i%4, amount, i%50, i)
+		if i%2500 == 0 {
+			fmt.Fprintf(&b, "%d,short\n", -i)
+		}
+	}
+	return writeRawFile(t, "big.csv", []byte(b.String()))
+}
+
+func TestReadTable_Checkpoint(t *testing.T) {
+	tmpFile := writeCheckpointCSV(t, 10000)
+	readers := map[string]*CSVReader{
+		"split":  NewCSVReader(','),
+		"csv":    {Delimiter: ',', LazyQuotes: true},
+		"report": {Delimiter: ',', OnParseError: ErrorsReport},
+	}
+	for name, reader := range readers {
+		for _, sketch := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s sketch=%v", name, sketch), func(t *testing.T) {
+				config := DefaultSamplingConfig()
+				config.UniqueKey = []string{"id"}
+				config.KeyCandidates = [][]string{{"region", "email"}, {"id", "amount"}}
+				config.GroupBy = "region"
+				config.Patterns = map[string]string{"email": `^user\d+@example\.com$`}
+				config.DetectAnomalies = true
+				if sketch {
+					config.SampleSize = SketchSampleSize
+				}
+				expected, err := reader.ReadTable(context.Background(), tmpFile, config)
+				if err != nil {
+					t.Fatalf("ReadTable failed: %v", err)
+				}
+
+				// Interrupt the read a few thousand rows in
+				config.Checkpoint = filepath.Join(t.TempDir(), "scan.ckpt")
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				config.Progress = func(_, _, rows int64) {
+					if rows >= 3000 {
+						cancel()
+					}
+				}
+				if _, err := reader.ReadTable(ctx, tmpFile, config); !errors.Is(err, context.Canceled) {
+					t.Fatalf("Expected the read to be cancelled, got %v", err)
+				}
+				if _, err := os.Stat(config.Checkpoint); err != nil {
+					t.Fatalf("Expected a checkpoint, got %v", err)
+				}
+
+				config.Progress = nil
+				stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+				if err != nil {
+					t.Fatalf("Resumed ReadTable failed: %v", err)
+				}
+				if _, err := os.Stat(config.Checkpoint); !errors.Is(err, os.ErrNotExist) {
+					t.Errorf("Expected the checkpoint to be removed, got %v", err)
+				}
+
+				want, _ := json.Marshal(expected)
+				got, _ := json.Marshal(stats)
+				if string(got) != string(want) {
+					t.Errorf("Expected the resumed read to match an uninterrupted one\nwant %s\ngot  %s", want, got)
+				}
+			})
+		}
+	}
+}
+
+func TestReadTable_CheckpointInterval(t *testing.T) {
+	tmpFile := writeCheckpointCSV(t, 5000)
+	config := DefaultSamplingConfig()
+	config.Checkpoint = filepath.Join(t.TempDir(), "scan.ckpt")
+	config.CheckpointInterval = 1 // Save at every opportunity
+
+	// The checkpoint of a completed read is removed
+	var saved bool
+	config.Progress = func(_, _, rows int64) {
+		if _, err := os.Stat(config.Checkpoint); err == nil {
+			saved = true
+		}
+	}
+	if _, err := NewCSVReader(',').ReadTable(context.Background(), tmpFile, config); err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if !saved {
+		t.Error("Expected checkpoints to be saved during the read")
+	}
+	if _, err := os.Stat(config.Checkpoint); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the checkpoint to be removed, got %v", err)
+	}
+}
+
+func TestReadTable_CheckpointMismatch(t *testing.T) {
+	tmpFile := writeCheckpointCSV(t, 5000)
+	config := DefaultSamplingConfig()
+	config.Checkpoint = filepath.Join(t.TempDir(), "scan.ckpt")
+	ctx, cancel := context.WithCancel(context.Background())
+	config.Progress = func(_, _, rows int64) {
+		if rows >= 2000 {
+			cancel()
+		}
+	}
+	if _, err := NewCSVReader(',').ReadTable(ctx, tmpFile, config); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the read to be cancelled, got %v", err)
+	}
+	config.Progress = nil
+
+	// Other options
+	other := config
+	other.Columns = []string{"id"}
+	if _, err := NewCSVReader(',').ReadTable(context.Background(), tmpFile, other); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("Expected ErrCheckpointMismatch for other options, got %v", err)
+	}
+
+	// A changed file
+	file, err := os.OpenFile(tmpFile, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	file.WriteString("10001,r1,1.00,user1@example.com,x\n")
+	file.Close()
+	if _, err := NewCSVReader(',').ReadTable(context.Background(), tmpFile, config); !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("Expected ErrCheckpointMismatch for a changed file, got %v", err)
+	}
+
+	// Only utf-8 files are checkpointed
+	reader := &CSVReader{Delimiter: ',', Encoding: "latin1"}
+	if _, err := reader.ReadTable(context.Background(), tmpFile, config); err == nil {
+		t.Error("Expected error checkpointing a latin1 file")
+	}
+}
@@ -0,0 +1,332 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// scatterBins is the number of equal ranges each axis of a scatter grid has
+const scatterBins = 10
+
+// scatterShades draw the cells of a scatter grid, from the fewest to the most
+// pairs; empty cells are blank
+var scatterShades = []rune("░▒▓█")
+
+// ColumnComparison compares the distributions of two numeric columns of the
+// same table, such as a forecast and the actual values
+type ColumnComparison struct {
+	A       string          `json:"a"`
+	B       string          `json:"b"`
+	StatsA  *AggregateStats `json:"stats_a"` // Numbers of A, in the rows that have one
+	StatsB  *AggregateStats `json:"stats_b"`
+	Pairs   int64           `json:"pairs"`   // Rows holding numbers in both columns
+	Skipped int64           `json:"skipped"` // Rows with a null or non-numeric value in either column
+	// Difference summarizes B minus A over the pairs
+	Difference          *AggregateStats `json:"difference,omitempty"`
+	MeanAbsoluteError   float64         `json:"mean_absolute_error"`
+	RootMeanSquareError float64         `json:"root_mean_square_error"`
+	// Correlation is Pearson's r over the pairs, nil when either column is constant
+	Correlation *float64 `json:"correlation,omitempty"`
+	// KS is the two-sample Kolmogorov-Smirnov statistic of the numbers of A and
+	// B: the largest gap between their cumulative distributions
+	KS       float64  `json:"ks_statistic"`
+	KSPValue float64  `json:"ks_p_value"` // Asymptotic chance of a gap this large between samples of one distribution
+	Scatter  *Scatter `json:"scatter,omitempty"`
+}
+
+// Scatter summarizes the pairs of a ColumnComparison as counts on a grid
+type Scatter struct {
+	Above int64 `json:"above"` // Pairs where B is greater than A
+	Below int64 `json:"below"`
+	Equal int64 `json:"equal"`
+	// Grid[i][j] counts the pairs with A in the ith and B in the jth of
+	// scatterBins equal ranges from Low to High, shared by both axes
+	Low  float64   `json:"low"`
+	High float64   `json:"high"`
+	Grid [][]int64 `json:"grid"`
+}
+
+// CompareColumns compares numeric columns a and b of a sample. Sample weights
+// are not applied.
+func CompareColumns(sample *Sample, a, b string) (*ColumnComparison, error) {
+	idxA, idxB := -1, -1
+	for i, name := range sample.Header {
+		if name == a {
+			idxA = i
+		}
+		if name == b {
+			idxB = i
+		}
+	}
+	for _, c := range []struct {
+		name string
+		idx  int
+	}{{a, idxA}, {b, idxB}} {
+		if c.idx < 0 {
+			return nil, fmt.Errorf("column %q: %w", c.name, ErrUnknownColumn)
+		}
+	}
+
+	var valuesA, valuesB, pairsA, pairsB []float64
+	for row := 0; row < sample.Len(); row++ {
+		va, okA := fieldNumber(sample.Columns[idxA].Value(row))
+		vb, okB := fieldNumber(sample.Columns[idxB].Value(row))
+		if okA {
+			valuesA = append(valuesA, va)
+		}
+		if okB {
+			valuesB = append(valuesB, vb)
+		}
+		if okA && okB {
+			pairsA = append(pairsA, va)
+			pairsB = append(pairsB, vb)
+		}
+	}
+	for _, c := range []struct {
+		name   string
+		values []float64
+	}{{a, valuesA}, {b, valuesB}} {
+		if len(c.values) == 0 {
+			return nil, fmt.Errorf("column %q holds no numbers", c.name)
+		}
+	}
+
+	cmp := &ColumnComparison{
+		A:       a,
+		B:       b,
+		StatsA:  calculateAggregates(valuesA),
+		StatsB:  calculateAggregates(valuesB),
+		Pairs:   int64(len(pairsA)),
+		Skipped: int64(sample.Len() - len(pairsA)),
+	}
+	cmp.KS = ksStatistic(valuesA, valuesB)
+	cmp.KSPValue = ksPValue(cmp.KS, len(valuesA), len(valuesB))
+	if len(pairsA) == 0 {
+		return cmp, nil
+	}
+
+	diffs := make([]float64, len(pairsA))
+	var absSum, squareSum float64
+	for i := range pairsA {
+		d := pairsB[i] - pairsA[i]
+		diffs[i] = d
+		absSum += math.Abs(d)
+		squareSum += d * d
+	}
+	cmp.Difference = calculateAggregates(diffs)
+	cmp.MeanAbsoluteError = absSum / float64(len(diffs))
+	cmp.RootMeanSquareError = math.Sqrt(squareSum / float64(len(diffs)))
+	cmp.Correlation = correlation(pairsA, pairsB)
+	cmp.Scatter = scatter(pairsA, pairsB)
+	return cmp, nil
+}
+
+// fieldNumber parses a field as a finite number
+func fieldNumber(field string) (float64, bool) {
+	value := strings.TrimSpace(field)
+	if isNullValue(value) {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	return v, err == nil && isFinite(v)
+}
+
+// correlation returns Pearson's r of paired values, or nil when either side
+// does not vary
+func correlation(xs, ys []float64) *float64 {
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	r := max(-1, min(1, cov/math.Sqrt(varX*varY)))
+	return &r
+}
+
+// ksStatistic returns the largest gap between the empirical cumulative
+// distributions of two samples
+func ksStatistic(a, b []float64) float64 {
+	a, b = sortedCopy(a), sortedCopy(b)
+	var i, j int
+	var d float64
+	for i < len(a) && j < len(b) {
+		// Step past every copy of the smaller value on both sides
+		v := math.Min(a[i], b[j])
+		for i < len(a) && a[i] == v {
+			i++
+		}
+		for j < len(b) && b[j] == v {
+			j++
+		}
+		d = math.Max(d, math.Abs(float64(i)/float64(len(a))-float64(j)/float64(len(b))))
+	}
+	return d
+}
+
+func sortedCopy(values []float64) []float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+// ksPValue approximates the chance of a KS statistic of at least d between
+// samples of n and m values drawn from one distribution, with the asymptotic
+// Kolmogorov distribution
+func ksPValue(d float64, n, m int) float64 {
+	ne := float64(n) * float64(m) / float64(n+m)
+	lambda := (math.Sqrt(ne) + 0.12 + 0.11/math.Sqrt(ne)) * d
+	if lambda < 0.3 {
+		// The series converges slowly here, to 1 within 1e-5
+		return 1
+	}
+	p, sign := 0.0, 1.0
+	for k := 1.0; k <= 100; k++ {
+		term := sign * 2 * math.Exp(-2*k*k*lambda*lambda)
+		p += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+	return max(0, min(1, p))
+}
+
+// scatter counts paired values on a grid over their shared range
+func scatter(xs, ys []float64) *Scatter {
+	s := &Scatter{Low: math.Inf(1), High: math.Inf(-1), Grid: make([][]int64, scatterBins)}
+	for i := range xs {
+		s.Low = math.Min(s.Low, math.Min(xs[i], ys[i]))
+		s.High = math.Max(s.High, math.Max(xs[i], ys[i]))
+		switch {
+		case ys[i] > xs[i]:
+			s.Above++
+		case ys[i] < xs[i]:
+			s.Below++
+		default:
+			s.Equal++
+		}
+	}
+	for i := range s.Grid {
+		s.Grid[i] = make([]int64, scatterBins)
+	}
+	// Divided first so that ranges wider than the largest float64 do not overflow
+	width := s.High/scatterBins - s.Low/scatterBins
+	bin := func(v float64) int {
+		if width == 0 {
+			return 0
+		}
+		return max(0, min(scatterBins-1, int(v/width-s.Low/width)))
+	}
+	for i := range xs {
+		s.Grid[bin(xs[i])][bin(ys[i])]++
+	}
+	return s
+}
+
+// lines draws the grid with B rising upwards and A to the right, each cell
+// shaded by its share of the fullest cell
+func (s *Scatter) lines() []string {
+	var most int64
+	for _, column := range s.Grid {
+		for _, c := range column {
+			most = max(most, c)
+		}
+	}
+	lines := make([]string, 0, scatterBins)
+	for j := scatterBins - 1; j >= 0; j-- {
+		var b strings.Builder
+		for i := range scatterBins {
+			c := s.Grid[i][j]
+			shade := ' '
+			if c > 0 {
+				shade = scatterShades[(c*int64(len(scatterShades))-1)/most]
+			}
+			b.WriteRune(shade)
+			b.WriteRune(shade)
+		}
+		lines = append(lines, "|"+b.String()+"|")
+	}
+	return lines
+}
+
+// WriteText writes the comparison as a report, with numbers formatted by nf
+func (c *ColumnComparison) WriteText(w io.Writer, nf NumberFormat) error {
+	ew := &errWriter{w: w}
+	nw := nf.writer()
+	ew.printf("=== Columns %s and %s ===\n", c.A, c.B)
+	ew.printf("Pairs: %s rows with numbers in both (%s skipped)\n", nw.int(c.Pairs), nw.int(c.Skipped))
+
+	rows := [][]string{{"", c.A, c.B}}
+	for _, stat := range []struct {
+		name  string
+		value func(*AggregateStats) string
+	}{
+		{"Count", func(a *AggregateStats) string { return nw.int(a.Count) }},
+		{"Mean", func(a *AggregateStats) string { return nw.float(a.Mean) }},
+		{"Std Dev", func(a *AggregateStats) string { return nw.float(a.StdDev) }},
+		{"25th", func(a *AggregateStats) string { return nw.float(a.Percentiles[25]) }},
+		{"Median", func(a *AggregateStats) string { return nw.float(a.Percentiles[50]) }},
+		{"75th", func(a *AggregateStats) string { return nw.float(a.Percentiles[75]) }},
+	} {
+		rows = append(rows, []string{stat.name, stat.value(c.StatsA), stat.value(c.StatsB)})
+	}
+	widths := make([]int, 3)
+	for _, row := range rows {
+		for i, s := range row {
+			widths[i] = max(widths[i], len([]rune(s)))
+		}
+	}
+	ew.printf("\n")
+	for _, row := range rows {
+		ew.printf("  %s%s  %s  %s\n", row[0], strings.Repeat(" ", widths[0]-len([]rune(row[0]))),
+			padLeft(row[1], widths[1]), padLeft(row[2], widths[2]))
+	}
+
+	ew.printf("\nKS Statistic: %.4f (p = %.4f)\n", c.KS, c.KSPValue)
+	if c.Pairs == 0 {
+		ew.printf("\n")
+		return ew.err
+	}
+	if c.Correlation != nil {
+		ew.printf("Correlation: %.4f\n", *c.Correlation)
+	} else {
+		ew.printf("Correlation: undefined, a column is constant\n")
+	}
+
+	d := c.Difference
+	ew.printf("\nDifference (%s - %s):\n", c.B, c.A)
+	ew.printf("  Mean: %s\n", nw.float(d.Mean))
+	ew.printf("  Std Dev: %s\n", nw.float(d.StdDev))
+	ew.printf("  Percentiles: 25th=%s, 50th=%s, 75th=%s, 95th=%s\n",
+		nw.float(d.Percentiles[25]), nw.float(d.Percentiles[50]), nw.float(d.Percentiles[75]), nw.float(d.Percentiles[95]))
+	ew.printf("  Mean Absolute Error: %s\n", nw.float(c.MeanAbsoluteError))
+	ew.printf("  Root Mean Square Error: %s\n", nw.float(c.RootMeanSquareError))
+
+	s := c.Scatter
+	share := func(n int64) float64 { return float64(n) / float64(c.Pairs) * 100 }
+	ew.printf("\nScatter (%s upwards, %s to the right, both from %s to %s):\n", c.B, c.A, nw.float(s.Low), nw.float(s.High))
+	for _, line := range s.lines() {
+		ew.printf("  %s\n", line)
+	}
+	ew.printf("  %s above %s: %s (%.2f%%), below: %s (%.2f%%), equal: %s (%.2f%%)\n", c.B, c.A,
+		nw.int(s.Above), share(s.Above), nw.int(s.Below), share(s.Below), nw.int(s.Equal), share(s.Equal))
+	ew.printf("\n")
+	return ew.err
+}
@@ -0,0 +1,82 @@
+package tablestats
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCompareColumns(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header: []string{"forecast", "actual"},
+	}, [][]string{
+		{"1", "2"},
+		{"2", "3"},
+		{"3", "5"},
+		{"4", "4"},
+		{"5", ""},
+		{"n/a", "6"},
+	})
+	cmp, err := CompareColumns(sample, "forecast", "actual")
+	if err != nil {
+		t.Fatalf("CompareColumns failed: %v", err)
+	}
+	if cmp.Pairs != 4 || cmp.Skipped != 2 {
+		t.Errorf("Expected 4 pairs and 2 skipped rows, got %d and %d", cmp.Pairs, cmp.Skipped)
+	}
+	if cmp.StatsA.Count != 5 || cmp.StatsB.Count != 5 {
+		t.Errorf("Expected 5 numbers in each column, got %d and %d", cmp.StatsA.Count, cmp.StatsB.Count)
+	}
+	if cmp.Difference.Mean != 1 || cmp.MeanAbsoluteError != 1 {
+		t.Errorf("Expected a mean difference and error of 1, got %v and %v", cmp.Difference.Mean, cmp.MeanAbsoluteError)
+	}
+	if math.Abs(cmp.RootMeanSquareError-math.Sqrt(1.5)) > 1e-9 {
+		t.Errorf("Expected an RMSE of %v, got %v", math.Sqrt(1.5), cmp.RootMeanSquareError)
+	}
+	if cmp.Correlation == nil || math.Abs(*cmp.Correlation-0.8) > 1e-9 {
+		t.Errorf("Expected a correlation of 0.8, got %v", cmp.Correlation)
+	}
+	// 1..5 against 2..6: the cumulative distributions are at most 1/5 apart
+	if math.Abs(cmp.KS-0.2) > 1e-9 {
+		t.Errorf("Expected a KS statistic of 0.2, got %v", cmp.KS)
+	}
+	if s := cmp.Scatter; s.Above != 3 || s.Below != 0 || s.Equal != 1 {
+		t.Errorf("Expected 3 pairs above and 1 equal, got %+v", s)
+	}
+
+	var b strings.Builder
+	if err := cmp.WriteText(&b, NumberFormat{}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, expected := range []string{
+		"Pairs: 4 rows with numbers in both (2 skipped)\n",
+		"  Mean         3.00    4.00\n",
+		"Correlation: 0.8000\n",
+		"actual above forecast: 3 (75.00%), below: 0 (0.00%), equal: 1 (25.00%)\n",
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("Expected %q in the report, got:\n%s", expected, b.String())
+		}
+	}
+
+	if _, err := CompareColumns(sample, "forecast", "missing"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestKSStatistic(t *testing.T) {
+	same := []float64{1, 2, 2, 3}
+	if d := ksStatistic(same, []float64{3, 2, 1, 2}); d != 0 {
+		t.Errorf("Expected 0 for equal samples, got %v", d)
+	}
+	if d := ksStatistic([]float64{1, 2}, []float64{3, 4}); d != 1 {
+		t.Errorf("Expected 1 for disjoint samples, got %v", d)
+	}
+	if p := ksPValue(0, 100, 100); p != 1 {
+		t.Errorf("Expected a p-value of 1, got %v", p)
+	}
+	if p := ksPValue(0.5, 100, 100); p > 1e-4 {
+		t.Errorf("Expected a tiny p-value, got %v", p)
+	}
+}
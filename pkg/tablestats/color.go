@@ -0,0 +1,39 @@
+package tablestats
+
+// ANSI escapes the text report is colored with
+const (
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorGreen  = "\x1b[32m"
+	colorReset  = "\x1b[0m"
+)
+
+// HighNullPercentage is the share of nulls, in percent, from which the
+// colored text report shows a column in red
+const HighNullPercentage = 20.0
+
+// paint wraps s in color when the report is colored
+func (r *TextRenderer) paint(color, s string) string {
+	if !r.Color {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// columnColor returns red for a column with many nulls, yellow for one with
+// data quality warnings and green for a clean one
+func columnColor(stats *TableStats, name string) string {
+	switch {
+	case stats.NullPercentage[name] >= HighNullPercentage:
+		return colorRed
+	case columnWarned(stats, name):
+		return colorYellow
+	default:
+		return colorGreen
+	}
+}
+
+// columnWarned reports whether the text report warns about a column
+func columnWarned(stats *TableStats, name string) bool {
+	return len(columnWarnings(stats, name)) > 0
+}
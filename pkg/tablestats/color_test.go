@@ -0,0 +1,68 @@
+package tablestats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestColumnColor(t *testing.T) {
+	stats := &TableStats{
+		NullPercentage: map[string]float64{"sparse": 40, "padded": 5, "clean": 0},
+		Padding:        map[string]*PaddingCounts{"padded": {Leading: 1}, "sparse": {Trailing: 1}},
+	}
+	for name, expected := range map[string]string{"sparse": colorRed, "padded": colorYellow, "clean": colorGreen} {
+		if got := columnColor(stats, name); got != expected {
+			t.Errorf("Expected %q for %s, got %q", expected, name, got)
+		}
+	}
+}
+
+func TestRender_Color(t *testing.T) {
+	stats := &TableStats{
+		ColumnNames:    []string{"id", "note"},
+		ColumnTypes:    map[string]string{"id": "int64", "note": "string"},
+		NullCounts:     map[string]int64{"note": 3},
+		NullPercentage: map[string]float64{"note": 75},
+		Padding:        map[string]*PaddingCounts{"id": {Trailing: 2}},
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{Color: true, Wide: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, expected := range []string{
+		"  " + colorYellow + "id:" + colorReset + "\n",
+		"    " + colorYellow + "Padded: 0 with leading, 2 with trailing whitespace" + colorReset + "\n",
+		"  " + colorRed + "note:" + colorReset + "\n",
+		"    " + colorRed + "Null Count: 3 (75.00%)" + colorReset + "\n",
+	} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("Expected %q in the report, got:\n%s", expected, b.String())
+		}
+	}
+
+	b.Reset()
+	if err := (&TextRenderer{Wide: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Contains(b.String(), "\x1b[") {
+		t.Errorf("Expected no escapes without Color, got:\n%s", b.String())
+	}
+}
+
+func TestRender_ColorTable(t *testing.T) {
+	stats := &TableStats{
+		ColumnNames:    []string{"id", "note"},
+		ColumnTypes:    map[string]string{"id": "int64", "note": "string"},
+		NullCounts:     map[string]int64{"note": 3},
+		NullPercentage: map[string]float64{"note": 75},
+	}
+	var b strings.Builder
+	if err := (&TextRenderer{Color: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := "  " + colorRed + "note  " + colorReset + "  string  " + colorRed + "3 (75.00%)" + colorReset
+	if !strings.Contains(b.String(), expected) {
+		t.Errorf("Expected %q in the report, got:\n%s", expected, b.String())
+	}
+}
@@ -0,0 +1,272 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	columnHistogramBins = 50 // Bins of the histogram of a column profile
+	columnTopValues     = 50 // Most frequent values of a column profile
+	columnMasks         = 20 // Most frequent value masks of a column profile
+	outlierExamples     = 5  // Outliers shown on each side
+	outlierFence        = 1.5
+)
+
+// ColumnProfile describes a single column in more depth than TableStats: a
+// finer histogram, its most frequent values, outliers and the shapes its
+// values take
+type ColumnProfile struct {
+	Column    string         `json:"column"`
+	Stats     *TableStats    `json:"stats"`               // Profile of the column alone
+	Histogram []HistogramBin `json:"histogram,omitempty"` // For numeric columns
+	TopValues []ValueCount   `json:"top_values"`          // Most frequent non-null values in the sample
+	Outliers  *Outliers      `json:"outliers,omitempty"`  // For numeric columns
+	Masks     []ValueMask    `json:"masks"`               // Most frequent value shapes in the sample
+}
+
+// Outliers counts the values of a numeric column beyond Tukey's fences,
+// 1.5 interquartile ranges below the 25th or above the 75th percentile
+type Outliers struct {
+	LowFence  float64   `json:"low_fence"`
+	HighFence float64   `json:"high_fence"`
+	Below     int64     `json:"below"`
+	Above     int64     `json:"above"`
+	Lowest    []float64 `json:"lowest,omitempty"`  // Smallest distinct outliers below the low fence
+	Highest   []float64 `json:"highest,omitempty"` // Largest distinct outliers above the high fence
+}
+
+// ValueMask counts the values sharing a shape, in which upper and lower case
+// letters become A and a and digits become 9, e.g. "AA-9999" for "FR-2024"
+type ValueMask struct {
+	Mask    string `json:"mask"`
+	Count   int64  `json:"count"`
+	Example string `json:"example"`
+}
+
+// ProfileColumn profiles a single column of a sample in depth. config selects
+// how the column is profiled as in AnalyzeSample; its column selection is
+// replaced by column.
+func ProfileColumn(sample *Sample, column string, config SamplingConfig) (*ColumnProfile, error) {
+	idx := -1
+	for i, name := range sample.Header {
+		if name == column {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("column %q: %w", column, ErrUnknownColumn)
+	}
+
+	config.Columns, config.ExcludeColumns, config.UniqueKey, config.GroupBy = []string{column}, nil, nil, ""
+	config.SampleRows = -1
+	stats := AnalyzeSample(sample, config)
+	profile := &ColumnProfile{Column: column, Stats: stats}
+
+	counts := make(map[string]int64)
+	masks := make(map[string]*ValueMask)
+	agg, numeric := stats.Aggregates[column]
+	var values, weights []float64
+	fields := sample.Columns[idx]
+	for i := 0; i < fields.Len(); i++ {
+		value := strings.TrimSpace(fields.Value(i))
+		if isNullValue(value) {
+			continue
+		}
+		counts[value]++
+		mask := valueMask(value)
+		if m, ok := masks[mask]; ok {
+			m.Count++
+		} else {
+			masks[mask] = &ValueMask{Mask: mask, Count: 1, Example: value}
+		}
+		if numeric {
+			if v, err := strconv.ParseFloat(value, 64); err == nil && isFinite(v) {
+				values = append(values, v)
+				if sample.Weights != nil {
+					weights = append(weights, sample.Weights[i])
+				}
+			}
+		}
+	}
+
+	profile.TopValues = topValues(counts, columnTopValues)
+	for _, m := range masks {
+		profile.Masks = append(profile.Masks, *m)
+	}
+	sort.Slice(profile.Masks, func(i, j int) bool {
+		if profile.Masks[i].Count != profile.Masks[j].Count {
+			return profile.Masks[i].Count > profile.Masks[j].Count
+		}
+		return profile.Masks[i].Mask < profile.Masks[j].Mask
+	})
+	profile.Masks = profile.Masks[:min(len(profile.Masks), columnMasks)]
+
+	if numeric && len(values) > 0 {
+		profile.Histogram = binValues(values, weights, agg.Count, columnHistogramBins)
+		profile.Outliers = findOutliers(values, agg.Percentiles[25], agg.Percentiles[75])
+	}
+	return profile, nil
+}
+
+// topValues returns the n most frequent values, ties in value order
+func topValues(counts map[string]int64, n int) []ValueCount {
+	top := make([]ValueCount, 0, len(counts))
+	for v, c := range counts {
+		top = append(top, ValueCount{Value: v, Count: c})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Value < top[j].Value
+	})
+	return top[:min(len(top), n)]
+}
+
+// findOutliers counts the values beyond Tukey's fences around q1 and q3
+func findOutliers(values []float64, q1, q3 float64) *Outliers {
+	iqr := q3 - q1
+	o := &Outliers{LowFence: q1 - outlierFence*iqr, HighFence: q3 + outlierFence*iqr}
+	var below, above []float64
+	for _, v := range values {
+		if v < o.LowFence {
+			below = append(below, v)
+		} else if v > o.HighFence {
+			above = append(above, v)
+		}
+	}
+	o.Below, o.Above = int64(len(below)), int64(len(above))
+
+	sort.Float64s(below)
+	sort.Sort(sort.Reverse(sort.Float64Slice(above)))
+	o.Lowest = distinctPrefix(below, outlierExamples)
+	o.Highest = distinctPrefix(above, outlierExamples)
+	return o
+}
+
+// distinctPrefix returns the first n distinct values of sorted
+func distinctPrefix(sorted []float64, n int) []float64 {
+	var result []float64
+	for i, v := range sorted {
+		if len(result) == n {
+			break
+		}
+		if i == 0 || v != sorted[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// valueMask returns the shape of a value, e.g. "Aaaa 99" for "Gate 12"
+func valueMask(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsUpper(r):
+			return 'A'
+		case unicode.IsLetter(r):
+			return 'a'
+		case unicode.IsDigit(r):
+			return '9'
+		default:
+			return r
+		}
+	}, value)
+}
+
+// WriteText writes the profile as a report, with numbers formatted by nf
+func (p *ColumnProfile) WriteText(w io.Writer, nf NumberFormat) error {
+	ew := &errWriter{w: w}
+	nw := nf.writer()
+	stats := p.Stats
+	name := p.Column
+	ew.printf("=== Column %s ===\n", name)
+	ew.printf("Type: %s\n", typeLabel(stats, name))
+	ew.printf("Sampled Rows: %s of %s\n", nw.int(stats.RowCount), nw.int(stats.EstimatedRows))
+	ew.printf("Nulls: %s (%.2f%%)\n", nw.int(stats.NullCounts[name]), stats.NullPercentage[name])
+	ew.printf("Distinct: %s%s\n", nw.int(stats.DistinctCounts[name]), stats.Provenance.sketchNote(name, "distinct"))
+	ew.printf("Min: %s\n", valueText(stats.MinValues[name]))
+	ew.printf("Max: %s\n", valueText(stats.MaxValues[name]))
+	for _, warning := range columnWarnings(stats, name) {
+		ew.printf("%s\n", warning)
+	}
+
+	if agg, ok := stats.Aggregates[name]; ok {
+		ew.printf("Mean: %s\n", nw.float(agg.Mean))
+		ew.printf("Std Dev: %s\n", nw.float(agg.StdDev))
+		ew.printf("Percentiles: 25th=%s, 50th=%s, 75th=%s, 90th=%s, 95th=%s, 99th=%s\n",
+			nw.float(agg.Percentiles[25]), nw.float(agg.Percentiles[50]), nw.float(agg.Percentiles[75]),
+			nw.float(agg.Percentiles[90]), nw.float(agg.Percentiles[95]), nw.float(agg.Percentiles[99]))
+	}
+	if len(p.Histogram) > 1 {
+		ew.printf("\nHistogram:\n")
+		for _, line := range histogramLines(p.Histogram, nw) {
+			ew.printf("  %s\n", line)
+		}
+	}
+	if o := p.Outliers; o != nil {
+		ew.printf("\nOutliers (outside %s to %s): %s below, %s above\n",
+			nw.float(o.LowFence), nw.float(o.HighFence), nw.int(o.Below), nw.int(o.Above))
+		if len(o.Lowest) > 0 {
+			ew.printf("  Lowest: %s\n", joinFloats(o.Lowest))
+		}
+		if len(o.Highest) > 0 {
+			ew.printf("  Highest: %s\n", joinFloats(o.Highest))
+		}
+	}
+
+	nonNull := stats.RowCount - stats.NullCounts[name]
+	if len(p.TopValues) > 0 {
+		ew.printf("\nTop Values:\n")
+		for _, line := range countLines(p.TopValues, nonNull, nw) {
+			ew.printf("  %s\n", line)
+		}
+	}
+	if len(p.Masks) > 0 {
+		ew.printf("\nValue Masks:\n")
+		masks := make([]ValueCount, len(p.Masks))
+		for i, m := range p.Masks {
+			masks[i] = ValueCount{Value: m.Mask, Count: m.Count}
+		}
+		for i, line := range countLines(masks, nonNull, nw) {
+			ew.printf("  %s  e.g. %q\n", line, p.Masks[i].Example)
+		}
+	}
+	ew.printf("\n")
+	return ew.err
+}
+
+// countLines aligns values with their counts and share of total
+func countLines(counts []ValueCount, total int64, nw numberWriter) []string {
+	cells := make([]string, len(counts))
+	width, countWidth := 0, 0
+	for i, vc := range counts {
+		cells[i] = cell(vc.Value)
+		width = max(width, len([]rune(cells[i])))
+		countWidth = max(countWidth, len([]rune(nw.int(vc.Count))))
+	}
+	lines := make([]string, len(counts))
+	for i, vc := range counts {
+		share := 0.0
+		if total > 0 {
+			share = float64(vc.Count) / float64(total) * 100
+		}
+		lines[i] = fmt.Sprintf("%s%s  %s (%.2f%%)", cells[i], strings.Repeat(" ", width-len([]rune(cells[i]))),
+			padLeft(nw.int(vc.Count), countWidth), share)
+	}
+	return lines
+}
+
+func joinFloats(values []float64) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return strings.Join(parts, ", ")
+}
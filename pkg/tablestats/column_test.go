@@ -0,0 +1,76 @@
+package tablestats
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestProfileColumn(t *testing.T) {
+	sample := &Sample{Header: []string{"id", "amount", "code"}, EstimatedRows: 24, Exact: true}
+	var records [][]string
+	for i := 1; i <= 20; i++ {
+		records = append(records, []string{strconv.Itoa(i), strconv.Itoa(10 + i%5), "FR-" + strconv.Itoa(2000+i%2)})
+	}
+	records = append(records,
+		[]string{"21", "500", "de-1"}, []string{"22", "-300", "FR-2000"}, []string{"23", "", ""}, []string{"24", "501", "NULL"})
+	withRecords(sample, records)
+
+	profile, err := ProfileColumn(sample, "amount", SamplingConfig{})
+	if err != nil {
+		t.Fatalf("ProfileColumn failed: %v", err)
+	}
+	if !reflect.DeepEqual(profile.Stats.ColumnNames, []string{"amount"}) || profile.Stats.NullCounts["amount"] != 1 {
+		t.Errorf("Expected the amount column alone with 1 null, got %v and %d", profile.Stats.ColumnNames, profile.Stats.NullCounts["amount"])
+	}
+	if len(profile.Histogram) != columnHistogramBins {
+		t.Errorf("Expected %d bins, got %d", columnHistogramBins, len(profile.Histogram))
+	}
+	o := profile.Outliers
+	if o == nil || o.Below != 1 || o.Above != 2 {
+		t.Fatalf("Expected 1 outlier below and 2 above, got %+v", o)
+	}
+	if !reflect.DeepEqual(o.Lowest, []float64{-300}) || !reflect.DeepEqual(o.Highest, []float64{501, 500}) {
+		t.Errorf("Expected outliers -300 and 501, 500, got %v and %v", o.Lowest, o.Highest)
+	}
+	if top := profile.TopValues[0]; top.Count != 4 {
+		t.Errorf("Expected the most frequent amount 4 times, got %+v", top)
+	}
+
+	profile, err = ProfileColumn(sample, "code", SamplingConfig{})
+	if err != nil {
+		t.Fatalf("ProfileColumn failed: %v", err)
+	}
+	if profile.Histogram != nil || profile.Outliers != nil {
+		t.Errorf("Expected no histogram or outliers for a string column, got %+v", profile)
+	}
+	expected := []ValueMask{{Mask: "AA-9999", Count: 21, Example: "FR-2001"}, {Mask: "aa-9", Count: 1, Example: "de-1"}}
+	if !reflect.DeepEqual(profile.Masks, expected) {
+		t.Errorf("Expected masks %+v, got %+v", expected, profile.Masks)
+	}
+	if expected := []ValueCount{{"FR-2000", 11}, {"FR-2001", 10}, {"de-1", 1}}; !reflect.DeepEqual(profile.TopValues, expected) {
+		t.Errorf("Expected top values %+v, got %+v", expected, profile.TopValues)
+	}
+
+	var b strings.Builder
+	if err := profile.WriteText(&b, NumberFormat{}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "  AA-9999  21 (95.45%)  e.g. \"FR-2001\"\n") {
+		t.Errorf("Expected masks in the report, got:\n%s", b.String())
+	}
+
+	if _, err := ProfileColumn(sample, "missing", SamplingConfig{}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestValueMask(t *testing.T) {
+	for value, expected := range map[string]string{"Gate 12": "Aaaa 99", "a.b@c.io": "a.a@a.aa", "Ünïcode-٣": "Aaaaaaa-9"} {
+		if got := valueMask(value); got != expected {
+			t.Errorf("valueMask(%q): expected %q, got %q", value, expected, got)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// ColumnChange describes a column whose inferred type differs between two profiles
+type ColumnChange struct {
+	Column  string
+	OldType string
+	NewType string
+}
+
+// MetricDelta describes how a single metric moved between two profiles
+type MetricDelta struct {
+	Column string // Empty for table-level metrics
+	Metric string // rows, null_pct, mean or distinct
+	Old    float64
+	New    float64
+	Change float64 // Percent change, or percentage points for null_pct
+}
+
+// Comparison holds the differences between an old and a new profile
+type Comparison struct {
+	AddedColumns   []string
+	RemovedColumns []string
+	RetypedColumns []ColumnChange
+	Deltas         []MetricDelta
+	Shifts         []DistributionShift // Numeric columns whose profiles both hold quantiles
+}
+
+// CompareThresholds sets the maximum tolerated change per metric.
+// A zero threshold disables the check for that metric.
+type CompareThresholds struct {
+	RowCountPct       float64 // Max percent change in estimated rows
+	NullPctPoints     float64 // Max change in null percentage, in points
+	MeanPct           float64 // Max percent change in column means
+	DistinctPct       float64 // Max percent change in distinct counts
+	KSAlpha           float64 // Fail when a column's KS p-value falls below this
+	PSI               float64 // Max population stability index of a column
+	AllowSchemaChange bool    // Do not fail on added, removed or retyped columns
+}
+
+// Compare reports schema changes and metric deltas between two profiles
+func Compare(old, new *TableStats) *Comparison {
+	c := &Comparison{}
+
+	oldColumns := make(map[string]bool, len(old.ColumnNames))
+	for _, name := range old.ColumnNames {
+		oldColumns[name] = true
+	}
+	newColumns := make(map[string]bool, len(new.ColumnNames))
+	for _, name := range new.ColumnNames {
+		newColumns[name] = true
+		if !oldColumns[name] {
+			c.AddedColumns = append(c.AddedColumns, name)
+		}
+	}
+	for _, name := range old.ColumnNames {
+		if !newColumns[name] {
+			c.RemovedColumns = append(c.RemovedColumns, name)
+		}
+	}
+
+	c.Deltas = append(c.Deltas, relativeDelta("", "rows",
+		float64(old.EstimatedRows), float64(new.EstimatedRows)))
+
+	for _, name := range new.ColumnNames {
+		if !oldColumns[name] {
+			continue
+		}
+		if old.ColumnTypes[name] != new.ColumnTypes[name] {
+			c.RetypedColumns = append(c.RetypedColumns, ColumnChange{
+				Column:  name,
+				OldType: old.ColumnTypes[name],
+				NewType: new.ColumnTypes[name],
+			})
+		}
+
+		oldNull, newNull := old.NullPercentage[name], new.NullPercentage[name]
+		c.Deltas = append(c.Deltas, MetricDelta{
+			Column: name,
+			Metric: "null_pct",
+			Old:    oldNull,
+			New:    newNull,
+			Change: newNull - oldNull,
+		})
+
+		oldAgg, newAgg := old.Aggregates[name], new.Aggregates[name]
+		if oldAgg != nil && newAgg != nil {
+			c.Deltas = append(c.Deltas, relativeDelta(name, "mean", oldAgg.Mean, newAgg.Mean))
+		}
+		if shift, ok := compareDistributions(name, oldAgg, newAgg); ok {
+			c.Shifts = append(c.Shifts, shift)
+		}
+
+		c.Deltas = append(c.Deltas, relativeDelta(name, "distinct",
+			float64(old.DistinctCounts[name]), float64(new.DistinctCounts[name])))
+	}
+
+	return c
+}
+
+// Name returns the qualified metric name, e.g. "amount.mean"
+func (d MetricDelta) Name() string {
+	if d.Column == "" {
+		return d.Metric
+	}
+	return d.Column + "." + d.Metric
+}
+
+func relativeDelta(column, metric string, old, new float64) MetricDelta {
+	change := 0.0
+	if old != 0 {
+		change = (new - old) / math.Abs(old) * 100
+	} else if new != 0 {
+		change = math.Inf(1)
+	}
+	return MetricDelta{Column: column, Metric: metric, Old: old, New: new, Change: change}
+}
+
+// HasSchemaChanges reports whether columns were added, removed or retyped
+func (c *Comparison) HasSchemaChanges() bool {
+	return len(c.AddedColumns) > 0 || len(c.RemovedColumns) > 0 || len(c.RetypedColumns) > 0
+}
+
+// Violations returns the deltas that exceed their threshold
+func (c *Comparison) Violations(th CompareThresholds) []MetricDelta {
+	var violations []MetricDelta
+	for _, d := range c.Deltas {
+		limit := 0.0
+		switch d.Metric {
+		case "rows":
+			limit = th.RowCountPct
+		case "null_pct":
+			limit = th.NullPctPoints
+		case "mean":
+			limit = th.MeanPct
+		case "distinct":
+			limit = th.DistinctPct
+		}
+		if limit > 0 && math.Abs(d.Change) > limit {
+			violations = append(violations, d)
+		}
+	}
+	return violations
+}
+
+// ShiftViolations returns the distribution shifts that exceed a threshold
+func (c *Comparison) ShiftViolations(th CompareThresholds) []DistributionShift {
+	var violations []DistributionShift
+	for _, s := range c.Shifts {
+		if (th.KSAlpha > 0 && s.KSPValue < th.KSAlpha) || (th.PSI > 0 && s.PSI > th.PSI) {
+			violations = append(violations, s)
+		}
+	}
+	return violations
+}
+
+// Failures describes each schema change that is not allowed and each
+// threshold violation in one line, for notifications
+func (c *Comparison) Failures(th CompareThresholds) []string {
+	var lines []string
+	if !th.AllowSchemaChange {
+		for _, name := range c.AddedColumns {
+			lines = append(lines, fmt.Sprintf("%s: column added", name))
+		}
+		for _, name := range c.RemovedColumns {
+			lines = append(lines, fmt.Sprintf("%s: column removed", name))
+		}
+		for _, change := range c.RetypedColumns {
+			lines = append(lines, fmt.Sprintf("%s: type changed from %s to %s", change.Column, change.OldType, change.NewType))
+		}
+	}
+	for _, d := range c.Violations(th) {
+		lines = append(lines, fmt.Sprintf("%s changed by %+.2f", d.Name(), d.Change))
+	}
+	for _, shift := range c.ShiftViolations(th) {
+		lines = append(lines, fmt.Sprintf("%s distribution shifted: %s", shift.Column, shift.describe()))
+	}
+	return lines
+}
+
+// Failed reports whether the comparison breaks any of the thresholds
+func (c *Comparison) Failed(th CompareThresholds) bool {
+	if c.HasSchemaChanges() && !th.AllowSchemaChange {
+		return true
+	}
+	return len(c.Violations(th)) > 0 || len(c.ShiftViolations(th)) > 0
+}
+
+// WriteText writes the schema changes, metric deltas and distribution shifts,
+// then the changes that break th
+func (c *Comparison) WriteText(w io.Writer, th CompareThresholds) error {
+	ew := &errWriter{w: w}
+	ew.printf("=== Schema Changes ===\n")
+	if !c.HasSchemaChanges() {
+		ew.printf("  none\n")
+	}
+	for _, name := range c.AddedColumns {
+		ew.printf("  + %s\n", name)
+	}
+	for _, name := range c.RemovedColumns {
+		ew.printf("  - %s\n", name)
+	}
+	for _, change := range c.RetypedColumns {
+		ew.printf("  ~ %s: %s -> %s\n", change.Column, change.OldType, change.NewType)
+	}
+
+	ew.printf("\n=== Metric Deltas ===\n")
+	for _, d := range c.Deltas {
+		unit := "%"
+		if d.Metric == "null_pct" {
+			unit = " pts"
+		}
+		ew.printf("  %s: %.2f -> %.2f (%+.2f%s)\n", d.Name(), d.Old, d.New, d.Change, unit)
+	}
+
+	if len(c.Shifts) > 0 {
+		ew.printf("\n=== Distribution Shifts ===\n")
+		for _, shift := range c.Shifts {
+			ew.printf("  %s: %s\n", shift.Column, shift.describe())
+		}
+	}
+
+	violations := c.Violations(th)
+	shifts := c.ShiftViolations(th)
+	if len(violations) > 0 || len(shifts) > 0 {
+		ew.printf("\n=== Threshold Violations ===\n")
+		for _, d := range violations {
+			ew.printf("  %s changed by %+.2f\n", d.Name(), d.Change)
+		}
+		for _, shift := range shifts {
+			ew.printf("  %s distribution shifted: %s\n", shift.Column, shift.describe())
+		}
+	}
+	ew.printf("\n")
+	return ew.err
+}
@@ -0,0 +1,119 @@
+package tablestats
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	old := &TableStats{
+		EstimatedRows:  100,
+		ColumnNames:    []string{"id", "amount", "legacy"},
+		ColumnTypes:    map[string]string{"id": "int64", "amount": "int64", "legacy": "string"},
+		NullPercentage: map[string]float64{"id": 0, "amount": 2},
+		DistinctCounts: map[string]int64{"id": 100, "amount": 50},
+		Aggregates: map[string]*AggregateStats{
+			"amount": {Mean: 10},
+		},
+	}
+	new := &TableStats{
+		EstimatedRows:  120,
+		ColumnNames:    []string{"id", "amount", "status"},
+		ColumnTypes:    map[string]string{"id": "int64", "amount": "float64", "status": "string"},
+		NullPercentage: map[string]float64{"id": 0, "amount": 10},
+		DistinctCounts: map[string]int64{"id": 120, "amount": 50},
+		Aggregates: map[string]*AggregateStats{
+			"amount": {Mean: 12},
+		},
+	}
+
+	c := Compare(old, new)
+
+	if !reflect.DeepEqual(c.AddedColumns, []string{"status"}) {
+		t.Errorf("Expected added columns [status], got %v", c.AddedColumns)
+	}
+	if !reflect.DeepEqual(c.RemovedColumns, []string{"legacy"}) {
+		t.Errorf("Expected removed columns [legacy], got %v", c.RemovedColumns)
+	}
+	expectedRetyped := []ColumnChange{{Column: "amount", OldType: "int64", NewType: "float64"}}
+	if !reflect.DeepEqual(c.RetypedColumns, expectedRetyped) {
+		t.Errorf("Expected retyped columns %v, got %v", expectedRetyped, c.RetypedColumns)
+	}
+
+	deltas := make(map[string]float64)
+	for _, d := range c.Deltas {
+		deltas[d.Name()] = d.Change
+	}
+	expected := map[string]float64{
+		"rows":            20,
+		"id.null_pct":     0,
+		"id.distinct":     20,
+		"amount.null_pct": 8,
+		"amount.mean":     20,
+		"amount.distinct": 0,
+	}
+	if !reflect.DeepEqual(deltas, expected) {
+		t.Errorf("Expected deltas %v, got %v", expected, deltas)
+	}
+}
+
+func TestComparisonFailed(t *testing.T) {
+	c := &Comparison{
+		Deltas: []MetricDelta{
+			{Metric: "rows", Change: 20},
+			{Column: "amount", Metric: "null_pct", Change: -8},
+			{Column: "amount", Metric: "mean", Change: math.Inf(1)},
+		},
+	}
+
+	if c.Failed(CompareThresholds{}) {
+		t.Error("Expected no failure without thresholds")
+	}
+	if !c.Failed(CompareThresholds{NullPctPoints: 5}) {
+		t.Error("Expected failure when null change exceeds threshold")
+	}
+	if got := len(c.Violations(CompareThresholds{RowCountPct: 25, MeanPct: 50})); got != 1 {
+		t.Errorf("Expected 1 violation, got %d", got)
+	}
+
+	c.AddedColumns = []string{"status"}
+	if !c.Failed(CompareThresholds{}) {
+		t.Error("Expected failure on schema change")
+	}
+	if c.Failed(CompareThresholds{AllowSchemaChange: true}) {
+		t.Error("Expected schema change to be allowed")
+	}
+
+	failures := c.Failures(CompareThresholds{NullPctPoints: 5})
+	expected := []string{"status: column added", "amount.null_pct changed by -8.00"}
+	if !reflect.DeepEqual(failures, expected) {
+		t.Errorf("Expected failures %v, got %v", expected, failures)
+	}
+	if failures := c.Failures(CompareThresholds{AllowSchemaChange: true}); len(failures) != 0 {
+		t.Errorf("Expected no failures, got %v", failures)
+	}
+}
+
+func TestComparison_WriteText(t *testing.T) {
+	c := &Comparison{
+		AddedColumns: []string{"status"},
+		Deltas: []MetricDelta{
+			{Metric: "rows", Old: 100, New: 120, Change: 20},
+			{Column: "amount", Metric: "null_pct", Old: 2, New: 10, Change: 8},
+		},
+	}
+	var b strings.Builder
+	if err := c.WriteText(&b, CompareThresholds{NullPctPoints: 5}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	expected := "=== Schema Changes ===\n  + status\n\n" +
+		"=== Metric Deltas ===\n" +
+		"  rows: 100.00 -> 120.00 (+20.00%)\n" +
+		"  amount.null_pct: 2.00 -> 10.00 (+8.00 pts)\n\n" +
+		"=== Threshold Violations ===\n  amount.null_pct changed by +8.00\n\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
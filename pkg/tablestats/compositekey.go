@@ -0,0 +1,160 @@
+package tablestats
+
+import (
+	"fmt"
+	"strings"
+)
+
+// compositeExactLimit is the number of distinct combinations a key candidate
+// counts exactly before switching to a HyperLogLog sketch
+const compositeExactLimit = 100_000
+
+// compositeTolerance is the shortfall of distinct combinations, as a share of
+// the rows, that a sketched candidate may show and still count as unique. It
+// is about three standard errors of the sketch.
+const compositeTolerance = 0.025
+
+// KeyEstimate is the number of distinct value combinations of a column tuple,
+// for judging whether it could serve as a composite key. Combinations are
+// counted exactly up to 100,000 and estimated with a HyperLogLog sketch beyond,
+// so large files can be evaluated in fixed memory.
+type KeyEstimate struct {
+	Columns  []string `json:"columns"`
+	Rows     int64    `json:"rows"`      // Rows read with every column set
+	NullRows int64    `json:"null_rows"` // Rows with a null column, which are not counted
+	Distinct int64    `json:"distinct"`  // Distinct combinations among Rows
+	Sketched bool     `json:"sketched"`  // Distinct is an estimate
+	Exact    bool     `json:"exact"`     // Every row was read rather than a sample
+}
+
+// Uniqueness returns the share of the rows counted that hold a distinct
+// combination, between 0 and 1
+func (k *KeyEstimate) Uniqueness() float64 {
+	if k.Rows == 0 {
+		return 0
+	}
+	return min(float64(k.Distinct)/float64(k.Rows), 1)
+}
+
+// Unique reports whether no two rows read share a combination and none has a
+// null column; for a sketched count, within the error of the sketch
+func (k *KeyEstimate) Unique() bool {
+	if k.Rows == 0 || k.NullRows > 0 {
+		return false
+	}
+	if k.Sketched {
+		return k.Uniqueness() >= 1-compositeTolerance
+	}
+	return k.Distinct == k.Rows
+}
+
+// summary describes the estimate in one line
+func (k *KeyEstimate) summary() string {
+	verdict := "not unique"
+	if k.Unique() {
+		verdict = "unique"
+		if k.Sketched {
+			verdict = "likely unique"
+		}
+	}
+	distinct := fmt.Sprintf("%d", k.Distinct)
+	if k.Sketched {
+		distinct = "~" + distinct
+	}
+	mode := "sampled"
+	if k.Exact {
+		mode = "all"
+	}
+	line := fmt.Sprintf("%s, %s distinct in %d rows (%.1f%%, %s)", verdict, distinct, k.Rows, k.Uniqueness()*100, mode)
+	if k.NullRows > 0 {
+		line += fmt.Sprintf(", %d rows with nulls", k.NullRows)
+	}
+	return line
+}
+
+// candidateIndexes returns the header positions of the columns of each
+// KeyCandidates tuple
+func (c SamplingConfig) candidateIndexes(header []string) ([][]int, error) {
+	positions := make(map[string]int, len(header))
+	for i := len(header) - 1; i >= 0; i-- {
+		positions[header[i]] = i
+	}
+	result := make([][]int, len(c.KeyCandidates))
+	for i, columns := range c.KeyCandidates {
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("empty key candidate")
+		}
+		for _, name := range columns {
+			idx, ok := positions[name]
+			if !ok {
+				return nil, fmt.Errorf("key candidate column %q: %w", name, ErrUnknownColumn)
+			}
+			result[i] = append(result[i], idx)
+		}
+	}
+	return result, nil
+}
+
+// compositeCounter counts the distinct combinations of a column tuple: in a
+// set while there are few, then in a sketch
+type compositeCounter struct {
+	columns []string
+	indexes []int
+	exact   map[string]struct{} // nil once sketched
+	sketch  *hyperLogLog
+	rows    int64
+	nulls   int64
+	parts   []string
+}
+
+func newCompositeCounter(columns []string, indexes []int) *compositeCounter {
+	return &compositeCounter{
+		columns: columns,
+		indexes: indexes,
+		exact:   make(map[string]struct{}),
+		parts:   make([]string, len(indexes)),
+	}
+}
+
+func (k *compositeCounter) add(record []string) {
+	for i, idx := range k.indexes {
+		value := ""
+		if idx < len(record) {
+			value = strings.TrimSpace(record[idx])
+		}
+		if isNullValue(value) {
+			k.nulls++
+			return
+		}
+		k.parts[i] = value
+	}
+	k.rows++
+	key := strings.Join(k.parts, keySeparator)
+	if k.sketch != nil {
+		addDistinct(k.sketch, key)
+		return
+	}
+	k.exact[key] = struct{}{}
+	if len(k.exact) > compositeExactLimit {
+		k.sketch = newHyperLogLog()
+		for key := range k.exact {
+			addDistinct(k.sketch, key)
+		}
+		k.exact = nil
+	}
+}
+
+func (k *compositeCounter) result(exact bool) *KeyEstimate {
+	estimate := &KeyEstimate{
+		Columns:  k.columns,
+		Rows:     k.rows,
+		NullRows: k.nulls,
+		Distinct: int64(len(k.exact)),
+		Exact:    exact,
+	}
+	if k.sketch != nil {
+		estimate.Distinct = k.sketch.estimate()
+		estimate.Sketched = true
+	}
+	return estimate
+}
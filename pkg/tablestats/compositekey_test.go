@@ -0,0 +1,142 @@
+package tablestats
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTableAccumulator_KeyCandidates(t *testing.T) {
+	header := []string{"date", "store_id", "sku", "qty"}
+	records := [][]string{
+		{"2024-01-01", "1", "a", "3"},
+		{"2024-01-01", "2", "a", "1"},
+		{"2024-01-01", " 1 ", "b", "2"},
+		{"2024-01-02", "1", "a", "5"},
+		{"2024-01-02", "", "c", "1"},
+	}
+	config := SamplingConfig{
+		KeyCandidates: [][]string{{"date", "store_id"}, {"date", "store_id", "sku"}},
+		Columns:       []string{"qty"},
+	}
+
+	acc, err := NewTableAccumulator(header, config)
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range records {
+		acc.Add(record)
+	}
+	candidates := acc.finalize(int64(len(records))).KeyCandidates
+	if len(candidates) != 2 {
+		t.Fatalf("Expected 2 key candidates, got %d", len(candidates))
+	}
+
+	pair := candidates[0]
+	if pair.Rows != 4 || pair.NullRows != 1 || pair.Distinct != 3 || pair.Sketched || !pair.Exact {
+		t.Errorf("Unexpected estimate %+v", pair)
+	}
+	if pair.Unique() || pair.Uniqueness() != 0.75 {
+		t.Errorf("Expected a non-unique pair with uniqueness 0.75, got %v", pair.Uniqueness())
+	}
+	triple := candidates[1]
+	if triple.Distinct != 4 || triple.Unique() {
+		t.Errorf("Expected 4 distinct combinations, not unique due to the null, got %+v", triple)
+	}
+
+	if _, err := NewTableAccumulator(header, SamplingConfig{KeyCandidates: [][]string{{"date", "store"}}}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestCompositeCounter_Sketch(t *testing.T) {
+	const rows = compositeExactLimit * 3
+	unique := newCompositeCounter([]string{"a", "b"}, []int{0, 1})
+	repeated := newCompositeCounter([]string{"a"}, []int{0})
+	for i := 0; i < rows; i++ {
+		record := []string{fmt.Sprint(i / 2), fmt.Sprint(i % 2)}
+		unique.add(record)
+		repeated.add(record)
+	}
+
+	key := unique.result(true)
+	if !key.Sketched || unique.exact != nil {
+		t.Fatalf("Expected the counter to switch to a sketch, got %+v", key)
+	}
+	if !key.Unique() {
+		t.Errorf("Expected a likely unique key, got %d distinct in %d rows", key.Distinct, key.Rows)
+	}
+	if !strings.HasPrefix(key.summary(), "likely unique, ~") {
+		t.Errorf("Expected the summary to mark the estimate, got %q", key.summary())
+	}
+
+	half := repeated.result(true)
+	if half.Unique() || half.Uniqueness() < 0.47 || half.Uniqueness() > 0.53 {
+		t.Errorf("Expected about half the rows to be distinct, got %d in %d", half.Distinct, half.Rows)
+	}
+}
+
+func TestAnalyzeSample_KeyCandidates(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header:        []string{"id", "region"},
+		EstimatedRows: 30,
+	}, [][]string{{"1", "a"}, {"2", "a"}, {"3", "b"}})
+	// Candidates with unknown columns are dropped
+	config := SamplingConfig{KeyCandidates: [][]string{{"id"}, {"id", "missing"}}}
+	stats := AnalyzeSample(sample, config)
+	if len(stats.KeyCandidates) != 1 {
+		t.Fatalf("Expected 1 key candidate, got %d", len(stats.KeyCandidates))
+	}
+	if k := stats.KeyCandidates[0]; !k.Unique() || k.Exact {
+		t.Errorf("Expected a unique sampled candidate, got %+v", k)
+	}
+
+	var buf bytes.Buffer
+	if err := (&TextRenderer{}).Render(&buf, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "(id): unique, 3 distinct in 3 rows (100.0%, sampled)") {
+		t.Errorf("Expected the key candidate in the output, got:\n%s", buf.String())
+	}
+}
+
+func TestReadTable_KeyCandidatesOfUnprofiledColumns(t *testing.T) {
+	var data strings.Builder
+	data.WriteString("a,b,c\n")
+	for i := 0; i < 2000; i++ {
+		fmt.Fprintf(&data, "%d,%d,%d\n", i%7, i/10, i%10)
+	}
+	path := writeRawFile(t, "keys.csv", []byte(data.String()))
+
+	reader := NewCSVReader(',')
+	for _, maxSize := range []int64{1024 * 1024, 1000} {
+		// The candidate columns are read even though only a is profiled
+		config := SamplingConfig{
+			MaxFileSize:     maxSize,
+			SampleSize:      500,
+			RandomPositions: 5,
+			Columns:         []string{"a"},
+			KeyCandidates:   [][]string{{"b", "c"}},
+		}
+		stats, err := reader.ReadTable(context.Background(), path, config)
+		if err != nil {
+			t.Fatalf("ReadTable failed: %v", err)
+		}
+		if len(stats.KeyCandidates) != 1 {
+			t.Fatalf("Expected 1 key candidate with max size %d, got %d", maxSize, len(stats.KeyCandidates))
+		}
+		// Sampled rows may repeat, so only a full read is unique
+		k := stats.KeyCandidates[0]
+		if k.Rows == 0 || k.NullRows != 0 || k.Exact != (maxSize > 1000) || k.Exact && !k.Unique() {
+			t.Errorf("Expected the candidate to be counted without nulls with max size %d, got %+v", maxSize, k)
+		}
+	}
+
+	config := SamplingConfig{MaxFileSize: 1000, Columns: []string{"a"}, KeyCandidates: [][]string{{"b", "d"}}}
+	if _, err := reader.ReadTable(context.Background(), path, config); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
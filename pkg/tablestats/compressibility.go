@@ -0,0 +1,153 @@
+package tablestats
+
+import (
+	"compress/flate"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Each column keeps its first compressionSampleBytes of non-null values,
+// which are compressed in blocks of compressionBlockBytes like the pages of
+// columnar formats
+const (
+	compressionBlockBytes  = 64 << 10
+	compressionSampleBytes = 4 * compressionBlockBytes
+)
+
+// heaviestColumns is the number of columns with the most compressed bytes
+// the text and markdown reports list
+const heaviestColumns = 5
+
+// Compressibility estimates how well a column's values compress with DEFLATE at
+// its fastest level, which is close to the Snappy or LZ4 of columnar formats
+type Compressibility struct {
+	SampledBytes    int64   `json:"sampled_bytes"`    // Bytes of the values compressed, one per line
+	CompressedBytes int64   `json:"compressed_bytes"` // Bytes they compressed to
+	Ratio           float64 `json:"ratio"`            // SampledBytes / CompressedBytes
+	EstimatedBytes  int64   `json:"estimated_bytes"`  // Compressed bytes of all values over the estimated total rows
+}
+
+// observeCompression appends a trimmed non-null value to the column's
+// compression sample until it is full
+func observeCompression[T fieldValue](c *columnAccumulator, value T) {
+	if len(c.compressionSample) >= compressionSampleBytes {
+		return
+	}
+	c.compressionSample = append(c.compressionSample, value...)
+	c.compressionSample = append(c.compressionSample, '\n')
+}
+
+// compressibility compresses the column's sample with fw, reset for each
+// block, and extrapolates its ratio to the column's values over
+// estimatedRows. It returns nil for columns without values.
+func (c *columnAccumulator) compressibility(fw *flate.Writer, estimatedRows int64) *Compressibility {
+	sample := c.compressionSample
+	c.compressionSample = nil
+	if len(sample) == 0 {
+		return nil
+	}
+	counter := &byteCounter{}
+	for start := 0; start < len(sample); start += compressionBlockBytes {
+		fw.Reset(counter)
+		fw.Write(sample[start:min(start+compressionBlockBytes, len(sample))])
+		fw.Close()
+	}
+	comp := &Compressibility{
+		SampledBytes:    int64(len(sample)),
+		CompressedBytes: counter.n,
+		Ratio:           float64(len(sample)) / float64(counter.n),
+	}
+	// Values are sampled with a separator each, as the offsets of a string
+	// column or the fixed width of a number column also take space
+	perRow := float64(c.textBytes+c.rows-c.nullCount) / float64(c.rows)
+	comp.EstimatedBytes = int64(math.Ceil(perRow * float64(estimatedRows) / comp.Ratio))
+	return comp
+}
+
+// byteCounter is a writer that only counts the bytes written to it
+type byteCounter struct {
+	n int64
+}
+
+func (b *byteCounter) Write(p []byte) (int, error) {
+	b.n += int64(len(p))
+	return len(p), nil
+}
+
+// newCompressor returns the DEFLATE writer the columns of a table are
+// compressed with
+func newCompressor() *flate.Writer {
+	fw, _ := flate.NewWriter(io.Discard, flate.BestSpeed)
+	return fw
+}
+
+// heaviest returns the columns with the most estimated compressed bytes,
+// most first
+func heaviest(stats *TableStats, n int) []string {
+	var names []string
+	for _, name := range stats.ColumnNames {
+		if stats.Compression[name] != nil {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return stats.Compression[names[i]].EstimatedBytes > stats.Compression[names[j]].EstimatedBytes
+	})
+	return names[:min(n, len(names))]
+}
+
+// heaviestRows returns a row per column of the heaviest, with its estimated
+// compressed size, its share of all compressed bytes and its ratio
+func heaviestRows(stats *TableStats, nw numberWriter) [][]string {
+	var total int64
+	for _, comp := range stats.Compression {
+		total += comp.EstimatedBytes
+	}
+	var rows [][]string
+	for _, name := range heaviest(stats, heaviestColumns) {
+		comp := stats.Compression[name]
+		rows = append(rows, []string{
+			cell(name),
+			nw.bytes(comp.EstimatedBytes),
+			fmt.Sprintf("%.1f%%", float64(comp.EstimatedBytes)/float64(total)*100),
+			fmt.Sprintf("%.1fx", comp.Ratio),
+		})
+	}
+	return rows
+}
+
+// renderHeaviest prints the columns that take the most space compressed
+func (r *TextRenderer) renderHeaviest(ew *errWriter, nw numberWriter, stats *TableStats) {
+	rows := heaviestRows(stats, nw)
+	if len(rows) == 0 {
+		return
+	}
+	ew.printf("\nHeaviest Columns (compressed):\n")
+	rows = append([][]string{{"Column", "Size", "Share", "Ratio"}}, rows...)
+	for _, line := range alignRows(rows) {
+		ew.printf("  %s\n", line)
+	}
+}
+
+// renderHeaviest writes the columns that take the most space compressed as a
+// table
+func (r *MarkdownRenderer) renderHeaviest(ew *errWriter, nw numberWriter, stats *TableStats) {
+	rows := heaviestRows(stats, nw)
+	if len(rows) == 0 {
+		return
+	}
+	ew.printf("\nHeaviest columns (compressed):\n\n")
+	ew.printf("| Column | Size | Share | Ratio |\n")
+	ew.printf("|---|---:|---:|---:|\n")
+	for _, row := range rows {
+		ew.printf("| %s | %s | %s | %s |\n", markdownCell(row[0]), row[1], row[2], row[3])
+	}
+}
+
+// summary describes a column's compressibility, e.g.
+// "3.4x, 1.2 MB compressed"
+func (c *Compressibility) summary(nw numberWriter) string {
+	return fmt.Sprintf("%.1fx, %s compressed", c.Ratio, nw.bytes(c.EstimatedBytes))
+}
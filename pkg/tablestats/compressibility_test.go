@@ -0,0 +1,62 @@
+package tablestats
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestTableStats_Compression(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sample := &Sample{Header: []string{"status", "token", "empty"}, EstimatedRows: 5000}
+	var records [][]string
+	for range 5000 {
+		records = append(records, []string{"active", fmt.Sprintf("%016x", rng.Uint64()), ""})
+	}
+	withRecords(sample, records)
+	stats := AnalyzeSample(sample, DefaultSamplingConfig())
+
+	status, token := stats.Compression["status"], stats.Compression["token"]
+	if status == nil || token == nil {
+		t.Fatalf("Expected the compressibility of both columns, got %+v", stats.Compression)
+	}
+	if status.Ratio < 50 {
+		t.Errorf("Expected a constant column to compress well, got a ratio of %.1f", status.Ratio)
+	}
+	if token.Ratio > 3 {
+		t.Errorf("Expected random hex to compress poorly, got a ratio of %.1f", token.Ratio)
+	}
+	if status.SampledBytes != int64(5000*len("active\n")) {
+		t.Errorf("Expected %d sampled bytes, got %d", 5000*len("active\n"), status.SampledBytes)
+	}
+	if _, ok := stats.Compression["empty"]; ok {
+		t.Error("Expected no compressibility of a column without values")
+	}
+	if got := heaviest(stats, 5); len(got) != 2 || got[0] != "token" {
+		t.Errorf("Expected token to be the heaviest column, got %v", got)
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "Heaviest Columns (compressed):\n  Column  Size") {
+		t.Errorf("Expected the heaviest columns in the report, got:\n%s", b.String())
+	}
+}
+
+func TestTableStats_CompressionSampleBounded(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"text"}, DefaultSamplingConfig())
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	value := strings.Repeat("x", 1000)
+	for range 1000 {
+		acc.Add([]string{value})
+	}
+	comp := acc.Finalize().Compression["text"]
+	if comp.SampledBytes > compressionSampleBytes+int64(len(value))+1 {
+		t.Errorf("Expected at most about %d sampled bytes, got %d", compressionSampleBytes, comp.SampledBytes)
+	}
+}
@@ -0,0 +1,83 @@
+package tablestats
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Conformance is the share of a column's non-null values that match the
+// pattern given for it in SamplingConfig.Patterns
+type Conformance struct {
+	Pattern  string   `json:"pattern"`
+	Checked  int64    `json:"checked"` // Non-null values checked
+	Matched  int64    `json:"matched"`
+	Examples []string `json:"examples,omitempty"` // Distinct values that do not match
+}
+
+// MatchPct returns matching values as a percentage of checked values
+func (c *Conformance) MatchPct() float64 {
+	if c.Checked == 0 {
+		return 100
+	}
+	return float64(c.Matched) / float64(c.Checked) * 100
+}
+
+// summary describes the match rate, e.g. "Pattern: 97.50% of 400 values match ^\d+$"
+func (c *Conformance) summary() string {
+	return fmt.Sprintf("Pattern: %.2f%% of %d values match %s", c.MatchPct(), c.Checked, c.Pattern)
+}
+
+// compilePatterns compiles the Patterns of the columns at indexes in header
+func (c SamplingConfig) compilePatterns(header []string, indexes []int) (map[int]*regexp.Regexp, error) {
+	if len(c.Patterns) == 0 {
+		return nil, nil
+	}
+	patterns := make(map[int]*regexp.Regexp, len(c.Patterns))
+	for i, idx := range indexes {
+		expr, ok := c.Patterns[header[idx]]
+		if !ok {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for column %q: %w", header[idx], err)
+		}
+		patterns[i] = re
+	}
+	return patterns, nil
+}
+
+// patternCheck counts the values of a column that match its pattern
+type patternCheck struct {
+	re     *regexp.Regexp
+	result Conformance
+}
+
+func newPatternCheck(re *regexp.Regexp) *patternCheck {
+	return &patternCheck{re: re, result: Conformance{Pattern: re.String()}}
+}
+
+// observePattern checks a trimmed non-null value
+func observePattern[T fieldValue](p *patternCheck, value T) {
+	p.result.Checked++
+	var matched bool
+	switch v := any(value).(type) {
+	case string:
+		matched = p.re.MatchString(v)
+	case []byte:
+		matched = p.re.Match(v)
+	}
+	if matched {
+		p.result.Matched++
+		return
+	}
+	if len(p.result.Examples) >= maxViolationExamples {
+		return
+	}
+	for _, ex := range p.result.Examples {
+		if ex == string(value) {
+			return
+		}
+	}
+	p.result.Examples = append(p.result.Examples, string(value))
+}
@@ -0,0 +1,66 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTable_Patterns(t *testing.T) {
+	data := "sku,qty\nSKU-000001,1\nSKU-000002,2\nsku-3,3\n,4\nSKU-12,5\nsku-3,6\n"
+	config := DefaultSamplingConfig()
+	config.Patterns = map[string]string{"sku": `^SKU-\d{6}$`}
+
+	stats, err := NewCSVReader(',').ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadTableFrom failed: %v", err)
+	}
+
+	// The null value is not checked and the repeated mismatch is listed once
+	c := stats.Conformance["sku"]
+	if c == nil {
+		t.Fatalf("Expected conformance for sku, got %v", stats.Conformance)
+	}
+	if c.Checked != 5 || c.Matched != 2 {
+		t.Errorf("Expected 2 of 5 values to match, got %d of %d", c.Matched, c.Checked)
+	}
+	if !floatEqual(c.MatchPct(), 40) {
+		t.Errorf("Expected 40%% to match, got %.2f", c.MatchPct())
+	}
+	if expected := []string{"sku-3", "SKU-12"}; !reflect.DeepEqual(c.Examples, expected) {
+		t.Errorf("Expected examples %v, got %v", expected, c.Examples)
+	}
+	if _, ok := stats.Conformance["qty"]; ok {
+		t.Errorf("Expected no conformance for a column without a pattern")
+	}
+}
+
+func TestNewTableAccumulator_PatternErrors(t *testing.T) {
+	header := []string{"sku"}
+
+	if _, err := NewTableAccumulator(header, SamplingConfig{Patterns: map[string]string{"code": "."}}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+	if _, err := NewTableAccumulator(header, SamplingConfig{Patterns: map[string]string{"sku": "("}}); err == nil {
+		t.Errorf("Expected an error for an invalid pattern")
+	}
+}
+
+func TestTableStats_MaskConformance(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header: []string{"email"},
+	}, [][]string{{"ada@example.com"}, {"not an email"}}), SamplingConfig{Patterns: map[string]string{"email": "@"}})
+	original := stats.Conformance["email"]
+
+	if err := stats.Mask([]string{"email"}, Masking{}); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+	if got := stats.Conformance["email"].Examples; !reflect.DeepEqual(got, []string{redacted}) {
+		t.Errorf("Expected the example to be masked, got %v", got)
+	}
+	if !reflect.DeepEqual(original.Examples, []string{"not an email"}) {
+		t.Errorf("Expected the unmasked result to be left alone, got %v", original.Examples)
+	}
+}
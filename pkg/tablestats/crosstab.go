@@ -0,0 +1,214 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxCrossTabValues is the largest number of values of each column a
+// cross-tabulation lists apart; rows with further values are counted together
+// under "(other)"
+const MaxCrossTabValues = 20
+
+// Labels of the null values and of the values past MaxCrossTabValues in a
+// cross-tabulation
+const (
+	crossTabNull  = "(null)"
+	crossTabOther = "(other)"
+)
+
+// CrossTab counts the rows of each combination of values of two categorical
+// columns
+type CrossTab struct {
+	Rows      string    `json:"rows"`       // Column whose values label the rows of the table
+	Cols      string    `json:"cols"`       // Column whose values label the columns of the table
+	RowValues []string  `json:"row_values"` // Most rows first, then "(other)" and "(null)"
+	ColValues []string  `json:"col_values"`
+	Counts    [][]int64 `json:"counts"` // Counts[i][j] is the number of rows with RowValues[i] and ColValues[j]
+	RowTotals []int64   `json:"row_totals"`
+	ColTotals []int64   `json:"col_totals"`
+	Total     int64     `json:"total"`
+}
+
+// CrossTabPercent selects what the percentages of a cross-tabulation are
+// shares of
+type CrossTabPercent int
+
+const (
+	// PercentOfTotal divides each count by the number of rows
+	PercentOfTotal CrossTabPercent = iota
+	// PercentOfRow divides each count by the total of its row
+	PercentOfRow
+	// PercentOfColumn divides each count by the total of its column
+	PercentOfColumn
+)
+
+// ParseCrossTabPercent converts "total", "row" or "col" into a CrossTabPercent
+func ParseCrossTabPercent(name string) (CrossTabPercent, error) {
+	switch name {
+	case "", "total":
+		return PercentOfTotal, nil
+	case "row":
+		return PercentOfRow, nil
+	case "col", "column":
+		return PercentOfColumn, nil
+	default:
+		return 0, fmt.Errorf("unsupported percentage %q (use total, row or col)", name)
+	}
+}
+
+// CrossTabulate counts the rows of a sample by the values of columns rows and
+// cols. Sample weights are not applied.
+func CrossTabulate(sample *Sample, rows, cols string) (*CrossTab, error) {
+	idxRows, idxCols := -1, -1
+	for i, name := range sample.Header {
+		if name == rows {
+			idxRows = i
+		}
+		if name == cols {
+			idxCols = i
+		}
+	}
+	for _, c := range []struct {
+		name string
+		idx  int
+	}{{rows, idxRows}, {cols, idxCols}} {
+		if c.idx < 0 {
+			return nil, fmt.Errorf("column %q: %w", c.name, ErrUnknownColumn)
+		}
+	}
+
+	label := func(row, idx int) (string, bool) {
+		value := strings.TrimSpace(sample.Columns[idx].Value(row))
+		if isNullValue(value) {
+			return crossTabNull, true
+		}
+		return value, false
+	}
+	rowCounts := make(map[string]int64)
+	colCounts := make(map[string]int64)
+	for row := 0; row < sample.Len(); row++ {
+		if v, null := label(row, idxRows); !null {
+			rowCounts[v]++
+		}
+		if v, null := label(row, idxCols); !null {
+			colCounts[v]++
+		}
+	}
+
+	ct := &CrossTab{Rows: rows, Cols: cols, Total: int64(sample.Len())}
+	var rowIndex, colIndex map[string]int
+	ct.RowValues, rowIndex = crossTabValues(rowCounts)
+	ct.ColValues, colIndex = crossTabValues(colCounts)
+	ct.Counts = make([][]int64, len(ct.RowValues))
+	for i := range ct.Counts {
+		ct.Counts[i] = make([]int64, len(ct.ColValues))
+	}
+	ct.RowTotals = make([]int64, len(ct.RowValues))
+	ct.ColTotals = make([]int64, len(ct.ColValues))
+
+	position := func(row, idx int, index map[string]int) int {
+		v, null := label(row, idx)
+		if null {
+			return len(index) + 1
+		}
+		if i, ok := index[v]; ok {
+			return i
+		}
+		return len(index)
+	}
+	for row := 0; row < sample.Len(); row++ {
+		i := position(row, idxRows, rowIndex)
+		j := position(row, idxCols, colIndex)
+		ct.Counts[i][j]++
+		ct.RowTotals[i]++
+		ct.ColTotals[j]++
+	}
+	ct.dropEmpty()
+	return ct, nil
+}
+
+// crossTabValues returns the MaxCrossTabValues most frequent values followed
+// by "(other)" and "(null)", and the positions of the former
+func crossTabValues(counts map[string]int64) ([]string, map[string]int) {
+	top := topValues(counts, MaxCrossTabValues)
+	values := make([]string, 0, len(top)+2)
+	index := make(map[string]int, len(top))
+	for i, vc := range top {
+		values = append(values, vc.Value)
+		index[vc.Value] = i
+	}
+	return append(values, crossTabOther, crossTabNull), index
+}
+
+// dropEmpty removes the "(other)" and "(null)" rows and columns nothing was
+// counted in
+func (ct *CrossTab) dropEmpty() {
+	for i := len(ct.RowValues) - 1; i >= 0; i-- {
+		if ct.RowTotals[i] == 0 {
+			ct.RowValues = append(ct.RowValues[:i], ct.RowValues[i+1:]...)
+			ct.RowTotals = append(ct.RowTotals[:i], ct.RowTotals[i+1:]...)
+			ct.Counts = append(ct.Counts[:i], ct.Counts[i+1:]...)
+		}
+	}
+	for j := len(ct.ColValues) - 1; j >= 0; j-- {
+		if ct.ColTotals[j] == 0 {
+			ct.ColValues = append(ct.ColValues[:j], ct.ColValues[j+1:]...)
+			ct.ColTotals = append(ct.ColTotals[:j], ct.ColTotals[j+1:]...)
+			for i := range ct.Counts {
+				ct.Counts[i] = append(ct.Counts[i][:j], ct.Counts[i][j+1:]...)
+			}
+		}
+	}
+}
+
+// share returns count as a percentage of what percent selects
+func (ct *CrossTab) share(count int64, i, j int, percent CrossTabPercent) float64 {
+	total := ct.Total
+	switch {
+	case percent == PercentOfRow && i >= 0:
+		total = ct.RowTotals[i]
+	case percent == PercentOfColumn && j >= 0:
+		total = ct.ColTotals[j]
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total) * 100
+}
+
+// WriteText writes the table with a count and percentage in each cell and
+// the totals of each row and column, with numbers formatted by nf
+func (ct *CrossTab) WriteText(w io.Writer, nf NumberFormat, percent CrossTabPercent) error {
+	ew := &errWriter{w: w}
+	nw := nf.writer()
+	cellText := func(count int64, i, j int) string {
+		return fmt.Sprintf("%s (%.1f%%)", nw.int(count), ct.share(count, i, j, percent))
+	}
+
+	ew.printf("=== %s by %s ===\n", ct.Rows, ct.Cols)
+	ew.printf("Rows: %s\n\n", nw.int(ct.Total))
+	header := []string{cell(ct.Rows + " \\ " + ct.Cols)}
+	for _, v := range ct.ColValues {
+		header = append(header, cell(v))
+	}
+	rows := [][]string{append(header, "Total")}
+	for i, v := range ct.RowValues {
+		row := []string{cell(v)}
+		for j, count := range ct.Counts[i] {
+			row = append(row, cellText(count, i, j))
+		}
+		rows = append(rows, append(row, cellText(ct.RowTotals[i], i, -1)))
+	}
+	totals := []string{"Total"}
+	for j, count := range ct.ColTotals {
+		totals = append(totals, cellText(count, -1, j))
+	}
+	rows = append(rows, append(totals, cellText(ct.Total, -1, -1)))
+	for _, line := range alignRows(rows) {
+		ew.printf("  %s\n", line)
+	}
+	ew.printf("\n")
+	return ew.err
+}
@@ -0,0 +1,86 @@
+package tablestats
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCrossTabulate(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header: []string{"region", "status"},
+	}, [][]string{
+		{"EU", "open"},
+		{"EU", "closed"},
+		{"EU", "closed"},
+		{"US", "closed"},
+		{"", "open"},
+	})
+	ct, err := CrossTabulate(sample, "region", "status")
+	if err != nil {
+		t.Fatalf("CrossTabulate failed: %v", err)
+	}
+	if !reflect.DeepEqual(ct.RowValues, []string{"EU", "US", "(null)"}) {
+		t.Errorf("Expected rows EU, US and (null), got %v", ct.RowValues)
+	}
+	if !reflect.DeepEqual(ct.ColValues, []string{"closed", "open"}) {
+		t.Errorf("Expected columns closed and open, got %v", ct.ColValues)
+	}
+	expected := [][]int64{{2, 1}, {1, 0}, {0, 1}}
+	if !reflect.DeepEqual(ct.Counts, expected) {
+		t.Errorf("Expected counts %v, got %v", expected, ct.Counts)
+	}
+	if !reflect.DeepEqual(ct.RowTotals, []int64{3, 1, 1}) || !reflect.DeepEqual(ct.ColTotals, []int64{3, 2}) || ct.Total != 5 {
+		t.Errorf("Unexpected totals %v, %v and %d", ct.RowTotals, ct.ColTotals, ct.Total)
+	}
+
+	var b strings.Builder
+	if err := ct.WriteText(&b, NumberFormat{}, PercentOfRow); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, line := range []string{
+		"  region \\ status  closed      open        Total\n",
+		"  EU               2 (66.7%)   1 (33.3%)   3 (100.0%)\n",
+		"  Total            3 (60.0%)   2 (40.0%)   5 (100.0%)\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in the report, got:\n%s", line, b.String())
+		}
+	}
+
+	if _, err := CrossTabulate(sample, "region", "state"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestCrossTabulate_Other(t *testing.T) {
+	sample := &Sample{Header: []string{"id", "flag"}}
+	var records [][]string
+	for i := range MaxCrossTabValues + 5 {
+		records = append(records, []string{fmt.Sprint(i), "y"})
+	}
+	withRecords(sample, records)
+	ct, err := CrossTabulate(sample, "id", "flag")
+	if err != nil {
+		t.Fatalf("CrossTabulate failed: %v", err)
+	}
+	if len(ct.RowValues) != MaxCrossTabValues+1 || ct.RowValues[MaxCrossTabValues] != "(other)" {
+		t.Fatalf("Expected %d values and (other), got %v", MaxCrossTabValues, ct.RowValues)
+	}
+	if ct.RowTotals[MaxCrossTabValues] != 5 {
+		t.Errorf("Expected 5 rows in (other), got %d", ct.RowTotals[MaxCrossTabValues])
+	}
+}
+
+func TestParseCrossTabPercent(t *testing.T) {
+	for name, expected := range map[string]CrossTabPercent{"": PercentOfTotal, "row": PercentOfRow, "col": PercentOfColumn} {
+		if got, err := ParseCrossTabPercent(name); err != nil || got != expected {
+			t.Errorf("ParseCrossTabPercent(%q): expected %v, got %v, %v", name, expected, got, err)
+		}
+	}
+	if _, err := ParseCrossTabPercent("cell"); err == nil {
+		t.Error("Expected an error for an unknown percentage")
+	}
+}
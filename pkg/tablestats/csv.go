@@ -0,0 +1,854 @@
+package tablestats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// CSVReader implements TableReader for CSV files with probabilistic sampling
+type CSVReader struct {
+	Delimiter  rune
+	Encoding   string      // utf-8 (default), utf-16le, latin1 or windows-1252
+	Quote      rune        // Quote character (default '"'), must be ASCII
+	LazyQuotes bool        // Allow quotes in unquoted fields and bare quotes in quoted fields
+	Escape     EscapeStyle // How quotes inside quoted fields are escaped (default doubled)
+	Comment    rune        // Lines starting with this character are skipped (0 disables)
+	NoHeader   bool        // The first record holds values; columns are named column_1, column_2 and so on
+	// OnParseError selects what happens to malformed records after the header
+	OnParseError ErrorPolicy
+	// MaxParseErrors makes ErrorsSkip and ErrorsReport fail once more records
+	// are malformed (0 means no limit)
+	MaxParseErrors int64
+}
+
+func NewCSVReader(delimiter rune) *CSVReader {
+	return &CSVReader{
+		Delimiter: delimiter,
+	}
+}
+
+func (r *CSVReader) GetFormatName() string {
+	return "CSV"
+}
+
+func (r *CSVReader) ReadTable(ctx context.Context, filePath string, config SamplingConfig) (*TableStats, error) {
+	file, size, err := openTable(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return r.ReadTableFrom(ctx, file, size, config)
+}
+
+// ReadSample reads the header and the rows selected by config without analyzing them
+func (r *CSVReader) ReadSample(ctx context.Context, filePath string, config SamplingConfig) (*Sample, error) {
+	file, size, err := openTable(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return r.ReadSampleFrom(ctx, file, size, config)
+}
+
+// openTable opens a file and returns its size
+func openTable(filePath string) (*os.File, int64, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	// Get file size
+	fileInfo, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, fmt.Errorf("failed to get file info: %w", err)
+	}
+	return file, fileInfo.Size(), nil
+}
+
+// ReadTableFrom profiles data read from rd. size is the total number of bytes
+// rd will yield, or -1 when unknown. Inputs that are read in full are analyzed
+// row by row without keeping the records in memory.
+func (r *CSVReader) ReadTableFrom(ctx context.Context, rd io.Reader, size int64, config SamplingConfig) (*TableStats, error) {
+	sample, acc, err := r.readSample(ctx, rd, size, config, true)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := startSpan(ctx, "analyze",
+		attribute.Int64("tablestats.sampled_rows", int64(sample.Len())),
+		attribute.Int("tablestats.columns", len(sample.Header)))
+	defer span.End()
+
+	if acc != nil {
+		stats := acc.finalize(sample.EstimatedRows)
+		stats.RaggedRows = sample.Ragged.found()
+		stats.ParseErrors = sample.ParseErrors
+		stats.RenamedColumns = sample.Renamed
+		stats.FileInfo = sample.FileInfo
+		return stats, nil
+	}
+	return analyzeSample(ctx, sample, config), nil
+}
+
+// ReadSampleFrom is ReadSample for data read from rd. Readers that also implement
+// io.Seeker and io.ReaderAt (files, bytes.Reader) are sampled at random positions;
+// other streams are read once from start to end and sampled with a reservoir.
+func (r *CSVReader) ReadSampleFrom(ctx context.Context, rd io.Reader, size int64, config SamplingConfig) (*Sample, error) {
+	sample, _, err := r.readSample(ctx, rd, size, config, false)
+	return sample, err
+}
+
+// readSample implements ReadSampleFrom. When stream is set and the input is read
+// in full, rows are fed to the returned accumulator instead of sample.Columns.
+func (r *CSVReader) readSample(ctx context.Context, rd io.Reader, size int64, config SamplingConfig, stream bool) (sample *Sample, acc *TableAccumulator, err error) {
+	ctx, span := startSpan(ctx, "read",
+		attribute.String("tablestats.format", r.GetFormatName()),
+		attribute.Int64("tablestats.size_bytes", size))
+	defer func() { endSpan(span, err) }()
+
+	enc, err := lookupEncoding(r.Encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := r.validateDialect(); err != nil {
+		return nil, nil, err
+	}
+
+	head, rd := peekHead(rd, size)
+	info := inspectFile(head, size, enc)
+	if info.Compression != "none" {
+		// Compressed bytes would be parsed as garbage columns, or fail as malformed
+		return nil, nil, fmt.Errorf("file is %s-compressed; decompress it before profiling", info.Compression)
+	}
+	src, seekable := rd.(randomAccessReader)
+	seekable = seekable && size >= 0
+
+	progress := newProgressReporter(config.Progress, size)
+	defer func() {
+		progress.report()
+		recordRead(ctx, r.GetFormatName(), progress)
+		span.SetAttributes(
+			attribute.Int64("tablestats.rows_read", progress.rows),
+			attribute.Int64("tablestats.bytes_read", progress.bytes))
+	}()
+
+	// Read header first. A full read that only accumulates statistics splits
+	// plain-dialect fields into bytes instead of allocating strings.
+	input := enc.decode(&countingReader{r: rd, p: progress})
+	full := config.Offset == 0 && config.Limit <= 0 && size >= 0 && size <= config.MaxFileSize
+	var csvReader *recordReader
+	var splitter *fieldSplitter
+	var header []string
+	if stream && full && r.splittable() {
+		splitter = newFieldSplitter(ctx, input, byte(r.Delimiter))
+	} else {
+		csvReader = r.newCSVReader(ctx, input)
+		csvReader.tracked = true
+	}
+	switch {
+	case r.NoHeader:
+		header, err = r.numberedHeader(ctx, head, enc)
+	case splitter != nil:
+		header, err = splitter.readHeader()
+	default:
+		header, err = csvReader.Read()
+	}
+	if err == io.EOF {
+		return nil, nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	stripBOM(header)
+	if !hasColumnNames(header) {
+		return nil, nil, ErrNoHeader
+	}
+	info.HeaderChecksum = headerChecksum(header)
+	header, renamed := uniqueHeader(header)
+	ragged := newRaggedCounter(header)
+	errs := &parseErrorCounter{policy: r.OnParseError, max: r.MaxParseErrors}
+	if splitter != nil {
+		splitter.progress = progress
+		splitter.ragged = ragged
+		splitter.errs = errs
+	} else {
+		csvReader.progress = progress
+		csvReader.ragged = ragged
+		csvReader.errs = errs
+	}
+	defer func() {
+		if sample != nil {
+			sample.Ragged = ragged.result()
+			sample.ParseErrors = errs.result()
+		}
+	}()
+
+	indexes, err := config.columnIndexes(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyIdx, err := config.keyIndexes(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	groupIdx, err := config.groupIndex(header)
+	if err != nil {
+		return nil, nil, err
+	}
+	sampleIdx, err := config.sampleIndexes(header, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	candidateIdx, err := config.candidateIndexes(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	weightIdx := -1
+	if config.WeightColumn != "" {
+		for i, name := range header {
+			if name == config.WeightColumn {
+				weightIdx = i
+				break
+			}
+		}
+		if weightIdx < 0 {
+			return nil, nil, fmt.Errorf("weight column %q: %w", config.WeightColumn, ErrUnknownColumn)
+		}
+	}
+
+	poolConfig := config
+	if weightIdx >= 0 {
+		// Oversample uniformly, then keep rows proportionally to their weight
+		poolConfig.SampleSize *= weightedOversampling
+	}
+
+	sample = &Sample{Header: header, Renamed: renamed, FileInfo: info}
+	var records [][]string // Rows picked by the sampling strategies
+	var readerBytes int64
+
+	// Only the profiled columns (and the weight, key, group, sample and key
+	// candidate columns) are copied out of each row
+	extra := append(append(keyIdx, sampleIdx...), weightIdx, groupIdx)
+	for _, idx := range candidateIdx {
+		extra = append(extra, idx...)
+	}
+	fields := storedFields(len(header), indexes, extra...)
+	if splitter != nil {
+		splitter.keepOnly(fields)
+	} else if fields != nil && !(full && stream) {
+		csvReader.fields = fields
+		sample.Header = projectRecords([][]string{header}, fields)[0]
+		for i, idx := range fields {
+			if idx == weightIdx {
+				weightIdx = i
+			}
+		}
+	}
+
+	// Decide sampling strategy based on the requested window and file size
+	if config.Offset != 0 || config.Limit > 0 {
+		// Row window - profile exactly the requested slice
+		span.SetAttributes(attribute.String("tablestats.strategy", "window"))
+		rows := newColumnBuilder(len(sample.Header))
+		if seekable {
+			err = r.readWindow(src, csvReader, enc, size, config, rows)
+		} else {
+			err = readStreamWindow(csvReader, config, rows)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read row window: %w", err)
+		}
+		sample.Columns = rows.finish()
+		sample.EstimatedRows = int64(sample.Len())
+		sample.Exact = true
+		return sample, nil, nil
+	} else if full {
+		// Small file - read entirely
+		span.SetAttributes(attribute.String("tablestats.strategy", "full"))
+		if stream && config.Checkpoint != "" {
+			// Offsets into the decoded input are only file offsets in utf-8
+			if !seekable || enc.decoder != nil {
+				return nil, nil, fmt.Errorf("checkpoints need a seekable utf-8 file")
+			}
+			acc, err = r.checkpointedRecords(ctx, src, size, info, splitter, csvReader, header, config)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			sample.EstimatedRows = acc.rows
+		} else if splitter != nil {
+			acc, err = splitRecords(splitter, header, config)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			sample.EstimatedRows = acc.rows
+		} else if stream {
+			acc, err = streamRecords(csvReader, header, config)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			sample.EstimatedRows = acc.rows
+		} else {
+			rows := newColumnBuilder(len(sample.Header))
+			if err := readRows(csvReader, 0, rows); err != nil {
+				return nil, nil, fmt.Errorf("failed to read CSV: %w", err)
+			}
+			sample.Columns = rows.finish()
+			sample.EstimatedRows = int64(sample.Len())
+		}
+		sample.Exact = true
+		return sample, acc, nil
+	} else if seekable {
+		// Large file - use probabilistic sampling
+		span.SetAttributes(attribute.String("tablestats.strategy", "random_positions"))
+		sampleCtx, sampleSpan := startSpan(ctx, "sample")
+		lines := &lineTally{}
+		records, readerBytes, err = r.sampleRecords(sampleCtx, src, size, poolConfig, len(header), fields, progress, ragged, errs, lines)
+		endSpan(sampleSpan, err)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to sample records: %w", err)
+		}
+		// Estimate total rows from the newline density, or from the sample density
+		// when the file has no newlines to count
+		rows, ok, err := estimateRowsByNewlines(src, enc, size)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to estimate row count: %w", err)
+		}
+		if !ok {
+			rows = r.estimateRowCount(size, readerBytes, poolConfig)
+		} else {
+			// Quoted fields spanning several lines make records fewer than lines
+			rows = int64(float64(rows) / lines.perRecord())
+			if r.NoHeader {
+				// The first line is a record too
+				rows++
+			}
+		}
+		sample.EstimatedRows = rows
+	} else {
+		// Large or unbounded stream - keep a uniform reservoir while counting rows
+		span.SetAttributes(attribute.String("tablestats.strategy", "reservoir"))
+		_, sampleSpan := startSpan(ctx, "sample")
+		records, sample.EstimatedRows, err = reservoirSample(csvReader, poolConfig.SampleSize)
+		endSpan(sampleSpan, err)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to sample records: %w", err)
+		}
+		sample.Exact = int64(len(records)) == sample.EstimatedRows
+	}
+
+	if weightIdx >= 0 {
+		records, sample.Weights = weightedSample(records, weightIdx, config.SampleSize)
+		sample.Exact = false
+	}
+	sample.Columns = buildColumns(len(sample.Header), records)
+
+	return sample, nil, nil
+}
+
+// numberedHeader names the columns of a file without a header column_1,
+// column_2 and so on, as many as the first record in head has fields. The
+// record is parsed apart, so it is read again as the first row.
+func (r *CSVReader) numberedHeader(ctx context.Context, head []byte, enc textEncoding) ([]string, error) {
+	record, err := r.newCSVReader(ctx, enc.decode(bytes.NewReader(head))).Read()
+	if err != nil {
+		return nil, err
+	}
+	header := make([]string, len(record))
+	for i := range header {
+		header[i] = "column_" + strconv.Itoa(i+1)
+	}
+	return header, nil
+}
+
+// newCSVReader creates a record reader configured with the reader's dialect
+func (r *CSVReader) newCSVReader(ctx context.Context, rd io.Reader) *recordReader {
+	var quote byte
+	if r.Quote != 0 && r.Quote != '"' {
+		quote = byte(r.Quote)
+		rd = &swapReader{r: rd, a: quote, b: '"'}
+	}
+	if r.Escape == EscapeBackslash {
+		rd = newUnescapeReader(rd, byte(r.Delimiter))
+	}
+
+	csvReader := csv.NewReader(rd)
+	csvReader.Comma = r.Delimiter
+	csvReader.Comment = r.Comment
+	// Backslash escapes outside quoted fields are left as bare quotes
+	csvReader.LazyQuotes = r.LazyQuotes || r.Escape == EscapeBackslash
+	// Rows of another width than the header are counted rather than rejected
+	csvReader.FieldsPerRecord = -1
+	return &recordReader{Reader: csvReader, quote: quote, ctx: ctx}
+}
+
+// readWindow reads the rows selected by config.Offset and config.Limit.
+// A negative offset is resolved by scanning backwards from the end of the file,
+// so tailing a large append-only file does not require parsing everything before it.
+func (r *CSVReader) readWindow(file randomAccessReader, csvReader *recordReader, enc textEncoding, fileSize int64, config SamplingConfig, rows *columnBuilder) error {
+	if config.Offset < 0 {
+		start, err := findTailOffset(file, enc, fileSize, -config.Offset)
+		if err != nil {
+			return err
+		}
+		// A tail reaching the start of the file covers every row after the header,
+		// which csvReader is already positioned at
+		if start > 0 {
+			if _, err := file.Seek(start, io.SeekStart); err != nil {
+				return err
+			}
+			progress := csvReader.progress
+			if progress != nil {
+				progress.bytes = start
+			}
+			prev := csvReader
+			csvReader = r.newCSVReader(prev.ctx, enc.decode(&countingReader{r: file, p: progress}))
+			csvReader.progress = progress
+			csvReader.fields = prev.fields
+			csvReader.ragged = prev.ragged
+			csvReader.errs = prev.errs
+		}
+	} else {
+		if err := skipRows(csvReader, config.Offset); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return readRows(csvReader, config.Limit, rows)
+}
+
+// readRows appends up to limit rows to rows, or every remaining row when limit
+// is 0. Rows are copied into the builder, so the reader's record is reused.
+func readRows(csvReader *recordReader, limit int64, rows *columnBuilder) error {
+	csvReader.ReuseRecord = true
+	for n := int64(0); limit == 0 || n < limit; n++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows.append(record)
+	}
+	return nil
+}
+
+// skipRows reads n rows without checking their field counts, returning
+// io.EOF when the input ends first
+func skipRows(csvReader *recordReader, n int64) error {
+	ragged := csvReader.ragged
+	csvReader.ragged = nil
+	defer func() { csvReader.ragged = ragged }()
+	for i := int64(0); i < n; i++ {
+		if _, err := csvReader.Read(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readStreamWindow is readWindow for streams that cannot seek. A negative offset
+// keeps the last rows in a ring buffer while the stream is read to the end.
+func readStreamWindow(csvReader *recordReader, config SamplingConfig, rows *columnBuilder) error {
+	if config.Offset >= 0 {
+		if err := skipRows(csvReader, config.Offset); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		return readRows(csvReader, config.Limit, rows)
+	}
+
+	n := -config.Offset
+	ring := make([][]string, 0, min(n, 4096))
+	var seen int64
+	csvReader.ReuseRecord = true
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if int64(len(ring)) < n {
+			ring = append(ring, keepRecord(nil, record))
+		} else {
+			ring[seen%n] = keepRecord(ring[seen%n], record)
+		}
+		seen++
+	}
+
+	// Rotate the ring so the oldest row comes first
+	records := ring
+	if seen > n {
+		split := seen % n
+		records = append(append([][]string{}, ring[split:]...), ring[:split]...)
+	}
+	if config.Limit > 0 && int64(len(records)) > config.Limit {
+		records = records[:config.Limit]
+	}
+	for _, record := range records {
+		rows.append(record)
+	}
+	return nil
+}
+
+// reservoirSample reads every remaining row, keeping a uniform sample of up to
+// size rows, and returns it along with the number of rows read
+func reservoirSample(csvReader *recordReader, size int) ([][]string, int64, error) {
+	var records [][]string
+	var seen int64
+	// Most rows are dropped, so only the kept ones are copied out of the reused slice
+	csvReader.ReuseRecord = true
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		seen++
+		if len(records) < size {
+			records = append(records, keepRecord(nil, record))
+		} else if j := rand.Int63n(seen); j < int64(size) {
+			records[j] = keepRecord(records[j], record)
+		}
+	}
+	return records, seen, nil
+}
+
+// findTailOffset returns the byte offset where the last n lines of the file begin.
+// Blocks are read backwards from the end, so the cost depends on the size of the
+// tail rather than the size of the file. Quoted fields spanning several lines are
+// counted as several lines.
+func findTailOffset(file io.ReaderAt, enc textEncoding, fileSize int64, n int64) (int64, error) {
+	const blockSize = 64 * 1024
+
+	unit := enc.unit
+	end := fileSize - fileSize%unit
+	if end >= unit {
+		last := make([]byte, unit)
+		if _, err := file.ReadAt(last, end-unit); err != nil {
+			return 0, err
+		}
+		// The final newline terminates the last line rather than starting a new one
+		if enc.isNewline(last, 0) {
+			end -= unit
+		}
+	}
+
+	buf := make([]byte, blockSize)
+	var newlines int64
+	for pos := end; pos > 0; {
+		size := int64(blockSize)
+		if pos < size {
+			size = pos
+		}
+		pos -= size
+
+		if _, err := file.ReadAt(buf[:size], pos); err != nil {
+			return 0, err
+		}
+		for i := size - unit; i >= 0; i -= unit {
+			if !enc.isNewline(buf, i) {
+				continue
+			}
+			newlines++
+			if newlines == n {
+				return pos + i + unit, nil
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+// sampleRecords reads records from random positions of the file. width is the
+// number of fields of the header, used to find record boundaries; lines, if
+// not nil, tallies the lines the records span.
+func (r *CSVReader) sampleRecords(ctx context.Context, file io.ReadSeeker, fileSize int64, config SamplingConfig, width int, fields []int, progress *progressReporter, ragged *raggedCounter, errs *parseErrorCounter, lines *lineTally) ([][]string, int64, error) {
+	enc, err := lookupEncoding(r.Encoding)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var allRecords [][]string
+	recordsPerPosition := config.SampleSize / config.RandomPositions
+	if recordsPerPosition < 1 {
+		recordsPerPosition = 1
+	}
+
+	var readerBytes int64 = 0
+
+	for i := 0; i < config.RandomPositions; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+
+		// Generate random position (skip first 1% to avoid header area)
+		minPos := fileSize / 100
+		randomPos := minPos + rand.Int63n(fileSize-minPos)
+		// Multi-byte encodings can only be decoded from a code unit boundary
+		randomPos -= randomPos % enc.unit
+
+		_, err := file.Seek(randomPos, io.SeekStart)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		records, err := r.readFromPosition(ctx, file, enc, recordsPerPosition, width, fields, ragged, errs, lines)
+		if err != nil {
+			return nil, 0, err
+		}
+		current, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		readerBytes += current - randomPos
+		allRecords = append(allRecords, records...)
+		if progress != nil {
+			progress.bytes += current - randomPos
+			progress.rows += int64(len(records))
+			progress.report()
+		}
+
+		if len(allRecords) >= config.SampleSize {
+			break
+		}
+	}
+
+	// Trim to exact sample size
+	if len(allRecords) > config.SampleSize {
+		allRecords = allRecords[:config.SampleSize]
+	}
+
+	return allRecords, readerBytes, nil
+}
+
+func (r *CSVReader) readFromPosition(ctx context.Context, file io.Reader, enc textEncoding, maxRecords, width int, fields []int, ragged *raggedCounter, errs *parseErrorCounter, lines *lineTally) ([][]string, error) {
+	reader := bufio.NewReaderSize(enc.decode(file), resyncBytes)
+
+	// Skip to the start of a record. The position may be in the middle of a
+	// line, or of a quoted field spanning several lines.
+	window, err := reader.Peek(resyncBytes)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if start, ok := r.recordStart(ctx, window, err == nil, width); ok {
+		reader.Discard(start)
+	} else if _, _, err := reader.ReadLine(); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// Read records from this position
+	csvReader := r.newCSVReader(ctx, reader)
+	csvReader.fields = fields
+	csvReader.ragged = ragged
+
+	// The first record may still begin inside a quoted field when no record
+	// boundary was found, so it is dropped rather than counted as malformed
+	// when it does not parse
+	record, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	var parseErr *ParseError
+	if err != nil && !errors.As(err, &parseErr) {
+		return nil, err
+	}
+	csvReader.errs = errs
+
+	var records [][]string
+	firstLine := 0
+	if err == nil {
+		records = append(records, record)
+		firstLine, _ = csvReader.FieldPos(0)
+	}
+	lastLine := firstLine
+	for len(records) < maxRecords {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			firstLine, _ = csvReader.FieldPos(0)
+		}
+		lastLine, _ = csvReader.FieldPos(0)
+		records = append(records, record)
+	}
+	if lines != nil && len(records) > 1 {
+		lines.lines += int64(lastLine - firstLine)
+		lines.records += int64(len(records) - 1)
+	}
+
+	return records, nil
+}
+
+// A random position is resynchronised by trying the first resyncLines line
+// starts in the next resyncBytes, taking the first from which resyncRecords
+// records parse as wide as the header
+const (
+	resyncBytes   = 64 << 10
+	resyncLines   = 8
+	resyncRecords = 4
+)
+
+// recordStart returns the offset in window of the first line start that
+// looks like the start of a record, skipping lines inside quoted fields that
+// span several lines. truncated is true when the input continues past window.
+// It falls back to the first line start, and ok is false when window has no
+// line break.
+func (r *CSVReader) recordStart(ctx context.Context, window []byte, truncated bool, width int) (start int, ok bool) {
+	var starts []int
+	for i := 0; i < len(window) && len(starts) < resyncLines; i++ {
+		if window[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	if len(starts) == 0 {
+		return 0, false
+	}
+	for _, start := range starts {
+		if r.parsesAligned(ctx, window[start:], truncated, width) {
+			return start, true
+		}
+	}
+	return starts[0], true
+}
+
+// parsesAligned reports whether the records at the start of buf parse without
+// errors and with width fields each. A record cut off at the end of a
+// truncated buf is not held against it.
+func (r *CSVReader) parsesAligned(ctx context.Context, buf []byte, truncated bool, width int) bool {
+	reader := r.newCSVReader(ctx, bytes.NewReader(buf))
+	for n := 0; n < resyncRecords; n++ {
+		record, err := reader.Reader.Read()
+		if err == io.EOF {
+			return n > 0
+		}
+		if err != nil {
+			return truncated && n > 0
+		}
+		if len(record) != width {
+			return false
+		}
+	}
+	return true
+}
+
+// lineTally counts the lines spanned by sampled records, which exceed them
+// when quoted fields hold line breaks
+type lineTally struct {
+	lines, records int64
+}
+
+// perRecord returns the average number of lines per record, 1 when unknown
+func (t *lineTally) perRecord() float64 {
+	if t == nil || t.records == 0 || t.lines < t.records {
+		return 1
+	}
+	return float64(t.lines) / float64(t.records)
+}
+
+func (r *CSVReader) estimateRowCount(fileSize int64, readerBytes int64, config SamplingConfig) int64 {
+	// Simple estimation based on file size and sample density
+	if readerBytes <= 0 || config.SampleSize <= 0 {
+		return 0
+	}
+	avgBytesPerRecord := float64(readerBytes) / float64(config.SampleSize)
+	return int64(float64(fileSize) / avgBytesPerRecord)
+}
+
+const (
+	newlineBlocks    = 4       // Blocks scanned by estimateRowsByNewlines
+	newlineBlockSize = 1 << 20 // Bytes per block
+)
+
+// estimateRowsByNewlines estimates the number of records from the newline
+// density of a few large blocks spread evenly over the file. Counting bytes is
+// far cheaper than parsing, so much more of the file is covered than by the
+// sampled rows, which makes the estimate more stable. Quoted fields spanning
+// several lines are counted as several rows, which callers correct for with
+// the lines per record of the sample. Files no larger than the blocks
+// are scanned entirely. ok is false when no newline was found.
+func estimateRowsByNewlines(file io.ReaderAt, enc textEncoding, fileSize int64) (rows int64, ok bool, err error) {
+	blockSize := int64(newlineBlockSize)
+	starts := make([]int64, 0, newlineBlocks)
+	if fileSize <= newlineBlocks*blockSize {
+		blockSize = fileSize
+		starts = append(starts, 0)
+	} else {
+		for i := int64(0); i < newlineBlocks; i++ {
+			start := (fileSize - blockSize) * i / (newlineBlocks - 1)
+			starts = append(starts, start-start%enc.unit)
+		}
+	}
+
+	buf := make([]byte, blockSize)
+	var scanned, newlines int64
+	for _, start := range starts {
+		n, err := file.ReadAt(buf, start)
+		if err != nil && err != io.EOF {
+			return 0, false, err
+		}
+		n -= n % int(enc.unit)
+		scanned += int64(n)
+		newlines += enc.countNewlines(buf[:n])
+	}
+	if newlines == 0 || scanned == 0 {
+		return 0, false, nil
+	}
+
+	lines := int64(float64(fileSize) * float64(newlines) / float64(scanned))
+	if blockSize == fileSize && !enc.isNewline(buf, fileSize-enc.unit) {
+		// The last line of a fully scanned file has no newline
+		lines++
+	}
+	// The header is not a record
+	return max(lines-1, 0), true, nil
+}
+
+// streamRecords feeds every remaining record to a new accumulator
+func streamRecords(csvReader *recordReader, header []string, config SamplingConfig) (*TableAccumulator, error) {
+	acc, err := newTableAccumulator(csvReader.ctx, header, config)
+	if err != nil {
+		return nil, err
+	}
+	// The accumulator copies what it keeps, so the record slice can be reused
+	csvReader.ReuseRecord = true
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return acc, nil
+		}
+		if err != nil {
+			acc.Discard()
+			return nil, err
+		}
+		acc.Add(record)
+	}
+}
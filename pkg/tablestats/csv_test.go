@@ -0,0 +1,1250 @@
+package tablestats
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// Test helper functions
+
+func createTempCSV(t *testing.T, content string, delimiter rune) string {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "test.csv")
+
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Comma = delimiter
+
+	lines := splitLines(content)
+	for _, line := range lines {
+		if line != "" {
+			fields := splitCSVLine(line, delimiter)
+			writer.Write(fields)
+		}
+	}
+	writer.Flush()
+
+	return tmpFile
+}
+
+func splitLines(content string) []string {
+	lines := []string{}
+	current := ""
+	for _, char := range content {
+		if char == '\n' {
+			lines = append(lines, current)
+			current = ""
+		} else {
+			current += string(char)
+		}
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+func splitCSVLine(line string, delimiter rune) []string {
+	fields := []string{}
+	current := ""
+	for _, char := range line {
+		if char == delimiter {
+			fields = append(fields, current)
+			current = ""
+		} else {
+			current += string(char)
+		}
+	}
+	fields = append(fields, current)
+	return fields
+}
+
+func createLargeCSV(t *testing.T, rows int) string {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "large.csv")
+
+	file, err := os.Create(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+
+	// Write header
+	writer.Write([]string{"id", "name", "value", "category"})
+
+	// Write data rows
+	for i := 1; i <= rows; i++ {
+		writer.Write([]string{
+			fmt.Sprintf("%d", i),
+			fmt.Sprintf("name_%d", i),
+			fmt.Sprintf("%.2f", float64(i)*1.5),
+			fmt.Sprintf("cat_%d", i%5),
+		})
+	}
+	writer.Flush()
+
+	return tmpFile
+}
+
+// Tests for NewCSVReader
+
+func TestNewCSVReader(t *testing.T) {
+	reader := NewCSVReader(',')
+
+	if reader.Delimiter != ',' {
+		t.Errorf("Expected delimiter ',', got %c", reader.Delimiter)
+	}
+
+	reader2 := NewCSVReader(';')
+	if reader2.Delimiter != ';' {
+		t.Errorf("Expected delimiter ';', got %c", reader2.Delimiter)
+	}
+}
+
+func TestGetFormatName(t *testing.T) {
+	reader := NewCSVReader(',')
+	if reader.GetFormatName() != "CSV" {
+		t.Errorf("Expected format name 'CSV', got %s", reader.GetFormatName())
+	}
+}
+
+// Tests for ReadTable
+
+func TestReadTable_BasicCSV(t *testing.T) {
+	csvContent := `name,age,salary
+John,25,50000
+Jane,30,60000
+Bob,35,55000`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024, // 1MB
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	// Check basic stats
+	if stats.ColumnCount != 3 {
+		t.Errorf("Expected 3 columns, got %d", stats.ColumnCount)
+	}
+
+	if stats.RowCount != 3 {
+		t.Errorf("Expected 3 rows, got %d", stats.RowCount)
+	}
+
+	expectedColumns := []string{"name", "age", "salary"}
+	if !reflect.DeepEqual(stats.ColumnNames, expectedColumns) {
+		t.Errorf("Expected columns %v, got %v", expectedColumns, stats.ColumnNames)
+	}
+
+	// Check column types
+	if stats.ColumnTypes["name"] != "string" {
+		t.Errorf("Expected name column to be string, got %s", stats.ColumnTypes["name"])
+	}
+
+	if stats.ColumnTypes["age"] != "int64" {
+		t.Errorf("Expected age column to be int64, got %s", stats.ColumnTypes["age"])
+	}
+
+	if stats.ColumnTypes["salary"] != "int64" {
+		t.Errorf("Expected salary column to be int64, got %s", stats.ColumnTypes["salary"])
+	}
+}
+
+func TestReadTable_WithFloats(t *testing.T) {
+	csvContent := `name,height,weight
+Alice,5.6,120.5
+Bob,6.0,180.0
+Charlie,5.8,165.25`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	// Check float column types
+	if stats.ColumnTypes["height"] != "float64" {
+		t.Errorf("Expected height column to be float64, got %s", stats.ColumnTypes["height"])
+	}
+
+	if stats.ColumnTypes["weight"] != "float64" {
+		t.Errorf("Expected weight column to be float64, got %s", stats.ColumnTypes["weight"])
+	}
+
+	// Check aggregates exist for numeric columns
+	if stats.Aggregates["height"] == nil {
+		t.Error("Expected aggregates for height column")
+	}
+
+	if stats.Aggregates["weight"] == nil {
+		t.Error("Expected aggregates for weight column")
+	}
+}
+
+func TestReadTable_WithNulls(t *testing.T) {
+	csvContent := `name,age,city
+John,25,NYC
+Jane,,Boston
+Bob,35,
+Alice,null,Chicago`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	// Check null counts
+	if stats.NullCounts["age"] != 2 { // Jane and Alice
+		t.Errorf("Expected 2 nulls in age column, got %d", stats.NullCounts["age"])
+	}
+
+	if stats.NullCounts["city"] != 1 { // Bob
+		t.Errorf("Expected 1 null in city column, got %d", stats.NullCounts["city"])
+	}
+
+	// Check null percentages
+	expectedAgeNullPct := 50.0 // 2 out of 4 rows
+	if stats.NullPercentage["age"] != expectedAgeNullPct {
+		t.Errorf("Expected %.1f%% nulls in age, got %.1f%%", expectedAgeNullPct, stats.NullPercentage["age"])
+	}
+}
+
+func TestReadTable_CustomDelimiter(t *testing.T) {
+	csvContent := `name;age;department
+John;25;Engineering
+Jane;30;Marketing`
+
+	tmpFile := createTempCSV(t, csvContent, ';')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(';')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	if stats.ColumnCount != 3 {
+		t.Errorf("Expected 3 columns, got %d", stats.ColumnCount)
+	}
+
+	expectedColumns := []string{"name", "age", "department"}
+	if !reflect.DeepEqual(stats.ColumnNames, expectedColumns) {
+		t.Errorf("Expected columns %v, got %v", expectedColumns, stats.ColumnNames)
+	}
+}
+
+func TestReadTable_EmptyFile(t *testing.T) {
+	tmpFile := createTempCSV(t, "", ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	_, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err == nil {
+		t.Error("Expected error for empty file")
+	}
+}
+
+func TestReadTable_HeaderOnly(t *testing.T) {
+	csvContent := `name,age,city`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	if stats.RowCount != 0 {
+		t.Errorf("Expected 0 data rows, got %d", stats.RowCount)
+	}
+
+	if stats.ColumnCount != 3 {
+		t.Errorf("Expected 3 columns, got %d", stats.ColumnCount)
+	}
+}
+
+func TestReadTable_NonExistentFile(t *testing.T) {
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	_, err := reader.ReadTable(context.Background(), "/nonexistent/file.csv", config)
+	if err == nil {
+		t.Error("Expected error for non-existent file")
+	}
+}
+
+func TestReadTable_SampleData(t *testing.T) {
+	csvContent := `name,value
+A,1
+B,2
+C,3
+D,4
+E,5
+F,6`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	// Should have 5 sample rows (default sample size)
+	if len(stats.SampleData) != 5 {
+		t.Errorf("Expected 5 sample rows, got %d", len(stats.SampleData))
+	}
+
+	// First sample row should be the first data row
+	if len(stats.SampleData[0]) != 2 {
+		t.Errorf("Expected 2 columns in sample data, got %d", len(stats.SampleData[0]))
+	}
+}
+
+// Tests for large file sampling
+
+func TestReadTable_LargeFileSampling(t *testing.T) {
+	// Create a file larger than MaxFileSize to trigger sampling
+	tmpFile := createLargeCSV(t, 10000)
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1000, // Very small to force sampling
+		SampleSize:      100,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	// Should have sampled data, not full dataset
+	if stats.RowCount > int64(config.SampleSize) {
+		t.Errorf("Expected at most %d sampled rows, got %d", config.SampleSize, stats.RowCount)
+	}
+
+	// Estimated rows should be much higher than actual sampled rows
+	if stats.EstimatedRows <= stats.RowCount {
+		t.Errorf("Expected estimated rows (%d) to be higher than sampled rows (%d)",
+			stats.EstimatedRows, stats.RowCount)
+	}
+}
+
+// Tests for row windows
+
+func TestReadTable_LimitOffset(t *testing.T) {
+	tmpFile := createLargeCSV(t, 100)
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+		Offset:          10,
+		Limit:           20,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	if stats.RowCount != 20 {
+		t.Errorf("Expected 20 rows, got %d", stats.RowCount)
+	}
+	if stats.MinValues["id"] != int64(11) {
+		t.Errorf("Expected min id 11, got %v", stats.MinValues["id"])
+	}
+	if stats.MaxValues["id"] != int64(30) {
+		t.Errorf("Expected max id 30, got %v", stats.MaxValues["id"])
+	}
+}
+
+func TestReadTable_NegativeOffset(t *testing.T) {
+	tmpFile := createLargeCSV(t, 100)
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+		Offset:          -10,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	if stats.RowCount != 10 {
+		t.Errorf("Expected 10 rows, got %d", stats.RowCount)
+	}
+	if stats.MinValues["id"] != int64(91) {
+		t.Errorf("Expected min id 91, got %v", stats.MinValues["id"])
+	}
+
+	// A tail longer than the file must not include the header
+	config.Offset = -1000
+	stats, err = reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if stats.RowCount != 100 {
+		t.Errorf("Expected 100 rows, got %d", stats.RowCount)
+	}
+	if stats.ColumnTypes["id"] != "int64" {
+		t.Errorf("Expected id column to be int64, got %s", stats.ColumnTypes["id"])
+	}
+}
+
+// Tests for column analysis
+
+func TestAnalyzeColumn_MinMaxValues(t *testing.T) {
+	csvContent := `name,age,score
+Alice,25,85.5
+Bob,30,92.0
+Charlie,22,78.5`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	// Check min/max for string column
+	if stats.MinValues["name"] != "Alice" {
+		t.Errorf("Expected min name 'Alice', got %v", stats.MinValues["name"])
+	}
+	if stats.MaxValues["name"] != "Charlie" {
+		t.Errorf("Expected max name 'Charlie', got %v", stats.MaxValues["name"])
+	}
+
+	// Check min/max for numeric columns
+	if stats.MinValues["age"] != int64(22) {
+		t.Errorf("Expected min age 22, got %v", stats.MinValues["age"])
+	}
+	if stats.MaxValues["age"] != int64(30) {
+		t.Errorf("Expected max age 30, got %v", stats.MaxValues["age"])
+	}
+}
+
+func TestAnalyzeColumn_MixedTypes(t *testing.T) {
+	// Column starts as numeric but has non-numeric values
+	csvContent := `id,mixed_col
+1,123
+2,456
+3,abc`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	// Mixed column should be treated as string
+	if stats.ColumnTypes["mixed_col"] != "string" {
+		t.Errorf("Expected mixed_col to be string, got %s", stats.ColumnTypes["mixed_col"])
+	}
+
+	// Should not have aggregates for string columns
+	if stats.Aggregates["mixed_col"] != nil {
+		t.Error("Expected no aggregates for string column")
+	}
+}
+
+// Test helper for sampling methods
+
+func TestSampleRecords(t *testing.T) {
+	// Create a reasonably sized file
+	tmpFile := createLargeCSV(t, 1000)
+	defer os.Remove(tmpFile)
+
+	file, err := os.Open(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	fileInfo, _ := file.Stat()
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		SampleSize:      50,
+		RandomPositions: 5,
+	}
+
+	records, _, err := reader.sampleRecords(context.Background(), file, fileInfo.Size(), config, 4, nil, nil, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("sampleRecords failed: %v", err)
+	}
+
+	if len(records) > config.SampleSize {
+		t.Errorf("Expected at most %d records, got %d", config.SampleSize, len(records))
+	}
+
+	// Each record should have 4 columns (from createLargeCSV)
+	for i, record := range records {
+		if len(record) != 4 {
+			t.Errorf("Record %d has %d columns, expected 4", i, len(record))
+		}
+	}
+}
+
+func TestEstimateRowCount(t *testing.T) {
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		RandomPositions: 5,
+		SampleSize:      100,
+	}
+
+	fileSize := int64(100000)
+	var readerBytes int64 = 1000
+
+	estimate := reader.estimateRowCount(fileSize, readerBytes, config)
+
+	// Estimate should be reasonable (non-zero and not negative)
+	if estimate <= 0 {
+		t.Errorf("Expected positive row count estimate, got %d", estimate)
+	}
+
+	// Estimate should be proportional to file size
+	estimate2 := reader.estimateRowCount(fileSize*2, readerBytes, config)
+	if estimate2 < estimate {
+		t.Errorf("Expected larger estimate for larger file, got %d >= %d", estimate2, estimate)
+	}
+}
+
+func TestEstimateRowCount_TinyReads(t *testing.T) {
+	reader := NewCSVReader(',')
+
+	// Fewer bytes read than rows requested must not divide by zero
+	config := SamplingConfig{RandomPositions: 5, SampleSize: 100}
+	if estimate := reader.estimateRowCount(100000, 10, config); estimate != 1000000 {
+		t.Errorf("Expected 1000000 rows, got %d", estimate)
+	}
+	if estimate := reader.estimateRowCount(100000, 0, config); estimate != 0 {
+		t.Errorf("Expected 0 rows when nothing was read, got %d", estimate)
+	}
+	config.SampleSize = 0
+	if estimate := reader.estimateRowCount(100000, 1000, config); estimate != 0 {
+		t.Errorf("Expected 0 rows for an empty sample, got %d", estimate)
+	}
+}
+
+func TestEstimateRowsByNewlines(t *testing.T) {
+	utf8, _ := lookupEncoding("utf-8")
+	utf16, _ := lookupEncoding("utf-16le")
+
+	tests := []struct {
+		name string
+		data []byte
+		enc  textEncoding
+		rows int64
+		ok   bool
+	}{
+		{"trailing newline", []byte("a,b\n1,2\n3,4\n"), utf8, 2, true},
+		{"no trailing newline", []byte("a,b\n1,2\n3,4"), utf8, 2, true},
+		{"header only", []byte("a,b\n"), utf8, 0, true},
+		{"no newline", []byte("a,b"), utf8, 0, false},
+		{"utf-16le", encodeUTF16LE("a,b\n1,2\n3,4\n"), utf16, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, ok, err := estimateRowsByNewlines(bytes.NewReader(tt.data), tt.enc, int64(len(tt.data)))
+			if err != nil {
+				t.Fatalf("estimateRowsByNewlines failed: %v", err)
+			}
+			if rows != tt.rows || ok != tt.ok {
+				t.Errorf("Expected %d rows (ok %v), got %d (ok %v)", tt.rows, tt.ok, rows, ok)
+			}
+		})
+	}
+}
+
+func TestReadTable_NewlineEstimate(t *testing.T) {
+	tmpFile := createLargeCSV(t, 20000)
+	defer os.Remove(tmpFile)
+
+	// Sampled reads estimate the row count from the newline density
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: 1024, SampleSize: 100, RandomPositions: 5}
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if stats.EstimatedRows != 20000 {
+		t.Errorf("Expected 20000 estimated rows, got %d", stats.EstimatedRows)
+	}
+}
+
+// Tests for column filters
+
+func TestReadTable_ColumnFilters(t *testing.T) {
+	csvContent := `id,name,notes,comments
+1,Alice,a,x
+2,Bob,b,y`
+
+	tmpFile := createTempCSV(t, csvContent, ',')
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+		Columns:         []string{"name", "id"},
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	expectedColumns := []string{"name", "id"}
+	if !reflect.DeepEqual(stats.ColumnNames, expectedColumns) {
+		t.Errorf("Expected columns %v, got %v", expectedColumns, stats.ColumnNames)
+	}
+	if _, ok := stats.ColumnTypes["notes"]; ok {
+		t.Error("Expected notes column not to be analyzed")
+	}
+	if !reflect.DeepEqual(stats.SampleData[0], []string{"Alice", "1"}) {
+		t.Errorf("Expected projected sample row [Alice 1], got %v", stats.SampleData[0])
+	}
+
+	config.Columns = nil
+	config.ExcludeColumns = []string{"notes", "comments"}
+	stats, err = reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	expectedColumns = []string{"id", "name"}
+	if !reflect.DeepEqual(stats.ColumnNames, expectedColumns) {
+		t.Errorf("Expected columns %v, got %v", expectedColumns, stats.ColumnNames)
+	}
+	if stats.ColumnCount != 2 {
+		t.Errorf("Expected 2 columns, got %d", stats.ColumnCount)
+	}
+
+	config.ExcludeColumns = []string{"missing"}
+	if _, err := reader.ReadTable(context.Background(), tmpFile, config); err == nil {
+		t.Error("Expected error for unknown column")
+	}
+}
+
+func TestReadSample_StoresSelectedColumns(t *testing.T) {
+	data := "id,name,notes,amount\n1,Alice,\"long, quoted\",10\n2,Bob,x,20\n3,Carol,y,30\n"
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: 1024, SampleSize: 1000, RandomPositions: 5, Columns: []string{"name", "id"}}
+
+	// Sampled rows hold only the selected fields, in header order
+	sample, err := reader.ReadSampleFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadSampleFrom failed: %v", err)
+	}
+	if !reflect.DeepEqual(sample.Header, []string{"id", "name"}) {
+		t.Errorf("Expected header [id name], got %v", sample.Header)
+	}
+	if !reflect.DeepEqual(sample.Record(0), []string{"1", "Alice"}) {
+		t.Errorf("Expected record [1 Alice], got %v", sample.Record(0))
+	}
+	stats := AnalyzeSample(sample, config)
+	if !reflect.DeepEqual(stats.ColumnNames, []string{"name", "id"}) {
+		t.Errorf("Expected columns [name id], got %v", stats.ColumnNames)
+	}
+
+	// The weight column is kept for weighting a sampled stream even when it is not profiled
+	config.WeightColumn = "amount"
+	sample, err = reader.ReadSampleFrom(context.Background(), io.MultiReader(strings.NewReader(data)), -1, config)
+	if err != nil {
+		t.Fatalf("ReadSampleFrom failed: %v", err)
+	}
+	if !reflect.DeepEqual(sample.Header, []string{"id", "name", "amount"}) {
+		t.Errorf("Expected header [id name amount], got %v", sample.Header)
+	}
+	if len(sample.Weights) != 3 {
+		t.Errorf("Expected 3 weighted rows, got %d", len(sample.Weights))
+	}
+}
+
+func TestReadTable_SampleRows(t *testing.T) {
+	tmpFile := createLargeCSV(t, 20)
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+		SampleRows:      12,
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if len(stats.SampleData) != 12 {
+		t.Errorf("Expected 12 sample rows, got %d", len(stats.SampleData))
+	}
+
+	config.SampleRows = -1
+	stats, err = reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if len(stats.SampleData) != 0 {
+		t.Errorf("Expected no sample rows, got %d", len(stats.SampleData))
+	}
+}
+
+func TestReadSample_Dialect(t *testing.T) {
+	csvContent := `# exported from legacy system
+id,name,note
+1,'Smith, John','say "hi"'
+# trailing comment
+2,'O''Brien',plain`
+
+	tmpFile := writeRawFile(t, "test.csv", []byte(csvContent))
+
+	reader := &CSVReader{Delimiter: ',', Quote: '\'', Comment: '#'}
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+
+	sample, err := reader.ReadSample(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadSample failed: %v", err)
+	}
+	if !reflect.DeepEqual(sample.Header, []string{"id", "name", "note"}) {
+		t.Errorf("Expected header [id name note], got %v", sample.Header)
+	}
+	expected := [][]string{
+		{"1", "Smith, John", `say "hi"`},
+		{"2", "O'Brien", "plain"},
+	}
+	if !reflect.DeepEqual(sample.Records(), expected) {
+		t.Errorf("Expected records %q, got %q", expected, sample.Records())
+	}
+
+	reader.Quote = 'é'
+	if _, err := reader.ReadSample(context.Background(), tmpFile, config); err == nil {
+		t.Error("Expected error for non-ASCII quote character")
+	}
+}
+
+func TestReadSample_LazyQuotes(t *testing.T) {
+	csvContent := `id,size
+1,5" screen
+2,"a "quoted" word"`
+
+	tmpFile := writeRawFile(t, "test.csv", []byte(csvContent))
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+
+	if _, err := reader.ReadSample(context.Background(), tmpFile, config); err == nil {
+		t.Error("Expected parse error without lazy quotes")
+	}
+
+	reader.LazyQuotes = true
+	sample, err := reader.ReadSample(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadSample failed: %v", err)
+	}
+	if sample.Record(0)[1] != `5" screen` {
+		t.Errorf("Expected 5\" screen, got %q", sample.Record(0)[1])
+	}
+	if sample.Record(1)[1] != `a "quoted" word` {
+		t.Errorf("Expected a \"quoted\" word, got %q", sample.Record(1)[1])
+	}
+}
+
+// writeMixedQuotingCSV writes rows combining the quoting real exports mix:
+// stray quotes in unquoted fields, quoted fields spanning three lines, and
+// quotes escaped both with a backslash and by doubling
+func writeMixedQuotingCSV(t *testing.T, rows int) string {
+	var b strings.Builder
+	b.WriteString("id,size,note,code\n")
+	for i := 1; i <= rows; i++ {
+		fmt.Fprintf(&b, "%d,5\" screen,\"note %d\nsays \\\"hi\\\" and \"\"bye\"\"\nend, done\",c%d\n", i, i, i%3)
+	}
+	return writeRawFile(t, "mixed.csv", []byte(b.String()))
+}
+
+// checkMixedQuotingRecord checks a record read from writeMixedQuotingCSV
+func checkMixedQuotingRecord(t *testing.T, record []string) {
+	t.Helper()
+	id, err := strconv.Atoi(record[0])
+	if err != nil || len(record) != 4 {
+		t.Fatalf("Expected a record of 4 fields starting with an id, got %q", record)
+	}
+	expected := []string{record[0], `5" screen`, fmt.Sprintf("note %d\nsays \"hi\" and \"bye\"\nend, done", id), fmt.Sprintf("c%d", id%3)}
+	if !reflect.DeepEqual(record, expected) {
+		t.Errorf("Expected %q, got %q", expected, record)
+	}
+}
+
+func TestUnescapeReader(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1,"say \"hi\""`, `1,"say ""hi"""`},
+		{`1,"C:\\dir\\"`, `1,"C:\dir\"`},
+		{`1,"mixed \"a\" and ""b"""`, `1,"mixed ""a"" and ""b"""`},
+		{`1,5\" screen,\n`, `1,5" screen,\n`},
+		{"1,\"two\nlines \\\"x\\\"\"\n2,\\\"", "1,\"two\nlines \"\"x\"\"\"\n2,\""},
+	}
+	for _, tt := range tests {
+		// One byte at a time, so escapes that grow are split across reads
+		got, err := io.ReadAll(iotest.OneByteReader(newUnescapeReader(strings.NewReader(tt.input), ',')))
+		if err != nil {
+			t.Fatalf("ReadAll failed: %v", err)
+		}
+		if string(got) != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.input, got)
+		}
+	}
+}
+
+func TestReadTable_MixedQuoting(t *testing.T) {
+	tmpFile := writeMixedQuotingCSV(t, 50)
+	reader := &CSVReader{Delimiter: ',', LazyQuotes: true, Escape: EscapeBackslash}
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if stats.RowCount != 50 {
+		t.Errorf("Expected 50 rows, got %d", stats.RowCount)
+	}
+	if stats.DistinctCounts["code"] != 3 || stats.DistinctCounts["size"] != 1 {
+		t.Errorf("Expected 3 codes and 1 size, got %d and %d", stats.DistinctCounts["code"], stats.DistinctCounts["size"])
+	}
+
+	sample, err := reader.ReadSample(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadSample failed: %v", err)
+	}
+	if sample.Len() != 50 {
+		t.Fatalf("Expected 50 records, got %d", sample.Len())
+	}
+	for _, record := range sample.Records() {
+		checkMixedQuotingRecord(t, record)
+	}
+}
+
+func TestReadSample_MixedQuotingSampled(t *testing.T) {
+	tmpFile := writeMixedQuotingCSV(t, 20000)
+	reader := &CSVReader{Delimiter: ',', LazyQuotes: true, Escape: EscapeBackslash}
+	config := SamplingConfig{MaxFileSize: 64 * 1024, SampleSize: 500, RandomPositions: 50}
+
+	// Random positions mostly land inside the multi-line notes, which must
+	// not desynchronise the parser and fail the read
+	sample, err := reader.ReadSample(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadSample failed: %v", err)
+	}
+	if sample.Exact {
+		t.Fatal("Expected the file to be sampled")
+	}
+	if sample.Len() < config.SampleSize/2 {
+		t.Errorf("Expected at least %d records, got %d", config.SampleSize/2, sample.Len())
+	}
+	for _, record := range sample.Records() {
+		checkMixedQuotingRecord(t, record)
+	}
+
+	// Lines are counted three to a record
+	if sample.EstimatedRows < 18000 || sample.EstimatedRows > 22000 {
+		t.Errorf("Expected about 20000 estimated rows, got %d", sample.EstimatedRows)
+	}
+}
+
+// forEachReadPath runs fn as a subtest for each way a full read parses
+// records: plain dialects are split into bytes, others read with encoding/csv.
+// A comment character is enough to leave the byte splitter.
+func forEachReadPath(t *testing.T, fn func(t *testing.T, reader *CSVReader)) {
+	t.Helper()
+	for _, path := range []struct {
+		name   string
+		reader *CSVReader
+	}{
+		{"split", NewCSVReader(',')},
+		{"encoding/csv", &CSVReader{Delimiter: ',', Comment: '#'}},
+	} {
+		t.Run(path.name, func(t *testing.T) { fn(t, path.reader) })
+	}
+}
+
+func TestReadTable_RaggedRows(t *testing.T) {
+	csvContent := "id,name,score\n1,a,10\n2,b\n3,c,30,extra\n4,d,40\n5\n"
+	tmpFile := writeRawFile(t, "test.csv", []byte(csvContent))
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+	expected := &RaggedRows{Rows: 5, Short: 2, Long: 1, Percentage: 60, ShortLines: []int{3, 6}, LongLines: []int{4}}
+
+	forEachReadPath(t, func(t *testing.T, reader *CSVReader) {
+		stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+		if err != nil {
+			t.Fatalf("ReadTable failed: %v", err)
+		}
+		if stats.RowCount != 5 {
+			t.Errorf("Expected 5 rows, got %d", stats.RowCount)
+		}
+		if !reflect.DeepEqual(stats.RaggedRows, expected) {
+			t.Errorf("Expected ragged rows %+v, got %+v", expected, stats.RaggedRows)
+		}
+		if stats.NullCounts["score"] != 2 {
+			t.Errorf("Expected the 2 short rows to have null scores, got %d", stats.NullCounts["score"])
+		}
+	})
+
+	sample, err := NewCSVReader(',').ReadSample(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadSample failed: %v", err)
+	}
+	if !reflect.DeepEqual(sample.Ragged, expected) {
+		t.Errorf("Expected sample ragged rows %+v, got %+v", expected, sample.Ragged)
+	}
+
+	// Rows skipped by the offset are not checked
+	config.Offset, config.Limit = 1, 2
+	stats, err := NewCSVReader(',').ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	expected = &RaggedRows{Rows: 2, Short: 1, Long: 1, Percentage: 100, ShortLines: []int{3}, LongLines: []int{4}}
+	if !reflect.DeepEqual(stats.RaggedRows, expected) {
+		t.Errorf("Expected ragged rows %+v, got %+v", expected, stats.RaggedRows)
+	}
+
+	var out bytes.Buffer
+	if err := (&TextRenderer{}).Render(&out, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if want := "Ragged Rows: 2 of 2 rows (100.00%): 1 short (line 3), 1 long (line 4)\n"; !strings.Contains(out.String(), want) {
+		t.Errorf("Expected report to contain %q, got:\n%s", want, out.String())
+	}
+
+	config.Offset, config.Limit = 0, 0
+	stats, err = NewCSVReader(',').ReadTable(context.Background(), createTempCSV(t, "id,name\n1,a\n2,b", ','), config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if stats.RaggedRows != nil {
+		t.Errorf("Expected no ragged rows, got %+v", stats.RaggedRows)
+	}
+}
+
+func TestReadTableFrom(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("id,value\n")
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&content, "%d,%d\n", i, i*10)
+	}
+	data := content.String()
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+
+	// Seekable in-memory buffer with a known size
+	stats, err := reader.ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadTableFrom failed: %v", err)
+	}
+	if stats.EstimatedRows != 100 {
+		t.Errorf("Expected 100 rows, got %d", stats.EstimatedRows)
+	}
+
+	// Stream of unknown size larger than the sample keeps an exact row count
+	config.SampleSize = 10
+	sample, err := reader.ReadSampleFrom(context.Background(), io.MultiReader(strings.NewReader(data)), -1, config)
+	if err != nil {
+		t.Fatalf("ReadSampleFrom failed: %v", err)
+	}
+	if sample.Len() != 10 {
+		t.Errorf("Expected 10 sampled rows, got %d", sample.Len())
+	}
+	if sample.EstimatedRows != 100 {
+		t.Errorf("Expected 100 estimated rows, got %d", sample.EstimatedRows)
+	}
+	if sample.Exact {
+		t.Error("Expected reservoir sample not to be exact")
+	}
+	// Kept rows must not share the reader's reused record slice
+	ids := make(map[string]bool)
+	for _, record := range sample.Records() {
+		ids[record[0]] = true
+	}
+	if len(ids) != sample.Len() {
+		t.Errorf("Expected %d distinct sampled rows, got %d", sample.Len(), len(ids))
+	}
+
+	// Tail of a stream
+	config.Offset = -3
+	sample, err = reader.ReadSampleFrom(context.Background(), io.MultiReader(strings.NewReader(data)), -1, config)
+	if err != nil {
+		t.Fatalf("ReadSampleFrom failed: %v", err)
+	}
+	expected := [][]string{{"98", "980"}, {"99", "990"}, {"100", "1000"}}
+	if !reflect.DeepEqual(sample.Records(), expected) {
+		t.Errorf("Expected tail %v, got %v", expected, sample.Records())
+	}
+
+	// Offset and limit on a stream
+	config.Offset, config.Limit = 10, 2
+	sample, err = reader.ReadSampleFrom(context.Background(), io.MultiReader(strings.NewReader(data)), -1, config)
+	if err != nil {
+		t.Fatalf("ReadSampleFrom failed: %v", err)
+	}
+	expected = [][]string{{"11", "110"}, {"12", "120"}}
+	if !reflect.DeepEqual(sample.Records(), expected) {
+		t.Errorf("Expected window %v, got %v", expected, sample.Records())
+	}
+}
+
+func TestReadTable_Cancelled(t *testing.T) {
+	tmpFile := createLargeCSV(t, 5000)
+	defer os.Remove(tmpFile)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+	if _, err := reader.ReadTable(ctx, tmpFile, config); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled for a full read, got %v", err)
+	}
+
+	config.MaxFileSize = 1024
+	if _, err := reader.ReadTable(ctx, tmpFile, config); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled while sampling, got %v", err)
+	}
+}
+
+func TestReadTable_Progress(t *testing.T) {
+	tmpFile := createLargeCSV(t, 5000)
+	defer os.Remove(tmpFile)
+	fileInfo, err := os.Stat(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	var calls int
+	var lastBytes, lastTotal, lastRows int64
+	config := SamplingConfig{
+		MaxFileSize:     1024 * 1024,
+		SampleSize:      1000,
+		RandomPositions: 5,
+		Progress: func(bytesRead, totalBytes, rowsProcessed int64) {
+			calls++
+			if bytesRead < lastBytes || rowsProcessed < lastRows {
+				t.Errorf("Expected progress to be monotonic, got %d bytes/%d rows after %d/%d",
+					bytesRead, rowsProcessed, lastBytes, lastRows)
+			}
+			lastBytes, lastTotal, lastRows = bytesRead, totalBytes, rowsProcessed
+		},
+	}
+
+	reader := NewCSVReader(',')
+	if _, err := reader.ReadTable(context.Background(), tmpFile, config); err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if calls < 2 {
+		t.Errorf("Expected several progress calls, got %d", calls)
+	}
+	if lastTotal != fileInfo.Size() || lastBytes != fileInfo.Size() {
+		t.Errorf("Expected final progress %d/%d bytes, got %d/%d",
+			fileInfo.Size(), fileInfo.Size(), lastBytes, lastTotal)
+	}
+	if lastRows != 5000 {
+		t.Errorf("Expected 5000 rows processed, got %d", lastRows)
+	}
+}
+
+func TestReadTable_StreamsFullRead(t *testing.T) {
+	tmpFile := createLargeCSV(t, 2000)
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5, SampleRows: 3}
+
+	streamed, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	sample, err := reader.ReadSample(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadSample failed: %v", err)
+	}
+
+	expected := AnalyzeSample(sample, config)
+	if !reflect.DeepEqual(streamed, expected) {
+		t.Errorf("Expected streamed stats to match the materialized analysis\ngot:  %+v\nwant: %+v", streamed, expected)
+	}
+	if streamed.RowCount != 2000 || streamed.EstimatedRows != 2000 {
+		t.Errorf("Expected 2000 rows, got %d (estimated %d)", streamed.RowCount, streamed.EstimatedRows)
+	}
+}
+
+func BenchmarkReadTable_Full(b *testing.B) {
+	var content strings.Builder
+	content.WriteString("id,name,value,category\n")
+	for i := 0; i < 50000; i++ {
+		fmt.Fprintf(&content, "%d,name_%d,%d.5,cat_%d\n", i, i, i*3, i%10)
+	}
+	data := content.String()
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: int64(len(data)), SampleSize: 1000, RandomPositions: 5}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := reader.ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestReadTable_NoHeader(t *testing.T) {
+	data := "1,a\n2,b\n3,c\n"
+	reader := &CSVReader{Delimiter: ',', NoHeader: true}
+	for name, rd := range map[string]func() io.Reader{
+		"seekable": func() io.Reader { return strings.NewReader(data) },
+		"stream":   func() io.Reader { return io.MultiReader(strings.NewReader(data)) },
+	} {
+		stats, err := reader.ReadTableFrom(context.Background(), rd(), int64(len(data)), DefaultSamplingConfig())
+		if err != nil {
+			t.Fatalf("%s: ReadTableFrom failed: %v", name, err)
+		}
+		if stats.RowCount != 3 || !reflect.DeepEqual(stats.ColumnNames, []string{"column_1", "column_2"}) {
+			t.Errorf("%s: expected 3 rows of column_1 and column_2, got %d of %v", name, stats.RowCount, stats.ColumnNames)
+		}
+		if stats.MinValues["column_1"] != int64(1) || len(stats.RenamedColumns) > 0 {
+			t.Errorf("%s: expected the first row to be profiled without renames, got min %v and %v", name, stats.MinValues["column_1"], stats.RenamedColumns)
+		}
+	}
+
+	config := DefaultSamplingConfig()
+	config.Limit = 1
+	sample, err := reader.ReadSampleFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadSampleFrom failed: %v", err)
+	}
+	if !reflect.DeepEqual(sample.Records(), [][]string{{"1", "a"}}) {
+		t.Errorf("Expected the first row in the window, got %v", sample.Records())
+	}
+}
+
+func TestReadTable_NoHeaderSampled(t *testing.T) {
+	var content strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&content, "%d,%d\n", i, i%7)
+	}
+	data := content.String()
+	reader := &CSVReader{Delimiter: ',', NoHeader: true}
+	config := SamplingConfig{MaxFileSize: 1024, SampleSize: 500, RandomPositions: 5}
+	stats, err := reader.ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadTableFrom failed: %v", err)
+	}
+	if stats.EstimatedRows != 20000 {
+		t.Errorf("Expected 20000 estimated rows, got %d", stats.EstimatedRows)
+	}
+}
@@ -0,0 +1,101 @@
+package tablestats
+
+import "fmt"
+
+// DecimalShape reports the most digits the values of a numeric column have
+// before and after the decimal point, which sizes a DECIMAL(p,s) type that
+// holds them all
+type DecimalShape struct {
+	Precision     int `json:"precision"`      // Total digits, IntegerDigits plus Scale and at least 1
+	Scale         int `json:"scale"`          // Most digits after the decimal point, trailing zeros included
+	IntegerDigits int `json:"integer_digits"` // Most digits before the decimal point, leading zeros excluded
+}
+
+// DDL returns the column type, e.g. "DECIMAL(7,2)"
+func (d *DecimalShape) DDL() string {
+	return fmt.Sprintf("DECIMAL(%d,%d)", d.Precision, d.Scale)
+}
+
+// observeDecimal widens d to hold value, a number as written in the file.
+// Exponents are applied, so 1.5e3 has 4 integer digits; hex numbers and
+// non-finite values are ignored.
+func observeDecimal[T fieldValue](d *DecimalShape, value T) {
+	intDigits, scale, ok := decimalDigits(value)
+	if !ok {
+		return
+	}
+	d.IntegerDigits = max(d.IntegerDigits, intDigits)
+	d.Scale = max(d.Scale, scale)
+	d.Precision = max(d.IntegerDigits+d.Scale, 1)
+}
+
+// decimalDigits returns the number of significant integer digits and of
+// decimal places of a decimal number
+func decimalDigits[T fieldValue](value T) (intDigits, scale int, ok bool) {
+	i := 0
+	if i < len(value) && (value[i] == '+' || value[i] == '-') {
+		i++
+	}
+
+	// point is the position of the decimal point among the digits, and
+	// leading the number of zeros the digits start with
+	digits, point, leading := 0, 0, 0
+	dot := false
+	for ; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= '0' && c <= '9':
+			if c == '0' && digits == leading {
+				leading++
+			}
+			digits++
+		case c == '.' && !dot:
+			point, dot = digits, true
+		case c == 'e' || c == 'E':
+			exp, ok := parseExponent(value[i+1:])
+			if !ok || digits == 0 {
+				return 0, 0, false
+			}
+			if !dot {
+				point, dot = digits, true
+			}
+			point += exp
+			i = len(value)
+		default:
+			return 0, 0, false
+		}
+	}
+	if digits == 0 {
+		return 0, 0, false
+	}
+	if !dot {
+		point = digits
+	}
+	return max(point-leading, 0), max(digits-point, 0), true
+}
+
+// parseExponent parses the signed decimal exponent of a number, bounded so
+// that it cannot overflow
+func parseExponent[T fieldValue](value T) (int, bool) {
+	i, sign := 0, 1
+	if i < len(value) && (value[i] == '+' || value[i] == '-') {
+		if value[i] == '-' {
+			sign = -1
+		}
+		i++
+	}
+	if i == len(value) {
+		return 0, false
+	}
+	exp := 0
+	for ; i < len(value); i++ {
+		c := value[i]
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		if exp < 10000 {
+			exp = exp*10 + int(c-'0')
+		}
+	}
+	return sign * exp, true
+}
@@ -0,0 +1,66 @@
+package tablestats
+
+import "testing"
+
+func TestDecimalDigits(t *testing.T) {
+	tests := []struct {
+		value     string
+		intDigits int
+		scale     int
+		ok        bool
+	}{
+		{"12345", 5, 0, true},
+		{"-12.50", 2, 2, true},
+		{"+0.05", 0, 2, true},
+		{"007", 1, 0, true},
+		{"100", 3, 0, true},
+		{".5", 0, 1, true},
+		{"5.", 1, 0, true},
+		{"0", 0, 0, true},
+		{"1.5e3", 4, 0, true},
+		{"1.25E-2", 0, 4, true},
+		{"12e+2", 4, 0, true},
+		{"NaN", 0, 0, false},
+		{"Inf", 0, 0, false},
+		{"0x1p-2", 0, 0, false},
+		{"1e", 0, 0, false},
+		{"1_000", 0, 0, false},
+	}
+	for _, tt := range tests {
+		intDigits, scale, ok := decimalDigits(tt.value)
+		if intDigits != tt.intDigits || scale != tt.scale || ok != tt.ok {
+			t.Errorf("decimalDigits(%q): expected %d, %d, %v, got %d, %d, %v",
+				tt.value, tt.intDigits, tt.scale, tt.ok, intDigits, scale, ok)
+		}
+	}
+}
+
+func TestTableAccumulator_Decimals(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"price", "qty", "zero", "code"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range [][]string{
+		{"19.99", "3", "0", "17"},
+		{"1234.5", "120", "", "A4"},
+		{"-0.125", "NaN", "0", "9"},
+	} {
+		acc.Add(record)
+	}
+
+	stats := acc.Finalize()
+	if d := stats.Decimals["price"]; d == nil || *d != (DecimalShape{Precision: 7, Scale: 3, IntegerDigits: 4}) {
+		t.Errorf("Expected price DECIMAL(7,3) with 4 integer digits, got %+v", d)
+	} else if d.DDL() != "DECIMAL(7,3)" {
+		t.Errorf("Expected DECIMAL(7,3), got %s", d.DDL())
+	}
+	if d := stats.Decimals["qty"]; d == nil || *d != (DecimalShape{Precision: 3, Scale: 0, IntegerDigits: 3}) {
+		t.Errorf("Expected qty DECIMAL(3,0), got %+v", d)
+	}
+	if d := stats.Decimals["zero"]; d == nil || d.Precision != 1 {
+		t.Errorf("Expected zero DECIMAL(1,0), got %+v", d)
+	}
+	if d, ok := stats.Decimals["code"]; ok {
+		t.Errorf("Expected no precision for a string column, got %+v", d)
+	}
+}
@@ -0,0 +1,277 @@
+package tablestats
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// cancelCheckInterval is how many rows are read between context checks and progress reports
+const cancelCheckInterval = 1024
+
+// recordReader wraps csv.Reader to support dialect options encoding/csv lacks.
+// A custom quote character is handled by swapping it with '"' in the input
+// stream and swapping the two back in every parsed field. Reading stops with
+// the context's error once it is cancelled.
+type recordReader struct {
+	*csv.Reader
+	quote    byte // 0 when the standard '"' quote is used
+	ctx      context.Context
+	rows     int
+	progress *progressReporter  // nil when progress is not reported
+	tracked  bool               // rows counts records from the start of the input
+	lineBase int                // Lines before the input, when it starts mid-file
+	fields   []int              // Header positions Read returns, nil for all
+	ragged   *raggedCounter     // Counts rows not as wide as the header, may be nil
+	errs     *parseErrorCounter // Skips malformed records, nil to return their errors
+}
+
+// Read returns the next record, skipping the malformed records rr.errs tolerates
+func (rr *recordReader) Read() ([]string, error) {
+	for {
+		record, err := rr.read()
+		if err == nil || err == io.EOF {
+			return record, err
+		}
+		if err := rr.errs.skip(err); err != nil {
+			return record, err
+		}
+	}
+}
+
+func (rr *recordReader) read() ([]string, error) {
+	rr.rows++
+	if rr.rows%cancelCheckInterval == 0 {
+		if err := rr.ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if rr.progress != nil && rr.rows%cancelCheckInterval == 0 {
+		rr.progress.report()
+	}
+	record, err := rr.Reader.Read()
+	if err == nil && rr.progress != nil {
+		rr.progress.rows++
+	}
+	if err == nil && rr.ragged != nil {
+		line := 0
+		if rr.tracked {
+			line, _ = rr.FieldPos(0)
+			line += rr.lineBase
+		}
+		rr.ragged.observe(len(record), line)
+	}
+	if err != nil && err != io.EOF {
+		row := int64(0)
+		if rr.tracked {
+			row = int64(rr.rows)
+		}
+		return record, wrapParseError(err, row)
+	}
+	if rr.fields != nil && err == nil {
+		return rr.project(record), nil
+	}
+	if rr.quote != 0 {
+		for i, field := range record {
+			record[i] = swapBytes(field, rr.quote, '"')
+		}
+	}
+	return record, err
+}
+
+// project copies the selected fields out of record. The copies do not share
+// memory with the parsed line, so the unselected fields can be freed.
+func (rr *recordReader) project(record []string) []string {
+	row := make([]string, len(rr.fields))
+	for i, idx := range rr.fields {
+		if idx >= len(record) {
+			continue
+		}
+		if rr.quote != 0 {
+			row[i] = swapBytes(record[idx], rr.quote, '"')
+		} else {
+			row[i] = strings.Clone(record[idx])
+		}
+	}
+	return row
+}
+
+func (rr *recordReader) ReadAll() ([][]string, error) {
+	var records [][]string
+	for {
+		record, err := rr.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+// EscapeStyle is how a quote character inside a quoted field is written
+type EscapeStyle int
+
+const (
+	// EscapeDoubled writes the quote twice, as in "say ""hi""" (RFC 4180)
+	EscapeDoubled EscapeStyle = iota
+	// EscapeBackslash writes a backslash before the quote, as in "say \"hi\""
+	EscapeBackslash
+)
+
+func (e EscapeStyle) String() string {
+	if e == EscapeBackslash {
+		return "backslash"
+	}
+	return "doubled"
+}
+
+// ParseEscapeStyle parses an escape style name: doubled or backslash
+func ParseEscapeStyle(name string) (EscapeStyle, error) {
+	switch strings.ToLower(name) {
+	case "", "doubled":
+		return EscapeDoubled, nil
+	case "backslash":
+		return EscapeBackslash, nil
+	}
+	return 0, fmt.Errorf("unknown escape style %q (want doubled or backslash)", name)
+}
+
+// unescapeReader rewrites backslash escapes to the doubled quotes encoding/csv
+// parses. Inside quoted fields \" becomes "" and \\ becomes \; outside them
+// \" becomes a bare quote, which needs LazyQuotes. Other backslashes are kept.
+// The input is read after any quote swap, so the quote is always '"'.
+type unescapeReader struct {
+	r          *bufio.Reader
+	delim      byte
+	inQuotes   bool
+	fieldStart bool
+	pending    []byte // Output that did not fit in the last Read
+}
+
+func newUnescapeReader(rd io.Reader, delim byte) *unescapeReader {
+	return &unescapeReader{r: bufio.NewReader(rd), delim: delim, fieldStart: true}
+}
+
+func (u *unescapeReader) Read(p []byte) (int, error) {
+	n := copy(p, u.pending)
+	u.pending = u.pending[n:]
+	emit := func(c byte) {
+		if n < len(p) {
+			p[n] = c
+			n++
+		} else {
+			u.pending = append(u.pending, c)
+		}
+	}
+	for n < len(p) {
+		c, err := u.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		next := byte(0)
+		if peek, _ := u.r.Peek(1); len(peek) == 1 {
+			next = peek[0]
+		}
+
+		switch {
+		case c == '\\' && next == '"':
+			u.r.ReadByte()
+			emit('"')
+			if u.inQuotes {
+				emit('"')
+			}
+			u.fieldStart = false
+			continue
+		case c == '\\' && next == '\\' && u.inQuotes:
+			u.r.ReadByte()
+			emit('\\')
+			continue
+		case u.inQuotes && c == '"' && next == '"':
+			// A doubled quote, in a file mixing both styles
+			u.r.ReadByte()
+			emit('"')
+			emit('"')
+			continue
+		case u.inQuotes && c == '"':
+			u.inQuotes = false
+		case c == '"' && u.fieldStart:
+			u.inQuotes = true
+		}
+		emit(c)
+		u.fieldStart = !u.inQuotes && (c == u.delim || c == '\n' || c == '\r')
+	}
+	return n, nil
+}
+
+// validateDialect checks the quote and comment characters of the reader
+func (r *CSVReader) validateDialect() error {
+	if r.Quote != 0 && r.Quote != '"' {
+		if r.Quote > 127 {
+			return fmt.Errorf("quote character %q must be ASCII", r.Quote)
+		}
+		if r.Quote == r.Delimiter || r.Quote == r.Comment {
+			return fmt.Errorf("quote character %q conflicts with the delimiter or comment character", r.Quote)
+		}
+	}
+	return nil
+}
+
+// swapReader exchanges two bytes throughout the stream
+type swapReader struct {
+	r    io.Reader
+	a, b byte
+}
+
+func (s *swapReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	for i := 0; i < n; i++ {
+		switch p[i] {
+		case s.a:
+			p[i] = s.b
+		case s.b:
+			p[i] = s.a
+		}
+	}
+	return n, err
+}
+
+// swapBufPool holds scratch buffers for swapBytes
+var swapBufPool = sync.Pool{
+	New: func() any { return new([]byte) },
+}
+
+func swapBytes(s string, a, b byte) string {
+	if strings.IndexByte(s, a) < 0 && strings.IndexByte(s, b) < 0 {
+		return s
+	}
+	bp := swapBufPool.Get().(*[]byte)
+	buf := append((*bp)[:0], s...)
+	for i := range buf {
+		switch buf[i] {
+		case a:
+			buf[i] = b
+		case b:
+			buf[i] = a
+		}
+	}
+	result := string(buf)
+	*bp = buf
+	swapBufPool.Put(bp)
+	return result
+}
+
+// keepRecord copies a record that is retained past the next Read into dst,
+// reusing its backing array. Readers with ReuseRecord set overwrite the slice
+// they return on every call.
+func keepRecord(dst, record []string) []string {
+	return append(dst[:0], record...)
+}
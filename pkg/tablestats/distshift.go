@@ -0,0 +1,133 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// DefaultKSAlpha is the KS p-value below which DetectDrift reports a numeric
+// column's distribution as changed
+const DefaultKSAlpha = 0.001
+
+// DefaultPSI is the population stability index above which DetectDrift
+// reports a numeric column's distribution as changed; 0.25 is the usual mark
+// of a significant shift
+const DefaultPSI = 0.25
+
+// psiBins is the number of baseline quantile bins the PSI is computed over
+const psiBins = 10
+
+// psiFloor stands in for the share of an empty bin, whose logarithm is undefined
+const psiFloor = 1e-4
+
+// DistributionShift compares the distribution of a numeric column in two
+// profiles. Both are rebuilt from the profiles' Quantiles, so the statistics
+// resolve differences down to about one percentile: the KS p-value only counts
+// the gap beyond a percentile, and the sizes of the samples are capped at the
+// values the quantiles were estimated from.
+type DistributionShift struct {
+	Column   string
+	KS       float64 // Two-sample Kolmogorov-Smirnov statistic: the largest gap between the two CDFs
+	KSPValue float64 // Asymptotic chance of a gap this large between samples of one distribution
+	PSI      float64 // Population stability index over the baseline's deciles
+}
+
+// compareDistributions returns the shift of a column from old to new, or false
+// when either profile has no quantiles for it
+func compareDistributions(column string, old, new *AggregateStats) (DistributionShift, bool) {
+	if old == nil || new == nil || len(old.Quantiles) < 2 || len(new.Quantiles) < 2 {
+		return DistributionShift{}, false
+	}
+	a, b := quantileCDF(old.Quantiles), quantileCDF(new.Quantiles)
+
+	var d float64
+	for _, knots := range [][]float64{a.xs, b.xs} {
+		for _, x := range knots {
+			d = max(d, math.Abs(a.at(x)-b.at(x)), math.Abs(a.before(x)-b.before(x)))
+		}
+	}
+
+	// The quantiles place each value's share on a percentile, rounding it by
+	// up to a step either way, so only the gap beyond one step is evidence
+	pValue := 1.0
+	step := 1 / float64(min(len(old.Quantiles), len(new.Quantiles))-1)
+	if old.Count > 0 && new.Count > 0 {
+		pValue = ksPValue(max(d-step, 0), int(min(old.Count, exactQuantileLimit)), int(min(new.Count, exactQuantileLimit)))
+	}
+
+	// Bins between the baseline's deciles; repeated deciles leave empty bins
+	psi := 0.0
+	prevA, prevB := 0.0, 0.0
+	for k := 1; k <= psiBins; k++ {
+		shareA, shareB := 1.0, 1.0
+		if k < psiBins {
+			edge := a.quantile(float64(k) / psiBins)
+			shareA, shareB = a.at(edge), b.at(edge)
+		}
+		expected, actual := shareA-prevA, shareB-prevB
+		prevA, prevB = shareA, shareB
+		if expected <= 0 && actual <= 0 {
+			continue
+		}
+		expected, actual = max(expected, psiFloor), max(actual, psiFloor)
+		psi += (actual - expected) * math.Log(actual/expected)
+	}
+
+	return DistributionShift{Column: column, KS: d, KSPValue: pValue, PSI: psi}, true
+}
+
+// piecewiseCDF is a CDF that is linear between knots. Knots at the same
+// value are a jump, as for a value that fills several percentiles.
+type piecewiseCDF struct {
+	xs []float64 // Ascending
+	ps []float64 // Share of values up to xs[i], ascending from 0 to 1
+}
+
+// quantileCDF builds the CDF of values at evenly spaced percentiles
+func quantileCDF(quantiles []float64) piecewiseCDF {
+	ps := make([]float64, len(quantiles))
+	for i := range ps {
+		ps[i] = float64(i) / float64(len(quantiles)-1)
+	}
+	return piecewiseCDF{xs: quantiles, ps: ps}
+}
+
+// at returns the share of values up to and including x
+func (c piecewiseCDF) at(x float64) float64 {
+	return c.interpolate(x, sort.Search(len(c.xs), func(i int) bool { return c.xs[i] > x }))
+}
+
+// before returns the share of values below x
+func (c piecewiseCDF) before(x float64) float64 {
+	return c.interpolate(x, sort.Search(len(c.xs), func(i int) bool { return c.xs[i] >= x }))
+}
+
+// interpolate evaluates the CDF at x, which lies between knots next-1 and next
+func (c piecewiseCDF) interpolate(x float64, next int) float64 {
+	switch {
+	case next == 0:
+		return 0
+	case next == len(c.xs):
+		return 1
+	}
+	lo, hi := next-1, next
+	return lerp(c.ps[lo], c.ps[hi], (x-c.xs[lo])/(c.xs[hi]-c.xs[lo]))
+}
+
+// quantile returns the smallest value the CDF reaches p at
+func (c piecewiseCDF) quantile(p float64) float64 {
+	i := sort.SearchFloat64s(c.ps, p)
+	if i == 0 {
+		return c.xs[0]
+	}
+	if i == len(c.ps) {
+		return c.xs[len(c.xs)-1]
+	}
+	return lerp(c.xs[i-1], c.xs[i], (p-c.ps[i-1])/(c.ps[i]-c.ps[i-1]))
+}
+
+// describe summarizes the shift in one line
+func (s DistributionShift) describe() string {
+	return fmt.Sprintf("KS %.3f (p=%.2g), PSI %.3f", s.KS, s.KSPValue, s.PSI)
+}
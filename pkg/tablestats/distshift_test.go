@@ -0,0 +1,133 @@
+package tablestats
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// sampleAggregates returns the aggregates of n values drawn by draw
+func sampleAggregates(seed int64, n int, draw func(r *rand.Rand) float64) *AggregateStats {
+	r := rand.New(rand.NewSource(seed))
+	var summary numericSummary
+	for i := 0; i < n; i++ {
+		summary.add(draw(r))
+	}
+	return summary.aggregates()
+}
+
+func TestCompareDistributions(t *testing.T) {
+	normal := func(r *rand.Rand) float64 { return 100 + 10*r.NormFloat64() }
+	base := sampleAggregates(1, 20000, normal)
+	if len(base.Quantiles) != quantilePoints || base.Quantiles[0] > base.Quantiles[100] {
+		t.Fatalf("Expected %d ascending quantiles, got %v", quantilePoints, base.Quantiles)
+	}
+
+	// Another sample of the same distribution
+	shift, ok := compareDistributions("x", base, sampleAggregates(2, 20000, normal))
+	if !ok {
+		t.Fatal("Expected the distributions to be compared")
+	}
+	if shift.KSPValue < DefaultKSAlpha || shift.PSI > 0.05 {
+		t.Errorf("Expected no shift between samples of one distribution, got %+v", shift)
+	}
+
+	// A shift of a fifth of a standard deviation
+	shift, _ = compareDistributions("x", base, sampleAggregates(3, 20000, func(r *rand.Rand) float64 { return normal(r) + 2 }))
+	if shift.KSPValue > 1e-6 || math.Abs(shift.KS-0.08) > 0.02 {
+		t.Errorf("Expected a KS of about 0.08 with a tiny p-value, got %+v", shift)
+	}
+
+	// The same mean and deviation in another shape, which the means cannot tell apart
+	half := 10 * math.Sqrt(3)
+	uniform := sampleAggregates(4, 20000, func(r *rand.Rand) float64 { return 100 - half + 2*half*r.Float64() })
+	if math.Abs(uniform.Mean-base.Mean) > 0.5 || math.Abs(uniform.StdDev-base.StdDev) > 0.5 {
+		t.Fatalf("Expected matching mean and deviation, got %v/%v and %v/%v", base.Mean, base.StdDev, uniform.Mean, uniform.StdDev)
+	}
+	shift, _ = compareDistributions("x", base, uniform)
+	if shift.KSPValue > 1e-6 {
+		t.Errorf("Expected the change of shape to be significant, got %+v", shift)
+	}
+
+	// A move to other values entirely
+	shift, _ = compareDistributions("x", base, sampleAggregates(5, 2000, func(r *rand.Rand) float64 { return normal(r) + 100 }))
+	if shift.KS != 1 || shift.PSI < DefaultPSI {
+		t.Errorf("Expected a KS of 1 and a large PSI, got %+v", shift)
+	}
+
+	// Profiles saved without quantiles are not compared
+	if _, ok := compareDistributions("x", &AggregateStats{Count: 10}, base); ok {
+		t.Error("Expected no comparison without quantiles")
+	}
+}
+
+func TestCompareDistributions_PointMass(t *testing.T) {
+	// Half zeros, half ones against a quarter zeros
+	base := sampleAggregates(1, 1000, func(r *rand.Rand) float64 { return float64(r.Intn(2)) })
+	current := sampleAggregates(2, 1000, func(r *rand.Rand) float64 { return float64(min(r.Intn(4), 1)) })
+
+	cdf := quantileCDF(base.Quantiles)
+	if cdf.before(0) != 0 || cdf.at(1) != 1 || cdf.at(0) < 0.45 || cdf.at(0) > 0.55 {
+		t.Errorf("Expected a jump at 0 and 1, got F(0)=%v", cdf.at(0))
+	}
+	shift, _ := compareDistributions("x", base, current)
+	if math.Abs(shift.KS-0.25) > 0.05 || shift.KSPValue > 1e-6 {
+		t.Errorf("Expected a KS of about 0.25, got %+v", shift)
+	}
+	if shift.PSI < 0.1 {
+		t.Errorf("Expected the PSI to see the move, got %+v", shift)
+	}
+}
+
+func TestCompare_Shifts(t *testing.T) {
+	profile := func(agg *AggregateStats) *TableStats {
+		return &TableStats{
+			ColumnNames: []string{"amount"},
+			ColumnTypes: map[string]string{"amount": "float64"},
+			Aggregates:  map[string]*AggregateStats{"amount": agg},
+		}
+	}
+	old := profile(sampleAggregates(1, 5000, func(r *rand.Rand) float64 { return r.ExpFloat64() }))
+	new := profile(sampleAggregates(2, 5000, func(r *rand.Rand) float64 { return 1.3 * r.ExpFloat64() }))
+
+	c := Compare(old, new)
+	if len(c.Shifts) != 1 || c.Shifts[0].Column != "amount" {
+		t.Fatalf("Expected a shift of amount, got %v", c.Shifts)
+	}
+	if c.Failed(CompareThresholds{}) {
+		t.Error("Expected no failure without distribution thresholds")
+	}
+	th := CompareThresholds{KSAlpha: 0.01}
+	if !c.Failed(th) {
+		t.Errorf("Expected the KS p-value %v to fail at 0.01", c.Shifts[0].KSPValue)
+	}
+	if failures := c.Failures(th); len(failures) != 1 || !strings.HasPrefix(failures[0], "amount distribution shifted: KS ") {
+		t.Errorf("Unexpected failures %v", failures)
+	}
+	if th := (CompareThresholds{PSI: 10}); c.Failed(th) {
+		t.Errorf("Expected a PSI of %v to pass 10", c.Shifts[0].PSI)
+	}
+}
+
+func TestCompareDistributions_Resample(t *testing.T) {
+	// Quantiles round the share of each value to a percentile, so samples of
+	// one distribution differ by up to a percentile however large they are
+	draws := map[string]func(r *rand.Rand) float64{
+		"discrete": func(r *rand.Rand) float64 { return float64(r.Intn(5)) },
+		"skewed":   func(r *rand.Rand) float64 { return float64(min(r.Intn(9), 4)) },
+		"normal":   func(r *rand.Rand) float64 { return r.NormFloat64() },
+	}
+	for name, draw := range draws {
+		base := sampleAggregates(1, 300000, draw)
+		if shift, _ := compareDistributions("x", base, base); shift.KS != 0 || shift.KSPValue != 1 || shift.PSI != 0 {
+			t.Errorf("%s: Expected a profile not to shift against itself, got %+v", name, shift)
+		}
+		for seed := int64(2); seed < 12; seed++ {
+			shift, _ := compareDistributions("x", base, sampleAggregates(seed, 300000, draw))
+			if shift.KSPValue < 0.05 || shift.PSI > 0.01 {
+				t.Errorf("%s: Expected no shift against resample %d, got %+v", name, seed, shift)
+			}
+		}
+	}
+}
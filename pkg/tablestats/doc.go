@@ -0,0 +1,22 @@
+// Package tablestats profiles delimited tables (CSV, TSV and similar) without
+// loading them fully into memory. Small files are read completely; larger ones
+// are sampled from random positions and the row count is estimated.
+//
+// A typical caller creates a reader and profiles a file:
+//
+//	reader := tablestats.NewCSVReader(',')
+//	stats, err := reader.ReadTable(ctx, "data.csv", tablestats.DefaultSamplingConfig())
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Println(stats.EstimatedRows, stats.ColumnTypes)
+//
+// Readers that also implement SampleReader expose the raw sampled rows, which
+// can be profiled with AnalyzeSample, checked against a Schema with Validate,
+// or combined across files with MergeSamples.
+//
+// Compatibility: the exported identifiers of this package follow semantic
+// versioning. Within a major version fields and functions are only added,
+// never removed or changed in meaning. The Print* helpers produce human
+// readable output whose exact format is not part of that guarantee.
+package tablestats
@@ -0,0 +1,213 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// DefaultDriftStdErrors is the number of standard errors a metric must move by
+// before DetectDrift reports it
+const DefaultDriftStdErrors = 3.0
+
+// Drift is a single significant change of a profile against its baseline
+type Drift struct {
+	Column string
+	Kind   string   // added, removed, type, null_pct, mean, distribution or new_values
+	Detail string   // Human readable description of the change
+	Score  float64  // Standard errors moved, for null_pct and mean
+	Values []string // The values listed in Detail, for new_values
+}
+
+// DriftReport lists the significant changes of a profile against its baseline
+type DriftReport struct {
+	Drifts []Drift
+}
+
+// DriftThresholds set how large a change DetectDriftWith reports
+type DriftThresholds struct {
+	StdErrors float64 // Standard errors a null share or mean must move by
+	KSAlpha   float64 // KS p-value a numeric column's distribution must fall below (0 = unchecked)
+	PSI       float64 // Population stability index a numeric column must exceed (0 = unchecked)
+}
+
+// DetectDrift reports how current differs from baseline. Null percentages and
+// means drift when they move by more than stdErrors standard errors, so small
+// samples need larger changes. The distributions of numeric columns drift when
+// their KS p-value is below DefaultKSAlpha or their PSI above DefaultPSI. New
+// values are only detected for columns the baseline lists in Categories.
+func DetectDrift(baseline, current *TableStats, stdErrors float64) *DriftReport {
+	return DetectDriftWith(baseline, current, DriftThresholds{StdErrors: stdErrors, KSAlpha: DefaultKSAlpha, PSI: DefaultPSI})
+}
+
+// DetectDriftWith is DetectDrift with every threshold given. Distributions are
+// compared when both profiles hold Quantiles for the column.
+func DetectDriftWith(baseline, current *TableStats, th DriftThresholds) *DriftReport {
+	report := &DriftReport{}
+	add := func(column, kind string, score float64, format string, args ...any) {
+		report.Drifts = append(report.Drifts, Drift{
+			Column: column,
+			Kind:   kind,
+			Detail: fmt.Sprintf(format, args...),
+			Score:  score,
+		})
+	}
+
+	baseColumns := make(map[string]bool, len(baseline.ColumnNames))
+	for _, name := range baseline.ColumnNames {
+		baseColumns[name] = true
+	}
+	curColumns := make(map[string]bool, len(current.ColumnNames))
+	for _, name := range current.ColumnNames {
+		curColumns[name] = true
+	}
+	for _, name := range baseline.ColumnNames {
+		if !curColumns[name] {
+			add(name, "removed", 0, "column is missing")
+		}
+	}
+
+	for _, name := range current.ColumnNames {
+		if !baseColumns[name] {
+			add(name, "added", 0, "column is new")
+			continue
+		}
+		oldType, newType := baseline.ColumnTypes[name], current.ColumnTypes[name]
+		if oldType != newType {
+			add(name, "type", 0, "type changed from %s to %s", oldType, newType)
+		}
+
+		oldNull, newNull := baseline.NullPercentage[name], current.NullPercentage[name]
+		if z := proportionZ(oldNull/100, baseline.RowCount, newNull/100, current.RowCount); math.Abs(z) > th.StdErrors {
+			add(name, "null_pct", z, "null share moved from %.2f%% to %.2f%%", oldNull, newNull)
+		}
+
+		oldAgg, newAgg := baseline.Aggregates[name], current.Aggregates[name]
+		if oldAgg != nil && newAgg != nil && oldType == newType {
+			if z := meanZ(oldAgg, newAgg); math.Abs(z) > th.StdErrors {
+				add(name, "mean", z, "mean moved from %.4g to %.4g", oldAgg.Mean, newAgg.Mean)
+			}
+			if shift, ok := compareDistributions(name, oldAgg, newAgg); ok &&
+				((th.KSAlpha > 0 && shift.KSPValue < th.KSAlpha) || (th.PSI > 0 && shift.PSI > th.PSI)) {
+				add(name, "distribution", 0, "%s", shift.describe())
+			}
+		}
+
+		if known, ok := baseline.Categories[name]; ok && newType == "string" {
+			if values, ok := current.Categories[name]; ok {
+				if unseen := newValues(known, values); len(unseen) > 0 {
+					add(name, "new_values", 0, "new values %s", quoteJoin(unseen))
+					report.Drifts[len(report.Drifts)-1].Values = unseen
+				}
+			} else if int(current.DistinctCounts[name]) > len(known) {
+				add(name, "new_values", 0, "%d distinct values, baseline had %d",
+					current.DistinctCounts[name], len(known))
+			}
+		}
+	}
+
+	return report
+}
+
+// proportionZ is the two-proportion z statistic of p2 against p1. Shares that
+// differ while the pooled share leaves no variance are infinitely significant.
+func proportionZ(p1 float64, n1 int64, p2 float64, n2 int64) float64 {
+	if n1 == 0 || n2 == 0 || p1 == p2 {
+		return 0
+	}
+	pooled := (p1*float64(n1) + p2*float64(n2)) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return math.Copysign(math.Inf(1), p2-p1)
+	}
+	return (p2 - p1) / se
+}
+
+// meanZ is the Welch z statistic of the new mean against the old one
+func meanZ(old, new *AggregateStats) float64 {
+	if old.Count == 0 || new.Count == 0 || old.Mean == new.Mean {
+		return 0
+	}
+	se := math.Sqrt(old.Variance/float64(old.Count) + new.Variance/float64(new.Count))
+	if se == 0 {
+		return math.Copysign(math.Inf(1), new.Mean-old.Mean)
+	}
+	return (new.Mean - old.Mean) / se
+}
+
+// newValues returns the values not in known, in their order
+func newValues(known, values []string) []string {
+	set := make(map[string]bool, len(known))
+	for _, v := range known {
+		set[v] = true
+	}
+	var unseen []string
+	for _, v := range values {
+		if !set[v] {
+			unseen = append(unseen, v)
+		}
+	}
+	return unseen
+}
+
+func quoteJoin(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = fmt.Sprintf("%q", v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// HasDrift reports whether any significant change was found
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// Mask replaces the values of the given columns listed in the drifts, as
+// TableStats.Mask does in the profile they were detected on
+func (r *DriftReport) Mask(columns []string, m Masking) {
+	masked := make(map[string]bool, len(columns))
+	for _, name := range columns {
+		masked[name] = true
+	}
+	for i := range r.Drifts {
+		d := &r.Drifts[i]
+		if !masked[d.Column] || len(d.Values) == 0 {
+			continue
+		}
+		values := make([]string, len(d.Values))
+		for j, v := range d.Values {
+			values[j] = m.value(v)
+		}
+		d.Values = values
+		d.Detail = "new values " + quoteJoin(values)
+	}
+}
+
+// Failures describes each drift in one line, for notifications
+func (r *DriftReport) Failures() []string {
+	lines := make([]string, len(r.Drifts))
+	for i, d := range r.Drifts {
+		lines[i] = fmt.Sprintf("%s: %s: %s", d.Column, d.Kind, d.Detail)
+	}
+	return lines
+}
+
+// WriteText writes the drifts, one per line
+func (r *DriftReport) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("=== Drift Against Baseline ===\n")
+	if !r.HasDrift() {
+		ew.printf("  none\n")
+	}
+	for _, d := range r.Drifts {
+		if d.Score != 0 {
+			ew.printf("  %s: %s: %s (%+.1f std errors)\n", d.Column, d.Kind, d.Detail, d.Score)
+		} else {
+			ew.printf("  %s: %s: %s\n", d.Column, d.Kind, d.Detail)
+		}
+	}
+	ew.printf("\n")
+	return ew.err
+}
@@ -0,0 +1,146 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func driftProfile(t *testing.T, rows int, record func(i int) []string) *TableStats {
+	acc, err := NewTableAccumulator([]string{"id", "amount", "status", "note"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for i := 0; i < rows; i++ {
+		acc.Add(record(i))
+	}
+	return acc.Finalize()
+}
+
+func TestDetectDrift(t *testing.T) {
+	statuses := []string{"active", "inactive"}
+	baseline := driftProfile(t, 1000, func(i int) []string {
+		return []string{fmt.Sprint(i), fmt.Sprint(100 + i%10), statuses[i%2], "x"}
+	})
+
+	// An identical feed does not drift
+	if report := DetectDrift(baseline, baseline, DefaultDriftStdErrors); report.HasDrift() {
+		t.Errorf("Expected no drift against itself, got %v", report.Drifts)
+	}
+
+	// A slightly different sample stays within the noise
+	similar := driftProfile(t, 1000, func(i int) []string {
+		note := "x"
+		if i == 0 {
+			note = ""
+		}
+		return []string{fmt.Sprint(i), fmt.Sprint(100 + (i+1)%10), statuses[i%2], note}
+	})
+	if report := DetectDrift(baseline, similar, DefaultDriftStdErrors); report.HasDrift() {
+		t.Errorf("Expected no significant drift, got %v", report.Drifts)
+	}
+
+	statuses = append(statuses, "deleted")
+	drifted := driftProfile(t, 1000, func(i int) []string {
+		note := "x"
+		if i%4 == 0 {
+			note = ""
+		}
+		return []string{fmt.Sprint(i), fmt.Sprint(110 + i%10), statuses[i%3], note}
+	})
+	report := DetectDrift(baseline, drifted, DefaultDriftStdErrors)
+
+	kinds := make(map[string]Drift)
+	for _, d := range report.Drifts {
+		kinds[d.Column+"."+d.Kind] = d
+	}
+	for _, key := range []string{"amount.mean", "amount.distribution", "status.new_values", "note.null_pct"} {
+		if _, ok := kinds[key]; !ok {
+			t.Errorf("Expected %s drift, got %v", key, report.Drifts)
+		}
+	}
+	if len(kinds) != 4 {
+		t.Errorf("Expected 4 drifts, got %v", report.Drifts)
+	}
+	if d := kinds["status.new_values"]; d.Detail != `new values "deleted"` {
+		t.Errorf("Unexpected new values detail %q", d.Detail)
+	}
+	if d := kinds["amount.mean"]; d.Score < DefaultDriftStdErrors {
+		t.Errorf("Expected a mean score above %v, got %f", DefaultDriftStdErrors, d.Score)
+	}
+
+	// Distributions are only compared with their thresholds set
+	report = DetectDriftWith(baseline, drifted, DriftThresholds{StdErrors: DefaultDriftStdErrors})
+	for _, d := range report.Drifts {
+		if d.Kind == "distribution" {
+			t.Errorf("Expected no distribution drift without thresholds, got %v", d)
+		}
+	}
+}
+
+func TestDetectDrift_Schema(t *testing.T) {
+	baseline := &TableStats{
+		RowCount:    10,
+		ColumnNames: []string{"id", "legacy"},
+		ColumnTypes: map[string]string{"id": "int64", "legacy": "string"},
+	}
+	current := &TableStats{
+		RowCount:    10,
+		ColumnNames: []string{"id", "status"},
+		ColumnTypes: map[string]string{"id": "string", "status": "string"},
+	}
+
+	report := DetectDrift(baseline, current, DefaultDriftStdErrors)
+	expected := []string{"legacy.removed", "status.added", "id.type"}
+	got := make(map[string]bool)
+	for _, d := range report.Drifts {
+		got[d.Column+"."+d.Kind] = true
+	}
+	for _, key := range expected {
+		if !got[key] {
+			t.Errorf("Expected %s drift, got %v", key, report.Drifts)
+		}
+	}
+	if len(report.Drifts) != len(expected) {
+		t.Errorf("Expected %d drifts, got %v", len(expected), report.Drifts)
+	}
+}
+
+func TestProportionZ(t *testing.T) {
+	if z := proportionZ(0, 100, 0, 100); z != 0 {
+		t.Errorf("Expected 0 for equal shares, got %f", z)
+	}
+	if z := proportionZ(0.1, 0, 0.5, 100); z != 0 {
+		t.Errorf("Expected 0 without baseline rows, got %f", z)
+	}
+	// 10% vs 20% of 1000 rows each is about 6.26 standard errors
+	if z := proportionZ(0.1, 1000, 0.2, 1000); math.Abs(z-6.26) > 0.01 {
+		t.Errorf("Expected z near 6.26, got %f", z)
+	}
+}
+
+func TestDriftReport_WriteText(t *testing.T) {
+	var b strings.Builder
+	if err := (&DriftReport{}).WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if expected := "=== Drift Against Baseline ===\n  none\n\n"; b.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, b.String())
+	}
+
+	b.Reset()
+	report := &DriftReport{Drifts: []Drift{
+		{Column: "amount", Kind: "mean", Detail: "mean 10 -> 20", Score: 4.3},
+		{Column: "note", Kind: "removed", Detail: "column removed"},
+	}}
+	if err := report.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	expected := "=== Drift Against Baseline ===\n" +
+		"  amount: mean: mean 10 -> 20 (+4.3 std errors)\n" +
+		"  note: removed: column removed\n\n"
+	if b.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, b.String())
+	}
+}
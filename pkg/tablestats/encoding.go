@@ -0,0 +1,85 @@
+package tablestats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// utf8BOM is stripped from the first header field regardless of the encoding
+const utf8BOM = "\ufeff"
+
+// textEncoding describes how to decode a file and how its newlines look on disk
+type textEncoding struct {
+	name    string                   // Canonical name, e.g. utf-8
+	decoder func() *encoding.Decoder // nil for UTF-8
+	unit    int64                    // Bytes per code unit; seek positions are aligned to it
+}
+
+// lookupEncoding resolves an encoding name as accepted by --encoding
+func lookupEncoding(name string) (textEncoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case "", "utf-8", "utf8":
+		return textEncoding{name: "utf-8", unit: 1}, nil
+	case "utf-16le", "utf16le", "utf-16":
+		return textEncoding{
+			name:    "utf-16le",
+			decoder: unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder,
+			unit:    2,
+		}, nil
+	case "latin1", "latin-1", "iso-8859-1":
+		return textEncoding{name: "latin1", decoder: charmap.ISO8859_1.NewDecoder, unit: 1}, nil
+	case "windows-1252", "cp1252":
+		return textEncoding{name: "windows-1252", decoder: charmap.Windows1252.NewDecoder, unit: 1}, nil
+	default:
+		return textEncoding{}, fmt.Errorf("unsupported encoding %q (use utf-8, utf-16le, latin1 or windows-1252)", name)
+	}
+}
+
+// decode wraps rd so it yields UTF-8 text
+func (e textEncoding) decode(rd io.Reader) io.Reader {
+	if e.decoder == nil {
+		return rd
+	}
+	return transform.NewReader(rd, e.decoder())
+}
+
+// isNewline reports whether the code unit starting at buf[i] is a line feed
+func (e textEncoding) isNewline(buf []byte, i int64) bool {
+	if buf[i] != '\n' {
+		return false
+	}
+	for j := int64(1); j < e.unit; j++ {
+		if buf[i+j] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// countNewlines counts the line feeds in buf, which must start at a code unit boundary
+func (e textEncoding) countNewlines(buf []byte) int64 {
+	if e.unit == 1 {
+		return int64(bytes.Count(buf, []byte{'\n'}))
+	}
+	var n int64
+	for i := int64(0); i+e.unit <= int64(len(buf)); i += e.unit {
+		if e.isNewline(buf, i) {
+			n++
+		}
+	}
+	return n
+}
+
+// stripBOM removes a UTF-8 byte order mark from the first header field
+func stripBOM(header []string) {
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], utf8BOM)
+	}
+}
@@ -0,0 +1,147 @@
+package tablestats
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEmptyFile is returned when the input has no content at all
+	ErrEmptyFile = errors.New("file is empty")
+	// ErrNoHeader is returned when the first record has no column names
+	ErrNoHeader = errors.New("file has no header")
+	// ErrUnsupportedFormat is returned when no reader can handle the input
+	ErrUnsupportedFormat = errors.New("unsupported format")
+	// ErrUnknownColumn is returned when a configured column is not in the header
+	ErrUnknownColumn = errors.New("column not found in header")
+	// ErrCheckpointMismatch is returned when a checkpoint was saved for another
+	// file, another version of it or other options
+	ErrCheckpointMismatch = errors.New("checkpoint does not match the read")
+)
+
+// ParseError describes a malformed record
+type ParseError struct {
+	Row    int64 // 1-based record number counting the header, 0 when reading started mid-file
+	Line   int   // Line relative to where reading started
+	Column int   // 1-based byte position within the line
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Row > 0 {
+		return fmt.Sprintf("parse error on row %d (line %d, column %d): %v", e.Row, e.Line, e.Column, e.Err)
+	}
+	return fmt.Sprintf("parse error on line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// wrapParseError converts csv parse errors into a ParseError. row is the record
+// number being read, or 0 when it is not known.
+func wrapParseError(err error, row int64) error {
+	var pe *csv.ParseError
+	if !errors.As(err, &pe) {
+		return err
+	}
+	return &ParseError{Row: row, Line: pe.Line, Column: pe.Column, Err: pe.Err}
+}
+
+// hasColumnNames reports whether any field of the header is non-empty
+func hasColumnNames(header []string) bool {
+	for _, name := range header {
+		if strings.TrimSpace(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorPolicy selects what a reader does with malformed records
+type ErrorPolicy int
+
+const (
+	// ErrorsFail stops reading with the *ParseError of the first malformed record
+	ErrorsFail ErrorPolicy = iota
+	// ErrorsSkip leaves malformed records out of the profile
+	ErrorsSkip
+	// ErrorsReport leaves malformed records out and lists them in
+	// TableStats.ParseErrors
+	ErrorsReport
+)
+
+// ParseErrorPolicy converts "fail", "skip" or "report" into an ErrorPolicy
+func ParseErrorPolicy(name string) (ErrorPolicy, error) {
+	switch name {
+	case "", "fail":
+		return ErrorsFail, nil
+	case "skip":
+		return ErrorsSkip, nil
+	case "report":
+		return ErrorsReport, nil
+	default:
+		return 0, fmt.Errorf("unsupported parse error policy %q (use fail, skip or report)", name)
+	}
+}
+
+// maxParseErrorExamples is the number of error messages kept by ErrorsReport
+const maxParseErrorExamples = 5
+
+// ParseErrorReport counts the malformed records left out of a profile
+type ParseErrorReport struct {
+	Count    int64    `json:"count"`
+	Examples []string `json:"examples,omitempty"` // Messages of the first errors
+}
+
+// parseErrorCounter applies an ErrorPolicy to the malformed records of a read
+type parseErrorCounter struct {
+	policy ErrorPolicy
+	max    int64 // Malformed records tolerated by skip and report, 0 for any number
+	ParseErrorReport
+}
+
+// skip returns nil when err is a parse error the policy tolerates, and the
+// error to stop reading with otherwise
+func (c *parseErrorCounter) skip(err error) error {
+	var parseErr *ParseError
+	if c == nil || c.policy == ErrorsFail || !errors.As(err, &parseErr) {
+		return err
+	}
+	c.Count++
+	if c.max > 0 && c.Count > c.max {
+		return fmt.Errorf("more than %d malformed records: %w", c.max, err)
+	}
+	if c.policy == ErrorsReport && len(c.Examples) < maxParseErrorExamples {
+		c.Examples = append(c.Examples, err.Error())
+	}
+	return nil
+}
+
+// result returns the malformed records to report, nil unless the policy is
+// ErrorsReport and some were found
+func (c *parseErrorCounter) result() *ParseErrorReport {
+	if c == nil || c.policy != ErrorsReport || c.Count == 0 {
+		return nil
+	}
+	r := c.ParseErrorReport
+	return &r
+}
+
+// mergeParseErrors adds the malformed records of b to those of a
+func mergeParseErrors(a, b *ParseErrorReport) *ParseErrorReport {
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+	merged := &ParseErrorReport{Count: a.Count + b.Count}
+	merged.Examples = append(append(merged.Examples, a.Examples...), b.Examples...)
+	if len(merged.Examples) > maxParseErrorExamples {
+		merged.Examples = merged.Examples[:maxParseErrorExamples]
+	}
+	return merged
+}
@@ -0,0 +1,149 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReadTable_Errors(t *testing.T) {
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+	reader := NewCSVReader(',')
+
+	tests := []struct {
+		name    string
+		content string
+		config  SamplingConfig
+		want    error
+	}{
+		{"empty file", "", config, ErrEmptyFile},
+		{"blank header", ",,\n1,2,3\n", config, ErrNoHeader},
+		{"unknown column", "a,b\n1,2\n", SamplingConfig{MaxFileSize: 1024, Columns: []string{"c"}}, ErrUnknownColumn},
+		{"unknown weight column", "a,b\n1,2\n", SamplingConfig{MaxFileSize: 1024, WeightColumn: "c"}, ErrUnknownColumn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeRawFile(t, "test.csv", []byte(tt.content))
+			_, err := reader.ReadTable(context.Background(), path, tt.config)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestReadTable_ParseError(t *testing.T) {
+	path := writeRawFile(t, "test.csv", []byte("id,name\n1,ok\n2,\"bad\"quote\n"))
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+
+	_, err := NewCSVReader(',').ReadTable(context.Background(), path, config)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected ParseError, got %v", err)
+	}
+	if parseErr.Row != 3 || parseErr.Line != 3 {
+		t.Errorf("Expected row 3 on line 3, got row %d on line %d", parseErr.Row, parseErr.Line)
+	}
+	if parseErr.Column == 0 {
+		t.Error("Expected a column position")
+	}
+}
+
+func TestReadTable_ParseErrorPolicy(t *testing.T) {
+	path := writeRawFile(t, "test.csv", []byte("id,name\n1,ok\n2,\"bad\"quote\n3,ok\n4,x\"y\n5,ok\n"))
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+
+	forEachReadPath(t, func(t *testing.T, reader *CSVReader) {
+		reader.OnParseError = ErrorsSkip
+		stats, err := reader.ReadTable(context.Background(), path, config)
+		if err != nil {
+			t.Fatalf("ReadTable failed: %v", err)
+		}
+		if stats.RowCount != 3 || stats.ParseErrors != nil {
+			t.Errorf("Expected 3 rows and no report when skipping, got %d rows and %+v", stats.RowCount, stats.ParseErrors)
+		}
+
+		reader.OnParseError = ErrorsReport
+		stats, err = reader.ReadTable(context.Background(), path, config)
+		if err != nil {
+			t.Fatalf("ReadTable failed: %v", err)
+		}
+		if stats.RowCount != 3 {
+			t.Errorf("Expected 3 rows, got %d", stats.RowCount)
+		}
+		if p := stats.ParseErrors; p == nil || p.Count != 2 || len(p.Examples) != 2 ||
+			!strings.Contains(p.Examples[0], "row 3") || !strings.Contains(p.Examples[1], "row 5") {
+			t.Errorf("Expected rows 3 and 5 to be reported, got %+v", p)
+		}
+
+		reader.MaxParseErrors = 1
+		_, err = reader.ReadTable(context.Background(), path, config)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Row != 5 || !strings.Contains(err.Error(), "more than 1 malformed records") {
+			t.Errorf("Expected to stop at the second malformed record, got %v", err)
+		}
+	})
+}
+
+func TestReadTable_SampledParseErrorPolicy(t *testing.T) {
+	var content strings.Builder
+	content.WriteString("id,name\n")
+	for i := 0; i < 2000; i++ {
+		if i%10 == 5 {
+			fmt.Fprintf(&content, "%d,x\"y\n", i)
+		} else {
+			fmt.Fprintf(&content, "%d,ok\n", i)
+		}
+	}
+	path := writeRawFile(t, "test.csv", []byte(content.String()))
+	// Small enough a limit to sample at random positions
+	config := SamplingConfig{MaxFileSize: 100, SampleSize: 200, RandomPositions: 5}
+
+	reader := NewCSVReader(',')
+	var parseErr *ParseError
+	if _, err := reader.ReadTable(context.Background(), path, config); !errors.As(err, &parseErr) {
+		t.Errorf("Expected sampling to fail on a malformed record, got %v", err)
+	}
+
+	reader.OnParseError = ErrorsReport
+	stats, err := reader.ReadTable(context.Background(), path, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if stats.ParseErrors == nil || stats.ParseErrors.Count == 0 {
+		t.Errorf("Expected sampled malformed records to be reported, got %+v", stats.ParseErrors)
+	}
+	if stats.NullCounts["name"] != 0 {
+		t.Errorf("Expected malformed records to be left out, got %d null names", stats.NullCounts["name"])
+	}
+}
+
+func TestParseErrorPolicy(t *testing.T) {
+	for name, want := range map[string]ErrorPolicy{"": ErrorsFail, "fail": ErrorsFail, "skip": ErrorsSkip, "report": ErrorsReport} {
+		if policy, err := ParseErrorPolicy(name); err != nil || policy != want {
+			t.Errorf("Expected %d for %q, got %d (%v)", want, name, policy, err)
+		}
+	}
+	if _, err := ParseErrorPolicy("ignore"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	if _, err := NewParquetReader().ReadTable(context.Background(), "data.parquet", SamplingConfig{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat from the Parquet reader, got %v", err)
+	}
+
+	path := writeRawFile(t, "notes.txt", []byte("just some text\n"))
+	if _, err := NewReaderFor(path, ReaderOptions{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for undetectable input, got %v", err)
+	}
+
+	path = writeRawFile(t, "empty.csv", nil)
+	if _, err := NewReaderFor(path, ReaderOptions{}); !errors.Is(err, ErrEmptyFile) {
+		t.Errorf("Expected ErrEmptyFile, got %v", err)
+	}
+}
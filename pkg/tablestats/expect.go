@@ -0,0 +1,399 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Expectations is a list of assertions over a computed profile, evaluated by
+// Evaluate. Each assertion compares a path into the profile with a value:
+//
+//	expect:
+//	  - row_count >= 1000
+//	  - aggregates.amount.p99 < 10000
+//	  - columns.status.top_values contains "active"
+//	  - columns."order date".null_pct <= 1
+type Expectations struct {
+	Expect []string `yaml:"expect"`
+
+	parsed []expectation
+}
+
+// expectation is one parsed assertion
+type expectation struct {
+	path  []string
+	op    string
+	value any // float64 or string
+}
+
+// expectationOps lists the operators, longer ones first so that "<=" is not
+// read as "<"
+var expectationOps = []string{"not contains", "contains", "<=", ">=", "==", "!=", "<", ">"}
+
+// ExpectationResult is the outcome of one assertion
+type ExpectationResult struct {
+	Expectation string `json:"expectation"`
+	Column      string `json:"column,omitempty"` // Column the expectation is about, if any
+	Passed      bool   `json:"passed"`
+	Actual      string `json:"actual,omitempty"` // Value found in the profile
+	Error       string `json:"error,omitempty"`  // Why the assertion could not be evaluated
+}
+
+// ExpectationReport holds the outcome of every assertion, in file order
+type ExpectationReport struct {
+	Results []ExpectationResult `json:"results"`
+}
+
+// LoadExpectations reads and parses a YAML expectations file
+func LoadExpectations(path string) (*Expectations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read expectations: %w", err)
+	}
+
+	e := &Expectations{}
+	if err := yaml.Unmarshal(data, e); err != nil {
+		return nil, fmt.Errorf("failed to parse expectations: %w", err)
+	}
+	if err := e.parse(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Expectations) parse() error {
+	e.parsed = make([]expectation, len(e.Expect))
+	for i, text := range e.Expect {
+		exp, err := parseExpectation(text)
+		if err != nil {
+			return fmt.Errorf("expectation %d (%s): %w", i+1, text, err)
+		}
+		e.parsed[i] = exp
+	}
+	return nil
+}
+
+// parseExpectation reads "<path> <operator> <value>". Path segments are
+// separated by dots and may be double-quoted to contain dots, spaces or
+// operator characters; the value is a number, a double-quoted string or a
+// bare word.
+func parseExpectation(text string) (expectation, error) {
+	var exp expectation
+	rest := strings.TrimSpace(text)
+	for {
+		var segment string
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return exp, fmt.Errorf("unterminated quoted path segment")
+			}
+			segment, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			end := strings.IndexFunc(rest, func(r rune) bool {
+				return r == '.' || unicode.IsSpace(r) || strings.ContainsRune("<>=!", r)
+			})
+			if end < 0 {
+				return exp, fmt.Errorf("missing operator")
+			}
+			segment, rest = rest[:end], rest[end:]
+		}
+		if segment == "" {
+			return exp, fmt.Errorf("empty path segment")
+		}
+		exp.path = append(exp.path, segment)
+		if !strings.HasPrefix(rest, ".") {
+			break
+		}
+		rest = rest[1:]
+	}
+
+	rest = strings.TrimSpace(rest)
+	for _, op := range expectationOps {
+		if strings.HasPrefix(rest, op) {
+			exp.op = op
+			rest = strings.TrimSpace(rest[len(op):])
+			break
+		}
+	}
+	switch {
+	case exp.op == "":
+		return exp, fmt.Errorf("missing operator (use %s)", strings.Join(expectationOps, ", "))
+	case rest == "":
+		return exp, fmt.Errorf("missing value")
+	case strings.HasPrefix(rest, `"`):
+		value, err := strconv.Unquote(rest)
+		if err != nil {
+			return exp, fmt.Errorf("invalid quoted value %s", rest)
+		}
+		exp.value = value
+	default:
+		if number, err := strconv.ParseFloat(rest, 64); err == nil {
+			exp.value = number
+		} else {
+			exp.value = rest
+		}
+	}
+	return exp, nil
+}
+
+// Evaluate checks every expectation against the profile. An expectation
+// that cannot be evaluated, e.g. because it names a missing column, fails.
+func Evaluate(stats *TableStats, e *Expectations) (*ExpectationReport, error) {
+	if len(e.parsed) != len(e.Expect) {
+		if err := e.parse(); err != nil {
+			return nil, err
+		}
+	}
+
+	view := expectationView(stats)
+	report := &ExpectationReport{Results: make([]ExpectationResult, len(e.parsed))}
+	for i, exp := range e.parsed {
+		result := &report.Results[i]
+		result.Expectation = e.Expect[i]
+		if len(exp.path) > 1 && (exp.path[0] == "columns" || exp.path[0] == "aggregates") {
+			result.Column = exp.path[1]
+		}
+
+		actual, err := resolvePath(view, exp.path)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Actual = formatActual(actual)
+		if result.Passed, err = exp.holds(actual); err != nil {
+			result.Error = err.Error()
+		}
+	}
+	return report, nil
+}
+
+// expectedRange returns a minimum or maximum as expectations compare it:
+// numbers as float64, whatever the column type
+func expectedRange(v any) any {
+	if f, ok := numericValue(v); ok {
+		return f
+	}
+	return v
+}
+
+// expectationView exposes the profile under the names expectations use
+func expectationView(stats *TableStats) map[string]any {
+	columns := make(map[string]any, len(stats.ColumnNames))
+	for _, name := range stats.ColumnNames {
+		col := map[string]any{
+			"type":             stats.ColumnTypes[name],
+			"null_count":       float64(stats.NullCounts[name]),
+			"null_pct":         stats.NullPercentage[name],
+			"empty_count":      float64(stats.EmptyCounts[name]),
+			"null_token_count": float64(stats.NullTokens[name]),
+			"distinct":         float64(stats.DistinctCounts[name]),
+			"min":              expectedRange(stats.MinValues[name]),
+			"max":              expectedRange(stats.MaxValues[name]),
+		}
+		if values, ok := stats.Categories[name]; ok {
+			col["top_values"] = values
+		}
+		if flag, ok := stats.PII[name]; ok {
+			col["pii"] = flag.Kind
+		}
+		if c, ok := stats.Conformance[name]; ok {
+			col["match_pct"] = c.MatchPct()
+		}
+		if w, ok := stats.Padding[name]; ok {
+			col["leading_space_count"] = float64(w.Leading)
+			col["trailing_space_count"] = float64(w.Trailing)
+		}
+		if d, ok := stats.Decimals[name]; ok {
+			col["precision"] = float64(d.Precision)
+			col["scale"] = float64(d.Scale)
+		}
+		columns[name] = col
+	}
+
+	aggregates := make(map[string]any, len(stats.Aggregates))
+	for name, agg := range stats.Aggregates {
+		a := map[string]any{
+			"count":           float64(agg.Count),
+			"sum":             agg.Sum,
+			"mean":            agg.Mean,
+			"median":          agg.Median,
+			"std_dev":         agg.StdDev,
+			"variance":        agg.Variance,
+			"estimated_total": agg.EstimatedTotal,
+		}
+		for p, v := range agg.Percentiles {
+			a[fmt.Sprintf("p%d", p)] = v
+		}
+		aggregates[name] = a
+	}
+
+	return map[string]any{
+		"row_count":      float64(stats.RowCount),
+		"estimated_rows": float64(stats.EstimatedRows),
+		"column_count":   float64(stats.ColumnCount),
+		"columns":        columns,
+		"aggregates":     aggregates,
+	}
+}
+
+func resolvePath(view map[string]any, path []string) (any, error) {
+	var node any = view
+	for i, segment := range path {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s has no field %q", strings.Join(path[:i], "."), segment)
+		}
+		if node, ok = m[segment]; !ok {
+			if i == 0 {
+				return nil, fmt.Errorf("unknown field %q (have %s)", segment, strings.Join(sortedKeys(m), ", "))
+			}
+			return nil, fmt.Errorf("%s has no field %q", strings.Join(path[:i], "."), segment)
+		}
+	}
+	if _, ok := node.(map[string]any); ok {
+		return nil, fmt.Errorf("%s is not a value", strings.Join(path, "."))
+	}
+	return node, nil
+}
+
+// holds applies the operator to the actual value
+func (exp expectation) holds(actual any) (bool, error) {
+	switch exp.op {
+	case "contains", "not contains":
+		want := fmt.Sprint(exp.value)
+		var found bool
+		switch a := actual.(type) {
+		case []string:
+			for _, v := range a {
+				found = found || v == want
+			}
+		case string:
+			found = strings.Contains(a, want)
+		default:
+			return false, fmt.Errorf("%s needs a list or text, got %v", exp.op, actual)
+		}
+		return found == (exp.op == "contains"), nil
+	}
+
+	var cmp int
+	switch a := actual.(type) {
+	case float64:
+		want, ok := exp.value.(float64)
+		if !ok {
+			return false, fmt.Errorf("cannot compare number %v with %q", a, exp.value)
+		}
+		cmp = compareFloats(a, want)
+	case string:
+		cmp = strings.Compare(a, fmt.Sprint(exp.value))
+	case nil:
+		return false, fmt.Errorf("no value")
+	default:
+		return false, fmt.Errorf("%s cannot compare %v", exp.op, actual)
+	}
+
+	switch exp.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	case "==":
+		return cmp == 0, nil
+	default:
+		return cmp != 0, nil
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// formatActual renders a profile value for the report
+func formatActual(v any) string {
+	switch a := v.(type) {
+	case []string:
+		return "[" + quoteJoin(a) + "]"
+	case float64:
+		return strconv.FormatFloat(a, 'g', -1, 64)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(a)
+	}
+}
+
+// Failed reports whether any expectation failed or could not be evaluated
+func (r *ExpectationReport) Failed() bool {
+	for _, res := range r.Results {
+		if !res.Passed {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures describes each failed expectation in one line, for notifications
+func (r *ExpectationReport) Failures() []string {
+	var lines []string
+	for _, res := range r.Results {
+		switch {
+		case res.Error != "":
+			lines = append(lines, fmt.Sprintf("%s: %s", res.Expectation, res.Error))
+		case !res.Passed:
+			lines = append(lines, fmt.Sprintf("%s (actual %s)", res.Expectation, res.Actual))
+		}
+	}
+	return lines
+}
+
+// PassedCount returns the number of expectations that held
+func (r *ExpectationReport) PassedCount() int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// WriteText writes the outcome of each expectation and the overall result
+func (r *ExpectationReport) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	ew.printf("=== Expectations ===\n")
+	for _, res := range r.Results {
+		switch {
+		case res.Error != "":
+			ew.printf("  ERROR %s: %s\n", res.Expectation, res.Error)
+		case res.Passed:
+			ew.printf("  PASS  %s\n", res.Expectation)
+		default:
+			ew.printf("  FAIL  %s (actual %s)\n", res.Expectation, res.Actual)
+		}
+	}
+	ew.printf("Passed: %d of %d\n", r.PassedCount(), len(r.Results))
+	if r.Failed() {
+		ew.printf("Result: FAIL\n")
+	} else {
+		ew.printf("Result: PASS\n")
+	}
+	ew.printf("\n")
+	return ew.err
+}
@@ -0,0 +1,106 @@
+package tablestats
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseExpectation(t *testing.T) {
+	tests := []struct {
+		text string
+		want expectation
+	}{
+		{"row_count >= 1000", expectation{[]string{"row_count"}, ">=", 1000.0}},
+		{"aggregates.amount.p99 < 1e4", expectation{[]string{"aggregates", "amount", "p99"}, "<", 10000.0}},
+		{`columns.status.top_values contains "active"`, expectation{[]string{"columns", "status", "top_values"}, "contains", "active"}},
+		{`columns."order.date".type not contains int`, expectation{[]string{"columns", "order.date", "type"}, "not contains", "int"}},
+		{`columns.status.min=="a b"`, expectation{[]string{"columns", "status", "min"}, "==", "a b"}},
+	}
+	for _, tt := range tests {
+		got, err := parseExpectation(tt.text)
+		if err != nil {
+			t.Errorf("Parsing %q failed: %v", tt.text, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Expected %+v for %q, got %+v", tt.want, tt.text, got)
+		}
+	}
+
+	invalid := []string{"row_count", "row_count >=", "row_count ~ 5", `columns."status.type == a`, "columns..type == a", `a == "b`}
+	for _, text := range invalid {
+		if _, err := parseExpectation(text); err == nil {
+			t.Errorf("Expected error for %q", text)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header:        []string{"status", "amount"},
+		EstimatedRows: 4,
+	}, [][]string{{"active", "10"}, {"closed", "20"}, {"active", ""}, {"active", "30"}}), SamplingConfig{})
+
+	e, err := LoadExpectations(writeSchema(t, `
+expect:
+  - row_count == 4
+  - aggregates.amount.mean == 20
+  - aggregates.amount.p99 < 30
+  - columns.status.top_values contains "active"
+  - columns.status.top_values not contains "closed"
+  - columns.amount.null_pct <= 25
+  - columns.status.max > "b"
+  - columns.missing.type == string
+  - aggregates.status.mean > 0
+  - columns.status.top_values > 1
+`))
+	if err != nil {
+		t.Fatalf("LoadExpectations failed: %v", err)
+	}
+
+	report, err := Evaluate(stats, e)
+	if err != nil {
+		t.Fatalf("Evaluate failed: %v", err)
+	}
+	var passed []bool
+	var errored []bool
+	for _, res := range report.Results {
+		passed = append(passed, res.Passed)
+		errored = append(errored, res.Error != "")
+	}
+	if expected := []bool{true, true, true, true, false, true, true, false, false, false}; !reflect.DeepEqual(passed, expected) {
+		t.Errorf("Expected passed %v, got %v", expected, passed)
+	}
+	if expected := []bool{false, false, false, false, false, false, false, true, true, true}; !reflect.DeepEqual(errored, expected) {
+		t.Errorf("Expected errors %v, got %v", expected, errored)
+	}
+	if got := report.Results[4].Actual; got != `["active", "closed"]` {
+		t.Errorf("Expected the actual values to be reported, got %s", got)
+	}
+	if report.Results[0].Column != "" || report.Results[1].Column != "amount" || report.Results[7].Column != "missing" {
+		t.Errorf("Expected the columns of the expectations, got %+v", report.Results)
+	}
+	if !report.Failed() || report.PassedCount() != 6 {
+		t.Errorf("Expected 6 passed and a failed report, got %d", report.PassedCount())
+	}
+
+	var b strings.Builder
+	if err := report.WriteText(&b); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, line := range []string{
+		"  PASS  " + report.Results[0].Expectation + "\n",
+		"  FAIL  " + report.Results[4].Expectation + ` (actual ["active", "closed"])` + "\n",
+		"  ERROR " + report.Results[7].Expectation + ": ",
+		"Passed: 6 of 10\nResult: FAIL\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in the report, got:\n%s", line, b.String())
+		}
+	}
+
+	if _, err := LoadExpectations(writeSchema(t, "expect:\n  - row_count\n")); err == nil {
+		t.Error("Expected error for an expectation without operator")
+	}
+}
@@ -0,0 +1,243 @@
+package tablestats
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// inspectBytes is how much of the start of a file is inspected for its
+// encoding, line endings and compression
+const inspectBytes = 64 << 10
+
+// utf16LEBOM is the byte order mark of UTF-16 little-endian text
+const utf16LEBOM = "\xff\xfe"
+
+// compressionMagic are the leading bytes of compressed files
+var compressionMagic = []struct {
+	name  string
+	magic string
+}{
+	{"gzip", "\x1f\x8b"},
+	{"zstd", "\x28\xb5\x2f\xfd"},
+	{"bzip2", "BZh"},
+	{"xz", "\xfd7zXZ\x00"},
+	{"zip", "PK\x03\x04"},
+}
+
+// FileInfo describes the file a profile was read from, as found in its first
+// 64 KiB
+type FileInfo struct {
+	Size             int64  `json:"size"`                   // Bytes, -1 for a stream of unknown length
+	Encoding         string `json:"encoding"`               // Encoding the file was decoded with
+	DetectedEncoding string `json:"detected_encoding"`      // utf-8, utf-16le or 8-bit (neither, e.g. latin1)
+	LineEndings      string `json:"line_endings,omitempty"` // LF, CRLF, CR or mixed; empty without line breaks
+	BOM              bool   `json:"bom"`                    // Starts with a UTF-8 or UTF-16 byte order mark
+	Compression      string `json:"compression"`            // none, gzip, zstd, bzip2, xz or zip
+	HeaderChecksum   string `json:"header_checksum"`        // Digest of the column names as read, e.g. to spot renamed or reordered columns
+}
+
+// peekHead returns the first inspectBytes of rd. Inputs that are not
+// randomly accessible are buffered, and the returned reader must be read
+// instead of rd.
+func peekHead(rd io.Reader, size int64) ([]byte, io.Reader) {
+	if src, ok := rd.(randomAccessReader); ok && size >= 0 {
+		head := make([]byte, min(size, inspectBytes))
+		n, _ := src.ReadAt(head, 0)
+		return head[:n], rd
+	}
+	br := bufio.NewReaderSize(rd, inspectBytes)
+	head, _ := br.Peek(inspectBytes)
+	return bytes.Clone(head), br
+}
+
+// inspectFile describes a file of size bytes starting with head, decoded with
+// enc
+func inspectFile(head []byte, size int64, enc textEncoding) *FileInfo {
+	info := &FileInfo{Size: size, Encoding: enc.name, Compression: "none"}
+	for _, c := range compressionMagic {
+		if bytes.HasPrefix(head, []byte(c.magic)) {
+			info.Compression = c.name
+			return info
+		}
+	}
+
+	unit := 1
+	switch {
+	case bytes.HasPrefix(head, []byte(utf16LEBOM)):
+		info.BOM = true
+		info.DetectedEncoding, unit = "utf-16le", 2
+	case bytes.HasPrefix(head, []byte(utf8BOM)):
+		info.BOM = true
+		info.DetectedEncoding = "utf-8"
+	case looksUTF16LE(head):
+		info.DetectedEncoding, unit = "utf-16le", 2
+	case validUTF8Prefix(head, len(head) == inspectBytes):
+		info.DetectedEncoding = "utf-8"
+	default:
+		info.DetectedEncoding = "8-bit"
+	}
+	info.LineEndings = lineEndings(head, unit)
+	return info
+}
+
+// looksUTF16LE reports whether most odd bytes are zero, as in UTF-16LE text
+// of Latin letters and digits
+func looksUTF16LE(head []byte) bool {
+	if len(head) < 4 {
+		return false
+	}
+	zeros := 0
+	for i := 1; i < len(head); i += 2 {
+		if head[i] == 0 {
+			zeros++
+		}
+	}
+	return zeros*10 >= len(head)/2*9
+}
+
+// validUTF8Prefix reports whether head is UTF-8. A truncated head may end
+// within a character, which is not held against it.
+func validUTF8Prefix(head []byte, truncated bool) bool {
+	if utf8.Valid(head) {
+		return true
+	}
+	for cut := 1; truncated && cut < utf8.UTFMax && cut < len(head); cut++ {
+		if utf8.Valid(head[:len(head)-cut]) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineEndings names the line breaks of text in code units of unit bytes
+func lineEndings(head []byte, unit int) string {
+	isUnit := func(i int, b byte) bool {
+		if i < 0 || i+unit > len(head) || head[i] != b {
+			return false
+		}
+		for j := 1; j < unit; j++ {
+			if head[i+j] != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	var lf, crlf, cr int
+	for i := 0; i+unit <= len(head); i += unit {
+		switch {
+		case isUnit(i, '\n') && isUnit(i-unit, '\r'):
+			crlf++
+		case isUnit(i, '\n'):
+			lf++
+		case isUnit(i, '\r') && !isUnit(i+unit, '\n') && i+unit < len(head):
+			cr++
+		}
+	}
+	var kinds []string
+	for _, k := range []struct {
+		name  string
+		count int
+	}{{"LF", lf}, {"CRLF", crlf}, {"CR", cr}} {
+		if k.count > 0 {
+			kinds = append(kinds, k.name)
+		}
+	}
+	switch len(kinds) {
+	case 0:
+		return ""
+	case 1:
+		return kinds[0]
+	default:
+		return "mixed"
+	}
+}
+
+// headerChecksum digests the column names of a header, e.g. "sha256:1a2b3c4d5e6f7a8b"
+func headerChecksum(header []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(header, keySeparator)))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// mergeFileInfo describes the parts of a merged table. Sizes add up, and
+// properties the parts do not share are "mixed".
+func mergeFileInfo(parts []*FileInfo) *FileInfo {
+	var merged *FileInfo
+	for _, p := range parts {
+		if p == nil {
+			return nil
+		}
+		if merged == nil {
+			m := *p
+			merged = &m
+			continue
+		}
+		if merged.Size < 0 || p.Size < 0 {
+			merged.Size = -1
+		} else {
+			merged.Size += p.Size
+		}
+		for _, f := range []struct{ merged, part *string }{
+			{&merged.Encoding, &p.Encoding},
+			{&merged.DetectedEncoding, &p.DetectedEncoding},
+			{&merged.LineEndings, &p.LineEndings},
+			{&merged.Compression, &p.Compression},
+			{&merged.HeaderChecksum, &p.HeaderChecksum},
+		} {
+			if *f.merged != *f.part {
+				*f.merged = "mixed"
+			}
+		}
+		merged.BOM = merged.BOM || p.BOM
+	}
+	return merged
+}
+
+// mismatch describes why the file may have been read wrongly, or is ""
+func (f *FileInfo) mismatch() string {
+	switch {
+	case f.DetectedEncoding == "utf-16le" && f.Encoding != "utf-16le":
+		return fmt.Sprintf("the file looks like utf-16le but was read as %s; try --encoding utf-16le", f.Encoding)
+	case f.DetectedEncoding == "8-bit" && f.Encoding == "utf-8":
+		return "the file is not valid utf-8; try --encoding latin1 or windows-1252"
+	}
+	return ""
+}
+
+// summary describes the file in one line, e.g.
+// "3.4 MB, utf-8, CRLF line endings, with BOM, header sha256:1a2b3c4d5e6f7a8b"
+func (f *FileInfo) summary(nw numberWriter) string {
+	var parts []string
+	if f.Size >= 0 {
+		parts = append(parts, nw.bytes(f.Size))
+	}
+	parts = append(parts, f.Encoding)
+	if f.LineEndings != "" {
+		parts = append(parts, f.LineEndings+" line endings")
+	}
+	if f.BOM {
+		parts = append(parts, "with BOM")
+	}
+	if f.Compression != "none" {
+		parts = append(parts, f.Compression+"-compressed")
+	}
+	parts = append(parts, "header "+f.HeaderChecksum)
+	return strings.Join(parts, ", ")
+}
+
+// renderFileInfo prints the file line of the report, with a warning when the
+// file may have been read wrongly
+func (r *TextRenderer) renderFileInfo(ew *errWriter, nw numberWriter, indent string, f *FileInfo) {
+	if f == nil {
+		return
+	}
+	ew.printf("%sFile: %s\n", indent, f.summary(nw))
+	if warning := f.mismatch(); warning != "" {
+		ew.printf("%s%s\n", indent, r.paint(colorYellow, "Warning: "+warning))
+	}
+}
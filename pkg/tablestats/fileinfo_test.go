@@ -0,0 +1,105 @@
+package tablestats
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestInspectFile(t *testing.T) {
+	utf8Enc, _ := lookupEncoding("utf-8")
+	tests := []struct {
+		name     string
+		head     string
+		detected string
+		endings  string
+		bom      bool
+		compress string
+	}{
+		{"lf", "a,b\n1,2\n", "utf-8", "LF", false, "none"},
+		{"crlf", "a,b\r\n1,2\r\n", "utf-8", "CRLF", false, "none"},
+		{"mixed", "a,b\r\n1,2\n", "utf-8", "mixed", false, "none"},
+		{"cr", "a,b\r1,2\r", "utf-8", "CR", false, "none"},
+		{"single line", "a,b", "utf-8", "", false, "none"},
+		{"utf-8 bom", utf8BOM + "a,b\n", "utf-8", "LF", true, "none"},
+		{"latin1", "n\xe9,b\n", "8-bit", "LF", false, "none"},
+		{"utf-16le bom", "\xff\xfea\x00,\x00b\x00\r\x00\n\x00", "utf-16le", "CRLF", true, "none"},
+		{"utf-16le", "a\x00,\x00b\x00\n\x00", "utf-16le", "LF", false, "none"},
+		{"gzip", "\x1f\x8b\x08\x00", "", "", false, "gzip"},
+	}
+	for _, tt := range tests {
+		info := inspectFile([]byte(tt.head), int64(len(tt.head)), utf8Enc)
+		if info.DetectedEncoding != tt.detected || info.LineEndings != tt.endings || info.BOM != tt.bom || info.Compression != tt.compress {
+			t.Errorf("%s: expected %s, %q, BOM %v, %s, got %+v", tt.name, tt.detected, tt.endings, tt.bom, tt.compress, info)
+		}
+	}
+}
+
+func TestFileInfo_Mismatch(t *testing.T) {
+	if m := (&FileInfo{Encoding: "utf-8", DetectedEncoding: "8-bit", Compression: "none"}).mismatch(); !strings.Contains(m, "--encoding latin1") {
+		t.Errorf("Expected a hint to use latin1, got %q", m)
+	}
+	if m := (&FileInfo{Encoding: "latin1", DetectedEncoding: "8-bit", Compression: "none"}).mismatch(); m != "" {
+		t.Errorf("Expected no warning, got %q", m)
+	}
+}
+
+func TestReadTable_FileInfo(t *testing.T) {
+	data := "id,name\r\n1,a\r\n2,b\r\n"
+	// A reader without ReadAt is read as a stream
+	stream := io.MultiReader(strings.NewReader(data))
+	stats, err := NewCSVReader(',').ReadTableFrom(context.Background(), stream, int64(len(data)), DefaultSamplingConfig())
+	if err != nil {
+		t.Fatalf("ReadTableFrom failed: %v", err)
+	}
+	f := stats.FileInfo
+	if f == nil || f.Size != int64(len(data)) || f.LineEndings != "CRLF" || f.HeaderChecksum != headerChecksum([]string{"id", "name"}) {
+		t.Fatalf("Unexpected file info %+v", f)
+	}
+	if stats.RowCount != 2 {
+		t.Errorf("Expected 2 rows after peeking at the stream, got %d", stats.RowCount)
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := "File: 19 B, utf-8, CRLF line endings, header " + f.HeaderChecksum + "\n"
+	if !strings.Contains(b.String(), expected) {
+		t.Errorf("Expected %q in the report, got:\n%s", expected, b.String())
+	}
+}
+
+func TestReadTable_Compressed(t *testing.T) {
+	var data bytes.Buffer
+	zw := gzip.NewWriter(&data)
+	zw.Write([]byte("id,name\n1,a\n2,b\n"))
+	zw.Close()
+	path := writeRawFile(t, "test.csv.gz", data.Bytes())
+
+	// The magic bytes are checked before the header is parsed
+	_, err := NewCSVReader(',').ReadTable(context.Background(), path, DefaultSamplingConfig())
+	if err == nil || err.Error() != "file is gzip-compressed; decompress it before profiling" {
+		t.Errorf("Expected the gzip hint as the error, got %v", err)
+	}
+	_, err = NewCSVReader(',').ReadTableFrom(context.Background(), bytes.NewReader(data.Bytes()), -1, DefaultSamplingConfig())
+	if err == nil || !strings.Contains(err.Error(), "gzip-compressed") {
+		t.Errorf("Expected the gzip hint for a stream, got %v", err)
+	}
+}
+
+func TestMergeFileInfo(t *testing.T) {
+	merged := mergeFileInfo([]*FileInfo{
+		{Size: 10, Encoding: "utf-8", LineEndings: "LF", HeaderChecksum: "sha256:1"},
+		{Size: 20, Encoding: "utf-8", LineEndings: "CRLF", BOM: true, HeaderChecksum: "sha256:1"},
+	})
+	if merged.Size != 30 || merged.Encoding != "utf-8" || merged.LineEndings != "mixed" || !merged.BOM || merged.HeaderChecksum != "sha256:1" {
+		t.Errorf("Unexpected merged file info %+v", merged)
+	}
+	if mergeFileInfo([]*FileInfo{{Size: 1}, nil}) != nil {
+		t.Error("Expected no file info when a part has none")
+	}
+}
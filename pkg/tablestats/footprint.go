@@ -0,0 +1,44 @@
+package tablestats
+
+import "math"
+
+// Bytes per value of the typed arrays a table is estimated to load into, as
+// laid out by Apache Arrow and similar columnar formats
+const (
+	numberBytes = 8 // int64 and float64 values
+	offsetBytes = 4 // Position of each string in the column's value buffer
+)
+
+// MemoryFootprint estimates how much memory the table would take loaded as
+// typed arrays: 8 bytes per number, the bytes of each string plus a 4-byte
+// offset, and a validity bitmap of one bit per row for columns with nulls.
+// Rows are extrapolated to EstimatedRows.
+type MemoryFootprint struct {
+	Total   int64            `json:"total"`
+	Columns map[string]int64 `json:"columns"`
+}
+
+// add records the footprint of a column
+func (m *MemoryFootprint) add(name string, bytes int64) {
+	m.Columns[name] = bytes
+	m.Total += bytes
+}
+
+// footprint estimates the bytes of the column loaded as an array of typ over
+// estimatedRows rows
+func (c *columnAccumulator) footprint(typ string, estimatedRows int64) int64 {
+	if c.rows == 0 {
+		return 0
+	}
+	var perRow float64
+	if typ == "string" {
+		perRow = offsetBytes + float64(c.textBytes)/float64(c.rows)
+	} else {
+		perRow = numberBytes
+	}
+	bytes := perRow * float64(estimatedRows)
+	if c.nullCount > 0 {
+		bytes += math.Ceil(float64(estimatedRows) / 8)
+	}
+	return int64(math.Ceil(bytes))
+}
@@ -0,0 +1,48 @@
+package tablestats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableStats_Memory(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header:        []string{"id", "code", "note", "score"},
+		EstimatedRows: 400,
+	}, [][]string{
+		{"1", "AAA", "", "3"},
+		{"2", "BBB", "x", ""},
+		{"3", "CC", "", "4.5"},
+		{"4", " DDD ", "", "5"},
+	})
+	stats := AnalyzeSample(sample, DefaultSamplingConfig())
+	expected := map[string]int64{
+		"id":    3200,         // 8 bytes per row
+		"code":  2700,         // 4-byte offsets and 2.75 bytes of trimmed text per row
+		"note":  1700 + 400/8, // Offsets, 0.25 bytes per row and a bitmap of the nulls
+		"score": 3200 + 400/8, // 8 bytes per float and a bitmap of the null
+	}
+	if stats.Memory == nil {
+		t.Fatal("Expected a memory footprint")
+	}
+	var total int64
+	for name, bytes := range expected {
+		if got := stats.Memory.Columns[name]; got != bytes {
+			t.Errorf("Column %s: expected %d bytes, got %d", name, bytes, got)
+		}
+		total += bytes
+	}
+	if stats.Memory.Total != total {
+		t.Errorf("Expected %d bytes in total, got %d", total, stats.Memory.Total)
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{Wide: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, line := range []string{"Estimated Memory: 10.9 kB\n", "    Estimated Memory: 3.2 kB\n"} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in the report, got:\n%s", line, b.String())
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package tablestats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxGroups is the largest number of values of the SamplingConfig.GroupBy
+// column that are aggregated apart; rows with further values are aggregated
+// together as the other group
+const MaxGroups = 50
+
+// GroupedStats holds the numeric aggregates of each value of a column
+type GroupedStats struct {
+	Column string       `json:"column"`
+	Groups []GroupStats `json:"groups"` // Most rows first
+}
+
+// GroupStats holds the aggregates of the rows sharing a value of the
+// grouping column
+type GroupStats struct {
+	Value      string                     `json:"value"`
+	Null       bool                       `json:"null,omitempty"`  // The rows where the column is null
+	Other      bool                       `json:"other,omitempty"` // The rows with values past the first MaxGroups
+	Rows       int64                      `json:"rows"`
+	Aggregates map[string]*AggregateStats `json:"aggregates"` // Numeric column -> aggregates over the group
+}
+
+// Label names the group in reports
+func (g *GroupStats) Label() string {
+	switch {
+	case g.Null:
+		return "(null)"
+	case g.Other:
+		return "(other)"
+	default:
+		return g.Value
+	}
+}
+
+// groupIndex returns the header position of the GroupBy column, or -1 when
+// rows are not grouped
+func (c SamplingConfig) groupIndex(header []string) (int, error) {
+	if c.GroupBy == "" {
+		return -1, nil
+	}
+	for i, h := range header {
+		if h == c.GroupBy {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("group by column %q: %w", c.GroupBy, ErrUnknownColumn)
+}
+
+// groupAccumulator aggregates the numbers of the profiled columns in one group
+type groupAccumulator struct {
+	rows    int64
+	columns []*numericSummary
+}
+
+// groupTracker splits rows by the value of a column and aggregates the numbers
+// of each profiled column per group. Percentiles come from t-digests, so
+// memory is bounded by MaxGroups rather than by the number of rows.
+type groupTracker struct {
+	column  string
+	idx     int
+	indexes []int // Positions of the profiled columns
+	groups  map[string]*groupAccumulator
+	null    *groupAccumulator
+	other   *groupAccumulator
+}
+
+func newGroupTracker(column string, idx int, indexes []int) *groupTracker {
+	return &groupTracker{
+		column:  column,
+		idx:     idx,
+		indexes: indexes,
+		groups:  make(map[string]*groupAccumulator),
+	}
+}
+
+func (g *groupTracker) newGroup() *groupAccumulator {
+	group := &groupAccumulator{columns: make([]*numericSummary, len(g.indexes))}
+	for i := range group.columns {
+		group.columns[i] = &numericSummary{digest: newTDigest()}
+	}
+	return group
+}
+
+// group returns the group of a record, creating it when needed
+func (g *groupTracker) group(record []string) *groupAccumulator {
+	value := ""
+	if g.idx < len(record) {
+		value = strings.TrimSpace(record[g.idx])
+	}
+	if isNullValue(value) {
+		if g.null == nil {
+			g.null = g.newGroup()
+		}
+		return g.null
+	}
+	if group, ok := g.groups[value]; ok {
+		return group
+	}
+	if len(g.groups) < MaxGroups {
+		group := g.newGroup()
+		g.groups[value] = group
+		return group
+	}
+	if g.other == nil {
+		g.other = g.newGroup()
+	}
+	return g.other
+}
+
+// add records a row with its inverse-probability weight, 0 when unweighted
+func (g *groupTracker) add(record []string, weight float64) {
+	group := g.group(record)
+	group.rows++
+	for i, idx := range g.indexes {
+		if idx >= len(record) {
+			continue
+		}
+		kind, v := classifyNumber(strings.TrimSpace(record[idx]))
+		if kind == notNumber || !isFinite(v) {
+			continue
+		}
+		if weight > 0 {
+			group.columns[i].addWeighted(v, weight)
+		} else {
+			group.columns[i].add(v)
+		}
+	}
+}
+
+// result aggregates the groups over the columns stats found numeric
+func (g *groupTracker) result(stats *TableStats) *GroupedStats {
+	grouped := &GroupedStats{Column: g.column}
+	add := func(value string, group *groupAccumulator) *GroupStats {
+		gs := GroupStats{Value: value, Rows: group.rows, Aggregates: make(map[string]*AggregateStats)}
+		for i, summary := range group.columns {
+			name := stats.ColumnNames[i]
+			if _, ok := stats.Aggregates[name]; !ok || summary.count == 0 {
+				continue
+			}
+			agg := summary.aggregates()
+			agg.Histogram, agg.Quantiles = nil, nil
+			gs.Aggregates[name] = agg
+		}
+		grouped.Groups = append(grouped.Groups, gs)
+		return &grouped.Groups[len(grouped.Groups)-1]
+	}
+	for value, group := range g.groups {
+		add(value, group)
+	}
+	sort.Slice(grouped.Groups, func(i, j int) bool {
+		a, b := grouped.Groups[i], grouped.Groups[j]
+		if a.Rows != b.Rows {
+			return a.Rows > b.Rows
+		}
+		return a.Value < b.Value
+	})
+	if g.null != nil {
+		add("", g.null).Null = true
+	}
+	if g.other != nil {
+		add("", g.other).Other = true
+	}
+	return grouped
+}
+
+// groupTables returns a table per numeric column, with a row per group of its
+// count, mean and 95th percentile, or a single table of the group sizes when
+// no column is numeric. The first row of each table is its header.
+func (g *GroupedStats) groupTables(stats *TableStats, nw numberWriter) (titles []string, tables [][][]string) {
+	for _, name := range stats.ColumnNames {
+		if _, ok := stats.Aggregates[name]; !ok || name == g.Column {
+			continue
+		}
+		table := [][]string{{"Group", "Rows", "Count", "Mean", "P95"}}
+		for _, group := range g.Groups {
+			row := []string{group.Label(), nw.int(group.Rows), "0", "", ""}
+			if agg, ok := group.Aggregates[name]; ok {
+				row[2], row[3], row[4] = nw.int(agg.Count), nw.float(agg.Mean), nw.float(agg.Percentiles[95])
+			}
+			table = append(table, row)
+		}
+		titles = append(titles, name)
+		tables = append(tables, table)
+	}
+	if len(tables) == 0 {
+		table := [][]string{{"Group", "Rows"}}
+		for _, group := range g.Groups {
+			table = append(table, []string{group.Label(), nw.int(group.Rows)})
+		}
+		titles, tables = []string{""}, [][][]string{table}
+	}
+	return titles, tables
+}
+
+// renderGroups prints the group tables of the text report
+func (r *TextRenderer) renderGroups(ew *errWriter, nw numberWriter, stats *TableStats) {
+	g := stats.Groups
+	ew.printf("\nGroups by %s (%d groups):\n", g.Column, len(g.Groups))
+	titles, tables := g.groupTables(stats, nw)
+	for i, table := range tables {
+		indent := "  "
+		if titles[i] != "" {
+			ew.printf("  %s:\n", titles[i])
+			indent = "    "
+		}
+		for j := range table {
+			table[j][0] = cell(table[j][0])
+		}
+		for _, line := range alignRows(table) {
+			ew.printf("%s%s\n", indent, line)
+		}
+	}
+}
+
+// renderGroups prints the group tables of the markdown report
+func (r *MarkdownRenderer) renderGroups(ew *errWriter, nw numberWriter, stats *TableStats) {
+	g := stats.Groups
+	ew.printf("\nGroups by %s:\n", markdownCell(g.Column))
+	titles, tables := g.groupTables(stats, nw)
+	for i, table := range tables {
+		ew.printf("\n")
+		if titles[i] != "" {
+			ew.printf("%s:\n\n", markdownCell(titles[i]))
+		}
+		ew.printf("| %s |\n", strings.Join(table[0], " | "))
+		ew.printf("| --- |%s\n", strings.Repeat(" ---: |", len(table[0])-1))
+		for _, row := range table[1:] {
+			ew.printf("| %s |\n", strings.Join(markdownCells(row), " | "))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTableAccumulator_GroupBy(t *testing.T) {
+	header := []string{"category", "amount", "note"}
+	records := [][]string{
+		{"books", "10", "a"},
+		{"books", "20", "b"},
+		{" books ", "", "c"},
+		{"toys", "5", "d"},
+		{"", "7", "e"},
+	}
+	acc, err := NewTableAccumulator(header, SamplingConfig{GroupBy: "category"})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range records {
+		acc.Add(record)
+	}
+	groups := acc.Finalize().Groups
+
+	if groups == nil || groups.Column != "category" || len(groups.Groups) != 3 {
+		t.Fatalf("Expected 3 groups by category, got %+v", groups)
+	}
+	books, toys, null := groups.Groups[0], groups.Groups[1], groups.Groups[2]
+	if books.Value != "books" || books.Rows != 3 {
+		t.Errorf("Expected 3 rows of books first, got %+v", books)
+	}
+	if agg := books.Aggregates["amount"]; agg == nil || agg.Count != 2 || agg.Mean != 15 {
+		t.Errorf("Expected 2 amounts of books with mean 15, got %+v", agg)
+	}
+	if _, ok := books.Aggregates["note"]; ok {
+		t.Error("Expected no aggregates of the text column")
+	}
+	if toys.Value != "toys" || toys.Rows != 1 {
+		t.Errorf("Expected 1 row of toys, got %+v", toys)
+	}
+	if !null.Null || null.Rows != 1 || null.Label() != "(null)" {
+		t.Errorf("Expected the null group last, got %+v", null)
+	}
+
+	if _, err := NewTableAccumulator(header, SamplingConfig{GroupBy: "kind"}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestTableAccumulator_GroupByOther(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"id", "n"}, SamplingConfig{GroupBy: "id"})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for i := range MaxGroups + 10 {
+		acc.Add([]string{fmt.Sprint(i), "1"})
+	}
+	groups := acc.Finalize().Groups.Groups
+	if len(groups) != MaxGroups+1 {
+		t.Fatalf("Expected %d groups, got %d", MaxGroups+1, len(groups))
+	}
+	if other := groups[MaxGroups]; !other.Other || other.Rows != 10 {
+		t.Errorf("Expected 10 rows in the other group, got %+v", other)
+	}
+}
+
+func TestReadTable_GroupBy(t *testing.T) {
+	data := "category,amount\n" + strings.Repeat("a,1\nb,3\n", 100)
+	config := DefaultSamplingConfig()
+	config.GroupBy = "category"
+	config.Columns = []string{"amount"}
+	stats, err := NewCSVReader(',').ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadTableFrom failed: %v", err)
+	}
+	if stats.Groups == nil || len(stats.Groups.Groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %+v", stats.Groups)
+	}
+	for _, g := range stats.Groups.Groups {
+		if agg := g.Aggregates["amount"]; g.Rows != 100 || agg == nil || agg.Percentiles[95] != map[string]float64{"a": 1, "b": 3}[g.Value] {
+			t.Errorf("Unexpected group %+v", g)
+		}
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `Groups by category (2 groups):
+  amount:
+    Group  Rows  Count  Mean  P95
+    a      100   100    1.00  1.00
+    b      100   100    3.00  3.00
+`
+	if !strings.Contains(b.String(), expected) {
+		t.Errorf("Expected %q in the report, got:\n%s", expected, b.String())
+	}
+}
+
+func TestTableStats_MaskGroups(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header: []string{"email", "amount"},
+	}, [][]string{{"a@example.com", "1"}, {"", "2"}}), SamplingConfig{GroupBy: "email"})
+
+	if err := stats.Mask([]string{"email"}, Masking{}); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+	if g := stats.Groups.Groups; g[0].Value != redacted || !g[1].Null {
+		t.Errorf("Expected the email group to be masked, got %+v", g)
+	}
+}
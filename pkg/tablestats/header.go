@@ -0,0 +1,75 @@
+package tablestats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ColumnRename records a header name that was changed so that every column
+// has a name of its own
+type ColumnRename struct {
+	Position int    `json:"position"` // 1-based position of the column in the header
+	Original string `json:"original"` // Name in the header, empty or a repeat of an earlier column's
+	Name     string `json:"name"`     // Name the column is profiled under
+}
+
+// renameSummary describes renamed columns, e.g. `column 2 "" as "column_2",
+// column 4 "id" as "id_2"`
+func renameSummary(renames []ColumnRename) string {
+	parts := make([]string, len(renames))
+	for i, r := range renames {
+		parts[i] = fmt.Sprintf("column %d %q as %q", r.Position, r.Original, r.Name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// uniqueHeader names the columns of a header uniquely. Blank names become
+// column_<position>; the second and later columns sharing a name get the
+// suffix _2, _3 and so on, skipping names the header already uses. The header
+// is returned as is when no name changes.
+func uniqueHeader(header []string) ([]string, []ColumnRename) {
+	taken := make(map[string]bool, len(header))
+	for _, name := range header {
+		taken[name] = true
+	}
+
+	var unique []string
+	var renames []ColumnRename
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		newName := name
+		if strings.TrimSpace(name) == "" {
+			newName = "column_" + strconv.Itoa(i+1)
+			if taken[newName] {
+				newName = freeName(taken, newName)
+			}
+		} else if seen[name] {
+			newName = freeName(taken, name)
+		}
+		seen[name] = true
+		if newName == name {
+			continue
+		}
+		if unique == nil {
+			unique = append([]string(nil), header...)
+		}
+		unique[i] = newName
+		taken[newName] = true
+		renames = append(renames, ColumnRename{Position: i + 1, Original: name, Name: newName})
+	}
+	if unique == nil {
+		return header, nil
+	}
+	return unique, renames
+}
+
+// freeName returns base followed by the first of _2, _3 and so on that no
+// column is named yet
+func freeName(taken map[string]bool, base string) string {
+	for n := 2; ; n++ {
+		if name := base + "_" + strconv.Itoa(n); !taken[name] {
+			return name
+		}
+	}
+}
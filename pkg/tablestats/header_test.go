@@ -0,0 +1,66 @@
+package tablestats
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestUniqueHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []string
+		expected []string
+		renamed  []int
+	}{
+		{"unique", []string{"id", "name"}, []string{"id", "name"}, nil},
+		{"repeated", []string{"id", "id", "id"}, []string{"id", "id_2", "id_3"}, []int{2, 3}},
+		{"blank", []string{"id", "", " "}, []string{"id", "column_2", "column_3"}, []int{2, 3}},
+		{"suffix taken", []string{"id", "id", "id_2"}, []string{"id", "id_3", "id_2"}, []int{2}},
+		{"blank name taken", []string{"column_2", ""}, []string{"column_2", "column_2_2"}, []int{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, renames := uniqueHeader(tt.header)
+			if !reflect.DeepEqual(header, tt.expected) {
+				t.Errorf("Expected header %v, got %v", tt.expected, header)
+			}
+			var positions []int
+			for _, r := range renames {
+				positions = append(positions, r.Position)
+				if r.Original != tt.header[r.Position-1] || r.Name != header[r.Position-1] {
+					t.Errorf("Expected rename of %q to %q, got %+v", tt.header[r.Position-1], header[r.Position-1], r)
+				}
+			}
+			if !reflect.DeepEqual(positions, tt.renamed) {
+				t.Errorf("Expected renamed positions %v, got %v", tt.renamed, positions)
+			}
+		})
+	}
+}
+
+func TestReadTable_DuplicateHeader(t *testing.T) {
+	tmpFile := writeRawFile(t, "test.csv", []byte("id,,id\n1,x,\n2,,10\n"))
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5, Columns: []string{"id", "id_2"}}
+
+	for _, reader := range []*CSVReader{NewCSVReader(','), {Delimiter: ',', LazyQuotes: true}} {
+		stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+		if err != nil {
+			t.Fatalf("ReadTable failed: %v", err)
+		}
+		if !reflect.DeepEqual(stats.ColumnNames, []string{"id", "id_2"}) {
+			t.Errorf("Expected columns [id id_2], got %v", stats.ColumnNames)
+		}
+		// Each column keeps the statistics of its own position
+		if stats.NullCounts["id"] != 0 || stats.NullCounts["id_2"] != 1 {
+			t.Errorf("Expected 0 and 1 nulls, got %d and %d", stats.NullCounts["id"], stats.NullCounts["id_2"])
+		}
+		if stats.MaxValues["id"] != int64(2) || stats.MaxValues["id_2"] != int64(10) {
+			t.Errorf("Expected maxima 2 and 10, got %v and %v", stats.MaxValues["id"], stats.MaxValues["id_2"])
+		}
+		expected := []ColumnRename{{Position: 2, Original: "", Name: "column_2"}, {Position: 3, Original: "id", Name: "id_2"}}
+		if !reflect.DeepEqual(stats.RenamedColumns, expected) {
+			t.Errorf("Expected renames %+v, got %+v", expected, stats.RenamedColumns)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tablestats
+
+import (
+	"math"
+	"os"
+	"sort"
+)
+
+// calculateAggregates computes statistical aggregates for numeric data
+func calculateAggregates(values []float64) *AggregateStats {
+	var summary numericSummary
+	for _, v := range values {
+		summary.add(v)
+	}
+	return summary.aggregates()
+}
+
+func calculatePercentile(sortedValues []float64, percentile int) float64 {
+	if len(sortedValues) == 0 {
+		return 0
+	}
+
+	index := float64(percentile) / 100.0 * float64(len(sortedValues)-1)
+
+	if index == float64(int(index)) {
+		return sortedValues[int(index)]
+	}
+
+	lower := int(math.Floor(index))
+	upper := int(math.Ceil(index))
+	weight := index - float64(lower)
+
+	return sortedValues[lower]*(1-weight) + sortedValues[upper]*weight
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// PrintStats writes the text report to stdout.
+//
+// Deprecated: use TextRenderer, which writes to any io.Writer.
+func PrintStats(stats *TableStats, format string) {
+	(&TextRenderer{Title: format}).Render(os.Stdout, stats)
+}
@@ -1,4 +1,4 @@
-package stats
+package tablestats
 
 import (
 	"bytes"
@@ -328,32 +328,14 @@ func TestPrintStats(t *testing.T) {
 	// Test various parts of the output
 	expectedStrings := []string{
 		"=== CSV File Statistics ===",
-		"Sampled Rows: 1000",
-		"Estimated Total Rows: 5000",
+		"Sampled Rows: 1,000",
+		"Estimated Total Rows: 5,000",
 		"Columns: 3",
 		"Column Names: [id name age]",
-		"id:",
-		"Type: integer",
-		"name:",
-		"Type: string",
-		"age:",
-		"Type: float",
-		"Null Count: 0 (0.00%)",
-		"Null Count: 10 (1.00%)",
-		"Null Count: 5 (0.50%)",
-		"Min: 1",
-		"Max: 1000",
-		"Min: Alice",
-		"Max: Zoe",
-		"Min: 18.5",
-		"Max: 65.2",
-		"Aggregates:",
-		"Count: 995",
-		"Sum: 25000.00",
-		"Mean: 25.13",
-		"Median: 24.50",
-		"Std Dev: 12.50",
-		"Percentiles: 25th=20.00, 75th=30.00, 95th=45.00, 99th=50.00",
+		"  Column  Type     Nulls       Min    Max   Mean   P95    Distinct\n",
+		"  id      integer  0 (0.00%)   1      1000                0\n",
+		"  name    string   10 (1.00%)  Alice  Zoe                 0\n",
+		"  age     float    5 (0.50%)   18.5   65.2  25.13  45.00  0\n",
 		"Sample Data:",
 		"Row 1: [1 Alice 25.5]",
 		"Row 2: [2 Bob 30.0]",
@@ -419,8 +401,7 @@ func TestPrintStatsWithoutAggregatesAndSampleData(t *testing.T) {
 	expectedStrings := []string{
 		"=== JSON File Statistics ===",
 		"Sampled Rows: 100",
-		"name:",
-		"Type: string",
+		"  name    string  0 (0.00%)  Alice  Zoe",
 	}
 
 	for _, expected := range expectedStrings {
@@ -0,0 +1,124 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// histogramBins is the number of equal-width bins of a column histogram
+const histogramBins = 10
+
+// histogramBarWidth is the number of characters of the longest histogram bar
+const histogramBarWidth = 40
+
+// HistogramBin counts the values of a numeric column between Low and High.
+// Every bin but the last excludes High.
+type HistogramBin struct {
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+	Count int64   `json:"count"` // Values in the bin, estimated when the column was sketched or weighted
+}
+
+// histogram bins the finite values into histogramBins equal-width bins over
+// their range. weights, when not nil, holds the weight of each value; the
+// counts are scaled so that they add up to about count.
+func histogram(values, weights []float64, count int64) []HistogramBin {
+	return binValues(values, weights, count, histogramBins)
+}
+
+// binValues is histogram with the given number of bins
+func binValues(values, weights []float64, count int64, bins int) []HistogramBin {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if isFinite(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if lo > hi {
+		return nil
+	}
+
+	if lo == hi {
+		bins = 1
+	}
+	// Divided first so that ranges wider than the largest float64 do not overflow
+	width := hi/float64(bins) - lo/float64(bins)
+	totals := make([]float64, bins)
+	total := 0.0
+	for i, v := range values {
+		if !isFinite(v) {
+			continue
+		}
+		w := 1.0
+		if weights != nil {
+			w = weights[i]
+		}
+		b := bins - 1
+		if width > 0 {
+			b = max(0, min(bins-1, int(v/width-lo/width)))
+		}
+		totals[b] += w
+		total += w
+	}
+	if total == 0 {
+		return nil
+	}
+
+	result := make([]HistogramBin, bins)
+	for b := range result {
+		result[b] = HistogramBin{
+			Low:   lo + float64(b)*width,
+			High:  lo + float64(b+1)*width,
+			Count: int64(math.Round(totals[b] / total * float64(count))),
+		}
+	}
+	result[bins-1].High = hi
+	return result
+}
+
+// histogramLines draws bins as bars of '#' scaled to the fullest bin, e.g.
+// "[  0.00,  10.00) ######## 120"
+func histogramLines(bins []HistogramBin, nw numberWriter) []string {
+	var most int64
+	lowWidth, highWidth := 0, 0
+	for _, b := range bins {
+		most = max(most, b.Count)
+		lowWidth = max(lowWidth, utf8.RuneCountInString(nw.float(b.Low)))
+		highWidth = max(highWidth, utf8.RuneCountInString(nw.float(b.High)))
+	}
+
+	lines := make([]string, len(bins))
+	for i, b := range bins {
+		bar := 0
+		if most > 0 {
+			bar = int(b.Count * histogramBarWidth / most)
+		}
+		if bar == 0 && b.Count > 0 {
+			// Keep rare values visible
+			bar = 1
+		}
+		closing := ")"
+		if i == len(bins)-1 {
+			closing = "]"
+		}
+		lines[i] = fmt.Sprintf("[%s, %s%s %-*s %s", padLeft(nw.float(b.Low), lowWidth), padLeft(nw.float(b.High), highWidth),
+			closing, histogramBarWidth, strings.Repeat("#", bar), nw.int(b.Count))
+	}
+	return lines
+}
+
+// histogramSparkline draws the bin counts as a sparkline, e.g. "▁▂▅▇█▅▂▁"
+func histogramSparkline(bins []HistogramBin) string {
+	counts := make([]float64, len(bins))
+	for i, b := range bins {
+		counts[i] = float64(b.Count)
+	}
+	return Sparkline(counts)
+}
+
+// padLeft pads s with spaces to width characters
+func padLeft(s string, width int) string {
+	return strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s))) + s
+}
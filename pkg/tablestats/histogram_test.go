@@ -0,0 +1,94 @@
+package tablestats
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHistogram(t *testing.T) {
+	bins := histogram([]float64{0, 1, 2, 2.5, 9, 10, math.NaN()}, nil, 6)
+	if len(bins) != histogramBins {
+		t.Fatalf("Expected %d bins, got %d", histogramBins, len(bins))
+	}
+	expected := []int64{1, 1, 2, 0, 0, 0, 0, 0, 0, 2}
+	for i, b := range bins {
+		if b.Count != expected[i] {
+			t.Errorf("Expected %d values in bin %d, got %d", expected[i], i, b.Count)
+		}
+	}
+	if bins[0].Low != 0 || bins[0].High != 1 || bins[9].High != 10 {
+		t.Errorf("Expected bins from 0 to 10 by 1, got %+v", bins)
+	}
+
+	// Counts of a subset or of weighted values are scaled to the column
+	bins = histogram([]float64{1, 2}, []float64{3, 1}, 100)
+	if bins[0].Count != 75 || bins[9].Count != 25 {
+		t.Errorf("Expected 75 and 25 values, got %d and %d", bins[0].Count, bins[9].Count)
+	}
+
+	if bins := histogram([]float64{5, 5}, nil, 2); len(bins) != 1 || bins[0].Count != 2 {
+		t.Errorf("Expected a single bin of 2 values, got %+v", bins)
+	}
+	if bins := histogram([]float64{-1e308, 1e308, 1e308}, nil, 3); bins[0].Count != 1 || bins[9].Count != 2 {
+		t.Errorf("Expected the full float64 range binned, got %+v", bins)
+	}
+	if bins := histogram([]float64{math.Inf(1)}, nil, 1); bins != nil {
+		t.Errorf("Expected no bins without finite values, got %+v", bins)
+	}
+}
+
+func TestHistogramLines(t *testing.T) {
+	lines := histogramLines([]HistogramBin{{Low: 0, High: 50, Count: 80}, {Low: 50, High: 100, Count: 1}}, NumberFormat{}.writer())
+	expected := []string{
+		"[ 0.00,  50.00) " + strings.Repeat("#", histogramBarWidth) + " 80",
+		"[50.00, 100.00] #" + strings.Repeat(" ", histogramBarWidth-1) + " 1",
+	}
+	for i, line := range lines {
+		if line != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], line)
+		}
+	}
+}
+
+func TestRender_Histogram(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"n"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, v := range []string{"1", "2", "2", "3", "10"} {
+		acc.Add([]string{v})
+	}
+	var b strings.Builder
+	if err := (&TextRenderer{Wide: true}).Render(&b, acc.Finalize()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "      Histogram:\n        [1.00,  1.90) ") {
+		t.Errorf("Expected a histogram in the report, got:\n%s", b.String())
+	}
+}
+
+func TestRender_Sparkline(t *testing.T) {
+	stats := &TableStats{
+		ColumnNames: []string{"n"},
+		ColumnTypes: map[string]string{"n": "int64"},
+		Aggregates: map[string]*AggregateStats{"n": {Count: 15, Histogram: []HistogramBin{
+			{Low: 0, High: 1, Count: 1}, {Low: 1, High: 2, Count: 8}, {Low: 2, High: 3, Count: 6},
+		}}},
+	}
+	var b strings.Builder
+	if err := (&TextRenderer{Wide: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "  n: ▁█▆\n") {
+		t.Errorf("Expected a sparkline next to the column, got:\n%s", b.String())
+	}
+
+	b.Reset()
+	if err := (&TextRenderer{Wide: true, NoSparklines: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "  n:\n") {
+		t.Errorf("Expected no sparkline, got:\n%s", b.String())
+	}
+}
@@ -0,0 +1,284 @@
+package tablestats
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// historyTimeLayout names run files so that they sort by time
+const historyTimeLayout = "20060102T150405.000000000Z"
+
+// HistoryStore keeps every profile of a data file, one JSON file per run in a
+// directory per file, to follow how the file changes over time
+type HistoryStore struct {
+	Dir string
+}
+
+// NewHistoryStore returns a store that keeps its runs in dir
+func NewHistoryStore(dir string) *HistoryStore {
+	return &HistoryStore{Dir: dir}
+}
+
+// HistoryRun is a stored profile and when it was made
+type HistoryRun struct {
+	Time  time.Time
+	Stats *TableStats
+}
+
+// Path returns the directory the runs of filePath are kept in. Files are
+// keyed by their absolute path, like in BaselineStore, and URLs by themselves.
+func (s *HistoryStore) Path(filePath string) (string, error) {
+	abs := filePath
+	if !strings.Contains(filePath, "://") {
+		var err error
+		if abs, err = filepath.Abs(filePath); err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", filePath, err)
+		}
+	}
+	sum := sha256.Sum256([]byte(abs))
+	name := strings.TrimSuffix(filepath.Base(abs), filepath.Ext(abs))
+	return filepath.Join(s.Dir, name+"-"+hex.EncodeToString(sum[:6])), nil
+}
+
+// Append records stats as the run of filePath at t
+func (s *HistoryStore) Append(filePath string, stats *TableStats, t time.Time) error {
+	dir, err := s.Path(filePath)
+	if err != nil {
+		return err
+	}
+	data, err := Marshal(stats)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, t.UTC().Format(historyTimeLayout)+".json")
+	if err := writeFileAtomic(dir, path, data); err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	return nil
+}
+
+// Runs returns the recorded runs of filePath, oldest first
+func (s *HistoryStore) Runs(filePath string) ([]HistoryRun, error) {
+	dir, err := s.Path(filePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var runs []HistoryRun
+	for _, entry := range entries {
+		t, err := time.Parse(historyTimeLayout, strings.TrimSuffix(entry.Name(), ".json"))
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || err != nil {
+			continue
+		}
+		stats, err := LoadBaseline(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, HistoryRun{Time: t, Stats: stats})
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Time.Before(runs[j].Time) })
+	return runs, nil
+}
+
+// Trend holds metrics of a file across runs
+type Trend struct {
+	Times  []time.Time
+	Series []TrendSeries
+}
+
+// TrendSeries is one metric across runs, named like MetricDelta: rows,
+// columns, or null_pct, distinct and mean of a column
+type TrendSeries struct {
+	Column string // Empty for table-level metrics
+	Metric string
+	Values []float64 // NaN for runs without the column or metric
+}
+
+// Name returns the qualified metric name, e.g. "amount.mean"
+func (s TrendSeries) Name() string {
+	if s.Column == "" {
+		return s.Metric
+	}
+	return s.Column + "." + s.Metric
+}
+
+// BuildTrend collects the metrics of runs, oldest first. Columns appear in
+// the order of the latest run, followed by columns that were dropped.
+func BuildTrend(runs []HistoryRun) *Trend {
+	t := &Trend{}
+	for _, run := range runs {
+		t.Times = append(t.Times, run.Time)
+	}
+	t.Series = append(t.Series,
+		trendSeries(runs, "", "rows", func(s *TableStats) (float64, bool) { return float64(s.EstimatedRows), true }),
+		trendSeries(runs, "", "columns", func(s *TableStats) (float64, bool) { return float64(s.ColumnCount), true }))
+
+	var columns []string
+	seen := make(map[string]bool)
+	for i := len(runs) - 1; i >= 0; i-- {
+		for _, name := range runs[i].Stats.ColumnNames {
+			if !seen[name] {
+				seen[name] = true
+				columns = append(columns, name)
+			}
+		}
+	}
+
+	for _, name := range columns {
+		t.Series = append(t.Series,
+			trendSeries(runs, name, "null_pct", func(s *TableStats) (float64, bool) {
+				v, ok := s.NullPercentage[name]
+				return v, ok
+			}),
+			trendSeries(runs, name, "distinct", func(s *TableStats) (float64, bool) {
+				v, ok := s.DistinctCounts[name]
+				return float64(v), ok
+			}))
+		mean := trendSeries(runs, name, "mean", func(s *TableStats) (float64, bool) {
+			if agg := s.Aggregates[name]; agg != nil {
+				return agg.Mean, true
+			}
+			return 0, false
+		})
+		if mean.points() > 0 {
+			t.Series = append(t.Series, mean)
+		}
+	}
+	return t
+}
+
+func trendSeries(runs []HistoryRun, column, metric string, value func(*TableStats) (float64, bool)) TrendSeries {
+	s := TrendSeries{Column: column, Metric: metric, Values: make([]float64, len(runs))}
+	for i, run := range runs {
+		v, ok := value(run.Stats)
+		if !ok {
+			v = math.NaN()
+		}
+		s.Values[i] = v
+	}
+	return s
+}
+
+// points returns the number of runs the series has a value for
+func (s TrendSeries) points() int {
+	n := 0
+	for _, v := range s.Values {
+		if !math.IsNaN(v) {
+			n++
+		}
+	}
+	return n
+}
+
+// sparkBlocks are the bars of a sparkline, from lowest to highest
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a line of block characters scaled between their
+// minimum and maximum. NaN values are left blank.
+func Sparkline(values []float64) string {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case hi == lo:
+			b.WriteRune(sparkBlocks[len(sparkBlocks)/2])
+		default:
+			i := int((v - lo) / (hi - lo) * float64(len(sparkBlocks)-1))
+			b.WriteRune(sparkBlocks[i])
+		}
+	}
+	return b.String()
+}
+
+// WriteText writes a sparkline per metric with its first and latest value
+func (t *Trend) WriteText(w io.Writer, title string) error {
+	ew := &errWriter{w: w}
+	ew.printf("=== %s History ===\n", title)
+	if len(t.Times) == 0 {
+		ew.printf("No runs recorded\n\n")
+		return ew.err
+	}
+	ew.printf("Runs: %d from %s to %s\n\n", len(t.Times),
+		t.Times[0].Local().Format(time.DateTime), t.Times[len(t.Times)-1].Local().Format(time.DateTime))
+
+	width := 0
+	for _, s := range t.Series {
+		width = max(width, len(s.Name()))
+	}
+	for _, s := range t.Series {
+		first, last := math.NaN(), math.NaN()
+		for _, v := range s.Values {
+			if !math.IsNaN(v) {
+				if math.IsNaN(first) {
+					first = v
+				}
+				last = v
+			}
+		}
+		ew.printf("  %-*s  %s  %s -> %s\n", width, s.Name(), Sparkline(s.Values), trendValue(first), trendValue(last))
+	}
+	ew.printf("\n")
+	return ew.err
+}
+
+func trendValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "-"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	default:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+}
+
+// WriteCSV writes a row per run: its time followed by every metric, with
+// empty cells where a run lacks the metric
+func (t *Trend) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"time"}
+	for _, s := range t.Series {
+		header = append(header, s.Name())
+	}
+	cw.Write(header)
+	for i, runTime := range t.Times {
+		row := []string{runTime.UTC().Format(time.RFC3339)}
+		for _, s := range t.Series {
+			if math.IsNaN(s.Values[i]) {
+				row = append(row, "")
+			} else {
+				row = append(row, strconv.FormatFloat(s.Values[i], 'f', -1, 64))
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,108 @@
+package tablestats
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryStore(t *testing.T) {
+	store := NewHistoryStore(filepath.Join(t.TempDir(), "history"))
+
+	runs, err := store.Runs("data/feed.csv")
+	if err != nil || len(runs) != 0 {
+		t.Errorf("Expected no runs, got %v, %v", runs, err)
+	}
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Appended out of order; runs come back oldest first
+	for _, day := range []int{2, 0, 1} {
+		stats := &TableStats{EstimatedRows: int64(100 * (day + 1))}
+		if err := store.Append("data/feed.csv", stats, start.AddDate(0, 0, day)); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	store.Append("other/feed.csv", &TableStats{EstimatedRows: 5}, start)
+
+	runs, err = store.Runs("data/feed.csv")
+	if err != nil {
+		t.Fatalf("Runs failed: %v", err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("Expected 3 runs, got %d", len(runs))
+	}
+	for i, run := range runs {
+		if !run.Time.Equal(start.AddDate(0, 0, i)) || run.Stats.EstimatedRows != int64(100*(i+1)) {
+			t.Errorf("Expected run %d from day %d, got %v with %d rows", i, i, run.Time, run.Stats.EstimatedRows)
+		}
+	}
+}
+
+func TestBuildTrend(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	runs := []HistoryRun{
+		{Time: start, Stats: &TableStats{
+			EstimatedRows: 100, ColumnCount: 2, ColumnNames: []string{"id", "old"},
+			NullPercentage: map[string]float64{"id": 0, "old": 50},
+			DistinctCounts: map[string]int64{"id": 100, "old": 3},
+			Aggregates:     map[string]*AggregateStats{"id": {Mean: 50.5}},
+		}},
+		{Time: start.Add(time.Hour), Stats: &TableStats{
+			EstimatedRows: 200, ColumnCount: 1, ColumnNames: []string{"id"},
+			NullPercentage: map[string]float64{"id": 1.5},
+			DistinctCounts: map[string]int64{"id": 197},
+			Aggregates:     map[string]*AggregateStats{"id": {Mean: 100.5}},
+		}},
+	}
+	trend := BuildTrend(runs)
+
+	var names []string
+	for _, s := range trend.Series {
+		names = append(names, s.Name())
+	}
+	expected := "rows,columns,id.null_pct,id.distinct,id.mean,old.null_pct,old.distinct"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("Expected series %s, got %s", expected, strings.Join(names, ","))
+	}
+	if old := trend.Series[5].Values; old[0] != 50 || !math.IsNaN(old[1]) {
+		t.Errorf("Expected a dropped column to have no value in later runs, got %v", old)
+	}
+
+	var buf bytes.Buffer
+	if err := trend.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[0] != "time,"+expected || lines[2] != "2024-01-01T01:00:00Z,200,1,1.5,197,100.5,," {
+		t.Errorf("Unexpected CSV:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := trend.WriteText(&buf, "feed.csv"); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Runs: 2") || !strings.Contains(buf.String(), "rows          ▁█  100 -> 200") {
+		t.Errorf("Unexpected text:\n%s", buf.String())
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values   []float64
+		expected string
+	}{
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8}, "▁▂▃▄▅▆▇█"},
+		{[]float64{0, 10, 5}, "▁█▄"},
+		{[]float64{3, 3}, "▅▅"},
+		{[]float64{1, math.NaN(), 2}, "▁ █"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := Sparkline(tt.values); got != tt.expected {
+			t.Errorf("Expected %q for %v, got %q", tt.expected, tt.values, got)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package tablestats
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// UnicodeIssues counts the values of a column holding characters that look
+// like others, or like nothing, but compare differently, which makes joins and
+// lookups on the column fail silently. A value is counted once per kind.
+type UnicodeIssues struct {
+	NonBreakingSpace int64 `json:"non_breaking_space,omitempty"` // Values with U+00A0, U+2007 or U+202F
+	ZeroWidth        int64 `json:"zero_width,omitempty"`         // Values with U+200B to U+200D, U+2060 or U+FEFF
+	Decomposed       int64 `json:"decomposed,omitempty"`         // Values that change under NFC, e.g. "e" followed by a combining accent
+	Composed         int64 `json:"composed,omitempty"`           // Values that change under NFD, e.g. a precomposed "é"
+}
+
+// MixedForms reports whether the column spells accented characters both
+// composed and decomposed, so that equal-looking values differ
+func (u *UnicodeIssues) MixedForms() bool {
+	return u.Decomposed > 0 && u.Composed > 0
+}
+
+// found reports whether any value has invisible characters or mixed forms
+func (u *UnicodeIssues) found() bool {
+	return u.NonBreakingSpace > 0 || u.ZeroWidth > 0 || u.MixedForms()
+}
+
+// PaddingCounts counts the values of a column that have whitespace around
+// them, which the profile trims but comparisons downstream may not. Blank
+// values are counted as empty nulls instead.
+type PaddingCounts struct {
+	Leading  int64 `json:"leading"`  // Values starting with whitespace
+	Trailing int64 `json:"trailing"` // Values ending with whitespace
+}
+
+// observePadding counts raw when trimming it left trimmed, a non-empty
+// part of it
+func observePadding[T fieldValue](w *PaddingCounts, raw, trimmed T) {
+	n := len(trimmed)
+	if n == 0 || n == len(raw) {
+		return
+	}
+	if string(raw[:n]) != string(trimmed) {
+		w.Leading++
+	}
+	if string(raw[len(raw)-n:]) != string(trimmed) {
+		w.Trailing++
+	}
+}
+
+// observeUnicode counts the issues of a raw, untrimmed value
+func observeUnicode[T fieldValue](u *UnicodeIssues, value T) {
+	// Every character checked for is outside ASCII
+	i := 0
+	for i < len(value) && value[i] < utf8.RuneSelf {
+		i++
+	}
+	if i == len(value) {
+		return
+	}
+
+	var nbsp, zeroWidth bool
+	for _, r := range string(value[i:]) {
+		switch r {
+		case '\u00a0', '\u2007', '\u202f':
+			nbsp = true
+		case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+			zeroWidth = true
+		}
+	}
+	if nbsp {
+		u.NonBreakingSpace++
+	}
+	if zeroWidth {
+		u.ZeroWidth++
+	}
+	if !isNormal(norm.NFC, value) {
+		u.Decomposed++
+	}
+	if !isNormal(norm.NFD, value) {
+		u.Composed++
+	}
+}
+
+func isNormal[T fieldValue](form norm.Form, value T) bool {
+	if v, ok := any(value).([]byte); ok {
+		return form.IsNormal(v)
+	}
+	return form.IsNormalString(string(value))
+}
+
+// summary describes the issues, e.g. "2 values with non-breaking spaces, 1
+// with zero-width characters"
+func (u *UnicodeIssues) summary() string {
+	var parts []string
+	if u.NonBreakingSpace > 0 {
+		parts = append(parts, fmt.Sprintf("%d with non-breaking spaces", u.NonBreakingSpace))
+	}
+	if u.ZeroWidth > 0 {
+		parts = append(parts, fmt.Sprintf("%d with zero-width characters", u.ZeroWidth))
+	}
+	if u.MixedForms() {
+		parts = append(parts, fmt.Sprintf("%d decomposed (NFD) and %d composed (NFC)", u.Decomposed, u.Composed))
+	}
+	return strings.Join(parts, ", ")
+}
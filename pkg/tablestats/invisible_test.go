@@ -0,0 +1,80 @@
+package tablestats
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestObserveUnicode(t *testing.T) {
+	var u UnicodeIssues
+	for _, v := range []string{
+		"plain",
+		"New\u00a0York",
+		"id\u200b42",
+		"\ufeffkey\u202f",
+		"Caf\u00e9",  // Composed
+		"Cafe\u0301", // Decomposed
+	} {
+		observeUnicode(&u, v)
+		observeUnicode(&u, []byte(v))
+	}
+
+	expected := UnicodeIssues{NonBreakingSpace: 4, ZeroWidth: 4, Decomposed: 2, Composed: 2}
+	if u != expected {
+		t.Errorf("Expected %+v, got %+v", expected, u)
+	}
+	if !u.MixedForms() {
+		t.Errorf("Expected mixed normalization forms")
+	}
+	if summary := u.summary(); summary != "4 with non-breaking spaces, 4 with zero-width characters, 2 decomposed (NFD) and 2 composed (NFC)" {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+}
+
+func TestTableAccumulator_UnicodeIssues(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"city", "name"}, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range [][]string{
+		{"Paris", "Jos\u00e9"},
+		{"Paris\u00a0", "Ren\u00e9e"},
+		{"\u00a0", "Zo\u00eb"},
+	} {
+		acc.Add(record)
+	}
+
+	stats := acc.Finalize()
+	// The profile trims the non-breaking spaces, which joins downstream would not
+	if stats.DistinctCounts["city"] != 1 || stats.NullCounts["city"] != 1 {
+		t.Errorf("Expected 1 distinct city and 1 null, got %d and %d", stats.DistinctCounts["city"], stats.NullCounts["city"])
+	}
+	expected := map[string]*UnicodeIssues{"city": {NonBreakingSpace: 2}}
+	if !reflect.DeepEqual(stats.UnicodeIssues, expected) {
+		t.Errorf("Expected issues %+v, got %+v", expected, stats.UnicodeIssues)
+	}
+}
+
+func TestReadTable_Padding(t *testing.T) {
+	tmpFile := writeRawFile(t, "test.csv", []byte("code,name\n A1,Ann\nB2 ,  Bob  \n C3 ,\n   ,Dan\n"))
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+
+	forEachReadPath(t, func(t *testing.T, reader *CSVReader) {
+		stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+		if err != nil {
+			t.Fatalf("ReadTable failed: %v", err)
+		}
+		expected := map[string]*PaddingCounts{
+			"code": {Leading: 2, Trailing: 2},
+			"name": {Leading: 1, Trailing: 1},
+		}
+		if !reflect.DeepEqual(stats.Padding, expected) {
+			t.Errorf("Expected padding %+v, got %+v", expected, stats.Padding)
+		}
+		// The blank code is an empty null, not padding
+		if stats.EmptyCounts["code"] != 1 {
+			t.Errorf("Expected 1 empty code, got %d", stats.EmptyCounts["code"])
+		}
+	})
+}
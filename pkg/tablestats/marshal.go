@@ -0,0 +1,81 @@
+package tablestats
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// CurrentSchemaVersion is the layout version written by Marshal. It is bumped
+// whenever a field changes meaning or is removed; added fields keep the version.
+const CurrentSchemaVersion = 1
+
+// ErrUnsupportedVersion is returned when a profile was written by a newer release
+var ErrUnsupportedVersion = errors.New("unsupported profile schema version")
+
+// Marshal serializes a profile as JSON, stamping the current schema version
+func Marshal(stats *TableStats) ([]byte, error) {
+	versioned := *stats
+	versioned.SchemaVersion = CurrentSchemaVersion
+	data, err := json.Marshal(&versioned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode profile: %w", err)
+	}
+	return data, nil
+}
+
+// Unmarshal loads a profile written by Marshal or the JSON renderer. Profiles
+// from a newer schema version are rejected with ErrUnsupportedVersion. Custom
+// metric results come back as generic JSON values (float64, string, map, slice).
+func Unmarshal(data []byte) (*TableStats, error) {
+	var header struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	}
+	if header.SchemaVersion == nil {
+		return nil, fmt.Errorf("profile has no schema_version: %w", ErrUnsupportedVersion)
+	}
+	if *header.SchemaVersion < 1 || *header.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("version %d (this build reads up to %d): %w",
+			*header.SchemaVersion, CurrentSchemaVersion, ErrUnsupportedVersion)
+	}
+
+	stats := &TableStats{}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, fmt.Errorf("failed to decode profile: %w", err)
+	}
+	if err := restoreIntRanges(data, stats); err != nil {
+		return nil, err
+	}
+	stats.SchemaVersion = CurrentSchemaVersion
+	return stats, nil
+}
+
+// restoreIntRanges decodes the minimum and maximum of integer columns as
+// int64, which float64 cannot hold exactly beyond 2^53
+func restoreIntRanges(data []byte, stats *TableStats) error {
+	var ranges struct {
+		MinValues map[string]json.RawMessage `json:"min_values"`
+		MaxValues map[string]json.RawMessage `json:"max_values"`
+	}
+	if err := json.Unmarshal(data, &ranges); err != nil {
+		return fmt.Errorf("failed to decode profile: %w", err)
+	}
+	for _, r := range []struct {
+		raw    map[string]json.RawMessage
+		values map[string]interface{}
+	}{{ranges.MinValues, stats.MinValues}, {ranges.MaxValues, stats.MaxValues}} {
+		for name, raw := range r.raw {
+			if stats.ColumnTypes[name] != "int64" {
+				continue
+			}
+			if n, err := strconv.ParseInt(string(raw), 10, 64); err == nil {
+				r.values[name] = n
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package tablestats
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	stats := renderTestStats()
+	stats.SamplingConfig = SamplingConfig{SampleSize: 1000, RandomPositions: 5, Columns: []string{"id", "label"}}
+
+	data, err := Marshal(stats)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	for _, key := range []string{`"schema_version":1`, `"row_count":3`, `"null_percentage"`, `"std_dev"`} {
+		if !strings.Contains(string(data), key) {
+			t.Errorf("Expected JSON to contain %s, got %s", key, data)
+		}
+	}
+
+	loaded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, stats) {
+		t.Errorf("Expected round trip to preserve the profile\ngot:  %+v\nwant: %+v", loaded, stats)
+	}
+
+	// The JSON renderer writes the same layout
+	var buf bytes.Buffer
+	if err := (&JSONRenderer{}).Render(&buf, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if _, err := Unmarshal(buf.Bytes()); err != nil {
+		t.Errorf("Expected rendered JSON to load, got %v", err)
+	}
+}
+
+func TestUnmarshal_Versions(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"missing version", `{"row_count": 1}`},
+		{"future version", `{"schema_version": 99, "row_count": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Unmarshal([]byte(tt.data)); !errors.Is(err, ErrUnsupportedVersion) {
+				t.Errorf("Expected ErrUnsupportedVersion, got %v", err)
+			}
+		})
+	}
+
+	if _, err := Unmarshal([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+
+	// Unknown fields from newer minor releases are ignored
+	stats, err := Unmarshal([]byte(`{"schema_version": 1, "row_count": 7, "added_later": true}`))
+	if err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if stats.RowCount != 7 {
+		t.Errorf("Expected 7 rows, got %d", stats.RowCount)
+	}
+}
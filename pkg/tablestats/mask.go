@@ -0,0 +1,155 @@
+package tablestats
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// redacted replaces masked values when they are not hashed
+const redacted = "***"
+
+// MaskMode selects how Mask replaces values
+type MaskMode int
+
+const (
+	// MaskRedact replaces every value with "***"
+	MaskRedact MaskMode = iota
+	// MaskHash replaces every value with a short HMAC-SHA-256 digest, so
+	// equal values stay recognizably equal
+	MaskHash
+)
+
+// Masking sets how Mask replaces values. Hashed values are keyed so that
+// common values, such as names, cannot be recovered by hashing candidates;
+// digests only compare equal between runs that share a Key.
+type Masking struct {
+	Mode MaskMode
+	Key  []byte // HMAC key of MaskHash digests
+}
+
+// ParseMaskMode converts "redact" or "hash" into a MaskMode
+func ParseMaskMode(name string) (MaskMode, error) {
+	switch name {
+	case "", "redact":
+		return MaskRedact, nil
+	case "hash":
+		return MaskHash, nil
+	default:
+		return 0, fmt.Errorf("unsupported mask mode %q (use redact or hash)", name)
+	}
+}
+
+// PIIColumns returns the columns flagged as likely personal data, in column order
+func (s *TableStats) PIIColumns() []string {
+	var columns []string
+	for _, name := range s.ColumnNames {
+		if _, ok := s.PII[name]; ok {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// Mask replaces the values of the given columns wherever the profile shows
+// them: example rows, minimum and maximum values, category lists and their case
+// variants, duplicated unique keys, group values and values that do not match a pattern. Null values
+// are kept so their pattern stays visible. Counts and numeric aggregates are left alone.
+func (s *TableStats) Mask(columns []string, m Masking) error {
+	known := make(map[string]bool, len(s.ColumnNames))
+	for _, name := range s.ColumnNames {
+		known[name] = true
+	}
+	positions := make(map[string]int, len(s.ColumnNames))
+	for i, name := range s.sampleColumnNames() {
+		positions[name] = i
+		known[name] = true
+	}
+	for _, name := range columns {
+		if !known[name] {
+			return fmt.Errorf("mask column %q: %w", name, ErrUnknownColumn)
+		}
+	}
+
+	// Copy the example rows, which may share storage with the sample they came from
+	for i, row := range s.SampleData {
+		s.SampleData[i] = append([]string(nil), row...)
+	}
+	for _, name := range columns {
+		if idx, ok := positions[name]; ok {
+			for _, row := range s.SampleData {
+				if idx < len(row) {
+					row[idx] = m.value(row[idx])
+				}
+			}
+		}
+		for _, values := range []map[string]interface{}{s.MinValues, s.MaxValues} {
+			if v, ok := values[name]; ok && v != nil {
+				values[name] = m.value(fmt.Sprint(v))
+			}
+		}
+		if s.UniqueKey != nil {
+			for i, column := range s.UniqueKey.Columns {
+				if column != name {
+					continue
+				}
+				for j := range s.UniqueKey.Collisions {
+					c := &s.UniqueKey.Collisions[j]
+					c.Values = append([]string(nil), c.Values...)
+					c.Values[i] = m.value(c.Values[i])
+				}
+			}
+		}
+		if g := s.Groups; g != nil && g.Column == name {
+			groups := make([]GroupStats, len(g.Groups))
+			for i, group := range g.Groups {
+				groups[i] = group
+				if !group.Null && !group.Other {
+					groups[i].Value = m.value(group.Value)
+				}
+			}
+			s.Groups = &GroupedStats{Column: g.Column, Groups: groups}
+		}
+		if c, ok := s.Conformance[name]; ok {
+			masked := *c
+			masked.Examples = make([]string, len(c.Examples))
+			for i, v := range c.Examples {
+				masked.Examples[i] = m.value(v)
+			}
+			s.Conformance[name] = &masked
+		}
+		if categories, ok := s.Categories[name]; ok {
+			masked := make([]string, len(categories))
+			for i, v := range categories {
+				masked[i] = m.value(v)
+			}
+			s.Categories[name] = masked
+		}
+		if groups, ok := s.CaseVariants[name]; ok {
+			masked := make([]CaseGroup, len(groups))
+			for i, g := range groups {
+				masked[i] = make(CaseGroup, len(g))
+				for j, vc := range g {
+					masked[i][j] = ValueCount{Value: m.value(vc.Value), Count: vc.Count}
+				}
+			}
+			s.CaseVariants[name] = masked
+		}
+	}
+	return nil
+}
+
+// value returns the masked form of a value; nulls are kept
+func (m Masking) value(value string) string {
+	if isNullValue(strings.TrimSpace(value)) {
+		return value
+	}
+	if m.Mode == MaskHash {
+		mac := hmac.New(sha256.New, m.Key)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:6])
+	}
+	return redacted
+}
@@ -0,0 +1,102 @@
+package tablestats
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTableStats_Mask(t *testing.T) {
+	records := [][]string{
+		{"1", "a@example.com", "gold"},
+		{"2", "b@example.com", ""},
+		{"3", "a@example.com", "silver"},
+	}
+	stats := AnalyzeSample(withRecords(&Sample{Header: []string{"id", "email", "tier"}}, records), SamplingConfig{})
+
+	if got := stats.PIIColumns(); len(got) != 1 || got[0] != "email" {
+		t.Fatalf("Expected email to be flagged, got %v", got)
+	}
+	if err := stats.Mask([]string{"email", "tier"}, Masking{}); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+
+	if row := stats.SampleData[1]; row[0] != "2" || row[1] != redacted || row[2] != "" {
+		t.Errorf("Expected only non-null values of masked columns to be redacted, got %v", row)
+	}
+	if stats.MinValues["email"] != redacted || stats.MaxValues["tier"] != redacted {
+		t.Errorf("Expected masked min and max, got %v and %v", stats.MinValues["email"], stats.MaxValues["tier"])
+	}
+	for _, v := range stats.Categories["tier"] {
+		if v != redacted {
+			t.Errorf("Expected masked categories, got %v", stats.Categories["tier"])
+		}
+	}
+	if records[0][1] != "a@example.com" {
+		t.Errorf("Expected the sampled records to be left alone, got %v", records[0])
+	}
+
+	if err := stats.Mask([]string{"missing"}, Masking{}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestTableStats_MaskHash(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header: []string{"user"},
+	}, [][]string{{"alice"}, {"bob"}, {"alice"}}), SamplingConfig{})
+	if err := stats.Mask([]string{"user"}, Masking{Mode: MaskHash, Key: []byte("k1")}); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+
+	first, second, third := stats.SampleData[0][0], stats.SampleData[1][0], stats.SampleData[2][0]
+	if !strings.HasPrefix(first, "hmac:") || first == "alice" {
+		t.Errorf("Expected a digest, got %q", first)
+	}
+	if first != third || first == second {
+		t.Errorf("Expected equal values to hash equally, got %q, %q, %q", first, second, third)
+	}
+
+	// Digests depend on the key, so they cannot be looked up without it
+	if other := (Masking{Mode: MaskHash, Key: []byte("k2")}).value("alice"); other == first {
+		t.Errorf("Expected another key to give another digest, got %q for both", other)
+	}
+	if plain := sha256.Sum256([]byte("alice")); strings.Contains(first, hex.EncodeToString(plain[:6])) {
+		t.Errorf("Expected a keyed digest, got the plain SHA-256 %q", first)
+	}
+}
+
+func TestDriftReport_Mask(t *testing.T) {
+	baseline := AnalyzeSample(withRecords(&Sample{
+		Header: []string{"email", "tier"},
+	}, [][]string{{"a@corp.com", "gold"}, {"a@corp.com", "gold"}, {"b@corp.com", "silver"}}), SamplingConfig{})
+	current := AnalyzeSample(withRecords(&Sample{
+		Header: []string{"email", "tier"},
+	}, [][]string{{"secret@corp.com", "bronze"}, {"a@corp.com", "gold"}, {"b@corp.com", "silver"}}), SamplingConfig{})
+	baseline.Categories = map[string][]string{"email": {"a@corp.com", "b@corp.com"}, "tier": {"gold", "silver"}}
+	current.Categories = map[string][]string{"email": {"secret@corp.com", "a@corp.com", "b@corp.com"}, "tier": {"bronze", "gold", "silver"}}
+
+	report := DetectDrift(baseline, current, DefaultDriftStdErrors)
+	report.Mask([]string{"email"}, Masking{})
+	failures := strings.Join(report.Failures(), "\n")
+	if strings.Contains(failures, "secret") {
+		t.Errorf("Expected the masked value to be hidden, got:\n%s", failures)
+	}
+	if !strings.Contains(failures, `email: new_values: new values "***"`) || !strings.Contains(failures, `"bronze"`) {
+		t.Errorf("Expected only the email values to be masked, got:\n%s", failures)
+	}
+}
+
+func TestParseMaskMode(t *testing.T) {
+	if mode, err := ParseMaskMode("hash"); err != nil || mode != MaskHash {
+		t.Errorf("Expected MaskHash, got %v, %v", mode, err)
+	}
+	if mode, err := ParseMaskMode(""); err != nil || mode != MaskRedact {
+		t.Errorf("Expected MaskRedact by default, got %v, %v", mode, err)
+	}
+	if _, err := ParseMaskMode("blur"); err == nil {
+		t.Error("Expected error for unknown mode")
+	}
+}
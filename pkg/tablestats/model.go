@@ -0,0 +1,187 @@
+package tablestats
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// AggregateStats represents statistical aggregations
+type AggregateStats struct {
+	Count       int64           `json:"count"`
+	Sum         float64         `json:"sum"`
+	Mean        float64         `json:"mean"`
+	Median      float64         `json:"median"`
+	StdDev      float64         `json:"std_dev"`
+	Variance    float64         `json:"variance"`
+	Percentiles map[int]float64 `json:"percentiles"` // 25th, 50th, 75th, 90th, 95th, 99th
+	// Quantiles holds the finite values at every percentile from 0 to 100, for
+	// comparing the distributions of two profiles
+	Quantiles []float64 `json:"quantiles,omitempty"`
+	// Histogram bins the finite values into equal-width ranges
+	Histogram []HistogramBin `json:"histogram,omitempty"`
+	// EstimatedTotal extrapolates the column total to EstimatedRows
+	EstimatedTotal float64 `json:"estimated_total"`
+}
+
+// TableStats represents the statistics we want to collect
+type TableStats struct {
+	SchemaVersion   int                         `json:"schema_version"` // Layout version of the serialized profile
+	RowCount        int64                       `json:"row_count"`
+	EstimatedRows   int64                       `json:"estimated_rows"`           // Estimated total rows based on sampling
+	EstimatedSize   int64                       `json:"estimated_size,omitempty"` // Estimated bytes of the profiled values over EstimatedRows, without delimiters and quotes
+	Memory          *MemoryFootprint            `json:"memory,omitempty"`         // Estimated bytes of the table loaded as typed arrays
+	Compression     map[string]*Compressibility `json:"compression,omitempty"`    // How well each column's values compress, for columns with values
+	FileInfo        *FileInfo                   `json:"file_info,omitempty"`      // The file the profile was read from, set by text readers
+	ColumnCount     int                         `json:"column_count"`
+	ColumnNames     []string                    `json:"column_names"`
+	ColumnTypes     map[string]string           `json:"column_types"`
+	NullCounts      map[string]int64            `json:"null_counts"`
+	EmptyCounts     map[string]int64            `json:"empty_counts,omitempty"` // Nulls that are empty or blank fields, or missing from short rows
+	NullTokens      map[string]int64            `json:"null_tokens,omitempty"`  // Nulls spelled NULL or null; ignored NaN and infinities are in neither count
+	NullPercentage  map[string]float64          `json:"null_percentage"`
+	DistinctCounts  map[string]int64            `json:"distinct_counts"` // Distinct non-null values observed, estimated from SketchSampleSize on
+	MinValues       map[string]interface{}      `json:"min_values"`
+	MaxValues       map[string]interface{}      `json:"max_values"`
+	UniqueKey       *KeyCheck                   `json:"unique_key,omitempty"`      // Set when SamplingConfig.UniqueKey is
+	KeyCandidates   []*KeyEstimate              `json:"key_candidates,omitempty"`  // One per SamplingConfig.KeyCandidates tuple
+	Groups          *GroupedStats               `json:"groups,omitempty"`          // Set when SamplingConfig.GroupBy is
+	Categories      map[string][]string         `json:"categories,omitempty"`      // Sorted values of string columns with at most MaxCategories distinct values
+	CaseVariants    map[string][]CaseGroup      `json:"case_variants,omitempty"`   // Values of Categories columns that differ only in case
+	PII             map[string]*PIIFlag         `json:"pii,omitempty"`             // Columns that likely hold personal data
+	Anomalies       []Anomaly                   `json:"anomalies,omitempty"`       // Set when SamplingConfig.DetectAnomalies is
+	Recommendations []Recommendation            `json:"recommendations,omitempty"` // Set when SamplingConfig.Recommend is
+	Conformance     map[string]*Conformance     `json:"conformance,omitempty"`     // Pattern matches of the columns in SamplingConfig.Patterns
+	RaggedRows      *RaggedRows                 `json:"ragged_rows,omitempty"`     // Set when rows with more or fewer fields than the header were read
+	ParseErrors     *ParseErrorReport           `json:"parse_errors,omitempty"`    // Malformed records left out, set by ErrorsReport
+	RenamedColumns  []ColumnRename              `json:"renamed_columns,omitempty"` // Blank and repeated header names, renamed to keep columns apart
+	NonFinite       map[string]*NonFiniteCounts `json:"non_finite,omitempty"`      // Numeric columns holding NaN or infinite values
+	Decimals        map[string]*DecimalShape    `json:"decimals,omitempty"`        // Precision and scale of numeric columns
+	TypeFit         map[string]*TypeFit         `json:"type_fit,omitempty"`        // Columns mixing numbers with values that are not
+	UnicodeIssues   map[string]*UnicodeIssues   `json:"unicode_issues,omitempty"`  // Columns with invisible characters or mixed normalization forms
+	Padding         map[string]*PaddingCounts   `json:"padding,omitempty"`         // Columns with values padded with whitespace, before trimming
+	SampleData      [][]string                  `json:"sample_data"`
+	SampleColumns   []string                    `json:"sample_columns,omitempty"` // Columns of SampleData, set when they are not ColumnNames
+	Aggregates      map[string]*AggregateStats  `json:"aggregates"`               // For numeric columns
+	CustomMetrics   map[string]map[string]any   `json:"custom_metrics,omitempty"` // Registered analyzer or plugin name -> column -> result
+	DetectedTypes   map[string]string           `json:"detected_types,omitempty"` // Column -> kind of values recognized by an analyzer or plugin, e.g. iban
+	Provenance      *Provenance                 `json:"provenance,omitempty"`     // Which metrics are exact and which are estimated
+	SamplingConfig  SamplingConfig              `json:"sampling_config"`
+}
+
+// MaxCategories is the largest number of distinct values for which a string
+// column's values are listed in TableStats.Categories
+const MaxCategories = 50
+
+// SamplingConfig controls the sampling behavior
+type SamplingConfig struct {
+	SampleSize      int               `json:"sample_size"`               // Number of rows to sample; from SketchSampleSize on, columns are aggregated with sketches
+	RandomPositions int               `json:"random_positions"`          // Number of random positions to seek to
+	Confidence      float64           `json:"confidence"`                // Confidence level for estimates
+	MaxFileSize     int64             `json:"max_file_size"`             // Max file size to process entirely
+	Offset          int64             `json:"offset,omitempty"`          // Rows to skip before profiling; negative counts back from the end of the file
+	Limit           int64             `json:"limit,omitempty"`           // Max rows to profile after Offset (0 means no limit)
+	WeightColumn    string            `json:"weight_column,omitempty"`   // Numeric column to weight the sample by (sampled files only)
+	Columns         []string          `json:"columns,omitempty"`         // Columns to profile (empty means all)
+	ExcludeColumns  []string          `json:"exclude_columns,omitempty"` // Columns to skip
+	SampleRows      int               `json:"sample_rows,omitempty"`     // Example rows kept in SampleData (0 uses DefaultSampleRows, negative keeps none)
+	SampleColumns   []string          `json:"sample_columns,omitempty"`  // Columns shown in SampleData, in this order (empty means the profiled columns)
+	UniqueKey       []string          `json:"unique_key,omitempty"`      // Columns whose combined values must be unique among the profiled rows
+	KeyCandidates   [][]string        `json:"key_candidates,omitempty"`  // Column tuples whose distinct combinations are counted, to evaluate them as composite keys
+	GroupBy         string            `json:"group_by,omitempty"`        // Column whose values the numeric columns are also aggregated by
+	Patterns        map[string]string `json:"patterns,omitempty"`        // Column -> regular expression its values should match; only profiled columns are checked
+	DetectAnomalies bool              `json:"anomalies,omitempty"`       // Report sentinel spikes, out-of-range and bimodal numeric columns
+	Recommend       bool              `json:"recommend,omitempty"`       // Suggest how to store the columns: types, dictionary encoding, a sort key
+	NonFinite       NonFinitePolicy   `json:"non_finite,omitempty"`      // How NaN and infinite values enter the aggregates
+	ExactSums       bool              `json:"exact_sums,omitempty"`      // Sum unweighted numeric columns exactly rather than with compensated floats
+	TypeTolerance   float64           `json:"type_tolerance,omitempty"`  // Share of non-null values that may not be numbers in a column inferred as numeric
+	Plugins         []Plugin          `json:"plugins,omitempty"`         // External analyzers run over the profiled columns
+	Progress        ProgressFunc      `json:"-"`                         // Called periodically while reading, may be nil
+	// Checkpoint is a file the state of a full read of a CSV file is saved to
+	// every CheckpointInterval (0 uses DefaultCheckpointInterval) and when the
+	// read is cancelled. A read finding it resumes from it; it is removed once
+	// the read completes. Empty disables checkpoints.
+	Checkpoint         string        `json:"-"`
+	CheckpointInterval time.Duration `json:"-"`
+}
+
+// DefaultSampleRows is the number of example rows kept when SampleRows is not set
+const DefaultSampleRows = 5
+
+// DefaultSamplingConfig returns sensible defaults
+func DefaultSamplingConfig() SamplingConfig {
+	return SamplingConfig{
+		SampleSize:      1000,
+		RandomPositions: 10,
+		Confidence:      0.95,
+		MaxFileSize:     100 * 1024 * 1024, // 100MB
+		SampleRows:      DefaultSampleRows,
+	}
+}
+
+// Sample holds the rows a reader selected from a file before analysis. The
+// values are stored by column, in one Arrow array per header field, so they
+// take a few bytes of overhead each and can be handed to Arrow consumers
+// as they are. Use NewSample to build one from rows.
+type Sample struct {
+	Header        []string
+	Columns       []*array.LargeString // Raw values of each Header field; fields missing from short rows are null
+	Weights       []float64            // Inverse-probability weights, nil for uniform samples
+	EstimatedRows int64
+	Exact         bool              // The rows cover the whole file or the requested row window
+	Ragged        *RaggedRows       // Field counts of the rows read, nil when the format has no rows of their own width
+	ParseErrors   *ParseErrorReport // Malformed records left out, set by ErrorsReport
+	Renamed       []ColumnRename    // Header names changed to keep columns apart; Header holds the new names
+	FileInfo      *FileInfo         // Nil when the format does not read a text file
+}
+
+// TableReader defines the strategy interface for reading different table formats.
+// Reading stops with the context's error once ctx is cancelled.
+type TableReader interface {
+	ReadTable(ctx context.Context, filePath string, config SamplingConfig) (*TableStats, error)
+	GetFormatName() string
+}
+
+// SampleReader is implemented by readers that can expose the raw selected rows
+type SampleReader interface {
+	ReadSample(ctx context.Context, filePath string, config SamplingConfig) (*Sample, error)
+}
+
+// StreamReader is implemented by readers that can profile data without a file path.
+// size is the number of bytes the reader will yield, or -1 when unknown.
+type StreamReader interface {
+	ReadTableFrom(ctx context.Context, r io.Reader, size int64, config SamplingConfig) (*TableStats, error)
+}
+
+// randomAccessReader is satisfied by sources that can be sampled at random positions
+type randomAccessReader interface {
+	io.Reader
+	io.Seeker
+	io.ReaderAt
+}
+
+// StatisticsGenerator is the context that uses the strategy
+type StatisticsGenerator struct {
+	reader TableReader
+	config SamplingConfig
+}
+
+// NewStatisticsGenerator creates a new statistics generator with a specific reader strategy
+func NewStatisticsGenerator(reader TableReader, config SamplingConfig) *StatisticsGenerator {
+	return &StatisticsGenerator{
+		reader: reader,
+		config: config,
+	}
+}
+
+// SetReader allows changing the strategy at runtime
+func (sg *StatisticsGenerator) SetReader(reader TableReader) {
+	sg.reader = reader
+}
+
+// GenerateStats generates statistics using the current reader strategy
+func (sg *StatisticsGenerator) GenerateStats(ctx context.Context, filePath string) (*TableStats, error) {
+	return sg.reader.ReadTable(ctx, filePath, sg.config)
+}
@@ -0,0 +1,399 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// exactQuantileLimit is how many values a quantile sketch keeps. Percentiles
+// are exact up to this many values; beyond it they are estimated from a
+// uniform random subset of this size.
+const exactQuantileLimit = 1 << 16
+
+// percentilePoints are the percentiles reported in AggregateStats
+var percentilePoints = []int{25, 50, 75, 90, 95, 99}
+
+// quantilePoints is the number of values in AggregateStats.Quantiles, one per
+// percentile from 0 to 100
+const quantilePoints = 101
+
+// exactSumPrec is the precision of exact sums: enough bits for any float64,
+// from 2^-1074 to 2^1024, with room for the carries of 2^100 additions
+const exactSumPrec = 2200
+
+// compensatedSum adds floats with Neumaier's variant of Kahan summation, which
+// carries the rounding error of every addition instead of dropping it. When
+// exact is set the values are also added without any rounding, which is
+// slower but survives intermediate overflow.
+type compensatedSum struct {
+	sum   float64
+	c     float64    // Low-order bits lost by the additions so far
+	exact *big.Float // Exact sum, dropped once a NaN or infinity is added
+}
+
+// newExactSum returns a sum that is also computed exactly
+func newExactSum() compensatedSum {
+	return compensatedSum{exact: new(big.Float).SetPrec(exactSumPrec)}
+}
+
+func (s *compensatedSum) add(v float64) {
+	t := s.sum + v
+	if math.Abs(s.sum) >= math.Abs(v) {
+		s.c += (s.sum - t) + v
+	} else {
+		s.c += (v - t) + s.sum
+	}
+	s.sum = t
+	if s.exact != nil {
+		if isFinite(v) {
+			s.exact.Add(s.exact, new(big.Float).SetFloat64(v))
+		} else {
+			s.exact = nil
+		}
+	}
+}
+
+func (s *compensatedSum) value() float64 {
+	if s.exact != nil {
+		v, _ := s.exact.Float64()
+		return v
+	}
+	if !isFinite(s.sum) {
+		// The compensation of an infinite sum is NaN
+		return s.sum
+	}
+	return s.sum + s.c
+}
+
+// numericSummary computes aggregates in a single pass. Mean and variance use
+// Welford's online algorithm (weighted by West's update); percentiles come from
+// a bounded quantile sketch, or from a t-digest when digest is set.
+type numericSummary struct {
+	count     int64
+	sum       compensatedSum // Sum of the observed values
+	weight    float64        // Total weight, equal to count when unweighted
+	mean      float64        // Running mean
+	m2        float64        // Weighted sum of squared deviations from the running mean
+	quantiles quantileSketch
+	digest    *tDigest
+}
+
+func (s *numericSummary) add(v float64) {
+	s.addWeighted(v, 1)
+}
+
+func (s *numericSummary) addWeighted(v, weight float64) {
+	s.count++
+	s.sum.add(v)
+	s.weight += weight
+	delta := v - s.mean
+	s.mean += delta * weight / s.weight
+	s.m2 += weight * delta * (v - s.mean)
+	if s.digest != nil {
+		s.digest.add(v, weight)
+	} else {
+		s.quantiles.add(v)
+	}
+}
+
+func (s *numericSummary) aggregates() *AggregateStats {
+	if s.count == 0 {
+		return &AggregateStats{}
+	}
+
+	sum := s.sum.value()
+	mean := s.mean
+	if math.IsInf(sum, 0) || math.IsNaN(sum) {
+		// The running mean cannot represent infinities, the plain average can
+		mean = sum / float64(s.count)
+	}
+	variance := s.m2 / s.weight
+
+	var percentiles map[int]float64
+	var quantiles []float64
+	var bins []HistogramBin
+	if s.digest != nil {
+		percentiles = make(map[int]float64, len(percentilePoints))
+		for _, p := range percentilePoints {
+			percentiles[p] = s.digest.quantile(float64(p) / 100)
+		}
+		quantiles = make([]float64, quantilePoints)
+		for p := range quantiles {
+			quantiles[p] = s.digest.quantile(float64(p) / (quantilePoints - 1))
+			if !isFinite(quantiles[p]) {
+				quantiles = nil
+				break
+			}
+		}
+		bins = s.digest.histogram(s.count)
+	} else {
+		percentiles = s.quantiles.percentiles(percentilePoints)
+		quantiles = s.quantiles.distribution()
+		bins = histogram(s.quantiles.values, nil, s.count)
+	}
+	return &AggregateStats{
+		Count:       s.count,
+		Sum:         sum,
+		Mean:        mean,
+		Median:      percentiles[50],
+		StdDev:      math.Sqrt(variance),
+		Variance:    variance,
+		Percentiles: percentiles,
+		Quantiles:   quantiles,
+		Histogram:   bins,
+	}
+}
+
+// quantileSketch keeps every value until exactQuantileLimit is reached and a
+// uniform reservoir of that many values afterwards
+type quantileSketch struct {
+	values []float64
+	seen   int64
+}
+
+func (q *quantileSketch) add(v float64) {
+	q.seen++
+	if len(q.values) < exactQuantileLimit {
+		q.values = append(q.values, v)
+		return
+	}
+	if j := rand.Int63n(q.seen); j < exactQuantileLimit {
+		q.values[j] = v
+	}
+}
+
+// percentiles sorts the kept values and interpolates the requested percentiles
+func (q *quantileSketch) percentiles(points []int) map[int]float64 {
+	sort.Float64s(q.values)
+	result := make(map[int]float64, len(points))
+	for _, p := range points {
+		result[p] = calculatePercentile(q.values, p)
+	}
+	return result
+}
+
+// distribution returns the finite kept values at every percentile from 0 to
+// 100, or nil when there are none. percentiles must have sorted the values.
+func (q *quantileSketch) distribution() []float64 {
+	lo, hi := 0, len(q.values)
+	for lo < hi && !isFinite(q.values[lo]) {
+		lo++
+	}
+	for hi > lo && !isFinite(q.values[hi-1]) {
+		hi--
+	}
+	if lo == hi {
+		return nil
+	}
+	result := make([]float64, quantilePoints)
+	for p := range result {
+		result[p] = calculatePercentile(q.values[lo:hi], p)
+	}
+	return result
+}
+
+// NonFinitePolicy selects how NaN and infinite values of numeric columns
+// are profiled. Their counts are reported in TableStats.NonFinite whatever
+// the policy.
+type NonFinitePolicy int
+
+const (
+	// NonFiniteIgnore counts them as nulls, so a single NaN cannot turn every
+	// aggregate into NaN
+	NonFiniteIgnore NonFinitePolicy = iota
+	// NonFinitePropagate aggregates them like other numbers, following IEEE
+	// 754: one NaN makes the sum, mean and deviation NaN
+	NonFinitePropagate
+	// NonFiniteCount keeps them as non-null values, but leaves them out of the
+	// aggregates and the range
+	NonFiniteCount
+)
+
+// ParseNonFinitePolicy converts "ignore", "propagate" or "count-separately"
+// into a NonFinitePolicy
+func ParseNonFinitePolicy(name string) (NonFinitePolicy, error) {
+	switch name {
+	case "", "ignore":
+		return NonFiniteIgnore, nil
+	case "propagate":
+		return NonFinitePropagate, nil
+	case "count-separately":
+		return NonFiniteCount, nil
+	default:
+		return 0, fmt.Errorf("unsupported NaN/Inf policy %q (use ignore, propagate or count-separately)", name)
+	}
+}
+
+// String returns the name ParseNonFinitePolicy accepts
+func (p NonFinitePolicy) String() string {
+	switch p {
+	case NonFinitePropagate:
+		return "propagate"
+	case NonFiniteCount:
+		return "count-separately"
+	default:
+		return "ignore"
+	}
+}
+
+// description tells report readers how the values were profiled
+func (p NonFinitePolicy) description() string {
+	switch p {
+	case NonFinitePropagate:
+		return "aggregated"
+	case NonFiniteCount:
+		return "left out of the aggregates"
+	default:
+		return "profiled as nulls"
+	}
+}
+
+// NonFiniteCounts counts the NaN and infinite values of a numeric column
+type NonFiniteCounts struct {
+	NaN    int64 `json:"nan"`
+	PosInf int64 `json:"pos_inf"`
+	NegInf int64 `json:"neg_inf"`
+}
+
+// Total returns the number of NaN and infinite values
+func (n *NonFiniteCounts) Total() int64 {
+	return n.NaN + n.PosInf + n.NegInf
+}
+
+func (n *NonFiniteCounts) add(v float64) {
+	switch {
+	case math.IsNaN(v):
+		n.NaN++
+	case v > 0:
+		n.PosInf++
+	default:
+		n.NegInf++
+	}
+}
+
+// isFinite reports whether v is neither NaN nor infinite
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// numberKind is the result of classifying a field value
+type numberKind uint8
+
+const (
+	notNumber numberKind = iota
+	intNumber
+	floatNumber // Contains a decimal point
+)
+
+// float64pow10 holds the powers of ten that are exactly representable as float64
+var float64pow10 = [...]float64{
+	1e0, 1e1, 1e2, 1e3, 1e4, 1e5, 1e6, 1e7, 1e8, 1e9, 1e10,
+	1e11, 1e12, 1e13, 1e14, 1e15, 1e16, 1e17, 1e18, 1e19, 1e20, 1e21, 1e22,
+}
+
+// classifyNumber determines whether value is an integer, a decimal or not a
+// number, and parses it, in a single scan. Plain decimals whose digits fit in
+// a float64 mantissa are converted exactly without strconv; anything else
+// (exponents, Inf, NaN, hex, long mantissas) falls back to strconv.ParseFloat.
+// It accepts exactly the values strconv.ParseFloat accepts.
+func classifyNumber[T fieldValue](value T) (numberKind, float64) {
+	i := 0
+	if i < len(value) && (value[i] == '+' || value[i] == '-') {
+		i++
+	}
+
+	var mantissa uint64
+	digits, fracDigits := 0, 0
+	dot := false
+	for ; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= '0' && c <= '9':
+			mantissa = mantissa*10 + uint64(c-'0')
+			digits++
+			if dot {
+				fracDigits++
+			}
+		case c == '.' && !dot:
+			dot = true
+		default:
+			if !floatSyntax(value) {
+				return notNumber, 0
+			}
+			return classifySlow(string(value))
+		}
+		if digits > 15 {
+			// The mantissa may no longer be exact
+			return classifySlow(string(value))
+		}
+	}
+	if digits == 0 {
+		return notNumber, 0
+	}
+
+	v := float64(mantissa)
+	if fracDigits > 0 {
+		v /= float64pow10[fracDigits]
+	}
+	if value[0] == '-' {
+		v = -v
+	}
+	if dot {
+		return floatNumber, v
+	}
+	return intNumber, v
+}
+
+// floatSyntax reports whether strconv.ParseFloat may accept value, so that
+// text is rejected without parsing: letters other than exponents only appear
+// in hex numbers, infinities and NaN
+func floatSyntax[T fieldValue](value T) bool {
+	rest := value
+	if len(rest) > 0 && (rest[0] == '+' || rest[0] == '-') {
+		rest = rest[1:]
+	}
+	if len(rest) > 1 && rest[0] == '0' && rest[1]|0x20 == 'x' {
+		return true
+	}
+	for i := 0; i < len(rest); i++ {
+		switch c := rest[i]; {
+		case c >= '0' && c <= '9', c == '.', c == '_', c == '+', c == '-', c|0x20 == 'e':
+		case c|0x20 >= 'a' && c|0x20 <= 'z':
+			word := string(rest)
+			return strings.EqualFold(word, "inf") || strings.EqualFold(word, "infinity") || strings.EqualFold(word, "nan")
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// classifySlow classifies values outside the fast path of classifyNumber
+func classifySlow(value string) (numberKind, float64) {
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return notNumber, 0
+	}
+	if strings.IndexByte(value, '.') >= 0 {
+		return floatNumber, v
+	}
+	return intNumber, v
+}
+
+// numericValue returns a minimum or maximum as a float64, which is int64 for
+// integer columns
+func numericValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,159 @@
+package tablestats
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNumericSummary_LargeOffsetVariance(t *testing.T) {
+	// A naive sum of squares loses all precision with a large common offset
+	var summary numericSummary
+	for _, v := range []float64{4, 7, 13, 16} {
+		summary.add(1e9 + v)
+	}
+	result := summary.aggregates()
+
+	if !floatEqual(result.Mean, 1e9+10) {
+		t.Errorf("Expected mean %f, got %f", 1e9+10, result.Mean)
+	}
+	if !floatEqual(result.Variance, 22.5) {
+		t.Errorf("Expected variance 22.5, got %f", result.Variance)
+	}
+}
+
+func TestCompensatedSum(t *testing.T) {
+	// A naive sum loses the 1 entirely
+	var sum compensatedSum
+	for _, v := range []float64{1e16, 1, -1e16} {
+		sum.add(v)
+	}
+	if got := sum.value(); got != 1 {
+		t.Errorf("Expected compensated sum 1, got %g", got)
+	}
+
+	// Only the exact sum survives an intermediate overflow
+	overflowing := []float64{1e308, 1e308, -1e308}
+	sum, exact := compensatedSum{}, newExactSum()
+	for _, v := range overflowing {
+		sum.add(v)
+		exact.add(v)
+	}
+	if got := sum.value(); !math.IsInf(got, 1) {
+		t.Errorf("Expected compensated sum +Inf, got %g", got)
+	}
+	if got := exact.value(); got != 1e308 {
+		t.Errorf("Expected exact sum 1e308, got %g", got)
+	}
+
+	// NaN cannot be summed exactly
+	exact.add(math.NaN())
+	if got := exact.value(); !math.IsNaN(got) {
+		t.Errorf("Expected NaN, got %g", got)
+	}
+}
+
+func TestTableAccumulator_ExactSums(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"amount"}, SamplingConfig{ExactSums: true})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, v := range []string{"1e308", "1e308", "-1e308", "0.1", "0.2"} {
+		acc.Add([]string{v})
+	}
+	if agg := acc.Finalize().Aggregates["amount"]; agg.Sum != 1e308 {
+		t.Errorf("Expected sum 1e308, got %g", agg.Sum)
+	}
+}
+
+func TestNumericSummary_BoundedSketch(t *testing.T) {
+	n := exactQuantileLimit * 4
+	var summary numericSummary
+	for i := 0; i < n; i++ {
+		summary.add(float64(i))
+	}
+
+	if len(summary.quantiles.values) != exactQuantileLimit {
+		t.Errorf("Expected sketch to keep %d values, got %d", exactQuantileLimit, len(summary.quantiles.values))
+	}
+
+	result := summary.aggregates()
+	if result.Count != int64(n) {
+		t.Errorf("Expected count %d, got %d", n, result.Count)
+	}
+	if !floatEqual(result.Mean, float64(n-1)/2) {
+		t.Errorf("Expected mean %f, got %f", float64(n-1)/2, result.Mean)
+	}
+
+	// Percentiles are estimated from the reservoir, allow 1% of the range
+	tolerance := float64(n) * 0.01
+	for _, p := range percentilePoints {
+		want := float64(n-1) * float64(p) / 100
+		if got := result.Percentiles[p]; math.Abs(got-want) > tolerance {
+			t.Errorf("Expected p%d near %f, got %f", p, want, got)
+		}
+	}
+}
+
+func TestClassifyNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		kind  numberKind
+	}{
+		{"0", intNumber},
+		{"-42", intNumber},
+		{"+7", intNumber},
+		{"3.14", floatNumber},
+		{"-0.001", floatNumber},
+		{".5", floatNumber},
+		{"5.", floatNumber},
+		{"123456789012345678901234", intNumber},
+		{"0.1234567890123456789", floatNumber},
+		{"1e5", intNumber},
+		{"1.5e-3", floatNumber},
+		{"Inf", intNumber},
+		{"NaN", intNumber},
+		{"", notNumber},
+		{"-", notNumber},
+		{".", notNumber},
+		{"1.2.3", notNumber},
+		{"12a", notNumber},
+		{"abc", notNumber},
+		{"-Infinity", intNumber},
+		{"nan", intNumber},
+		{"0x1p-2", intNumber},
+		{"0x1F", notNumber},
+		{"1_000", intNumber},
+		{"cat_1", notNumber},
+		{"infinite", notNumber},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			kind, v := classifyNumber(tt.value)
+			if kind != tt.kind {
+				t.Errorf("Expected kind %d, got %d", tt.kind, kind)
+			}
+
+			// Must agree with strconv on both acceptance and value
+			want, err := strconv.ParseFloat(tt.value, 64)
+			if (err == nil) != (kind != notNumber) {
+				t.Errorf("Expected acceptance to match strconv (err %v), got kind %d", err, kind)
+			}
+			if err == nil && v != want && !(math.IsNaN(v) && math.IsNaN(want)) {
+				t.Errorf("Expected %v, got %v", want, v)
+			}
+			if kind == intNumber && strings.Contains(tt.value, ".") {
+				t.Errorf("Expected a value with a decimal point not to be an integer")
+			}
+		})
+	}
+}
+
+func BenchmarkClassifyNumber(b *testing.B) {
+	values := []string{"12345", "-3.25", "98765.4321", "not a number", "0.5"}
+	for i := 0; i < b.N; i++ {
+		classifyNumber(values[i%len(values)])
+	}
+}
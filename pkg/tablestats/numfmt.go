@@ -0,0 +1,90 @@
+package tablestats
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// siPrefixes abbreviate powers of a thousand, from a thousand up
+var siPrefixes = []string{"k", "M", "G", "T", "P", "E"}
+
+// NumberFormat controls how the human-readable reports write counts, sums
+// and other statistics. Values of the data, such as min and max, are written
+// as they are. The zero value groups thousands with commas, e.g. 1,234,567.
+type NumberFormat struct {
+	Locale language.Tag // Thousands and decimal separators; language.Und uses English ones
+	SI     bool         // Abbreviate numbers of a thousand or more with SI prefixes, e.g. 1.2M
+	Plain  bool         // Write numbers without separators, as machine formats do
+}
+
+// ParseLocale converts a locale name such as "de", "de-CH" or the POSIX
+// "de_DE.UTF-8" into a language tag. "", "C" and "POSIX" are language.Und.
+func ParseLocale(name string) (language.Tag, error) {
+	if i := strings.IndexAny(name, ".@"); i >= 0 {
+		name = name[:i]
+	}
+	if name == "" || name == "C" || name == "POSIX" {
+		return language.Und, nil
+	}
+	return language.Parse(strings.ReplaceAll(name, "_", "-"))
+}
+
+// numberWriter formats numbers as a NumberFormat says
+type numberWriter struct {
+	NumberFormat
+	p *message.Printer
+}
+
+func (f NumberFormat) writer() numberWriter {
+	return numberWriter{NumberFormat: f, p: message.NewPrinter(f.Locale)}
+}
+
+// int formats a count, e.g. "1,234,567" or "1.2M"
+func (w numberWriter) int(n int64) string {
+	switch {
+	case w.Plain:
+		return strconv.FormatInt(n, 10)
+	case w.SI && (n >= 1000 || n <= -1000):
+		return w.si(float64(n))
+	default:
+		return w.p.Sprintf("%d", n)
+	}
+}
+
+// float formats a statistic with two decimals, e.g. "25,000.50" or "25.0k"
+func (w numberWriter) float(v float64) string {
+	switch {
+	case w.Plain || !isFinite(v):
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case w.SI && math.Abs(v) >= 1000:
+		return w.si(v)
+	default:
+		return w.p.Sprintf("%.2f", v)
+	}
+}
+
+// si abbreviates v, at least a thousand in magnitude, with one decimal
+func (w numberWriter) si(v float64) string {
+	i := 0
+	for v /= 1000; math.Abs(v) >= 999.95 && i < len(siPrefixes)-1; i++ {
+		v /= 1000
+	}
+	return w.p.Sprintf("%.1f", v) + siPrefixes[i]
+}
+
+// bytes formats a size in decimal units, e.g. "3.4 GB"
+func (w numberWriter) bytes(n int64) string {
+	switch {
+	case w.Plain:
+		return strconv.FormatInt(n, 10) + " bytes"
+	case n < 1000:
+		return w.p.Sprintf("%d B", n)
+	default:
+		s := w.si(float64(n))
+		return s[:len(s)-1] + " " + s[len(s)-1:] + "B"
+	}
+}
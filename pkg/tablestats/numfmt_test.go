@@ -0,0 +1,95 @@
+package tablestats
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format NumberFormat
+		int    string
+		float  string
+		bytes  string
+	}{
+		{"default", NumberFormat{}, "1,234,567", "25,000.50", "3.4 GB"},
+		{"german", NumberFormat{Locale: language.German}, "1.234.567", "25.000,50", "3,4 GB"},
+		{"si", NumberFormat{SI: true}, "1.2M", "25.0k", "3.4 GB"},
+		{"plain", NumberFormat{Plain: true, SI: true}, "1234567", "25000.50", "3400000000 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nw := tt.format.writer()
+			if got := nw.int(1234567); got != tt.int {
+				t.Errorf("Expected %s, got %s", tt.int, got)
+			}
+			if got := nw.float(25000.5); got != tt.float {
+				t.Errorf("Expected %s, got %s", tt.float, got)
+			}
+			if got := nw.bytes(3_400_000_000); got != tt.bytes {
+				t.Errorf("Expected %s, got %s", tt.bytes, got)
+			}
+		})
+	}
+
+	si := NumberFormat{SI: true}.writer()
+	for n, expected := range map[int64]string{999: "999", 1000: "1.0k", 999_950: "1.0M", -2_500_000: "-2.5M"} {
+		if got := si.int(n); got != expected {
+			t.Errorf("Expected %d as %s, got %s", n, expected, got)
+		}
+	}
+	if got := si.bytes(512); got != "512 B" {
+		t.Errorf("Expected 512 B, got %s", got)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	for name, expected := range map[string]language.Tag{
+		"":            language.Und,
+		"C":           language.Und,
+		"C.UTF-8":     language.Und,
+		"de_DE.UTF-8": language.MustParse("de-DE"),
+		"fr-CH":       language.MustParse("fr-CH"),
+	} {
+		tag, err := ParseLocale(name)
+		if err != nil || tag != expected {
+			t.Errorf("ParseLocale(%q): expected %v, got %v, %v", name, expected, tag, err)
+		}
+	}
+	if _, err := ParseLocale("not a locale"); err == nil {
+		t.Error("Expected an error for an invalid locale")
+	}
+}
+
+func TestRender_Numbers(t *testing.T) {
+	stats := &TableStats{
+		RowCount:      1_500_000,
+		EstimatedRows: 1_500_000,
+		ColumnNames:   []string{"amount"},
+		ColumnTypes:   map[string]string{"amount": "int64"},
+		MinValues:     map[string]any{"amount": int64(1000)},
+		MaxValues:     map[string]any{"amount": int64(20000)},
+		Aggregates:    map[string]*AggregateStats{"amount": {Count: 1_500_000, Sum: 3e9, Mean: 2000}},
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{Wide: true, Numbers: NumberFormat{SI: true}}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, expected := range []string{"Sampled Rows: 1.5M\n", "Sum: 3.0G\n", "Mean: 2.0k\n", "Min: 1000\n"} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("Expected %q in the report, got:\n%s", expected, b.String())
+		}
+	}
+
+	b.Reset()
+	if err := (&MarkdownRenderer{Numbers: NumberFormat{Locale: language.German}}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "- Sampled rows: 1.500.000\n") || !strings.Contains(b.String(), "| 2.000,00 |") {
+		t.Errorf("Expected German separators, got:\n%s", b.String())
+	}
+}
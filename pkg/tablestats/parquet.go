@@ -1,6 +1,7 @@
-package stats
+package tablestats
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -16,8 +17,8 @@ func (r *ParquetReader) GetFormatName() string {
 	return "Parquet"
 }
 
-func (r *ParquetReader) ReadTable(filePath string, config SamplingConfig) (*TableStats, error) {
+func (r *ParquetReader) ReadTable(ctx context.Context, filePath string, config SamplingConfig) (*TableStats, error) {
 	// This is a mock implementation
 	// In a real implementation, you would use a parquet library with similar sampling logic
-	return nil, fmt.Errorf("parquet reader not fully implemented - requires parquet library like github.com/xitongsys/parquet-go")
+	return nil, fmt.Errorf("parquet reader not fully implemented - requires parquet library like github.com/xitongsys/parquet-go: %w", ErrUnsupportedFormat)
 }
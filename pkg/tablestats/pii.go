@@ -0,0 +1,197 @@
+package tablestats
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// PIIFlag marks a column that likely holds personal data
+type PIIFlag struct {
+	Kind  string  `json:"kind"`  // email, phone, national_id, credit_card or name
+	Score float64 `json:"score"` // Likelihood between 0 and 1
+}
+
+// piiCheckLimit is the number of non-null values per column run through the
+// detectors; the share that matches is the column's score
+const piiCheckLimit = 1000
+
+// piiMinScore is the score from which a column is flagged
+const piiMinScore = 0.5
+
+// piiHintBoost is added to the score of a kind the column header names
+const piiHintBoost = 0.25
+
+// piiDetector recognizes one kind of personal data
+type piiDetector struct {
+	kind  string
+	hints []string // Header fragments that suggest the kind
+	match func(value string) bool
+	weak  bool // Matches ordinary text too, so only counts for hinted headers
+}
+
+var piiDetectors = []piiDetector{
+	{kind: "email", hints: []string{"mail"}, match: isEmail},
+	{kind: "phone", hints: []string{"phone", "mobile"}, match: isPhone},
+	{kind: "national_id", hints: []string{"ssn", "national", "social"}, match: isSSN},
+	{kind: "credit_card", hints: []string{"card", "credit"}, match: isCardNumber},
+	{kind: "name", hints: []string{"name"}, match: isPersonName, weak: true},
+}
+
+// piiScanner counts how many of a column's leading values each detector matches
+type piiScanner struct {
+	hinted  []bool
+	matches []int
+	checked int
+}
+
+func newPIIScanner(column string) *piiScanner {
+	s := &piiScanner{
+		hinted:  make([]bool, len(piiDetectors)),
+		matches: make([]int, len(piiDetectors)),
+	}
+	header := strings.ToLower(column)
+	for i, d := range piiDetectors {
+		for _, hint := range d.hints {
+			if strings.Contains(header, hint) {
+				s.hinted[i] = true
+			}
+		}
+	}
+	return s
+}
+
+// wants reports whether the scanner still checks values
+func (s *piiScanner) wants() bool {
+	return s.checked < piiCheckLimit
+}
+
+// observe runs the detectors on a trimmed non-null value
+func (s *piiScanner) observe(value string) {
+	s.checked++
+	for i, d := range piiDetectors {
+		if (!d.weak || s.hinted[i]) && d.match(value) {
+			s.matches[i]++
+		}
+	}
+}
+
+// flag returns the most likely kind of personal data in the column, or nil.
+// A header that names the kind raises the score of matching values.
+func (s *piiScanner) flag() *PIIFlag {
+	if s.checked == 0 {
+		return nil
+	}
+	var best *PIIFlag
+	for i, d := range piiDetectors {
+		if s.matches[i] == 0 {
+			continue
+		}
+		score := float64(s.matches[i]) / float64(s.checked)
+		if s.hinted[i] {
+			score = math.Min(1, score+piiHintBoost)
+		}
+		if score >= piiMinScore && (best == nil || score > best.Score) {
+			best = &PIIFlag{Kind: d.kind, Score: score}
+		}
+	}
+	return best
+}
+
+// isEmail accepts local@domain.tld without spaces
+func isEmail(value string) bool {
+	at := strings.IndexByte(value, '@')
+	if at < 1 || strings.ContainsAny(value, " \t,;") {
+		return false
+	}
+	domain := value[at+1:]
+	dot := strings.LastIndexByte(domain, '.')
+	return dot > 0 && dot < len(domain)-2 && !strings.ContainsRune(domain, '@')
+}
+
+// isPhone accepts 10 to 15 digits written with an international prefix or
+// separators, so plain integer columns are not mistaken for phone numbers
+func isPhone(value string) bool {
+	digits, formatted := 0, strings.HasPrefix(value, "+")
+	for i, r := range value {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			formatted = true
+		default:
+			return false
+		}
+	}
+	return formatted && digits >= 10 && digits <= 15
+}
+
+// isSSN accepts US social security numbers written as 123-45-6789
+func isSSN(value string) bool {
+	if len(value) != 11 || value[3] != '-' || value[6] != '-' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if i != 3 && i != 6 && (value[i] < '0' || value[i] > '9') {
+			return false
+		}
+	}
+	return value[:3] != "000" && value[:3] != "666" && value[0] != '9'
+}
+
+// isCardNumber accepts 13 to 19 digits, optionally grouped by spaces or dashes,
+// that pass the Luhn checksum
+func isCardNumber(value string) bool {
+	digits := make([]byte, 0, 19)
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == ' ' || c == '-':
+		default:
+			return false
+		}
+		if len(digits) > 19 {
+			return false
+		}
+	}
+	return len(digits) >= 13 && luhnValid(digits)
+}
+
+// luhnValid checks the Luhn checksum of a digit string
+func luhnValid(digits []byte) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// isPersonName accepts one to three capitalized words, e.g. "Ada Lovelace"
+func isPersonName(value string) bool {
+	words := strings.Fields(value)
+	if len(words) == 0 || len(words) > 3 {
+		return false
+	}
+	for _, word := range words {
+		for i, r := range word {
+			switch {
+			case i == 0 && !unicode.IsUpper(r):
+				return false
+			case i > 0 && !unicode.IsLetter(r) && r != '\'' && r != '-':
+				return false
+			}
+		}
+	}
+	return true
+}
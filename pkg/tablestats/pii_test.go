@@ -0,0 +1,132 @@
+package tablestats
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestPIIDetectors(t *testing.T) {
+	tests := []struct {
+		name  string
+		match func(string) bool
+		value string
+		want  bool
+	}{
+		{"email", isEmail, "ada@example.com", true},
+		{"email without domain dot", isEmail, "ada@localhost", false},
+		{"email with space", isEmail, "ada lovelace@example.com", false},
+		{"email without local part", isEmail, "@example.com", false},
+		{"phone international", isPhone, "+44 20 7946 0958", true},
+		{"phone dashed", isPhone, "555-867-5309", true},
+		{"phone plain integer", isPhone, "5558675309", false},
+		{"phone too short", isPhone, "555-5309", false},
+		{"ssn", isSSN, "123-45-6789", true},
+		{"ssn invalid area", isSSN, "000-45-6789", false},
+		{"ssn without dashes", isSSN, "123456789", false},
+		{"card", isCardNumber, "4111 1111 1111 1111", true},
+		{"card dashed", isCardNumber, "5500-0000-0000-0004", true},
+		{"card bad checksum", isCardNumber, "4111111111111112", false},
+		{"card too short", isCardNumber, "4111111", false},
+		{"name", isPersonName, "Ada Lovelace", true},
+		{"name with apostrophe", isPersonName, "Miles O'Brien", true},
+		{"name lowercase", isPersonName, "ada", false},
+		{"name with digits", isPersonName, "Room 101", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match(tt.value); got != tt.want {
+				t.Errorf("Expected %v for %q, got %v", tt.want, tt.value, got)
+			}
+		})
+	}
+}
+
+func TestTableAccumulator_PII(t *testing.T) {
+	header := []string{"id", "contact", "phone", "ssn", "card", "full_name", "status", "account"}
+	acc, err := NewTableAccumulator(header, SamplingConfig{})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		acc.Add([]string{
+			fmt.Sprint(i),
+			fmt.Sprintf("user%d@example.com", i),
+			fmt.Sprintf("+1 555 010 %04d", i),
+			fmt.Sprintf("123-45-%04d", i),
+			"4111 1111 1111 1111",
+			"Ada Lovelace",
+			"Active",
+			fmt.Sprintf("%016d", 1000000000000000+i),
+		})
+	}
+	stats := acc.Finalize()
+
+	expected := map[string]string{
+		"contact":   "email",
+		"phone":     "phone",
+		"ssn":       "national_id",
+		"card":      "credit_card",
+		"full_name": "name",
+	}
+	for column, kind := range expected {
+		flag, ok := stats.PII[column]
+		if !ok || flag.Kind != kind {
+			t.Errorf("Expected %s to be flagged as %s, got %+v", column, kind, flag)
+		}
+	}
+	if len(stats.PII) != len(expected) {
+		t.Errorf("Expected %d flagged columns, got %v", len(expected), stats.PII)
+	}
+	if stats.PII["contact"].Score != 1 {
+		t.Errorf("Expected email score 1, got %f", stats.PII["contact"].Score)
+	}
+}
+
+func TestPIIScanner_Scores(t *testing.T) {
+	// A few Luhn-valid values in an unhinted column are not enough to flag it
+	s := newPIIScanner("reference")
+	for i := 0; i < 10; i++ {
+		s.observe("4111111111111111")
+	}
+	for i := 0; i < 90; i++ {
+		s.observe("other")
+	}
+	if flag := s.flag(); flag != nil {
+		t.Errorf("Expected no flag, got %+v", flag)
+	}
+
+	// The header raises the score of matching values
+	s = newPIIScanner("credit_card")
+	for i := 0; i < 30; i++ {
+		s.observe("4111111111111111")
+	}
+	for i := 0; i < 70; i++ {
+		s.observe("n/a")
+	}
+	if flag := s.flag(); flag == nil || flag.Kind != "credit_card" || !floatEqual(flag.Score, 0.55) {
+		t.Errorf("Expected credit_card with score 0.55, got %+v", flag)
+	}
+}
+
+func TestRenderers_PII(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header: []string{"email"},
+	}, [][]string{{"a@example.com"}, {"b@example.com"}}), SamplingConfig{})
+
+	var text, markdown bytes.Buffer
+	if err := (&TextRenderer{Wide: true}).Render(&text, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if err := (&MarkdownRenderer{}).Render(&markdown, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(text.String(), "    PII: email (score 1.00)") {
+		t.Errorf("Expected text output to flag email, got:\n%s", text.String())
+	}
+	if !strings.Contains(markdown.String(), "- email: email (score 1.00)") {
+		t.Errorf("Expected markdown output to flag email, got:\n%s", markdown.String())
+	}
+}
@@ -0,0 +1,279 @@
+package tablestats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// pluginProtocol is the version of the plugin protocol sent in the start message
+const pluginProtocol = 1
+
+// pluginBatchSize is the number of values of a column sent in one message
+const pluginBatchSize = 1024
+
+// pluginStderrBytes is how much of a plugin's stderr is kept for error messages
+const pluginStderrBytes = 4 << 10
+
+// pluginWaitDelay is how long a killed plugin's output pipes are waited on,
+// should its children keep them open
+const pluginWaitDelay = 5 * time.Second
+
+// DefaultPluginTimeout is how long a plugin may run when Plugin.Timeout is not set
+const DefaultPluginTimeout = 10 * time.Minute
+
+// Plugin is an external column analyzer run as a subprocess, so analyzers can
+// be shipped without modifying or recompiling gotablestats. One process is
+// started per profiled table and receives JSON messages on stdin, one per line:
+//
+//	{"type":"start","protocol":1,"columns":["id","email"]}
+//	{"type":"values","column":"email","values":["a@example.com","",...]}
+//	{"type":"finish"}
+//
+// Values are the raw fields in row order, nulls included, in batches of up to
+// 1024. After the finish message stdin is closed, and the plugin writes a
+// single JSON object to stdout and exits with status 0:
+//
+//	{"columns":{"email":{"metrics":{"domains":3},"type":"corporate_email"}}}
+//
+// metrics can be any JSON value and is stored in TableStats.CustomMetrics under
+// the plugin's name; type, if not empty, is reported as the column's detected
+// type. Columns the plugin does not list get no result. A plugin still running
+// after its timeout, or when the read it is part of is cancelled, is killed.
+type Plugin struct {
+	Name    string        `json:"name"`              // Key of the results in TableStats.CustomMetrics
+	Command []string      `json:"command"`           // Program and its arguments
+	Timeout time.Duration `json:"timeout,omitempty"` // Limit on the whole run; 0 means DefaultPluginTimeout
+}
+
+// ParsePlugin parses a plugin given as name=command, the command's arguments
+// separated by spaces, e.g. "iban=/opt/analyzers/iban --strict"
+func ParsePlugin(spec string) (Plugin, error) {
+	name, command, ok := strings.Cut(spec, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || len(strings.Fields(command)) == 0 {
+		return Plugin{}, fmt.Errorf("invalid plugin %q, expected name=command", spec)
+	}
+	return Plugin{Name: name, Command: strings.Fields(command)}, nil
+}
+
+// TypeDetector is implemented by column analyzers that recognize what kind of
+// values a column holds, beyond the storage type; e.g. "iban" for a string
+// column. An empty result means no type was recognized.
+type TypeDetector interface {
+	DetectedType() string
+}
+
+// pluginMessage is a message sent to a plugin
+type pluginMessage struct {
+	Type     string   `json:"type"`
+	Protocol int      `json:"protocol,omitempty"`
+	Columns  []string `json:"columns,omitempty"`
+	Column   string   `json:"column,omitempty"`
+	Values   []string `json:"values,omitempty"`
+}
+
+// pluginResponse is what a plugin writes once every value was sent
+type pluginResponse struct {
+	Columns map[string]struct {
+		Metrics any    `json:"metrics"`
+		Type    string `json:"type"`
+	} `json:"columns"`
+}
+
+// pluginSession runs a plugin over the columns of one table. The process is
+// started with the first batch of values, and finished by the first call for
+// a result or stopped by discard.
+type pluginSession struct {
+	ctx      context.Context // Of the read the table is part of
+	run      context.Context // Of the process, limited to timeout
+	cancel   context.CancelFunc
+	timeout  time.Duration
+	plugin   Plugin
+	columns  []string
+	batches  [][]string // Values of each column not sent yet
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	w        *bufio.Writer
+	enc      *json.Encoder
+	stdout   bytes.Buffer
+	stderr   limitedBuffer
+	finished bool
+	response pluginResponse
+	err      error
+}
+
+func newPluginSession(ctx context.Context, plugin Plugin, columns []string) *pluginSession {
+	return &pluginSession{ctx: ctx, plugin: plugin, columns: columns, batches: make([][]string, len(columns))}
+}
+
+// start runs the plugin and sends the start message
+func (s *pluginSession) start() {
+	if s.cmd != nil || s.err != nil {
+		return
+	}
+	s.timeout = s.plugin.Timeout
+	if s.timeout <= 0 {
+		s.timeout = DefaultPluginTimeout
+	}
+	s.run, s.cancel = context.WithTimeout(s.ctx, s.timeout)
+	s.cmd = exec.CommandContext(s.run, s.plugin.Command[0], s.plugin.Command[1:]...)
+	s.cmd.Stdout = &s.stdout
+	s.cmd.Stderr = &s.stderr
+	s.cmd.WaitDelay = pluginWaitDelay
+	stdin, err := s.cmd.StdinPipe()
+	if err == nil {
+		err = s.cmd.Start()
+	}
+	if err != nil {
+		s.cancel()
+		s.err = fmt.Errorf("plugin %s: %w", s.plugin.Name, err)
+		return
+	}
+	s.stdin = stdin
+	s.w = bufio.NewWriter(stdin)
+	s.enc = json.NewEncoder(s.w)
+	s.send(pluginMessage{Type: "start", Protocol: pluginProtocol, Columns: s.columns})
+}
+
+// send writes a message to the plugin, buffered until flushed by finish
+func (s *pluginSession) send(msg pluginMessage) {
+	if s.err != nil {
+		return
+	}
+	if err := s.enc.Encode(msg); err != nil {
+		s.err = s.failure(err)
+	}
+}
+
+// observe queues a value of the i-th column
+func (s *pluginSession) observe(i int, value string) {
+	s.batches[i] = append(s.batches[i], value)
+	if len(s.batches[i]) >= pluginBatchSize {
+		s.flush(i)
+	}
+}
+
+func (s *pluginSession) flush(i int) {
+	s.start()
+	s.send(pluginMessage{Type: "values", Column: s.columns[i], Values: s.batches[i]})
+	s.batches[i] = s.batches[i][:0]
+}
+
+// finish sends the remaining values and reads the plugin's response
+func (s *pluginSession) finish() {
+	if s.finished {
+		return
+	}
+	s.finished = true
+	s.start()
+	for i, batch := range s.batches {
+		if len(batch) > 0 {
+			s.flush(i)
+		}
+	}
+	s.send(pluginMessage{Type: "finish"})
+	if s.stdin == nil {
+		return
+	}
+	if err := s.w.Flush(); err != nil && s.err == nil {
+		s.err = s.failure(err)
+	}
+	s.wait()
+	if s.err != nil {
+		return
+	}
+	if err := json.Unmarshal(s.stdout.Bytes(), &s.response); err != nil {
+		s.err = fmt.Errorf("plugin %s wrote an invalid response: %w", s.plugin.Name, err)
+	}
+}
+
+// discard stops a plugin whose result will not be asked for
+func (s *pluginSession) discard() {
+	if s.finished {
+		return
+	}
+	s.finished = true
+	if s.stdin == nil {
+		return
+	}
+	s.cancel()
+	s.wait()
+}
+
+// wait closes the plugin's stdin and waits for it to exit
+func (s *pluginSession) wait() {
+	s.stdin.Close()
+	err := s.cmd.Wait()
+	if cause := s.run.Err(); cause != nil {
+		// A killed plugin fails with broken pipes and its signal; the reason is clearer
+		err = cause
+		if errors.Is(cause, context.DeadlineExceeded) && s.ctx.Err() == nil {
+			err = fmt.Errorf("timed out after %s", s.timeout)
+		}
+		s.err = s.failure(err)
+	} else if err != nil && s.err == nil {
+		s.err = s.failure(err)
+	}
+	s.cancel()
+}
+
+// failure describes an error of the plugin process with the start of its stderr
+func (s *pluginSession) failure(err error) error {
+	if msg := strings.TrimSpace(s.stderr.String()); msg != "" {
+		return fmt.Errorf("plugin %s: %w: %s", s.plugin.Name, err, msg)
+	}
+	return fmt.Errorf("plugin %s: %w", s.plugin.Name, err)
+}
+
+// pluginColumn is the analyzer of one column fed to a plugin session
+type pluginColumn struct {
+	session *pluginSession
+	index   int
+}
+
+func (c *pluginColumn) Observe(value string) {
+	c.session.observe(c.index, value)
+}
+
+// Result returns the plugin's metrics for the column, or its error
+func (c *pluginColumn) Result() any {
+	c.session.finish()
+	if c.session.err != nil {
+		return map[string]string{"error": c.session.err.Error()}
+	}
+	return c.session.response.Columns[c.session.columns[c.index]].Metrics
+}
+
+func (c *pluginColumn) DetectedType() string {
+	c.session.finish()
+	return c.session.response.Columns[c.session.columns[c.index]].Type
+}
+
+// limitedBuffer keeps the first pluginStderrBytes written to it
+type limitedBuffer struct {
+	bytes.Buffer
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := pluginStderrBytes - b.Len(); room > 0 {
+		b.Buffer.Write(p[:min(room, len(p))])
+	}
+	return len(p), nil
+}
+
+// typeLabel returns the type of a column followed by the kind of values an
+// analyzer recognized in it, e.g. "string (iban)"
+func typeLabel(stats *TableStats, name string) string {
+	if detected := stats.DetectedTypes[name]; detected != "" {
+		return stats.ColumnTypes[name] + " (" + detected + ")"
+	}
+	return stats.ColumnTypes[name]
+}
@@ -0,0 +1,216 @@
+package tablestats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPluginHelper is not a test: it is the plugin process started by the
+// tests below. It counts the values and empty values of each column, and
+// detects iban in the column of that name; in hang mode it never answers.
+func TestPluginHelper(t *testing.T) {
+	mode := os.Getenv("GTS_PLUGIN_HELPER")
+	if mode == "" {
+		return
+	}
+	type counts struct {
+		Values int `json:"values"`
+		Empty  int `json:"empty"`
+	}
+	result := make(map[string]*counts)
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var msg pluginMessage
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		switch msg.Type {
+		case "start":
+			if msg.Protocol != pluginProtocol {
+				fmt.Fprintln(os.Stderr, "unexpected protocol", msg.Protocol)
+				os.Exit(2)
+			}
+			for _, column := range msg.Columns {
+				result[column] = &counts{}
+			}
+		case "values":
+			for _, value := range msg.Values {
+				result[msg.Column].Values++
+				if value == "" {
+					result[msg.Column].Empty++
+				}
+			}
+		}
+	}
+	switch mode {
+	case "fail":
+		fmt.Fprintln(os.Stderr, "no license found")
+		os.Exit(3)
+	case "hang":
+		time.Sleep(time.Hour)
+	}
+
+	response := map[string]map[string]any{"columns": {}}
+	for column, c := range result {
+		entry := map[string]any{"metrics": c}
+		if column == "iban" {
+			entry["type"] = "iban"
+		}
+		response["columns"][column] = entry
+	}
+	json.NewEncoder(os.Stdout).Encode(response)
+	os.Exit(0)
+}
+
+// helperPlugin returns a plugin running TestPluginHelper in the given mode
+func helperPlugin(t *testing.T, name, mode string) Plugin {
+	t.Setenv("GTS_PLUGIN_HELPER", mode)
+	return Plugin{Name: name, Command: []string{os.Args[0], "-test.run=^TestPluginHelper$"}}
+}
+
+// pluginSample has more rows than fit in one batch
+func pluginSample() *Sample {
+	sample := &Sample{Header: []string{"id", "iban"}, EstimatedRows: 2500}
+	var records [][]string
+	for i := 1; i <= 2500; i++ {
+		iban := "DE89370400440532013000"
+		if i%10 == 0 {
+			iban = ""
+		}
+		records = append(records, []string{strconv.Itoa(i), iban})
+	}
+	return withRecords(sample, records)
+}
+
+func TestPlugin(t *testing.T) {
+	config := SamplingConfig{Plugins: []Plugin{helperPlugin(t, "counter", "ok")}}
+	stats := AnalyzeSample(pluginSample(), config)
+
+	metrics, ok := stats.CustomMetrics["counter"]["iban"].(map[string]any)
+	if !ok {
+		t.Fatalf("Expected counter metrics for iban, got %v", stats.CustomMetrics)
+	}
+	if metrics["values"] != 2500.0 || metrics["empty"] != 250.0 {
+		t.Errorf("Expected 2500 values and 250 empty, got %v", metrics)
+	}
+	if _, ok := stats.CustomMetrics["counter"]["id"]; !ok {
+		t.Error("Expected counter metrics for id")
+	}
+	if stats.DetectedTypes["iban"] != "iban" {
+		t.Errorf("Expected detected type iban, got %v", stats.DetectedTypes)
+	}
+	if _, ok := stats.DetectedTypes["id"]; ok {
+		t.Error("Expected no detected type for id")
+	}
+
+	// The detected type is shown next to the column type
+	var buf bytes.Buffer
+	if err := (&TextRenderer{Wide: true}).Render(&buf, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Type: string (iban)") {
+		t.Errorf("Expected output to contain the detected type, got:\n%s", buf.String())
+	}
+}
+
+func TestPlugin_Failure(t *testing.T) {
+	config := SamplingConfig{Plugins: []Plugin{helperPlugin(t, "counter", "fail")}}
+	stats := AnalyzeSample(pluginSample(), config)
+
+	result, ok := stats.CustomMetrics["counter"]["iban"].(map[string]string)
+	if !ok {
+		t.Fatalf("Expected an error result for iban, got %v", stats.CustomMetrics)
+	}
+	if !strings.Contains(result["error"], "exit status 3") || !strings.Contains(result["error"], "no license found") {
+		t.Errorf("Expected the exit status and stderr in the error, got %q", result["error"])
+	}
+	if len(stats.DetectedTypes) != 0 {
+		t.Errorf("Expected no detected types, got %v", stats.DetectedTypes)
+	}
+
+	// A missing program
+	config.Plugins = []Plugin{{Name: "missing", Command: []string{"/nonexistent/analyzer"}}}
+	stats = AnalyzeSample(pluginSample(), config)
+	if result, ok := stats.CustomMetrics["missing"]["id"].(map[string]string); !ok || result["error"] == "" {
+		t.Errorf("Expected an error result for a missing program, got %v", stats.CustomMetrics)
+	}
+}
+
+func TestParsePlugin(t *testing.T) {
+	plugin, err := ParsePlugin("iban=/opt/analyzers/iban --strict  --country DE")
+	if err != nil {
+		t.Fatalf("ParsePlugin failed: %v", err)
+	}
+	if plugin.Name != "iban" || strings.Join(plugin.Command, "|") != "/opt/analyzers/iban|--strict|--country|DE" {
+		t.Errorf("Expected iban with 4 command words, got %+v", plugin)
+	}
+
+	for _, spec := range []string{"iban", "=iban", "iban=", "iban=  "} {
+		if _, err := ParsePlugin(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestPlugin_Timeout(t *testing.T) {
+	plugin := helperPlugin(t, "counter", "hang")
+	plugin.Timeout = 200 * time.Millisecond
+	start := time.Now()
+	stats := AnalyzeSample(pluginSample(), SamplingConfig{Plugins: []Plugin{plugin}})
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Expected the plugin to be killed after its timeout, took %s", elapsed)
+	}
+	result, ok := stats.CustomMetrics["counter"]["iban"].(map[string]string)
+	if !ok || !strings.Contains(result["error"], "timed out after 200ms") {
+		t.Errorf("Expected a timeout error, got %v", stats.CustomMetrics)
+	}
+}
+
+func TestPlugin_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	acc, err := newTableAccumulator(ctx, []string{"id"}, SamplingConfig{Plugins: []Plugin{helperPlugin(t, "counter", "hang")}})
+	if err != nil {
+		t.Fatalf("newTableAccumulator failed: %v", err)
+	}
+	for i := 0; i < pluginBatchSize; i++ {
+		acc.Add([]string{strconv.Itoa(i)})
+	}
+	session := acc.plugins[0]
+	if session.cmd == nil || session.cmd.Process == nil {
+		t.Fatal("Expected a full batch to start the plugin")
+	}
+
+	cancel()
+	stats := acc.Finalize()
+	if result, ok := stats.CustomMetrics["counter"]["id"].(map[string]string); !ok || !strings.Contains(result["error"], "context canceled") {
+		t.Errorf("Expected a cancellation error, got %v", stats.CustomMetrics)
+	}
+	if session.cmd.ProcessState == nil {
+		t.Error("Expected the killed plugin to be waited on")
+	}
+}
+
+func TestTableAccumulator_Discard(t *testing.T) {
+	acc, err := NewTableAccumulator([]string{"id"}, SamplingConfig{Plugins: []Plugin{helperPlugin(t, "counter", "hang")}})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for i := 0; i < pluginBatchSize; i++ {
+		acc.Add([]string{strconv.Itoa(i)})
+	}
+	acc.Discard()
+	if session := acc.plugins[0]; session.cmd.ProcessState == nil {
+		t.Error("Expected the discarded plugin to be killed and waited on")
+	}
+}
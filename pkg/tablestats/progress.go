@@ -0,0 +1,39 @@
+package tablestats
+
+import "io"
+
+// ProgressFunc receives the bytes consumed from the source, the total size of the
+// source (-1 when unknown) and the number of rows parsed so far
+type ProgressFunc func(bytesRead, totalBytes, rowsProcessed int64)
+
+// progressReporter accumulates read progress and forwards it to a ProgressFunc, if any
+type progressReporter struct {
+	fn    ProgressFunc
+	total int64
+	bytes int64
+	rows  int64
+}
+
+func newProgressReporter(fn ProgressFunc, total int64) *progressReporter {
+	return &progressReporter{fn: fn, total: total}
+}
+
+func (p *progressReporter) report() {
+	if p != nil && p.fn != nil {
+		p.fn(p.bytes, p.total, p.rows)
+	}
+}
+
+// countingReader adds every byte read from r to the reporter's byte count
+type countingReader struct {
+	r io.Reader
+	p *progressReporter
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	if c.p != nil {
+		c.p.bytes += int64(n)
+	}
+	return n, err
+}
@@ -0,0 +1,149 @@
+package tablestats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Methods a metric can be computed with
+const (
+	MethodCount          = "count"           // Computed over every row profiled
+	MethodSample         = "sample"          // Computed over sampled rows and taken to hold for the whole table
+	MethodExtrapolated   = "extrapolated"    // Scaled from the sampled rows to the estimated table size
+	MethodHyperLogLog    = "hyperloglog"     // Estimated with a HyperLogLog sketch
+	MethodTDigest        = "t-digest"        // Estimated with a t-digest
+	MethodQuantileSketch = "quantile-sketch" // Interpolated from a uniform subset of the values
+)
+
+// MetricSource tells how a reported metric was computed
+type MetricSource struct {
+	Exact        bool   `json:"exact"`
+	Method       string `json:"method"`       // One of the Method constants
+	Observations int64  `json:"observations"` // Rows or non-null values the metric was computed from
+}
+
+// Provenance tells which metrics of a profile are exact and which are
+// estimated, so that a sampled null share is not taken for an exact one.
+// Metrics are named as in expectations: row_count and estimated_rows for the
+// table, and null_count, null_pct, distinct, min, max, top_values, mean, sum,
+// std_dev, variance, percentiles and estimated_total for columns.
+type Provenance struct {
+	Exact   bool                               `json:"exact"`   // Every row of the table, or of the requested window, was profiled
+	Rows    int64                              `json:"rows"`    // Rows profiled
+	Table   map[string]MetricSource            `json:"table"`   // Table metric -> source
+	Columns map[string]map[string]MetricSource `json:"columns"` // Column -> metric -> source
+}
+
+// newProvenance describes a profile of rows out of estimatedRows
+func newProvenance(rows, estimatedRows int64) *Provenance {
+	p := &Provenance{
+		Exact:   rows == estimatedRows,
+		Rows:    rows,
+		Columns: make(map[string]map[string]MetricSource),
+	}
+	p.Table = map[string]MetricSource{
+		"row_count":      {Exact: true, Method: MethodCount, Observations: rows},
+		"estimated_rows": p.extrapolated(rows),
+	}
+	return p
+}
+
+// source describes a metric computed with method over n observations, which
+// is only exact for exact methods on an exact profile
+func (p *Provenance) source(method string, n int64) MetricSource {
+	switch {
+	case method != MethodCount:
+		return MetricSource{Method: method, Observations: n}
+	case p.Exact:
+		return MetricSource{Exact: true, Method: MethodCount, Observations: n}
+	default:
+		return MetricSource{Method: MethodSample, Observations: n}
+	}
+}
+
+// extrapolated describes a metric scaled to the estimated table size, which
+// is only exact when the profile is
+func (p *Provenance) extrapolated(n int64) MetricSource {
+	if p.Exact {
+		return p.source(MethodCount, n)
+	}
+	return MetricSource{Method: MethodExtrapolated, Observations: n}
+}
+
+// addColumn records the sources of the metrics of a finalized column
+func (p *Provenance) addColumn(c *columnAccumulator, stats *TableStats) {
+	name := c.name
+	nonNull := c.rows - c.nullCount
+	metrics := map[string]MetricSource{
+		"null_count": p.source(MethodCount, c.rows),
+		"null_pct":   p.source(MethodCount, c.rows),
+		"distinct":   p.source(MethodCount, nonNull),
+		"min":        p.source(MethodCount, nonNull),
+		"max":        p.source(MethodCount, nonNull),
+	}
+	if c.distinctHLL != nil {
+		metrics["distinct"] = p.source(MethodHyperLogLog, nonNull)
+	}
+	if _, ok := stats.Categories[name]; ok {
+		metrics["top_values"] = p.source(MethodCount, nonNull)
+	}
+	if agg, ok := stats.Aggregates[name]; ok {
+		for _, metric := range []string{"mean", "sum", "std_dev", "variance"} {
+			metrics[metric] = p.source(MethodCount, agg.Count)
+		}
+		percentiles := MethodCount
+		switch {
+		case len(c.numericValues) > 0:
+			// Weighted percentiles are computed from every value
+		case c.numeric.digest != nil:
+			percentiles = MethodTDigest
+		case c.numeric.quantiles.seen > exactQuantileLimit:
+			percentiles = MethodQuantileSketch
+		}
+		metrics["percentiles"] = p.source(percentiles, agg.Count)
+		metrics["estimated_total"] = p.extrapolated(agg.Count)
+	}
+	p.Columns[name] = metrics
+}
+
+// summary describes the profile, e.g. "exact, computed from all 500 rows"
+func (p *Provenance) summary() string {
+	if p.Exact {
+		return fmt.Sprintf("exact, computed from all %d rows", p.Rows)
+	}
+	return fmt.Sprintf("estimated from %d sampled rows", p.Rows)
+}
+
+// sketchNote returns " (estimated, <method>)" when a metric of a column was
+// estimated with a sketch, "" otherwise
+func (p *Provenance) sketchNote(column, metric string) string {
+	if p == nil {
+		return ""
+	}
+	switch method := p.Columns[column][metric].Method; method {
+	case MethodHyperLogLog, MethodTDigest, MethodQuantileSketch:
+		return fmt.Sprintf(" (estimated, %s)", method)
+	}
+	return ""
+}
+
+// sketched lists the metrics estimated with sketches and the columns, out of
+// names, they were estimated for, e.g. "distinct (hyperloglog): id, name"
+func (p *Provenance) sketched(names []string) []string {
+	columns := make(map[string][]string)
+	for _, name := range names {
+		for _, metric := range sortedKeys(p.Columns[name]) {
+			if note := p.sketchNote(name, metric); note != "" {
+				key := metric + " (" + p.Columns[name][metric].Method + ")"
+				columns[key] = append(columns[key], name)
+			}
+		}
+	}
+	var lines []string
+	for key, names := range columns {
+		lines = append(lines, fmt.Sprintf("%s: %s", key, strings.Join(names, ", ")))
+	}
+	sort.Strings(lines)
+	return lines
+}
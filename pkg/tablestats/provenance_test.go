@@ -0,0 +1,57 @@
+package tablestats
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProvenance(t *testing.T) {
+	header := []string{"id", "name"}
+	records := [][]string{{"1", "a"}, {"2", ""}, {"3", "c"}, {"4", "d"}}
+
+	exact := AnalyzeSample(withRecords(&Sample{Header: header, EstimatedRows: 4, Exact: true}, records), SamplingConfig{})
+	p := exact.Provenance
+	if !p.Exact || p.Rows != 4 {
+		t.Fatalf("Expected an exact profile of 4 rows, got %+v", p)
+	}
+	if source := p.Columns["name"]["null_pct"]; source != (MetricSource{Exact: true, Method: MethodCount, Observations: 4}) {
+		t.Errorf("Expected an exact null share over 4 rows, got %+v", source)
+	}
+	if source := p.Columns["name"]["distinct"]; source.Observations != 3 {
+		t.Errorf("Expected distinct over 3 non-null values, got %+v", source)
+	}
+	if _, ok := p.Columns["name"]["mean"]; ok {
+		t.Errorf("Expected no aggregates for a string column")
+	}
+
+	sampled := AnalyzeSample(withRecords(&Sample{Header: header, EstimatedRows: 400}, records), SamplingConfig{})
+	p = sampled.Provenance
+	if p.Exact {
+		t.Fatalf("Expected a sampled profile, got %+v", p)
+	}
+	for metric, method := range map[string]string{
+		"null_pct":        MethodSample,
+		"mean":            MethodSample,
+		"percentiles":     MethodSample,
+		"estimated_total": MethodExtrapolated,
+	} {
+		if source := p.Columns["id"][metric]; source.Exact || source.Method != method || source.Observations != 4 {
+			t.Errorf("Expected %s estimated by %s from 4 values, got %+v", metric, method, source)
+		}
+	}
+	if source := p.Table["estimated_rows"]; source.Exact || source.Method != MethodExtrapolated {
+		t.Errorf("Expected extrapolated row count, got %+v", source)
+	}
+	if source := p.Table["row_count"]; !source.Exact {
+		t.Errorf("Expected the profiled row count to be exact, got %+v", source)
+	}
+
+	var buf bytes.Buffer
+	if err := (&TextRenderer{}).Render(&buf, sampled); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Metrics: estimated from 4 sampled rows") {
+		t.Errorf("Expected the report to say metrics are estimated, got:\n%s", buf.String())
+	}
+}
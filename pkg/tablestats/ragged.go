@@ -0,0 +1,112 @@
+package tablestats
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxRaggedExamples is the number of line numbers kept of short and of long rows
+const maxRaggedExamples = 5
+
+// RaggedRows reports rows whose number of fields differs from the header's.
+// The missing fields of short rows are profiled as nulls; the extra fields of
+// long rows are ignored.
+type RaggedRows struct {
+	Rows       int64   `json:"rows"`                  // Rows checked, which includes rows read but not kept when sampling
+	Short      int64   `json:"short"`                 // Rows with fewer fields than the header
+	Long       int64   `json:"long"`                  // Rows with more fields than the header
+	Percentage float64 `json:"percentage"`            // Share of the checked rows that are short or long
+	ShortLines []int   `json:"short_lines,omitempty"` // Line numbers of the first short rows, when known
+	LongLines  []int   `json:"long_lines,omitempty"`  // Line numbers of the first long rows, when known
+}
+
+// raggedCounter compares the field count of every row read with the header's
+type raggedCounter struct {
+	width int
+	RaggedRows
+}
+
+func newRaggedCounter(header []string) *raggedCounter {
+	return &raggedCounter{width: len(header)}
+}
+
+// observe counts a row of n fields starting on the given line, 0 when the
+// line is not known
+func (c *raggedCounter) observe(n, line int) {
+	c.Rows++
+	switch {
+	case n < c.width:
+		c.Short++
+		if line > 0 && len(c.ShortLines) < maxRaggedExamples {
+			c.ShortLines = append(c.ShortLines, line)
+		}
+	case n > c.width:
+		c.Long++
+		if line > 0 && len(c.LongLines) < maxRaggedExamples {
+			c.LongLines = append(c.LongLines, line)
+		}
+	}
+}
+
+// result returns the rows checked so far
+func (c *raggedCounter) result() *RaggedRows {
+	r := c.RaggedRows
+	if r.Rows > 0 {
+		r.Percentage = float64(r.Short+r.Long) / float64(r.Rows) * 100
+	}
+	return &r
+}
+
+// found returns r when it has short or long rows, nil otherwise
+func (r *RaggedRows) found() *RaggedRows {
+	if r == nil || r.Short+r.Long == 0 {
+		return nil
+	}
+	return r
+}
+
+// mergeRaggedRows sums the rows checked in several parts. Line numbers are
+// dropped as they are relative to each part.
+func mergeRaggedRows(parts []*RaggedRows) *RaggedRows {
+	var merged raggedCounter
+	for _, r := range parts {
+		if r == nil {
+			// Field counts of this part were not checked
+			return nil
+		}
+		merged.Rows += r.Rows
+		merged.Short += r.Short
+		merged.Long += r.Long
+	}
+	return merged.result()
+}
+
+// summary describes the ragged rows, e.g. "3 of 100 rows (3.00%): 2 short
+// (lines 4, 9), 1 long (line 7)"
+func (r *RaggedRows) summary() string {
+	var parts []string
+	for _, kind := range []struct {
+		name  string
+		count int64
+		lines []int
+	}{{"short", r.Short, r.ShortLines}, {"long", r.Long, r.LongLines}} {
+		if kind.count == 0 {
+			continue
+		}
+		part := fmt.Sprintf("%d %s", kind.count, kind.name)
+		if len(kind.lines) > 0 {
+			lines := make([]string, len(kind.lines))
+			for i, line := range kind.lines {
+				lines[i] = strconv.Itoa(line)
+			}
+			label := "lines"
+			if len(lines) == 1 {
+				label = "line"
+			}
+			part += fmt.Sprintf(" (%s %s)", label, strings.Join(lines, ", "))
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("%d of %d rows (%.2f%%): %s", r.Short+r.Long, r.Rows, r.Percentage, strings.Join(parts, ", "))
+}
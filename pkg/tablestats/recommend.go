@@ -0,0 +1,148 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// A string column is suggested for dictionary encoding when it has at most
+// dictionaryMaxDistinct values and at most one distinct value per
+// dictionaryMinRepeats non-null values
+const (
+	dictionaryMaxDistinct = 1 << 16
+	dictionaryMinRepeats  = 10
+)
+
+// A column is reported as barely compressible when its ratio is below
+// incompressibleRatio and it takes at least incompressibleShare of the
+// compressed table
+const (
+	incompressibleRatio = 1.5
+	incompressibleShare = 0.25
+)
+
+// decimalMaxScale is the largest scale a float column is suggested as
+// DECIMAL with; columns with more digits after the point are measurements
+// better kept as float64
+const decimalMaxScale = 4
+
+// dateWords are header words of columns holding dates or times, which are
+// good sort keys
+var dateWords = []string{"date", "time", "timestamp", "datetime", "ts", "day", "at", "on"}
+
+// Recommendation is a suggestion for storing the table in a columnar format
+// or warehouse
+type Recommendation struct {
+	Column string `json:"column"`
+	Kind   string `json:"kind"`   // drop, type, dictionary, incompressible or sort
+	Detail string `json:"detail"` // e.g. "encode status as dictionary (3 distinct values)"
+}
+
+// recommend derives storage suggestions from the cardinality, type and
+// compressibility of each column
+func recommend(stats *TableStats) []Recommendation {
+	var recs []Recommendation
+	add := func(column, kind, format string, args ...any) {
+		recs = append(recs, Recommendation{Column: column, Kind: kind, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	var compressed int64
+	for _, comp := range stats.Compression {
+		compressed += comp.EstimatedBytes
+	}
+	var dictionary []string
+	for _, name := range stats.ColumnNames {
+		count := stats.RowCount - stats.NullCounts[name]
+		distinct := stats.DistinctCounts[name]
+		switch {
+		case count == 0:
+			add(name, "drop", "drop %s, it is always null", name)
+			continue
+		case distinct == 1 && stats.NullCounts[name] == 0:
+			add(name, "drop", "drop %s, it always holds %v", name, stats.MinValues[name])
+			continue
+		}
+
+		switch stats.ColumnTypes[name] {
+		case "int64":
+			add(name, "type", "store %s as %s not string", name, integerType(stats.MinValues[name], stats.MaxValues[name]))
+		case "float64":
+			if d, ok := stats.Decimals[name]; ok && d.Precision <= 18 && d.Scale <= decimalMaxScale {
+				add(name, "type", "store %s as %s not string", name, d.DDL())
+			} else {
+				add(name, "type", "store %s as float64 not string", name)
+			}
+		default:
+			if distinct <= dictionaryMaxDistinct && distinct*dictionaryMinRepeats <= count {
+				add(name, "dictionary", "encode %s as dictionary (%d distinct values)", name, distinct)
+				dictionary = append(dictionary, name)
+			}
+		}
+
+		if comp, ok := stats.Compression[name]; ok && comp.Ratio < incompressibleRatio &&
+			float64(comp.EstimatedBytes) >= incompressibleShare*float64(compressed) {
+			add(name, "incompressible", "%s barely compresses (%.1fx) and takes %.0f%% of the compressed table; drop it or store a shorter key if it is not needed",
+				name, comp.Ratio, float64(comp.EstimatedBytes)/float64(compressed)*100)
+		}
+	}
+
+	if key := sortKey(stats, dictionary); key != "" {
+		add(key, "sort", "sort by %s for better compression", key)
+	}
+	return recs
+}
+
+// integerType returns the narrowest integer type holding values from lo to
+// hi, e.g. "int32"
+func integerType(lo, hi any) string {
+	low, okLow := numericValue(lo)
+	high, okHigh := numericValue(hi)
+	if !okLow || !okHigh {
+		return "int64"
+	}
+	for _, t := range []struct {
+		name string
+		bits int
+	}{{"int8", 8}, {"int16", 16}, {"int32", 32}} {
+		limit := math.Ldexp(1, t.bits-1)
+		if low >= -limit && high < limit {
+			return t.name
+		}
+	}
+	return "int64"
+}
+
+// sortKey returns the column sorting by would compress the table best: the
+// first column named like a date or time, or else the dictionary column with
+// the fewest values, whose equal values then form long runs
+func sortKey(stats *TableStats, dictionary []string) string {
+	for _, name := range stats.ColumnNames {
+		if stats.RowCount-stats.NullCounts[name] == 0 || stats.DistinctCounts[name] <= 1 {
+			continue
+		}
+		for _, word := range headerWords(name) {
+			if slices.Contains(dateWords, word) {
+				return name
+			}
+		}
+	}
+	key := ""
+	for _, name := range dictionary {
+		if key == "" || stats.DistinctCounts[name] < stats.DistinctCounts[key] {
+			key = name
+		}
+	}
+	return key
+}
+
+// renderRecommendations prints the storage suggestions
+func (r *TextRenderer) renderRecommendations(ew *errWriter, stats *TableStats) {
+	if len(stats.Recommendations) == 0 {
+		return
+	}
+	ew.printf("\nStorage Recommendations:\n")
+	for _, rec := range stats.Recommendations {
+		ew.printf("  - %s\n", rec.Detail)
+	}
+}
@@ -0,0 +1,81 @@
+package tablestats
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableStats_Recommendations(t *testing.T) {
+	sample := &Sample{Header: []string{"id", "status", "amount", "created_at", "source", "note"}, EstimatedRows: 200}
+	var records [][]string
+	for i := range 200 {
+		status := []string{"open", "closed"}[i%2]
+		records = append(records, []string{
+			fmt.Sprint(i * 200), status, fmt.Sprintf("%d.25", i), fmt.Sprintf("2024-01-%02d", i%28+1), "web", "",
+		})
+	}
+	withRecords(sample, records)
+	config := DefaultSamplingConfig()
+	config.Recommend = true
+	stats := AnalyzeSample(sample, config)
+
+	var got []string
+	for _, rec := range stats.Recommendations {
+		got = append(got, rec.Kind+": "+rec.Detail)
+	}
+	expected := []string{
+		"type: store id as int32 not string",
+		"dictionary: encode status as dictionary (2 distinct values)",
+		"type: store amount as DECIMAL(5,2) not string",
+		"drop: drop source, it always holds web",
+		"drop: drop note, it is always null",
+		"sort: sort by created_at for better compression",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected recommendations\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "Storage Recommendations:\n  - store id as int32 not string\n") {
+		t.Errorf("Expected the recommendations in the report, got:\n%s", b.String())
+	}
+
+	config.Recommend = false
+	if recs := AnalyzeSample(sample, config).Recommendations; recs != nil {
+		t.Errorf("Expected no recommendations unless requested, got %v", recs)
+	}
+}
+
+func TestIntegerType(t *testing.T) {
+	tests := []struct {
+		lo, hi   any
+		expected string
+	}{
+		{int64(0), int64(127), "int8"},
+		{int64(-129), int64(0), "int16"},
+		{int64(0), int64(1 << 31), "int64"},
+		{nil, nil, "int64"},
+	}
+	for _, tt := range tests {
+		if got := integerType(tt.lo, tt.hi); got != tt.expected {
+			t.Errorf("integerType(%v, %v): expected %s, got %s", tt.lo, tt.hi, tt.expected, got)
+		}
+	}
+}
+
+func TestSortKey_Dictionary(t *testing.T) {
+	stats := &TableStats{
+		ColumnNames:    []string{"region", "status"},
+		RowCount:       100,
+		NullCounts:     map[string]int64{},
+		DistinctCounts: map[string]int64{"region": 5, "status": 2},
+	}
+	if key := sortKey(stats, []string{"region", "status"}); key != "status" {
+		t.Errorf("Expected to sort by the column with the fewest values, got %q", key)
+	}
+}
@@ -0,0 +1,159 @@
+package tablestats
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// refCheckFalsePositiveRate bounds the share of missing keys a referential
+// check can overlook because the key filter wrongly reports them as present
+const refCheckFalsePositiveRate = 0.001
+
+// ColumnScanner is implemented by readers that can stream every value of one
+// column of a file
+type ColumnScanner interface {
+	// ScanColumn calls fn with the trimmed value of column in every row, in order
+	ScanColumn(ctx context.Context, filePath, column string, fn func(value string)) error
+}
+
+// ColumnRef names a column of a file and the reader for it
+type ColumnRef struct {
+	Reader ColumnScanner
+	Path   string
+	Column string
+}
+
+// RefCheckReport is the outcome of checking a foreign key column against the
+// keys of another file
+type RefCheckReport struct {
+	ParentKeys        int64 // Non-null key values read, including duplicates
+	CheckedValues     int64 // Non-null foreign key values checked
+	NullValues        int64 // Null foreign key values, which are not checked
+	Missing           int64 // Foreign key values without a matching key
+	Examples          []ViolationExample
+	FalsePositiveRate float64 // Upper bound on missing values reported as present
+	FilterBytes       int64   // Memory used by the key filter
+}
+
+// ScanColumn reads the whole file once, copying out only the requested column
+func (r *CSVReader) ScanColumn(ctx context.Context, filePath, column string, fn func(value string)) error {
+	enc, err := lookupEncoding(r.Encoding)
+	if err != nil {
+		return err
+	}
+	if err := r.validateDialect(); err != nil {
+		return err
+	}
+	file, _, err := openTable(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	csvReader := r.newCSVReader(ctx, enc.decode(file))
+	csvReader.tracked = true
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return ErrEmptyFile
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	stripBOM(header)
+	header, _ = uniqueHeader(header)
+
+	idx := -1
+	for i, name := range header {
+		if name == column {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return fmt.Errorf("column %q: %w", column, ErrUnknownColumn)
+	}
+
+	csvReader.ReuseRecord = true
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV: %w", err)
+		}
+		value := ""
+		if idx < len(record) {
+			value = strings.TrimSpace(record[idx])
+		}
+		fn(value)
+	}
+}
+
+// RefCheck reports the values of child that do not occur in parent, e.g.
+// order customer IDs without a customer. The parent keys are kept in a Bloom
+// filter, so memory grows with the number of keys at a few bytes each
+// rather than with their length. Null foreign keys are not checked.
+func RefCheck(ctx context.Context, child, parent ColumnRef) (*RefCheckReport, error) {
+	report := &RefCheckReport{FalsePositiveRate: refCheckFalsePositiveRate}
+
+	keys := newScalableBloom(refCheckFalsePositiveRate)
+	err := parent.Reader.ScanColumn(ctx, parent.Path, parent.Column, func(value string) {
+		if !isNullValue(value) {
+			keys.add(value)
+			report.ParentKeys++
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", parent.Path, err)
+	}
+	report.FilterBytes = keys.bytes()
+
+	var row int64
+	err = child.Reader.ScanColumn(ctx, child.Path, child.Column, func(value string) {
+		row++
+		if isNullValue(value) {
+			report.NullValues++
+			return
+		}
+		report.CheckedValues++
+		if keys.has(value) {
+			return
+		}
+		report.Missing++
+		if len(report.Examples) < maxViolationExamples {
+			report.Examples = append(report.Examples, ViolationExample{Row: row, Value: value})
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", child.Path, err)
+	}
+	return report, nil
+}
+
+// MissingPct returns missing values as a percentage of checked values
+func (r *RefCheckReport) MissingPct() float64 {
+	if r.CheckedValues == 0 {
+		return 0
+	}
+	return float64(r.Missing) / float64(r.CheckedValues) * 100
+}
+
+// WriteText writes the report of child's values checked against parent
+func (r *RefCheckReport) WriteText(w io.Writer, child, parent ColumnRef) error {
+	ew := &errWriter{w: w}
+	ew.printf("=== Referential Integrity ===\n")
+	ew.printf("%s:%s -> %s:%s\n", child.Path, child.Column, parent.Path, parent.Column)
+	nw := NumberFormat{}.writer()
+	ew.printf("Parent Keys: %s (filter %s)\n", nw.int(r.ParentKeys), nw.bytes(r.FilterBytes))
+	ew.printf("Checked Values: %d (%d null)\n", r.CheckedValues, r.NullValues)
+	ew.printf("Missing: %d (%.2f%%, may undercount by up to %.1f%%)\n",
+		r.Missing, r.MissingPct(), r.FalsePositiveRate*100)
+	for _, ex := range r.Examples {
+		ew.printf("  row %d: %q\n", ex.Row, ex.Value)
+	}
+	ew.printf("\n")
+	return ew.err
+}
@@ -0,0 +1,104 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestScalableBloom(t *testing.T) {
+	n := 300000 // Grows past the initial capacity several times
+	bloom := newScalableBloom(0.01)
+	for i := 0; i < n; i++ {
+		bloom.add(fmt.Sprintf("key_%d", i))
+	}
+	if len(bloom.filters) < 2 {
+		t.Errorf("Expected the filter to grow, got %d filters", len(bloom.filters))
+	}
+
+	for i := 0; i < n; i++ {
+		if !bloom.has(fmt.Sprintf("key_%d", i)) {
+			t.Fatalf("Expected key_%d to be present", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 0; i < n; i++ {
+		if bloom.has(fmt.Sprintf("other_%d", i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / float64(n); rate > 0.01 {
+		t.Errorf("Expected a false positive rate below 1%%, got %.4f", rate)
+	}
+	if perKey := float64(bloom.bytes()) / float64(n); perKey > 4 {
+		t.Errorf("Expected at most 4 bytes per key, got %.2f", perKey)
+	}
+}
+
+func TestRefCheck(t *testing.T) {
+	customers := createTempCSV(t, "id,name\n1,Alice\n2,Bob\n3,Carol\n", ',')
+	orders := writeRawFile(t, "orders.csv", []byte("order_id,customer_id\n10,1\n11, 4 \n12,\n13,2\n14,9\n15,NULL\n"))
+	reader := NewCSVReader(',')
+
+	child := ColumnRef{Reader: reader, Path: orders, Column: "customer_id"}
+	parent := ColumnRef{Reader: reader, Path: customers, Column: "id"}
+	report, err := RefCheck(context.Background(), child, parent)
+	if err != nil {
+		t.Fatalf("RefCheck failed: %v", err)
+	}
+
+	if report.ParentKeys != 3 || report.CheckedValues != 4 || report.NullValues != 2 {
+		t.Errorf("Expected 3 keys, 4 checked and 2 null values, got %+v", report)
+	}
+	if report.Missing != 2 || !floatEqual(report.MissingPct(), 50) {
+		t.Errorf("Expected 2 missing values (50%%), got %d (%f)", report.Missing, report.MissingPct())
+	}
+	expected := []ViolationExample{{Row: 2, Value: "4"}, {Row: 5, Value: "9"}}
+	if len(report.Examples) != len(expected) {
+		t.Fatalf("Expected examples %v, got %v", expected, report.Examples)
+	}
+	for i, ex := range expected {
+		if report.Examples[i] != ex {
+			t.Errorf("Expected example %v, got %v", ex, report.Examples[i])
+		}
+	}
+
+	var b strings.Builder
+	if err := report.WriteText(&b, child, parent); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, line := range []string{
+		orders + ":customer_id -> " + customers + ":id\n",
+		"Checked Values: 4 (2 null)\n",
+		"Missing: 2 (50.00%",
+		"  row 5: \"9\"\n",
+	} {
+		if !strings.Contains(b.String(), line) {
+			t.Errorf("Expected %q in the report, got:\n%s", line, b.String())
+		}
+	}
+
+	child.Column = "customer"
+	if _, err := RefCheck(context.Background(), child, parent); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestCSVReader_ScanColumn(t *testing.T) {
+	path := writeRawFile(t, "quoted.csv", []byte("\ufeffid;note\n'a;b';x\n2;y\n"))
+	reader := &CSVReader{Delimiter: ';', Quote: '\''}
+
+	var values []string
+	err := reader.ScanColumn(context.Background(), path, "id", func(value string) {
+		values = append(values, value)
+	})
+	if err != nil {
+		t.Fatalf("ScanColumn failed: %v", err)
+	}
+	if strings.Join(values, "|") != "a;b|2" {
+		t.Errorf("Expected [a;b 2], got %q", values)
+	}
+}
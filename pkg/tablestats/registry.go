@@ -0,0 +1,184 @@
+package tablestats
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ReaderOptions carries the parsing options a ReaderFactory may honor
+type ReaderOptions struct {
+	Delimiter  rune   // Field delimiter; 0 when neither given nor detected
+	Encoding   string // utf-8 (default), utf-16le, latin1 or windows-1252
+	Quote      rune   // Quote character (default '"')
+	LazyQuotes bool
+	Escape     EscapeStyle // How quotes inside quoted fields are escaped
+	Comment    rune
+	NoHeader   bool // The first record holds values rather than column names
+	// OnParseError and MaxParseErrors select what delimited readers do with
+	// malformed records, see CSVReader
+	OnParseError   ErrorPolicy
+	MaxParseErrors int64
+}
+
+// ReaderFactory creates a TableReader configured with the given options
+type ReaderFactory func(opts ReaderOptions) TableReader
+
+// ContentSniffer reports whether the leading bytes of a file belong to its format
+type ContentSniffer func(head []byte) bool
+
+type sniffer struct {
+	match   ContentSniffer
+	factory ReaderFactory
+}
+
+var registry = struct {
+	sync.RWMutex
+	byExt    map[string]ReaderFactory
+	sniffers []sniffer
+}{byExt: make(map[string]ReaderFactory)}
+
+func init() {
+	RegisterReader(".csv", func(opts ReaderOptions) TableReader {
+		return newDelimitedReader(opts, ',')
+	})
+	RegisterReader(".tsv", func(opts ReaderOptions) TableReader {
+		return newDelimitedReader(opts, '\t')
+	})
+	RegisterReader(".parquet", func(ReaderOptions) TableReader {
+		return NewParquetReader()
+	})
+	RegisterSniffer(func(head []byte) bool {
+		return bytes.HasPrefix(head, []byte("PAR1"))
+	}, func(ReaderOptions) TableReader {
+		return NewParquetReader()
+	})
+}
+
+// RegisterReader makes factory the reader for files with the given extension,
+// e.g. ".csv". A later registration for the same extension replaces the earlier one.
+func RegisterReader(ext string, factory ReaderFactory) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.byExt[normalizeExt(ext)] = factory
+}
+
+// RegisterSniffer adds a content-based detector. Sniffers run before extension
+// lookup, in registration order, and the first match wins.
+func RegisterSniffer(match ContentSniffer, factory ReaderFactory) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.sniffers = append(registry.sniffers, sniffer{match: match, factory: factory})
+}
+
+// NewReaderFor picks a TableReader for the file. Content sniffers are tried
+// first unless a delimiter is given, then the reader registered for the file
+// extension. Files with an unknown extension are read as delimited text when a
+// delimiter is given or can be detected. Without a delimiter, the rest of the
+// dialect is detected along with it, see applyDialect.
+func NewReaderFor(filePath string, opts ReaderOptions) (TableReader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot access file: %v", err)
+	}
+	head := make([]byte, sniffSize)
+	n, err := io.ReadFull(file, head)
+	file.Close()
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return NewReaderForContent(filePath, head[:n], opts)
+}
+
+// NewReaderForContent is NewReaderFor for data that is not a file on disk,
+// e.g. an upload held in memory: name supplies the extension and head holds the
+// leading bytes of the data (the whole data or at least its first 16KB).
+func NewReaderForContent(name string, head []byte, opts ReaderOptions) (TableReader, error) {
+	if len(head) == 0 {
+		return nil, ErrEmptyFile
+	}
+	if len(head) > sniffSize {
+		head = head[:sniffSize]
+	}
+
+	registry.RLock()
+	sniffers := registry.sniffers
+	factory := registry.byExt[normalizeExt(filepath.Ext(name))]
+	registry.RUnlock()
+
+	if opts.Delimiter == 0 {
+		for _, s := range sniffers {
+			if s.match(head) {
+				return s.factory(opts), nil
+			}
+		}
+
+		enc, err := lookupEncoding(opts.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		if d, err := SniffDialect(enc.decode(bytes.NewReader(head))); err == nil {
+			opts.applyDialect(d)
+		}
+	}
+
+	if factory != nil {
+		return factory(opts), nil
+	}
+	if opts.Delimiter == 0 {
+		return nil, fmt.Errorf("cannot auto-detect delimiter for %s, use --delimiter: %w", name, ErrUnsupportedFormat)
+	}
+	return newDelimitedReader(opts, opts.Delimiter), nil
+}
+
+// applyDialect fills in the options a sniffed dialect detects. The quote
+// character is only taken when none is given; lazy quotes, backslash escapes
+// and a missing header can only be switched on.
+func (o *ReaderOptions) applyDialect(d *Dialect) {
+	o.Delimiter = d.Delimiter
+	if o.Quote == 0 {
+		o.Quote = d.Quote
+	}
+	o.LazyQuotes = o.LazyQuotes || d.LazyQuotes
+	if d.Escape == EscapeBackslash {
+		o.Escape = EscapeBackslash
+	}
+	o.NoHeader = o.NoHeader || !d.HasHeader
+}
+
+// newDelimitedReader creates a CSV or TSV reader, using fallback when no
+// delimiter was given or detected
+func newDelimitedReader(opts ReaderOptions, fallback rune) TableReader {
+	delim := opts.Delimiter
+	if delim == 0 {
+		delim = fallback
+	}
+
+	reader := &CSVReader{
+		Delimiter:      delim,
+		Encoding:       opts.Encoding,
+		Quote:          opts.Quote,
+		LazyQuotes:     opts.LazyQuotes,
+		Escape:         opts.Escape,
+		Comment:        opts.Comment,
+		NoHeader:       opts.NoHeader,
+		OnParseError:   opts.OnParseError,
+		MaxParseErrors: opts.MaxParseErrors,
+	}
+	if delim == '\t' {
+		return &TSVReader{CSVReader: reader}
+	}
+	return reader
+}
+
+func normalizeExt(ext string) string {
+	ext = strings.ToLower(ext)
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
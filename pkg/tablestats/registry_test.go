@@ -0,0 +1,122 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubReader struct{ opts ReaderOptions }
+
+func (r *stubReader) ReadTable(ctx context.Context, filePath string, config SamplingConfig) (*TableStats, error) {
+	return &TableStats{}, nil
+}
+
+func (r *stubReader) GetFormatName() string {
+	return "Stub"
+}
+
+func TestNewReaderFor(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		content   string
+		opts      ReaderOptions
+		format    string
+		delimiter rune
+	}{
+		{"csv extension", "data.csv", "a,b\n1,2\n", ReaderOptions{}, "CSV", ','},
+		{"sniffed over extension", "data.csv", "a;b\n1;2\n", ReaderOptions{}, "CSV", ';'},
+		{"tsv extension", "data.tsv", "single\nvalue\n", ReaderOptions{}, "TSV", '\t'},
+		{"unknown extension sniffed", "data.txt", "a|b\n1|2\n", ReaderOptions{}, "CSV", '|'},
+		{"explicit delimiter", "data.dat", "a b\n", ReaderOptions{Delimiter: '\t'}, "TSV", '\t'},
+		{"parquet magic", "data.bin", "PAR1\x00\x01", ReaderOptions{}, "Parquet", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeRawFile(t, tt.file, []byte(tt.content))
+			reader, err := NewReaderFor(path, tt.opts)
+			if err != nil {
+				t.Fatalf("NewReaderFor failed: %v", err)
+			}
+			if reader.GetFormatName() != tt.format {
+				t.Errorf("Expected format %s, got %s", tt.format, reader.GetFormatName())
+			}
+
+			var delim rune
+			switch r := reader.(type) {
+			case *CSVReader:
+				delim = r.Delimiter
+			case *TSVReader:
+				delim = r.Delimiter
+			}
+			if delim != tt.delimiter {
+				t.Errorf("Expected delimiter %q, got %q", tt.delimiter, delim)
+			}
+		})
+	}
+
+	path := writeRawFile(t, "export.csv", []byte("1;'a; b'\n2;'c; d'\n3;'e; f'\n"))
+	reader, err := NewReaderFor(path, ReaderOptions{})
+	if err != nil {
+		t.Fatalf("NewReaderFor failed: %v", err)
+	}
+	if r := reader.(*CSVReader); r.Delimiter != ';' || r.Quote != '\'' || !r.NoHeader {
+		t.Errorf("Expected the sniffed dialect to be applied, got %+v", r)
+	}
+	reader, err = NewReaderFor(path, ReaderOptions{Delimiter: ';'})
+	if err != nil {
+		t.Fatalf("NewReaderFor failed: %v", err)
+	}
+	if r := reader.(*CSVReader); r.Quote != 0 || r.NoHeader {
+		t.Errorf("Expected no sniffing with an explicit delimiter, got %+v", r)
+	}
+
+	path = writeRawFile(t, "escaped.csv", []byte("id,note\n1,\"say \\\"hi\\\"\"\n2,\"x\"\n"))
+	reader, err = NewReaderFor(path, ReaderOptions{})
+	if err != nil {
+		t.Fatalf("NewReaderFor failed: %v", err)
+	}
+	if r := reader.(*CSVReader); r.Escape != EscapeBackslash || r.LazyQuotes {
+		t.Errorf("Expected backslash escapes to be detected, got %+v", r)
+	}
+
+	path = writeRawFile(t, "notes.txt", []byte("just some text\n"))
+	if _, err := NewReaderFor(path, ReaderOptions{}); err == nil {
+		t.Error("Expected error for undetectable format")
+	}
+}
+
+func TestNewReaderForContent(t *testing.T) {
+	reader, err := NewReaderForContent("upload.txt", []byte("a|b\n1|2\n"), ReaderOptions{})
+	if err != nil {
+		t.Fatalf("NewReaderForContent failed: %v", err)
+	}
+	if r, ok := reader.(*CSVReader); !ok || r.Delimiter != '|' {
+		t.Errorf("Expected a CSV reader with delimiter '|', got %+v", reader)
+	}
+
+	if _, err := NewReaderForContent("upload.csv", nil, ReaderOptions{}); !errors.Is(err, ErrEmptyFile) {
+		t.Errorf("Expected ErrEmptyFile, got %v", err)
+	}
+}
+
+func TestRegisterReader(t *testing.T) {
+	RegisterReader("stub", func(opts ReaderOptions) TableReader {
+		return &stubReader{opts: opts}
+	})
+
+	path := writeRawFile(t, "data.STUB", []byte("anything"))
+	reader, err := NewReaderFor(path, ReaderOptions{Encoding: "latin1"})
+	if err != nil {
+		t.Fatalf("NewReaderFor failed: %v", err)
+	}
+	stub, ok := reader.(*stubReader)
+	if !ok {
+		t.Fatalf("Expected the registered reader, got %T", reader)
+	}
+	if stub.opts.Encoding != "latin1" {
+		t.Errorf("Expected options to be passed through, got %+v", stub.opts)
+	}
+}
@@ -0,0 +1,435 @@
+package tablestats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Renderer writes a profile in a particular output format
+type Renderer interface {
+	Render(w io.Writer, stats *TableStats) error
+}
+
+// NewRenderer returns the renderer for a format name: text, json, markdown or
+// ydata (ydata-profiling's JSON report).
+// title labels the report in formats that have a heading.
+func NewRenderer(format, title string) (Renderer, error) {
+	switch format {
+	case "", "text":
+		return &TextRenderer{Title: title}, nil
+	case "json":
+		return &JSONRenderer{Indent: "  "}, nil
+	case "markdown", "md":
+		return &MarkdownRenderer{Title: title}, nil
+	case "ydata":
+		return &YDataRenderer{Title: title}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (use text, json, markdown or ydata): %w", format, ErrUnsupportedFormat)
+	}
+}
+
+// errWriter remembers the first write error so renderers can check it once
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err == nil {
+		_, ew.err = fmt.Fprintf(ew.w, format, args...)
+	}
+}
+
+// TextRenderer writes the human readable report
+type TextRenderer struct {
+	Title        string // Shown in the report heading, e.g. the file name
+	NoSparklines bool   // Leave out the sparklines of numeric columns, for plain-ASCII terminals
+	Color        bool   // Color columns by health and warnings in yellow with ANSI escapes
+	Wide         bool   // Print every detail of each column instead of a row per column
+	Summary      bool   // Print a one-screen overview instead, with the column details only when Wide
+	Numbers      NumberFormat
+}
+
+func (r *TextRenderer) Render(w io.Writer, stats *TableStats) error {
+	ew := &errWriter{w: w}
+	nw := r.Numbers.writer()
+	ew.printf("=== %s File Statistics ===\n", r.Title)
+	if r.Summary {
+		r.renderSummary(ew, nw, stats)
+		if r.Wide {
+			r.renderDetails(ew, nw, stats)
+		}
+		ew.printf("\n")
+		return ew.err
+	}
+	ew.printf("Sampled Rows: %s\n", nw.int(stats.RowCount))
+	ew.printf("Estimated Total Rows: %s\n", nw.int(stats.EstimatedRows))
+	if m := stats.Memory; m != nil {
+		ew.printf("Estimated Memory: %s\n", nw.bytes(m.Total))
+	}
+	r.renderFileInfo(ew, nw, "", stats.FileInfo)
+	if p := stats.Provenance; p != nil {
+		ew.printf("Metrics: %s\n", p.summary())
+	}
+	ew.printf("Columns: %d\n", stats.ColumnCount)
+	ew.printf("Column Names: %v\n", stats.ColumnNames)
+	if len(stats.RenamedColumns) > 0 {
+		ew.printf("%s\n", r.paint(colorYellow, "Renamed Columns: "+renameSummary(stats.RenamedColumns)))
+	}
+	if stats.SamplingConfig.WeightColumn != "" {
+		ew.printf("Weighted By: %s\n", stats.SamplingConfig.WeightColumn)
+	}
+	if stats.RaggedRows != nil {
+		ew.printf("%s\n", r.paint(colorYellow, "Ragged Rows: "+stats.RaggedRows.summary()))
+	}
+	if p := stats.ParseErrors; p != nil {
+		ew.printf("%s\n", r.paint(colorYellow, "Malformed Records Skipped: "+nw.int(p.Count)))
+		for _, ex := range p.Examples {
+			ew.printf("  %s\n", r.paint(colorYellow, ex))
+		}
+	}
+
+	if r.Wide {
+		r.renderDetails(ew, nw, stats)
+	} else {
+		r.renderColumnTable(ew, nw, stats)
+	}
+	r.renderHeaviest(ew, nw, stats)
+	r.renderRecommendations(ew, stats)
+	if stats.Groups != nil {
+		r.renderGroups(ew, nw, stats)
+	}
+
+	if k := stats.UniqueKey; k != nil {
+		ew.printf("\nUnique Key (%s):\n", strings.Join(k.Columns, ", "))
+		ew.printf("  %s\n", k.summary())
+		for _, c := range k.Collisions {
+			ew.printf("  %v: %d rows\n", c.Values, c.Rows)
+		}
+	}
+
+	if len(stats.KeyCandidates) > 0 {
+		ew.printf("\nKey Candidates:\n")
+		for _, k := range stats.KeyCandidates {
+			ew.printf("  (%s): %s\n", strings.Join(k.Columns, ", "), k.summary())
+		}
+	}
+
+	if len(stats.SampleData) > 0 {
+		if len(stats.SampleColumns) > 0 {
+			ew.printf("\nSample Data (%s):\n", strings.Join(stats.SampleColumns, ", "))
+		} else {
+			ew.printf("\nSample Data:\n")
+		}
+		for i, row := range stats.SampleData {
+			ew.printf("  Row %d: %v\n", i+1, row)
+		}
+	}
+	ew.printf("\n")
+	return ew.err
+}
+
+// renderDetails prints every statistic of each column under its name
+func (r *TextRenderer) renderDetails(ew *errWriter, nw numberWriter, stats *TableStats) {
+	ew.printf("\nColumn Details:\n")
+	for _, colName := range stats.ColumnNames {
+		heading := r.paint(columnColor(stats, colName), colName+":")
+		if agg, ok := stats.Aggregates[colName]; ok && len(agg.Histogram) > 1 && !r.NoSparklines {
+			ew.printf("  %s %s\n", heading, histogramSparkline(agg.Histogram))
+		} else {
+			ew.printf("  %s\n", heading)
+		}
+		ew.printf("    Type: %s\n", typeLabel(stats, colName))
+		if m := stats.Memory; m != nil {
+			ew.printf("    Estimated Memory: %s\n", nw.bytes(m.Columns[colName]))
+		}
+		if comp, ok := stats.Compression[colName]; ok {
+			ew.printf("    Compression: %s\n", comp.summary(nw))
+		}
+		nulls := fmt.Sprintf("Null Count: %s (%.2f%%)", nw.int(stats.NullCounts[colName]), stats.NullPercentage[colName])
+		if stats.NullPercentage[colName] >= HighNullPercentage {
+			nulls = r.paint(colorRed, nulls)
+		}
+		ew.printf("    %s\n", nulls)
+		if stats.NullCounts[colName] > 0 && stats.EmptyCounts != nil {
+			ew.printf("      Empty: %s, NULL: %s\n", nw.int(stats.EmptyCounts[colName]), nw.int(stats.NullTokens[colName]))
+		}
+		ew.printf("    Distinct: %s%s\n", nw.int(stats.DistinctCounts[colName]), stats.Provenance.sketchNote(colName, "distinct"))
+		ew.printf("    Min: %v\n", stats.MinValues[colName])
+		ew.printf("    Max: %v\n", stats.MaxValues[colName])
+		if d, ok := stats.Decimals[colName]; ok {
+			ew.printf("    Decimal: %s (%d integer digits)\n", d.DDL(), d.IntegerDigits)
+		}
+		if flag, ok := stats.PII[colName]; ok {
+			ew.printf("    PII: %s (score %.2f)\n", flag.Kind, flag.Score)
+		}
+		for _, warning := range columnWarnings(stats, colName) {
+			ew.printf("    %s\n", r.paint(colorYellow, warning))
+		}
+		if c, ok := stats.Conformance[colName]; ok {
+			if c.Matched == c.Checked {
+				ew.printf("    %s\n", c.summary())
+			}
+			for _, ex := range c.Examples {
+				ew.printf("      %s\n", r.paint(colorYellow, fmt.Sprintf("not matching: %q", ex)))
+			}
+		}
+
+		// Print aggregates for numeric columns
+		if agg, exists := stats.Aggregates[colName]; exists {
+			ew.printf("    Aggregates:\n")
+			ew.printf("      Count: %s\n", nw.int(agg.Count))
+			ew.printf("      Sum: %s\n", nw.float(agg.Sum))
+			ew.printf("      Mean: %s\n", nw.float(agg.Mean))
+			ew.printf("      Median: %s\n", nw.float(agg.Median))
+			ew.printf("      Std Dev: %s\n", nw.float(agg.StdDev))
+			ew.printf("      Percentiles: 25th=%s, 75th=%s, 95th=%s, 99th=%s%s\n",
+				nw.float(agg.Percentiles[25]), nw.float(agg.Percentiles[75]),
+				nw.float(agg.Percentiles[95]), nw.float(agg.Percentiles[99]), stats.Provenance.sketchNote(colName, "percentiles"))
+			if len(agg.Histogram) > 1 {
+				ew.printf("      Histogram:\n")
+				for _, line := range histogramLines(agg.Histogram, nw) {
+					ew.printf("        %s\n", line)
+				}
+			}
+			if stats.EstimatedRows != stats.RowCount {
+				ew.printf("      Estimated Total: %s\n", nw.float(agg.EstimatedTotal))
+			}
+		}
+
+		for _, name := range sortedKeys(stats.CustomMetrics) {
+			if result, ok := stats.CustomMetrics[name][colName]; ok {
+				ew.printf("    %s: %v\n", name, result)
+			}
+		}
+	}
+}
+
+// JSONRenderer writes the profile as a JSON document
+type JSONRenderer struct {
+	Indent string // Indentation per level; empty writes compact JSON
+}
+
+func (r *JSONRenderer) Render(w io.Writer, stats *TableStats) error {
+	versioned := *stats
+	versioned.SchemaVersion = CurrentSchemaVersion
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", r.Indent)
+	if err := encoder.Encode(&versioned); err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+	return nil
+}
+
+// MarkdownRenderer writes the profile as Markdown tables
+type MarkdownRenderer struct {
+	Title   string // Used as the document heading when set
+	Numbers NumberFormat
+}
+
+func (r *MarkdownRenderer) Render(w io.Writer, stats *TableStats) error {
+	ew := &errWriter{w: w}
+	if r.Title != "" {
+		ew.printf("## %s\n\n", r.Title)
+	}
+	nw := r.Numbers.writer()
+	ew.printf("- Sampled rows: %s\n", nw.int(stats.RowCount))
+	ew.printf("- Estimated total rows: %s\n", nw.int(stats.EstimatedRows))
+	if m := stats.Memory; m != nil {
+		ew.printf("- Estimated memory: %s\n", nw.bytes(m.Total))
+	}
+	if f := stats.FileInfo; f != nil {
+		ew.printf("- File: %s\n", markdownCell(f.summary(nw)))
+		if warning := f.mismatch(); warning != "" {
+			ew.printf("  - Warning: %s\n", markdownCell(warning))
+		}
+	}
+	if p := stats.Provenance; p != nil {
+		ew.printf("- Metrics: %s\n", p.summary())
+		for _, line := range p.sketched(stats.ColumnNames) {
+			ew.printf("  - Estimated %s\n", markdownCell(line))
+		}
+	}
+	ew.printf("- Columns: %d\n", stats.ColumnCount)
+	if len(stats.RenamedColumns) > 0 {
+		ew.printf("- Renamed columns: %s\n", markdownCell(renameSummary(stats.RenamedColumns)))
+	}
+	if stats.RaggedRows != nil {
+		ew.printf("- Ragged rows: %s\n", stats.RaggedRows.summary())
+	}
+	if p := stats.ParseErrors; p != nil {
+		ew.printf("- Malformed records skipped: %s\n", nw.int(p.Count))
+		for _, ex := range p.Examples {
+			ew.printf("  - %s\n", markdownCell(ex))
+		}
+	}
+	ew.printf("\n")
+
+	ew.printf("| Column | Type | Nulls | Distinct | Min | Max | Mean | Median |\n")
+	ew.printf("| --- | --- | ---: | ---: | --- | --- | ---: | ---: |\n")
+	for _, colName := range stats.ColumnNames {
+		mean, median := "", ""
+		if agg, ok := stats.Aggregates[colName]; ok {
+			mean, median = nw.float(agg.Mean), nw.float(agg.Median)
+		}
+		nulls := fmt.Sprintf("%s (%.2f%%)", nw.int(stats.NullCounts[colName]), stats.NullPercentage[colName])
+		if stats.NullCounts[colName] > 0 && stats.EmptyCounts != nil {
+			nulls = fmt.Sprintf("%s (%.2f%%: %s empty, %s NULL)", nw.int(stats.NullCounts[colName]), stats.NullPercentage[colName],
+				nw.int(stats.EmptyCounts[colName]), nw.int(stats.NullTokens[colName]))
+		}
+		ew.printf("| %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownCell(colName), typeLabel(stats, colName), nulls,
+			nw.int(stats.DistinctCounts[colName]),
+			markdownValue(stats.MinValues[colName]), markdownValue(stats.MaxValues[colName]),
+			mean, median)
+	}
+
+	if k := stats.UniqueKey; k != nil {
+		ew.printf("\nUnique key (%s): %s\n", markdownCell(strings.Join(k.Columns, ", ")), k.summary())
+		for _, c := range k.Collisions {
+			ew.printf("\n- %s: %d rows", markdownCell(strings.Join(c.Values, ", ")), c.Rows)
+		}
+		if len(k.Collisions) > 0 {
+			ew.printf("\n")
+		}
+	}
+
+	if len(stats.KeyCandidates) > 0 {
+		ew.printf("\nKey candidates:\n\n")
+		for _, k := range stats.KeyCandidates {
+			ew.printf("- (%s): %s\n", markdownCell(strings.Join(k.Columns, ", ")), k.summary())
+		}
+	}
+
+	r.renderHeaviest(ew, nw, stats)
+	if stats.Groups != nil {
+		r.renderGroups(ew, nw, stats)
+	}
+
+	if len(stats.PII) > 0 {
+		ew.printf("\nPossible personal data:\n\n")
+		for _, colName := range stats.ColumnNames {
+			if flag, ok := stats.PII[colName]; ok {
+				ew.printf("- %s: %s (score %.2f)\n", markdownCell(colName), flag.Kind, flag.Score)
+			}
+		}
+	}
+
+	if len(stats.Conformance) > 0 {
+		ew.printf("\nPattern conformance:\n\n")
+		for _, colName := range stats.ColumnNames {
+			c, ok := stats.Conformance[colName]
+			if !ok {
+				continue
+			}
+			ew.printf("- %s: %.2f%% of %d values match `%s`", markdownCell(colName), c.MatchPct(), c.Checked, markdownCell(c.Pattern))
+			if len(c.Examples) > 0 {
+				ew.printf(", e.g. not %s", markdownCell(quoteJoin(c.Examples)))
+			}
+			ew.printf("\n")
+		}
+	}
+
+	if len(stats.TypeFit) > 0 {
+		ew.printf("\nValues not parseable as numbers:\n\n")
+		for _, colName := range stats.ColumnNames {
+			if f, ok := stats.TypeFit[colName]; ok {
+				ew.printf("- %s (%s): %s\n", markdownCell(colName), stats.ColumnTypes[colName], f.summary(stats.ColumnTypes[colName]))
+			}
+		}
+	}
+
+	if len(stats.Decimals) > 0 {
+		ew.printf("\nDecimal precision and scale:\n\n")
+		for _, colName := range stats.ColumnNames {
+			if d, ok := stats.Decimals[colName]; ok {
+				ew.printf("- %s: %s\n", markdownCell(colName), d.DDL())
+			}
+		}
+	}
+
+	if len(stats.CaseVariants) > 0 {
+		ew.printf("\nValues differing only in case:\n\n")
+		for _, colName := range stats.ColumnNames {
+			for _, g := range stats.CaseVariants[colName] {
+				ew.printf("- %s: %s\n", markdownCell(colName), markdownCell(g.String()))
+			}
+		}
+	}
+
+	if len(stats.Padding) > 0 {
+		ew.printf("\nValues padded with whitespace:\n\n")
+		for _, colName := range stats.ColumnNames {
+			if w, ok := stats.Padding[colName]; ok {
+				ew.printf("- %s: %d with leading, %d with trailing whitespace\n", markdownCell(colName), w.Leading, w.Trailing)
+			}
+		}
+	}
+
+	if len(stats.UnicodeIssues) > 0 {
+		ew.printf("\nValues with invisible characters or mixed Unicode forms:\n\n")
+		for _, colName := range stats.ColumnNames {
+			if u, ok := stats.UnicodeIssues[colName]; ok {
+				ew.printf("- %s: %s\n", markdownCell(colName), u.summary())
+			}
+		}
+	}
+
+	if len(stats.NonFinite) > 0 {
+		ew.printf("\nNaN and infinite values (%s):\n\n", stats.SamplingConfig.NonFinite.description())
+		for _, colName := range stats.ColumnNames {
+			if n, ok := stats.NonFinite[colName]; ok {
+				ew.printf("- %s: %d NaN, %d +Inf, %d -Inf\n", markdownCell(colName), n.NaN, n.PosInf, n.NegInf)
+			}
+		}
+	}
+
+	if len(stats.Anomalies) > 0 {
+		ew.printf("\nPossible anomalies:\n\n")
+		for _, a := range stats.Anomalies {
+			ew.printf("- %s: %s, %s\n", markdownCell(a.Column), a.Kind, a.Detail)
+		}
+	}
+
+	if len(stats.Recommendations) > 0 {
+		ew.printf("\nStorage recommendations:\n\n")
+		for _, rec := range stats.Recommendations {
+			ew.printf("- %s\n", markdownCell(rec.Detail))
+		}
+	}
+
+	if len(stats.SampleData) > 0 {
+		names := stats.sampleColumnNames()
+		ew.printf("\n| %s |\n", strings.Join(markdownCells(names), " | "))
+		ew.printf("|%s\n", strings.Repeat(" --- |", len(names)))
+		for _, row := range stats.SampleData {
+			ew.printf("| %s |\n", strings.Join(markdownCells(row), " | "))
+		}
+	}
+	ew.printf("\n")
+	return ew.err
+}
+
+func markdownValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return markdownCell(fmt.Sprint(v))
+}
+
+// markdownCell escapes characters that would break a table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func markdownCells(values []string) []string {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		cells[i] = markdownCell(v)
+	}
+	return cells
+}
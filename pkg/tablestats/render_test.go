@@ -0,0 +1,142 @@
+package tablestats
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func renderTestStats() *TableStats {
+	sample := withRecords(&Sample{
+		Header:        []string{"id", "label"},
+		EstimatedRows: 3,
+		Exact:         true,
+	}, [][]string{{"1", "a|b"}, {"2", ""}, {"3", "c"}})
+	return AnalyzeSample(sample, SamplingConfig{})
+}
+
+func TestTextRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&TextRenderer{Title: "CSV", Wide: true}).Render(&buf, renderTestStats()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	output := buf.String()
+	expected := []string{
+		"=== CSV File Statistics ===",
+		"Sampled Rows: 3",
+		"  label:",
+		"    Null Count: 1 (33.33%)",
+		"      Empty: 1, NULL: 0",
+		"      Mean: 2.00",
+		"  Row 1: [1 a|b]",
+	}
+	for _, s := range expected {
+		if !strings.Contains(output, s) {
+			t.Errorf("Expected output to contain %q, got:\n%s", s, output)
+		}
+	}
+}
+
+func TestTextRenderer_Table(t *testing.T) {
+	stats := renderTestStats()
+	stats.Padding = map[string]*PaddingCounts{"label": {Leading: 1}}
+
+	var buf bytes.Buffer
+	if err := (&TextRenderer{Title: "CSV"}).Render(&buf, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	output := buf.String()
+	expected := []string{
+		"  Column  Type    Nulls       Min  Max  Mean  P95   Distinct  Shape\n",
+		"  id      int64   0 (0.00%)   1    3    2.00  2.90  3         █▁▁▁▁█▁▁▁█\n",
+		"  label   string  1 (33.33%)  a|b  c                2\n",
+		"Warnings:\n  label: Padded: 1 with leading, 0 with trailing whitespace\n",
+	}
+	for _, s := range expected {
+		if !strings.Contains(output, s) {
+			t.Errorf("Expected output to contain %q, got:\n%s", s, output)
+		}
+	}
+	if strings.Contains(output, "Aggregates:") {
+		t.Errorf("Expected no column details without Wide, got:\n%s", output)
+	}
+}
+
+func TestCell(t *testing.T) {
+	if got := cell("a\nb"); got != "a b" {
+		t.Errorf("Expected newlines replaced, got %q", got)
+	}
+	long := strings.Repeat("é", 30)
+	if got := cell(long); got != strings.Repeat("é", maxCellWidth-3)+"..." {
+		t.Errorf("Expected %d characters, got %q", maxCellWidth, got)
+	}
+}
+
+func TestJSONRenderer(t *testing.T) {
+	stats := renderTestStats()
+	stats.SamplingConfig.Progress = func(int64, int64, int64) {}
+
+	var buf bytes.Buffer
+	if err := (&JSONRenderer{}).Render(&buf, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var decoded TableStats
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+	if decoded.RowCount != 3 || decoded.ColumnTypes["id"] != "int64" {
+		t.Errorf("Expected 3 rows and int64 id, got %d rows and %q", decoded.RowCount, decoded.ColumnTypes["id"])
+	}
+	if !floatEqual(decoded.Aggregates["id"].Percentiles[50], 2) {
+		t.Errorf("Expected median 2, got %f", decoded.Aggregates["id"].Percentiles[50])
+	}
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&MarkdownRenderer{Title: "data.csv"}).Render(&buf, renderTestStats()); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	output := buf.String()
+	expected := []string{
+		"## data.csv",
+		"| id | int64 | 0 (0.00%) | 3 | 1 | 3 | 2.00 | 2.00 |",
+		"| label | string | 1 (33.33%: 1 empty, 0 NULL) | 2 | a\\|b | c |  |  |",
+		"| 1 | a\\|b |",
+	}
+	for _, s := range expected {
+		if !strings.Contains(output, s) {
+			t.Errorf("Expected output to contain %q, got:\n%s", s, output)
+		}
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	for _, format := range []string{"", "text", "json", "markdown", "md", "ydata"} {
+		if _, err := NewRenderer(format, ""); err != nil {
+			t.Errorf("Expected renderer for %q, got %v", format, err)
+		}
+	}
+	if _, err := NewRenderer("xml", ""); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRenderer_WriteError(t *testing.T) {
+	for _, r := range []Renderer{&TextRenderer{}, &JSONRenderer{}, &MarkdownRenderer{}, &YDataRenderer{}} {
+		if err := r.Render(failingWriter{}, renderTestStats()); err == nil {
+			t.Errorf("Expected write error from %T", r)
+		}
+	}
+}
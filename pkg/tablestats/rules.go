@@ -0,0 +1,180 @@
+package tablestats
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules is a set of quality rules evaluated by Check
+type Rules struct {
+	Columns []ColumnRules `yaml:"columns"`
+}
+
+// ColumnRules declares the rules one column must satisfy. Every value rule
+// skips null values; use NotNull or MaxNullPct to constrain those.
+type ColumnRules struct {
+	Column     string    `yaml:"column"`
+	NotNull    bool      `yaml:"not_null,omitempty"`
+	Unique     bool      `yaml:"unique,omitempty"` // Within the checked rows
+	InSet      []string  `yaml:"in_set,omitempty"`
+	Between    []float64 `yaml:"between,omitempty"` // Inclusive [min, max]
+	Matches    string    `yaml:"matches,omitempty"` // Regular expression every value must match
+	MaxNullPct *float64  `yaml:"max_null_pct,omitempty"`
+
+	matches *regexp.Regexp
+}
+
+// LoadRules reads and compiles a YAML rules file
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules: %w", err)
+	}
+
+	rules := &Rules{}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules: %w", err)
+	}
+	if err := rules.compile(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *Rules) compile() error {
+	for i := range r.Columns {
+		col := &r.Columns[i]
+		if col.Column == "" {
+			return fmt.Errorf("rule %d has no column", i+1)
+		}
+		if col.Between != nil && (len(col.Between) != 2 || col.Between[0] > col.Between[1]) {
+			return fmt.Errorf("column %q: between needs [min, max]", col.Column)
+		}
+		if col.MaxNullPct != nil && (*col.MaxNullPct < 0 || *col.MaxNullPct > 100) {
+			return fmt.Errorf("column %q: max_null_pct must be between 0 and 100", col.Column)
+		}
+		if col.Matches != "" {
+			re, err := regexp.Compile(col.Matches)
+			if err != nil {
+				return fmt.Errorf("column %q: invalid matches pattern: %w", col.Column, err)
+			}
+			col.matches = re
+		}
+	}
+	return nil
+}
+
+// Check evaluates the rules against every row of the sample. Violations use
+// the rule names of the YAML file; a column the rules name but the header
+// lacks is reported as missing.
+func Check(sample *Sample, rules *Rules) *ValidationReport {
+	report := &ValidationReport{
+		CheckedRows: int64(sample.Len()),
+		Exact:       sample.Exact,
+	}
+
+	positions := make(map[string]int, len(sample.Header))
+	for i, name := range sample.Header {
+		positions[name] = i
+	}
+
+	for i := range rules.Columns {
+		col := &rules.Columns[i]
+		colIdx, ok := positions[col.Column]
+		if !ok {
+			report.Violations = append(report.Violations, &Violation{
+				Column: col.Column,
+				Rule:   "missing",
+				Count:  1,
+			})
+			continue
+		}
+		report.checkColumn(sample.Columns[colIdx], col)
+	}
+
+	return report
+}
+
+func (r *ValidationReport) checkColumn(column *array.LargeString, col *ColumnRules) {
+	violations := make(map[string]*Violation)
+	var order []string
+	record := func(rule string, row int, value string) {
+		v, ok := violations[rule]
+		if !ok {
+			v = &Violation{Column: col.Column, Rule: rule}
+			violations[rule] = v
+			order = append(order, rule)
+		}
+		v.Count++
+		if len(v.Examples) < maxViolationExamples {
+			v.Examples = append(v.Examples, ViolationExample{Row: int64(row + 1), Value: value})
+		}
+	}
+
+	var inSet map[string]bool
+	if len(col.InSet) > 0 {
+		inSet = make(map[string]bool, len(col.InSet))
+		for _, v := range col.InSet {
+			inSet[v] = true
+		}
+	}
+	var seen map[string]bool
+	if col.Unique {
+		seen = make(map[string]bool)
+	}
+
+	var nulls []int
+	for row := 0; row < column.Len(); row++ {
+		r.CheckedValues++
+
+		value := strings.TrimSpace(column.Value(row))
+		if isNullValue(value) {
+			if col.MaxNullPct != nil {
+				nulls = append(nulls, row)
+			}
+			if col.NotNull {
+				record("not_null", row, value)
+			}
+			continue
+		}
+
+		if seen != nil {
+			if seen[value] {
+				record("unique", row, value)
+			} else {
+				seen[value] = true
+			}
+		}
+		if inSet != nil && !inSet[value] {
+			record("in_set", row, value)
+		}
+		if col.Between != nil {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil || number < col.Between[0] || number > col.Between[1] {
+				record("between", row, value)
+			}
+		}
+		if col.matches != nil && !col.matches.MatchString(value) {
+			record("matches", row, value)
+		}
+	}
+
+	// The null share is a column-level rule: every null counts once it is exceeded
+	if col.MaxNullPct != nil && column.Len() > 0 {
+		if pct := float64(len(nulls)) / float64(column.Len()) * 100; pct > *col.MaxNullPct {
+			for _, row := range nulls {
+				record("max_null_pct", row, column.Value(row))
+			}
+		}
+	}
+
+	for _, rule := range order {
+		r.Violations = append(r.Violations, violations[rule])
+	}
+}
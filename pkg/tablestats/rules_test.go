@@ -0,0 +1,128 @@
+package tablestats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules(writeSchema(t, `
+columns:
+  - column: id
+    not_null: true
+    unique: true
+  - column: age
+    between: [0, 120]
+  - column: notes
+    max_null_pct: 10
+`))
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+	if len(rules.Columns) != 3 {
+		t.Fatalf("Expected 3 rule sets, got %d", len(rules.Columns))
+	}
+	if !rules.Columns[0].NotNull || !rules.Columns[0].Unique {
+		t.Error("Expected id to be not_null and unique")
+	}
+	if *rules.Columns[2].MaxNullPct != 10 {
+		t.Errorf("Expected max_null_pct 10, got %f", *rules.Columns[2].MaxNullPct)
+	}
+
+	invalid := []string{
+		"columns:\n  - not_null: true\n",
+		"columns:\n  - column: a\n    between: [5]\n",
+		"columns:\n  - column: a\n    between: [5, 1]\n",
+		"columns:\n  - column: a\n    max_null_pct: 150\n",
+		"columns:\n  - column: a\n    matches: '('\n",
+	}
+	for _, content := range invalid {
+		if _, err := LoadRules(writeSchema(t, content)); err == nil {
+			t.Errorf("Expected error for rules %q", content)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	rules, err := LoadRules(writeSchema(t, `
+columns:
+  - column: id
+    not_null: true
+    unique: true
+  - column: age
+    between: [0, 120]
+  - column: sku
+    matches: '^SKU-\d{3}$'
+  - column: status
+    in_set: [active, inactive]
+  - column: notes
+    max_null_pct: 25
+  - column: email
+`))
+	if err != nil {
+		t.Fatalf("LoadRules failed: %v", err)
+	}
+
+	sample := withRecords(&Sample{
+		Header: []string{"id", "age", "sku", "status", "notes"},
+		Exact:  true,
+	}, [][]string{
+		{"1", "30", "SKU-001", "active", "ok"},
+		{"", "-1", "SKU-002", "inactive", ""},
+		{"1", "150", "sku-3", "deleted", "NULL"},
+		{"4", "abc", "SKU-004", "", "fine"},
+	})
+
+	report := Check(sample, rules)
+
+	counts := make(map[string]int64)
+	for _, v := range report.Violations {
+		counts[v.Column+"."+v.Rule] = v.Count
+	}
+	expected := map[string]int64{
+		"id.not_null":        1,
+		"id.unique":          1,
+		"age.between":        3,
+		"sku.matches":        1,
+		"status.in_set":      1,
+		"notes.max_null_pct": 2,
+		"email.missing":      1,
+	}
+	for key, want := range expected {
+		if counts[key] != want {
+			t.Errorf("Expected %d violations for %s, got %d", want, key, counts[key])
+		}
+	}
+	if len(counts) != len(expected) {
+		t.Errorf("Expected %d violation groups, got %v", len(expected), counts)
+	}
+
+	for _, v := range report.Violations {
+		if v.Rule == "unique" && (len(v.Examples) != 1 || v.Examples[0].Row != 3 || v.Examples[0].Value != "1") {
+			t.Errorf("Unexpected unique examples %v", v.Examples)
+		}
+	}
+	if !report.Failed(SchemaThresholds{}) {
+		t.Error("Expected violations to fail the check")
+	}
+
+	failures := strings.Join(report.Failures(), "\n")
+	for _, want := range []string{"age: between violated by 3 values", "email: required column is missing"} {
+		if !strings.Contains(failures, want) {
+			t.Errorf("Expected failures to contain %q, got:\n%s", want, failures)
+		}
+	}
+}
+
+func TestCheck_Passes(t *testing.T) {
+	pct := 50.0
+	rules := &Rules{Columns: []ColumnRules{{Column: "notes", MaxNullPct: &pct}}}
+	sample := withRecords(&Sample{
+		Header: []string{"notes"},
+	}, [][]string{{"a"}, {""}})
+
+	report := Check(sample, rules)
+	if len(report.Violations) != 0 || report.Failed(SchemaThresholds{}) {
+		t.Errorf("Expected no violations at the null limit, got %v", report.Violations)
+	}
+}
@@ -0,0 +1,218 @@
+package tablestats
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// NewSample returns a sample of the given rows of header fields. Fields
+// missing from short rows are null, and fields beyond the header are dropped.
+func NewSample(header []string, records [][]string) *Sample {
+	return &Sample{Header: header, Columns: buildColumns(len(header), records)}
+}
+
+// Len returns the number of sampled rows
+func (s *Sample) Len() int {
+	if len(s.Columns) == 0 {
+		return 0
+	}
+	return s.Columns[0].Len()
+}
+
+// Record returns the fields of row i, with "" for fields missing from it
+func (s *Sample) Record(i int) []string {
+	record := make([]string, len(s.Columns))
+	for j, column := range s.Columns {
+		record[j] = column.Value(i)
+	}
+	return record
+}
+
+// Records returns every sampled row, for callers that work by row rather
+// than by column
+func (s *Sample) Records() [][]string {
+	records := make([][]string, s.Len())
+	for i := range records {
+		records[i] = s.Record(i)
+	}
+	return records
+}
+
+// Shrink reduces the sample to at most n rows chosen uniformly at random,
+// keeping the rows in their original order.
+func (s *Sample) Shrink(n int) {
+	if n < 0 || s.Len() <= n {
+		return
+	}
+
+	keep := rand.Perm(s.Len())[:n]
+	sort.Ints(keep)
+
+	b := newColumnBuilder(len(s.Columns))
+	var weights []float64
+	if s.Weights != nil {
+		weights = make([]float64, n)
+	}
+	for i, idx := range keep {
+		b.appendRow(s.Columns, idx)
+		if weights != nil {
+			weights[i] = s.Weights[idx]
+		}
+	}
+
+	s.Columns = b.finish()
+	s.Weights = weights
+	s.Exact = false
+}
+
+// WriteCSV writes the header and the sampled rows using the given delimiter
+func (s *Sample) WriteCSV(w io.Writer, delimiter rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	if err := writer.Write(s.Header); err != nil {
+		return err
+	}
+	record := make([]string, len(s.Columns))
+	for i := 0; i < s.Len(); i++ {
+		for j, column := range s.Columns {
+			record[j] = column.Value(i)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// MergeSamples combines samples of several files sharing the same header into
+// one logical table. When any part was sampled, every row is weighted by the
+// number of table rows it stands for, so large parts are not under-represented.
+func MergeSamples(samples []*Sample) (*Sample, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples to merge")
+	}
+
+	merged := &Sample{Header: samples[0].Header, Renamed: samples[0].Renamed, Exact: true}
+	ragged := make([]*RaggedRows, len(samples))
+	files := make([]*FileInfo, len(samples))
+	rows := newColumnBuilder(len(merged.Header))
+	for i, s := range samples {
+		if len(s.Header) != len(merged.Header) {
+			return nil, fmt.Errorf("part %d has %d columns, expected %d", i+1, len(s.Header), len(merged.Header))
+		}
+		for j := range s.Header {
+			if s.Header[j] != merged.Header[j] {
+				return nil, fmt.Errorf("part %d has column %q at position %d, expected %q", i+1, s.Header[j], j+1, merged.Header[j])
+			}
+		}
+		for row := 0; row < s.Len(); row++ {
+			rows.appendRow(s.Columns, row)
+		}
+		merged.EstimatedRows += s.EstimatedRows
+		merged.Exact = merged.Exact && s.Exact
+		ragged[i] = s.Ragged
+		files[i] = s.FileInfo
+		merged.ParseErrors = mergeParseErrors(merged.ParseErrors, s.ParseErrors)
+	}
+	merged.Columns = rows.finish()
+	merged.Ragged = mergeRaggedRows(ragged)
+	merged.FileInfo = mergeFileInfo(files)
+
+	if merged.Exact {
+		return merged, nil
+	}
+
+	merged.Weights = make([]float64, 0, merged.Len())
+	for _, s := range samples {
+		if s.Len() == 0 {
+			continue
+		}
+		if s.Weights == nil {
+			weight := float64(s.EstimatedRows) / float64(s.Len())
+			for range s.Len() {
+				merged.Weights = append(merged.Weights, weight)
+			}
+			continue
+		}
+		total := 0.0
+		for _, w := range s.Weights {
+			total += w
+		}
+		for _, w := range s.Weights {
+			merged.Weights = append(merged.Weights, w*float64(s.EstimatedRows)/total)
+		}
+	}
+
+	return merged, nil
+}
+
+// columnBuilder collects rows into the Arrow arrays of a Sample. Values are
+// copied, so the rows appended may be reused by the caller.
+type columnBuilder struct {
+	fields []*array.LargeStringBuilder
+}
+
+func newColumnBuilder(width int) *columnBuilder {
+	b := &columnBuilder{fields: make([]*array.LargeStringBuilder, width)}
+	for i := range b.fields {
+		b.fields[i] = array.NewLargeStringBuilder(memory.DefaultAllocator)
+	}
+	return b
+}
+
+// buildColumns returns the arrays of width fields holding records
+func buildColumns(width int, records [][]string) []*array.LargeString {
+	b := newColumnBuilder(width)
+	for _, record := range records {
+		b.append(record)
+	}
+	return b.finish()
+}
+
+// append adds a row; fields missing from it are null
+func (b *columnBuilder) append(record []string) {
+	for i, field := range b.fields {
+		if i < len(record) {
+			field.Append(record[i])
+		} else {
+			field.AppendNull()
+		}
+	}
+}
+
+// appendRow adds row i of columns, which must be as many as the builder's
+func (b *columnBuilder) appendRow(columns []*array.LargeString, i int) {
+	for j, field := range b.fields {
+		if columns[j].IsNull(i) {
+			field.AppendNull()
+		} else {
+			field.Append(columns[j].Value(i))
+		}
+	}
+}
+
+// len returns the number of rows appended
+func (b *columnBuilder) len() int {
+	if len(b.fields) == 0 {
+		return 0
+	}
+	return b.fields[0].Len()
+}
+
+// finish returns the arrays of the rows appended
+func (b *columnBuilder) finish() []*array.LargeString {
+	columns := make([]*array.LargeString, len(b.fields))
+	for i, field := range b.fields {
+		columns[i] = field.NewLargeStringArray()
+		field.Release()
+	}
+	return columns
+}
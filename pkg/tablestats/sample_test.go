@@ -0,0 +1,154 @@
+package tablestats
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// withRecords stores records in the columns of sample, as the readers do
+func withRecords(sample *Sample, records [][]string) *Sample {
+	sample.Columns = buildColumns(len(sample.Header), records)
+	return sample
+}
+
+func TestNewSample(t *testing.T) {
+	sample := NewSample([]string{"id", "name"}, [][]string{{"1", "Alice"}, {"2"}, {"3", "Carol", "extra"}})
+
+	if sample.Len() != 3 || len(sample.Columns) != 2 {
+		t.Fatalf("Expected 3 rows of 2 columns, got %d rows of %d", sample.Len(), len(sample.Columns))
+	}
+	expected := [][]string{{"1", "Alice"}, {"2", ""}, {"3", "Carol"}}
+	if !reflect.DeepEqual(sample.Records(), expected) {
+		t.Errorf("Expected records %q, got %q", expected, sample.Records())
+	}
+	if !sample.Columns[1].IsNull(1) {
+		t.Errorf("Expected the missing field to be null")
+	}
+	if empty := NewSample([]string{"id"}, nil); empty.Len() != 0 || len(empty.Records()) != 0 {
+		t.Errorf("Expected an empty sample, got %d rows", empty.Len())
+	}
+}
+
+func TestSampleShrink(t *testing.T) {
+	sample := &Sample{Header: []string{"id"}, Exact: true}
+	var records [][]string
+	for i := 0; i < 100; i++ {
+		records = append(records, []string{strconv.Itoa(i)})
+	}
+	withRecords(sample, records)
+
+	sample.Shrink(10)
+
+	if sample.Len() != 10 {
+		t.Fatalf("Expected 10 records, got %d", sample.Len())
+	}
+	if sample.Exact {
+		t.Error("Expected shrunk sample to be marked as not exact")
+	}
+
+	// Rows must keep their original order
+	prev := -1
+	for _, record := range sample.Records() {
+		id, _ := strconv.Atoi(record[0])
+		if id <= prev {
+			t.Errorf("Expected ascending ids, got %d after %d", id, prev)
+		}
+		prev = id
+	}
+
+	sample.Shrink(20)
+	if sample.Len() != 10 {
+		t.Errorf("Expected shrinking to a larger size to be a no-op, got %d records", sample.Len())
+	}
+}
+
+func TestSampleWriteCSV(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header: []string{"id", "name"},
+	}, [][]string{{"1", "Alice"}, {"2", "Bob, Jr."}})
+
+	var buf bytes.Buffer
+	if err := sample.WriteCSV(&buf, ','); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+
+	expected := "id,name\n1,Alice\n2,\"Bob, Jr.\"\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := sample.WriteCSV(&buf, '\t'); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	expected = "id\tname\n1\tAlice\n2\tBob, Jr.\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestMergeSamples(t *testing.T) {
+	exact := withRecords(&Sample{
+		Header:        []string{"id", "value"},
+		EstimatedRows: 2,
+		Exact:         true,
+	}, [][]string{{"1", "10"}, {"2", "20"}})
+	sampled := withRecords(&Sample{
+		Header:        []string{"id", "value"},
+		EstimatedRows: 100,
+	}, [][]string{{"3", "30"}, {"4", "40"}})
+
+	merged, err := MergeSamples([]*Sample{exact, exact})
+	if err != nil {
+		t.Fatalf("MergeSamples failed: %v", err)
+	}
+	if merged.Len() != 4 || merged.EstimatedRows != 4 || !merged.Exact {
+		t.Errorf("Unexpected exact merge: %d records, %d estimated, exact=%v",
+			merged.Len(), merged.EstimatedRows, merged.Exact)
+	}
+	if merged.Weights != nil {
+		t.Error("Expected no weights when every part is exact")
+	}
+
+	merged, err = MergeSamples([]*Sample{exact, sampled})
+	if err != nil {
+		t.Fatalf("MergeSamples failed: %v", err)
+	}
+	if merged.Exact || merged.EstimatedRows != 102 {
+		t.Errorf("Expected inexact merge of 102 rows, got exact=%v rows=%d", merged.Exact, merged.EstimatedRows)
+	}
+	expectedWeights := []float64{1, 1, 50, 50}
+	for i, w := range expectedWeights {
+		if !floatEqual(merged.Weights[i], w) {
+			t.Errorf("Weight %d = %f, want %f", i, merged.Weights[i], w)
+		}
+	}
+
+	stats := AnalyzeSample(merged, SamplingConfig{})
+	if !floatEqual(stats.Aggregates["value"].Mean, (10+20+50*30+50*40)/102.0) {
+		t.Errorf("Expected weighted mean, got %f", stats.Aggregates["value"].Mean)
+	}
+
+	if stats.RaggedRows != nil {
+		t.Errorf("Expected no ragged rows for unchecked parts, got %+v", stats.RaggedRows)
+	}
+
+	// Ragged rows are summed, without the line numbers of each part
+	exact.Ragged = &RaggedRows{Rows: 2, Short: 1, ShortLines: []int{3}}
+	sampled.Ragged = &RaggedRows{Rows: 2}
+	merged, err = MergeSamples([]*Sample{exact, sampled})
+	if err != nil {
+		t.Fatalf("MergeSamples failed: %v", err)
+	}
+	expected := &RaggedRows{Rows: 4, Short: 1, Percentage: 25}
+	if !reflect.DeepEqual(merged.Ragged, expected) {
+		t.Errorf("Expected ragged rows %+v, got %+v", expected, merged.Ragged)
+	}
+
+	other := &Sample{Header: []string{"id", "amount"}}
+	if _, err := MergeSamples([]*Sample{exact, other}); err == nil {
+		t.Error("Expected error for mismatched headers")
+	}
+}
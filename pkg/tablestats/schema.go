@@ -0,0 +1,125 @@
+package tablestats
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema describes the expected shape of a table
+type Schema struct {
+	Columns    []ColumnSchema   `yaml:"columns"`
+	Thresholds SchemaThresholds `yaml:"thresholds,omitempty"`
+}
+
+// ColumnSchema describes the expectations for a single column
+type ColumnSchema struct {
+	Name     string   `yaml:"name"`
+	Type     string   `yaml:"type,omitempty"`     // int64, float64 or string; empty accepts anything
+	Optional bool     `yaml:"optional,omitempty"` // Column may be absent from the header
+	Nullable *bool    `yaml:"nullable,omitempty"` // Defaults to true
+	Min      *float64 `yaml:"min,omitempty"`
+	Max      *float64 `yaml:"max,omitempty"`
+	Pattern  string   `yaml:"pattern,omitempty"` // Regular expression every value must match
+	Allowed  []string `yaml:"allowed,omitempty"` // Exhaustive list of permitted values
+
+	pattern *regexp.Regexp
+}
+
+// SchemaThresholds controls how many violations a table may have and still pass.
+// When MaxViolationPct is set it takes precedence over MaxViolations.
+type SchemaThresholds struct {
+	MaxViolations   int64   `yaml:"max_violations,omitempty"`
+	MaxViolationPct float64 `yaml:"max_violation_pct,omitempty"`
+}
+
+// LoadSchema reads and compiles a YAML schema file
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	schema := &Schema{}
+	if err := yaml.Unmarshal(data, schema); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if err := schema.compile(); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (s *Schema) compile() error {
+	for i := range s.Columns {
+		col := &s.Columns[i]
+		if col.Name == "" {
+			return fmt.Errorf("schema column %d has no name", i+1)
+		}
+		switch col.Type {
+		case "", "int64", "float64", "string":
+		default:
+			return fmt.Errorf("column %q: unsupported type %q", col.Name, col.Type)
+		}
+		if col.Pattern != "" {
+			re, err := regexp.Compile(col.Pattern)
+			if err != nil {
+				return fmt.Errorf("column %q: invalid pattern: %w", col.Name, err)
+			}
+			col.pattern = re
+		}
+	}
+	return nil
+}
+
+// IsNullable reports whether null values are permitted in the column
+func (c *ColumnSchema) IsNullable() bool {
+	return c.Nullable == nil || *c.Nullable
+}
+
+// InferSchema derives column names, types and nullability from sampled rows.
+// Columns without any non-null value are left untyped.
+func InferSchema(sample *Sample) *Schema {
+	schema := &Schema{Columns: make([]ColumnSchema, len(sample.Header))}
+
+	for colIdx, name := range sample.Header {
+		nullable := false
+		seen, isNumeric, isFloat := false, true, false
+
+		column := sample.Columns[colIdx]
+		for row := 0; row < column.Len(); row++ {
+			value := strings.TrimSpace(column.Value(row))
+			if isNullValue(value) {
+				nullable = true
+				continue
+			}
+
+			seen = true
+			if isNumeric {
+				switch kind, _ := classifyNumber(value); kind {
+				case notNumber:
+					isNumeric = false
+				case floatNumber:
+					isFloat = true
+				}
+			}
+		}
+
+		col := ColumnSchema{Name: name, Nullable: &nullable}
+		switch {
+		case !seen:
+		case !isNumeric:
+			col.Type = "string"
+		case isFloat:
+			col.Type = "float64"
+		default:
+			col.Type = "int64"
+		}
+		schema.Columns[colIdx] = col
+	}
+
+	return schema
+}
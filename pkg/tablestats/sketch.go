@@ -0,0 +1,195 @@
+package tablestats
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// SketchSampleSize is the SampleSize from which columns are aggregated with
+// bounded-memory sketches instead of exact structures: percentiles come from a
+// t-digest and distinct counts from a HyperLogLog estimate.
+const SketchSampleSize = 1_000_000
+
+// tDigestCompression bounds the number of centroids a t-digest keeps (at most
+// about this many); larger values trade memory for accuracy
+const tDigestCompression = 200
+
+// centroid is a cluster of nearby values in a t-digest
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+// tDigest estimates quantiles of a weighted stream in bounded memory
+// (Dunning's merging t-digest). Accuracy is highest near the tails.
+type tDigest struct {
+	centroids []centroid // Merged clusters sorted by mean
+	buffer    []centroid // Values added since the last merge
+	scratch   []centroid
+	total     float64 // Weight of the merged clusters
+	min, max  float64
+}
+
+func newTDigest() *tDigest {
+	return &tDigest{
+		buffer: make([]centroid, 0, 5*tDigestCompression),
+		min:    math.Inf(1),
+		max:    math.Inf(-1),
+	}
+}
+
+func (d *tDigest) add(v, weight float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	d.min = math.Min(d.min, v)
+	d.max = math.Max(d.max, v)
+	d.buffer = append(d.buffer, centroid{mean: v, weight: weight})
+	if len(d.buffer) == cap(d.buffer) {
+		d.merge()
+	}
+}
+
+// merge folds the buffered values into the centroids. Neighbouring clusters are
+// combined while they span at most one unit of the arcsine scale function,
+// which keeps clusters small at the tails and bounds their number.
+func (d *tDigest) merge() {
+	if len(d.buffer) == 0 {
+		return
+	}
+	all := append(d.scratch[:0], d.centroids...)
+	all = append(all, d.buffer...)
+	d.buffer = d.buffer[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	total := 0.0
+	for _, c := range all {
+		total += c.weight
+	}
+
+	merged := d.centroids[:0]
+	cur := all[0]
+	soFar := 0.0
+	for _, c := range all[1:] {
+		q0 := soFar / total
+		q2 := (soFar + cur.weight + c.weight) / total
+		if tDigestScale(q2)-tDigestScale(q0) <= 1 {
+			cur.weight += c.weight
+			cur.mean += (c.mean - cur.mean) * c.weight / cur.weight
+			continue
+		}
+		merged = append(merged, cur)
+		soFar += cur.weight
+		cur = c
+	}
+	d.centroids = append(merged, cur)
+	d.scratch = all
+	d.total = total
+}
+
+// tDigestScale is the k1 scale function of the t-digest
+func tDigestScale(q float64) float64 {
+	return tDigestCompression / (2 * math.Pi) * math.Asin(2*q-1)
+}
+
+// quantile returns the estimated value at q (0-1), interpolating between the
+// centres of neighbouring clusters
+func (d *tDigest) quantile(q float64) float64 {
+	d.merge()
+	if len(d.centroids) == 0 {
+		return 0
+	}
+	if len(d.centroids) == 1 {
+		return d.centroids[0].mean
+	}
+
+	target := q * d.total
+	cum := 0.0
+	prevCentre, prevMean := 0.0, d.min
+	for _, c := range d.centroids {
+		centre := cum + c.weight/2
+		if target < centre {
+			return lerp(prevMean, c.mean, (target-prevCentre)/(centre-prevCentre))
+		}
+		cum += c.weight
+		prevCentre, prevMean = centre, c.mean
+	}
+	if d.total == prevCentre {
+		return d.max
+	}
+	return lerp(prevMean, d.max, (target-prevCentre)/(d.total-prevCentre))
+}
+
+// histogram bins the clusters by their means, scaled to count values
+func (d *tDigest) histogram(count int64) []HistogramBin {
+	d.merge()
+	values := make([]float64, len(d.centroids))
+	weights := make([]float64, len(d.centroids))
+	for i, c := range d.centroids {
+		values[i], weights[i] = c.mean, c.weight
+	}
+	return histogram(values, weights, count)
+}
+
+func lerp(a, b, t float64) float64 {
+	return a + (b-a)*t
+}
+
+// hllPrecision is the number of hash bits that select a HyperLogLog register;
+// 2^14 registers give a standard error of about 0.8%
+const hllPrecision = 14
+
+// hyperLogLog estimates the number of distinct values added to it in a fixed
+// 16KB, regardless of how many values there are
+type hyperLogLog struct {
+	registers [1 << hllPrecision]uint8
+}
+
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{}
+}
+
+// hllHash hashes a value with FNV-1a and the finalizer of MurmurHash3. Unlike
+// a maphash seed it is the same in every process, so the registers of a
+// checkpointed accumulator stay valid when another run resumes it.
+func hllHash[T fieldValue](value T) uint64 {
+	hash := uint64(14695981039346656037)
+	for i := 0; i < len(value); i++ {
+		hash ^= uint64(value[i])
+		hash *= 1099511628211
+	}
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+	return hash
+}
+
+func addDistinct[T fieldValue](h *hyperLogLog, value T) {
+	hash := hllHash(value)
+	idx := hash >> (64 - hllPrecision)
+	// The sentinel bit caps the run of zeros for hashes with empty low bits
+	rest := hash<<hllPrecision | 1<<(hllPrecision-1)
+	if rank := uint8(bits.LeadingZeros64(rest) + 1); rank > h.registers[idx] {
+		h.registers[idx] = rank
+	}
+}
+
+func (h *hyperLogLog) estimate() int64 {
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if estimate <= 2.5*m && zeros > 0 {
+		// Linear counting is more accurate for small cardinalities
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
@@ -0,0 +1,153 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestTDigest_Quantiles(t *testing.T) {
+	n := 200000
+	digest := newTDigest()
+	for _, i := range rand.Perm(n) {
+		digest.add(float64(i), 1)
+	}
+
+	if len(digest.centroids) > tDigestCompression {
+		t.Errorf("Expected at most %d centroids, got %d", tDigestCompression, len(digest.centroids))
+	}
+
+	tests := []struct {
+		q         float64
+		tolerance float64 // Fraction of the value range
+	}{
+		{0.01, 0.001},
+		{0.25, 0.005},
+		{0.5, 0.005},
+		{0.75, 0.005},
+		{0.99, 0.001},
+	}
+	for _, tt := range tests {
+		want := tt.q * float64(n)
+		if got := digest.quantile(tt.q); math.Abs(got-want) > tt.tolerance*float64(n) {
+			t.Errorf("Expected quantile %.2f near %f, got %f", tt.q, want, got)
+		}
+	}
+}
+
+func TestTDigest_Weighted(t *testing.T) {
+	// Value 10 carries three times the weight of value 1
+	digest := newTDigest()
+	for i := 0; i < 1000; i++ {
+		digest.add(1, 1)
+		digest.add(10, 3)
+	}
+
+	if got := digest.quantile(0.1); got != 1 {
+		t.Errorf("Expected 10th percentile 1, got %f", got)
+	}
+	if got := digest.quantile(0.9); got != 10 {
+		t.Errorf("Expected 90th percentile 10, got %f", got)
+	}
+}
+
+func TestTDigest_Small(t *testing.T) {
+	digest := newTDigest()
+	if got := digest.quantile(0.5); got != 0 {
+		t.Errorf("Expected 0 for an empty digest, got %f", got)
+	}
+	digest.add(42, 1)
+	if got := digest.quantile(0.99); got != 42 {
+		t.Errorf("Expected 42 for a single value, got %f", got)
+	}
+}
+
+func TestHyperLogLog_Estimate(t *testing.T) {
+	tests := []int{0, 1, 100, 10000, 300000}
+
+	for _, n := range tests {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			hll := newHyperLogLog()
+			for i := 0; i < n; i++ {
+				value := fmt.Sprintf("value_%d", i)
+				addDistinct(hll, value)
+				addDistinct(hll, []byte(value)) // Duplicates do not count
+			}
+			got := hll.estimate()
+			if math.Abs(float64(got)-float64(n)) > 0.03*float64(n)+1 {
+				t.Errorf("Expected about %d distinct values, got %d", n, got)
+			}
+		})
+	}
+}
+
+func TestTableAccumulator_Sketched(t *testing.T) {
+	config := SamplingConfig{SampleSize: SketchSampleSize}
+	acc, err := NewTableAccumulator([]string{"id", "group"}, config)
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+
+	n := 100000
+	for i := 0; i < n; i++ {
+		acc.Add([]string{fmt.Sprint(i), fmt.Sprint(i % 7)})
+	}
+	for _, col := range acc.columns {
+		if col.distinct != nil || col.numericValues != nil {
+			t.Errorf("Expected column %s to keep no per-value state", col.name)
+		}
+	}
+
+	stats := acc.Finalize()
+	if got := stats.DistinctCounts["id"]; math.Abs(float64(got-int64(n))) > 0.03*float64(n) {
+		t.Errorf("Expected about %d distinct ids, got %d", n, got)
+	}
+	if got := stats.DistinctCounts["group"]; got != 7 {
+		t.Errorf("Expected 7 distinct groups, got %d", got)
+	}
+	for metric, method := range map[string]string{"distinct": MethodHyperLogLog, "percentiles": MethodTDigest, "mean": MethodCount} {
+		if source := stats.Provenance.Columns["id"][metric]; source.Method != method || source.Exact != (method == MethodCount) {
+			t.Errorf("Expected %s computed with %s, got %+v", metric, method, source)
+		}
+	}
+
+	agg := stats.Aggregates["id"]
+	if agg.Count != int64(n) {
+		t.Errorf("Expected count %d, got %d", n, agg.Count)
+	}
+	if !floatEqual(agg.Mean, float64(n-1)/2) {
+		t.Errorf("Expected mean %f, got %f", float64(n-1)/2, agg.Mean)
+	}
+	if math.Abs(agg.Median-float64(n)/2) > 0.005*float64(n) {
+		t.Errorf("Expected median near %d, got %f", n/2, agg.Median)
+	}
+	if stats.MinValues["id"] != int64(0) || stats.MaxValues["id"] != int64(n-1) {
+		t.Errorf("Expected exact range 0..%d, got %v..%v", n-1, stats.MinValues["id"], stats.MaxValues["id"])
+	}
+}
+
+func TestTableAccumulator_SketchedWeighted(t *testing.T) {
+	config := SamplingConfig{SampleSize: SketchSampleSize}
+	acc, err := NewTableAccumulator([]string{"amount"}, config)
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	acc.setWeighted()
+
+	for i := 0; i < 1000; i++ {
+		acc.add([]string{"10"}, 1)
+		acc.add([]string{"20"}, 3)
+	}
+	agg := acc.Finalize().Aggregates["amount"]
+
+	if !floatEqual(agg.Mean, 17.5) {
+		t.Errorf("Expected weighted mean 17.5, got %f", agg.Mean)
+	}
+	if !floatEqual(agg.Variance, 18.75) {
+		t.Errorf("Expected weighted variance 18.75, got %f", agg.Variance)
+	}
+	if agg.Sum != 30000 {
+		t.Errorf("Expected observed sum 30000, got %f", agg.Sum)
+	}
+}
@@ -0,0 +1,286 @@
+package tablestats
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// sniffSize is how many leading bytes are inspected when detecting a delimiter
+const sniffSize = 16 * 1024
+
+// candidateDelimiters are tried in order of preference when sniffing
+var candidateDelimiters = []rune{',', '\t', ';', '|'}
+
+// candidateQuotes are the quote characters detected, '"' winning ties
+var candidateQuotes = []byte{'"', '\''}
+
+// sniffRows is the number of records after the first that are compared with
+// it to decide whether it is a header
+const sniffRows = 20
+
+// Dialect is how a delimited file is written, as detected by SniffDialect
+type Dialect struct {
+	Delimiter  rune
+	Quote      rune        // '"' unless fields are quoted with '\''
+	Escape     EscapeStyle // How quotes inside quoted fields are written
+	LazyQuotes bool        // Quotes appear inside unquoted fields
+	HasHeader  bool        // The first record names the columns rather than holding values
+}
+
+// SniffDialect detects the delimiter, quote character, escaping style and
+// header of the input from its start, like Python's csv.Sniffer. The first
+// record is taken for a header unless the columns whose other values look
+// alike, all numbers or all of one length, mostly have a first value that
+// looks like them too.
+func SniffDialect(r io.Reader) (*Dialect, error) {
+	buf, truncated, err := readSniffBuffer(r)
+	if err != nil {
+		return nil, err
+	}
+	delim, err := sniffDelimiter(buf, truncated)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dialect{Delimiter: delim, Quote: rune(sniffQuote(buf, byte(delim)))}
+	backslashes, doubled, stray := scanQuotes(buf, byte(delim), byte(d.Quote))
+	if backslashes > doubled {
+		d.Escape = EscapeBackslash
+	}
+	d.LazyQuotes = stray > 0
+	d.HasHeader = headerVotes(sniffRecords(buf, truncated, d)) >= 0
+	return d, nil
+}
+
+// DetectDialect sniffs the dialect of a file in the given encoding
+func DetectDialect(filePath string, encodingName string) (*Dialect, error) {
+	enc, err := lookupEncoding(encodingName)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return SniffDialect(enc.decode(file))
+}
+
+// sniffQuote returns the candidate quote that most often opens or closes a
+// field, next to a delimiter or line break
+func sniffQuote(buf []byte, delim byte) byte {
+	boundary := func(i int) bool {
+		return i < 0 || i >= len(buf) || buf[i] == delim || buf[i] == '\n' || buf[i] == '\r'
+	}
+	best, bestCount := candidateQuotes[0], 0
+	for _, q := range candidateQuotes {
+		count := 0
+		for i, c := range buf {
+			if c == q && (boundary(i-1) || boundary(i+1)) {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = q, count
+		}
+	}
+	return best
+}
+
+// scanQuotes counts the quotes escaped with a backslash and doubled inside
+// quoted fields, and the stray quotes inside unquoted fields
+func scanQuotes(buf []byte, delim, quote byte) (backslashes, doubled, stray int) {
+	inQuotes, fieldStart := false, true
+	for i := 0; i < len(buf); i++ {
+		c := buf[i]
+		next := byte(0)
+		if i+1 < len(buf) {
+			next = buf[i+1]
+		}
+		if inQuotes {
+			switch {
+			case c == '\\' && (next == quote || next == '\\'):
+				if next == quote {
+					backslashes++
+				}
+				i++
+			case c == quote && next == quote:
+				doubled++
+				i++
+			case c == quote:
+				inQuotes = false
+			}
+			continue
+		}
+		if c == quote {
+			if fieldStart {
+				inQuotes = true
+			} else {
+				stray++
+			}
+		}
+		fieldStart = c == delim || c == '\n' || c == '\r'
+	}
+	return backslashes, doubled, stray
+}
+
+// sniffRecords parses the first record and up to sniffRows more from buf,
+// leaving out a last record that may be cut off
+func sniffRecords(buf []byte, truncated bool, d *Dialect) [][]string {
+	reader := (&CSVReader{Delimiter: d.Delimiter, Quote: d.Quote, LazyQuotes: true}).newCSVReader(context.Background(), bytes.NewReader(buf))
+	var records [][]string
+	for len(records) <= sniffRows {
+		record, err := reader.Read()
+		if err != nil {
+			break
+		}
+		records = append(records, record)
+	}
+	if truncated && len(records) <= sniffRows && len(records) > 2 {
+		records = records[:len(records)-1]
+	}
+	return records
+}
+
+// headerVotes compares the first record with the rest column by column. A
+// column whose other values are all numbers, or all of one length, votes for
+// a header when its first value does not look like them and against one when
+// it does.
+func headerVotes(records [][]string) int {
+	if len(records) < 2 {
+		return 0
+	}
+	votes := 0
+	for col, first := range records[0] {
+		shape, consistent := "", true
+		for _, record := range records[1:] {
+			if col >= len(record) {
+				consistent = false
+				break
+			}
+			value := strings.TrimSpace(record[col])
+			if isNullValue(value) {
+				continue
+			}
+			if s := valueShape(value); shape == "" {
+				shape = s
+			} else if s != shape {
+				consistent = false
+				break
+			}
+		}
+		if !consistent || shape == "" {
+			continue
+		}
+		if valueShape(strings.TrimSpace(first)) != shape {
+			votes++
+		} else {
+			votes--
+		}
+	}
+	return votes
+}
+
+// valueShape is "number" for numbers and the length of other values
+func valueShape(value string) string {
+	if kind, _ := classifyNumber(value); kind != notNumber {
+		return "number"
+	}
+	return fmt.Sprintf("length %d", len([]rune(value)))
+}
+
+// SniffDelimiter detects the field delimiter from the start of the input. The
+// delimiter that splits the most lines into the same number of fields wins;
+// ties are broken by field count and then by candidate order.
+func SniffDelimiter(r io.Reader) (rune, error) {
+	buf, truncated, err := readSniffBuffer(r)
+	if err != nil {
+		return 0, err
+	}
+	return sniffDelimiter(buf, truncated)
+}
+
+// readSniffBuffer reads the first sniffSize bytes of the input. truncated is
+// true when there may be more.
+func readSniffBuffer(r io.Reader) (buf []byte, truncated bool, err error) {
+	buf = make([]byte, sniffSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, false, err
+	}
+	return buf[:n], n == sniffSize, nil
+}
+
+func sniffDelimiter(buf []byte, truncated bool) (rune, error) {
+	lines := bytes.Split(buf, []byte{'\n'})
+	if truncated && len(lines) > 1 {
+		// The last line was probably cut off
+		lines = lines[:len(lines)-1]
+	}
+
+	best, bestLines, bestFields := rune(0), 0, 0
+	for _, delim := range candidateDelimiters {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			line = bytes.TrimRight(line, "\r")
+			if len(line) == 0 {
+				continue
+			}
+			counts[countFields(line, delim)]++
+		}
+
+		// The most common field count is the candidate's consistent layout
+		modeFields, modeLines := 0, 0
+		for fields, lineCount := range counts {
+			if lineCount > modeLines || (lineCount == modeLines && fields > modeFields) {
+				modeFields, modeLines = fields, lineCount
+			}
+		}
+		if modeFields < 2 {
+			continue
+		}
+		if modeLines > bestLines || (modeLines == bestLines && modeFields > bestFields) {
+			best, bestLines, bestFields = delim, modeLines, modeFields
+		}
+	}
+
+	if best == 0 {
+		return 0, fmt.Errorf("cannot detect delimiter")
+	}
+	return best, nil
+}
+
+// DetectDelimiter sniffs the delimiter of a file in the given encoding
+func DetectDelimiter(filePath string, encodingName string) (rune, error) {
+	enc, err := lookupEncoding(encodingName)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return SniffDelimiter(enc.decode(file))
+}
+
+// countFields counts the fields of a line, ignoring delimiters inside quotes
+func countFields(line []byte, delim rune) int {
+	fields, inQuotes := 1, false
+	for _, c := range string(line) {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == delim && !inQuotes:
+			fields++
+		}
+	}
+	return fields
+}
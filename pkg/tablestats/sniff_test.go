@@ -0,0 +1,130 @@
+package tablestats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected rune
+	}{
+		{
+			name:     "comma",
+			content:  "id,name,city\n1,Alice,NYC\n2,Bob,Boston\n",
+			expected: ',',
+		},
+		{
+			name:     "tab",
+			content:  "id\tname\tcity\n1\tAlice\tNYC\n2\tBob\tBoston\n",
+			expected: '\t',
+		},
+		{
+			name:     "semicolon with decimal commas",
+			content:  "id;amount;city\n1;1,5;NYC\n2;2,25;Boston\n",
+			expected: ';',
+		},
+		{
+			name:     "pipe",
+			content:  "id|name\n1|Alice\n2|Bob\n",
+			expected: '|',
+		},
+		{
+			name:     "quoted commas",
+			content:  "id;note\n1;\"a, b, c\"\n2;\"d, e\"\n",
+			expected: ';',
+		},
+		{
+			name:     "crlf line endings",
+			content:  "a,b\r\n1,2\r\n3,4\r\n",
+			expected: ',',
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delim, err := SniffDelimiter(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("SniffDelimiter failed: %v", err)
+			}
+			if delim != tt.expected {
+				t.Errorf("SniffDelimiter = %q, want %q", delim, tt.expected)
+			}
+		})
+	}
+
+	if _, err := SniffDelimiter(strings.NewReader("single\ncolumn\n")); err == nil {
+		t.Error("Expected error for single-column input")
+	}
+}
+
+func TestSniffDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected Dialect
+	}{
+		{
+			name:     "plain with header",
+			content:  "id,name,score\n1,Alice,3.5\n2,Bob,4\n",
+			expected: Dialect{Delimiter: ',', Quote: '"', HasHeader: true},
+		},
+		{
+			name:     "no header",
+			content:  "1,Alice,3.5\n2,Bob,4\n3,Carol,2.25\n",
+			expected: Dialect{Delimiter: ',', Quote: '"'},
+		},
+		{
+			name:     "single quotes",
+			content:  "id;note\n1;'a; b'\n2;'c'\n",
+			expected: Dialect{Delimiter: ';', Quote: '\'', HasHeader: true},
+		},
+		{
+			name:     "doubled quotes",
+			content:  "id,note\n1,\"say \"\"hi\"\"\"\n2,\"x\"\n",
+			expected: Dialect{Delimiter: ',', Quote: '"', HasHeader: true},
+		},
+		{
+			name:     "backslash escapes",
+			content:  "id,note\n1,\"say \\\"hi\\\"\"\n2,\"a\\\\\"\n",
+			expected: Dialect{Delimiter: ',', Quote: '"', Escape: EscapeBackslash, HasHeader: true},
+		},
+		{
+			name:     "stray quotes",
+			content:  "id,size\n1,5\" screen\n2,7\" screen\n",
+			expected: Dialect{Delimiter: ',', Quote: '"', LazyQuotes: true, HasHeader: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := SniffDialect(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("SniffDialect failed: %v", err)
+			}
+			if *d != tt.expected {
+				t.Errorf("SniffDialect = %+v, want %+v", *d, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHeaderVotes(t *testing.T) {
+	tests := []struct {
+		records  [][]string
+		expected int
+	}{
+		{[][]string{{"id", "code"}, {"1", "AB"}, {"2", "CD"}}, 2},
+		{[][]string{{"7", "XY"}, {"1", "AB"}, {"2", "CD"}}, -2},
+		// Names and free text of varying length say nothing
+		{[][]string{{"name"}, {"Alice"}, {"Bob"}}, 0},
+		{[][]string{{"id"}}, 0},
+	}
+	for _, tt := range tests {
+		if got := headerVotes(tt.records); got != tt.expected {
+			t.Errorf("headerVotes(%v) = %d, want %d", tt.records, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package tablestats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+)
+
+// fieldSplitter splits delimited records into byte fields without allocating a
+// string per field. It implements the plain dialect of encoding/csv (standard
+// quotes, strict quoting, no comments) with the same rules and errors, for reads
+// where fields are inspected but not retained. The returned fields are only
+// valid until the next call to Read.
+type fieldSplitter struct {
+	r        *bufio.Reader
+	delim    byte
+	ctx      context.Context
+	rows     int
+	progress *progressReporter  // nil when progress is not reported
+	ragged   *raggedCounter     // Counts rows not as wide as the header, may be nil
+	errs     *parseErrorCounter // Skips malformed records, nil to return their errors
+
+	keep            []bool // Fields whose bytes are copied, nil for all
+	numLine         int
+	offset          int64 // Input bytes consumed by the records read
+	fieldsPerRecord int
+	raw             []byte   // Lines longer than the bufio buffer
+	buf             []byte   // Unquoted bytes of all fields of the record
+	ends            []int    // End offset of each field in buf
+	fields          [][]byte // Fields of the record, sliced from buf
+}
+
+func newFieldSplitter(ctx context.Context, rd io.Reader, delim byte) *fieldSplitter {
+	return &fieldSplitter{r: bufio.NewReader(rd), delim: delim, ctx: ctx}
+}
+
+// splittable reports whether the reader's dialect can be parsed by fieldSplitter
+func (r *CSVReader) splittable() bool {
+	return r.Delimiter > 0 && r.Delimiter < 128 && r.Delimiter != '"' &&
+		r.Delimiter != '\r' && r.Delimiter != '\n' &&
+		(r.Quote == 0 || r.Quote == '"') && !r.LazyQuotes && r.Escape == EscapeDoubled && r.Comment == 0
+}
+
+// keepOnly limits the fields whose bytes are copied to the given header
+// positions. Other fields are still parsed to find their end and validated,
+// but are returned empty.
+func (s *fieldSplitter) keepOnly(fields []int) {
+	if fields == nil {
+		s.keep = nil
+		return
+	}
+	s.keep = make([]bool, s.fieldsPerRecord)
+	for _, idx := range fields {
+		s.keep[idx] = true
+	}
+}
+
+// kept reports whether the bytes of field i are copied
+func (s *fieldSplitter) kept(i int) bool {
+	return s.keep == nil || (i < len(s.keep) && s.keep[i])
+}
+
+// readHeader reads the first record as strings
+func (s *fieldSplitter) readHeader() ([]string, error) {
+	fields, err := s.Read()
+	if err != nil {
+		return nil, err
+	}
+	header := make([]string, len(fields))
+	for i, field := range fields {
+		header[i] = string(field)
+	}
+	return header, nil
+}
+
+// Read returns the fields of the next record, skipping the malformed records
+// s.errs tolerates. Like recordReader it checks the context and reports
+// progress every cancelCheckInterval rows.
+func (s *fieldSplitter) Read() ([][]byte, error) {
+	for {
+		fields, err := s.read()
+		if err == nil || err == io.EOF {
+			return fields, err
+		}
+		if err := s.errs.skip(err); err != nil {
+			return fields, err
+		}
+	}
+}
+
+func (s *fieldSplitter) read() ([][]byte, error) {
+	s.rows++
+	if s.rows%cancelCheckInterval == 0 {
+		if err := s.ctx.Err(); err != nil {
+			return nil, err
+		}
+		if s.progress != nil {
+			s.progress.report()
+		}
+	}
+	fields, err := s.readRecord()
+	if err == nil && s.progress != nil {
+		s.progress.rows++
+	}
+	if err != nil && err != io.EOF {
+		return fields, wrapParseError(err, int64(s.rows))
+	}
+	return fields, err
+}
+
+// readLine reads the next line, normalizing \r\n to \n as encoding/csv does
+func (s *fieldSplitter) readLine() ([]byte, error) {
+	line, err := s.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		s.raw = append(s.raw[:0], line...)
+		for err == bufio.ErrBufferFull {
+			line, err = s.r.ReadSlice('\n')
+			s.raw = append(s.raw, line...)
+		}
+		line = s.raw
+	}
+	s.offset += int64(len(line))
+	if len(line) > 0 && err == io.EOF {
+		err = nil
+		// A trailing \r before EOF is dropped
+		if line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+	}
+	s.numLine++
+	if n := len(line); n >= 2 && line[n-2] == '\r' && line[n-1] == '\n' {
+		line[n-2] = '\n'
+		line = line[:n-1]
+	}
+	return line, err
+}
+
+// lengthNL returns 1 if line ends with a newline, otherwise 0
+func lengthNL(line []byte) int {
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		return 1
+	}
+	return 0
+}
+
+func (s *fieldSplitter) readRecord() ([][]byte, error) {
+	// Skip empty lines
+	var line []byte
+	var errRead error
+	for errRead == nil {
+		line, errRead = s.readLine()
+		if errRead == nil && len(line) == lengthNL(line) {
+			continue
+		}
+		break
+	}
+	if errRead == io.EOF {
+		return nil, errRead
+	}
+
+	var err error
+	recLine := s.numLine
+	posLine, col := s.numLine, 1
+	s.buf = s.buf[:0]
+	s.ends = s.ends[:0]
+parseField:
+	for {
+		if len(line) == 0 || line[0] != '"' {
+			// Unquoted field
+			i := bytes.IndexByte(line, s.delim)
+			field := line
+			if i >= 0 {
+				field = field[:i]
+			} else {
+				field = field[:len(field)-lengthNL(field)]
+			}
+			if j := bytes.IndexByte(field, '"'); j >= 0 {
+				err = &csv.ParseError{StartLine: recLine, Line: s.numLine, Column: col + j, Err: csv.ErrBareQuote}
+				break parseField
+			}
+			if s.kept(len(s.ends)) {
+				s.buf = append(s.buf, field...)
+			}
+			s.ends = append(s.ends, len(s.buf))
+			if i >= 0 {
+				line = line[i+1:]
+				col += i + 1
+				continue parseField
+			}
+			break parseField
+		}
+
+		// Quoted field, possibly spanning several lines
+		keep := s.kept(len(s.ends))
+		line = line[1:]
+		col++
+		for {
+			i := bytes.IndexByte(line, '"')
+			switch {
+			case i >= 0:
+				if keep {
+					s.buf = append(s.buf, line[:i]...)
+				}
+				line = line[i+1:]
+				col += i + 1
+				switch {
+				case len(line) > 0 && line[0] == '"':
+					// Escaped quote
+					if keep {
+						s.buf = append(s.buf, '"')
+					}
+					line = line[1:]
+					col++
+				case len(line) > 0 && line[0] == s.delim:
+					s.ends = append(s.ends, len(s.buf))
+					line = line[1:]
+					col++
+					continue parseField
+				case lengthNL(line) == len(line):
+					s.ends = append(s.ends, len(s.buf))
+					break parseField
+				default:
+					err = &csv.ParseError{StartLine: recLine, Line: s.numLine, Column: col - 1, Err: csv.ErrQuote}
+					break parseField
+				}
+			case len(line) > 0:
+				if keep {
+					s.buf = append(s.buf, line...)
+				}
+				if errRead != nil {
+					break parseField
+				}
+				col += len(line)
+				line, errRead = s.readLine()
+				if len(line) > 0 {
+					posLine++
+					col = 1
+				}
+				if errRead == io.EOF {
+					errRead = nil
+				}
+			default:
+				// Unterminated quote at the end of the input
+				if errRead == nil {
+					err = &csv.ParseError{StartLine: recLine, Line: posLine, Column: col, Err: csv.ErrQuote}
+					break parseField
+				}
+				s.ends = append(s.ends, len(s.buf))
+				break parseField
+			}
+		}
+	}
+	if err == nil {
+		err = errRead
+	}
+
+	s.fields = s.fields[:0]
+	start := 0
+	for _, end := range s.ends {
+		s.fields = append(s.fields, s.buf[start:end])
+		start = end
+	}
+
+	// The first record sets the number of fields; other widths are counted
+	if s.fieldsPerRecord == 0 {
+		s.fieldsPerRecord = len(s.fields)
+	} else if s.ragged != nil && err == nil {
+		s.ragged.observe(len(s.fields), recLine)
+	}
+	return s.fields, err
+}
+
+// splitRecords feeds every remaining record of the splitter into a new accumulator
+func splitRecords(splitter *fieldSplitter, header []string, config SamplingConfig) (*TableAccumulator, error) {
+	acc, err := newTableAccumulator(splitter.ctx, header, config)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		fields, err := splitter.Read()
+		if err == io.EOF {
+			return acc, nil
+		}
+		if err != nil {
+			acc.Discard()
+			return nil, err
+		}
+		acc.addFields(fields)
+	}
+}
@@ -0,0 +1,126 @@
+package tablestats
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFieldSplitter_MatchesEncodingCSV(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"simple", "a,b\n1,2\n3,4\n"},
+		{"no trailing newline", "a,b\n1,2"},
+		{"crlf", "a,b\r\n1,2\r\n"},
+		{"trailing carriage return", "a,b\n1,2\r"},
+		{"empty lines", "a,b\n\n1,2\n\r\n3,4\n"},
+		{"empty fields", "a,b,c\n,,\n1,,3\n"},
+		{"quoted", "a,b\n\"x,y\",\"say \"\"hi\"\"\"\n"},
+		{"quoted newline", "a,b\n\"line1\nline2\",2\n"},
+		{"quoted crlf", "a,b\n\"line1\r\nline2\",2\r\n"},
+		{"quoted at eof", "a,b\n1,\"2\""},
+		{"empty quoted", "a,b\n\"\",\"\"\n"},
+		{"bare quote", "a,b\n1,x\"y\n"},
+		{"extraneous quote", "a,b\n\"x\"y,2\n"},
+		{"unterminated quote", "a,b\n1,\"2\n3,4\n"},
+		{"field count", "a,b\n1,2,3\n4\n"},
+		{"long line", "a,b\n" + strings.Repeat("x", 10000) + ",2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Like newCSVReader, rows of another width are not an error
+			reference := csv.NewReader(strings.NewReader(tt.input))
+			reference.FieldsPerRecord = -1
+			want, wantErr := reference.ReadAll()
+
+			splitter := newFieldSplitter(context.Background(), strings.NewReader(tt.input), ',')
+			var got [][]string
+			var gotErr error
+			for {
+				fields, err := splitter.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					gotErr = err
+					break
+				}
+				record := make([]string, len(fields))
+				for i, field := range fields {
+					record[i] = string(field)
+				}
+				got = append(got, record)
+			}
+
+			if wantErr != nil {
+				var csvErr *csv.ParseError
+				var parseErr *ParseError
+				if !errors.As(wantErr, &csvErr) || !errors.As(gotErr, &parseErr) {
+					t.Fatalf("Expected a parse error like %v, got %v", wantErr, gotErr)
+				}
+				if parseErr.Err != csvErr.Err || parseErr.Line != csvErr.Line || parseErr.Column != csvErr.Column {
+					t.Errorf("Expected %v, got %v", wantErr, gotErr)
+				}
+				return
+			}
+			if gotErr != nil {
+				t.Fatalf("Unexpected error: %v", gotErr)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Expected %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestReadTable_SplitMatchesRecords(t *testing.T) {
+	data := "id,name,score\n1, Alice ,9.5\n2,Bob,\n3,Alice,7\n4,NULL,8.25\n1,Bob,9.5\n"
+	config := SamplingConfig{MaxFileSize: 1024, SampleSize: 1000, RandomPositions: 5}
+
+	// The split full read must agree with analyzing the parsed records
+	reader := NewCSVReader(',')
+	split, err := reader.ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadTableFrom failed: %v", err)
+	}
+	sample, err := reader.ReadSampleFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+	if err != nil {
+		t.Fatalf("ReadSampleFrom failed: %v", err)
+	}
+	want := AnalyzeSample(sample, config)
+
+	if !reflect.DeepEqual(split, want) {
+		t.Errorf("Expected %+v, got %+v", want, split)
+	}
+}
+
+func TestFieldSplitter_KeepOnly(t *testing.T) {
+	data := "a,b,c\n1,\"x,\"\"y\"\"\",3\n4,\"multi\nline\",6\n"
+	splitter := newFieldSplitter(context.Background(), strings.NewReader(data), ',')
+	if _, err := splitter.readHeader(); err != nil {
+		t.Fatalf("readHeader failed: %v", err)
+	}
+	splitter.keepOnly([]int{0, 2})
+
+	expected := [][]string{{"1", "", "3"}, {"4", "", "6"}}
+	for _, want := range expected {
+		fields, err := splitter.Read()
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		got := make([]string, len(fields))
+		for i, field := range fields {
+			got[i] = string(field)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Expected %q, got %q", want, got)
+		}
+	}
+}
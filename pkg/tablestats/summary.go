@@ -0,0 +1,89 @@
+package tablestats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// summaryNullColumns is the number of columns with the most nulls the summary
+// lists
+const summaryNullColumns = 5
+
+// renderSummary prints a one-screen overview of the table for triage: its
+// size, the mix of column types, the columns with the most nulls and how many
+// columns have warnings
+func (r *TextRenderer) renderSummary(ew *errWriter, nw numberWriter, stats *TableStats) {
+	ew.printf("\nSummary:\n")
+	ew.printf("  Rows: %s sampled of %s\n", nw.int(stats.RowCount), nw.int(stats.EstimatedRows))
+	ew.printf("  Columns: %d (%s)\n", stats.ColumnCount, typeMix(stats))
+	if stats.EstimatedSize > 0 {
+		ew.printf("  Estimated Size: %s\n", nw.bytes(stats.EstimatedSize))
+	}
+	if m := stats.Memory; m != nil {
+		ew.printf("  Estimated Memory: %s\n", nw.bytes(m.Total))
+	}
+	r.renderFileInfo(ew, nw, "  ", stats.FileInfo)
+
+	var warned int
+	for _, name := range stats.ColumnNames {
+		if columnWarned(stats, name) {
+			warned++
+		}
+	}
+	if warned > 0 {
+		ew.printf("  %s\n", r.paint(colorYellow, fmt.Sprintf("Columns With Warnings: %d", warned)))
+	}
+	if len(stats.PII) > 0 {
+		ew.printf("  Columns With Personal Data: %d\n", len(stats.PII))
+	}
+
+	nullColumns := mostNullColumns(stats, summaryNullColumns)
+	if len(nullColumns) == 0 {
+		ew.printf("  Most Nulls: none\n")
+		return
+	}
+	width := 0
+	for _, name := range nullColumns {
+		width = max(width, len([]rune(cell(name))))
+	}
+	ew.printf("  Most Nulls:\n")
+	for _, name := range nullColumns {
+		line := fmt.Sprintf("%s%s  %s (%.2f%%)", cell(name), strings.Repeat(" ", width-len([]rune(cell(name)))),
+			nw.int(stats.NullCounts[name]), stats.NullPercentage[name])
+		if stats.NullPercentage[name] >= HighNullPercentage {
+			line = r.paint(colorRed, line)
+		}
+		ew.printf("    %s\n", line)
+	}
+}
+
+// typeMix counts the columns of each type, most common first, e.g.
+// "5 int64, 2 string"
+func typeMix(stats *TableStats) string {
+	counts := make(map[string]int)
+	for _, name := range stats.ColumnNames {
+		counts[stats.ColumnTypes[name]]++
+	}
+	types := sortedKeys(counts)
+	sort.SliceStable(types, func(i, j int) bool { return counts[types[i]] > counts[types[j]] })
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%d %s", counts[t], t)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// mostNullColumns returns up to n columns holding nulls, most nulls first
+func mostNullColumns(stats *TableStats, n int) []string {
+	var names []string
+	for _, name := range stats.ColumnNames {
+		if stats.NullCounts[name] > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		return stats.NullPercentage[names[i]] > stats.NullPercentage[names[j]]
+	})
+	return names[:min(len(names), n)]
+}
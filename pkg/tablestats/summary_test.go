@@ -0,0 +1,61 @@
+package tablestats
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender_Summary(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header:        []string{"id", "code", "note", "score"},
+		EstimatedRows: 400,
+	}, [][]string{
+		{"1", "AAA", "", "3"},
+		{"2", "BBB", "x", ""},
+		{"3", "CC", "", "4"},
+		{"4", "DDD", "", "5"},
+	})
+	stats := AnalyzeSample(sample, DefaultSamplingConfig())
+	// 19 bytes of values in 4 rows
+	if stats.EstimatedSize != 1900 {
+		t.Errorf("Expected an estimated size of 1900, got %d", stats.EstimatedSize)
+	}
+
+	var b strings.Builder
+	if err := (&TextRenderer{Title: "CSV", Summary: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	expected := `=== CSV File Statistics ===
+
+Summary:
+  Rows: 4 sampled of 400
+  Columns: 4 (2 int64, 2 string)
+  Estimated Size: 1.9 kB
+  Estimated Memory: 10.9 kB
+  Most Nulls:
+    note   3 (75.00%)
+    score  1 (25.00%)
+
+`
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+
+	b.Reset()
+	if err := (&TextRenderer{Summary: true, Wide: true}).Render(&b, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(b.String(), "Column Details:\n  id:") {
+		t.Errorf("Expected the column details with Wide, got:\n%s", b.String())
+	}
+}
+
+func TestTypeMix(t *testing.T) {
+	stats := &TableStats{
+		ColumnNames: []string{"a", "b", "c", "d"},
+		ColumnTypes: map[string]string{"a": "string", "b": "float64", "c": "string", "d": "int64"},
+	}
+	if got := typeMix(stats); got != "2 string, 1 float64, 1 int64" {
+		t.Errorf("Expected 2 string, 1 float64, 1 int64, got %s", got)
+	}
+}
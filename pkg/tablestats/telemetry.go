@@ -0,0 +1,49 @@
+package tablestats
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies this package to OpenTelemetry. Spans and
+// metrics go to the global providers, so they are no-ops until the embedding
+// program installs an SDK with otel.SetTracerProvider and otel.SetMeterProvider.
+const instrumentationName = "github.com/WindowGenerator/gotablestats/pkg/tablestats"
+
+var (
+	tracer = otel.Tracer(instrumentationName)
+	meter  = otel.Meter(instrumentationName)
+
+	rowsRead, _ = meter.Int64Counter("tablestats.rows_read",
+		metric.WithDescription("Rows parsed from table sources"),
+		metric.WithUnit("{row}"))
+	bytesRead, _ = meter.Int64Counter("tablestats.bytes_read",
+		metric.WithDescription("Bytes consumed from table sources"),
+		metric.WithUnit("By"))
+)
+
+// startSpan starts a span for one profiling phase: read, sample or analyze
+func startSpan(ctx context.Context, phase string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "tablestats."+phase, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on the span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// recordRead adds the rows and bytes consumed by one read to the counters
+func recordRead(ctx context.Context, format string, progress *progressReporter) {
+	attrs := metric.WithAttributes(attribute.String("tablestats.format", format))
+	rowsRead.Add(ctx, progress.rows, attrs)
+	bytesRead.Add(ctx, progress.bytes, attrs)
+}
@@ -0,0 +1,74 @@
+package tablestats
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTelemetry(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	metrics := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(metrics))
+
+	prevTP, prevMP := otel.GetTracerProvider(), otel.GetMeterProvider()
+	otel.SetTracerProvider(tp)
+	otel.SetMeterProvider(mp)
+	t.Cleanup(func() {
+		// The default providers keep delegating to the first ones set, so
+		// those are shut down as well as replaced
+		otel.SetTracerProvider(prevTP)
+		otel.SetMeterProvider(prevMP)
+		tp.Shutdown(context.Background())
+		mp.Shutdown(context.Background())
+	})
+
+	tmpFile := createLargeCSV(t, 100)
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 1000, RandomPositions: 5}
+	if _, err := NewCSVReader(',').ReadTable(context.Background(), tmpFile, config); err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, span := range spans.Ended() {
+		names[span.Name()] = true
+		if span.Name() != "tablestats.read" {
+			continue
+		}
+		for _, attr := range span.Attributes() {
+			if attr.Key == "tablestats.rows_read" && attr.Value.AsInt64() != 100 {
+				t.Errorf("Expected 100 rows on the read span, got %d", attr.Value.AsInt64())
+			}
+		}
+	}
+	for _, name := range []string{"tablestats.read", "tablestats.analyze"} {
+		if !names[name] {
+			t.Errorf("Expected span %s, got %v", name, names)
+		}
+	}
+
+	var data metricdata.ResourceMetrics
+	if err := metrics.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	var rows int64
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name != "tablestats.rows_read" {
+				continue
+			}
+			for _, point := range m.Data.(metricdata.Sum[int64]).DataPoints {
+				rows += point.Value
+			}
+		}
+	}
+	if rows != 100 {
+		t.Errorf("Expected 100 rows counted, got %d", rows)
+	}
+}
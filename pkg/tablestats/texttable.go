@@ -0,0 +1,169 @@
+package tablestats
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCellWidth is the number of characters a value of the column table is
+// cut to
+const maxCellWidth = 24
+
+// renderColumnTable prints a row per column with its type, nulls, range,
+// mean, 95th percentile and distinct count, followed by the warnings about
+// each column
+func (r *TextRenderer) renderColumnTable(ew *errWriter, nw numberWriter, stats *TableStats) {
+	header := []string{"Column", "Type", "Nulls", "Min", "Max", "Mean", "P95", "Distinct"}
+	sparklines := false
+	for _, agg := range stats.Aggregates {
+		sparklines = sparklines || (len(agg.Histogram) > 1 && !r.NoSparklines)
+	}
+	if sparklines {
+		header = append(header, "Shape")
+	}
+	rows := [][]string{header}
+	for _, name := range stats.ColumnNames {
+		row := []string{
+			cell(name),
+			typeLabel(stats, name),
+			fmt.Sprintf("%s (%.2f%%)", nw.int(stats.NullCounts[name]), stats.NullPercentage[name]),
+			cell(valueText(stats.MinValues[name])),
+			cell(valueText(stats.MaxValues[name])),
+			"", "",
+			nw.int(stats.DistinctCounts[name]),
+		}
+		if stats.Provenance.sketchNote(name, "distinct") != "" {
+			row[7] = "~" + row[7]
+		}
+		agg, ok := stats.Aggregates[name]
+		if ok {
+			row[5] = nw.float(agg.Mean)
+			row[6] = nw.float(agg.Percentiles[95])
+		}
+		if sparklines {
+			shape := ""
+			if ok && len(agg.Histogram) > 1 {
+				shape = histogramSparkline(agg.Histogram)
+			}
+			row = append(row, shape)
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, c := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(c))
+		}
+	}
+	ew.printf("\nColumn Details:\n")
+	for n, row := range rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = c + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c))
+		}
+		if n > 0 {
+			name := stats.ColumnNames[n-1]
+			cells[0] = r.paint(columnColor(stats, name), cells[0])
+			if stats.NullPercentage[name] >= HighNullPercentage {
+				cells[2] = r.paint(colorRed, cells[2])
+			}
+		}
+		ew.printf("  %s\n", strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+
+	var warned bool
+	for _, name := range stats.ColumnNames {
+		for _, warning := range columnWarnings(stats, name) {
+			if !warned {
+				ew.printf("\nWarnings:\n")
+				warned = true
+			}
+			ew.printf("  %s: %s\n", name, r.paint(colorYellow, warning))
+		}
+	}
+	if len(stats.PII) > 0 {
+		ew.printf("\nPersonal Data:\n")
+		for _, name := range stats.ColumnNames {
+			if flag, ok := stats.PII[name]; ok {
+				ew.printf("  %s: %s (score %.2f)\n", name, flag.Kind, flag.Score)
+			}
+		}
+	}
+}
+
+// columnWarnings returns the data quality warnings about a column, e.g.
+// "Padded: 0 with leading, 2 with trailing whitespace"
+func columnWarnings(stats *TableStats, name string) []string {
+	var warnings []string
+	if f, ok := stats.TypeFit[name]; ok {
+		warnings = append(warnings, "Type fit: "+f.summary(stats.ColumnTypes[name]))
+	}
+	for _, g := range stats.CaseVariants[name] {
+		warnings = append(warnings, "Case variants: "+g.String())
+	}
+	if w, ok := stats.Padding[name]; ok {
+		warnings = append(warnings, fmt.Sprintf("Padded: %d with leading, %d with trailing whitespace", w.Leading, w.Trailing))
+	}
+	if u, ok := stats.UnicodeIssues[name]; ok {
+		warnings = append(warnings, "Unicode: "+u.summary())
+	}
+	if n, ok := stats.NonFinite[name]; ok {
+		warnings = append(warnings, fmt.Sprintf("NaN/Inf: %d NaN, %d +Inf, %d -Inf (%s)",
+			n.NaN, n.PosInf, n.NegInf, stats.SamplingConfig.NonFinite.description()))
+	}
+	for _, a := range stats.Anomalies {
+		if a.Column == name {
+			warnings = append(warnings, fmt.Sprintf("Anomaly: %s, %s", a.Kind, a.Detail))
+		}
+	}
+	if c, ok := stats.Conformance[name]; ok && c.Matched < c.Checked {
+		warnings = append(warnings, c.summary())
+	}
+	return warnings
+}
+
+// valueText formats a min or max value, which is nil for an all-null column
+func valueText(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// cell cuts s to maxCellWidth characters and keeps it on one line
+func cell(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, s)
+	if utf8.RuneCountInString(s) <= maxCellWidth {
+		return s
+	}
+	return string([]rune(s)[:maxCellWidth-3]) + "..."
+}
+
+// alignRows pads the cells of rows into columns two spaces apart
+func alignRows(rows [][]string) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, c := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(c))
+		}
+	}
+	lines := make([]string, len(rows))
+	for n, row := range rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = c + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c))
+		}
+		lines[n] = strings.TrimRight(strings.Join(cells, "  "), " ")
+	}
+	return lines
+}
@@ -1,4 +1,4 @@
-package stats
+package tablestats
 
 // TSVReader implements TableReader for TSV files
 type TSVReader struct {
@@ -0,0 +1,68 @@
+package tablestats
+
+import "fmt"
+
+// TypeFit counts the non-null values of a column that fail to parse as each
+// numeric type, so that a column of numbers with a few stray values shows how
+// far it is from being numeric
+type TypeFit struct {
+	Values  int64 `json:"values"`  // Non-null values checked
+	Int64   int64 `json:"int64"`   // Values that are not integers
+	Float64 int64 `json:"float64"` // Values that are not numbers
+}
+
+// observe counts a value classified as kind; non-finite values are numbers
+// but not integers
+func (f *TypeFit) observe(kind numberKind, finite bool) {
+	f.Values++
+	switch {
+	case kind == notNumber:
+		f.Float64++
+		f.Int64++
+	case kind == floatNumber || !finite:
+		f.Int64++
+	}
+}
+
+// Failures returns the number of values that do not parse as typ, one of the
+// column types
+func (f *TypeFit) Failures(typ string) int64 {
+	switch typ {
+	case "int64":
+		return f.Int64
+	case "float64":
+		return f.Float64
+	default:
+		return 0
+	}
+}
+
+// mixed reports whether the column holds both numbers and values that are not
+func (f *TypeFit) mixed() bool {
+	return f.Float64 > 0 && f.Float64 < f.Values
+}
+
+// fits reports whether the values that are not numbers are few enough for
+// the column to stay numeric
+func (f *TypeFit) fits(tolerance float64) bool {
+	return float64(f.Float64) <= tolerance*float64(f.Values)
+}
+
+// summary describes how well typ fits, e.g. "37 of 9963 values (0.37%) not
+// parseable" for a numeric type, or "int64 but for 37 of 9963 values (0.37%)"
+// for a string column
+func (f *TypeFit) summary(typ string) string {
+	if n := f.Failures(typ); n > 0 {
+		return fmt.Sprintf("%d of %d values (%.2f%%) not parseable", n, f.Values, f.percentage(n))
+	}
+	candidate := "float64"
+	if f.Int64 == f.Float64 {
+		candidate = "int64"
+	}
+	n := f.Failures(candidate)
+	return fmt.Sprintf("%s but for %d of %d values (%.2f%%)", candidate, n, f.Values, f.percentage(n))
+}
+
+func (f *TypeFit) percentage(n int64) float64 {
+	return float64(n) / float64(f.Values) * 100
+}
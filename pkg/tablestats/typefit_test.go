@@ -0,0 +1,76 @@
+package tablestats
+
+import "testing"
+
+func typeFitStats(t *testing.T, tolerance float64, values ...string) *TableStats {
+	t.Helper()
+	acc, err := NewTableAccumulator([]string{"id"}, SamplingConfig{TypeTolerance: tolerance})
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, v := range values {
+		acc.Add([]string{v})
+	}
+	return acc.Finalize()
+}
+
+func TestTableAccumulator_TypeFit(t *testing.T) {
+	values := []string{"10", "", "n/a", "2.5", "30", "7", "12", "5", "8", "9", "11"}
+
+	// A value that is not a number makes the column text by default
+	stats := typeFitStats(t, 0, values...)
+	if stats.ColumnTypes["id"] != "string" {
+		t.Errorf("Expected string, got %s", stats.ColumnTypes["id"])
+	}
+	fit := stats.TypeFit["id"]
+	if fit == nil || *fit != (TypeFit{Values: 10, Int64: 2, Float64: 1}) {
+		t.Fatalf("Expected 10 values, 2 not int64 and 1 not float64, got %+v", fit)
+	}
+	if got := fit.summary("string"); got != "float64 but for 1 of 10 values (10.00%)" {
+		t.Errorf("Unexpected summary: %s", got)
+	}
+	if stats.MinValues["id"] != "10" || stats.MaxValues["id"] != "n/a" {
+		t.Errorf("Expected text range 10 to n/a, got %v to %v", stats.MinValues["id"], stats.MaxValues["id"])
+	}
+
+	// Within the tolerance, the column stays numeric and the stray value is
+	// left out of its statistics
+	stats = typeFitStats(t, 0.1, values...)
+	if stats.ColumnTypes["id"] != "float64" {
+		t.Errorf("Expected float64, got %s", stats.ColumnTypes["id"])
+	}
+	if got := stats.TypeFit["id"].summary("float64"); got != "1 of 10 values (10.00%) not parseable" {
+		t.Errorf("Unexpected summary: %s", got)
+	}
+	if stats.MinValues["id"] != 2.5 || stats.MaxValues["id"] != 30.0 {
+		t.Errorf("Expected range 2.5 to 30, got %v to %v", stats.MinValues["id"], stats.MaxValues["id"])
+	}
+	if agg := stats.Aggregates["id"]; agg == nil || agg.Count != 9 {
+		t.Errorf("Expected 9 aggregated values, got %+v", agg)
+	}
+
+	// Beyond it, the column is text again
+	stats = typeFitStats(t, 0.05, values...)
+	if stats.ColumnTypes["id"] != "string" {
+		t.Errorf("Expected string, got %s", stats.ColumnTypes["id"])
+	}
+	if stats.MinValues["id"] != "10" || stats.MaxValues["id"] != "n/a" {
+		t.Errorf("Expected text range 10 to n/a, got %v to %v", stats.MinValues["id"], stats.MaxValues["id"])
+	}
+}
+
+func TestTableAccumulator_TypeFitOnlyWhenMixed(t *testing.T) {
+	for _, values := range [][]string{{"1", "2"}, {"a", "b"}} {
+		if stats := typeFitStats(t, 0, values...); stats.TypeFit != nil {
+			t.Errorf("Expected no type fit for %v, got %+v", values, stats.TypeFit["id"])
+		}
+	}
+
+	stats := typeFitStats(t, 0.5, "1", "2", "3", "x")
+	if stats.ColumnTypes["id"] != "int64" {
+		t.Errorf("Expected int64, got %s", stats.ColumnTypes["id"])
+	}
+	if got := stats.TypeFit["id"].summary("int64"); got != "1 of 4 values (25.00%) not parseable" {
+		t.Errorf("Unexpected summary: %s", got)
+	}
+}
@@ -0,0 +1,144 @@
+package tablestats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxKeyCollisions caps how many duplicated keys are kept as examples
+const maxKeyCollisions = 5
+
+// keySeparator joins the parts of a compound key; it cannot occur in text fields
+const keySeparator = "\x00"
+
+// KeyCollision is a key value shared by more than one row
+type KeyCollision struct {
+	Values []string `json:"values"`
+	Rows   int64    `json:"rows"`
+}
+
+// KeyCheck is the outcome of checking that a column combination is unique.
+// A sampled check only sees part of the file and may count a row twice when
+// two random positions read overlapping stretches, so only an exact check
+// proves or disproves uniqueness.
+type KeyCheck struct {
+	Columns       []string       `json:"columns"`
+	CheckedRows   int64          `json:"checked_rows"`
+	NullRows      int64          `json:"null_rows"`      // Rows with a null key part, which are not checked
+	DuplicateRows int64          `json:"duplicate_rows"` // Rows whose key an earlier row already had
+	DuplicateKeys int64          `json:"duplicate_keys"` // Distinct keys held by more than one row
+	Exact         bool           `json:"exact"`          // Every row was checked rather than a sample
+	Collisions    []KeyCollision `json:"collisions,omitempty"`
+}
+
+// keyIndexes returns the header positions of the UniqueKey columns
+func (c SamplingConfig) keyIndexes(header []string) ([]int, error) {
+	var indexes []int
+	for _, name := range c.UniqueKey {
+		idx := -1
+		for i, h := range header {
+			if h == name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("unique key column %q: %w", name, ErrUnknownColumn)
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, nil
+}
+
+// keyTracker counts the rows of every key value seen. Its memory grows with
+// the number of distinct keys, so it is only kept when a key is requested.
+type keyTracker struct {
+	columns []string
+	indexes []int
+	counts  map[string]int64
+	nulls   int64
+	parts   []string
+}
+
+func newKeyTracker(columns []string, indexes []int) *keyTracker {
+	return &keyTracker{
+		columns: columns,
+		indexes: indexes,
+		counts:  make(map[string]int64),
+		parts:   make([]string, len(indexes)),
+	}
+}
+
+func (k *keyTracker) add(record []string) {
+	for i, idx := range k.indexes {
+		value := ""
+		if idx < len(record) {
+			value = strings.TrimSpace(record[idx])
+		}
+		if isNullValue(value) {
+			k.nulls++
+			return
+		}
+		k.parts[i] = value
+	}
+	k.counts[strings.Join(k.parts, keySeparator)]++
+}
+
+func (k *keyTracker) result(rows int64, exact bool) *KeyCheck {
+	check := &KeyCheck{
+		Columns:     k.columns,
+		CheckedRows: rows - k.nulls,
+		NullRows:    k.nulls,
+		Exact:       exact,
+	}
+	for key, count := range k.counts {
+		if count > 1 {
+			check.DuplicateRows += count - 1
+			check.DuplicateKeys++
+			check.Collisions = append(check.Collisions, KeyCollision{
+				Values: strings.Split(key, keySeparator),
+				Rows:   count,
+			})
+		}
+	}
+
+	// Report the most repeated keys first
+	sort.Slice(check.Collisions, func(i, j int) bool {
+		a, b := check.Collisions[i], check.Collisions[j]
+		if a.Rows != b.Rows {
+			return a.Rows > b.Rows
+		}
+		return strings.Join(a.Values, keySeparator) < strings.Join(b.Values, keySeparator)
+	})
+	if len(check.Collisions) > maxKeyCollisions {
+		check.Collisions = check.Collisions[:maxKeyCollisions]
+	}
+	return check
+}
+
+// Unique reports whether no two checked rows share a key
+func (k *KeyCheck) Unique() bool {
+	return k.DuplicateRows == 0
+}
+
+// Failures describes a failed check in one line, for notifications
+func (k *KeyCheck) Failures() []string {
+	if k.Unique() {
+		return nil
+	}
+	return []string{fmt.Sprintf("unique key (%s): %s", strings.Join(k.Columns, ", "), k.summary())}
+}
+
+// summary describes the outcome in one line
+func (k *KeyCheck) summary() string {
+	mode := "sampled"
+	if k.Exact {
+		mode = "all"
+	}
+	if k.Unique() {
+		return fmt.Sprintf("unique across %d rows (%s)", k.CheckedRows, mode)
+	}
+	return fmt.Sprintf("%d duplicate rows across %d keys in %d rows (%s)",
+		k.DuplicateRows, k.DuplicateKeys, k.CheckedRows, mode)
+}
@@ -0,0 +1,100 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTableAccumulator_UniqueKey(t *testing.T) {
+	header := []string{"order_id", "line_no", "sku"}
+	records := [][]string{
+		{"1", "1", "a"},
+		{"1", "2", "b"},
+		{"1", " 1 ", "c"},
+		{"2", "1", "d"},
+		{"2", "1", "e"},
+		{"2", "1", "f"},
+		{"3", "", "g"},
+	}
+	config := SamplingConfig{UniqueKey: []string{"order_id", "line_no"}, Columns: []string{"sku"}}
+
+	acc, err := NewTableAccumulator(header, config)
+	if err != nil {
+		t.Fatalf("NewTableAccumulator failed: %v", err)
+	}
+	for _, record := range records {
+		acc.Add(record)
+	}
+	key := acc.finalize(int64(len(records))).UniqueKey
+
+	if key == nil {
+		t.Fatal("Expected a unique key check")
+	}
+	if key.CheckedRows != 6 || key.NullRows != 1 || key.DuplicateRows != 3 || key.DuplicateKeys != 2 {
+		t.Errorf("Unexpected counts %+v", key)
+	}
+	if !key.Exact || key.Unique() {
+		t.Errorf("Expected an exact failed check, got %+v", key)
+	}
+	expected := []KeyCollision{{Values: []string{"2", "1"}, Rows: 3}, {Values: []string{"1", "1"}, Rows: 2}}
+	if !reflect.DeepEqual(key.Collisions, expected) {
+		t.Errorf("Expected collisions %v, got %v", expected, key.Collisions)
+	}
+
+	if _, err := NewTableAccumulator(header, SamplingConfig{UniqueKey: []string{"line"}}); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestReadTable_UniqueKey(t *testing.T) {
+	// Every row repeats the key, so any position that reads two rows finds a duplicate
+	data := "order_id,line_no,sku\n" + strings.Repeat("1,1,a\n", 500)
+	reader := NewCSVReader(',')
+
+	// Key columns are kept when only other columns are profiled, both when the
+	// whole input is split and when rows are sampled
+	tests := []struct {
+		name        string
+		maxFileSize int64
+	}{
+		{"full", 1 << 20},
+		{"sampled", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := SamplingConfig{
+				MaxFileSize:     tt.maxFileSize,
+				SampleSize:      10,
+				RandomPositions: 5,
+				Columns:         []string{"sku"},
+				UniqueKey:       []string{"order_id", "line_no"},
+			}
+			stats, err := reader.ReadTableFrom(context.Background(), strings.NewReader(data), int64(len(data)), config)
+			if err != nil {
+				t.Fatalf("ReadTableFrom failed: %v", err)
+			}
+			if stats.UniqueKey == nil || stats.UniqueKey.DuplicateRows == 0 {
+				t.Errorf("Expected duplicate rows, got %+v", stats.UniqueKey)
+			}
+			if !reflect.DeepEqual(stats.ColumnNames, []string{"sku"}) {
+				t.Errorf("Expected only sku to be profiled, got %v", stats.ColumnNames)
+			}
+		})
+	}
+}
+
+func TestTableStats_MaskUniqueKey(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header: []string{"email", "day"},
+	}, [][]string{{"a@example.com", "1"}, {"a@example.com", "1"}}), SamplingConfig{UniqueKey: []string{"email", "day"}})
+
+	if err := stats.Mask([]string{"email"}, Masking{}); err != nil {
+		t.Fatalf("Mask failed: %v", err)
+	}
+	if got := stats.UniqueKey.Collisions[0].Values; !reflect.DeepEqual(got, []string{redacted, "1"}) {
+		t.Errorf("Expected the email part to be masked, got %v", got)
+	}
+}
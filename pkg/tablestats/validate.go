@@ -0,0 +1,218 @@
+package tablestats
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/apache/arrow-go/v18/arrow/array"
+)
+
+// maxViolationExamples caps how many offending values are kept per violation
+const maxViolationExamples = 5
+
+// ViolationExample is an offending value and the row it was found in
+type ViolationExample struct {
+	Row   int64 // 1-based position among the checked rows
+	Value string
+}
+
+// Violation groups every failure of one rule in one column
+type Violation struct {
+	Column   string
+	Rule     string // missing, type, null, min, max, pattern or allowed; Check uses the rule names of its file
+	Count    int64
+	Examples []ViolationExample
+}
+
+// ValidationReport is the outcome of checking a sample against a schema
+type ValidationReport struct {
+	CheckedRows   int64
+	CheckedValues int64
+	Exact         bool // All rows were checked rather than a sample
+	Violations    []*Violation
+}
+
+// Validate checks every value of the sample against the schema
+func Validate(sample *Sample, schema *Schema) *ValidationReport {
+	report := &ValidationReport{
+		CheckedRows: int64(sample.Len()),
+		Exact:       sample.Exact,
+	}
+
+	positions := make(map[string]int, len(sample.Header))
+	for i, name := range sample.Header {
+		positions[name] = i
+	}
+
+	for i := range schema.Columns {
+		col := &schema.Columns[i]
+		colIdx, ok := positions[col.Name]
+		if !ok {
+			if !col.Optional {
+				report.Violations = append(report.Violations, &Violation{
+					Column: col.Name,
+					Rule:   "missing",
+					Count:  1,
+				})
+			}
+			continue
+		}
+		report.validateColumn(sample.Columns[colIdx], col)
+	}
+
+	return report
+}
+
+func (r *ValidationReport) validateColumn(column *array.LargeString, col *ColumnSchema) {
+	violations := make(map[string]*Violation)
+	var order []string
+	record := func(rule string, row int, value string) {
+		v, ok := violations[rule]
+		if !ok {
+			v = &Violation{Column: col.Name, Rule: rule}
+			violations[rule] = v
+			order = append(order, rule)
+		}
+		v.Count++
+		if len(v.Examples) < maxViolationExamples {
+			v.Examples = append(v.Examples, ViolationExample{Row: int64(row + 1), Value: value})
+		}
+	}
+
+	var allowed map[string]bool
+	if len(col.Allowed) > 0 {
+		allowed = make(map[string]bool, len(col.Allowed))
+		for _, v := range col.Allowed {
+			allowed[v] = true
+		}
+	}
+
+	for row := 0; row < column.Len(); row++ {
+		r.CheckedValues++
+
+		value := strings.TrimSpace(column.Value(row))
+		if isNullValue(value) {
+			if !col.IsNullable() {
+				record("null", row, value)
+			}
+			continue
+		}
+
+		if !matchesType(value, col.Type) {
+			record("type", row, value)
+			continue
+		}
+		if col.Min != nil || col.Max != nil {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				if col.Min != nil && number < *col.Min {
+					record("min", row, value)
+				}
+				if col.Max != nil && number > *col.Max {
+					record("max", row, value)
+				}
+			}
+		}
+		if col.pattern != nil && !col.pattern.MatchString(value) {
+			record("pattern", row, value)
+		}
+		if allowed != nil && !allowed[value] {
+			record("allowed", row, value)
+		}
+	}
+
+	for _, rule := range order {
+		r.Violations = append(r.Violations, violations[rule])
+	}
+}
+
+func matchesType(value, typ string) bool {
+	switch typ {
+	case "int64":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case "float64":
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// TotalViolations returns the number of offending values across all rules
+func (r *ValidationReport) TotalViolations() int64 {
+	var total int64
+	for _, v := range r.Violations {
+		total += v.Count
+	}
+	return total
+}
+
+// ViolationPct returns offending values as a percentage of checked values
+func (r *ValidationReport) ViolationPct() float64 {
+	if r.CheckedValues == 0 {
+		return 0
+	}
+	return float64(r.TotalViolations()) / float64(r.CheckedValues) * 100
+}
+
+// Failed reports whether the violations exceed the thresholds.
+// Missing required columns always fail the validation.
+func (r *ValidationReport) Failed(th SchemaThresholds) bool {
+	for _, v := range r.Violations {
+		if v.Rule == "missing" {
+			return true
+		}
+	}
+	if th.MaxViolationPct > 0 {
+		return r.ViolationPct() > th.MaxViolationPct
+	}
+	return r.TotalViolations() > th.MaxViolations
+}
+
+// Failures describes each violation in one line, for notifications
+func (r *ValidationReport) Failures() []string {
+	lines := make([]string, 0, len(r.Violations))
+	for _, v := range r.Violations {
+		if v.Rule == "missing" {
+			lines = append(lines, fmt.Sprintf("%s: required column is missing", v.Column))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s violated by %d values", v.Column, v.Rule, v.Count))
+	}
+	return lines
+}
+
+// WriteText writes the violations with their example rows and whether the
+// report passes th
+func (r *ValidationReport) WriteText(w io.Writer, th SchemaThresholds) error {
+	ew := &errWriter{w: w}
+	ew.printf("=== Validation Report ===\n")
+	mode := "sampled"
+	if r.Exact {
+		mode = "all"
+	}
+	ew.printf("Checked Rows: %d (%s)\n", r.CheckedRows, mode)
+	ew.printf("Violations: %d (%.2f%% of %d values)\n",
+		r.TotalViolations(), r.ViolationPct(), r.CheckedValues)
+
+	for _, v := range r.Violations {
+		if v.Rule == "missing" {
+			ew.printf("  %s: required column is missing\n", v.Column)
+			continue
+		}
+		ew.printf("  %s: %s violated by %d values\n", v.Column, v.Rule, v.Count)
+		for _, ex := range v.Examples {
+			ew.printf("    row %d: %q\n", ex.Row, ex.Value)
+		}
+	}
+
+	if r.Failed(th) {
+		ew.printf("Result: FAIL\n")
+	} else {
+		ew.printf("Result: PASS\n")
+	}
+	ew.printf("\n")
+	return ew.err
+}
@@ -0,0 +1,197 @@
+package tablestats
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSchema(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+	return path
+}
+
+func TestLoadSchema(t *testing.T) {
+	path := writeSchema(t, `
+columns:
+  - name: id
+    type: int64
+    nullable: false
+  - name: status
+    allowed: [active, inactive]
+thresholds:
+  max_violation_pct: 1.5
+`)
+
+	schema, err := LoadSchema(path)
+	if err != nil {
+		t.Fatalf("LoadSchema failed: %v", err)
+	}
+	if len(schema.Columns) != 2 {
+		t.Fatalf("Expected 2 columns, got %d", len(schema.Columns))
+	}
+	if schema.Columns[0].IsNullable() {
+		t.Error("Expected id to be non-nullable")
+	}
+	if !schema.Columns[1].IsNullable() {
+		t.Error("Expected status to be nullable by default")
+	}
+	if schema.Thresholds.MaxViolationPct != 1.5 {
+		t.Errorf("Expected max_violation_pct 1.5, got %f", schema.Thresholds.MaxViolationPct)
+	}
+
+	if _, err := LoadSchema(writeSchema(t, "columns:\n  - name: id\n    type: uuid\n")); err == nil {
+		t.Error("Expected error for unsupported type")
+	}
+	if _, err := LoadSchema(writeSchema(t, "columns:\n  - name: id\n    pattern: '('\n")); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := LoadSchema(writeSchema(t, `
+columns:
+  - name: id
+    type: int64
+    nullable: false
+  - name: age
+    type: int64
+    min: 0
+    max: 120
+  - name: sku
+    pattern: '^SKU-\d{3}$'
+  - name: status
+    allowed: [active, inactive]
+  - name: email
+  - name: notes
+    optional: true
+`))
+	if err != nil {
+		t.Fatalf("LoadSchema failed: %v", err)
+	}
+
+	sample := withRecords(&Sample{
+		Header: []string{"id", "age", "sku", "status"},
+		Exact:  true,
+	}, [][]string{
+		{"1", "30", "SKU-001", "active"},
+		{"", "-1", "SKU-002", "inactive"},
+		{"x", "150", "sku-3", "deleted"},
+		{"4", "abc", "SKU-004", "active"},
+	})
+
+	report := Validate(sample, schema)
+
+	counts := make(map[string]int64)
+	for _, v := range report.Violations {
+		counts[v.Column+"."+v.Rule] = v.Count
+	}
+	expected := map[string]int64{
+		"id.null":        1,
+		"id.type":        1,
+		"age.min":        1,
+		"age.max":        1,
+		"age.type":       1,
+		"sku.pattern":    1,
+		"status.allowed": 1,
+		"email.missing":  1,
+	}
+	for key, want := range expected {
+		if counts[key] != want {
+			t.Errorf("Expected %d violations for %s, got %d", want, key, counts[key])
+		}
+	}
+	if len(counts) != len(expected) {
+		t.Errorf("Expected %d violation groups, got %v", len(expected), counts)
+	}
+
+	for _, v := range report.Violations {
+		if v.Column == "sku" && (len(v.Examples) != 1 || v.Examples[0].Row != 3 || v.Examples[0].Value != "sku-3") {
+			t.Errorf("Unexpected sku examples %v", v.Examples)
+		}
+	}
+
+	if !report.Failed(SchemaThresholds{MaxViolationPct: 100}) {
+		t.Error("Expected missing column to fail validation")
+	}
+}
+
+func TestValidationReportFailed(t *testing.T) {
+	report := &ValidationReport{
+		CheckedValues: 100,
+		Violations:    []*Violation{{Column: "a", Rule: "type", Count: 2}},
+	}
+
+	if !report.Failed(SchemaThresholds{}) {
+		t.Error("Expected failure with zero tolerance")
+	}
+	if report.Failed(SchemaThresholds{MaxViolations: 2}) {
+		t.Error("Expected pass with max_violations 2")
+	}
+	if report.Failed(SchemaThresholds{MaxViolationPct: 5}) {
+		t.Error("Expected pass with max_violation_pct 5")
+	}
+	if !report.Failed(SchemaThresholds{MaxViolationPct: 1}) {
+		t.Error("Expected failure with max_violation_pct 1")
+	}
+}
+
+func TestValidationReport_WriteText(t *testing.T) {
+	report := &ValidationReport{
+		CheckedRows:   50,
+		CheckedValues: 100,
+		Exact:         true,
+		Violations: []*Violation{
+			{Column: "a", Rule: "type", Count: 2, Examples: []ViolationExample{{Row: 3, Value: "x"}}},
+			{Column: "b", Rule: "missing", Count: 1},
+		},
+	}
+	var b strings.Builder
+	if err := report.WriteText(&b, SchemaThresholds{}); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	expected := "=== Validation Report ===\n" +
+		"Checked Rows: 50 (all)\n" +
+		"Violations: 3 (3.00% of 100 values)\n" +
+		"  a: type violated by 2 values\n" +
+		"    row 3: \"x\"\n" +
+		"  b: required column is missing\n" +
+		"Result: FAIL\n\n"
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestInferSchema(t *testing.T) {
+	sample := withRecords(&Sample{
+		Header: []string{"id", "score", "name", "empty"},
+	}, [][]string{
+		{"1", "1.5", "Alice", ""},
+		{"2", "2", "", "NULL"},
+		{"3", "", "Carol"},
+	})
+
+	schema := InferSchema(sample)
+
+	expected := []struct {
+		name     string
+		typ      string
+		nullable bool
+	}{
+		{"id", "int64", false},
+		{"score", "float64", true},
+		{"name", "string", true},
+		{"empty", "", true},
+	}
+	for i, want := range expected {
+		col := schema.Columns[i]
+		if col.Name != want.name || col.Type != want.typ || col.IsNullable() != want.nullable {
+			t.Errorf("Column %d = {%s %s %v}, want {%s %s %v}", i,
+				col.Name, col.Type, col.IsNullable(), want.name, want.typ, want.nullable)
+		}
+	}
+}
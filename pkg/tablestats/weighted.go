@@ -0,0 +1,148 @@
+package tablestats
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// weightedOversampling is how many uniform candidates are drawn per final row
+// when sampling proportionally to a weight column
+const weightedOversampling = 4
+
+// weightedSample draws up to size records with probability proportional to the
+// value in column weightIdx (Efraimidis-Spirakis A-Res). It returns the selected
+// records together with their inverse-probability weights, which make aggregates
+// computed from the sample unbiased for the whole table. Rows with a missing,
+// non-numeric or non-positive weight can never be selected.
+func weightedSample(records [][]string, weightIdx int, size int) ([][]string, []float64) {
+	type candidate struct {
+		record []string
+		weight float64
+		key    float64
+	}
+
+	candidates := make([]candidate, 0, len(records))
+	for _, record := range records {
+		weight, ok := parseWeight(record, weightIdx)
+		if !ok {
+			continue
+		}
+		key := math.Pow(rand.Float64(), 1/weight)
+		candidates = append(candidates, candidate{record: record, weight: weight, key: key})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].key > candidates[j].key
+	})
+	if len(candidates) > size {
+		candidates = candidates[:size]
+	}
+
+	selected := make([][]string, len(candidates))
+	weights := make([]float64, len(candidates))
+	for i, c := range candidates {
+		selected[i] = c.record
+		weights[i] = 1 / c.weight
+	}
+
+	return selected, weights
+}
+
+// ShrinkWeighted reduces the sample to at most n rows drawn with probability
+// proportional to the numeric column named column, as sampled reads of a
+// weight column do, and weights them accordingly. It is for samples read in
+// full; samples of at most n rows are kept as they are.
+func (s *Sample) ShrinkWeighted(n int, column string) error {
+	weightIdx := -1
+	for i, name := range s.Header {
+		if name == column {
+			weightIdx = i
+			break
+		}
+	}
+	if weightIdx < 0 {
+		return fmt.Errorf("weight column %q: %w", column, ErrUnknownColumn)
+	}
+	if n < 0 || s.Len() <= n {
+		return nil
+	}
+	records, weights := weightedSample(s.Records(), weightIdx, n)
+	s.Columns = buildColumns(len(s.Header), records)
+	s.Weights = weights
+	s.Exact = false
+	return nil
+}
+
+func parseWeight(record []string, weightIdx int) (float64, bool) {
+	if weightIdx >= len(record) {
+		return 0, false
+	}
+	weight, err := strconv.ParseFloat(strings.TrimSpace(record[weightIdx]), 64)
+	if err != nil || weight <= 0 || math.IsInf(weight, 0) || math.IsNaN(weight) {
+		return 0, false
+	}
+	return weight, true
+}
+
+// calculateWeightedAggregates computes aggregates where each value counts in
+// proportion to its weight. Count and Sum describe the observed values, while
+// Mean, Variance and Percentiles are weighted estimates.
+func calculateWeightedAggregates(values []float64, weights []float64) *AggregateStats {
+	if len(values) == 0 {
+		return &AggregateStats{}
+	}
+
+	type point struct {
+		value  float64
+		weight float64
+	}
+
+	points := make([]point, len(values))
+	var sum, weightedSum compensatedSum
+	totalWeight := 0.0
+	for i, v := range values {
+		points[i] = point{value: v, weight: weights[i]}
+		sum.add(v)
+		totalWeight += weights[i]
+		weightedSum.add(weights[i] * v)
+	}
+	mean := weightedSum.value() / totalWeight
+
+	variance := 0.0
+	for _, p := range points {
+		variance += p.weight * (p.value - mean) * (p.value - mean)
+	}
+	variance /= totalWeight
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].value < points[j].value
+	})
+
+	percentiles := make(map[int]float64)
+	for _, p := range []int{25, 50, 75, 90, 95, 99} {
+		target := float64(p) / 100.0 * totalWeight
+		cumulative := 0.0
+		for _, pt := range points {
+			cumulative += pt.weight
+			if cumulative >= target {
+				percentiles[p] = pt.value
+				break
+			}
+		}
+	}
+
+	return &AggregateStats{
+		Count:       int64(len(values)),
+		Sum:         sum.value(),
+		Mean:        mean,
+		Median:      percentiles[50],
+		StdDev:      math.Sqrt(variance),
+		Variance:    variance,
+		Percentiles: percentiles,
+		Histogram:   histogram(values, weights, int64(len(values))),
+	}
+}
@@ -0,0 +1,156 @@
+package tablestats
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestWeightedSample(t *testing.T) {
+	records := [][]string{
+		{"a", "1"},
+		{"b", "0"},
+		{"c", "abc"},
+		{"d", "2"},
+		{"e", "4"},
+	}
+
+	selected, weights := weightedSample(records, 1, 10)
+
+	// Rows with zero or non-numeric weights can never be selected
+	if len(selected) != 3 {
+		t.Fatalf("Expected 3 selected records, got %d", len(selected))
+	}
+	for i, record := range selected {
+		if record[0] == "b" || record[0] == "c" {
+			t.Errorf("Record %v should not have been selected", record)
+		}
+		w, _ := parseWeight(record, 1)
+		if !floatEqual(weights[i], 1/w) {
+			t.Errorf("Expected inverse weight %f for %v, got %f", 1/w, record, weights[i])
+		}
+	}
+
+	selected, _ = weightedSample(records, 1, 2)
+	if len(selected) != 2 {
+		t.Errorf("Expected sample trimmed to 2 records, got %d", len(selected))
+	}
+}
+
+func TestCalculateWeightedAggregates(t *testing.T) {
+	values := []float64{1, 2, 3, 4}
+
+	// Equal weights must match the unweighted mean and variance
+	equal := calculateWeightedAggregates(values, []float64{1, 1, 1, 1})
+	plain := calculateAggregates(values)
+	if !floatEqual(equal.Mean, plain.Mean) {
+		t.Errorf("Mean = %f, want %f", equal.Mean, plain.Mean)
+	}
+	if !floatEqual(equal.Variance, plain.Variance) {
+		t.Errorf("Variance = %f, want %f", equal.Variance, plain.Variance)
+	}
+
+	skewed := calculateWeightedAggregates(values, []float64{1, 1, 1, 7})
+	if !floatEqual(skewed.Mean, 3.4) {
+		t.Errorf("Mean = %f, want 3.4", skewed.Mean)
+	}
+	if skewed.Median != 4 {
+		t.Errorf("Median = %f, want 4", skewed.Median)
+	}
+	if skewed.Sum != 10 {
+		t.Errorf("Sum = %f, want 10", skewed.Sum)
+	}
+}
+
+func TestReadTable_WeightedSampling(t *testing.T) {
+	tmpFile := createLargeCSV(t, 10000)
+	defer os.Remove(tmpFile)
+
+	reader := NewCSVReader(',')
+	config := SamplingConfig{
+		MaxFileSize:     1000, // Force sampling
+		SampleSize:      100,
+		RandomPositions: 5,
+		WeightColumn:    "value",
+	}
+
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+
+	if stats.RowCount > int64(config.SampleSize) {
+		t.Errorf("Expected at most %d sampled rows, got %d", config.SampleSize, stats.RowCount)
+	}
+	agg := stats.Aggregates["value"]
+	if agg == nil {
+		t.Fatal("Expected aggregates for value column")
+	}
+	if agg.EstimatedTotal <= agg.Sum {
+		t.Errorf("Expected estimated total (%f) to exceed sampled sum (%f)", agg.EstimatedTotal, agg.Sum)
+	}
+
+	config.WeightColumn = "missing"
+	if _, err := reader.ReadTable(context.Background(), tmpFile, config); err == nil {
+		t.Error("Expected error for unknown weight column")
+	}
+}
+
+func TestReadTable_WeightedFullRead(t *testing.T) {
+	tmpFile := createLargeCSV(t, 2000)
+	reader := NewCSVReader(',')
+	config := SamplingConfig{MaxFileSize: 1024 * 1024, SampleSize: 100, RandomPositions: 5, WeightColumn: "value"}
+
+	// Files read in full and row windows are profiled exactly, without weights
+	stats, err := reader.ReadTable(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadTable failed: %v", err)
+	}
+	if stats.RowCount != 2000 || stats.EstimatedRows != 2000 {
+		t.Errorf("Expected all 2000 rows, got %d of %d", stats.RowCount, stats.EstimatedRows)
+	}
+	config.Limit = 1000
+	sample, err := reader.ReadSample(context.Background(), tmpFile, config)
+	if err != nil {
+		t.Fatalf("ReadSample failed: %v", err)
+	}
+	if sample.Len() != 1000 || sample.Weights != nil || !sample.Exact {
+		t.Errorf("Expected the 1000 rows of the window unweighted, got %d rows, weights %v, exact %v",
+			sample.Len(), sample.Weights != nil, sample.Exact)
+	}
+}
+
+func TestSample_ShrinkWeighted(t *testing.T) {
+	var records [][]string
+	for i := 1; i <= 2000; i++ {
+		records = append(records, []string{strconv.Itoa(i), strconv.Itoa(i)})
+	}
+	sample := withRecords(&Sample{Header: []string{"id", "value"}, EstimatedRows: 2000, Exact: true}, records)
+
+	if err := sample.ShrinkWeighted(100, "value"); err != nil {
+		t.Fatalf("ShrinkWeighted failed: %v", err)
+	}
+	if sample.Len() != 100 || len(sample.Weights) != 100 || sample.Exact || sample.EstimatedRows != 2000 {
+		t.Fatalf("Expected 100 weighted rows of 2000, got %d rows, %d weights, exact %v, %d estimated",
+			sample.Len(), len(sample.Weights), sample.Exact, sample.EstimatedRows)
+	}
+	// Rows are picked proportionally to their value, so large values are overrepresented
+	var sum float64
+	for _, record := range sample.Records() {
+		value, _ := parseWeight(record, 1)
+		sum += value
+	}
+	if mean := sum / 100; mean <= 1000.5 {
+		t.Errorf("Expected the sampled values to average more than the table's, got %f", mean)
+	}
+
+	// Samples that already fit are kept whole
+	if err := sample.ShrinkWeighted(500, "value"); err != nil || sample.Len() != 100 {
+		t.Errorf("Expected shrinking to a larger size to be a no-op, got %d rows, %v", sample.Len(), err)
+	}
+	if err := sample.ShrinkWeighted(10, "missing"); !errors.Is(err, ErrUnknownColumn) {
+		t.Errorf("Expected ErrUnknownColumn, got %v", err)
+	}
+}
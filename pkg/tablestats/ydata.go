@@ -0,0 +1,255 @@
+package tablestats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ydataMissingAlert is the share of missing values above which a column gets
+// a MISSING alert
+const ydataMissingAlert = 0.05
+
+// YDataRenderer writes the profile shaped like the JSON report of
+// ydata-profiling (formerly pandas-profiling), so that dashboards and
+// notebook tooling built around that format can read it. Only the sections
+// the profile has data for are written: analysis, table, variables, alerts
+// and sample; ratios are between 0 and 1 as in ydata-profiling.
+type YDataRenderer struct {
+	Title string    // Empty uses "Profiling Report", ydata-profiling's default
+	Time  time.Time // Reported as the analysis date; zero uses the current time
+}
+
+func (r *YDataRenderer) Render(w io.Writer, stats *TableStats) error {
+	date := r.Time
+	if date.IsZero() {
+		date = time.Now()
+	}
+	date = date.UTC()
+	title := r.Title
+	if title == "" {
+		title = "Profiling Report"
+	}
+
+	report := jsonObject{
+		{"analysis", jsonObject{
+			{"title", title},
+			{"date_start", date},
+			{"date_end", date},
+		}},
+		{"time_index_analysis", nil},
+		{"table", ydataTable(stats)},
+		{"variables", ydataVariables(stats)},
+		{"alerts", ydataAlerts(stats)},
+		{"package", jsonObject{
+			{"generator", "gotablestats"},
+			{"schema_version", CurrentSchemaVersion},
+		}},
+		{"sample", ydataSample(stats)},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode profile: %w", err)
+	}
+	return nil
+}
+
+// ydataType maps a column to the variable types of ydata-profiling. String
+// columns with few distinct values are categorical, other strings text.
+func ydataType(stats *TableStats, name string) string {
+	switch {
+	case stats.ColumnTypes[name] == "int64" || stats.ColumnTypes[name] == "float64":
+		return "Numeric"
+	case stats.NullCounts[name] == stats.RowCount:
+		return "Unsupported"
+	case stats.Categories[name] != nil:
+		return "Categorical"
+	default:
+		return "Text"
+	}
+}
+
+func ydataTable(stats *TableStats) jsonObject {
+	var missingCells int64
+	withMissing, allMissing := 0, 0
+	types := jsonObject{}
+	counts := make(map[string]int)
+	for _, name := range stats.ColumnNames {
+		missing := stats.NullCounts[name]
+		missingCells += missing
+		if missing > 0 {
+			withMissing++
+		}
+		if missing == stats.RowCount && missing > 0 {
+			allMissing++
+		}
+		typ := ydataType(stats, name)
+		if counts[typ] == 0 {
+			types = append(types, jsonField{typ, nil})
+		}
+		counts[typ]++
+	}
+	for i := range types {
+		types[i].value = counts[types[i].key]
+	}
+
+	table := jsonObject{
+		{"n", stats.RowCount},
+		{"n_var", stats.ColumnCount},
+		{"n_cells_missing", missingCells},
+		{"n_vars_with_missing", withMissing},
+		{"n_vars_all_missing", allMissing},
+		{"p_cells_missing", ratio(float64(missingCells), float64(stats.RowCount)*float64(stats.ColumnCount))},
+		{"types", types},
+	}
+	if stats.Memory != nil {
+		table = append(table, jsonField{"memory_size", stats.Memory.Total})
+	}
+	if stats.FileInfo != nil {
+		table = append(table, jsonField{"file", stats.FileInfo})
+	}
+	return table
+}
+
+func ydataVariables(stats *TableStats) jsonObject {
+	variables := make(jsonObject, 0, len(stats.ColumnNames))
+	for _, name := range stats.ColumnNames {
+		n := stats.RowCount
+		missing := stats.NullCounts[name]
+		count := n - missing
+		distinct := stats.DistinctCounts[name]
+		v := jsonObject{
+			{"type", ydataType(stats, name)},
+			{"n", n},
+			{"count", count},
+			{"n_missing", missing},
+			{"p_missing", ratio(float64(missing), float64(n))},
+			{"n_distinct", distinct},
+			{"p_distinct", ratio(float64(distinct), float64(count))},
+			{"is_unique", count > 0 && distinct == count},
+			{"hashable", true},
+		}
+		if stats.Memory != nil {
+			v = append(v, jsonField{"memory_size", stats.Memory.Columns[name]})
+		}
+
+		if agg := stats.Aggregates[name]; agg != nil {
+			v = append(v,
+				jsonField{"mean", finite(agg.Mean)},
+				jsonField{"std", finite(agg.StdDev)},
+				jsonField{"variance", finite(agg.Variance)},
+				jsonField{"sum", finite(agg.Sum)},
+				jsonField{"min", stats.MinValues[name]},
+				jsonField{"max", stats.MaxValues[name]})
+			lo, okLo := numericValue(stats.MinValues[name])
+			hi, okHi := numericValue(stats.MaxValues[name])
+			if okLo && okHi {
+				v = append(v, jsonField{"range", finite(hi - lo)})
+			}
+			for _, p := range []int{25, 50, 75, 95} {
+				v = append(v, jsonField{fmt.Sprintf("%d%%", p), finite(agg.Percentiles[p])})
+			}
+			v = append(v, jsonField{"iqr", finite(agg.Percentiles[75] - agg.Percentiles[25])})
+			if agg.Mean != 0 {
+				v = append(v, jsonField{"cv", finite(agg.StdDev / agg.Mean)})
+			}
+		}
+		if values := stats.Categories[name]; values != nil {
+			// Counts per value are not kept, only which values occur
+			v = append(v, jsonField{"first_rows", values})
+		}
+		variables = append(variables, jsonField{name, v})
+	}
+	return variables
+}
+
+// ydataAlerts lists alerts like ydata-profiling's "[TYPE] alert on column x"
+func ydataAlerts(stats *TableStats) []string {
+	alerts := []string{}
+	for _, name := range stats.ColumnNames {
+		count := stats.RowCount - stats.NullCounts[name]
+		switch {
+		case count > 0 && stats.DistinctCounts[name] == 1:
+			alerts = append(alerts, "[CONSTANT] alert on column "+name)
+		case count > 1 && stats.DistinctCounts[name] == count:
+			alerts = append(alerts, "[UNIQUE] alert on column "+name)
+		}
+		if ratio(float64(stats.NullCounts[name]), float64(stats.RowCount)) > ydataMissingAlert {
+			alerts = append(alerts, "[MISSING] alert on column "+name)
+		}
+	}
+	return alerts
+}
+
+// ydataSample returns the example rows as the "head" sample, one object per row
+func ydataSample(stats *TableStats) []jsonObject {
+	if len(stats.SampleData) == 0 {
+		return []jsonObject{}
+	}
+	rows := make([]jsonObject, len(stats.SampleData))
+	for i, record := range stats.SampleData {
+		names := stats.sampleColumnNames()
+		row := make(jsonObject, 0, len(names))
+		for j, name := range names {
+			var value any
+			if j < len(record) {
+				value = record[j]
+			}
+			row = append(row, jsonField{name, value})
+		}
+		rows[i] = row
+	}
+	return []jsonObject{{{"id", "head"}, {"name", "First rows"}, {"data", rows}}}
+}
+
+func ratio(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole
+}
+
+// finite returns v, or nil for NaN and infinities, which JSON cannot hold
+func finite(v float64) any {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return v
+}
+
+// jsonObject is a JSON object that keeps its keys in order, like the column
+// order of ydata-profiling's reports
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value any
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package tablestats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYDataRenderer(t *testing.T) {
+	stats := AnalyzeSample(withRecords(&Sample{
+		Header:        []string{"id", "status", "constant"},
+		EstimatedRows: 4,
+		Exact:         true,
+	}, [][]string{{"1", "open", "x"}, {"2", "", "x"}, {"3", "closed", "x"}, {"4", "open", "x"}}), SamplingConfig{})
+
+	var buf bytes.Buffer
+	r := &YDataRenderer{Title: "orders.csv", Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	if err := r.Render(&buf, stats); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	var report struct {
+		Analysis struct {
+			Title     string `json:"title"`
+			DateStart string `json:"date_start"`
+		} `json:"analysis"`
+		Table struct {
+			N             int64          `json:"n"`
+			NVar          int            `json:"n_var"`
+			NCellsMissing int64          `json:"n_cells_missing"`
+			PCellsMissing float64        `json:"p_cells_missing"`
+			Types         map[string]int `json:"types"`
+		} `json:"table"`
+		Variables map[string]map[string]any `json:"variables"`
+		Alerts    []string                  `json:"alerts"`
+		Sample    []struct {
+			ID   string              `json:"id"`
+			Data []map[string]string `json:"data"`
+		} `json:"sample"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("Invalid JSON: %v\n%s", err, buf.String())
+	}
+
+	if report.Analysis.Title != "orders.csv" || report.Analysis.DateStart != "2024-05-01T12:00:00Z" {
+		t.Errorf("Unexpected analysis %+v", report.Analysis)
+	}
+	if report.Table.N != 4 || report.Table.NVar != 3 || report.Table.NCellsMissing != 1 || report.Table.PCellsMissing != 1.0/12 {
+		t.Errorf("Unexpected table %+v", report.Table)
+	}
+	if report.Table.Types["Numeric"] != 1 || report.Table.Types["Categorical"] != 2 {
+		t.Errorf("Expected 1 numeric and 2 categorical columns, got %v", report.Table.Types)
+	}
+
+	id := report.Variables["id"]
+	if id["type"] != "Numeric" || id["mean"] != 2.5 || id["is_unique"] != true || id["50%"] != 2.5 || id["range"] != 3.0 {
+		t.Errorf("Unexpected id variable %v", id)
+	}
+	status := report.Variables["status"]
+	if status["p_missing"] != 0.25 || status["n_distinct"] != 2.0 || status["mean"] != nil {
+		t.Errorf("Unexpected status variable %v", status)
+	}
+
+	alerts := strings.Join(report.Alerts, "\n")
+	for _, want := range []string{"[UNIQUE] alert on column id", "[MISSING] alert on column status", "[CONSTANT] alert on column constant"} {
+		if !strings.Contains(alerts, want) {
+			t.Errorf("Expected alert %q, got %v", want, report.Alerts)
+		}
+	}
+	if len(report.Sample) != 1 || report.Sample[0].ID != "head" || report.Sample[0].Data[0]["status"] != "open" {
+		t.Errorf("Unexpected sample %+v", report.Sample)
+	}
+
+	// Columns keep the order of the file
+	if i, j := strings.Index(buf.String(), `"status": {`), strings.Index(buf.String(), `"constant": {`); i < 0 || i > j {
+		t.Error("Expected variables in column order")
+	}
+}
@@ -0,0 +1,139 @@
+//go:build js && wasm
+
+// Command wasm is the WebAssembly build of the profiler, for profiling files
+// in a web page without uploading them. It defines a global gotablestats
+// object with one function:
+//
+//	gotablestats.profile(input, options) -> Promise
+//
+// input is a File, Blob, ArrayBuffer or typed array; options (optional) are
+// those of browser.Options, e.g. {format: "text", sampleSize: 5000}. The
+// promise resolves to the profile as an object for the json format, and to
+// the rendered report as a string for the others.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"syscall/js"
+
+	"github.com/WindowGenerator/gotablestats/internal/browser"
+)
+
+func main() {
+	api := js.Global().Get("Object").New()
+	api.Set("profile", js.FuncOf(profile))
+	js.Global().Set("gotablestats", api)
+
+	// Keep the exported functions alive
+	select {}
+}
+
+func profile(_ js.Value, args []js.Value) any {
+	input, options := js.Undefined(), js.Undefined()
+	if len(args) > 0 {
+		input = args[0]
+	}
+	if len(args) > 1 {
+		options = args[1]
+	}
+
+	return newPromise(func() (any, error) {
+		var opts browser.Options
+		if options.Truthy() {
+			text := js.Global().Get("JSON").Call("stringify", options).String()
+			if err := json.Unmarshal([]byte(text), &opts); err != nil {
+				return nil, fmt.Errorf("invalid options: %w", err)
+			}
+		}
+		if opts.Name == "" && input.Type() == js.TypeObject && input.Get("name").Type() == js.TypeString {
+			opts.Name = input.Get("name").String()
+		}
+
+		data, err := readInput(input)
+		if err != nil {
+			return nil, err
+		}
+		out, err := browser.Profile(context.Background(), data, opts)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Format == "" || opts.Format == "json" {
+			return js.Global().Get("JSON").Call("parse", string(out)), nil
+		}
+		return string(out), nil
+	})
+}
+
+// readInput copies the bytes of a File, Blob, ArrayBuffer or typed array
+func readInput(input js.Value) ([]byte, error) {
+	if input.Type() != js.TypeObject {
+		return nil, errors.New("expected a File, Blob, ArrayBuffer or typed array")
+	}
+	if input.Get("arrayBuffer").Type() == js.TypeFunction {
+		buf, err := await(input.Call("arrayBuffer"))
+		if err != nil {
+			return nil, err
+		}
+		input = buf
+	}
+
+	uint8Array := js.Global().Get("Uint8Array")
+	switch {
+	case input.InstanceOf(js.Global().Get("ArrayBuffer")):
+		input = uint8Array.New(input)
+	case js.Global().Get("ArrayBuffer").Call("isView", input).Bool():
+		input = uint8Array.New(input.Get("buffer"), input.Get("byteOffset"), input.Get("byteLength"))
+	default:
+		return nil, errors.New("expected a File, Blob, ArrayBuffer or typed array")
+	}
+	data := make([]byte, input.Get("length").Int())
+	js.CopyBytesToGo(data, input)
+	return data, nil
+}
+
+// await blocks until a promise settles. It must not be called from the event
+// loop, i.e. only from goroutines started by newPromise.
+func await(promise js.Value) (js.Value, error) {
+	done := make(chan struct{})
+	var result js.Value
+	var err error
+	onResolve := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		result = args[0]
+		close(done)
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		err = errors.New(js.Global().Get("String").Invoke(args[0]).String())
+		close(done)
+		return nil
+	})
+	defer onReject.Release()
+
+	promise.Call("then", onResolve, onReject)
+	<-done
+	return result, err
+}
+
+// newPromise returns a promise settled by the result of fn, which runs in its
+// own goroutine so it can wait for other promises
+func newPromise(fn func() (any, error)) js.Value {
+	var executor js.Func
+	executor = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		resolve, reject := args[0], args[1]
+		go func() {
+			defer executor.Release()
+			result, err := fn()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(result)
+		}()
+		return nil
+	})
+	return js.Global().Get("Promise").New(executor)
+}